@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -214,9 +215,72 @@ type Adapter interface {
 	FormatHistory(history *History) []Message
 }
 
+// InstructionPlacement controls where adapters put the signature's instruction (its description)
+type InstructionPlacement int
+
+const (
+	// InstructionSystem sends the instruction as a leading system message (default)
+	InstructionSystem InstructionPlacement = iota
+	// InstructionUser embeds the instruction at the top of the user prompt
+	InstructionUser
+)
+
+// withInstruction prepends the signature instruction as a system message when placement is InstructionSystem
+// With InstructionUser the adapter has already written it into the user prompt.
+// The WithGlobalInstructionPrefix text always leads the system message, whatever the placement.
+func withInstruction(placement InstructionPlacement, sig *Signature, messages []Message) []Message {
+	var parts []string
+	if prefix := globalInstructionPrefix(); prefix != "" {
+		parts = append(parts, prefix)
+	}
+	if placement == InstructionSystem && sig.Description != "" {
+		parts = append(parts, sig.Description)
+	}
+	if len(parts) == 0 {
+		return messages
+	}
+	return append([]Message{{Role: "system", Content: strings.Join(parts, "\n\n")}}, messages...)
+}
+
+// globalInstructionPrefix returns the configured WithGlobalInstructionPrefix text
+func globalInstructionPrefix() string {
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	return globalSettings.InstructionPrefix
+}
+
+// DefaultReasoningField is the output field CoT adapters request reasoning in
+const DefaultReasoningField = "reasoning"
+
+// defaultReasoningPrompt asks for reasoning before the answer
+const defaultReasoningPrompt = "Think through this step-by-step before providing your final answer."
+
+// ReasoningFieldOrDefault returns name, or DefaultReasoningField when it is empty
+func ReasoningFieldOrDefault(name string) string {
+	if name == "" {
+		return DefaultReasoningField
+	}
+	return name
+}
+
+// reasoningPromptOrDefault returns prompt, or the step-by-step instruction when it is empty
+func reasoningPromptOrDefault(prompt string) string {
+	if prompt == "" {
+		return defaultReasoningPrompt
+	}
+	return prompt
+}
+
 // JSONAdapter implements Adapter using JSON format for structured I/O
 type JSONAdapter struct {
-	IncludeReasoning bool // Whether to request reasoning field (for CoT)
+	IncludeReasoning     bool                 // Whether to request reasoning field (for CoT)
+	ReasoningField       string               // Name of the requested reasoning field (empty = "reasoning")
+	ReasoningPrompt      string               // Instruction asking for the reasoning (empty = think step-by-step)
+	InstructionPlacement InstructionPlacement // Where the signature description goes (default system message)
+	StrictFields         bool                 // Reject outputs with keys not in the signature instead of ignoring them
+	FunctionCalling      bool                 // Return outputs through a forced return_result tool call when the LM supports tools
+	DemoSeparator        string               // Text written after each rendered demo (empty = a blank line)
+	ConstrainedEnums     bool                 // Constrain class outputs to their classes at generation time (see ConstrainEnums)
 }
 
 // NewJSONAdapter creates a new JSON adapter
@@ -232,19 +296,67 @@ func (a *JSONAdapter) WithReasoning(include bool) *JSONAdapter {
 	return a
 }
 
+// WithReasoningField renames the requested reasoning field, e.g. "scratchpad"
+func (a *JSONAdapter) WithReasoningField(name string) *JSONAdapter {
+	a.ReasoningField = name
+	return a
+}
+
+// WithReasoningPrompt replaces the step-by-step instruction that asks for the reasoning,
+// e.g. "First identify the relevant formula."
+func (a *JSONAdapter) WithReasoningPrompt(prompt string) *JSONAdapter {
+	a.ReasoningPrompt = prompt
+	return a
+}
+
+// WithInstructionPlacement sets whether the signature description is sent as a system message or in the user prompt
+func (a *JSONAdapter) WithInstructionPlacement(placement InstructionPlacement) *JSONAdapter {
+	a.InstructionPlacement = placement
+	return a
+}
+
+// WithStrictFields makes Parse fail on output keys not in the signature, so fallback and retry
+// can recover; when lenient (the default) such keys are reported as Prediction.Metadata["ignored_fields"]
+func (a *JSONAdapter) WithStrictFields(strict bool) *JSONAdapter {
+	a.StrictFields = strict
+	return a
+}
+
+// WithDemoSeparator sets the text written after each rendered demo, e.g. "\n---\n"
+func (a *JSONAdapter) WithDemoSeparator(separator string) *JSONAdapter {
+	a.DemoSeparator = separator
+	return a
+}
+
+// WithFunctionCalling makes Predict request outputs as the arguments of a forced
+// return_result tool call instead of free-text JSON; LMs without tool support
+// fall back to prompt-based JSON (see UseFunctionCalling)
+func (a *JSONAdapter) WithFunctionCalling(enable bool) *JSONAdapter {
+	a.FunctionCalling = enable
+	return a
+}
+
+// WithConstrainedEnums makes modules constrain class outputs to their valid classes
+// while generating: through the JSON schema enum on models with structured outputs,
+// or else through logit_bias toward the class tokens (see ConstrainEnums)
+func (a *JSONAdapter) WithConstrainedEnums(enable bool) *JSONAdapter {
+	a.ConstrainedEnums = enable
+	return a
+}
+
 // Format builds prompt messages from signature and inputs
 func (a *JSONAdapter) Format(sig *Signature, inputs map[string]any, demos []Example) ([]Message, error) {
 	var prompt strings.Builder
 
-	// Add description
-	if sig.Description != "" {
+	// Add description unless it is sent as a system message
+	if sig.Description != "" && a.InstructionPlacement == InstructionUser {
 		prompt.WriteString(sig.Description)
 		prompt.WriteString("\n\n")
 	}
 
 	// Add CoT instruction if reasoning is enabled
 	if a.IncludeReasoning {
-		prompt.WriteString("Think through this step-by-step before providing your final answer.\n\n")
+		prompt.WriteString(reasoningPromptOrDefault(a.ReasoningPrompt) + "\n\n")
 	}
 
 	// Add demos if provided
@@ -255,16 +367,21 @@ func (a *JSONAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 		}
 		if len(demoMessages) > 0 {
 			// Append demo messages as examples
+			separator := a.DemoSeparator
+			if separator == "" {
+				separator = "\n"
+			}
 			prompt.WriteString("--- Examples ---\n")
 			for _, msg := range demoMessages {
 				prompt.WriteString(msg.Content)
-				prompt.WriteString("\n")
+				prompt.WriteString(separator)
 			}
 			prompt.WriteString("\n")
 		}
 	}
 
-	// Add input fields
+	// Add input fields (image inputs are attached to the message)
+	var images []ImageContent
 	if len(sig.InputFields) > 0 {
 		prompt.WriteString("--- Inputs ---\n")
 		for _, field := range sig.InputFields {
@@ -275,10 +392,14 @@ func (a *JSONAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 				}
 				continue
 			}
+			rendered, err := formatInputValue(field, value, &images)
+			if err != nil {
+				return nil, err
+			}
 			if field.Description != "" {
-				prompt.WriteString(fmt.Sprintf("%s (%s): %v\n", field.Name, field.Description, value))
+				prompt.WriteString(fmt.Sprintf("%s (%s): %s\n", field.Name, field.Description, rendered))
 			} else {
-				prompt.WriteString(fmt.Sprintf("%s: %v\n", field.Name, value))
+				prompt.WriteString(fmt.Sprintf("%s: %s\n", field.Name, rendered))
 			}
 		}
 		prompt.WriteString("\n")
@@ -291,7 +412,7 @@ func (a *JSONAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 
 		// Add reasoning field if enabled
 		if a.IncludeReasoning {
-			prompt.WriteString("- reasoning (string): Your step-by-step thought process\n")
+			prompt.WriteString("- " + ReasoningFieldOrDefault(a.ReasoningField) + " (string): Your step-by-step thought process\n")
 		}
 
 		for _, field := range sig.OutputFields {
@@ -312,13 +433,23 @@ func (a *JSONAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 		prompt.WriteString("\nIMPORTANT: Return ONLY valid JSON in your response. Do not include any markdown formatting, code blocks, or explanatory text.\n")
 	}
 
-	return []Message{{Role: "user", Content: prompt.String()}}, nil
+	return withInstruction(a.InstructionPlacement, sig, []Message{{Role: "user", Content: prompt.String(), Images: images}}), nil
 }
 
 // Parse extracts structured outputs from LM response
 func (a *JSONAdapter) Parse(sig *Signature, content string) (map[string]any, error) {
+	// Thinking text from reasoning models would otherwise pollute the JSON
+	_, content = SplitReasoning(content)
+
 	// Extract JSON using unified utility
 	jsonStr, err := jsonutil.ExtractJSON(content)
+	repaired := false
+	if err != nil {
+		// Truncated output has no complete object to extract - try to repair it first
+		if candidate, ok := repairTruncatedJSON(content); ok {
+			jsonStr, err, repaired = candidate, nil, true
+		}
+	}
 	if err != nil {
 		// VERBOSE DEBUG for parsing failures
 		if debugEnv := os.Getenv("DSGO_DEBUG_PARSE"); debugEnv == "1" || debugEnv == "true" {
@@ -337,7 +468,7 @@ func (a *JSONAdapter) Parse(sig *Signature, content string) (map[string]any, err
 			}
 			return outputs, nil
 		}
-		return nil, err
+		return nil, &ParseError{Adapter: "JSONAdapter", Raw: content, Err: err}
 	}
 
 	var outputs map[string]any
@@ -345,21 +476,66 @@ func (a *JSONAdapter) Parse(sig *Signature, content string) (map[string]any, err
 		// Try to repair the JSON before failing
 		repairedJSON := jsonutil.RepairJSON(jsonStr)
 		if err := json.Unmarshal([]byte(repairedJSON), &outputs); err != nil {
-			return nil, fmt.Errorf("failed to parse JSON output: %w (content: %s)", err, jsonStr)
+			return nil, &ParseError{Adapter: "JSONAdapter", Raw: content, Err: fmt.Errorf("failed to parse JSON output: %w (content: %s)", err, jsonStr)}
 		}
-		// Track that repair was used
-		outputs["__json_repair"] = true
+		repaired = true
+	}
+
+	// Track that repair was used (surfaced as Prediction.Metadata["json_repaired"])
+	if repaired {
+		outputs[jsonRepairedKey] = true
 	}
 
 	// Normalize field names for resilient parsing
 	outputs = NormalizeOutputKeys(sig, outputs)
 
+	// Keys the model invented can signal a misunderstood prompt
+	if extra := a.unexpectedFields(sig, outputs); len(extra) > 0 {
+		if a.StrictFields {
+			return nil, &ParseError{Adapter: "JSONAdapter", Raw: content, Err: fmt.Errorf("unexpected output fields not in signature: %s", strings.Join(extra, ", "))}
+		}
+		outputs[ignoredFieldsKey] = extra
+	}
+
 	// Coerce types to match signature expectations
 	outputs = a.coerceTypes(sig, outputs)
 
 	return outputs, nil
 }
 
+// unexpectedFields returns the sorted output keys that are not signature output fields
+// The requested reasoning field and internal markers are expected.
+func (a *JSONAdapter) unexpectedFields(sig *Signature, outputs map[string]any) []string {
+	var extra []string
+	for key := range outputs {
+		if sig.GetOutputField(key) != nil || strings.HasPrefix(key, "__") {
+			continue
+		}
+		if a.IncludeReasoning && key == ReasoningFieldOrDefault(a.ReasoningField) {
+			continue
+		}
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+// repairTruncatedJSON attempts to repair content whose JSON object was cut off
+// (e.g. by max tokens) so that no complete object could be extracted
+func repairTruncatedJSON(content string) (string, bool) {
+	start := strings.Index(content, "{")
+	if start < 0 {
+		return "", false
+	}
+
+	candidate := jsonutil.RepairJSON(content[start:])
+	var probe map[string]any
+	if err := json.Unmarshal([]byte(candidate), &probe); err != nil {
+		return "", false
+	}
+	return candidate, true
+}
+
 // coerceTypes attempts to convert output values to expected types
 func (a *JSONAdapter) coerceTypes(sig *Signature, outputs map[string]any) map[string]any {
 	return coerceOutputs(sig, outputs, true) // allow array→string coercion
@@ -375,14 +551,14 @@ func (a *JSONAdapter) formatDemos(sig *Signature, demos []Example) ([]Message, e
 
 		// Show inputs
 		demoText.WriteString("Inputs:\n")
-		for k, v := range demo.Inputs {
-			demoText.WriteString(fmt.Sprintf("  %s: %v\n", k, v))
+		for _, k := range OrderedFieldKeys(sig.InputFields, demo.Inputs) {
+			demoText.WriteString(fmt.Sprintf("  %s: %v\n", k, demo.Inputs[k]))
 		}
 
 		// Show outputs
 		if len(demo.Outputs) > 0 {
 			demoText.WriteString("Expected Output:\n")
-			outputJSON, err := json.MarshalIndent(demo.Outputs, "  ", "  ")
+			outputJSON, err := marshalOrderedIndent(OrderedFieldKeys(sig.OutputFields, demo.Outputs), demo.Outputs, "  ", "  ")
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal demo output: %w", err)
 			}
@@ -406,39 +582,92 @@ func (a *JSONAdapter) FormatHistory(history *History) []Message {
 	return history.Get()
 }
 
+// Default delimiters around output field names: [[ ## field_name ## ]]
+const (
+	defaultFieldOpen  = "[[ ## "
+	defaultFieldClose = " ## ]]"
+)
+
 // ChatAdapter implements Adapter using field markers for structured I/O
 // Uses format: [[ ## field_name ## ]] value to mark outputs
 // This adapter is more robust for models that struggle with JSON
 type ChatAdapter struct {
-	IncludeReasoning bool // Whether to request reasoning field (for CoT)
+	IncludeReasoning     bool                 // Whether to request reasoning field (for CoT)
+	ReasoningField       string               // Name of the requested reasoning field (empty = "reasoning")
+	ReasoningPrompt      string               // Instruction asking for the reasoning (empty = think step-by-step)
+	FieldOpen            string               // Marker text before a field name (default "[[ ## ")
+	FieldClose           string               // Marker text after a field name (default " ## ]]")
+	InstructionPlacement InstructionPlacement // Where the signature description goes (default system message)
 }
 
 // NewChatAdapter creates a new chat adapter
 func NewChatAdapter() *ChatAdapter {
 	return &ChatAdapter{
 		IncludeReasoning: false,
+		FieldOpen:        defaultFieldOpen,
+		FieldClose:       defaultFieldClose,
 	}
 }
 
+// WithFieldDelimiter sets the marker text placed before and after output field names,
+// e.g. WithFieldDelimiter("<<<", ">>>") produces markers like <<<answer>>>
+func (a *ChatAdapter) WithFieldDelimiter(open, close string) *ChatAdapter {
+	a.FieldOpen = open
+	a.FieldClose = close
+	return a
+}
+
+// marker returns the field marker for name
+func (a *ChatAdapter) marker(name string) string {
+	if a.FieldOpen == "" && a.FieldClose == "" {
+		return defaultFieldOpen + name + defaultFieldClose
+	}
+	return a.FieldOpen + name + a.FieldClose
+}
+
+// usesDefaultDelimiter reports whether the default [[ ## ... ## ]] markers are in use
+func (a *ChatAdapter) usesDefaultDelimiter() bool {
+	return a.marker("x") == defaultFieldOpen+"x"+defaultFieldClose
+}
+
 // WithReasoning enables reasoning field in output format
 func (a *ChatAdapter) WithReasoning(include bool) *ChatAdapter {
 	a.IncludeReasoning = include
 	return a
 }
 
+// WithReasoningField renames the requested reasoning field, e.g. "scratchpad"
+func (a *ChatAdapter) WithReasoningField(name string) *ChatAdapter {
+	a.ReasoningField = name
+	return a
+}
+
+// WithReasoningPrompt replaces the step-by-step instruction that asks for the reasoning,
+// e.g. "First identify the relevant formula."
+func (a *ChatAdapter) WithReasoningPrompt(prompt string) *ChatAdapter {
+	a.ReasoningPrompt = prompt
+	return a
+}
+
+// WithInstructionPlacement sets whether the signature description is sent as a system message or in the user prompt
+func (a *ChatAdapter) WithInstructionPlacement(placement InstructionPlacement) *ChatAdapter {
+	a.InstructionPlacement = placement
+	return a
+}
+
 // Format builds prompt messages from signature and inputs
 func (a *ChatAdapter) Format(sig *Signature, inputs map[string]any, demos []Example) ([]Message, error) {
 	var prompt strings.Builder
 
-	// Add description
-	if sig.Description != "" {
+	// Add description unless it is sent as a system message
+	if sig.Description != "" && a.InstructionPlacement == InstructionUser {
 		prompt.WriteString(sig.Description)
 		prompt.WriteString("\n\n")
 	}
 
 	// Add CoT instruction if reasoning is enabled
 	if a.IncludeReasoning {
-		prompt.WriteString("Think through this step-by-step before providing your final answer.\n\n")
+		prompt.WriteString(reasoningPromptOrDefault(a.ReasoningPrompt) + "\n\n")
 	}
 
 	// Add demos if provided (will be added as separate messages)
@@ -451,7 +680,8 @@ func (a *ChatAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 		}
 	}
 
-	// Add input fields
+	// Add input fields (image inputs are attached to the message)
+	var images []ImageContent
 	if len(sig.InputFields) > 0 {
 		prompt.WriteString("--- Inputs ---\n")
 		for _, field := range sig.InputFields {
@@ -462,10 +692,14 @@ func (a *ChatAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 				}
 				continue
 			}
+			rendered, err := formatInputValue(field, value, &images)
+			if err != nil {
+				return nil, err
+			}
 			if field.Description != "" {
-				prompt.WriteString(fmt.Sprintf("%s (%s): %v\n", field.Name, field.Description, value))
+				prompt.WriteString(fmt.Sprintf("%s (%s): %s\n", field.Name, field.Description, rendered))
 			} else {
-				prompt.WriteString(fmt.Sprintf("%s: %v\n", field.Name, value))
+				prompt.WriteString(fmt.Sprintf("%s: %s\n", field.Name, rendered))
 			}
 		}
 		prompt.WriteString("\n")
@@ -478,7 +712,7 @@ func (a *ChatAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 
 		// Add reasoning field if enabled
 		if a.IncludeReasoning {
-			prompt.WriteString("[[ ## reasoning ## ]]\nYour step-by-step thought process\n\n")
+			prompt.WriteString(a.marker(ReasoningFieldOrDefault(a.ReasoningField)) + "\nYour step-by-step thought process\n\n")
 		}
 
 		for _, field := range sig.OutputFields {
@@ -512,44 +746,49 @@ func (a *ChatAdapter) Format(sig *Signature, inputs map[string]any, demos []Exam
 				hintText = " (" + strings.Join(hints, ", ") + ")"
 			}
 
-			prompt.WriteString(fmt.Sprintf("[[ ## %s ## ]]%s\n\n", field.Name, hintText))
+			prompt.WriteString(fmt.Sprintf("%s%s\n\n", a.marker(field.Name), hintText))
 		}
-		prompt.WriteString("IMPORTANT: Use the exact field marker format shown above. Start each field with [[ ## field_name ## ]].\n")
+		prompt.WriteString(fmt.Sprintf("IMPORTANT: Use the exact field marker format shown above. Start each field with %s on its own line.\n", a.marker("field_name")))
 	}
 
 	// Combine demo messages with the main prompt
 	messages := demoMessages
-	messages = append(messages, Message{Role: "user", Content: prompt.String()})
+	messages = append(messages, Message{Role: "user", Content: prompt.String(), Images: images})
 
-	return messages, nil
+	return withInstruction(a.InstructionPlacement, sig, messages), nil
 }
 
 // Parse extracts structured outputs from LM response using field markers
 func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, error) {
+	// Thinking text from reasoning models may contain field markers of its own
+	_, content = SplitReasoning(content)
+
 	outputs := make(map[string]any)
 
 	// Build list of fields to extract
 	fieldsToExtract := make([]string, 0, len(sig.OutputFields)+1)
 	if a.IncludeReasoning {
-		fieldsToExtract = append(fieldsToExtract, "reasoning")
+		fieldsToExtract = append(fieldsToExtract, ReasoningFieldOrDefault(a.ReasoningField))
 	}
 	for _, field := range sig.OutputFields {
 		fieldsToExtract = append(fieldsToExtract, field.Name)
 	}
 
 	// Extract each field using the marker pattern [[ ## field ## ]]
+	// Markers on their own line take precedence over markers echoed inside values
+	defaultDelimiter := a.usesDefaultDelimiter()
 	for _, fieldName := range fieldsToExtract {
-		marker := fmt.Sprintf("[[ ## %s ## ]]", fieldName)
-		startIdx := strings.Index(content, marker)
+		marker := a.marker(fieldName)
+		startIdx := indexMarker(content, marker)
 		markerLen := len(marker)
 
 		// CRITICAL FIX 1: Try variations with/without spaces
-		if startIdx == -1 {
+		if startIdx == -1 && defaultDelimiter {
 			marker = fmt.Sprintf("[[## %s ##]]", fieldName)
 			startIdx = strings.Index(content, marker)
 			markerLen = len(marker)
 		}
-		if startIdx == -1 {
+		if startIdx == -1 && defaultDelimiter {
 			marker = fmt.Sprintf("[[##%s##]]", fieldName)
 			startIdx = strings.Index(content, marker)
 			markerLen = len(marker)
@@ -557,7 +796,7 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 
 		// CRITICAL FIX 2: Try incomplete markers (common LM error)
 		// Models often emit [[ ## field ## ] or [[ ## field ## instead of [[ ## field ## ]]
-		if startIdx == -1 {
+		if startIdx == -1 && defaultDelimiter {
 			// Try marker missing closing brackets
 			lenientMarker := fmt.Sprintf("[[ ## %s ##", fieldName)
 			startIdx = strings.Index(content, lenientMarker)
@@ -577,7 +816,7 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 		}
 
 		// Try single closing bracket variant
-		if startIdx == -1 {
+		if startIdx == -1 && defaultDelimiter {
 			singleBracketMarker := fmt.Sprintf("[[ ## %s ## ]", fieldName)
 			startIdx = strings.Index(content, singleBracketMarker)
 			if startIdx >= 0 {
@@ -595,7 +834,12 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 					outputs[fieldName] = extracted
 					continue
 				}
-				return nil, fmt.Errorf("required field '%s' not found in response (expected marker: [[ ## %s ## ]])", fieldName, fieldName)
+				return nil, &ParseError{
+					Adapter: "ChatAdapter",
+					Raw:     content,
+					Field:   fieldName,
+					Err:     fmt.Errorf("required field '%s' not found in response (expected marker: %s)", fieldName, a.marker(fieldName)),
+				}
 			}
 			continue
 		}
@@ -609,8 +853,7 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 			if nextField == fieldName {
 				continue
 			}
-			nextMarker := fmt.Sprintf("[[ ## %s ## ]]", nextField)
-			nextIdx := strings.Index(content[valueStart:], nextMarker)
+			nextIdx := indexMarker(content[valueStart:], a.marker(nextField))
 			if nextIdx != -1 {
 				absIdx := valueStart + nextIdx
 				if absIdx < valueEnd {
@@ -631,7 +874,12 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 
 		// Strip field markers from value (removes markers for clean internal data flow)
 		// For JSON fields, do this BEFORE parsing to clean up markers but preserve JSON syntax
-		if field != nil && field.Type == FieldTypeJSON {
+		if !defaultDelimiter {
+			// Custom delimiters: only remove exact markers of known fields
+			for _, name := range fieldsToExtract {
+				value = strings.TrimSpace(strings.ReplaceAll(value, a.marker(name), ""))
+			}
+		} else if field != nil && field.Type == FieldTypeJSON {
 			// For JSON fields, use lighter stripping that preserves JSON structure
 			value = stripFieldMarkersPreserveJSON(value)
 		} else {
@@ -683,6 +931,28 @@ func (a *ChatAdapter) Parse(sig *Signature, content string) (map[string]any, err
 	return outputs, nil
 }
 
+// indexMarker returns the index of the first occurrence of marker that starts a
+// line (ignoring leading whitespace), falling back to the first occurrence anywhere.
+// This keeps markers echoed inside a field value from being read as boundaries.
+func indexMarker(content, marker string) int {
+	first := -1
+	for offset := 0; ; {
+		idx := strings.Index(content[offset:], marker)
+		if idx == -1 {
+			return first
+		}
+		idx += offset
+		if first == -1 {
+			first = idx
+		}
+		lineStart := strings.LastIndex(content[:idx], "\n") + 1
+		if strings.TrimSpace(content[lineStart:idx]) == "" {
+			return idx
+		}
+		offset = idx + len(marker)
+	}
+}
+
 // heuristicExtract attempts to extract a field value using simple heuristics when markers aren't found
 func (a *ChatAdapter) heuristicExtract(content string, fieldName string, fieldType FieldType) string {
 	// Try common field name synonyms
@@ -822,8 +1092,8 @@ func (a *ChatAdapter) formatDemos(sig *Signature, demos []Example) ([]Message, e
 		// User message with inputs
 		var userText strings.Builder
 		userText.WriteString(fmt.Sprintf("--- Example %d (Inputs) ---\n", i+1))
-		for k, v := range demo.Inputs {
-			userText.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+		for _, k := range OrderedFieldKeys(sig.InputFields, demo.Inputs) {
+			userText.WriteString(fmt.Sprintf("%s: %v\n", k, demo.Inputs[k]))
 		}
 
 		messages = append(messages, Message{
@@ -836,7 +1106,7 @@ func (a *ChatAdapter) formatDemos(sig *Signature, demos []Example) ([]Message, e
 			var assistantText strings.Builder
 			for _, field := range sig.OutputFields {
 				if value, exists := demo.Outputs[field.Name]; exists {
-					assistantText.WriteString(fmt.Sprintf("[[ ## %s ## ]]\n%v\n\n", field.Name, value))
+					assistantText.WriteString(fmt.Sprintf("%s\n%v\n\n", a.marker(field.Name), value))
 				}
 			}
 
@@ -893,6 +1163,31 @@ func NewFallbackAdapterWithChain(adapters ...Adapter) *FallbackAdapter {
 	}
 }
 
+// DefaultAdapterFor returns the adapter Predict uses for lm unless one is set.
+// Models with native structured outputs get a JSON-first chain (JSON → Chat), so
+// prompts ask for JSON, are sent in JSON mode with a schema and usually parse on the
+// first attempt; other models get the chat-first NewFallbackAdapter.
+func DefaultAdapterFor(lm LM) Adapter {
+	if lm != nil && CapabilitiesOf(lm).SupportsJSONSchema {
+		return NewFallbackAdapterWithChain(NewJSONAdapter(), NewChatAdapter())
+	}
+	return NewFallbackAdapter()
+}
+
+// UsesJSONMode reports whether prompts formatted by adapter ask for JSON output, so
+// providers can be put in JSON mode: adapter is a JSONAdapter, or a FallbackAdapter
+// whose first (formatting) adapter is one.
+func UsesJSONMode(adapter Adapter) bool {
+	if fallback, ok := adapter.(*FallbackAdapter); ok {
+		if len(fallback.adapters) == 0 {
+			return false
+		}
+		adapter = fallback.adapters[0]
+	}
+	_, isJSON := adapter.(*JSONAdapter)
+	return isJSON
+}
+
 // WithReasoning enables reasoning field in all adapters that support it
 func (f *FallbackAdapter) WithReasoning(include bool) *FallbackAdapter {
 	for _, adapter := range f.adapters {
@@ -906,6 +1201,47 @@ func (f *FallbackAdapter) WithReasoning(include bool) *FallbackAdapter {
 	return f
 }
 
+// WithReasoningField renames the reasoning field in all adapters that support it
+func (f *FallbackAdapter) WithReasoningField(name string) *FallbackAdapter {
+	for _, adapter := range f.adapters {
+		switch a := adapter.(type) {
+		case *ChatAdapter:
+			a.WithReasoningField(name)
+		case *JSONAdapter:
+			a.WithReasoningField(name)
+		}
+	}
+	return f
+}
+
+// WithReasoningPrompt sets the reasoning instruction in all adapters that support it
+func (f *FallbackAdapter) WithReasoningPrompt(prompt string) *FallbackAdapter {
+	for _, adapter := range f.adapters {
+		switch a := adapter.(type) {
+		case *ChatAdapter:
+			a.WithReasoningPrompt(prompt)
+		case *JSONAdapter:
+			a.WithReasoningPrompt(prompt)
+		}
+	}
+	return f
+}
+
+// WithInstructionPlacement sets the instruction placement in all adapters that support it
+func (f *FallbackAdapter) WithInstructionPlacement(placement InstructionPlacement) *FallbackAdapter {
+	for _, adapter := range f.adapters {
+		switch a := adapter.(type) {
+		case *ChatAdapter:
+			a.WithInstructionPlacement(placement)
+		case *JSONAdapter:
+			a.WithInstructionPlacement(placement)
+		case *TwoStepAdapter:
+			a.WithInstructionPlacement(placement)
+		}
+	}
+	return f
+}
+
 // Format uses the first adapter in the chain for formatting
 func (f *FallbackAdapter) Format(sig *Signature, inputs map[string]any, demos []Example) ([]Message, error) {
 	if len(f.adapters) == 0 {
@@ -943,7 +1279,11 @@ func (f *FallbackAdapter) Parse(sig *Signature, content string) (map[string]any,
 		errMsg.WriteString(fmt.Sprintf("  - %v\n", err))
 	}
 	errMsg.WriteString(fmt.Sprintf("\nRAW RESPONSE (length=%d):\n%s\n", len(content), content))
-	return nil, fmt.Errorf("%s", errMsg.String())
+	attempts := make([]string, len(parseErrors))
+	for i, err := range parseErrors {
+		attempts[i] = err.Error()
+	}
+	return nil, &ParseError{Adapter: "FallbackAdapter", Raw: content, Err: fmt.Errorf("%s", errMsg.String()), Attempts: attempts}
 }
 
 // FormatHistory uses the first adapter in the chain
@@ -967,8 +1307,9 @@ func (f *FallbackAdapter) GetLastUsedAdapter() int {
 // Stage 2: Extraction model parses the free-form response into structured outputs
 // This is critical for reasoning models (o1/o3/gpt-5) that struggle with structured outputs
 type TwoStepAdapter struct {
-	extractionLM     LM   // The LM to use for extraction (stage 2)
-	IncludeReasoning bool // Whether to preserve reasoning from stage 1
+	extractionLM         LM                   // The LM to use for extraction (stage 2)
+	IncludeReasoning     bool                 // Whether to preserve reasoning from stage 1
+	InstructionPlacement InstructionPlacement // Where the signature description goes (default system message)
 }
 
 // NewTwoStepAdapter creates a new two-step adapter
@@ -987,13 +1328,19 @@ func (a *TwoStepAdapter) WithReasoning(include bool) *TwoStepAdapter {
 	return a
 }
 
+// WithInstructionPlacement sets whether the signature description is sent as a system message or in the user prompt
+func (a *TwoStepAdapter) WithInstructionPlacement(placement InstructionPlacement) *TwoStepAdapter {
+	a.InstructionPlacement = placement
+	return a
+}
+
 // Format builds prompt messages for stage 1 (free-form generation)
 // This allows the reasoning model to work without structured output constraints
 func (a *TwoStepAdapter) Format(sig *Signature, inputs map[string]any, demos []Example) ([]Message, error) {
 	var prompt strings.Builder
 
-	// Add description
-	if sig.Description != "" {
+	// Add description unless it is sent as a system message
+	if sig.Description != "" && a.InstructionPlacement == InstructionUser {
 		prompt.WriteString(sig.Description)
 		prompt.WriteString("\n\n")
 	}
@@ -1008,20 +1355,21 @@ func (a *TwoStepAdapter) Format(sig *Signature, inputs map[string]any, demos []E
 		for i, demo := range demos {
 			prompt.WriteString(fmt.Sprintf("\nExample %d:\n", i+1))
 			prompt.WriteString("Inputs:\n")
-			for k, v := range demo.Inputs {
-				prompt.WriteString(fmt.Sprintf("  %s: %v\n", k, v))
+			for _, k := range OrderedFieldKeys(sig.InputFields, demo.Inputs) {
+				prompt.WriteString(fmt.Sprintf("  %s: %v\n", k, demo.Inputs[k]))
 			}
 			if len(demo.Outputs) > 0 {
 				prompt.WriteString("Response:\n")
-				for k, v := range demo.Outputs {
-					prompt.WriteString(fmt.Sprintf("  %s: %v\n", k, v))
+				for _, k := range OrderedFieldKeys(sig.OutputFields, demo.Outputs) {
+					prompt.WriteString(fmt.Sprintf("  %s: %v\n", k, demo.Outputs[k]))
 				}
 			}
 		}
 		prompt.WriteString("\n")
 	}
 
-	// Add input fields
+	// Add input fields (image inputs are attached to the message)
+	var images []ImageContent
 	if len(sig.InputFields) > 0 {
 		prompt.WriteString("--- Inputs ---\n")
 		for _, field := range sig.InputFields {
@@ -1032,10 +1380,14 @@ func (a *TwoStepAdapter) Format(sig *Signature, inputs map[string]any, demos []E
 				}
 				continue
 			}
+			rendered, err := formatInputValue(field, value, &images)
+			if err != nil {
+				return nil, err
+			}
 			if field.Description != "" {
-				prompt.WriteString(fmt.Sprintf("%s (%s): %v\n", field.Name, field.Description, value))
+				prompt.WriteString(fmt.Sprintf("%s (%s): %s\n", field.Name, field.Description, rendered))
 			} else {
-				prompt.WriteString(fmt.Sprintf("%s: %v\n", field.Name, value))
+				prompt.WriteString(fmt.Sprintf("%s: %s\n", field.Name, rendered))
 			}
 		}
 		prompt.WriteString("\n")
@@ -1054,7 +1406,7 @@ func (a *TwoStepAdapter) Format(sig *Signature, inputs map[string]any, demos []E
 		prompt.WriteString("\nProvide your response in a clear, natural format.\n")
 	}
 
-	return []Message{{Role: "user", Content: prompt.String()}}, nil
+	return withInstruction(a.InstructionPlacement, sig, []Message{{Role: "user", Content: prompt.String(), Images: images}}), nil
 }
 
 // Parse implements a two-stage extraction process
@@ -1110,7 +1462,7 @@ func (a *TwoStepAdapter) Parse(sig *Signature, content string) (map[string]any,
 	jsonAdapter := NewJSONAdapter()
 	outputs, err := jsonAdapter.Parse(sig, result.Content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse extraction result: %w", err)
+		return nil, &ParseError{Adapter: "TwoStepAdapter", Raw: result.Content, Err: fmt.Errorf("failed to parse extraction result: %w", err)}
 	}
 
 	return outputs, nil
@@ -1189,3 +1541,55 @@ func stripFieldMarkersPreserveJSON(s string) string {
 
 	return s
 }
+
+// OrderedFieldKeys returns the keys of values in signature field order, followed
+// by any keys not in the signature sorted alphabetically, so prompts are stable
+func OrderedFieldKeys(fields []Field, values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, field := range fields {
+		if _, ok := values[field.Name]; ok && !seen[field.Name] {
+			keys = append(keys, field.Name)
+			seen[field.Name] = true
+		}
+	}
+
+	var extra []string
+	for k := range values {
+		if !seen[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+// marshalOrderedIndent is json.MarshalIndent for a map, emitting keys in the given order
+func marshalOrderedIndent(keys []string, values map[string]any, prefix, indent string) ([]byte, error) {
+	if len(keys) == 0 {
+		return []byte("{}"), nil
+	}
+
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	for i, k := range keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(values[k], prefix+indent, indent)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(prefix + indent)
+		buf.Write(key)
+		buf.WriteString(": ")
+		buf.Write(value)
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(prefix + "}")
+	return []byte(buf.String()), nil
+}
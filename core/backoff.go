@@ -0,0 +1,29 @@
+package core
+
+import "time"
+
+// BackoffConfig tunes how providers retry network errors, 429 and 5xx responses.
+// Retry attempt n waits InitialDelay * Multiplier^n, capped at MaxDelay, plus or
+// minus Jitter of it; a server's Retry-After takes precedence over the schedule.
+// Zero fields keep the defaults (1s, 2, 30s, 0.1, 3 retries); a negative Jitter
+// disables jitter and a negative MaxRetries disables retries.
+type BackoffConfig struct {
+	InitialDelay time.Duration // Delay before the first retry
+	Multiplier   float64       // Growth factor per retry
+	MaxDelay     time.Duration // Upper bound of a delay before jitter
+	Jitter       float64       // Random spread as a fraction of the delay, e.g. 0.1 for ±10%
+	MaxRetries   int           // Retries after the first attempt
+}
+
+// RetryBackoff returns the retry schedule providers apply to each request: the
+// configured BackoffConfig with MaxRetries taken from the MaxRetries setting
+func RetryBackoff() BackoffConfig {
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	config := globalSettings.Backoff
+	config.MaxRetries = globalSettings.MaxRetries
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = -1
+	}
+	return config
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultBatchConcurrency bounds concurrent calls when an LM has no native batch support
+const defaultBatchConcurrency = 8
+
+// BatchRequest is one generation request in a batch
+// An empty ID defaults to "request-<index>"
+type BatchRequest struct {
+	ID       string
+	Messages []Message
+	Options  *GenerateOptions // nil uses DefaultGenerateOptions
+}
+
+// BatchResult is the outcome of one BatchRequest
+// Exactly one of Result and Err is set
+type BatchResult struct {
+	ID     string
+	Result *GenerateResult
+	Err    error
+}
+
+// BatchLM is implemented by providers with a native batch API (e.g. OpenAI's Batch API)
+// Implementations receive requests with IDs already assigned and must return one
+// result per request, in request order.
+type BatchLM interface {
+	GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error)
+}
+
+// BatchGenerate runs requests as a batch and returns results in request order
+// LMs implementing BatchLM submit a single provider batch job; all others fall back to
+// bounded-concurrency Generate calls. Wrapped LMs (collector, rate limit, middleware)
+// take the fallback path so every request is still observed per call.
+// Per-request failures are reported in BatchResult.Err; the returned error is only
+// set when the batch as a whole could not run.
+func BatchGenerate(ctx context.Context, lm LM, requests []BatchRequest) ([]BatchResult, error) {
+	requests = append([]BatchRequest(nil), requests...)
+	seen := make(map[string]bool, len(requests))
+	for i := range requests {
+		if requests[i].ID == "" {
+			requests[i].ID = fmt.Sprintf("request-%d", i)
+		}
+		if seen[requests[i].ID] {
+			return nil, fmt.Errorf("duplicate batch request ID %q", requests[i].ID)
+		}
+		seen[requests[i].ID] = true
+		if requests[i].Options == nil {
+			requests[i].Options = DefaultGenerateOptions()
+		}
+	}
+	if len(requests) == 0 {
+		return []BatchResult{}, nil
+	}
+
+	if batchLM, ok := lm.(BatchLM); ok {
+		results, err := batchLM.GenerateBatch(ctx, requests)
+		if err != nil {
+			return nil, fmt.Errorf("batch generation failed: %w", err)
+		}
+		if len(results) != len(requests) {
+			return nil, fmt.Errorf("batch generation returned %d results for %d requests", len(results), len(requests))
+		}
+		return results, nil
+	}
+
+	return generateConcurrently(ctx, lm, requests, defaultBatchConcurrency), nil
+}
+
+// generateConcurrently calls Generate for each request with at most limit calls in flight
+func generateConcurrently(ctx context.Context, lm LM, requests []BatchRequest, limit int) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, req := range requests {
+		results[i].ID = req.ID
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req BatchRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Result, results[i].Err = lm.Generate(ctx, req.Messages, req.Options)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return results
+}
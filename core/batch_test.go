@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchGenerate_ConcurrentFallback(t *testing.T) {
+	var inFlight, peak int32
+	lm := &mockWrapperLM{
+		name: "plain",
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			if messages[0].Content == "fail" {
+				return nil, errors.New("boom")
+			}
+			return &GenerateResult{Content: "re: " + messages[0].Content}, nil
+		},
+	}
+
+	requests := make([]BatchRequest, 20)
+	for i := range requests {
+		content := "msg"
+		if i == 3 {
+			content = "fail"
+		}
+		requests[i] = BatchRequest{Messages: []Message{{Role: "user", Content: content}}}
+	}
+	requests[0].ID = "first"
+
+	results, err := BatchGenerate(context.Background(), lm, requests)
+	if err != nil {
+		t.Fatalf("BatchGenerate() error = %v", err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(results), len(requests))
+	}
+	if results[0].ID != "first" || results[1].ID != "request-1" {
+		t.Errorf("unexpected IDs: %q, %q", results[0].ID, results[1].ID)
+	}
+	if results[3].Err == nil || results[3].Result != nil {
+		t.Errorf("expected per-item error for request 3, got %+v", results[3])
+	}
+	if results[4].Err != nil || results[4].Result.Content != "re: msg" {
+		t.Errorf("unexpected result: %+v", results[4])
+	}
+	if peak > defaultBatchConcurrency || peak < 2 {
+		t.Errorf("peak concurrency = %d, want between 2 and %d", peak, defaultBatchConcurrency)
+	}
+}
+
+// nativeBatchLM records whether its batch API was used
+type nativeBatchLM struct {
+	mockWrapperLM
+	batches int
+}
+
+func (n *nativeBatchLM) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	n.batches++
+	results := make([]BatchResult, len(requests))
+	for i, req := range requests {
+		results[i] = BatchResult{ID: req.ID, Result: &GenerateResult{Content: "batched " + req.ID}}
+	}
+	return results, nil
+}
+
+func TestBatchGenerate_NativeBatch(t *testing.T) {
+	lm := &nativeBatchLM{mockWrapperLM: mockWrapperLM{name: "batcher"}}
+
+	results, err := BatchGenerate(context.Background(), lm, []BatchRequest{{ID: "x"}, {}})
+	if err != nil {
+		t.Fatalf("BatchGenerate() error = %v", err)
+	}
+	if lm.batches != 1 || results[0].Result.Content != "batched x" || results[1].Result.Content != "batched request-1" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestBatchGenerate_Validation(t *testing.T) {
+	_, err := BatchGenerate(context.Background(), &mockWrapperLM{}, []BatchRequest{{ID: "a"}, {ID: "a"}})
+	if err == nil || !strings.Contains(err.Error(), `duplicate batch request ID "a"`) {
+		t.Errorf("expected duplicate ID error, got %v", err)
+	}
+
+	results, err := BatchGenerate(context.Background(), &mockWrapperLM{}, nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected empty results, got %v, %v", results, err)
+	}
+}
@@ -196,22 +196,32 @@ func (c *LMCache) Stats() CacheStats {
 	}
 }
 
+// cacheKeyVersion is mixed into every cache key. Bump it whenever prompt
+// assembly or adapter formatting changes so previously cached responses for
+// the old format are no longer hit.
+const cacheKeyVersion byte = 1
+
 // GenerateCacheKey creates a deterministic cache key from LM request parameters
 //
 // Cache key components (all affect cache key generation):
+//   - Cache key version (invalidates entries across format changes)
 //   - LM name (model identifier)
-//   - Messages (conversation history)
+//   - Messages (the fully assembled prompt: adapter instructions, demos,
+//     history and inputs, including tool calls and images)
 //   - Temperature, MaxTokens, TopP (generation parameters)
 //   - ResponseFormat, ResponseSchema (output format)
 //   - Stop sequences (canonicalized/sorted)
 //   - Tools and ToolChoice (function calling)
 //   - FrequencyPenalty, PresencePenalty (repetition controls)
+//   - LogitBias (token biases, when set)
+//   - ReasoningEffort, ThinkingBudgetTokens (reasoning controls, when set)
 //
 // Maps (ResponseSchema, Tool.Parameters) are canonicalized to ensure
 // deterministic key generation regardless of insertion order.
 func GenerateCacheKey(lmName string, messages []Message, options *GenerateOptions) string {
 	// Build a deterministic representation
 	keyData := struct {
+		Version          byte
 		LMName           string
 		Messages         []Message
 		Temperature      float64
@@ -224,7 +234,11 @@ func GenerateCacheKey(lmName string, messages []Message, options *GenerateOption
 		ToolChoice       string
 		FrequencyPenalty float64
 		PresencePenalty  float64
+		LogitBias        map[int]int `json:",omitempty"` // Omitted when unset, keeping existing keys stable
+		ReasoningEffort  string      `json:",omitempty"`
+		ThinkingBudget   int         `json:",omitempty"`
 	}{
+		Version:          cacheKeyVersion,
 		LMName:           lmName,
 		Messages:         messages,
 		Temperature:      options.Temperature,
@@ -234,6 +248,9 @@ func GenerateCacheKey(lmName string, messages []Message, options *GenerateOption
 		ToolChoice:       options.ToolChoice,
 		FrequencyPenalty: options.FrequencyPenalty,
 		PresencePenalty:  options.PresencePenalty,
+		LogitBias:        options.LogitBias,
+		ReasoningEffort:  options.ReasoningEffort,
+		ThinkingBudget:   options.ThinkingBudgetTokens,
 	}
 
 	// Sort stop sequences for determinism
@@ -267,8 +284,9 @@ func GenerateCacheKey(lmName string, messages []Message, options *GenerateOption
 	// Serialize to JSON
 	data, err := json.Marshal(keyData)
 	if err != nil {
-		// Fallback to simple key if marshaling fails
-		return fmt.Sprintf("%s:%d", lmName, len(messages))
+		// Fall back to the printed form so the key still covers the full prompt;
+		// unmarshalable values (e.g. channels) print by identity, so at worst this misses
+		data = fmt.Appendf(nil, "%+v", keyData)
 	}
 
 	// Hash the JSON to create a compact key
@@ -330,6 +348,7 @@ func deepCopyResult(r *GenerateResult) *GenerateResult {
 
 	result := &GenerateResult{
 		Content:      r.Content,
+		Reasoning:    r.Reasoning,
 		FinishReason: r.FinishReason,
 		Usage:        r.Usage, // Usage is a value type, automatically copied
 	}
@@ -563,6 +563,35 @@ func TestGenerateCacheKey_Penalties(t *testing.T) {
 	}
 }
 
+// TestGenerateCacheKey_LogitBias tests cache key generation with logit bias
+func TestGenerateCacheKey_LogitBias(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "test"}}
+
+	options1 := DefaultGenerateOptions()
+	options2 := DefaultGenerateOptions()
+	options2.LogitBias = map[int]int{42: 5}
+
+	if GenerateCacheKey("gpt-4", messages, options1) == GenerateCacheKey("gpt-4", messages, options2) {
+		t.Error("Expected different keys for different logit biases")
+	}
+}
+
+// TestGenerateCacheKey_Reasoning tests cache key generation with reasoning controls
+func TestGenerateCacheKey_Reasoning(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "test"}}
+
+	options1 := DefaultGenerateOptions()
+	options2 := DefaultGenerateOptions()
+	options2.ReasoningEffort = ReasoningEffortHigh
+	options3 := DefaultGenerateOptions()
+	options3.ThinkingBudgetTokens = 1024
+
+	key1 := GenerateCacheKey("o3", messages, options1)
+	if key1 == GenerateCacheKey("o3", messages, options2) || key1 == GenerateCacheKey("o3", messages, options3) {
+		t.Error("Expected different keys for different reasoning controls")
+	}
+}
+
 // TestGenerateCacheKey_ResponseSchema tests cache key generation with response schema
 func TestGenerateCacheKey_ResponseSchema(t *testing.T) {
 	messages := []Message{{Role: "user", Content: "test"}}
@@ -1040,11 +1069,14 @@ func TestGenerateCacheKey_MarshalError(t *testing.T) {
 
 	// This should trigger the error path in GenerateCacheKey and use the fallback
 	key := GenerateCacheKey("gpt-4", messages, options)
+	if key != GenerateCacheKey("gpt-4", messages, options) {
+		t.Error("fallback key should be deterministic for the same request")
+	}
 
-	// The fallback key format is "lmName:messageCount"
-	expectedFallback := "gpt-4:1"
-	if key != expectedFallback {
-		t.Errorf("Expected fallback key '%s', got '%s'", expectedFallback, key)
+	// The fallback must still cover message content, not just the message count
+	other := []Message{{Role: "assistant", Content: "different", ToolCalls: messages[0].ToolCalls}}
+	if key == GenerateCacheKey("gpt-4", other, options) {
+		t.Error("fallback key should differ when message content differs")
 	}
 }
 
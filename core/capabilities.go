@@ -0,0 +1,46 @@
+package core
+
+import "errors"
+
+// ErrToolsUnsupported is returned when tools are required but the model cannot call them
+var ErrToolsUnsupported = errors.New("model does not support tool calling")
+
+// Capabilities describes what a model can do, so callers can pick modules and
+// request formats at runtime instead of hardcoding per-model knowledge
+type Capabilities struct {
+	SupportsTools      bool // Native tool/function calling
+	SupportsJSON       bool // JSON mode (response_format json_object)
+	SupportsJSONSchema bool // Structured outputs constrained to a JSON schema
+	SupportsVision     bool // Image inputs
+	SupportsStreaming  bool // Incremental Stream output
+	MaxContextTokens   int  // Context window in tokens (0 = unknown)
+}
+
+// CapableLM is implemented by LMs that report their capabilities
+// Providers and the built-in wrappers implement it; use CapabilitiesOf for any LM.
+type CapableLM interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns lm's capabilities
+// LMs that do not implement CapableLM are described from SupportsTools and
+// SupportsJSON, assuming JSON-mode models accept a schema, with vision from
+// IsVisionModel. A missing MaxContextTokens is filled from the context window registry.
+func CapabilitiesOf(lm LM) Capabilities {
+	var caps Capabilities
+	if capable, ok := lm.(CapableLM); ok {
+		caps = capable.Capabilities()
+	} else {
+		caps = Capabilities{
+			SupportsTools:      lm.SupportsTools(),
+			SupportsJSON:       lm.SupportsJSON(),
+			SupportsJSONSchema: lm.SupportsJSON(),
+			SupportsVision:     IsVisionModel(lm.Name()),
+			SupportsStreaming:  true,
+		}
+	}
+	if caps.MaxContextTokens == 0 {
+		caps.MaxContextTokens, _ = ContextWindowFor(lm.Name())
+	}
+	return caps
+}
@@ -0,0 +1,93 @@
+package core
+
+import "testing"
+
+// capableMockLM reports fixed capabilities
+type capableMockLM struct {
+	mockWrapperLM
+	caps Capabilities
+}
+
+func (m *capableMockLM) Capabilities() Capabilities {
+	return m.caps
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		name string
+		lm   LM
+		want Capabilities
+	}{
+		{
+			name: "derived from Supports methods",
+			lm:   &mockWrapperLM{name: "gpt-4o", supportsJSON: true, supportsTools: true},
+			want: Capabilities{
+				SupportsTools:      true,
+				SupportsJSON:       true,
+				SupportsJSONSchema: true,
+				SupportsVision:     true,
+				SupportsStreaming:  true,
+				MaxContextTokens:   128000,
+			},
+		},
+		{
+			name: "unknown model without JSON",
+			lm:   &mockWrapperLM{name: "acme-text"},
+			want: Capabilities{SupportsStreaming: true},
+		},
+		{
+			name: "reported capabilities win",
+			lm: &capableMockLM{
+				mockWrapperLM: mockWrapperLM{name: "gpt-4", supportsJSON: true, supportsTools: true},
+				caps:          Capabilities{SupportsTools: true, SupportsJSON: true},
+			},
+			// The registry fills in the missing context window
+			want: Capabilities{SupportsTools: true, SupportsJSON: true, MaxContextTokens: 8192},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CapabilitiesOf(tt.lm); got != tt.want {
+				t.Errorf("CapabilitiesOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilities_Wrappers(t *testing.T) {
+	inner := &capableMockLM{
+		mockWrapperLM: mockWrapperLM{name: "acme"},
+		caps:          Capabilities{SupportsTools: true, SupportsVision: true, MaxContextTokens: 1000},
+	}
+
+	wrappers := map[string]LM{
+		"LMWrapper":      NewLMWrapper(inner, NewMemoryCollector(10)),
+		"RateLimitedLM":  NewRateLimitedLM(inner, NewRateLimiter(10, 1)),
+		"MiddlewareLM":   NewMiddlewareLM(inner),
+		"HedgedLM":       NewHedgedLM(inner, 0),
+		"CircuitBreaker": NewCircuitBreaker(inner, CircuitConfig{}),
+	}
+	for name, lm := range wrappers {
+		if got := CapabilitiesOf(lm); got != inner.caps {
+			t.Errorf("%s: CapabilitiesOf() = %+v, want %+v", name, got, inner.caps)
+		}
+	}
+}
+
+func TestFallbackLM_CapabilitiesIntersect(t *testing.T) {
+	primary := &capableMockLM{
+		mockWrapperLM: mockWrapperLM{name: "primary"},
+		caps:          Capabilities{SupportsTools: true, SupportsVision: true, SupportsStreaming: true, MaxContextTokens: 128000},
+	}
+	fallback := &capableMockLM{
+		mockWrapperLM: mockWrapperLM{name: "fallback"},
+		caps:          Capabilities{SupportsTools: true, SupportsStreaming: true, MaxContextTokens: 8000},
+	}
+
+	got := CapabilitiesOf(NewFallbackLM(primary, fallback))
+	want := Capabilities{SupportsTools: true, SupportsStreaming: true, MaxContextTokens: 8000}
+	if got != want {
+		t.Errorf("CapabilitiesOf() = %+v, want %+v", got, want)
+	}
+}
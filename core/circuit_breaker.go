@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a CircuitBreaker rejects a call without reaching the LM
+// It is fallback-worthy, so an open breaker inside a FallbackLM moves on to the next LM
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed passes calls through and counts consecutive failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fast-fails calls until ResetTimeout has elapsed
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe calls through to test recovery
+	CircuitHalfOpen
+)
+
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitConfig configures a CircuitBreaker
+type CircuitConfig struct {
+	FailureThreshold int           // Consecutive failures before opening (default 5)
+	ResetTimeout     time.Duration // How long to stay open before probing (default 30s)
+	HalfOpenProbes   int           // Successful probes needed to close again, also the max concurrent probes (default 1)
+
+	// IsFailure classifies errors that count towards opening (default IsFallbackError)
+	// Other errors, such as bad requests, show the LM is reachable and count as successes
+	IsFailure func(err error) bool
+
+	// OnStateChange is called after every state transition, e.g. for alerting
+	OnStateChange func(lm string, from, to CircuitState)
+}
+
+// CircuitBreaker wraps an LM and stops calling it after repeated failures
+// After FailureThreshold consecutive failures the circuit opens and calls fail fast
+// with ErrCircuitOpen. Once ResetTimeout has elapsed it half-opens and lets probe
+// calls through; HalfOpenProbes successes close it again, while any probe failure
+// re-opens it for another cooldown.
+type CircuitBreaker struct {
+	lm     LM
+	config CircuitConfig
+	now    func() time.Time // Overridable for tests
+
+	mu             sync.Mutex
+	state          CircuitState
+	failures       int
+	openedAt       time.Time
+	probesInFlight int
+	probeSuccesses int
+	generation     int // Incremented on every transition so stale probes are ignored
+}
+
+// NewCircuitBreaker wraps lm with a circuit breaker; zero config fields use defaults
+func NewCircuitBreaker(lm LM, config CircuitConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.ResetTimeout <= 0 {
+		config.ResetTimeout = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = IsFallbackError
+	}
+	return &CircuitBreaker{
+		lm:     lm,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// State returns the current state, moving from open to half-open once the cooldown has elapsed
+func (c *CircuitBreaker) State() CircuitState {
+	c.mu.Lock()
+	transition := c.checkCooldown()
+	state := c.state
+	c.mu.Unlock()
+
+	c.notify(transition)
+	return state
+}
+
+// Generate calls the wrapped LM unless the circuit is open
+func (c *CircuitBreaker) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	call, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.lm.Generate(ctx, messages, options)
+	c.record(ctx, call, err)
+	return result, err
+}
+
+// Stream calls the wrapped LM unless the circuit is open
+// The outcome is recorded once the underlying stream has finished
+func (c *CircuitBreaker) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	outChunkChan := make(chan Chunk)
+	outErrChan := make(chan error, 1)
+
+	go func() {
+		defer close(outChunkChan)
+		defer close(outErrChan)
+
+		call, err := c.acquire()
+		if err != nil {
+			outErrChan <- err
+			return
+		}
+
+		chunkChan, errChan := c.lm.Stream(ctx, messages, options)
+		_, err = forwardStream(ctx, chunkChan, errChan, outChunkChan, func() {})
+		c.record(ctx, call, err)
+		if err != nil {
+			outErrChan <- err
+		}
+	}()
+
+	return outChunkChan, outErrChan
+}
+
+// Name returns the wrapped LM's name
+func (c *CircuitBreaker) Name() string {
+	return c.lm.Name()
+}
+
+// SupportsJSON delegates to the wrapped LM
+func (c *CircuitBreaker) SupportsJSON() bool {
+	return c.lm.SupportsJSON()
+}
+
+// SupportsTools delegates to the wrapped LM
+func (c *CircuitBreaker) SupportsTools() bool {
+	return c.lm.SupportsTools()
+}
+
+// Capabilities reports the wrapped LM's capabilities
+func (c *CircuitBreaker) Capabilities() Capabilities {
+	return CapabilitiesOf(c.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (c *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, c.lm)
+}
+
+// circuitTransition records a state change to report once the lock is released
+type circuitTransition struct {
+	from, to CircuitState
+}
+
+// circuitCall identifies an admitted call so its outcome can be attributed correctly
+type circuitCall struct {
+	probe      bool
+	generation int
+}
+
+// acquire decides whether a call may proceed and whether it is a half-open probe
+func (c *CircuitBreaker) acquire() (circuitCall, error) {
+	c.mu.Lock()
+	transition := c.checkCooldown()
+
+	call := circuitCall{generation: c.generation}
+	var err error
+	switch c.state {
+	case CircuitOpen:
+		remaining := c.config.ResetTimeout - c.now().Sub(c.openedAt)
+		err = fmt.Errorf("%w: %s (retry in %s)", ErrCircuitOpen, c.lm.Name(), remaining.Round(time.Millisecond))
+	case CircuitHalfOpen:
+		if c.probesInFlight >= c.config.HalfOpenProbes {
+			err = fmt.Errorf("%w: %s (recovery probe in progress)", ErrCircuitOpen, c.lm.Name())
+		} else {
+			c.probesInFlight++
+			call.probe = true
+		}
+	}
+	c.mu.Unlock()
+
+	c.notify(transition)
+	return call, err
+}
+
+// record updates the breaker with the outcome of a call
+// Outcomes of calls admitted before the last transition are ignored
+func (c *CircuitBreaker) record(ctx context.Context, call circuitCall, err error) {
+	c.mu.Lock()
+	var transition *circuitTransition
+
+	current := call.generation == c.generation
+	probe := call.probe && current
+	if probe {
+		c.probesInFlight--
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// Caller cancellation says nothing about the LM's health
+	case err != nil && c.config.IsFailure(err):
+		switch {
+		case c.state == CircuitClosed && current:
+			c.failures++
+			if c.failures >= c.config.FailureThreshold {
+				transition = c.setState(CircuitOpen)
+			}
+		case probe:
+			transition = c.setState(CircuitOpen)
+		}
+	default:
+		switch {
+		case c.state == CircuitClosed && current:
+			c.failures = 0
+		case probe:
+			c.probeSuccesses++
+			if c.probeSuccesses >= c.config.HalfOpenProbes {
+				transition = c.setState(CircuitClosed)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.notify(transition)
+}
+
+// checkCooldown moves an open circuit to half-open once ResetTimeout has elapsed
+// The caller must hold c.mu
+func (c *CircuitBreaker) checkCooldown() *circuitTransition {
+	if c.state == CircuitOpen && c.now().Sub(c.openedAt) >= c.config.ResetTimeout {
+		return c.setState(CircuitHalfOpen)
+	}
+	return nil
+}
+
+// setState switches state and resets the counters for the new state
+// The caller must hold c.mu
+func (c *CircuitBreaker) setState(to CircuitState) *circuitTransition {
+	from := c.state
+	c.state = to
+	c.failures = 0
+	c.probesInFlight = 0
+	c.probeSuccesses = 0
+	c.generation++
+	if to == CircuitOpen {
+		c.openedAt = c.now()
+	}
+	return &circuitTransition{from: from, to: to}
+}
+
+// notify invokes OnStateChange outside the lock so callbacks may inspect the breaker
+func (c *CircuitBreaker) notify(transition *circuitTransition) {
+	if transition != nil && c.config.OnStateChange != nil {
+		c.config.OnStateChange(c.lm.Name(), transition.from, transition.to)
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyLM fails while failing is set, counting calls that reach it
+type flakyLM struct {
+	mockWrapperLM
+	failing bool
+	err     error
+	calls   int
+}
+
+func newFlakyLM(err error) *flakyLM {
+	f := &flakyLM{failing: true, err: err}
+	f.name = "flaky"
+	f.generateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+		f.calls++
+		if f.failing {
+			return nil, f.err
+		}
+		return &GenerateResult{Content: "ok"}, nil
+	}
+	return f
+}
+
+func TestCircuitBreaker_Lifecycle(t *testing.T) {
+	lm := newFlakyLM(errors.New("API request failed with status 503: unavailable"))
+	now := time.Unix(0, 0)
+	var transitions []string
+
+	cb := NewCircuitBreaker(lm, CircuitConfig{
+		FailureThreshold: 3,
+		ResetTimeout:     time.Minute,
+		HalfOpenProbes:   2,
+		OnStateChange: func(name string, from, to CircuitState) {
+			transitions = append(transitions, name+":"+from.String()+"->"+to.String())
+		},
+	})
+	cb.now = func() time.Time { return now }
+
+	call := func() error {
+		_, err := cb.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, DefaultGenerateOptions())
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := call(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected LM error, got %v", i, err)
+		}
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("State() = %s, want open after threshold", cb.State())
+	}
+
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected fast failure while open, got %v", err)
+	}
+	if lm.calls != 3 {
+		t.Errorf("open circuit should not call the LM, calls = %d", lm.calls)
+	}
+
+	// A failed probe re-opens the circuit for another cooldown
+	now = now.Add(time.Minute)
+	if err := call(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected probe to reach the LM, got %v", err)
+	}
+	if cb.State() != CircuitOpen {
+		t.Errorf("State() = %s, want open after failed probe", cb.State())
+	}
+
+	// Two successful probes close it again
+	now = now.Add(time.Minute)
+	lm.failing = false
+	if err := call(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if cb.State() != CircuitHalfOpen {
+		t.Errorf("State() = %s, want half-open after one of two probes", cb.State())
+	}
+	if err := call(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("State() = %s, want closed", cb.State())
+	}
+
+	want := []string{
+		"flaky:closed->open",
+		"flaky:open->half-open",
+		"flaky:half-open->open",
+		"flaky:open->half-open",
+		"flaky:half-open->closed",
+	}
+	if strings.Join(transitions, ",") != strings.Join(want, ",") {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreaker_FailureClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantOpen bool
+	}{
+		{"server errors open the circuit", errors.New("API request failed with status 500: boom"), true},
+		{"client errors do not", errors.New("API request failed with status 400: bad request"), false},
+		{"cancellation does not", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewCircuitBreaker(newFlakyLM(tt.err), CircuitConfig{FailureThreshold: 2})
+			for i := 0; i < 2; i++ {
+				_, _ = cb.Generate(context.Background(), nil, DefaultGenerateOptions())
+			}
+			if got := cb.State() == CircuitOpen; got != tt.wantOpen {
+				t.Errorf("open = %v, want %v", got, tt.wantOpen)
+			}
+		})
+	}
+
+	t.Run("successes reset the count", func(t *testing.T) {
+		lm := newFlakyLM(errors.New("request failed: connection refused"))
+		cb := NewCircuitBreaker(lm, CircuitConfig{FailureThreshold: 2})
+		_, _ = cb.Generate(context.Background(), nil, DefaultGenerateOptions())
+		lm.failing = false
+		_, _ = cb.Generate(context.Background(), nil, DefaultGenerateOptions())
+		lm.failing = true
+		_, _ = cb.Generate(context.Background(), nil, DefaultGenerateOptions())
+		if cb.State() != CircuitClosed {
+			t.Errorf("State() = %s, want closed", cb.State())
+		}
+	})
+}
+
+func TestCircuitBreaker_ComposesWithFallbackLM(t *testing.T) {
+	primary := newFlakyLM(errors.New("API request failed with status 503: down"))
+	breaker := NewCircuitBreaker(primary, CircuitConfig{FailureThreshold: 1, ResetTimeout: time.Hour})
+	secondary := &mockWrapperLM{
+		name: "secondary",
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			return &GenerateResult{Content: "from secondary"}, nil
+		},
+	}
+	lm := NewFallbackLM(breaker, secondary)
+
+	for i := 0; i < 3; i++ {
+		result, err := lm.Generate(context.Background(), nil, DefaultGenerateOptions())
+		if err != nil || result.Content != "from secondary" {
+			t.Fatalf("call %d: got %v, %v", i, result, err)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("expected the open breaker to skip the primary, calls = %d", primary.calls)
+	}
+}
+
+func TestCircuitBreaker_Stream(t *testing.T) {
+	lm := &mockStreamLM{
+		mockWrapperLM: mockWrapperLM{name: "streamer"},
+		chunks:        []string{"a"},
+		streamErr:     errors.New("API request failed with status 502: bad gateway"),
+	}
+	cb := NewCircuitBreaker(lm, CircuitConfig{FailureThreshold: 1})
+
+	chunks, errs := cb.Stream(context.Background(), nil, DefaultGenerateOptions())
+	for range chunks {
+	}
+	if err := <-errs; err == nil {
+		t.Fatal("expected stream error")
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("State() = %s, want open", cb.State())
+	}
+
+	chunks, errs = cb.Stream(context.Background(), nil, DefaultGenerateOptions())
+	for range chunks {
+		t.Error("open circuit should not stream")
+	}
+	if err := <-errs; !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -41,6 +42,11 @@ func (c *MemoryCollector) Collect(entry *HistoryEntry) error {
 	return nil
 }
 
+// Flush is a no-op for memory collector
+func (c *MemoryCollector) Flush(ctx context.Context) error {
+	return nil
+}
+
 // Close is a no-op for memory collector
 func (c *MemoryCollector) Close() error {
 	return nil
@@ -104,6 +110,20 @@ func (c *MemoryCollector) GetLast(n int) []*HistoryEntry {
 	return result
 }
 
+// GetByTag returns all entries whose tag key equals value (oldest first)
+func (c *MemoryCollector) GetByTag(key, value string) []*HistoryEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*HistoryEntry
+	for _, entry := range c.getAllUnsafe() {
+		if tag, ok := entry.Tags[key]; ok && tag == value {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
 // getAllUnsafe returns all entries without locking (helper method)
 func (c *MemoryCollector) getAllUnsafe() []*HistoryEntry {
 	if c.count < int64(c.size) {
@@ -198,6 +218,21 @@ func (c *JSONLCollector) Collect(entry *HistoryEntry) error {
 	return nil
 }
 
+// Flush syncs the JSONL file to disk
+// Entries are written unbuffered, so Collect has already handed them to the OS.
+func (c *JSONLCollector) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync JSONL file: %w", err)
+	}
+	return nil
+}
+
 // Close closes the JSONL file
 func (c *JSONLCollector) Close() error {
 	c.mu.Lock()
@@ -222,8 +257,12 @@ func (c *JSONLCollector) Path() string {
 }
 
 // CompositeCollector sends history entries to multiple collectors
+// A failing or panicking sink never stops the others or the LM call: Collect and
+// Flush swallow sink errors, reporting them to the error handler if one is set.
 type CompositeCollector struct {
+	mu         sync.RWMutex
 	collectors []Collector
+	onError    func(collector Collector, err error)
 }
 
 // NewCompositeCollector creates a new composite collector
@@ -233,22 +272,47 @@ func NewCompositeCollector(collectors ...Collector) *CompositeCollector {
 	}
 }
 
-// Collect sends the entry to all collectors
+// WithErrorHandler sets a function called with each sink error or recovered panic
+func (c *CompositeCollector) WithErrorHandler(onError func(collector Collector, err error)) *CompositeCollector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onError = onError
+	return c
+}
+
+// Collect sends the entry to all collectors; sink errors are swallowed
 func (c *CompositeCollector) Collect(entry *HistoryEntry) error {
-	var firstError error
-	for _, collector := range c.collectors {
-		if err := collector.Collect(entry); err != nil && firstError == nil {
-			firstError = err
+	collectors, onError := c.snapshot()
+	for _, collector := range collectors {
+		err := callSink(func() error { return collector.Collect(entry) })
+		if err != nil && onError != nil {
+			onError(collector, err)
+		}
+	}
+	return nil
+}
+
+// Flush flushes all collectors, stopping early if ctx is done; sink errors are swallowed
+func (c *CompositeCollector) Flush(ctx context.Context) error {
+	collectors, onError := c.snapshot()
+	for _, collector := range collectors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := callSink(func() error { return collector.Flush(ctx) })
+		if err != nil && onError != nil {
+			onError(collector, err)
 		}
 	}
-	return firstError
+	return nil
 }
 
 // Close closes all collectors
 func (c *CompositeCollector) Close() error {
+	collectors, _ := c.snapshot()
 	var errs []error
-	for _, collector := range c.collectors {
-		if err := collector.Close(); err != nil {
+	for _, collector := range collectors {
+		if err := callSink(collector.Close); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -260,10 +324,31 @@ func (c *CompositeCollector) Close() error {
 
 // Add adds a collector to the composite
 func (c *CompositeCollector) Add(collector Collector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.collectors = append(c.collectors, collector)
 }
 
 // Len returns the number of collectors
 func (c *CompositeCollector) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return len(c.collectors)
 }
+
+// snapshot returns the current sinks and error handler
+func (c *CompositeCollector) snapshot() ([]Collector, func(Collector, error)) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Collector(nil), c.collectors...), c.onError
+}
+
+// callSink runs fn, turning a panic into an error
+func callSink(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("collector panicked: %v", r)
+		}
+	}()
+	return fn()
+}
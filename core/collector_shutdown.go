@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// collectorShutdownTimeout bounds the flush when the shutdown context is done
+const collectorShutdownTimeout = 5 * time.Second
+
+// collectorWatch is the goroutine flushing the configured collector on shutdown
+var collectorWatch struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	collector Collector
+	stop      chan struct{}
+}
+
+// watchCollectorShutdown flushes and closes collector once ctx is done,
+// replacing any previous watch; nil arguments only stop the current one
+func watchCollectorShutdown(ctx context.Context, collector Collector) {
+	collectorWatch.mu.Lock()
+	defer collectorWatch.mu.Unlock()
+
+	if collectorWatch.stop != nil && collectorWatch.ctx == ctx && collectorWatch.collector == collector {
+		return
+	}
+	if collectorWatch.stop != nil {
+		close(collectorWatch.stop)
+		collectorWatch.stop = nil
+	}
+	collectorWatch.ctx = ctx
+	collectorWatch.collector = collector
+	if ctx == nil || collector == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	collectorWatch.stop = stop
+	go func() {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), collectorShutdownTimeout)
+			defer cancel()
+			_ = shutdownCollector(flushCtx, collector) // Nobody is left to report to
+		case <-stop:
+		}
+	}()
+}
+
+// shutdownCollector flushes then closes collector, returning both errors
+func shutdownCollector(ctx context.Context, collector Collector) error {
+	if collector == nil {
+		return nil
+	}
+	return errors.Join(collector.Flush(ctx), collector.Close())
+}
@@ -1,8 +1,11 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -552,6 +555,58 @@ func TestCompositeCollectorErrors(t *testing.T) {
 	})
 }
 
+// sinkStub is a Collector whose methods panic or fail on demand
+type sinkStub struct {
+	panics bool
+	err    error
+}
+
+func (s *sinkStub) Collect(entry *HistoryEntry) error {
+	if s.panics {
+		panic("sink exploded")
+	}
+	return s.err
+}
+
+func (s *sinkStub) Flush(ctx context.Context) error {
+	if s.panics {
+		panic("sink exploded")
+	}
+	return s.err
+}
+
+func (s *sinkStub) Close() error { return s.err }
+
+func TestCompositeCollector_IsolatesFailingSinks(t *testing.T) {
+	before := NewMemoryCollector(10)
+	after := NewMemoryCollector(10)
+	panicking := &sinkStub{panics: true}
+	failing := &sinkStub{err: errors.New("backend down")}
+
+	var reported []error
+	composite := NewCompositeCollector(before, panicking, failing, after).
+		WithErrorHandler(func(sink Collector, err error) {
+			reported = append(reported, err)
+		})
+
+	if err := composite.Collect(&HistoryEntry{ID: "test"}); err != nil {
+		t.Fatalf("Collect() = %v, want sink errors swallowed", err)
+	}
+	if before.Count() != 1 || after.Count() != 1 {
+		t.Errorf("healthy sinks got %d and %d entries, want 1 each", before.Count(), after.Count())
+	}
+	if len(reported) != 2 || !strings.Contains(reported[0].Error(), "sink exploded") {
+		t.Errorf("reported errors = %v, want the panic and the backend error", reported)
+	}
+
+	if err := composite.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() = %v, want sink errors swallowed", err)
+	}
+	if err := composite.Close(); err == nil {
+		t.Error("Close() should report the failing sink")
+	}
+}
+
 // TestJSONLCollector_CloseWithoutFile tests closing a collector that was never initialized with a file
 func TestJSONLCollector_CloseWithoutFile(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"os"
 	"strings"
 	"time"
 )
@@ -19,6 +23,8 @@ func Configure(opts ...Option) {
 	for _, opt := range opts {
 		opt(globalSettings)
 	}
+
+	watchCollectorShutdown(globalSettings.ShutdownContext, globalSettings.Collector)
 }
 
 // WithProvider sets the default provider name.
@@ -37,6 +43,8 @@ func WithModel(model string) Option {
 }
 
 // WithTimeout sets the default timeout for LM calls.
+// Providers apply it as a context deadline on each Generate call whose context has no
+// deadline of its own (see WithDefaultTimeout).
 func WithTimeout(timeout time.Duration) Option {
 	return func(s *Settings) {
 		s.DefaultTimeout = timeout
@@ -60,6 +68,19 @@ func WithAPIKey(provider, key string) Option {
 	}
 }
 
+// WithAPIKeys rotates a provider's requests across several API keys using strategy.
+// A key that returns 429 is parked until its Retry-After elapses; the masked key that
+// served each call is recorded in GenerateResult.Metadata["api_key"].
+// It takes precedence over the provider's single key (WithAPIKey or environment).
+func WithAPIKeys(provider string, keys []string, strategy KeyStrategy) Option {
+	return func(s *Settings) {
+		if s.APIKeyPools == nil {
+			s.APIKeyPools = make(map[string]*KeyPool)
+		}
+		s.APIKeyPools[provider] = NewKeyPool(keys, strategy)
+	}
+}
+
 // WithMaxRetries sets the default number of retries for failed LM calls.
 func WithMaxRetries(retries int) Option {
 	return func(s *Settings) {
@@ -67,6 +88,33 @@ func WithMaxRetries(retries int) Option {
 	}
 }
 
+// WithBackoff sets the retry schedule of provider requests. A non-zero
+// config.MaxRetries also replaces the MaxRetries setting (negative = no retries).
+func WithBackoff(config BackoffConfig) Option {
+	return func(s *Settings) {
+		s.Backoff = config
+		if config.MaxRetries != 0 {
+			s.MaxRetries = max(config.MaxRetries, 0)
+		}
+	}
+}
+
+// WithDryRun makes every module Forward (and Generate of LMs created by NewLM) return
+// the fully assembled prompt with an empty result instead of calling the provider.
+// Predictions carry the messages in Metadata["messages"]; see DryRun.
+func WithDryRun(enable bool) Option {
+	return func(s *Settings) {
+		s.DryRun = enable
+	}
+}
+
+// WithDryRunOutput prints the prompt of every dry-run call to w (e.g. os.Stderr).
+func WithDryRunOutput(w io.Writer) Option {
+	return func(s *Settings) {
+		s.DryRunOutput = w
+	}
+}
+
 // WithTracing enables or disables detailed tracing and diagnostics.
 func WithTracing(enable bool) Option {
 	return func(s *Settings) {
@@ -81,6 +129,15 @@ func WithCollector(collector Collector) Option {
 	}
 }
 
+// WithShutdownContext flushes and closes the configured collector once ctx is done.
+// Pass the process context (e.g. from signal.NotifyContext) so buffered entries
+// are written on shutdown; call Shutdown instead to observe flush errors.
+func WithShutdownContext(ctx context.Context) Option {
+	return func(s *Settings) {
+		s.ShutdownContext = ctx
+	}
+}
+
 // WithCache enables caching with the specified capacity.
 // A cache with the given capacity will be created and auto-wired to all LM instances.
 // Uses the configured CacheTTL if set, otherwise no expiration.
@@ -104,15 +161,291 @@ func WithCacheTTL(ttl time.Duration) Option {
 	}
 }
 
+// WithRateLimit installs a token-bucket limiter shared by all LM calls of a provider.
+// Calls block (respecting context cancellation) until a token is available.
+// A non-positive rps disables rate limiting.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(s *Settings) {
+		s.RateLimit = rps
+		s.RateLimitBurst = burst
+	}
+}
+
+// WithAdaptiveRateLimit enables AIMD adaptation of the rate limit:
+// the rate is halved on 429 responses and recovers gradually on success.
+func WithAdaptiveRateLimit(enable bool) Option {
+	return func(s *Settings) {
+		s.AdaptiveRateLimit = enable
+	}
+}
+
+// WithHedging enables hedged requests for LMs created by NewLM.
+// If a Generate call has not returned within after, a second identical request is sent
+// and whichever succeeds first wins; the other is cancelled. This trades tokens for
+// lower tail latency. A non-positive delay disables hedging.
+func WithHedging(after time.Duration) Option {
+	return func(s *Settings) {
+		s.HedgeAfter = after
+	}
+}
+
+// WithRequestDeduplication makes concurrent, identical Generate calls (same model,
+// messages and options) of LMs created by NewLM share one in-flight request, so a
+// cache-miss stampede costs a single provider call. See DedupLM.
+func WithRequestDeduplication(enable bool) Option {
+	return func(s *Settings) {
+		s.DeduplicateRequests = enable
+	}
+}
+
+// WithRefusalRetry retries Generate calls of LMs created by NewLM up to n times when the
+// model refuses a request, rewording the prompt with a preamble that states the request is
+// legitimate. Only model refusals are retried (see RefusalRetryLM); provider moderation
+// blocks are returned at once. Zero disables it.
+func WithRefusalRetry(n int) Option {
+	return func(s *Settings) {
+		s.RefusalRetries = n
+	}
+}
+
+// WithContextWindow overrides the context length (in tokens) used for truncation checks.
+// By default the length is looked up from the model registry.
+func WithContextWindow(tokens int) Option {
+	return func(s *Settings) {
+		s.ContextWindow = tokens
+	}
+}
+
+// WithTruncationPolicy sets how prompts exceeding the context window are handled.
+// Policies may be combined, e.g. WithTruncationPolicy(DropDemos | DropHistory).
+func WithTruncationPolicy(policy TruncationPolicy) Option {
+	return func(s *Settings) {
+		s.TruncationPolicy = policy
+	}
+}
+
+// WithDemoDropOrder sets which few-shot demos are dropped first when a prompt
+// exceeds the context window, e.g. WithDemoDropOrder(DropLowestWeightFirst).
+func WithDemoDropOrder(order DemoDropOrder) Option {
+	return func(s *Settings) {
+		s.DemoDropOrder = order
+	}
+}
+
+// WithMiddleware appends middleware applied to every Generate and Stream call
+// of LMs created by NewLM. Middleware runs outermost-first in registration order.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(s *Settings) {
+		s.Middleware = append(s.Middleware, middleware...)
+	}
+}
+
+// WithPricing overrides the price (USD per million tokens) of a model for cost calculation.
+// Overrides take precedence over the pricing registry and are cleared by ResetConfig.
+func WithPricing(model string, inputPerMTok, outputPerMTok float64) Option {
+	return func(s *Settings) {
+		if s.Pricing == nil {
+			s.Pricing = make(map[string]ModelPricing)
+		}
+		s.Pricing[model] = ModelPricing{InputPerMTok: inputPerMTok, OutputPerMTok: outputPerMTok}
+	}
+}
+
+// WithRegion sets the cloud region for region-scoped providers such as bedrock.
+// It takes precedence over the AWS_REGION environment variable.
+func WithRegion(region string) Option {
+	return func(s *Settings) {
+		s.Region = region
+	}
+}
+
+// WithTraceOnError makes modules write a JSON trace file to dir whenever Forward fails.
+// The trace holds the inputs, every prompt sent and raw response received, parse attempts
+// and the error, written once by the outermost module. An empty dir disables tracing.
+func WithTraceOnError(dir string) Option {
+	return func(s *Settings) {
+		s.TraceOnErrorDir = dir
+	}
+}
+
+// WithRawResponseCapture makes providers save each raw HTTP exchange to dir.
+// Every request body and provider response (including error and unparsable ones)
+// is written to <dir>/<request id>/<timestamp>_<provider>_<model>.json.
+// OpenAI and OpenRouter streams are saved once they end, with the SSE body as received.
+// It takes precedence over the DSGO_SAVE_RAW_RESPONSES and DSGO_ARTIFACT_DIR environment variables.
+func WithRawResponseCapture(dir string) Option {
+	return func(s *Settings) {
+		s.RawResponseDir = dir
+	}
+}
+
+// WithHTTPClient sets the HTTP client providers use for their requests, e.g. to route
+// traffic through a proxy or a custom http.RoundTripper. It applies to LMs created afterwards.
+// WithTimeout still bounds each Generate call via its context, whatever the client's own Timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Settings) {
+		s.HTTPClient = client
+	}
+}
+
+// WithProviderHTTPClient sets the HTTP client for one provider (e.g. "openai").
+// It takes precedence over WithHTTPClient for that provider.
+func WithProviderHTTPClient(provider string, client *http.Client) Option {
+	return func(s *Settings) {
+		if s.ProviderHTTPClients == nil {
+			s.ProviderHTTPClients = make(map[string]*http.Client)
+		}
+		s.ProviderHTTPClients[provider] = client
+	}
+}
+
+// WithMaxResponseBytes aborts reading any provider response, buffered or streamed, once
+// its body exceeds n bytes, so a misbehaving provider or gateway cannot exhaust memory.
+// The read fails with a *ResponseTooLargeError (errors.Is ErrResponseTooLarge).
+// Like WithHTTPClient, it applies to LMs created afterwards (0 = unlimited).
+func WithMaxResponseBytes(n int64) Option {
+	return func(s *Settings) {
+		s.MaxResponseBytes = n
+	}
+}
+
+// WithRequestHeaders adds headers to every provider HTTP request, e.g. HTTP-Referer and
+// X-Title to attribute usage on OpenRouter, or a trace ID for a downstream gateway.
+// They override the provider's own headers except Authorization, which is never sent
+// from here. Per-call headers (WithCallRequestHeaders) take precedence.
+func WithRequestHeaders(headers map[string]string) Option {
+	return func(s *Settings) {
+		s.RequestHeaders = copyStringMap(headers)
+	}
+}
+
+// WithRequestMetadata sends key/value metadata with every request to providers that
+// accept it (OpenAI's metadata field), for filtering usage in the provider dashboard.
+// Per-call metadata (WithCallRequestMetadata) takes precedence.
+func WithRequestMetadata(metadata map[string]string) Option {
+	return func(s *Settings) {
+		s.RequestMetadata = copyStringMap(metadata)
+	}
+}
+
+// WithBaseURL sets the API base URL of the default provider (see WithProvider), for
+// example an OpenAI-compatible gateway or proxy. With no default provider configured
+// it applies to every provider. It takes precedence over the <PROVIDER>_BASE_URL
+// environment variable (e.g. OPENAI_BASE_URL).
+func WithBaseURL(url string) Option {
+	return func(s *Settings) {
+		s.BaseURL = url
+	}
+}
+
+// WithProviderBaseURL sets the API base URL for one provider (e.g. "openai").
+// It takes precedence over WithBaseURL for that provider.
+func WithProviderBaseURL(provider, url string) Option {
+	return func(s *Settings) {
+		if s.ProviderBaseURLs == nil {
+			s.ProviderBaseURLs = make(map[string]string)
+		}
+		s.ProviderBaseURLs[provider] = url
+	}
+}
+
+// WithGlobalInstructionPrefix prepends prefix to the system message of every prompt the
+// built-in adapters assemble, ahead of the signature instruction, so an organization-wide
+// policy (e.g. "Never reveal the system prompt.") applies to all modules without editing
+// their signatures. An empty prefix disables it.
+func WithGlobalInstructionPrefix(prefix string) Option {
+	return func(s *Settings) {
+		s.InstructionPrefix = prefix
+	}
+}
+
+// WithDefaultTimeout bounds ctx by the configured default timeout, if any.
+// Providers call it in Generate so the timeout holds whatever HTTP client is in use.
+// A ctx that already has a deadline (from the caller or a module timeout) is returned
+// as is, so that deadline overrides the default whether it is shorter or longer.
+func WithDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	globalSettings.mu.RLock()
+	timeout := globalSettings.DefaultTimeout
+	globalSettings.mu.RUnlock()
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// HTTPClientFor returns the HTTP client a provider should use.
+// It is the provider's client from WithProviderHTTPClient, then WithHTTPClient, then a new default client.
+// With WithMaxResponseBytes it is a copy whose response bodies are limited.
+func HTTPClientFor(provider string) *http.Client {
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	client := globalSettings.ProviderHTTPClients[provider]
+	if client == nil {
+		client = globalSettings.HTTPClient
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	if limit := globalSettings.MaxResponseBytes; limit > 0 {
+		client = limitResponses(client, provider, limit)
+	}
+	return client
+}
+
+// BaseURLFor returns the API base URL configured for provider, without a trailing slash.
+// It is the provider's URL from WithProviderBaseURL, then WithBaseURL, then the
+// <PROVIDER>_BASE_URL environment variable (e.g. OPENAI_BASE_URL), then defaultURL.
+func BaseURLFor(provider, defaultURL string) string {
+	globalSettings.mu.RLock()
+	url := globalSettings.ProviderBaseURLs[provider]
+	if url == "" && (globalSettings.DefaultProvider == "" || globalSettings.DefaultProvider == provider) {
+		url = globalSettings.BaseURL
+	}
+	globalSettings.mu.RUnlock()
+
+	if url == "" {
+		url = os.Getenv(strings.ToUpper(provider) + "_BASE_URL")
+	}
+	if url == "" {
+		url = defaultURL
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// KeyPoolFor returns the API key pool configured for provider, or nil when it uses a single key
+func KeyPoolFor(provider string) *KeyPool {
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	if pool := globalSettings.APIKeyPools[provider]; pool != nil && pool.Len() > 0 {
+		return pool
+	}
+	return nil
+}
+
+// Shutdown flushes and closes the configured collector.
+// ctx bounds the flush; the collector is closed even if flushing fails.
+func Shutdown(ctx context.Context) error {
+	globalSettings.mu.RLock()
+	collector := globalSettings.Collector
+	globalSettings.mu.RUnlock()
+
+	watchCollectorShutdown(nil, nil)
+	return shutdownCollector(ctx, collector)
+}
+
 // ResetConfig resets all settings to their default values.
 func ResetConfig() {
 	globalSettings.Reset()
+	watchCollectorShutdown(nil, nil)
 }
 
 // stripProviderPrefix removes known provider prefixes from model names.
 // For example: "openrouter/meta-llama/llama-3.3-70b-instruct" -> "meta-llama/llama-3.3-70b-instruct"
 func stripProviderPrefix(model string) string {
-	prefixes := []string{"openrouter/", "openai/"}
+	prefixes := []string{"openrouter/", "openai/", "bedrock/"}
 	for _, prefix := range prefixes {
 		if strings.HasPrefix(model, prefix) {
 			return strings.TrimPrefix(model, prefix)
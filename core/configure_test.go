@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -69,6 +71,29 @@ func TestConfigure(t *testing.T) {
 		}
 	})
 
+	t.Run("WithBackoff", func(t *testing.T) {
+		ResetConfig()
+		if got := RetryBackoff(); got != (BackoffConfig{MaxRetries: 3}) {
+			t.Errorf("default RetryBackoff() = %+v", got)
+		}
+
+		config := BackoffConfig{InitialDelay: 2 * time.Second, Multiplier: 1.5, MaxDelay: time.Minute, Jitter: 0.2, MaxRetries: 5}
+		Configure(WithBackoff(config))
+		if got := RetryBackoff(); got != config {
+			t.Errorf("RetryBackoff() = %+v, want %+v", got, config)
+		}
+
+		// Zero MaxRetries keeps the setting; WithMaxRetries(0) turns retries off
+		Configure(WithBackoff(BackoffConfig{InitialDelay: time.Second}))
+		if got := RetryBackoff().MaxRetries; got != 5 {
+			t.Errorf("MaxRetries = %d, want 5 kept", got)
+		}
+		Configure(WithMaxRetries(0))
+		if got := RetryBackoff().MaxRetries; got != -1 {
+			t.Errorf("MaxRetries = %d, want -1 (no retries)", got)
+		}
+	})
+
 	t.Run("WithTracing", func(t *testing.T) {
 		ResetConfig()
 		Configure(WithTracing(true))
@@ -332,6 +357,102 @@ func TestWithCollector(t *testing.T) {
 }
 
 // TestStripProviderPrefix tests the stripProviderPrefix helper function
+func TestHTTPClientFor(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	if client := HTTPClientFor("openai"); client == nil {
+		t.Fatal("expected a default client")
+	}
+
+	global := &http.Client{}
+	openAIClient := &http.Client{}
+	Configure(WithHTTPClient(global), WithProviderHTTPClient("openai", openAIClient))
+
+	if HTTPClientFor("openai") != openAIClient {
+		t.Error("expected the per-provider client for openai")
+	}
+	if HTTPClientFor("openrouter") != global {
+		t.Error("expected the global client for providers without their own")
+	}
+
+	ResetConfig()
+	if HTTPClientFor("openai") == openAIClient {
+		t.Error("expected ResetConfig to clear HTTP clients")
+	}
+}
+
+func TestBaseURLFor(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+	t.Setenv("OPENAI_BASE_URL", "")
+	t.Setenv("OPENROUTER_BASE_URL", "")
+
+	if got := BaseURLFor("openai", "https://api.openai.com/v1"); got != "https://api.openai.com/v1" {
+		t.Errorf("BaseURLFor() = %q, want the default", got)
+	}
+
+	t.Setenv("OPENAI_BASE_URL", "https://env.example/v1/")
+	if got := BaseURLFor("openai", "https://api.openai.com/v1"); got != "https://env.example/v1" {
+		t.Errorf("BaseURLFor() = %q, want OPENAI_BASE_URL without the trailing slash", got)
+	}
+
+	Configure(WithProvider("openai"), WithBaseURL("https://gateway.internal/v1"))
+	if got := BaseURLFor("openai", ""); got != "https://gateway.internal/v1" {
+		t.Errorf("BaseURLFor(openai) = %q, want the WithBaseURL gateway", got)
+	}
+	if got := BaseURLFor("openrouter", "https://openrouter.ai/api/v1"); got != "https://openrouter.ai/api/v1" {
+		t.Errorf("BaseURLFor(openrouter) = %q, WithBaseURL should only apply to the default provider", got)
+	}
+
+	Configure(WithProviderBaseURL("openrouter", "https://proxy.internal/api/v1"))
+	if got := BaseURLFor("openrouter", ""); got != "https://proxy.internal/api/v1" {
+		t.Errorf("BaseURLFor(openrouter) = %q, want the per-provider URL", got)
+	}
+
+	ResetConfig()
+	if got := BaseURLFor("openai", ""); got != "https://env.example/v1" {
+		t.Errorf("BaseURLFor() after reset = %q, want OPENAI_BASE_URL", got)
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	Configure(WithTimeout(time.Minute))
+	ctx, cancel := WithDefaultTimeout(context.Background())
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline within 1m, got %v (set %v)", deadline, ok)
+	}
+
+	// An earlier caller deadline wins
+	short, cancelShort := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShort()
+	ctx, cancel = WithDefaultTimeout(short)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) > time.Second {
+		t.Errorf("expected the caller's 1s deadline to win, got %v", time.Until(deadline))
+	}
+
+	// A later caller deadline, e.g. from a module timeout, overrides the default
+	long, cancelLong := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelLong()
+	ctx, cancel = WithDefaultTimeout(long)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) <= time.Minute {
+		t.Errorf("expected the caller's 1h deadline to win, got %v", time.Until(deadline))
+	}
+
+	Configure(WithTimeout(0))
+	ctx, cancel = WithDefaultTimeout(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline when the timeout is disabled")
+	}
+}
+
 func TestStripProviderPrefix(t *testing.T) {
 	tests := []struct {
 		name     string
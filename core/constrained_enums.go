@@ -0,0 +1,80 @@
+package core
+
+const (
+	// enumLogitBias nudges class tokens without overriding the JSON syntax around them
+	enumLogitBias = 5
+	// maxLogitBiasTokens is the most token biases providers accept in one request
+	maxLogitBiasTokens = 300
+)
+
+// ConstrainEnums constrains sig's class outputs to their classes for one call when adapter
+// is a JSONAdapter with ConstrainedEnums. Models with structured outputs get the signature's
+// JSON schema, whose enums the provider enforces. Other models get LogitBias toward the
+// class tokens, when the configured tokenizer is a TokenEncoder (e.g. TiktokenTokenizer for
+// OpenAI models); without one the options are left unchanged. Function-calling mode
+// already carries the enums in the return_result parameters, so it is left as is.
+func ConstrainEnums(adapter Adapter, lm LM, sig *Signature, options *GenerateOptions) {
+	jsonAdapter, ok := adapter.(*JSONAdapter)
+	if !ok || !jsonAdapter.ConstrainedEnums || UseFunctionCalling(adapter, lm) {
+		return
+	}
+
+	caps := CapabilitiesOf(lm)
+	if caps.SupportsJSONSchema {
+		if !hasClassOutputs(sig) {
+			return
+		}
+		options.ResponseFormat = "json"
+		if options.ResponseSchema == nil {
+			options.ResponseSchema = sig.SignatureToJSONSchema()
+		}
+		return
+	}
+
+	encoder, ok := GetTokenizer().(TokenEncoder)
+	if !ok {
+		return
+	}
+	if bias := EnumLogitBias(encoder, lm.Name(), sig); len(bias) > 0 {
+		if options.LogitBias == nil {
+			options.LogitBias = make(map[int]int, len(bias))
+		}
+		for token, value := range bias {
+			if _, set := options.LogitBias[token]; !set {
+				options.LogitBias[token] = value
+			}
+		}
+	}
+}
+
+// EnumLogitBias returns a positive bias for the tokens of every class of sig's class
+// outputs, as written bare and after a space, capped at the 300 tokens providers accept
+func EnumLogitBias(encoder TokenEncoder, model string, sig *Signature) map[int]int {
+	bias := make(map[int]int)
+	for _, field := range sig.OutputFields {
+		if field.Type != FieldTypeClass {
+			continue
+		}
+		for _, class := range field.Classes {
+			for _, text := range []string{class, " " + class} {
+				for _, token := range encoder.Encode(model, text) {
+					if len(bias) >= maxLogitBiasTokens {
+						return bias
+					}
+					bias[token] = enumLogitBias
+				}
+			}
+		}
+	}
+	return bias
+}
+
+// hasClassOutputs reports whether sig has a class output with classes
+func hasClassOutputs(sig *Signature) bool {
+	for _, field := range sig.OutputFields {
+		if field.Type == FieldTypeClass && len(field.Classes) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConstrainEnums(t *testing.T) {
+	sig := NewSignature("Classify").
+		AddInput("text", FieldTypeString, "").
+		AddClassOutput("label", []string{"ab", "abc"}, "Label")
+	constrained := NewJSONAdapter().WithConstrainedEnums(true)
+
+	t.Run("schema enum on structured-output models", func(t *testing.T) {
+		options := DefaultGenerateOptions()
+		ConstrainEnums(constrained, NewMockLM(), sig, options)
+		if options.ResponseFormat != "json" {
+			t.Errorf("ResponseFormat = %q, want json", options.ResponseFormat)
+		}
+		props, _ := options.ResponseSchema["properties"].(map[string]any)
+		label, _ := props["label"].(map[string]any)
+		if enum, _ := label["enum"].([]string); len(enum) != 2 {
+			t.Errorf("schema = %v, want the label enum", options.ResponseSchema)
+		}
+		if options.LogitBias != nil {
+			t.Errorf("LogitBias = %v, want none when the schema constrains the enum", options.LogitBias)
+		}
+	})
+
+	t.Run("logit bias on other models", func(t *testing.T) {
+		tok, err := NewTiktokenTokenizer(strings.NewReader(tiktokenRanks("a", "b", "c", " ", "ab", " ab", "abc")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		SetTokenizer(tok)
+		defer SetTokenizer(nil)
+
+		options := DefaultGenerateOptions()
+		ConstrainEnums(constrained, NewMockLM().WithJSONSupport(false), sig, options)
+		// "ab" -> 4, " ab" -> 5, "abc" -> 6, " abc" -> 5 2
+		want := map[int]int{2: enumLogitBias, 4: enumLogitBias, 5: enumLogitBias, 6: enumLogitBias}
+		if len(options.LogitBias) != len(want) {
+			t.Fatalf("LogitBias = %v, want %v", options.LogitBias, want)
+		}
+		for token, bias := range want {
+			if options.LogitBias[token] != bias {
+				t.Errorf("LogitBias[%d] = %d, want %d", token, options.LogitBias[token], bias)
+			}
+		}
+	})
+
+	t.Run("no logit bias without a token encoder", func(t *testing.T) {
+		options := DefaultGenerateOptions()
+		ConstrainEnums(constrained, NewMockLM().WithJSONSupport(false), sig, options)
+		if options.LogitBias != nil {
+			t.Errorf("LogitBias = %v, want none with the heuristic tokenizer", options.LogitBias)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		for _, adapter := range []Adapter{NewJSONAdapter(), NewChatAdapter(), NewJSONAdapter().WithConstrainedEnums(true).WithFunctionCalling(true)} {
+			options := DefaultGenerateOptions()
+			ConstrainEnums(adapter, NewMockLM(), sig, options)
+			if options.ResponseSchema != nil || options.LogitBias != nil {
+				t.Errorf("%T: options changed to %+v", adapter, options)
+			}
+		}
+	})
+}
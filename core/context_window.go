@@ -0,0 +1,262 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrContextWindowExceeded is returned when a prompt cannot fit the model's context window
+var ErrContextWindowExceeded = errors.New("prompt exceeds model context window")
+
+// TruncationPolicy controls what happens when a prompt exceeds the context window.
+// Policies are flags and may be combined, e.g. DropDemos | DropHistory.
+type TruncationPolicy int
+
+const (
+	// DropDemos removes few-shot demos (in the configured DemoDropOrder) until the prompt fits
+	DropDemos TruncationPolicy = 1 << iota
+	// DropHistory removes the oldest history turns (a user message and its replies) until the prompt fits
+	DropHistory
+	// TruncationError fails early with ErrContextWindowExceeded instead of calling the provider
+	TruncationError
+)
+
+// DemoDropOrder controls which few-shot demos are dropped first when a prompt
+// exceeds the context window
+type DemoDropOrder int
+
+const (
+	// DropLastDemoFirst drops demos from the end of the list (default)
+	DropLastDemoFirst DemoDropOrder = iota
+	// DropFirstDemoFirst drops demos from the start of the list
+	DropFirstDemoFirst
+	// DropLowestWeightFirst drops the demo with the lowest Example.Weight, the latest on ties
+	DropLowestWeightFirst
+)
+
+const (
+	// charsPerToken is the heuristic used for token estimation
+	charsPerToken = 4
+	// messageTokenOverhead approximates per-message formatting tokens (role, separators)
+	messageTokenOverhead = 4
+	// imageTokenEstimate approximates the prompt cost of one image part
+	imageTokenEstimate = 85
+)
+
+// contextWindows maps model name prefixes (without organization) to context length in tokens
+var (
+	contextWindows = map[string]int{
+		"gpt-3.5-turbo":    16385,
+		"gpt-4":            8192,
+		"gpt-4-turbo":      128000,
+		"gpt-4o":           128000,
+		"gpt-4.1":          1047576,
+		"gpt-5":            400000,
+		"gpt-oss":          131072,
+		"o1":               200000,
+		"o3":               200000,
+		"o4":               200000,
+		"claude-3":         200000,
+		"claude-sonnet-4":  200000,
+		"claude-opus-4":    200000,
+		"claude-haiku-4":   200000,
+		"gemini-1.5":       1048576,
+		"gemini-2":         1048576,
+		"llama-3.1":        131072,
+		"llama-3.3":        131072,
+		"qwen3-235b":       262144,
+		"glm-4.6":          202752,
+		"minimax-m2":       204800,
+		"deepseek-v3":      163840,
+		"kimi-k2":          262144,
+		"mistral-large":    128000,
+		"mistral-small":    32768,
+		"llama-4-maverick": 1048576,
+		"llama-4-scout":    327680,
+		"deepseek-r1":      163840,
+		"grok-4":           256000,
+		"command-r":        128000,
+		"qwen-2.5-72b":     32768,
+		"mixtral-8x7b":     32768,
+		"phi-4":            16384,
+		"gemma-3":          131072,
+		"nova-pro":         300000,
+		"llama-3.2":        131072,
+	}
+	contextWindowsMu sync.RWMutex
+)
+
+// RegisterContextWindow sets the context length for models whose name starts with prefix
+func RegisterContextWindow(prefix string, tokens int) {
+	contextWindowsMu.Lock()
+	defer contextWindowsMu.Unlock()
+	contextWindows[strings.ToLower(prefix)] = tokens
+}
+
+// ContextWindowFor returns the known context length for a model
+// The longest matching prefix wins, e.g. "gpt-4o-mini" matches "gpt-4o" rather than "gpt-4"
+func ContextWindowFor(model string) (int, bool) {
+	name := strings.ToLower(model)
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	contextWindowsMu.RLock()
+	defer contextWindowsMu.RUnlock()
+
+	best, bestLen := 0, 0
+	for prefix, tokens := range contextWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > bestLen {
+			best, bestLen = tokens, len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// EstimateTokens approximates the token count of text with the configured tokenizer
+func EstimateTokens(text string) int {
+	return CountTokens("", text)
+}
+
+// EstimateMessageTokens approximates the prompt token count of messages with the configured tokenizer
+func EstimateMessageTokens(messages []Message) int {
+	return CountMessageTokens("", messages)
+}
+
+// EstimateToolTokens approximates the prompt token count of tool definitions
+func EstimateToolTokens(tools []Tool) int {
+	total := 0
+	for _, tool := range tools {
+		data, err := json.Marshal(tool)
+		if err != nil {
+			continue
+		}
+		total += EstimateTokens(string(data))
+	}
+	return total
+}
+
+// PromptAssembly describes the parts of a module prompt subject to context-window truncation
+type PromptAssembly struct {
+	Adapter   Adapter
+	Signature *Signature
+	Inputs    map[string]any
+	Demos     []Example
+	History   *History
+	Prefix    []Message // Leading messages that are never dropped (e.g. a system prompt)
+	MaxTokens int       // Completion tokens to reserve in the context window
+}
+
+// AssemblyReport describes what Assemble dropped to fit the context window
+type AssemblyReport struct {
+	Demos          int // Demos rendered in the final prompt
+	DroppedDemos   int
+	DroppedHistory int // History messages dropped
+}
+
+// Assemble formats the prompt for model and applies the configured truncation policy.
+// It returns the full message list and the newly formatted (non-history) messages.
+func (a PromptAssembly) Assemble(model string) ([]Message, []Message, error) {
+	messages, newMessages, _, err := a.AssembleWithReport(model)
+	return messages, newMessages, err
+}
+
+// AssembleWithReport is Assemble that also reports what was dropped.
+// Without a truncation policy, demos are still dropped automatically when the
+// model's context window is known and the prompt would exceed it; if the prompt
+// still does not fit, it is returned as is and the provider decides.
+func (a PromptAssembly) AssembleWithReport(model string) ([]Message, []Message, AssemblyReport, error) {
+	var historyMessages []Message
+	if a.History != nil && !a.History.IsEmpty() {
+		historyMessages = a.Adapter.FormatHistory(a.History)
+	}
+	demos := a.Demos
+	totalHistory := len(historyMessages)
+
+	settings := GetSettings()
+	policy := settings.TruncationPolicy
+	window := settings.ContextWindow
+	if window <= 0 {
+		window, _ = ContextWindowFor(model)
+	}
+	dropDemos := policy == 0 || policy&DropDemos != 0
+
+	for {
+		newMessages, err := a.Adapter.Format(a.Signature, a.Inputs, demos)
+		if err != nil {
+			return nil, nil, AssemblyReport{}, fmt.Errorf("failed to format messages: %w", err)
+		}
+
+		// Keep the adapter's system instruction ahead of the conversation history
+		system := 0
+		for system < len(newMessages) && newMessages[system].Role == "system" {
+			system++
+		}
+
+		messages := make([]Message, 0, len(a.Prefix)+len(historyMessages)+len(newMessages))
+		messages = append(messages, a.Prefix...)
+		messages = append(messages, newMessages[:system]...)
+		messages = append(messages, historyMessages...)
+		messages = append(messages, newMessages[system:]...)
+
+		report := AssemblyReport{
+			Demos:          len(demos),
+			DroppedDemos:   len(a.Demos) - len(demos),
+			DroppedHistory: totalHistory - len(historyMessages),
+		}
+		// Without a policy only demos can be dropped, so skip estimation when there are none
+		if window <= 0 || (policy == 0 && len(demos) == 0) {
+			return messages, newMessages, report, nil
+		}
+
+		estimated := CountMessageTokens(model, messages) + a.MaxTokens
+		if estimated <= window {
+			return messages, newMessages, report, nil
+		}
+
+		switch {
+		case dropDemos && len(demos) > 0:
+			demos = dropDemo(demos, settings.DemoDropOrder)
+		case policy&DropHistory != 0 && len(historyMessages) > 0:
+			historyMessages = dropHistoryTurn(historyMessages)
+		case policy == 0:
+			return messages, newMessages, report, nil
+		default:
+			return nil, nil, report, fmt.Errorf("%w: estimated %d tokens (including %d reserved for completion) exceeds %d for model %s",
+				ErrContextWindowExceeded, estimated, a.MaxTokens, window, model)
+		}
+	}
+}
+
+// dropHistoryTurn returns history without its oldest message and the replies that follow it,
+// so a user message is never kept without its answer
+func dropHistoryTurn(history []Message) []Message {
+	next := 1
+	for next < len(history) && history[next].Role != "user" {
+		next++
+	}
+	return history[next:]
+}
+
+// dropDemo returns demos without the one order drops first
+func dropDemo(demos []Example, order DemoDropOrder) []Example {
+	switch order {
+	case DropFirstDemoFirst:
+		return demos[1:]
+	case DropLowestWeightFirst:
+		lowest := len(demos) - 1
+		for i := len(demos) - 2; i >= 0; i-- {
+			if demos[i].Weight < demos[lowest].Weight {
+				lowest = i
+			}
+		}
+		kept := make([]Example, 0, len(demos)-1)
+		kept = append(kept, demos[:lowest]...)
+		return append(kept, demos[lowest+1:]...)
+	default:
+		return demos[:len(demos)-1]
+	}
+}
@@ -0,0 +1,418 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContextWindowFor(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   int
+		wantOK bool
+	}{
+		{"gpt-4", 8192, true},
+		{"gpt-4o-mini", 128000, true},
+		{"openai/gpt-4o", 128000, true},
+		{"anthropic/claude-3-5-sonnet", 200000, true},
+		{"GPT-4-Turbo-Preview", 128000, true},
+		{"unknown-model", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := ContextWindowFor(tt.model)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ContextWindowFor(%q) = (%d, %v), want (%d, %v)", tt.model, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRegisterContextWindow(t *testing.T) {
+	RegisterContextWindow("acme-large", 4096)
+	defer func() {
+		contextWindowsMu.Lock()
+		delete(contextWindows, "acme-large")
+		contextWindowsMu.Unlock()
+	}()
+
+	got, ok := ContextWindowFor("acme/acme-large-v2")
+	if !ok || got != 4096 {
+		t.Errorf("ContextWindowFor() = (%d, %v), want (4096, true)", got, ok)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("a", 400), 100},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%d chars) = %d, want %d", len(tt.text), got, tt.want)
+		}
+	}
+}
+
+func TestEstimateMessageTokens(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: strings.Repeat("a", 40)},
+		{Role: "user", Content: "", Images: []ImageContent{{URL: "https://example.com/a.png"}}},
+	}
+
+	want := (messageTokenOverhead + 10) + (messageTokenOverhead + imageTokenEstimate)
+	if got := EstimateMessageTokens(messages); got != want {
+		t.Errorf("EstimateMessageTokens() = %d, want %d", got, want)
+	}
+}
+
+// newTruncationAssembly builds a prompt with two large demos and two large history messages
+func newTruncationAssembly() PromptAssembly {
+	sig := NewSignature("Answer the question").
+		AddInput("question", FieldTypeString, "Question").
+		AddOutput("answer", FieldTypeString, "Answer")
+
+	padding := strings.Repeat("x", 2000)
+	demos := []Example{
+		*NewExample(map[string]any{"question": "q1 " + padding}, map[string]any{"answer": "a1"}),
+		*NewExample(map[string]any{"question": "q2 " + padding}, map[string]any{"answer": "a2"}),
+	}
+
+	history := NewHistory()
+	history.AddUserMessage("oldest " + padding)
+	history.AddAssistantMessage("newest " + padding)
+
+	return PromptAssembly{
+		Adapter:   NewJSONAdapter(),
+		Signature: sig,
+		Inputs:    map[string]any{"question": "What is 2+2?"},
+		Demos:     demos,
+		History:   history,
+	}
+}
+
+// baseTokens returns the estimate for the assembly without demos or history
+func baseTokens(t *testing.T, a PromptAssembly) int {
+	t.Helper()
+	a.Demos = nil
+	a.History = nil
+	messages, _, err := a.Assemble("test-model")
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	return EstimateMessageTokens(messages)
+}
+
+func TestPromptAssembly_Assemble(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       TruncationPolicy
+		slack        int // tokens allowed beyond the bare prompt
+		maxTokens    int
+		wantErr      bool
+		wantHistory  int
+		wantDemoText []string
+		dropDemoText []string
+	}{
+		{
+			name:         "no policy sends everything that fits",
+			policy:       0,
+			slack:        2500,
+			wantHistory:  2,
+			wantDemoText: []string{"q1", "q2"},
+		},
+		{
+			name:         "no policy drops demos but never fails",
+			policy:       0,
+			wantHistory:  2,
+			dropDemoText: []string{"q1", "q2"},
+		},
+		{
+			name:         "drop demos keeps history",
+			policy:       DropDemos | TruncationError,
+			slack:        1100,
+			wantHistory:  2,
+			dropDemoText: []string{"q1", "q2"},
+		},
+		{
+			name:    "drop demos alone cannot fit history",
+			policy:  DropDemos,
+			slack:   600,
+			wantErr: true,
+		},
+		{
+			name:         "drop history drops the whole turn",
+			policy:       DropHistory,
+			slack:        1700,
+			wantHistory:  0,
+			wantDemoText: []string{"q1", "q2"},
+		},
+		{
+			name:         "combined drops demos before history",
+			policy:       DropDemos | DropHistory,
+			slack:        600,
+			wantHistory:  0,
+			dropDemoText: []string{"q1", "q2"},
+		},
+		{
+			name:    "error policy fails early",
+			policy:  TruncationError,
+			slack:   100,
+			wantErr: true,
+		},
+		{
+			name:      "reserved completion tokens count toward the window",
+			policy:    DropDemos | DropHistory,
+			slack:     100,
+			maxTokens: 200,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetConfig()
+			defer ResetConfig()
+
+			a := newTruncationAssembly()
+			a.MaxTokens = tt.maxTokens
+			Configure(
+				WithContextWindow(baseTokens(t, a)+tt.slack),
+				WithTruncationPolicy(tt.policy),
+			)
+
+			messages, newMessages, err := a.Assemble("test-model")
+			if tt.wantErr {
+				if !errors.Is(err, ErrContextWindowExceeded) {
+					t.Fatalf("expected ErrContextWindowExceeded, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+
+			if got := len(messages) - len(newMessages); got != tt.wantHistory {
+				t.Errorf("history messages = %d, want %d", got, tt.wantHistory)
+			}
+
+			var prompt strings.Builder
+			for _, msg := range newMessages {
+				prompt.WriteString(msg.Content)
+			}
+			for _, text := range tt.wantDemoText {
+				if !strings.Contains(prompt.String(), text) {
+					t.Errorf("expected demo %q in prompt", text)
+				}
+			}
+			for _, text := range tt.dropDemoText {
+				if strings.Contains(prompt.String(), text+" x") {
+					t.Errorf("expected demo %q to be dropped", text)
+				}
+			}
+		})
+	}
+}
+
+func TestPromptAssembly_DropHistoryInTurns(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	a := newTruncationAssembly()
+	a.Demos = nil
+	padding := strings.Repeat("x", 2000)
+	a.History.AddUserMessage("follow-up " + padding)
+	a.History.AddAssistantMessage("latest " + padding)
+
+	// The budget fits the last three history messages, which falls between the two turns
+	lastThree := a
+	lastThree.History = NewHistory()
+	for _, msg := range a.History.Get()[1:] {
+		lastThree.History.Add(msg)
+	}
+	fits, _, err := lastThree.Assemble("test-model")
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	Configure(
+		WithContextWindow(EstimateMessageTokens(fits)),
+		WithTruncationPolicy(DropHistory),
+	)
+
+	messages, newMessages, report, err := a.AssembleWithReport("test-model")
+	if err != nil {
+		t.Fatalf("AssembleWithReport() error = %v", err)
+	}
+	if report.DroppedHistory != 2 || len(messages)-len(newMessages) != 2 {
+		t.Fatalf("report = %+v, want the oldest user/assistant pair dropped", report)
+	}
+	// messages[0] is the signature instruction, which stays ahead of history
+	if messages[1].Role != "user" || !strings.HasPrefix(messages[1].Content, "follow-up") ||
+		messages[2].Role != "assistant" || !strings.HasPrefix(messages[2].Content, "latest") {
+		t.Errorf("expected the newest turn to be kept whole, got %q and %q", messages[1].Content[:10], messages[2].Content[:10])
+	}
+}
+
+func TestPromptAssembly_DemoDropOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    DemoDropOrder
+		weights  []float64
+		wantKept string
+	}{
+		{"last first by default", DropLastDemoFirst, nil, "q1"},
+		{"first first", DropFirstDemoFirst, nil, "q2"},
+		{"lowest weight first", DropLowestWeightFirst, []float64{0.5, 2}, "q2"},
+		{"lowest weight ties drop the latest", DropLowestWeightFirst, []float64{1, 1}, "q1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetConfig()
+			defer ResetConfig()
+
+			a := newTruncationAssembly()
+			a.History = nil
+			for i, weight := range tt.weights {
+				a.Demos[i].Weight = weight
+			}
+			Configure(
+				WithContextWindow(baseTokens(t, a)+600),
+				WithDemoDropOrder(tt.order),
+			)
+
+			_, newMessages, report, err := a.AssembleWithReport("test-model")
+			if err != nil {
+				t.Fatalf("AssembleWithReport() error = %v", err)
+			}
+			if report.Demos != 1 || report.DroppedDemos != 1 || report.DroppedHistory != 0 {
+				t.Errorf("report = %+v, want 1 demo kept and 1 dropped", report)
+			}
+			var prompt strings.Builder
+			for _, msg := range newMessages {
+				prompt.WriteString(msg.Content)
+			}
+			if !strings.Contains(prompt.String(), tt.wantKept+" x") {
+				t.Errorf("expected demo %q to be kept", tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestPromptAssembly_PrefixIsKept(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	a := newTruncationAssembly()
+	a.Prefix = []Message{{Role: "system", Content: "system prompt"}}
+	Configure(
+		WithContextWindow(baseTokens(t, a)+50),
+		WithTruncationPolicy(DropDemos|DropHistory),
+	)
+
+	messages, _, err := a.Assemble("test-model")
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if messages[0].Role != "system" || messages[0].Content != "system prompt" {
+		t.Errorf("expected prefix to be kept, got %+v", messages[0])
+	}
+	if len(messages) != 3 {
+		t.Errorf("expected prefix, instruction and user message only, got %d messages", len(messages))
+	}
+}
+
+func TestPromptAssembly_InstructionPlacement(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	tests := []struct {
+		name      string
+		placement InstructionPlacement
+		wantRoles []string
+	}{
+		{"system by default", InstructionSystem, []string{"system", "user", "assistant", "user"}},
+		{"user on request", InstructionUser, []string{"user", "assistant", "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTruncationAssembly()
+			a.Demos = nil
+			if tt.placement != InstructionSystem {
+				a.Adapter = NewJSONAdapter().WithInstructionPlacement(tt.placement)
+			}
+
+			messages, newMessages, err := a.Assemble("test-model")
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			var roles []string
+			for _, msg := range messages {
+				roles = append(roles, msg.Role)
+			}
+			if strings.Join(roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Fatalf("roles = %v, want %v", roles, tt.wantRoles)
+			}
+
+			prompt := newMessages[len(newMessages)-1].Content
+			if tt.placement == InstructionSystem {
+				if messages[0].Content != "Answer the question" {
+					t.Errorf("system message = %q, want the signature instruction", messages[0].Content)
+				}
+				if strings.Contains(prompt, "Answer the question") {
+					t.Error("instruction should not be repeated in the user prompt")
+				}
+			} else if !strings.HasPrefix(prompt, "Answer the question") {
+				t.Errorf("user prompt should start with the instruction, got %q", prompt[:20])
+			}
+		})
+	}
+}
+
+func TestPromptAssembly_UsesModelRegistry(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	RegisterContextWindow("tiny-model", 10)
+	defer func() {
+		contextWindowsMu.Lock()
+		delete(contextWindows, "tiny-model")
+		contextWindowsMu.Unlock()
+	}()
+
+	Configure(WithTruncationPolicy(TruncationError))
+	a := newTruncationAssembly()
+
+	if _, _, err := a.Assemble("tiny-model"); !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("expected registry window to apply, got %v", err)
+	}
+
+	// An explicit window overrides the registry
+	Configure(WithContextWindow(1_000_000))
+	if _, _, err := a.Assemble("tiny-model"); err != nil {
+		t.Errorf("expected WithContextWindow to override registry, got %v", err)
+	}
+}
+
+func TestEstimateToolTokens(t *testing.T) {
+	small := NewTool("search", "Search", nil)
+	large := NewTool("search", "Search the web for up-to-date information", nil).
+		AddParameter("query", "string", "Search query", true)
+
+	if got := EstimateToolTokens(nil); got != 0 {
+		t.Errorf("EstimateToolTokens(nil) = %d, want 0", got)
+	}
+	if s, l := EstimateToolTokens([]Tool{*small}), EstimateToolTokens([]Tool{*large}); s <= 0 || l <= s {
+		t.Errorf("expected larger schema to cost more tokens, got small=%d large=%d", s, l)
+	}
+}
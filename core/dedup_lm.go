@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// DedupLM wraps an LM so concurrent, identical Generate calls share one in-flight
+// request (singleflight). Calls are identical when they have the same model, messages
+// and options, as defined by GenerateCacheKey. The first call goes to the wrapped LM;
+// the others wait for it and receive the same result or error, so with a cache the
+// miss is coalesced and the cache is populated once. Every caller gets its own copy of
+// the result, so wrappers may annotate it; copies handed to waiting calls carry
+// Metadata["deduplicated"] = true. The shared request keeps the first caller's deadline
+// and is cancelled only once every caller waiting for it has gone.
+// Stream calls are passed through without deduplication.
+type DedupLM struct {
+	lm LM
+
+	mu       sync.Mutex
+	inFlight map[string]*dedupCall
+}
+
+// dedupCall is an in-flight Generate call shared by identical requests
+type dedupCall struct {
+	done    chan struct{}
+	result  *GenerateResult
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewDedupLM creates a DedupLM around lm
+func NewDedupLM(lm LM) *DedupLM {
+	return &DedupLM{lm: lm, inFlight: make(map[string]*dedupCall)}
+}
+
+// Generate joins an identical in-flight call, or starts one
+func (d *DedupLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	if options == nil {
+		return d.lm.Generate(ctx, messages, options)
+	}
+	key := GenerateCacheKey(d.lm.Name(), messages, options)
+
+	d.mu.Lock()
+	call, joined := d.inFlight[key]
+	if !joined {
+		// The shared request keeps the first caller's values (request ID, spans) and
+		// deadline, but not its cancellation, which would fail every other waiter
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		if deadline, ok := ctx.Deadline(); ok {
+			callCtx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+		}
+		call = &dedupCall{done: make(chan struct{}), cancel: cancel}
+		d.inFlight[key] = call
+		go d.run(callCtx, key, call, messages, options.Copy())
+	}
+	call.waiters++
+	d.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return resultCopy(call.result, joined), nil
+	case <-ctx.Done():
+		d.leave(key, call)
+		return nil, ctx.Err()
+	}
+}
+
+// run performs the shared call and releases its waiters
+func (d *DedupLM) run(ctx context.Context, key string, call *dedupCall, messages []Message, options *GenerateOptions) {
+	defer call.cancel()
+	call.result, call.err = d.lm.Generate(ctx, messages, options)
+
+	d.mu.Lock()
+	if d.inFlight[key] == call {
+		delete(d.inFlight, key)
+	}
+	d.mu.Unlock()
+	close(call.done)
+}
+
+// leave drops a cancelled waiter, cancelling the shared request when none remain
+func (d *DedupLM) leave(key string, call *dedupCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	call.waiters--
+	if call.waiters == 0 {
+		// Later identical calls must not join a request that is being cancelled
+		if d.inFlight[key] == call {
+			delete(d.inFlight, key)
+		}
+		call.cancel()
+	}
+}
+
+// resultCopy returns a caller's own copy of the shared result, marked as deduplicated
+// for callers that joined. The metadata map is copied too, so a wrapper annotating one
+// caller's result does not race with the others reading theirs.
+func resultCopy(result *GenerateResult, joined bool) *GenerateResult {
+	if result == nil {
+		return nil
+	}
+	own := *result
+	metadata := make(map[string]any, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	if joined {
+		metadata["deduplicated"] = true
+	}
+	own.Metadata = metadata
+	return &own
+}
+
+// Stream passes through to the wrapped LM without deduplication
+func (d *DedupLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	return d.lm.Stream(ctx, messages, options)
+}
+
+// Name returns the wrapped LM's name
+func (d *DedupLM) Name() string {
+	return d.lm.Name()
+}
+
+// SupportsJSON reports whether the wrapped LM supports JSON mode
+func (d *DedupLM) SupportsJSON() bool {
+	return d.lm.SupportsJSON()
+}
+
+// SupportsTools reports whether the wrapped LM supports tool calling
+func (d *DedupLM) SupportsTools() bool {
+	return d.lm.SupportsTools()
+}
+
+// Capabilities reports the wrapped LM's capabilities
+func (d *DedupLM) Capabilities() Capabilities {
+	return CapabilitiesOf(d.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (d *DedupLM) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, d.lm)
+}
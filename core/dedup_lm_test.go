@@ -0,0 +1,269 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingLM counts Generate calls and holds each one until release is closed
+type blockingLM struct {
+	mockWrapperLM
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func newBlockingLM(err error) *blockingLM {
+	b := &blockingLM{release: make(chan struct{})}
+	b.generateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+		b.calls.Add(1)
+		select {
+		case <-b.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &GenerateResult{Content: "shared", Metadata: map[string]any{"provider": "x"}}, nil
+	}
+	return b
+}
+
+// waitForWaiters blocks until n callers are waiting on the in-flight calls of d
+func waitForWaiters(t *testing.T, d *DedupLM, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.Lock()
+		waiters := 0
+		for _, call := range d.inFlight {
+			waiters += call.waiters
+		}
+		d.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers", n)
+}
+
+func TestDedupLM_Generate_Concurrent(t *testing.T) {
+	const n = 20
+	base := newBlockingLM(nil)
+	lm := NewDedupLM(base)
+	messages := []Message{{Role: "user", Content: "What is Go?"}}
+
+	var wg sync.WaitGroup
+	results := make([]*GenerateResult, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = lm.Generate(context.Background(), messages, DefaultGenerateOptions())
+		}(i)
+	}
+	waitForWaiters(t, lm, n)
+	close(base.release)
+	wg.Wait()
+
+	if calls := base.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+	deduplicated := 0
+	for i := 0; i < n; i++ {
+		if errs[i] != nil || results[i] == nil || results[i].Content != "shared" {
+			t.Fatalf("call %d = %+v, %v", i, results[i], errs[i])
+		}
+		if results[i].Metadata["deduplicated"] == true {
+			deduplicated++
+		}
+	}
+	if deduplicated != n-1 {
+		t.Errorf("%d results marked deduplicated, want %d", deduplicated, n-1)
+	}
+
+	// Once the call has finished, the next identical call goes to the provider again
+	if _, err := lm.Generate(context.Background(), messages, DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if calls := base.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
+
+func TestDedupLM_Generate_AnnotatingWrapper(t *testing.T) {
+	const n = 20
+	base := newBlockingLM(nil)
+	dedup := NewDedupLM(base)
+	// A wrapper that annotates results in place, as a user middleware might
+	lm := &mockWrapperLM{
+		name: "annotating",
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			result, err := dedup.Generate(ctx, messages, options)
+			if err == nil {
+				result.Metadata["annotated"] = true
+			}
+			return result, err
+		},
+	}
+	messages := []Message{{Role: "user", Content: "What is Go?"}}
+
+	var wg sync.WaitGroup
+	results := make([]*GenerateResult, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = NewFallbackLM(lm).Generate(context.Background(), messages, DefaultGenerateOptions())
+		}(i)
+	}
+	waitForWaiters(t, dedup, n)
+	close(base.release)
+	wg.Wait()
+
+	for i, result := range results {
+		if result == nil || result.Metadata["annotated"] != true || result.Metadata["lm_used"] != "annotating" {
+			t.Fatalf("result %d = %+v, want its own annotated copy", i, result)
+		}
+		for j := i + 1; j < n; j++ {
+			if result == results[j] {
+				t.Fatalf("results %d and %d share a pointer", i, j)
+			}
+		}
+	}
+}
+
+func TestDedupLM_Generate_KeepsCallerDeadline(t *testing.T) {
+	var remaining time.Duration
+	inner := &mockWrapperLM{
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			if deadline, ok := ctx.Deadline(); ok {
+				remaining = time.Until(deadline)
+			}
+			return &GenerateResult{Content: "ok"}, nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if _, err := NewDedupLM(inner).Generate(ctx, []Message{{Role: "user", Content: "hi"}}, DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if remaining < 59*time.Second || remaining > 60*time.Second {
+		t.Errorf("inner LM deadline in %v, want the caller's 60s deadline", remaining)
+	}
+}
+
+func TestDedupLM_Generate_DifferentRequests(t *testing.T) {
+	base := newBlockingLM(nil)
+	close(base.release)
+	lm := NewDedupLM(base)
+
+	options := DefaultGenerateOptions()
+	other := DefaultGenerateOptions()
+	other.Temperature = 0.1
+
+	var wg sync.WaitGroup
+	for _, opts := range []*GenerateOptions{options, other} {
+		wg.Add(1)
+		go func(opts *GenerateOptions) {
+			defer wg.Done()
+			_, _ = lm.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, opts)
+		}(opts)
+	}
+	wg.Wait()
+
+	if calls := base.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want 2 for different options", calls)
+	}
+}
+
+func TestDedupLM_Generate_SharedError(t *testing.T) {
+	errBoom := errors.New("boom")
+	base := newBlockingLM(errBoom)
+	lm := NewDedupLM(base)
+	messages := []Message{{Role: "user", Content: "hi"}}
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lm.Generate(context.Background(), messages, DefaultGenerateOptions()); errors.Is(err, errBoom) {
+				failed.Add(1)
+			}
+		}()
+	}
+	waitForWaiters(t, lm, 3)
+	close(base.release)
+	wg.Wait()
+
+	if failed.Load() != 3 || base.calls.Load() != 1 {
+		t.Errorf("failed = %d, calls = %d, want every caller to get the single call's error", failed.Load(), base.calls.Load())
+	}
+}
+
+func TestDedupLM_Generate_Cancellation(t *testing.T) {
+	base := newBlockingLM(nil)
+	lm := NewDedupLM(base)
+	messages := []Message{{Role: "user", Content: "hi"}}
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := lm.Generate(first, messages, DefaultGenerateOptions())
+		firstErr <- err
+	}()
+	waitForWaiters(t, lm, 1)
+
+	second := make(chan *GenerateResult, 1)
+	go func() {
+		result, _ := lm.Generate(context.Background(), messages, DefaultGenerateOptions())
+		second <- result
+	}()
+	waitForWaiters(t, lm, 2)
+
+	// The first caller leaving must not fail the shared request for the second
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller error = %v, want context.Canceled", err)
+	}
+	close(base.release)
+	if result := <-second; result == nil || result.Content != "shared" {
+		t.Errorf("second caller result = %+v, want the shared result", result)
+	}
+}
+
+func TestNewLM_RequestDeduplication(t *testing.T) {
+	originalRegistry := make(map[string]LMFactory)
+	registryLock.Lock()
+	for k, v := range lmRegistry {
+		originalRegistry[k] = v
+	}
+	registryLock.Unlock()
+	defer func() {
+		registryLock.Lock()
+		lmRegistry = originalRegistry
+		registryLock.Unlock()
+		ResetConfig()
+	}()
+
+	RegisterLM("dedup-provider", func(model string) LM { return &mockWrapperLM{name: model} })
+
+	ResetConfig()
+	Configure(WithRequestDeduplication(true))
+	lm, err := NewLM(context.Background(), "dedup-provider/model")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	if _, ok := lm.(*DedupLM); !ok {
+		t.Errorf("NewLM() = %T, want a *DedupLM", lm)
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DemoSelector picks few-shot examples for a specific input at inference time
+type DemoSelector interface {
+	SelectDemos(ctx context.Context, inputs map[string]any) ([]Example, error)
+}
+
+// KNNDemoSelector selects the K pool examples whose inputs are most similar to the
+// current input (KNN few-shot). Pool embeddings are computed once, on first use.
+type KNNDemoSelector struct {
+	Pool     []Example
+	Embedder Embedder
+	K        int
+
+	mu          sync.Mutex
+	poolVectors [][]float64
+}
+
+// NewKNNDemoSelector creates a selector returning the k nearest examples from pool
+func NewKNNDemoSelector(pool []Example, embedder Embedder, k int) *KNNDemoSelector {
+	return &KNNDemoSelector{
+		Pool:     pool,
+		Embedder: embedder,
+		K:        k,
+	}
+}
+
+// SelectDemos returns up to K examples ordered from most to least similar
+// Ties keep pool order so prompts are deterministic.
+func (s *KNNDemoSelector) SelectDemos(ctx context.Context, inputs map[string]any) ([]Example, error) {
+	if s.K <= 0 || len(s.Pool) == 0 {
+		return nil, nil
+	}
+	if s.K >= len(s.Pool) {
+		return s.Pool, nil
+	}
+
+	poolVectors, err := s.embedPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := s.Embedder.Embed(ctx, []string{exampleText(inputs)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed input: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 input", len(vectors))
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	ranked := make([]scored, len(poolVectors))
+	for i, v := range poolVectors {
+		ranked[i] = scored{index: i, score: CosineSimilarity(vectors[0], v)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	demos := make([]Example, 0, s.K)
+	for _, r := range ranked[:s.K] {
+		demos = append(demos, s.Pool[r.index])
+	}
+	return demos, nil
+}
+
+// embedPool embeds the pool inputs once and caches the vectors
+// A failed attempt is not cached, so the next call retries.
+func (s *KNNDemoSelector) embedPool(ctx context.Context) ([][]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.poolVectors != nil {
+		return s.poolVectors, nil
+	}
+
+	texts := make([]string, len(s.Pool))
+	for i, ex := range s.Pool {
+		texts[i] = exampleText(ex.Inputs)
+	}
+	vectors, err := s.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed demo pool: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d demos", len(vectors), len(texts))
+	}
+	s.poolVectors = vectors
+	return vectors, nil
+}
+
+// exampleText renders inputs as "name: value" lines in key order for embedding
+func exampleText(inputs map[string]any) string {
+	keys := make([]string, 0, len(inputs))
+	for k := range inputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s: %v\n", k, inputs[k])
+	}
+	return sb.String()
+}
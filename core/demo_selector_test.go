@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// keywordEmbedder maps texts onto fixed axes by keyword so similarity is predictable
+func keywordEmbedder(calls *atomic.Int32) Embedder {
+	axes := []string{"space", "romance", "detective"}
+	return EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		calls.Add(1)
+		vectors := make([][]float64, len(texts))
+		for i, text := range texts {
+			v := make([]float64, len(axes))
+			for j, axis := range axes {
+				v[j] = float64(strings.Count(text, axis))
+			}
+			vectors[i] = v
+		}
+		return vectors, nil
+	})
+}
+
+func genreExample(text, genre string) Example {
+	return *NewExample(map[string]any{"text": text}, map[string]any{"genre": genre})
+}
+
+func TestKNNDemoSelector_SelectDemos(t *testing.T) {
+	pool := []Example{
+		genreExample("a romance in paris", "romance"),
+		genreExample("space battle", "scifi"),
+		genreExample("detective solves a case", "mystery"),
+		genreExample("space station space crew", "scifi"),
+	}
+
+	var calls atomic.Int32
+	selector := NewKNNDemoSelector(pool, keywordEmbedder(&calls), 2)
+
+	demos, err := selector.SelectDemos(context.Background(), map[string]any{"text": "lost in space"})
+	if err != nil {
+		t.Fatalf("SelectDemos() error: %v", err)
+	}
+	if len(demos) != 2 || demos[0].Outputs["genre"] != "scifi" || demos[1].Outputs["genre"] != "scifi" {
+		t.Fatalf("expected the two scifi demos, got %+v", demos)
+	}
+	// Equal similarity keeps pool order
+	if demos[0].Inputs["text"] != "space battle" {
+		t.Errorf("expected pool order on ties, got %v first", demos[0].Inputs["text"])
+	}
+
+	demos, err = selector.SelectDemos(context.Background(), map[string]any{"text": "a detective story"})
+	if err != nil {
+		t.Fatalf("SelectDemos() error: %v", err)
+	}
+	if demos[0].Outputs["genre"] != "mystery" {
+		t.Errorf("expected mystery demo first, got %+v", demos[0])
+	}
+
+	// Pool embedded once, plus one call per input
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 embed calls, got %d", got)
+	}
+}
+
+func TestKNNDemoSelector_SmallPool(t *testing.T) {
+	pool := []Example{genreExample("space", "scifi")}
+	embedder := EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		t.Fatal("embedder should not be called when k covers the pool")
+		return nil, nil
+	})
+
+	demos, err := NewKNNDemoSelector(pool, embedder, 3).SelectDemos(context.Background(), map[string]any{"text": "x"})
+	if err != nil || len(demos) != 1 {
+		t.Errorf("expected whole pool, got %v, %v", demos, err)
+	}
+}
+
+func TestKNNDemoSelector_PoolErrorRetried(t *testing.T) {
+	pool := []Example{genreExample("space", "scifi"), genreExample("romance", "romance")}
+	fail := true
+	var calls atomic.Int32
+	inner := keywordEmbedder(&calls)
+	embedder := EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		if fail {
+			return nil, errors.New("embeddings unavailable")
+		}
+		return inner.Embed(ctx, texts)
+	})
+
+	selector := NewKNNDemoSelector(pool, embedder, 1)
+	if _, err := selector.SelectDemos(context.Background(), map[string]any{"text": "space"}); err == nil {
+		t.Fatal("expected error when pool embedding fails")
+	}
+
+	fail = false
+	demos, err := selector.SelectDemos(context.Background(), map[string]any{"text": "space"})
+	if err != nil || len(demos) != 1 || demos[0].Outputs["genre"] != "scifi" {
+		t.Errorf("expected retry to succeed, got %v, %v", demos, err)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "identical", a: []float64{1, 2}, b: []float64{2, 4}, want: 1},
+		{name: "orthogonal", a: []float64{1, 0}, b: []float64{0, 1}, want: 0},
+		{name: "opposite", a: []float64{1, 0}, b: []float64{-1, 0}, want: -1},
+		{name: "zero vector", a: []float64{0, 0}, b: []float64{1, 0}, want: 0},
+		{name: "length mismatch", a: []float64{1}, b: []float64{1, 0}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CosineSimilarity(tt.a, tt.b)
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("CosineSimilarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	RegisterEmbedder("test-embed", func(model string) Embedder {
+		return EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+			return [][]float64{{float64(len(model))}}, nil
+		})
+	})
+
+	e, err := NewEmbedder(context.Background(), "test-embed/abc")
+	if err != nil {
+		t.Fatalf("NewEmbedder() error: %v", err)
+	}
+	if v, _ := e.Embed(context.Background(), []string{"x"}); v[0][0] != 3 {
+		t.Errorf("factory received wrong model, got %v", v)
+	}
+
+	for _, model := range []string{"", "no-provider", "unknown/model"} {
+		if _, err := NewEmbedder(context.Background(), model); err == nil {
+			t.Errorf("NewEmbedder(%q) expected error", model)
+		}
+	}
+}
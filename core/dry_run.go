@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FinishReasonDryRun is the finish reason of the canned result of a dry-run call
+const FinishReasonDryRun = "dry_run"
+
+// dryRunKey is the context key for a per-call dry-run switch
+type dryRunKey struct{}
+
+// WithCallDryRun returns ctx switching dry-run mode on or off for module calls made
+// under ctx, overriding the WithDryRun setting
+func WithCallDryRun(ctx context.Context, enable bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, enable)
+}
+
+// DryRunEnabled reports whether calls made under ctx are dry runs: the WithCallDryRun
+// value attached to ctx, or else the WithDryRun setting
+func DryRunEnabled(ctx context.Context) bool {
+	if ctx != nil {
+		if enable, ok := ctx.Value(dryRunKey{}).(bool); ok {
+			return enable
+		}
+	}
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	return globalSettings.DryRun
+}
+
+// DryRun returns the canned result of a call to model when dry-run mode is enabled
+// for ctx, and false otherwise. The result has empty content, FinishReasonDryRun, the
+// estimated prompt tokens and cost in Usage, and the assembled messages and options in
+// Metadata["messages"] and Metadata["options"]. The prompt is also written to the
+// WithDryRunOutput writer, if any.
+func DryRun(ctx context.Context, model string, messages []Message, options *GenerateOptions) (*GenerateResult, bool) {
+	if !DryRunEnabled(ctx) {
+		return nil, false
+	}
+
+	promptTokens := CountMessageTokens(model, messages)
+	usage := Usage{PromptTokens: promptTokens, TotalTokens: promptTokens}
+	FillCost(model, &usage)
+
+	globalSettings.mu.RLock()
+	output := globalSettings.DryRunOutput
+	globalSettings.mu.RUnlock()
+	if output != nil {
+		_, _ = io.WriteString(output, FormatDryRun(model, messages))
+	}
+
+	return &GenerateResult{
+		FinishReason: FinishReasonDryRun,
+		Usage:        usage,
+		Metadata: map[string]any{
+			"dry_run":  true,
+			"messages": append([]Message(nil), messages...),
+			"options":  options,
+		},
+	}, true
+}
+
+// IsDryRun reports whether result is the canned result of a dry-run call
+func IsDryRun(result *GenerateResult) bool {
+	return result != nil && result.FinishReason == FinishReasonDryRun
+}
+
+// DryRunMiddleware answers every Generate and Stream call made under a dry-run
+// context with the canned DryRun result instead of calling the provider.
+// NewLM installs it when WithDryRun is configured.
+func DryRunMiddleware(model string) Middleware {
+	return func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			if result, ok := DryRun(ctx, model, messages, options); ok {
+				return result, nil
+			}
+			return next(ctx, messages, options)
+		}
+	}
+}
+
+// FormatDryRun renders the prompt of a dry-run call for printing
+func FormatDryRun(model string, messages []Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== dry run: %s (%d messages, ~%d prompt tokens) ===\n", model, len(messages), CountMessageTokens(model, messages))
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "--- %s ---\n%s\n", msg.Role, msg.Content)
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&b, "[tool call %s %s(%v)]\n", call.ID, call.Name, call.Arguments)
+		}
+		if len(msg.Images) > 0 {
+			fmt.Fprintf(&b, "[%d image(s)]\n", len(msg.Images))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDryRunEnabled(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	ctx := context.Background()
+	if DryRunEnabled(ctx) {
+		t.Error("dry run should be off by default")
+	}
+	if !DryRunEnabled(WithCallDryRun(ctx, true)) {
+		t.Error("WithCallDryRun(true) should enable dry run")
+	}
+
+	Configure(WithDryRun(true))
+	if !DryRunEnabled(ctx) {
+		t.Error("WithDryRun(true) should enable dry run")
+	}
+	if DryRunEnabled(WithCallDryRun(ctx, false)) {
+		t.Error("WithCallDryRun(false) should override the setting")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	messages := []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "What is Go?"}}
+	options := DefaultGenerateOptions()
+
+	if _, ok := DryRun(context.Background(), "gpt-4o", messages, options); ok {
+		t.Fatal("DryRun() should report false when dry run is off")
+	}
+
+	var out bytes.Buffer
+	Configure(WithDryRun(true), WithDryRunOutput(&out))
+	result, ok := DryRun(context.Background(), "gpt-4o", messages, options)
+	if !ok {
+		t.Fatal("DryRun() should report true when dry run is on")
+	}
+
+	if !IsDryRun(result) || result.Content != "" {
+		t.Errorf("result = %+v, want an empty dry-run result", result)
+	}
+	if result.Usage.PromptTokens == 0 || result.Usage.TotalTokens != result.Usage.PromptTokens {
+		t.Errorf("Usage = %+v, want estimated prompt tokens", result.Usage)
+	}
+	if got, _ := result.Metadata["messages"].([]Message); len(got) != 2 || got[1].Content != "What is Go?" {
+		t.Errorf("Metadata[messages] = %v", result.Metadata["messages"])
+	}
+	if result.Metadata["options"] != options {
+		t.Error("Metadata[options] should hold the call's options")
+	}
+
+	printed := out.String()
+	for _, want := range []string{"dry run: gpt-4o", "--- system ---", "Be brief.", "--- user ---", "What is Go?"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("printed prompt missing %q:\n%s", want, printed)
+		}
+	}
+}
+
+func TestNewLM_DryRun(t *testing.T) {
+	originalRegistry := make(map[string]LMFactory)
+	registryLock.Lock()
+	for k, v := range lmRegistry {
+		originalRegistry[k] = v
+	}
+	registryLock.Unlock()
+	defer func() {
+		registryLock.Lock()
+		lmRegistry = originalRegistry
+		registryLock.Unlock()
+		ResetConfig()
+	}()
+
+	provider := NewMockLM().Respond("real")
+	RegisterLM("dryrun-provider", func(model string) LM { return provider })
+
+	ResetConfig()
+	Configure(WithDryRun(true))
+	lm, err := NewLM(context.Background(), "dryrun-provider/model")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	messages := []Message{{Role: "user", Content: "hi"}}
+
+	result, err := lm.Generate(context.Background(), messages, DefaultGenerateOptions())
+	if err != nil || !IsDryRun(result) {
+		t.Fatalf("Generate() = %+v, %v, want a dry-run result", result, err)
+	}
+
+	chunks, errs := lm.Stream(context.Background(), messages, DefaultGenerateOptions())
+	var finish string
+	for chunk := range chunks {
+		finish = chunk.FinishReason
+	}
+	if err := <-errs; err != nil || finish != FinishReasonDryRun {
+		t.Errorf("Stream() finish = %q, err = %v", finish, err)
+	}
+	if calls := provider.CallCount(); calls != 0 {
+		t.Errorf("provider called %d times during dry run", calls)
+	}
+
+	// A call can opt out of the configured dry run
+	if result, err := lm.Generate(WithCallDryRun(context.Background(), false), messages, DefaultGenerateOptions()); err != nil || result.Content != "real" {
+		t.Errorf("Generate() with dry run off = %+v, %v", result, err)
+	}
+}
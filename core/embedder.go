@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Embedder turns texts into embedding vectors, one per text in the same order
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+// Embed calls f
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return f(ctx, texts)
+}
+
+// EmbedderFactory is a function that creates an Embedder for a given model.
+type EmbedderFactory func(model string) Embedder
+
+var (
+	embedderRegistry     = make(map[string]EmbedderFactory)
+	embedderRegistryLock sync.RWMutex
+)
+
+// RegisterEmbedder registers an Embedder factory for a specific provider.
+// This is called automatically during package initialization for built-in providers.
+func RegisterEmbedder(provider string, factory EmbedderFactory) {
+	embedderRegistryLock.Lock()
+	defer embedderRegistryLock.Unlock()
+	embedderRegistry[provider] = factory
+}
+
+// NewEmbedder creates an Embedder from a "provider/model" string,
+// e.g. NewEmbedder(ctx, "openai/text-embedding-3-small")
+func NewEmbedder(ctx context.Context, model string) (Embedder, error) {
+	parts := strings.SplitN(model, "/", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("embedding model must include provider: format 'provider/model' (e.g., 'openai/text-embedding-3-small')")
+	}
+
+	embedderRegistryLock.RLock()
+	factory, ok := embedderRegistry[parts[0]]
+	embedderRegistryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("provider '%s' has no registered embedder for model '%s'", parts[0], parts[1])
+	}
+	return factory(parts[1]), nil
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, or 0 if either is empty,
+// all zeros, or the lengths differ
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
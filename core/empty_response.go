@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/assagman/dsgo/internal/retry"
+)
+
+// ErrEmptyResponse matches any EmptyResponseError with errors.Is
+var ErrEmptyResponse = errors.New("empty response")
+
+// EmptyResponseError is a successful (200) completion with no content and no tool calls,
+// which some providers return when they soft-fail under load. It is returned instead of
+// the empty result so it reads as a provider failure rather than a parse error.
+type EmptyResponseError struct {
+	Provider     string // e.g. "openai", "openrouter", "bedrock"
+	FinishReason string // Finish reason of the last empty completion
+	Attempts     int    // Calls made, including retries
+}
+
+func (e *EmptyResponseError) Error() string {
+	return fmt.Sprintf("%s: %s returned no content (finish_reason=%s) after %d attempt(s)", ErrEmptyResponse, e.Provider, e.FinishReason, e.Attempts)
+}
+
+// Is reports whether target is ErrEmptyResponse
+func (e *EmptyResponseError) Is(target error) bool { return target == ErrEmptyResponse }
+
+// IsEmptyResult reports whether result is a completion without content or tool calls
+// Truncated (finish_reason=length) and dry-run results are not empty responses.
+func IsEmptyResult(result *GenerateResult) bool {
+	if result == nil || len(result.ToolCalls) > 0 || strings.TrimSpace(result.Content) != "" {
+		return false
+	}
+	return result.FinishReason != "length" && result.FinishReason != FinishReasonDryRun
+}
+
+// RetryEmptyResponses calls generate and retries empty completions (IsEmptyResult) on the
+// provider retry schedule (RetryBackoff), so they count against the MaxRetries setting.
+// A result recovered by retrying has Metadata["empty_retries"]; when every attempt is
+// empty it returns an *EmptyResponseError. Providers wrap Generate with it.
+func RetryEmptyResponses(ctx context.Context, provider string, generate func() (*GenerateResult, error)) (*GenerateResult, error) {
+	config := RetryBackoff()
+	policy := retry.Policy(config)
+	retries := max(config.MaxRetries, 0)
+
+	for attempt := 0; ; attempt++ {
+		result, err := generate()
+		if err != nil || !IsEmptyResult(result) {
+			if err == nil && attempt > 0 {
+				result = annotateEmptyRetries(result, attempt)
+			}
+			return result, err
+		}
+		if attempt >= retries {
+			return nil, &EmptyResponseError{Provider: provider, FinishReason: result.FinishReason, Attempts: attempt + 1}
+		}
+
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// annotateEmptyRetries records the retries on a copy of result, so cached results are not mutated
+func annotateEmptyRetries(result *GenerateResult, retries int) *GenerateResult {
+	annotated := *result
+	annotated.Metadata = make(map[string]any, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		annotated.Metadata[k] = v
+	}
+	annotated.Metadata["empty_retries"] = retries
+	return &annotated
+}
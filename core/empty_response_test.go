@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryEmptyResponses(t *testing.T) {
+	empty := &GenerateResult{FinishReason: "stop"}
+	answer := &GenerateResult{Content: "42", FinishReason: "stop", Metadata: map[string]any{"request_id": "r1"}}
+	truncated := &GenerateResult{FinishReason: "length"}
+
+	tests := []struct {
+		name        string
+		maxRetries  int
+		results     []*GenerateResult
+		wantCalls   int
+		wantResult  *GenerateResult
+		wantRetries any
+	}{
+		{"content is returned", 3, []*GenerateResult{answer}, 1, answer, nil},
+		{"empty then content", 3, []*GenerateResult{empty, empty, answer}, 3, answer, 2},
+		{"exhausted", 2, []*GenerateResult{empty, empty, empty, answer}, 3, nil, nil},
+		{"retries disabled", -1, []*GenerateResult{empty, answer}, 1, nil, nil},
+		{"truncation is not empty", 3, []*GenerateResult{truncated}, 1, truncated, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetConfig()
+			defer ResetConfig()
+			Configure(WithBackoff(BackoffConfig{InitialDelay: time.Millisecond, MaxRetries: tt.maxRetries}))
+
+			calls := 0
+			result, err := RetryEmptyResponses(context.Background(), "openai", func() (*GenerateResult, error) {
+				calls++
+				return tt.results[calls-1], nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantResult == nil {
+				var emptyErr *EmptyResponseError
+				if !errors.As(err, &emptyErr) || !errors.Is(err, ErrEmptyResponse) || emptyErr.Attempts != calls {
+					t.Fatalf("RetryEmptyResponses() = %v, %v, want an EmptyResponseError after %d attempts", result, err, calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RetryEmptyResponses() error = %v", err)
+			}
+			if result.Content != tt.wantResult.Content || result.Metadata["empty_retries"] != tt.wantRetries {
+				t.Errorf("result = %+v, want %q with empty_retries %v", result, tt.wantResult.Content, tt.wantRetries)
+			}
+			if _, mutated := answer.Metadata["empty_retries"]; mutated {
+				t.Error("the provider's result metadata was mutated")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,10 +12,17 @@ import (
 // Environment variables supported:
 //   - DSGO_TIMEOUT: Default timeout in seconds (e.g., "30")
 //   - DSGO_MAX_RETRIES: Default number of retries (e.g., "3")
+//   - DSGO_RETRY_INITIAL_DELAY, DSGO_RETRY_MAX_DELAY: Retry backoff delays (e.g., "500ms", "1m")
+//   - DSGO_RETRY_MULTIPLIER, DSGO_RETRY_JITTER: Backoff growth factor and jitter fraction (e.g., "2", "0.1")
 //   - DSGO_TRACING: Enable tracing ("true" or "false")
+//   - DSGO_DRY_RUN: Return assembled prompts instead of calling providers ("true" or "false")
 //   - DSGO_CACHE_TTL: Cache time-to-live duration (e.g., "5m", "1h", "30s")
 //   - DSGO_OPENAI_API_KEY: OpenAI API key
 //   - DSGO_OPENROUTER_API_KEY: OpenRouter API key
+//   - DSGO_OPENAI_API_KEYS, DSGO_OPENROUTER_API_KEYS: comma-separated keys rotated round-robin (see WithAPIKeys)
+//
+// DSGO_SAVE_RAW_RESPONSES and DSGO_ARTIFACT_DIR are read on each call by RawCaptureDir,
+// so raw response capture also works without calling Configure.
 func loadEnv() {
 
 	if timeoutStr := os.Getenv("DSGO_TIMEOUT"); timeoutStr != "" {
@@ -29,12 +37,37 @@ func loadEnv() {
 		}
 	}
 
+	if delay, err := time.ParseDuration(os.Getenv("DSGO_RETRY_INITIAL_DELAY")); err == nil && delay > 0 {
+		globalSettings.Backoff.InitialDelay = delay
+	}
+
+	if delay, err := time.ParseDuration(os.Getenv("DSGO_RETRY_MAX_DELAY")); err == nil && delay > 0 {
+		globalSettings.Backoff.MaxDelay = delay
+	}
+
+	if multiplier, err := strconv.ParseFloat(os.Getenv("DSGO_RETRY_MULTIPLIER"), 64); err == nil && multiplier > 0 {
+		globalSettings.Backoff.Multiplier = multiplier
+	}
+
+	if jitter, err := strconv.ParseFloat(os.Getenv("DSGO_RETRY_JITTER"), 64); err == nil {
+		if jitter == 0 {
+			jitter = -1 // an explicit 0 turns jitter off
+		}
+		globalSettings.Backoff.Jitter = jitter
+	}
+
 	if tracingStr := os.Getenv("DSGO_TRACING"); tracingStr != "" {
 		if tracing, err := strconv.ParseBool(tracingStr); err == nil {
 			globalSettings.EnableTracing = tracing
 		}
 	}
 
+	if dryRunStr := os.Getenv("DSGO_DRY_RUN"); dryRunStr != "" {
+		if dryRun, err := strconv.ParseBool(dryRunStr); err == nil {
+			globalSettings.DryRun = dryRun
+		}
+	}
+
 	if globalSettings.APIKey == nil {
 		globalSettings.APIKey = make(map[string]string)
 	}
@@ -55,6 +88,12 @@ func loadEnv() {
 		globalSettings.APIKey["openrouter"] = apiKey
 	}
 
+	for provider, name := range map[string]string{"openai": "DSGO_OPENAI_API_KEYS", "openrouter": "DSGO_OPENROUTER_API_KEYS"} {
+		if keys := os.Getenv(name); keys != "" {
+			WithAPIKeys(provider, strings.Split(keys, ","), KeyRoundRobin)(globalSettings)
+		}
+	}
+
 	// Parse DSGO_CACHE_TTL (e.g., "5m", "1h", "30s")
 	if ttlStr := os.Getenv("DSGO_CACHE_TTL"); ttlStr != "" {
 		if ttl, err := time.ParseDuration(ttlStr); err == nil {
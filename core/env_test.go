@@ -24,6 +24,11 @@ func TestLoadEnv(t *testing.T) {
 		_ = os.Unsetenv("DSGO_OPENROUTER_API_KEY")
 		_ = os.Unsetenv("OPENAI_API_KEY")
 		_ = os.Unsetenv("OPENROUTER_API_KEY")
+		_ = os.Unsetenv("DSGO_OPENROUTER_API_KEYS")
+		_ = os.Unsetenv("DSGO_RETRY_INITIAL_DELAY")
+		_ = os.Unsetenv("DSGO_RETRY_MAX_DELAY")
+		_ = os.Unsetenv("DSGO_RETRY_MULTIPLIER")
+		_ = os.Unsetenv("DSGO_RETRY_JITTER")
 	}
 
 	t.Run("LoadAllEnvVars", func(t *testing.T) {
@@ -91,6 +96,43 @@ func TestLoadEnv(t *testing.T) {
 		}
 	})
 
+	t.Run("APIKeyPool", func(t *testing.T) {
+		cleanupEnv()
+		defer cleanupEnv()
+
+		_ = os.Setenv("DSGO_OPENROUTER_API_KEYS", "key-one, key-two,")
+
+		ResetConfig()
+		Configure()
+
+		pool := KeyPoolFor("openrouter")
+		if pool == nil || pool.Len() != 2 {
+			t.Fatalf("expected a pool of 2 openrouter keys, got %v", pool)
+		}
+		if first, second := pool.Next(), pool.Next(); first != "key-one" || second != "key-two" {
+			t.Errorf("expected trimmed keys in order, got %q, %q", first, second)
+		}
+	})
+
+	t.Run("RetryBackoff", func(t *testing.T) {
+		cleanupEnv()
+		defer cleanupEnv()
+
+		_ = os.Setenv("DSGO_RETRY_INITIAL_DELAY", "250ms")
+		_ = os.Setenv("DSGO_RETRY_MAX_DELAY", "1m")
+		_ = os.Setenv("DSGO_RETRY_MULTIPLIER", "3")
+		_ = os.Setenv("DSGO_RETRY_JITTER", "0")
+		_ = os.Setenv("DSGO_MAX_RETRIES", "1")
+
+		ResetConfig()
+		Configure()
+
+		want := BackoffConfig{InitialDelay: 250 * time.Millisecond, MaxDelay: time.Minute, Multiplier: 3, Jitter: -1, MaxRetries: 1}
+		if got := RetryBackoff(); got != want {
+			t.Errorf("RetryBackoff() = %+v, want %+v", got, want)
+		}
+	})
+
 	t.Run("OptionsOverrideEnv", func(t *testing.T) {
 		cleanupEnv()
 		defer cleanupEnv()
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/assagman/dsgo/internal/retry"
+)
+
+// APIError is a non-success HTTP response from an LM provider
+// Use errors.As to inspect it; RateLimitError and AuthError wrap an APIError
+type APIError struct {
+	StatusCode int
+	Provider   string // e.g. "openai", "openrouter", "bedrock"
+	Body       string // Raw response body
+}
+
+// Error keeps the historical "API request failed with status N: body" format
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// RateLimitError is an HTTP 429 response
+type RateLimitError struct {
+	RetryAfter time.Duration // Server-suggested wait from the Retry-After header (0 if absent)
+	Err        *APIError
+}
+
+func (e *RateLimitError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying APIError
+func (e *RateLimitError) Unwrap() error { return e.Err }
+
+// AuthError is an HTTP 401 or 403 response, typically a missing or invalid API key
+type AuthError struct {
+	Err *APIError
+}
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying APIError
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// ParseError is returned when an adapter cannot turn LM output into the signature's fields
+type ParseError struct {
+	Adapter string // Adapter that failed, e.g. "JSONAdapter"
+	Raw     string // LM output that could not be parsed
+	Field   string // Output field that could not be extracted, when known
+	Err     error  // Underlying cause
+	// Attempts describes each adapter's failure when a FallbackAdapter tried several
+	Attempts []string
+}
+
+func (e *ParseError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying cause
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ErrContentFiltered matches any ContentFilterError with errors.Is
+var ErrContentFiltered = errors.New("content filtered")
+
+// FinishReasonContentFilter is the finish reason providers report when moderation
+// blocked the output or the model refused to answer
+const FinishReasonContentFilter = "content_filter"
+
+// ContentFilterError is a response blocked by a provider content filter or refused by
+// the model. It is returned instead of the (empty or apologetic) completion so callers
+// can tell moderation apart from parse failures.
+type ContentFilterError struct {
+	Provider  string // e.g. "openai", "openrouter", "bedrock"
+	Reason    string // Model's refusal message or the provider's block reason
+	Moderated bool   // Blocked by finish reason (moderation) rather than refused in a message
+}
+
+func (e *ContentFilterError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrContentFiltered, e.Provider, e.Reason)
+}
+
+// Is reports whether target is ErrContentFiltered
+func (e *ContentFilterError) Is(target error) bool { return target == ErrContentFiltered }
+
+// CheckContentFilter returns a *ContentFilterError when a response carries a refusal
+// message or finished with FinishReasonContentFilter, and nil otherwise
+func CheckContentFilter(provider, finishReason, refusal string) error {
+	if refusal != "" {
+		return &ContentFilterError{Provider: provider, Reason: refusal}
+	}
+	if finishReason == FinishReasonContentFilter {
+		return &ContentFilterError{Provider: provider, Reason: "finish_reason=" + finishReason, Moderated: true}
+	}
+	return nil
+}
+
+// ErrResponseTooLarge matches any ResponseTooLargeError with errors.Is
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ResponseTooLargeError is a provider response whose body exceeded WithMaxResponseBytes
+// Reading stops at the limit, so at most Limit bytes of it were held in memory.
+type ResponseTooLargeError struct {
+	Provider string // e.g. "openai", "openrouter", "bedrock"
+	Limit    int64  // Configured maximum in bytes
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %s response exceeded %d bytes", ErrResponseTooLarge, e.Provider, e.Limit)
+}
+
+// Is reports whether target is ErrResponseTooLarge
+func (e *ResponseTooLargeError) Is(target error) bool { return target == ErrResponseTooLarge }
+
+// NewAPIError builds the typed error for a failed provider response:
+// *RateLimitError for 429, *AuthError for 401/403 and *APIError otherwise
+func NewAPIError(provider string, statusCode int, body string, header http.Header) error {
+	apiErr := &APIError{StatusCode: statusCode, Provider: provider, Body: body}
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: retry.ParseRetryAfter(header), Err: apiErr}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{Err: apiErr}
+	}
+	return apiErr
+}
+
+// StatusCode returns the HTTP status of a provider error, or 0 if err carries none
+// Typed errors are checked first; untyped errors from custom providers fall back
+// to the "status NNN" text of the historical error format.
+func StatusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	if match := statusCodePattern.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return code
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError_Types(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:       "rate limit",
+			statusCode: http.StatusTooManyRequests,
+			check: func(t *testing.T, err error) {
+				var rateErr *RateLimitError
+				if !errors.As(err, &rateErr) {
+					t.Fatalf("expected *RateLimitError, got %T", err)
+				}
+			},
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				if !errors.As(err, &authErr) {
+					t.Fatalf("expected *AuthError, got %T", err)
+				}
+			},
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				if !errors.As(err, &authErr) {
+					t.Fatalf("expected *AuthError, got %T", err)
+				}
+			},
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusInternalServerError,
+			check: func(t *testing.T, err error) {
+				var rateErr *RateLimitError
+				var authErr *AuthError
+				if errors.As(err, &rateErr) || errors.As(err, &authErr) {
+					t.Fatalf("expected plain *APIError, got %T", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("request failed: %w", NewAPIError("openai", tt.statusCode, `{"error":"x"}`, nil))
+			tt.check(t, err)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError in chain, got %T", err)
+			}
+			if apiErr.StatusCode != tt.statusCode || apiErr.Provider != "openai" || apiErr.Body != `{"error":"x"}` {
+				t.Errorf("unexpected APIError fields: %+v", apiErr)
+			}
+			want := fmt.Sprintf("request failed: API request failed with status %d: {\"error\":\"x\"}", tt.statusCode)
+			if err.Error() != want {
+				t.Errorf("Error() = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestNewAPIError_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "seconds", value: "12", min: 12 * time.Second, max: 12 * time.Second},
+		{name: "http date", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+		{name: "past date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), min: 0, max: 0},
+		{name: "invalid", value: "soon", min: 0, max: 0},
+		{name: "absent", value: "", min: 0, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			var rateErr *RateLimitError
+			if !errors.As(NewAPIError("openai", http.StatusTooManyRequests, "", header), &rateErr) {
+				t.Fatal("expected *RateLimitError")
+			}
+			if rateErr.RetryAfter < tt.min || rateErr.RetryAfter > tt.max {
+				t.Errorf("RetryAfter = %v, want between %v and %v", rateErr.RetryAfter, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: 0},
+		{name: "typed", err: NewAPIError("openrouter", 503, "unavailable", nil), want: 503},
+		{name: "wrapped typed", err: fmt.Errorf("call: %w", NewAPIError("openai", 429, "", nil)), want: 429},
+		{name: "untyped fallback", err: errors.New("API request failed with status 502: bad gateway"), want: 502},
+		{name: "no status", err: errors.New("connection refused"), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckContentFilter(t *testing.T) {
+	if err := CheckContentFilter("openai", "stop", ""); err != nil {
+		t.Errorf("normal completion reported as filtered: %v", err)
+	}
+
+	err := CheckContentFilter("openai", "stop", "I can't help with that.")
+	var filterErr *ContentFilterError
+	if !errors.As(err, &filterErr) {
+		t.Fatalf("expected *ContentFilterError for a refusal, got %T", err)
+	}
+	if filterErr.Reason != "I can't help with that." || filterErr.Provider != "openai" {
+		t.Errorf("unexpected error fields: %+v", filterErr)
+	}
+
+	wrapped := fmt.Errorf("LM generation failed: %w", CheckContentFilter("openrouter", FinishReasonContentFilter, ""))
+	if !errors.Is(wrapped, ErrContentFiltered) {
+		t.Errorf("expected errors.Is(ErrContentFiltered) for finish_reason=content_filter, got %v", wrapped)
+	}
+}
+
+func TestAdapters_ReturnParseError(t *testing.T) {
+	sig := NewSignature("Test").
+		AddOutput("answer", FieldTypeString, "Answer").
+		AddOutput("confidence", FieldTypeString, "Confidence")
+
+	tests := []struct {
+		name      string
+		adapter   Adapter
+		content   string
+		wantName  string
+		wantField string
+	}{
+		{name: "json no object", adapter: NewJSONAdapter(), content: "no json here", wantName: "JSONAdapter"},
+		{name: "chat missing field", adapter: NewChatAdapter(), content: "[[ ## answer ## ]]\n42", wantName: "ChatAdapter", wantField: "confidence"},
+		{name: "fallback", adapter: NewFallbackAdapter(), content: "nothing useful", wantName: "FallbackAdapter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.adapter.Parse(sig, tt.content)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %T: %v", err, err)
+			}
+			if parseErr.Adapter != tt.wantName {
+				t.Errorf("Adapter = %q, want %q", parseErr.Adapter, tt.wantName)
+			}
+			if parseErr.Raw != tt.content {
+				t.Errorf("Raw = %q, want %q", parseErr.Raw, tt.content)
+			}
+			if parseErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", parseErr.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -148,3 +148,39 @@ func copyMap(m map[string]any) map[string]any {
 	}
 	return result
 }
+
+// LimitDemos returns at most maxDemos demos (0 = all) with string field values cut
+// to maxChars characters (0 = no limit), keeping demo prompts bounded; the originals are not modified
+func LimitDemos(demos []Example, maxDemos, maxChars int) []Example {
+	if maxDemos > 0 && len(demos) > maxDemos {
+		demos = demos[:maxDemos]
+	}
+	if maxChars <= 0 {
+		return demos
+	}
+
+	limited := make([]Example, len(demos))
+	for i, demo := range demos {
+		limited[i] = demo
+		limited[i].Inputs = truncateFieldValues(demo.Inputs, maxChars)
+		limited[i].Outputs = truncateFieldValues(demo.Outputs, maxChars)
+	}
+	return limited
+}
+
+// truncateFieldValues copies fields with string values longer than maxChars characters shortened
+func truncateFieldValues(fields map[string]any, maxChars int) map[string]any {
+	if fields == nil {
+		return nil
+	}
+	result := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			if runes := []rune(s); len(runes) > maxChars {
+				v = string(runes[:maxChars]) + "..."
+			}
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -173,3 +173,44 @@ func TestExampleSet_Get(t *testing.T) {
 		t.Errorf("Expected x=2 at index 1, got %v", examples[1].Inputs["x"])
 	}
 }
+
+func TestLimitDemos(t *testing.T) {
+	demos := []Example{
+		{Inputs: map[string]any{"plot": "a very long plot summary", "year": 1999}, Outputs: map[string]any{"genre": "drama"}},
+		{Inputs: map[string]any{"plot": "second"}, Outputs: map[string]any{"genre": "comedy"}},
+		{Inputs: map[string]any{"plot": "third"}, Outputs: map[string]any{"genre": "horror"}},
+	}
+
+	tests := []struct {
+		name      string
+		maxDemos  int
+		maxChars  int
+		wantLen   int
+		wantPlot  string
+		wantGenre string
+	}{
+		{"no limits", 0, 0, 3, "a very long plot summary", "drama"},
+		{"cap count", 2, 0, 2, "a very long plot summary", "drama"},
+		{"cap above length", 10, 0, 3, "a very long plot summary", "drama"},
+		{"truncate values", 0, 6, 3, "a very...", "drama"},
+		{"cap and truncate", 1, 4, 1, "a ve...", "dram..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LimitDemos(demos, tt.maxDemos, tt.maxChars)
+			if len(got) != tt.wantLen {
+				t.Fatalf("got %d demos, want %d", len(got), tt.wantLen)
+			}
+			if got[0].Inputs["plot"] != tt.wantPlot || got[0].Outputs["genre"] != tt.wantGenre {
+				t.Errorf("first demo = %v / %v", got[0].Inputs, got[0].Outputs)
+			}
+			if got[0].Inputs["year"] != 1999 {
+				t.Errorf("non-string values should be kept, got %v", got[0].Inputs["year"])
+			}
+		})
+	}
+
+	if demos[0].Inputs["plot"] != "a very long plot summary" {
+		t.Error("LimitDemos modified the original demos")
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// statusCodePattern extracts the HTTP status from untyped provider errors like
+// "API request failed with status 503: ..."
+var statusCodePattern = regexp.MustCompile(`status (\d{3})`)
+
+// FallbackLM tries multiple LMs in sequence until one succeeds
+// This provides provider/model failover, complementing FallbackAdapter's format failover
+type FallbackLM struct {
+	lms            []LM
+	shouldFallback func(err error) bool
+	mu             sync.RWMutex
+	lastUsed       int // Track which LM answered (for debugging)
+}
+
+// NewFallbackLM creates a fallback LM that tries primary first, then each fallback in order
+func NewFallbackLM(primary LM, fallbacks ...LM) *FallbackLM {
+	lms := make([]LM, 0, len(fallbacks)+1)
+	lms = append(lms, primary)
+	lms = append(lms, fallbacks...)
+	return &FallbackLM{
+		lms:            lms,
+		shouldFallback: IsFallbackError,
+		lastUsed:       -1,
+	}
+}
+
+// WithShouldFallback overrides how errors are classified as fallback-worthy
+func (f *FallbackLM) WithShouldFallback(fn func(err error) bool) *FallbackLM {
+	if fn != nil {
+		f.shouldFallback = fn
+	}
+	return f
+}
+
+// LastUsed returns the name of the LM that answered the most recent call, or "" if none has
+func (f *FallbackLM) LastUsed() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.lastUsed < 0 {
+		return ""
+	}
+	return f.lms[f.lastUsed].Name()
+}
+
+// Generate tries each LM in order until one succeeds
+// Non-retryable errors are returned immediately without trying the remaining LMs
+func (f *FallbackLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	var lmErrors []error
+
+	for i, lm := range f.lms {
+		result, err := lm.Generate(ctx, messages, options)
+		if err == nil {
+			f.setLastUsed(i)
+			return annotateFallback(result, lm.Name(), i+1), nil
+		}
+
+		if ctx.Err() != nil || !f.shouldFallback(err) {
+			return nil, err
+		}
+		lmErrors = append(lmErrors, fmt.Errorf("LM %d (%s): %w", i, lm.Name(), err))
+	}
+
+	return nil, fmt.Errorf("all LMs failed: %w", errors.Join(lmErrors...))
+}
+
+// annotateFallback records which LM answered on a copy of result
+// The metadata map is copied so cached or shared results are not mutated.
+func annotateFallback(result *GenerateResult, lmUsed string, attempts int) *GenerateResult {
+	if result == nil {
+		return nil
+	}
+	annotated := *result
+	metadata := make(map[string]any, len(result.Metadata)+2)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	metadata["lm_used"] = lmUsed
+	metadata["lm_attempts"] = attempts
+	annotated.Metadata = metadata
+	return &annotated
+}
+
+// Stream tries each LM in order until one starts streaming
+// Once the first chunk has been forwarded the stream is committed to that LM,
+// so later errors are returned as-is rather than triggering a fallback
+func (f *FallbackLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	outChunkChan := make(chan Chunk)
+	outErrChan := make(chan error, 1)
+
+	go func() {
+		defer close(outChunkChan)
+		defer close(outErrChan)
+
+		var lmErrors []error
+		for i, lm := range f.lms {
+			chunkChan, errChan := lm.Stream(ctx, messages, options)
+			started, err := forwardStream(ctx, chunkChan, errChan, outChunkChan, func() { f.setLastUsed(i) })
+			if err == nil {
+				f.setLastUsed(i)
+				return
+			}
+
+			if started || ctx.Err() != nil || !f.shouldFallback(err) {
+				outErrChan <- err
+				return
+			}
+			lmErrors = append(lmErrors, fmt.Errorf("LM %d (%s): %w", i, lm.Name(), err))
+		}
+
+		outErrChan <- fmt.Errorf("all LMs failed: %w", errors.Join(lmErrors...))
+	}()
+
+	return outChunkChan, outErrChan
+}
+
+// Name returns the primary LM's name
+func (f *FallbackLM) Name() string {
+	return f.lms[0].Name()
+}
+
+// SupportsJSON reports whether every LM in the chain supports JSON mode
+func (f *FallbackLM) SupportsJSON() bool {
+	for _, lm := range f.lms {
+		if !lm.SupportsJSON() {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsTools reports whether every LM in the chain supports tool calling
+func (f *FallbackLM) SupportsTools() bool {
+	for _, lm := range f.lms {
+		if !lm.SupportsTools() {
+			return false
+		}
+	}
+	return true
+}
+
+// Capabilities reports what every LM in the chain supports, since any of them may serve a call
+// MaxContextTokens is the smallest known window in the chain.
+func (f *FallbackLM) Capabilities() Capabilities {
+	caps := CapabilitiesOf(f.lms[0])
+	for _, lm := range f.lms[1:] {
+		other := CapabilitiesOf(lm)
+		caps.SupportsTools = caps.SupportsTools && other.SupportsTools
+		caps.SupportsJSON = caps.SupportsJSON && other.SupportsJSON
+		caps.SupportsJSONSchema = caps.SupportsJSONSchema && other.SupportsJSONSchema
+		caps.SupportsVision = caps.SupportsVision && other.SupportsVision
+		caps.SupportsStreaming = caps.SupportsStreaming && other.SupportsStreaming
+		if other.MaxContextTokens > 0 && (caps.MaxContextTokens == 0 || other.MaxContextTokens < caps.MaxContextTokens) {
+			caps.MaxContextTokens = other.MaxContextTokens
+		}
+	}
+	return caps
+}
+
+// HealthCheck passes when any LM in the chain is healthy, since it can serve every call
+// Otherwise it returns the failures of all of them.
+func (f *FallbackLM) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, lm := range f.lms {
+		err := healthCheck(ctx, lm)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", lm.Name(), err))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FallbackLM) setLastUsed(i int) {
+	f.mu.Lock()
+	f.lastUsed = i
+	f.mu.Unlock()
+}
+
+// forwardStream copies chunks from one stream to out until both input channels close,
+// an error arrives or ctx is cancelled. It reports whether any chunk was forwarded.
+func forwardStream(ctx context.Context, chunkChan <-chan Chunk, errChan <-chan error, out chan<- Chunk, onFirstChunk func()) (bool, error) {
+	started := false
+	for chunkChan != nil || errChan != nil {
+		select {
+		case chunk, ok := <-chunkChan:
+			if !ok {
+				chunkChan = nil
+				continue
+			}
+			if !started {
+				started = true
+				onFirstChunk()
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return started, ctx.Err()
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				return started, err
+			}
+		}
+	}
+	return started, nil
+}
+
+// IsFallbackError reports whether an LM error warrants trying the next LM
+// Server errors, rate limits, quota/billing failures and network errors are fallback-worthy;
+// client errors such as bad requests or invalid credentials are not
+func IsFallbackError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	code := StatusCode(err)
+	if code == 0 {
+		// No HTTP status: network, timeout or decode failure - another LM may succeed
+		return true
+	}
+
+	switch {
+	case code >= 500:
+		return true
+	case code == http.StatusTooManyRequests, code == http.StatusPaymentRequired:
+		return true
+	default:
+		return false
+	}
+}
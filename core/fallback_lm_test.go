@@ -0,0 +1,260 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockStreamLM streams fixed chunks, optionally failing before or after them
+type mockStreamLM struct {
+	mockWrapperLM
+	chunks    []string
+	streamErr error
+}
+
+func (m *mockStreamLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	chunkChan := make(chan Chunk)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+		for _, c := range m.chunks {
+			chunkChan <- Chunk{Content: c}
+		}
+		if m.streamErr != nil {
+			errChan <- m.streamErr
+		}
+	}()
+	return chunkChan, errChan
+}
+
+func failingLM(name string, err error) *mockWrapperLM {
+	return &mockWrapperLM{
+		name: name,
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			return nil, err
+		},
+	}
+}
+
+func TestIsFallbackError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"server error", errors.New("API request failed with status 503: unavailable"), true},
+		{"rate limit", errors.New("API request failed with status 429: slow down"), true},
+		{"payment required", errors.New("API request failed with status 402: insufficient credits"), true},
+		{"bad request", errors.New("API request failed with status 400: invalid"), false},
+		{"unauthorized", errors.New("API request failed with status 401: bad key"), false},
+		{"network", errors.New("request failed: connection refused"), true},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFallbackError(tt.err); got != tt.want {
+				t.Errorf("IsFallbackError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackLM_Generate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("primary succeeds", func(t *testing.T) {
+		lm := NewFallbackLM(&mockWrapperLM{name: "primary"}, &mockWrapperLM{name: "secondary"})
+		result, err := lm.Generate(ctx, nil, nil)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result.Metadata["lm_used"] != "primary" {
+			t.Errorf("lm_used = %v, want primary", result.Metadata["lm_used"])
+		}
+		if lm.LastUsed() != "primary" {
+			t.Errorf("LastUsed() = %q, want primary", lm.LastUsed())
+		}
+	})
+
+	t.Run("falls back on retryable error", func(t *testing.T) {
+		lm := NewFallbackLM(
+			failingLM("primary", errors.New("API request failed with status 500: boom")),
+			&mockWrapperLM{name: "secondary"},
+		)
+		result, err := lm.Generate(ctx, nil, nil)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result.Metadata["lm_used"] != "secondary" {
+			t.Errorf("lm_used = %v, want secondary", result.Metadata["lm_used"])
+		}
+		if result.Metadata["lm_attempts"] != 2 {
+			t.Errorf("lm_attempts = %v, want 2", result.Metadata["lm_attempts"])
+		}
+	})
+
+	t.Run("returns non-retryable error immediately", func(t *testing.T) {
+		secondaryCalled := false
+		secondary := &mockWrapperLM{
+			name: "secondary",
+			generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+				secondaryCalled = true
+				return &GenerateResult{}, nil
+			},
+		}
+		lm := NewFallbackLM(failingLM("primary", errors.New("API request failed with status 401: bad key")), secondary)
+		_, err := lm.Generate(ctx, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("Generate() error = %v, want 401 error", err)
+		}
+		if secondaryCalled {
+			t.Error("secondary should not be called on non-retryable error")
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		lm := NewFallbackLM(
+			failingLM("primary", errors.New("API request failed with status 500: a")),
+			failingLM("secondary", errors.New("API request failed with status 503: b")),
+		)
+		_, err := lm.Generate(ctx, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "all LMs failed") {
+			t.Fatalf("Generate() error = %v, want all LMs failed", err)
+		}
+		if !strings.Contains(err.Error(), "primary") || !strings.Contains(err.Error(), "secondary") {
+			t.Errorf("error should mention each LM: %v", err)
+		}
+	})
+
+	t.Run("does not mutate the inner result", func(t *testing.T) {
+		shared := &GenerateResult{Content: "cached", Metadata: map[string]any{"cache_hit": true}}
+		inner := &mockWrapperLM{
+			name: "primary",
+			generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+				return shared, nil
+			},
+		}
+		result, err := NewFallbackLM(inner).Generate(ctx, nil, nil)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result == shared || result.Metadata["lm_used"] != "primary" || result.Metadata["cache_hit"] != true {
+			t.Errorf("expected an annotated copy, got %+v", result)
+		}
+		if _, ok := shared.Metadata["lm_used"]; ok {
+			t.Errorf("inner result was mutated: %v", shared.Metadata)
+		}
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		lm := NewFallbackLM(
+			failingLM("primary", errors.New("API request failed with status 400: context too long")),
+			&mockWrapperLM{name: "secondary"},
+		).WithShouldFallback(func(err error) bool { return true })
+		result, err := lm.Generate(ctx, nil, nil)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result.Metadata["lm_used"] != "secondary" {
+			t.Errorf("lm_used = %v, want secondary", result.Metadata["lm_used"])
+		}
+	})
+}
+
+func TestFallbackLM_Stream(t *testing.T) {
+	collect := func(lm LM) (string, error) {
+		chunks, errs := lm.Stream(context.Background(), nil, nil)
+		var sb strings.Builder
+		for c := range chunks {
+			sb.WriteString(c.Content)
+		}
+		return sb.String(), <-errs
+	}
+
+	t.Run("falls back before first chunk", func(t *testing.T) {
+		lm := NewFallbackLM(
+			&mockStreamLM{mockWrapperLM: mockWrapperLM{name: "primary"}, streamErr: errors.New("API request failed with status 502: bad gateway")},
+			&mockStreamLM{mockWrapperLM: mockWrapperLM{name: "secondary"}, chunks: []string{"hello", " world"}},
+		)
+		content, err := collect(lm)
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if content != "hello world" {
+			t.Errorf("content = %q, want %q", content, "hello world")
+		}
+		if lm.LastUsed() != "secondary" {
+			t.Errorf("LastUsed() = %q, want secondary", lm.LastUsed())
+		}
+	})
+
+	t.Run("does not fall back after chunks were sent", func(t *testing.T) {
+		lm := NewFallbackLM(
+			&mockStreamLM{mockWrapperLM: mockWrapperLM{name: "primary"}, chunks: []string{"partial"}, streamErr: errors.New("stream reading error: EOF")},
+			&mockStreamLM{mockWrapperLM: mockWrapperLM{name: "secondary"}, chunks: []string{"other"}},
+		)
+		content, err := collect(lm)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if content != "partial" {
+			t.Errorf("content = %q, want %q", content, "partial")
+		}
+	})
+}
+
+func TestFallbackLM_Capabilities(t *testing.T) {
+	lm := NewFallbackLM(
+		&mockWrapperLM{name: "primary", supportsJSON: true, supportsTools: true},
+		&mockWrapperLM{name: "secondary", supportsJSON: true},
+	)
+	if lm.Name() != "primary" {
+		t.Errorf("Name() = %q, want primary", lm.Name())
+	}
+	if !lm.SupportsJSON() {
+		t.Error("SupportsJSON() should be true when all LMs support JSON")
+	}
+	if lm.SupportsTools() {
+		t.Error("SupportsTools() should be false when any LM lacks tool support")
+	}
+}
+
+func TestStreamWrappers_StopOnCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(LM) LM
+	}{
+		{name: "LMWrapper", wrap: func(lm LM) LM { return NewLMWrapper(lm, NewMemoryCollector(10)) }},
+		{name: "FallbackLM", wrap: func(lm LM) LM { return NewFallbackLM(lm, NewMockLM()) }},
+		{name: "CircuitBreaker", wrap: func(lm LM) LM { return NewCircuitBreaker(lm, CircuitConfig{}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := tt.wrap(NewMockLM().Respond(strings.Repeat("token ", 1000)))
+			ctx, cancel := context.WithCancel(context.Background())
+
+			chunkChan, errChan := lm.Stream(ctx, []Message{{Role: "user", Content: "hi"}}, DefaultGenerateOptions())
+			<-chunkChan
+			// Stop reading and cancel: the wrapper must not stay blocked forwarding chunks
+			cancel()
+
+			select {
+			case err := <-errChan:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expected context.Canceled, got %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("stream did not stop after cancel")
+			}
+			for range chunkChan {
+			}
+		})
+	}
+}
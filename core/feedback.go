@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+// feedbackKey is the context key for corrective feedback
+type feedbackKey struct{}
+
+// WithFeedback returns ctx carrying corrective feedback that modules append to their
+// prompt as a final user message on every LM call made under ctx. Feedback accumulates
+// in the order it was added. Assert uses this to tell the model why its last attempt failed.
+func WithFeedback(ctx context.Context, feedback string) context.Context {
+	existing, _ := ctx.Value(feedbackKey{}).([]string)
+	merged := make([]string, 0, len(existing)+1)
+	merged = append(merged, existing...)
+	merged = append(merged, feedback)
+	return context.WithValue(ctx, feedbackKey{}, merged)
+}
+
+// FeedbackFromContext returns the feedback attached to ctx
+func FeedbackFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	feedback, _ := ctx.Value(feedbackKey{}).([]string)
+	return feedback
+}
+
+// ApplyFeedback returns messages with the feedback attached to ctx appended as a user message
+// The input slice is not modified; messages are returned as-is when there is no feedback.
+func ApplyFeedback(ctx context.Context, messages []Message) []Message {
+	feedback := FeedbackFromContext(ctx)
+	if len(feedback) == 0 {
+		return messages
+	}
+	out := make([]Message, 0, len(messages)+1)
+	out = append(out, messages...)
+	return append(out, Message{Role: "user", Content: strings.Join(feedback, "\n\n")})
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultFileCollectorFlushInterval is how often FileCollector writes buffered entries
+const DefaultFileCollectorFlushInterval = time.Second
+
+// errCollectorClosed is returned when writing to a closed collector
+var errCollectorClosed = errors.New("collector is closed")
+
+// FileCollector appends history entries to a JSONL file through a buffer
+// Buffered entries are written every flush interval, on Flush and on Close,
+// so a process should Close it (or configure WithShutdownContext) before exiting.
+type FileCollector struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	path   string
+	count  int64
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFileCollector opens path for appending and flushes buffered entries every interval
+// An interval <= 0 uses DefaultFileCollectorFlushInterval.
+func NewFileCollector(path string, interval time.Duration) (*FileCollector, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collector file: %w", err)
+	}
+	if interval <= 0 {
+		interval = DefaultFileCollectorFlushInterval
+	}
+
+	c := &FileCollector{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		path:   path,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go c.flushLoop(interval)
+	return c, nil
+}
+
+// flushLoop writes buffered entries every interval until Close
+func (c *FileCollector) flushLoop(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			_ = c.writer.Flush() // Surfaced by the next Flush or Close
+			c.mu.Unlock()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Collect buffers a history entry as one JSON line
+func (c *FileCollector) Collect(entry *HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errCollectorClosed
+	}
+	if _, err := c.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to collector file: %w", err)
+	}
+	c.count++
+	return nil
+}
+
+// Flush writes buffered entries to the file
+func (c *FileCollector) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	if err := c.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush collector file: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered entries and closes the file; later calls are no-ops
+func (c *FileCollector) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	close(c.stop)
+	<-c.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushErr := c.writer.Flush()
+	if err := c.file.Close(); err != nil {
+		return errors.Join(flushErr, fmt.Errorf("failed to close collector file: %w", err))
+	}
+	if flushErr != nil {
+		return fmt.Errorf("failed to flush collector file: %w", flushErr)
+	}
+	return nil
+}
+
+// Count returns the number of entries collected
+func (c *FileCollector) Count() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// Path returns the file path
+func (c *FileCollector) Path() string {
+	return c.path
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readEntries parses the JSONL history entries written to path
+func readEntries(t *testing.T, path string) []HistoryEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestFileCollector(t *testing.T) {
+	t.Run("buffers until flush and close", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		collector, err := NewFileCollector(path, time.Hour)
+		if err != nil {
+			t.Fatalf("NewFileCollector failed: %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := collector.Collect(&HistoryEntry{ID: fmt.Sprintf("entry-%d", i)}); err != nil {
+				t.Fatalf("Collect failed: %v", err)
+			}
+		}
+		if got := readEntries(t, path); len(got) != 0 {
+			t.Errorf("expected entries to stay buffered, found %d on disk", len(got))
+		}
+
+		if err := collector.Flush(context.Background()); err != nil {
+			t.Fatalf("Flush failed: %v", err)
+		}
+		if got := readEntries(t, path); len(got) != 3 || got[2].ID != "entry-2" {
+			t.Errorf("after Flush got %+v, want 3 entries", got)
+		}
+
+		if err := collector.Collect(&HistoryEntry{ID: "entry-3"}); err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+		if err := collector.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if err := collector.Close(); err != nil {
+			t.Errorf("second Close should be a no-op, got %v", err)
+		}
+		if got := readEntries(t, path); len(got) != 4 {
+			t.Errorf("after Close got %d entries, want 4", len(got))
+		}
+		if collector.Count() != 4 {
+			t.Errorf("Count() = %d, want 4", collector.Count())
+		}
+		if err := collector.Collect(&HistoryEntry{ID: "late"}); err == nil {
+			t.Error("Collect after Close should fail")
+		}
+	})
+
+	t.Run("flushes on interval", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		collector, err := NewFileCollector(path, 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("NewFileCollector failed: %v", err)
+		}
+		defer func() { _ = collector.Close() }()
+
+		if err := collector.Collect(&HistoryEntry{ID: "tick"}); err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for len(readEntries(t, path)) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("entry was not flushed on the interval")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("flush honours cancelled context", func(t *testing.T) {
+		collector, err := NewFileCollector(filepath.Join(t.TempDir(), "history.jsonl"), time.Hour)
+		if err != nil {
+			t.Fatalf("NewFileCollector failed: %v", err)
+		}
+		defer func() { _ = collector.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := collector.Flush(ctx); err == nil {
+			t.Error("Flush with a cancelled context should fail")
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		if _, err := NewFileCollector(filepath.Join(t.TempDir(), "missing", "history.jsonl"), 0); err == nil {
+			t.Error("expected an error for a missing directory")
+		}
+	})
+}
+
+func TestWithShutdownContext(t *testing.T) {
+	ResetConfig()
+	t.Cleanup(ResetConfig)
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	collector, err := NewFileCollector(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileCollector failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	Configure(WithCollector(collector), WithShutdownContext(ctx))
+	if err := collector.Collect(&HistoryEntry{ID: "before-shutdown"}); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for collector.Collect(&HistoryEntry{ID: "probe"}) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("collector was not closed after the shutdown context was cancelled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	got := readEntries(t, path)
+	if len(got) == 0 || got[0].ID != "before-shutdown" {
+		t.Errorf("buffered entries were not flushed on shutdown: %+v", got)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	ResetConfig()
+	t.Cleanup(ResetConfig)
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown without a collector = %v, want nil", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	collector, err := NewFileCollector(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileCollector failed: %v", err)
+	}
+	Configure(WithCollector(collector))
+	_ = collector.Collect(&HistoryEntry{ID: "pending"})
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := readEntries(t, path); len(got) != 1 {
+		t.Errorf("got %d entries after Shutdown, want 1", len(got))
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"encoding/json"
+)
+
+// ReturnResultToolName is the function the model calls to return outputs in function-calling mode
+const ReturnResultToolName = "return_result"
+
+// UseFunctionCalling reports whether outputs should be returned through a return_result
+// tool call: the adapter is a JSONAdapter with FunctionCalling enabled and lm supports tools
+func UseFunctionCalling(adapter Adapter, lm LM) bool {
+	jsonAdapter, ok := adapter.(*JSONAdapter)
+	if !ok || !jsonAdapter.FunctionCalling {
+		return false
+	}
+	return CapabilitiesOf(lm).SupportsTools
+}
+
+// ReturnResultTool describes sig's output fields as the parameters of the return_result function
+func ReturnResultTool(sig *Signature) Tool {
+	tool := Tool{
+		Name:        ReturnResultToolName,
+		Description: "Return the final result. Call this exactly once with every output field.",
+	}
+	if sig.Description != "" {
+		tool.Description = sig.Description + "\n\n" + tool.Description
+	}
+
+	for _, field := range sig.OutputFields {
+		param := ToolParameter{
+			Name:        field.Name,
+			Type:        jsonSchemaType(field.Type),
+			Description: field.Description,
+			Required:    !field.Optional,
+		}
+		if field.Type == FieldTypeClass {
+			param.Enum = field.Classes
+		}
+		tool.Parameters = append(tool.Parameters, param)
+	}
+	return tool
+}
+
+// ReturnResultContent returns the arguments of the return_result call in result as JSON
+func ReturnResultContent(result *GenerateResult) (string, bool) {
+	for _, call := range result.ToolCalls {
+		if call.Name != ReturnResultToolName {
+			continue
+		}
+		data, err := json.Marshal(call.Arguments)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+	return "", false
+}
+
+// jsonSchemaType maps a field type to its JSON schema type
+func jsonSchemaType(fieldType FieldType) string {
+	switch fieldType {
+	case FieldTypeInt:
+		return "integer"
+	case FieldTypeFloat:
+		return "number"
+	case FieldTypeBool:
+		return "boolean"
+	case FieldTypeJSON:
+		return "object"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReturnResultTool(t *testing.T) {
+	sig := NewSignature("Classify the review").
+		AddClassOutput("sentiment", []string{"positive", "negative"}, "Overall sentiment").
+		AddOutput("score", FieldTypeFloat, "Confidence").
+		AddOutput("stars", FieldTypeInt, "Star rating").
+		AddOptionalOutput("notes", FieldTypeString, "Extra notes")
+
+	tool := ReturnResultTool(sig)
+	if tool.Name != ReturnResultToolName {
+		t.Errorf("Name = %q, want %q", tool.Name, ReturnResultToolName)
+	}
+
+	want := map[string]struct {
+		typ      string
+		required bool
+	}{
+		"sentiment": {"string", true},
+		"score":     {"number", true},
+		"stars":     {"integer", true},
+		"notes":     {"string", false},
+	}
+	if len(tool.Parameters) != len(want) {
+		t.Fatalf("got %d parameters, want %d", len(tool.Parameters), len(want))
+	}
+	for _, param := range tool.Parameters {
+		w := want[param.Name]
+		if param.Type != w.typ || param.Required != w.required {
+			t.Errorf("parameter %s = (%s, required=%v), want (%s, required=%v)", param.Name, param.Type, param.Required, w.typ, w.required)
+		}
+	}
+	if enum := tool.Parameters[0].Enum; len(enum) != 2 || enum[0] != "positive" {
+		t.Errorf("sentiment enum = %v, want the class labels", enum)
+	}
+}
+
+func TestReturnResultContent(t *testing.T) {
+	result := &GenerateResult{ToolCalls: []ToolCall{
+		{Name: "search", Arguments: map[string]any{"q": "x"}},
+		{Name: ReturnResultToolName, Arguments: map[string]any{"answer": "42"}},
+	}}
+	content, ok := ReturnResultContent(result)
+	if !ok {
+		t.Fatal("expected the return_result call to be found")
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(content), &args); err != nil || args["answer"] != "42" {
+		t.Errorf("content = %q, want the call arguments as JSON", content)
+	}
+
+	if _, ok := ReturnResultContent(&GenerateResult{Content: "{}"}); ok {
+		t.Error("expected no return_result call without tool calls")
+	}
+}
+
+func TestUseFunctionCalling(t *testing.T) {
+	tools := &mockWrapperLM{supportsTools: true}
+	noTools := &mockWrapperLM{supportsJSON: true}
+
+	tests := []struct {
+		name    string
+		adapter Adapter
+		lm      LM
+		want    bool
+	}{
+		{"enabled with tool support", NewJSONAdapter().WithFunctionCalling(true), tools, true},
+		{"falls back without tool support", NewJSONAdapter().WithFunctionCalling(true), noTools, false},
+		{"disabled", NewJSONAdapter(), tools, false},
+		{"other adapter", NewChatAdapter(), tools, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UseFunctionCalling(tt.adapter, tt.lm); got != tt.want {
+				t.Errorf("UseFunctionCalling() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
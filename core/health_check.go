@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrModelNotFound is returned by health checks when the provider does not serve the model
+var ErrModelNotFound = errors.New("model not found")
+
+// HealthCheckLM is implemented by LMs with a cheap way to verify credentials and
+// connectivity, such as a models or status endpoint. Providers and the built-in wrappers
+// implement it; use HealthCheck for any LM.
+type HealthCheckLM interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck verifies that lm can serve requests, so an app can fail fast at startup
+// instead of discovering a bad key mid-run. Providers query their models endpoint where
+// they have one, which also reports an unknown model as ErrModelNotFound; other LMs get
+// a one-token Generate call. Authentication failures read "invalid API key for provider X"
+// and still match *AuthError with errors.As. In dry-run mode it succeeds without a request.
+func HealthCheck(ctx context.Context, lm LM) error {
+	if DryRunEnabled(ctx) {
+		return nil
+	}
+	err := healthCheck(ctx, lm)
+	if err == nil {
+		return nil
+	}
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return fmt.Errorf("invalid API key for provider %s (model %s): %w", authErr.Err.Provider, lm.Name(), err)
+	}
+	return fmt.Errorf("health check failed for %s: %w", lm.Name(), err)
+}
+
+// healthCheck runs lm's own health check, or a one-token Generate call without one
+// Wrappers call it for the LM they wrap, so errors are only annotated once by HealthCheck.
+func healthCheck(ctx context.Context, lm LM) error {
+	if checker, ok := lm.(HealthCheckLM); ok {
+		return checker.HealthCheck(ctx)
+	}
+	_, err := lm.Generate(ctx, []Message{{Role: "user", Content: "ping"}}, &GenerateOptions{MaxTokens: 1})
+	return err
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// healthCheckedLM is a MockLM with its own health check
+type healthCheckedLM struct {
+	*MockLM
+	err   error
+	calls int
+}
+
+func (h *healthCheckedLM) HealthCheck(ctx context.Context) error {
+	h.calls++
+	return h.err
+}
+
+func TestHealthCheck(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	t.Run("generate fallback", func(t *testing.T) {
+		lm := NewMockLM().Respond("pong")
+		if err := HealthCheck(context.Background(), lm); err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+		if lm.CallCount() != 1 {
+			t.Errorf("Generate calls = %d, want 1", lm.CallCount())
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		lm := NewMockLM().WithName("gpt-4o").FailWith(NewAPIError("openai", http.StatusUnauthorized, "bad key", nil))
+		err := HealthCheck(context.Background(), lm)
+		var authErr *AuthError
+		if !errors.As(err, &authErr) || !strings.Contains(err.Error(), "invalid API key for provider openai") {
+			t.Errorf("HealthCheck() error = %v, want an invalid API key error", err)
+		}
+	})
+
+	t.Run("wrappers forward", func(t *testing.T) {
+		inner := &healthCheckedLM{MockLM: NewMockLM(), err: ErrModelNotFound}
+		lm := NewMiddlewareLM(NewLMWrapper(NewDedupLM(inner), NewMemoryCollector(10)))
+		if err := HealthCheck(context.Background(), lm); !errors.Is(err, ErrModelNotFound) {
+			t.Errorf("HealthCheck() error = %v, want the inner health check error", err)
+		}
+		if inner.calls != 1 || inner.CallCount() != 0 {
+			t.Errorf("health checks = %d, Generate calls = %d, want 1 and 0", inner.calls, inner.CallCount())
+		}
+	})
+
+	t.Run("fallback chain", func(t *testing.T) {
+		broken := &healthCheckedLM{MockLM: NewMockLM(), err: errors.New("connection refused")}
+		healthy := &healthCheckedLM{MockLM: NewMockLM()}
+		if err := HealthCheck(context.Background(), NewFallbackLM(broken, healthy)); err != nil {
+			t.Errorf("HealthCheck() error = %v, want healthy while one LM is", err)
+		}
+		if err := HealthCheck(context.Background(), NewFallbackLM(broken, broken)); err == nil {
+			t.Error("HealthCheck() should fail when every LM fails")
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		lm := NewMockLM().FailWith(errors.New("unreachable"))
+		if err := HealthCheck(WithCallDryRun(context.Background(), true), lm); err != nil || lm.CallCount() != 0 {
+			t.Errorf("HealthCheck() = %v after %d calls, want no request in dry-run mode", err, lm.CallCount())
+		}
+	})
+}
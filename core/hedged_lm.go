@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// HedgedLM wraps an LM and issues a second, identical Generate request when the
+// first has not returned within After. Whichever request succeeds first wins and
+// the other is cancelled, trading extra tokens for lower tail latency.
+// Results of hedged calls carry Metadata["hedged"] = true, plus
+// Metadata["hedge_won"] = true when the second request won.
+// Stream calls are passed through without hedging.
+type HedgedLM struct {
+	lm    LM
+	after time.Duration
+}
+
+// NewHedgedLM creates a HedgedLM that hedges calls still running after the given delay
+// A non-positive delay disables hedging.
+func NewHedgedLM(lm LM, after time.Duration) *HedgedLM {
+	return &HedgedLM{lm: lm, after: after}
+}
+
+// hedgeResult is the outcome of one of the two requests
+type hedgeResult struct {
+	result *GenerateResult
+	err    error
+	hedge  bool
+}
+
+// Generate calls the wrapped LM, hedging with a second request after the configured delay
+func (h *HedgedLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	if h.after <= 0 {
+		return h.lm.Generate(ctx, messages, options)
+	}
+
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+
+	// Buffered so the losing request never blocks after Generate returns
+	results := make(chan hedgeResult, 2)
+	call := func(ctx context.Context, hedge bool) {
+		result, err := h.lm.Generate(ctx, messages, options.Copy())
+		results <- hedgeResult{result: result, err: err, hedge: hedge}
+	}
+	go call(primaryCtx, false)
+
+	timer := time.NewTimer(h.after)
+	defer timer.Stop()
+
+	pending := 1
+	hedged := false
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			hedged = true
+			pending++
+			go call(hedgeCtx, true)
+
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				// Cancel the loser to avoid paying for a second completion where the provider allows
+				cancelPrimary()
+				cancelHedge()
+				return h.annotate(res, hedged), nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			// Wait for the other request if one is in flight; a primary failing before the
+			// delay is reported as-is, since retrying errors is the retry layer's job
+			if pending == 0 {
+				return nil, firstErr
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// annotate marks a result produced while a hedge was in flight
+// The metadata map is copied so cached results are not mutated.
+func (h *HedgedLM) annotate(res hedgeResult, hedged bool) *GenerateResult {
+	if !hedged || res.result == nil {
+		return res.result
+	}
+	result := *res.result
+	metadata := make(map[string]any, len(res.result.Metadata)+2)
+	for k, v := range res.result.Metadata {
+		metadata[k] = v
+	}
+	metadata["hedged"] = true
+	if res.hedge {
+		metadata["hedge_won"] = true
+	}
+	result.Metadata = metadata
+	return &result
+}
+
+// Stream passes through to the wrapped LM without hedging
+func (h *HedgedLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	return h.lm.Stream(ctx, messages, options)
+}
+
+// Name returns the wrapped LM's name
+func (h *HedgedLM) Name() string {
+	return h.lm.Name()
+}
+
+// SupportsJSON reports whether the wrapped LM supports JSON mode
+func (h *HedgedLM) SupportsJSON() bool {
+	return h.lm.SupportsJSON()
+}
+
+// SupportsTools reports whether the wrapped LM supports tool calling
+func (h *HedgedLM) SupportsTools() bool {
+	return h.lm.SupportsTools()
+}
+
+// Capabilities reports the wrapped LM's capabilities
+func (h *HedgedLM) Capabilities() Capabilities {
+	return CapabilitiesOf(h.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (h *HedgedLM) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, h.lm)
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hedgeTestLM serves call i (0 = primary, 1 = hedge) with delays[i] and errs[i],
+// recording whether each call's context was cancelled
+type hedgeTestLM struct {
+	mockWrapperLM
+	delays    []time.Duration
+	errs      []error
+	calls     atomic.Int32
+	cancelled atomic.Int32
+}
+
+func newHedgeTestLM(delays []time.Duration, errs ...error) *hedgeTestLM {
+	h := &hedgeTestLM{delays: delays, errs: append(errs, nil, nil)}
+	h.generateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+		i := int(h.calls.Add(1)) - 1
+		select {
+		case <-time.After(h.delays[i]):
+		case <-ctx.Done():
+			h.cancelled.Add(1)
+			return nil, ctx.Err()
+		}
+		if h.errs[i] != nil {
+			return nil, h.errs[i]
+		}
+		return &GenerateResult{Content: []string{"primary", "hedge"}[i], Metadata: map[string]any{"provider": "x"}}, nil
+	}
+	return h
+}
+
+func TestHedgedLM_Generate(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name          string
+		delays        []time.Duration
+		errs          []error
+		wantContent   string
+		wantErr       error
+		wantCalls     int32
+		wantHedged    bool
+		wantHedgeWon  bool
+		wantCancelled int32
+	}{
+		{
+			name:        "fast primary is not hedged",
+			delays:      []time.Duration{0},
+			wantContent: "primary",
+			wantCalls:   1,
+		},
+		{
+			name:          "hedge wins when primary is slow",
+			delays:        []time.Duration{time.Second, 0},
+			wantContent:   "hedge",
+			wantCalls:     2,
+			wantHedged:    true,
+			wantHedgeWon:  true,
+			wantCancelled: 1,
+		},
+		{
+			name:          "primary can still win after hedging",
+			delays:        []time.Duration{40 * time.Millisecond, time.Second},
+			wantContent:   "primary",
+			wantCalls:     2,
+			wantHedged:    true,
+			wantCancelled: 1,
+		},
+		{
+			name:         "failed primary falls back to the hedge",
+			delays:       []time.Duration{40 * time.Millisecond, 60 * time.Millisecond},
+			errs:         []error{errBoom},
+			wantContent:  "hedge",
+			wantCalls:    2,
+			wantHedged:   true,
+			wantHedgeWon: true,
+		},
+		{
+			name:      "early primary error is returned without hedging",
+			delays:    []time.Duration{0},
+			errs:      []error{errBoom},
+			wantErr:   errBoom,
+			wantCalls: 1,
+		},
+		{
+			name:      "both failing returns the first error",
+			delays:    []time.Duration{40 * time.Millisecond, 60 * time.Millisecond},
+			errs:      []error{errBoom, errors.New("second")},
+			wantErr:   errBoom,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newHedgeTestLM(tt.delays, tt.errs...)
+			lm := NewHedgedLM(mock, 20*time.Millisecond)
+
+			result, err := lm.Generate(context.Background(), nil, DefaultGenerateOptions())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Generate() error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if result.Content != tt.wantContent {
+					t.Errorf("Content = %q, want %q", result.Content, tt.wantContent)
+				}
+				if hedged, _ := result.Metadata["hedged"].(bool); hedged != tt.wantHedged {
+					t.Errorf("Metadata[hedged] = %v, want %v", hedged, tt.wantHedged)
+				}
+				if won, _ := result.Metadata["hedge_won"].(bool); won != tt.wantHedgeWon {
+					t.Errorf("Metadata[hedge_won] = %v, want %v", won, tt.wantHedgeWon)
+				}
+				if result.Metadata["provider"] != "x" {
+					t.Error("provider metadata should be preserved")
+				}
+			}
+
+			if got := mock.calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			// The loser observes cancellation shortly after Generate returns
+			deadline := time.Now().Add(time.Second)
+			for mock.cancelled.Load() < tt.wantCancelled && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := mock.cancelled.Load(); got != tt.wantCancelled {
+				t.Errorf("cancelled calls = %d, want %d", got, tt.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestHedgedLM_ContextCancelled(t *testing.T) {
+	mock := newHedgeTestLM([]time.Duration{time.Second, time.Second})
+	lm := NewHedgedLM(mock, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := lm.Generate(ctx, nil, DefaultGenerateOptions()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Generate() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Generate() took %v, want prompt return on cancellation", elapsed)
+	}
+}
+
+func TestNewLM_WithHedging(t *testing.T) {
+	defer ResetConfig()
+	RegisterLM("hedge-provider", func(model string) LM {
+		return &mockLM{}
+	})
+	defer func() {
+		registryLock.Lock()
+		delete(lmRegistry, "hedge-provider")
+		registryLock.Unlock()
+	}()
+
+	ResetConfig()
+	Configure(WithHedging(100 * time.Millisecond))
+	if GetSettings().HedgeAfter != 100*time.Millisecond {
+		t.Errorf("HedgeAfter = %v, want 100ms", GetSettings().HedgeAfter)
+	}
+
+	lm, err := NewLM(context.Background(), "hedge-provider/model")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	if _, ok := lm.(*HedgedLM); !ok {
+		t.Fatalf("expected *HedgedLM, got %T", lm)
+	}
+}
@@ -1,7 +1,11 @@
 package core
 
+import "sync"
+
 // History manages conversation history for multi-turn interactions
+// It is safe for concurrent use; readers receive copies of the messages.
 type History struct {
+	mu       sync.RWMutex
 	messages []Message
 	maxSize  int // 0 = unlimited
 }
@@ -24,6 +28,9 @@ func NewHistoryWithLimit(maxSize int) *History {
 
 // Add appends a message to the history
 func (h *History) Add(message Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.messages = append(h.messages, message)
 
 	// Trim if exceeds max size (keep most recent)
@@ -47,49 +54,59 @@ func (h *History) AddSystemMessage(content string) {
 	h.Add(Message{Role: "system", Content: content})
 }
 
-// Get returns all messages in the history
+// Get returns a copy of all messages in the history
 func (h *History) Get() []Message {
-	return h.messages
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return copyMessages(h.messages)
 }
 
-// GetLast returns the last n messages from history
+// GetLast returns a copy of the last n messages from history
 func (h *History) GetLast(n int) []Message {
 	if n <= 0 {
 		return []Message{}
 	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	if n >= len(h.messages) {
-		return h.messages
+		return copyMessages(h.messages)
 	}
-	return h.messages[len(h.messages)-n:]
+	return copyMessages(h.messages[len(h.messages)-n:])
 }
 
 // Clear removes all messages from history
 func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.messages = []Message{}
 }
 
 // Len returns the number of messages in history
 func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	return len(h.messages)
 }
 
 // IsEmpty returns true if history has no messages
 func (h *History) IsEmpty() bool {
-	return len(h.messages) == 0
+	return h.Len() == 0
 }
 
 // Clone creates a deep copy of the history
 func (h *History) Clone() *History {
-	cloned := &History{
-		messages: make([]Message, len(h.messages)),
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return &History{
+		messages: copyMessages(h.messages),
 		maxSize:  h.maxSize,
 	}
-	copy(cloned.messages, h.messages)
-	return cloned
 }
 
 // Truncate keeps only the first n messages
 func (h *History) Truncate(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if n > 0 && n < len(h.messages) {
 		h.messages = h.messages[:n]
 	}
@@ -97,9 +114,18 @@ func (h *History) Truncate(n int) {
 
 // RemoveFirst removes the first n messages
 func (h *History) RemoveFirst(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if n > 0 && n < len(h.messages) {
 		h.messages = h.messages[n:]
 	} else if n >= len(h.messages) {
-		h.Clear()
+		h.messages = []Message{}
 	}
 }
+
+// copyMessages returns a copy of messages so callers never share the backing array
+func copyMessages(messages []Message) []Message {
+	copied := make([]Message, len(messages))
+	copy(copied, messages)
+	return copied
+}
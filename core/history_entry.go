@@ -1,12 +1,20 @@
 package core
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
-// HistoryEntry represents a rich structured event for LM interactions
+// HistoryEntryKindTool marks history entries that record a tool execution
+const HistoryEntryKindTool = "tool"
+
+// HistoryEntry represents a rich structured event for LM interactions and tool executions
 type HistoryEntry struct {
-	ID        string    `json:"id"`         // UUID for this call
-	Timestamp time.Time `json:"timestamp"`  // Call timestamp
-	SessionID string    `json:"session_id"` // Conversation session identifier
+	ID        string    `json:"id"`                   // UUID for this call
+	Kind      string    `json:"kind,omitempty"`       // Empty for LM calls, HistoryEntryKindTool for tool executions
+	Timestamp time.Time `json:"timestamp"`            // Call timestamp
+	SessionID string    `json:"session_id"`           // Conversation session identifier
+	RequestID string    `json:"request_id,omitempty"` // Request ID of the module call that ran the tool
 
 	// Provider and model info
 	Provider string `json:"provider"` // "openrouter", "openai", etc.
@@ -24,13 +32,28 @@ type HistoryEntry struct {
 	// Cache metadata
 	Cache CacheMeta `json:"cache"`
 
+	// Request tags from WithTags (user_id, feature, ...) for filtering and cost attribution
+	Tags map[string]string `json:"tags,omitempty"`
+
 	// Provider-specific metadata (request IDs, rate limits, headers, etc.)
 	ProviderMeta map[string]any `json:"provider_meta,omitempty"`
 
+	// Tool execution details (tool entries only)
+	Tool *ToolMeta `json:"tool,omitempty"`
+
 	// Error details (if failed)
 	Error *ErrorMeta `json:"error,omitempty"`
 }
 
+// ToolMeta describes a tool execution
+type ToolMeta struct {
+	Name       string         `json:"name"`
+	CallID     string         `json:"call_id,omitempty"` // ID of the model's tool call
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Result     string         `json:"result"`      // Observation returned to the model
+	DurationMs int64          `json:"duration_ms"` // Execution time in milliseconds
+}
+
 // RequestMeta contains metadata about the request
 type RequestMeta struct {
 	Messages       []Message        `json:"messages"`
@@ -71,6 +94,9 @@ type Collector interface {
 	// Collect records a history entry
 	Collect(entry *HistoryEntry) error
 
+	// Flush writes any buffered entries to their destination
+	Flush(ctx context.Context) error
+
 	// Close closes the collector and flushes any pending entries
 	Close() error
 }
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// historyJSON is the serialized form of a History
+type historyJSON struct {
+	MaxSize  int           `json:"max_size,omitempty"`
+	Messages []messageJSON `json:"messages"`
+}
+
+// messageJSON is the serialized form of a Message
+type messageJSON struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	ToolID    string         `json:"tool_id,omitempty"`
+	ToolCalls []toolCallJSON `json:"tool_calls,omitempty"`
+	Images    []ImageContent `json:"images,omitempty"`
+}
+
+// toolCallJSON is the serialized form of a ToolCall
+type toolCallJSON struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// MarshalJSON encodes the messages and size limit of the history, so a conversation can
+// be persisted between requests and restored with LoadHistory. Roles, contents, tool
+// calls (with int and float arguments kept apart), tool IDs and images are preserved.
+func (h *History) MarshalJSON() ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	wire := historyJSON{MaxSize: h.maxSize, Messages: make([]messageJSON, 0, len(h.messages))}
+	for _, msg := range h.messages {
+		m := messageJSON{Role: msg.Role, Content: msg.Content, ToolID: msg.ToolID, Images: msg.Images}
+		for _, call := range msg.ToolCalls {
+			m.ToolCalls = append(m.ToolCalls, toolCallJSON{ID: call.ID, Name: call.Name, Arguments: typedJSONMap(call.Arguments)})
+		}
+		wire.Messages = append(wire.Messages, m)
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON replaces the history with one written by MarshalJSON, keeping at most
+// the most recent max_size messages when the encoded history has a limit
+func (h *History) UnmarshalJSON(data []byte) error {
+	var wire historyJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&wire); err != nil {
+		return err
+	}
+	if wire.MaxSize < 0 {
+		return fmt.Errorf("invalid history max_size %d", wire.MaxSize)
+	}
+
+	messages := make([]Message, 0, len(wire.Messages))
+	for _, m := range wire.Messages {
+		msg := Message{Role: m.Role, Content: m.Content, ToolID: m.ToolID, Images: m.Images}
+		for _, call := range m.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{ID: call.ID, Name: call.Name, Arguments: untypedJSONMap(call.Arguments)})
+		}
+		messages = append(messages, msg)
+	}
+	if wire.MaxSize > 0 && len(messages) > wire.MaxSize {
+		messages = messages[len(messages)-wire.MaxSize:]
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = messages
+	h.maxSize = wire.MaxSize
+	return nil
+}
+
+// LoadHistory restores a history written by History.MarshalJSON, including its size limit
+func LoadHistory(data []byte) (*History, error) {
+	h := NewHistory()
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	return h, nil
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestHistory_JSONRoundTrip(t *testing.T) {
+	h := NewHistoryWithLimit(5)
+	h.AddSystemMessage("Be brief.")
+	h.AddUserMessage("What's the weather in Paris?")
+	h.Add(Message{
+		Role:      "assistant",
+		ToolCalls: []ToolCall{{ID: "call_1", Name: "weather", Arguments: map[string]any{"city": "Paris", "days": 3, "threshold": 2.0}}},
+	})
+	h.Add(Message{Role: "tool", ToolID: "call_1", Content: `{"temp": 21}`})
+	h.Add(Message{Role: "user", Content: "And this?", Images: []ImageContent{NewImageFromURL("https://example.com/sky.png")}})
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	restored, err := LoadHistory(data)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+
+	if got, want := restored.Get(), h.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored messages = %+v\nwant %+v", got, want)
+	}
+
+	// The restored history keeps its limit
+	restored.AddAssistantMessage("Clear skies.")
+	if restored.Len() != 5 || restored.Get()[0].Role != "user" {
+		t.Errorf("restored history should trim to its limit of 5, got %+v", restored.Get())
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	h, err := LoadHistory([]byte(`{"max_size": 2, "messages": [
+		{"role": "user", "content": "a"}, {"role": "assistant", "content": "b"}, {"role": "user", "content": "c"}]}`))
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if got := h.Get(); len(got) != 2 || got[0].Content != "b" || got[1].Content != "c" {
+		t.Errorf("messages = %+v, want the two most recent", got)
+	}
+
+	empty, err := LoadHistory([]byte(`{"messages": []}`))
+	if err != nil || !empty.IsEmpty() {
+		t.Errorf("LoadHistory(empty) = %+v, %v", empty, err)
+	}
+
+	for _, bad := range []string{`not json`, `{"max_size": -1, "messages": []}`} {
+		if _, err := LoadHistory([]byte(bad)); err == nil {
+			t.Errorf("LoadHistory(%s) should fail", bad)
+		}
+	}
+}
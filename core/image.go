@@ -0,0 +1,143 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrImageInputUnsupported is returned when image inputs are sent to a model without vision support
+var ErrImageInputUnsupported = errors.New("model does not support image inputs")
+
+// ImageContent is an image attached to a message, referenced by URL or carried inline
+type ImageContent struct {
+	URL      string `json:"url,omitempty"`       // http(s) or data: URL
+	Data     []byte `json:"data,omitempty"`      // Raw image bytes (base64-encoded when sent)
+	MimeType string `json:"mime_type,omitempty"` // e.g. "image/png"; detected from Data if empty
+}
+
+// NewImageFromURL creates an image reference from an http(s) or data: URL
+func NewImageFromURL(url string) ImageContent {
+	return ImageContent{URL: url}
+}
+
+// NewImageFromBytes creates an inline image; mimeType is detected when empty
+func NewImageFromBytes(data []byte, mimeType string) ImageContent {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return ImageContent{Data: data, MimeType: mimeType}
+}
+
+// DataURL returns the URL to send to the provider, encoding inline data as a base64 data: URL
+func (i ImageContent) DataURL() string {
+	if i.URL != "" {
+		return i.URL
+	}
+	mimeType := i.MimeType
+	if mimeType == "" {
+		mimeType = http.DetectContentType(i.Data)
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(i.Data))
+}
+
+// ImageFromValue converts an image field value ([]byte, URL string or ImageContent) into ImageContent
+func ImageFromValue(value any) (ImageContent, error) {
+	switch v := value.(type) {
+	case ImageContent:
+		return v, nil
+	case *ImageContent:
+		if v == nil {
+			return ImageContent{}, fmt.Errorf("image is nil")
+		}
+		return *v, nil
+	case []byte:
+		if len(v) == 0 {
+			return ImageContent{}, fmt.Errorf("image data is empty")
+		}
+		img := NewImageFromBytes(v, "")
+		if !strings.HasPrefix(img.MimeType, "image/") {
+			return ImageContent{}, fmt.Errorf("data is not a recognized image (detected %s)", img.MimeType)
+		}
+		return img, nil
+	case string:
+		if !isImageURL(v) {
+			return ImageContent{}, fmt.Errorf("image string must be an http(s) or data: URL, got %q", truncateString(v, 50))
+		}
+		return NewImageFromURL(v), nil
+	default:
+		return ImageContent{}, fmt.Errorf("expected []byte, URL string or ImageContent, got %T", value)
+	}
+}
+
+func isImageURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "data:")
+}
+
+// HasImages reports whether any message carries image content
+func HasImages(messages []Message) bool {
+	for _, msg := range messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatInputValue renders an input value for a prompt
+// Image fields are collected into images and replaced with a placeholder reference
+func formatInputValue(field Field, value any, images *[]ImageContent) (string, error) {
+	if field.Type != FieldTypeImage {
+		return fmt.Sprintf("%v", value), nil
+	}
+	img, err := ImageFromValue(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid image input %s: %w", field.Name, err)
+	}
+	*images = append(*images, img)
+	return fmt.Sprintf("[image %d attached]", len(*images)), nil
+}
+
+var (
+	visionModelPrefixes = []string{
+		"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-4-vision", "gpt-5", "o1", "o3", "o4",
+		"claude-3", "claude-sonnet-4", "claude-opus-4", "claude-haiku-4",
+		"gemini", "gemma-3", "llama-4", "pixtral", "grok-4",
+	}
+	visionModelMarkers = []string{"vision", "-vl", "vl-"}
+	visionModelsMu     sync.RWMutex
+)
+
+// RegisterVisionModel marks models whose name (without organization prefix) starts with prefix as vision-capable
+func RegisterVisionModel(prefix string) {
+	visionModelsMu.Lock()
+	defer visionModelsMu.Unlock()
+	visionModelPrefixes = append(visionModelPrefixes, strings.ToLower(prefix))
+}
+
+// IsVisionModel reports whether a model is known to accept image inputs
+// Matches on the model name without organization prefix, e.g. "anthropic/claude-3.5-sonnet" -> "claude-3.5-sonnet"
+func IsVisionModel(model string) bool {
+	name := strings.ToLower(model)
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	for _, marker := range visionModelMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+
+	visionModelsMu.RLock()
+	defer visionModelsMu.RUnlock()
+	for _, prefix := range visionModelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// pngHeader is the 8-byte PNG signature, enough for content type detection
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+func TestImageFromValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		wantURL string
+		wantErr bool
+	}{
+		{"https URL", "https://example.com/cat.png", "https://example.com/cat.png", false},
+		{"data URL", "data:image/png;base64,AAAA", "data:image/png;base64,AAAA", false},
+		{"png bytes", pngHeader, "data:image/png;base64,iVBORw0KGgo=", false},
+		{"ImageContent", NewImageFromURL("https://example.com/a.jpg"), "https://example.com/a.jpg", false},
+		{"ImageContent pointer", &ImageContent{URL: "https://example.com/b.jpg"}, "https://example.com/b.jpg", false},
+		{"plain string", "cat.png", "", true},
+		{"empty bytes", []byte{}, "", true},
+		{"non-image bytes", []byte("hello world"), "", true},
+		{"unsupported type", 42, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := ImageFromValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImageFromValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && img.DataURL() != tt.wantURL {
+				t.Errorf("DataURL() = %q, want %q", img.DataURL(), tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestIsVisionModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"gpt-4o", true},
+		{"gpt-4o-mini", true},
+		{"openai/gpt-4.1", true},
+		{"anthropic/claude-3.5-sonnet", true},
+		{"google/gemini-2.5-pro", true},
+		{"qwen/qwen2.5-vl-72b-instruct", true},
+		{"meta-llama/llama-3.2-90b-vision-instruct", true},
+		{"gpt-3.5-turbo", false},
+		{"openai/gpt-oss-120b", false},
+		{"meta-llama/llama-3.3-70b-instruct", false},
+		{"deepseek/deepseek-v3.1-terminus", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := IsVisionModel(tt.model); got != tt.want {
+				t.Errorf("IsVisionModel(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterVisionModel(t *testing.T) {
+	if IsVisionModel("custom/acme-pix-1") {
+		t.Fatal("expected custom model to be unknown before registration")
+	}
+	RegisterVisionModel("acme-pix")
+	if !IsVisionModel("custom/acme-pix-1") {
+		t.Error("expected custom model to be vision-capable after registration")
+	}
+}
+
+func TestAdapters_FormatImageInput(t *testing.T) {
+	sig := NewSignature("Describe the image").
+		AddInput("photo", FieldTypeImage, "Photo to describe").
+		AddOutput("description", FieldTypeString, "Description")
+
+	adapters := map[string]Adapter{
+		"JSONAdapter":    NewJSONAdapter(),
+		"ChatAdapter":    NewChatAdapter(),
+		"TwoStepAdapter": NewTwoStepAdapter(nil),
+	}
+
+	for name, adapter := range adapters {
+		t.Run(name, func(t *testing.T) {
+			messages, err := adapter.Format(sig, map[string]any{"photo": pngHeader}, nil)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			last := messages[len(messages)-1]
+			if len(last.Images) != 1 {
+				t.Fatalf("expected 1 image attached, got %d", len(last.Images))
+			}
+			if last.Images[0].MimeType != "image/png" {
+				t.Errorf("MimeType = %q, want image/png", last.Images[0].MimeType)
+			}
+			if !strings.Contains(last.Content, "[image 1 attached]") {
+				t.Errorf("expected image placeholder in prompt, got:\n%s", last.Content)
+			}
+			if strings.Contains(last.Content, "137 80 78 71") {
+				t.Error("raw image bytes should not be rendered into the prompt")
+			}
+		})
+	}
+
+	t.Run("invalid image", func(t *testing.T) {
+		_, err := NewJSONAdapter().Format(sig, map[string]any{"photo": "not-a-url"}, nil)
+		if err == nil || !strings.Contains(err.Error(), "invalid image input photo") {
+			t.Errorf("expected invalid image error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/assagman/dsgo/internal/retry"
+)
+
+// defaultKeyPark is how long a rate-limited key is parked when the 429 has no Retry-After
+const defaultKeyPark = 30 * time.Second
+
+// KeyStrategy chooses which API key of a KeyPool serves the next request
+type KeyStrategy int
+
+const (
+	// KeyRoundRobin cycles through the keys in order, skipping parked ones
+	KeyRoundRobin KeyStrategy = iota
+	// KeyLeastRecentlyLimited prefers the key whose last 429 is oldest (or that never had one)
+	KeyLeastRecentlyLimited
+)
+
+// KeyPool rotates requests across several API keys of one provider to multiply
+// per-key rate limits. A key that returns 429 is parked until its Retry-After
+// elapses. List a key more than once to give it a larger share of the traffic.
+// It is safe for concurrent use.
+type KeyPool struct {
+	mu          sync.Mutex
+	keys        []string
+	strategy    KeyStrategy
+	parkedUntil []time.Time
+	lastLimited []time.Time
+	next        int
+	now         func() time.Time
+}
+
+// NewKeyPool creates a pool over keys using strategy; surrounding spaces are trimmed
+// and empty keys ignored
+func NewKeyPool(keys []string, strategy KeyStrategy) *KeyPool {
+	pool := &KeyPool{strategy: strategy, now: time.Now}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			pool.keys = append(pool.keys, key)
+		}
+	}
+	pool.parkedUntil = make([]time.Time, len(pool.keys))
+	pool.lastLimited = make([]time.Time, len(pool.keys))
+	return pool
+}
+
+// Len returns the number of keys in the pool
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Next returns the key for the next request. When every key is parked it returns
+// the one whose parking ends first rather than blocking; "" means the pool is empty.
+func (p *KeyPool) Next() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return ""
+	}
+
+	now := p.now()
+	best := -1
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		if best < 0 || p.better(idx, best, now) {
+			best = idx
+		}
+		if p.strategy == KeyRoundRobin && !p.parkedUntil[idx].After(now) {
+			best = idx
+			break
+		}
+	}
+	p.next = (best + 1) % len(p.keys)
+	return p.keys[best]
+}
+
+// better reports whether key a should be chosen over key b
+func (p *KeyPool) better(a, b int, now time.Time) bool {
+	aParked, bParked := p.parkedUntil[a].After(now), p.parkedUntil[b].After(now)
+	switch {
+	case aParked && bParked:
+		return p.parkedUntil[a].Before(p.parkedUntil[b])
+	case aParked != bParked:
+		return !aParked
+	case p.strategy == KeyLeastRecentlyLimited:
+		return p.lastLimited[a].Before(p.lastLimited[b])
+	}
+	return false
+}
+
+// Park takes key out of rotation for d (defaultKeyPark when d is not positive)
+func (p *KeyPool) Park(key string, d time.Duration) {
+	if d <= 0 {
+		d = defaultKeyPark
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for i, k := range p.keys {
+		if k == key {
+			p.parkedUntil[i] = now.Add(d)
+			p.lastLimited[i] = now
+		}
+	}
+}
+
+// Do sends a request with the next key. When a key answers 429 it is parked and the
+// next key is tried straight away, so one limited key does not stall the call while a
+// spare key is free; once every key is parked the 429 is returned for the caller's
+// retry policy. It returns the response and the key that produced it. A nil pool
+// sends with fallbackKey.
+func (p *KeyPool) Do(fallbackKey string, send func(key string) (*http.Response, error)) (*http.Response, string, error) {
+	if p == nil {
+		resp, err := send(fallbackKey)
+		return resp, fallbackKey, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		key := p.Next()
+		resp, err := send(key)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, key, err
+		}
+		p.Observe(key, resp)
+		if attempt >= len(p.keys) || !p.available() {
+			return resp, key, nil
+		}
+		_ = resp.Body.Close()
+	}
+}
+
+// available reports whether any key is not parked
+func (p *KeyPool) available() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for _, until := range p.parkedUntil {
+		if !until.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Observe parks key when resp is a 429, for the response's Retry-After.
+// It is a no-op on a nil pool or any other response.
+func (p *KeyPool) Observe(key string, resp *http.Response) {
+	if p == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	p.Park(key, retry.ParseRetryAfter(resp.Header))
+}
+
+// MaskAPIKey shortens key to its last four characters for logs and metadata
+func MaskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "..." + key[len(key)-4:]
+}
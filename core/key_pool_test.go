@@ -0,0 +1,137 @@
+package core
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool := NewKeyPool([]string{"a", "", "b", "c"}, KeyRoundRobin)
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, pool.Next())
+	}
+	if want := []string{"a", "b", "c", "a"}; !slices.Equal(got, want) {
+		t.Errorf("rotation = %v, want %v", got, want)
+	}
+}
+
+func TestKeyPool_ParksRateLimitedKey(t *testing.T) {
+	now := time.Now()
+	pool := NewKeyPool([]string{"a", "b"}, KeyRoundRobin)
+	pool.now = func() time.Time { return now }
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"10"}}}
+	pool.Observe("a", resp)
+	pool.Observe("b", &http.Response{StatusCode: http.StatusOK})
+
+	for i := 0; i < 3; i++ {
+		if key := pool.Next(); key != "b" {
+			t.Fatalf("Next() = %q while a is parked, want b", key)
+		}
+	}
+
+	now = now.Add(11 * time.Second)
+	if key := pool.Next(); key != "a" {
+		t.Errorf("Next() = %q after Retry-After elapsed, want a", key)
+	}
+}
+
+func TestKeyPool_AllParked(t *testing.T) {
+	now := time.Now()
+	pool := NewKeyPool([]string{"a", "b"}, KeyRoundRobin)
+	pool.now = func() time.Time { return now }
+	pool.Park("a", time.Minute)
+	pool.Park("b", time.Second)
+
+	if key := pool.Next(); key != "b" {
+		t.Errorf("Next() = %q with every key parked, want the one unparked soonest", key)
+	}
+}
+
+func TestKeyPool_LeastRecentlyLimited(t *testing.T) {
+	now := time.Now()
+	pool := NewKeyPool([]string{"a", "b", "c"}, KeyLeastRecentlyLimited)
+	pool.now = func() time.Time { return now }
+	pool.Park("b", time.Second)
+	now = now.Add(time.Second)
+	pool.Park("a", time.Second)
+	now = now.Add(2 * time.Second)
+
+	// c was never limited; of the others b was limited longest ago
+	want := []string{"c", "c"}
+	for _, w := range want {
+		if key := pool.Next(); key != w {
+			t.Errorf("Next() = %q, want %q", key, w)
+		}
+	}
+	pool.Park("c", time.Second)
+	if key := pool.Next(); key != "b" {
+		t.Errorf("Next() = %q, want b", key)
+	}
+}
+
+func TestKeyPool_Concurrent(t *testing.T) {
+	pool := NewKeyPool([]string{"a", "b", "c"}, KeyLeastRecentlyLimited)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := pool.Next()
+			pool.Observe(key, &http.Response{StatusCode: http.StatusTooManyRequests})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestKeyPoolFor(t *testing.T) {
+	defer globalSettings.Reset()
+
+	if KeyPoolFor("openai") != nil {
+		t.Error("expected no pool before WithAPIKeys")
+	}
+	Configure(WithAPIKeys("openai", []string{"k1", "k2"}, KeyRoundRobin))
+	if pool := KeyPoolFor("openai"); pool == nil || pool.Len() != 2 {
+		t.Errorf("KeyPoolFor() = %v, want a pool of 2 keys", pool)
+	}
+	if MaskAPIKey("sk-abcdef1234") != "...1234" {
+		t.Errorf("MaskAPIKey() = %q", MaskAPIKey("sk-abcdef1234"))
+	}
+}
+
+func TestKeyPool_DoSkipsLimitedKey(t *testing.T) {
+	pool := NewKeyPool([]string{"a", "b"}, KeyRoundRobin)
+	var sent []string
+	send := func(key string) (*http.Response, error) {
+		sent = append(sent, key)
+		status := http.StatusOK
+		if key == "a" {
+			status = http.StatusTooManyRequests
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	}
+
+	resp, key, err := pool.Do("", send)
+	if err != nil || resp.StatusCode != http.StatusOK || key != "b" {
+		t.Fatalf("Do() = %d, %q, %v; want 200 from b", resp.StatusCode, key, err)
+	}
+	if !slices.Equal(sent, []string{"a", "b"}) {
+		t.Errorf("sent with %v, want [a b]", sent)
+	}
+
+	// With every key limited the 429 is handed back to the caller's retry policy
+	pool.Park("b", time.Minute)
+	resp, _, _ = pool.Do("", send)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Do() status = %d with every key parked, want 429", resp.StatusCode)
+	}
+
+	var nilPool *KeyPool
+	if _, key, _ := nilPool.Do("single", send); key != "single" {
+		t.Errorf("nil pool sent with %q, want the fallback key", key)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"strconv"
+
+	"github.com/assagman/dsgo/internal/ids"
 )
 
 // Common errors
@@ -19,8 +21,9 @@ type StreamCallback func(Chunk)
 type Message struct {
 	Role      string // "system", "user", "assistant", "tool"
 	Content   string
-	ToolID    string     // For tool responses
-	ToolCalls []ToolCall // For assistant messages with tool calls
+	ToolID    string         // For tool responses
+	ToolCalls []ToolCall     // For assistant messages with tool calls
+	Images    []ImageContent // Image parts for multimodal messages
 }
 
 // GenerateOptions contains options for LM generation
@@ -37,11 +40,33 @@ type GenerateOptions struct {
 	StreamCallback   StreamCallback `json:"-"` // Optional callback for each streaming chunk
 	FrequencyPenalty float64
 	PresencePenalty  float64
+	// LogitBias adds a bias (-100 to 100) to the logits of token IDs, sent as logit_bias by
+	// providers that support it (see JSONAdapter.WithConstrainedEnums)
+	LogitBias map[int]int
+	// IdempotencyKey is sent as the Idempotency-Key header by providers that support it, so a
+	// retry after an ambiguous failure is de-duplicated server-side (empty = a new key per call)
+	IdempotencyKey string
+	// ReasoningEffort asks reasoning models to think less or more (ReasoningEffortLow, Medium
+	// or High), sent as OpenAI reasoning_effort or OpenRouter reasoning.effort (empty = model default)
+	ReasoningEffort string
+	// ThinkingBudgetTokens caps the tokens reasoning models spend thinking, sent as OpenRouter
+	// reasoning.max_tokens (mapped to Gemini thinkingConfig) or Claude thinking.budget_tokens on
+	// Bedrock; it takes precedence over ReasoningEffort where a provider accepts only one (0 = model default)
+	ThinkingBudgetTokens int
 }
 
+// Reasoning effort levels for GenerateOptions.ReasoningEffort
+// Providers without a reasoning control ignore them.
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
 // GenerateResult represents the result of an LM generation
 type GenerateResult struct {
 	Content      string
+	Reasoning    string // Thinking returned separately by reasoning models (e.g. reasoning_content)
 	ToolCalls    []ToolCall
 	FinishReason string
 	Usage        Usage
@@ -64,10 +89,21 @@ type Usage struct {
 	Latency          int64   // Latency in milliseconds
 }
 
+// Add accumulates other into u
+// Latency is summed as well, so for concurrent calls it is total LM time rather than wall-clock time.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.Cost += other.Cost
+	u.Latency += other.Latency
+}
+
 // Chunk represents a streaming response chunk from the LM
 type Chunk struct {
 	Content      string     // Incremental content delta (cleaned of internal markers by default)
-	ToolCalls    []ToolCall // Incremental tool call deltas
+	Reasoning    string     // Incremental reasoning delta, for streams that separate thinking from the answer
+	ToolCalls    []ToolCall // Completed tool calls, emitted once their streamed arguments are fully assembled
 	FinishReason string     // Set when stream ends ("stop", "length", "tool_calls", etc.)
 	Usage        Usage      // Token usage (typically only set in final chunk)
 }
@@ -145,6 +181,10 @@ func (o *GenerateOptions) Copy() *GenerateOptions {
 		StreamCallback:   o.StreamCallback, // Copy reference (function pointer)
 		FrequencyPenalty: o.FrequencyPenalty,
 		PresencePenalty:  o.PresencePenalty,
+		IdempotencyKey:   o.IdempotencyKey,
+
+		ReasoningEffort:      o.ReasoningEffort,
+		ThinkingBudgetTokens: o.ThinkingBudgetTokens,
 	}
 
 	// Copy slices
@@ -158,6 +198,13 @@ func (o *GenerateOptions) Copy() *GenerateOptions {
 		copy(copied.Tools, o.Tools)
 	}
 
+	if o.LogitBias != nil {
+		copied.LogitBias = make(map[int]int, len(o.LogitBias))
+		for token, bias := range o.LogitBias {
+			copied.LogitBias[token] = bias
+		}
+	}
+
 	return copied
 }
 
@@ -179,3 +226,13 @@ func parseFloat(s string) float64 {
 	}
 	return 0
 }
+
+// IdempotencyKeyFor returns the idempotency key for one logical request
+// Providers call it once per Generate or Stream, outside their retry loop, so
+// every retry of the request carries the same key.
+func IdempotencyKeyFor(options *GenerateOptions) string {
+	if options != nil && options.IdempotencyKey != "" {
+		return options.IdempotencyKey
+	}
+	return "dsgo-" + ids.NewUUID()
+}
@@ -3,7 +3,6 @@ package core
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 )
 
@@ -24,7 +23,8 @@ func RegisterLM(provider string, factory LMFactory) {
 }
 
 // NewLM creates a new LM instance with explicit provider specification in model string.
-// Users must provide a valid model string that includes provider as first part.
+// The model string normally includes the provider as first part; see ResolveModel for
+// aliases and bare model names.
 //
 // The model string format is: "provider/model" or "provider/org/model"
 // - First part (before first slash) = provider name
@@ -34,20 +34,18 @@ func RegisterLM(provider string, factory LMFactory) {
 //   - NewLM(ctx, "openai/gpt-4o") -> uses openai provider with model "gpt-4o"
 //   - NewLM(ctx, "openrouter/z-ai/glm-4.6") -> uses openrouter provider with model "z-ai/glm-4.6"
 //   - NewLM(ctx, "openrouter/meta-llama/llama-3.3-70b-instruct") -> uses openrouter provider with model "meta-llama/llama-3.3-70b-instruct"
+//   - NewLM(ctx, "gpt-4o") -> infers the openai provider with model "gpt-4o"
 func NewLM(ctx context.Context, model string) (LM, error) {
 	if model == "" {
 		return nil, fmt.Errorf("model string is required - provide a valid model like 'openai/gpt-4o' or 'openrouter/z-ai/glm-4.6'. Example: dsgo.NewLM(ctx, \"openai/gpt-4o\")")
 	}
 
-	// Parse provider and model from model string
-	parts := strings.SplitN(model, "/", 2)
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("model string must include provider: format 'provider/model' (e.g., 'openai/gpt-4o' or 'openrouter/z-ai/glm-4.6'). Example: dsgo.NewLM(ctx, \"openai/gpt-4o\")")
+	// Parse provider and model from model string, inferring the provider of bare names
+	provider, targetModel, err := ResolveModel(model)
+	if err != nil {
+		return nil, err
 	}
 
-	provider := parts[0]
-	targetModel := parts[1]
-
 	// Get factory for provider
 	registryLock.RLock()
 	factory, ok := lmRegistry[provider]
@@ -69,12 +67,40 @@ func NewLM(ctx context.Context, model string) (LM, error) {
 		}
 	}
 
+	// Share a per-provider rate limiter if configured
+	var lm LM = baseLM
+	if settings.RateLimit > 0 {
+		limiter := providerRateLimiter(provider, settings.RateLimit, settings.RateLimitBurst, settings.AdaptiveRateLimit)
+		lm = NewRateLimitedLM(baseLM, limiter)
+	}
+
+	// Hedge slow calls; both requests of a hedged call pass through the rate limiter
+	if settings.HedgeAfter > 0 {
+		lm = NewHedgedLM(lm, settings.HedgeAfter)
+	}
+
+	// Coalesce identical concurrent calls before hedging, so a hedge never joins its own primary
+	if settings.DeduplicateRequests {
+		lm = NewDedupLM(lm)
+	}
+
+	// Reword refused prompts; inside the collector so each call is recorded once
+	if settings.RefusalRetries > 0 {
+		lm = NewRefusalRetryLM(lm, settings.RefusalRetries)
+	}
+
 	// Automatically wrap with LMWrapper if a Collector is configured
 	if settings.Collector != nil {
-		return NewLMWrapper(baseLM, settings.Collector), nil
+		lm = NewLMWrapper(lm, settings.Collector)
 	}
 
-	return baseLM, nil
+	// Apply user middleware outermost so the collector observes its effects (e.g. redaction)
+	middleware := settings.Middleware
+	if settings.DryRun {
+		// Innermost middleware, so dry runs skip rate limiting, hedging and the collector
+		middleware = append(middleware, DryRunMiddleware(targetModel))
+	}
+	return NewMiddlewareLM(lm, middleware...), nil
 }
 
 // getRegisteredProviders returns a list of registered provider names.
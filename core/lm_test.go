@@ -1,118 +1,10 @@
 package core
 
 import (
-	"context"
-	"encoding/json"
 	"os"
 	"testing"
 )
 
-// MockLM is a mock language model for testing
-type MockLM struct {
-	GenerateFunc     func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error)
-	NameValue        string
-	SupportsJSONVal  bool
-	SupportsToolsVal bool
-}
-
-func (m *MockLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
-	if m.GenerateFunc != nil {
-		return m.GenerateFunc(ctx, messages, options)
-	}
-	return &GenerateResult{Content: "{}"}, nil
-}
-
-func (m *MockLM) Name() string {
-	if m.NameValue != "" {
-		return m.NameValue
-	}
-	return "mock-lm"
-}
-
-func (m *MockLM) SupportsJSON() bool {
-	return m.SupportsJSONVal
-}
-
-func (m *MockLM) SupportsTools() bool {
-	return m.SupportsToolsVal
-}
-
-func (m *MockLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
-	chunkChan := make(chan Chunk, 1)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(chunkChan)
-		defer close(errChan)
-
-		result, err := m.Generate(ctx, messages, options)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		chunkChan <- Chunk{
-			Content:      result.Content,
-			FinishReason: result.FinishReason,
-			Usage:        result.Usage,
-		}
-	}()
-
-	return chunkChan, errChan
-}
-
-// NewMockLM creates a new mock LM with default behavior
-func NewMockLM() *MockLM {
-	return &MockLM{
-		NameValue:        "mock-lm",
-		SupportsJSONVal:  true,
-		SupportsToolsVal: false,
-	}
-}
-
-// WithJSONResponse configures the mock to return a JSON response
-func (m *MockLM) WithJSONResponse(data map[string]interface{}) *MockLM {
-	m.GenerateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
-		jsonBytes, _ := json.Marshal(data)
-		return &GenerateResult{
-			Content: string(jsonBytes),
-			Usage: Usage{
-				PromptTokens:     10,
-				CompletionTokens: 20,
-				TotalTokens:      30,
-			},
-		}, nil
-	}
-	return m
-}
-
-// WithError configures the mock to return an error
-func (m *MockLM) WithError(err error) *MockLM {
-	m.GenerateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
-		return nil, err
-	}
-	return m
-}
-
-// WithTextResponse configures the mock to return a text response
-func (m *MockLM) WithTextResponse(text string) *MockLM {
-	m.GenerateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
-		return &GenerateResult{Content: text}, nil
-	}
-	return m
-}
-
-// WithToolCalls configures the mock to return tool calls
-func (m *MockLM) WithToolCalls(toolCalls []ToolCall) *MockLM {
-	m.GenerateFunc = func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
-		return &GenerateResult{
-			Content:   "Let me use a tool",
-			ToolCalls: toolCalls,
-		}, nil
-	}
-	return m
-}
-
 func TestGenerateOptions_Copy(t *testing.T) {
 	original := &GenerateOptions{
 		Temperature:      0.8,
@@ -124,10 +16,14 @@ func TestGenerateOptions_Copy(t *testing.T) {
 		Stream:           true,
 		FrequencyPenalty: 0.5,
 		PresencePenalty:  0.3,
+		IdempotencyKey:   "order-42",
 		Tools: []Tool{
 			{Name: "tool1", Description: "Test tool 1"},
 			{Name: "tool2", Description: "Test tool 2"},
 		},
+
+		ReasoningEffort:      ReasoningEffortLow,
+		ThinkingBudgetTokens: 512,
 	}
 
 	copied := original.Copy()
@@ -157,6 +53,13 @@ func TestGenerateOptions_Copy(t *testing.T) {
 	if copied.PresencePenalty != original.PresencePenalty {
 		t.Errorf("PresencePenalty not copied correctly: got %v, want %v", copied.PresencePenalty, original.PresencePenalty)
 	}
+	if copied.IdempotencyKey != original.IdempotencyKey {
+		t.Errorf("IdempotencyKey not copied correctly: got %v, want %v", copied.IdempotencyKey, original.IdempotencyKey)
+	}
+	if copied.ReasoningEffort != original.ReasoningEffort || copied.ThinkingBudgetTokens != original.ThinkingBudgetTokens {
+		t.Errorf("reasoning controls not copied correctly: got %q/%d, want %q/%d",
+			copied.ReasoningEffort, copied.ThinkingBudgetTokens, original.ReasoningEffort, original.ThinkingBudgetTokens)
+	}
 
 	// Verify slices are deep copied (not same memory address)
 	if len(copied.Stop) != len(original.Stop) {
@@ -393,3 +296,16 @@ func TestGetEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestUsage_Add(t *testing.T) {
+	var total Usage
+	total.Add(Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Cost: 0.01, Latency: 100})
+	total.Add(Usage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, Cost: 0.02, Latency: 50})
+
+	want := Usage{PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, Cost: 0.03, Latency: 150}
+	if total.PromptTokens != want.PromptTokens || total.CompletionTokens != want.CompletionTokens ||
+		total.TotalTokens != want.TotalTokens || total.Latency != want.Latency ||
+		total.Cost < want.Cost-1e-9 || total.Cost > want.Cost+1e-9 {
+		t.Errorf("Add() = %+v, want %+v", total, want)
+	}
+}
@@ -5,35 +5,31 @@ import (
 	"strings"
 	"time"
 
-	"github.com/assagman/dsgo/internal/cost"
 	"github.com/assagman/dsgo/internal/ids"
 )
 
 // LMWrapper wraps an LM to add observability (cost, latency, history collection)
 type LMWrapper struct {
-	lm         LM
-	collector  Collector
-	calculator *cost.Calculator
-	sessionID  string
+	lm        LM
+	collector Collector
+	sessionID string
 }
 
 // NewLMWrapper creates a new LM wrapper with observability features
 func NewLMWrapper(lm LM, collector Collector) LM {
 	return &LMWrapper{
-		lm:         lm,
-		collector:  collector,
-		calculator: cost.NewCalculator(),
-		sessionID:  ids.NewUUID(),
+		lm:        lm,
+		collector: collector,
+		sessionID: ids.NewUUID(),
 	}
 }
 
 // NewLMWrapperWithSession creates a new LM wrapper with a custom session ID
 func NewLMWrapperWithSession(lm LM, collector Collector, sessionID string) LM {
 	return &LMWrapper{
-		lm:         lm,
-		collector:  collector,
-		calculator: cost.NewCalculator(),
-		sessionID:  sessionID,
+		lm:        lm,
+		collector: collector,
+		sessionID: sessionID,
 	}
 }
 
@@ -49,7 +45,7 @@ func (w *LMWrapper) Generate(ctx context.Context, messages []Message, options *G
 	latency := time.Since(startTime).Milliseconds()
 
 	// Build history entry
-	entry := w.buildHistoryEntry(entryID, startTime, messages, options, result, latency, err)
+	entry := w.buildHistoryEntry(ctx, entryID, startTime, messages, options, result, latency, err)
 
 	// Collect history (best effort - don't fail the call if collection fails)
 	if w.collector != nil {
@@ -119,8 +115,14 @@ func (w *LMWrapper) Stream(ctx context.Context, messages []Message, options *Gen
 					finalUsage = chunk.Usage
 				}
 
-				// Forward to caller
-				outChunkChan <- chunk
+				// Forward to caller, giving up if the caller cancelled and stopped reading
+				select {
+				case outChunkChan <- chunk:
+				case <-ctx.Done():
+					streamErr = ctx.Err()
+					outErrChan <- streamErr
+					goto StreamComplete
+				}
 
 			case err, ok := <-inErrChan:
 				if !ok {
@@ -156,18 +158,7 @@ func (w *LMWrapper) Stream(ctx context.Context, messages []Message, options *Gen
 		}
 
 		// Build and collect history entry
-		entry := w.buildHistoryEntry(entryID, startTime, messages, options, result, latency, streamErr)
-
-		// Update cost in entry if we have usage data
-		if result != nil && result.Usage.TotalTokens > 0 {
-			modelName := w.lm.Name()
-			calculatedCost := w.calculator.Calculate(
-				modelName,
-				result.Usage.PromptTokens,
-				result.Usage.CompletionTokens,
-			)
-			entry.Usage.Cost = calculatedCost
-		}
+		entry := w.buildHistoryEntry(ctx, entryID, startTime, messages, options, result, latency, streamErr)
 
 		// Collect history (best effort)
 		if w.collector != nil {
@@ -193,8 +184,19 @@ func (w *LMWrapper) SupportsTools() bool {
 	return w.lm.SupportsTools()
 }
 
+// Capabilities reports the wrapped LM's capabilities
+func (w *LMWrapper) Capabilities() Capabilities {
+	return CapabilitiesOf(w.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (w *LMWrapper) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, w.lm)
+}
+
 // buildHistoryEntry constructs a complete HistoryEntry
 func (w *LMWrapper) buildHistoryEntry(
+	ctx context.Context,
 	entryID string,
 	startTime time.Time,
 	messages []Message,
@@ -211,6 +213,7 @@ func (w *LMWrapper) buildHistoryEntry(
 		Model:     w.lm.Name(),
 		Request:   w.buildRequestMeta(messages, options),
 		Cache:     CacheMeta{Hit: false}, // Default, will be updated from metadata
+		Tags:      TagsFromContext(ctx),
 	}
 
 	// Populate response metadata
@@ -227,14 +230,8 @@ func (w *LMWrapper) buildHistoryEntry(
 		entry.Usage = result.Usage
 		entry.Usage.Latency = latency
 
-		// Calculate cost
-		modelName := w.lm.Name()
-		calculatedCost := w.calculator.Calculate(
-			modelName,
-			result.Usage.PromptTokens,
-			result.Usage.CompletionTokens,
-		)
-		entry.Usage.Cost = calculatedCost
+		// Calculate cost unless the provider reported it
+		FillCost(w.lm.Name(), &entry.Usage)
 
 		// Wire provider-specific metadata
 		if result.Metadata != nil {
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"testing"
 	"time"
-
-	"github.com/assagman/dsgo/internal/cost"
 )
 
 // mockWrapperLM is a mock LM for testing the wrapper
@@ -991,9 +989,8 @@ func TestLMWrapper_Stream_Error(t *testing.T) {
 			name: "gpt-4",
 			err:  expectedErr,
 		},
-		collector:  memCollector,
-		calculator: cost.NewCalculator(),
-		sessionID:  "test-session",
+		collector: memCollector,
+		sessionID: "test-session",
 	}
 
 	ctx := context.Background()
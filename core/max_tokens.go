@@ -0,0 +1,47 @@
+package core
+
+// FieldTokenBudgets is the completion budget EstimateMaxTokens gives an output field
+// of each type when no per-field budget is set. Free-form text gets the most room;
+// scalars and classes need only a few tokens.
+var FieldTokenBudgets = map[FieldType]int{
+	FieldTypeString:   2048,
+	FieldTypeJSON:     2048,
+	FieldTypeImage:    512,
+	FieldTypeDatetime: 32,
+	FieldTypeClass:    32,
+	FieldTypeInt:      32,
+	FieldTypeFloat:    32,
+	FieldTypeBool:     16,
+}
+
+// maxTokensOverhead covers output formatting (JSON syntax, field markers, a stray preamble)
+const maxTokensOverhead = 512
+
+// EstimateMaxTokens returns a completion token budget for the signature's outputs:
+// the sum of each output field's budget, from perField (keyed by field name) or else
+// FieldTokenBudgets for its type, plus formatting overhead. A single int output gets
+// a few hundred tokens; several long string outputs get several thousand.
+func (s *Signature) EstimateMaxTokens(perField map[string]int) int {
+	total := maxTokensOverhead
+	for _, field := range s.OutputFields {
+		if budget, ok := perField[field.Name]; ok {
+			total += budget
+			continue
+		}
+		if budget, ok := FieldTokenBudgets[field.Type]; ok {
+			total += budget
+		} else {
+			total += FieldTokenBudgets[FieldTypeString]
+		}
+	}
+	return total
+}
+
+// ResolveMaxTokens returns the MaxTokens to send for a call: maxTokens when it is set
+// and no per-field budgets are given, and EstimateMaxTokens(perField) otherwise
+func (s *Signature) ResolveMaxTokens(maxTokens int, perField map[string]int) int {
+	if maxTokens > 0 && perField == nil {
+		return maxTokens
+	}
+	return s.EstimateMaxTokens(perField)
+}
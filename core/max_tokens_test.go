@@ -0,0 +1,36 @@
+package core
+
+import "testing"
+
+func TestSignature_EstimateMaxTokens(t *testing.T) {
+	count := NewSignature("Count").AddOutput("count", FieldTypeInt, "")
+	story := NewSignature("Write").
+		AddOutput("title", FieldTypeString, "").
+		AddOutput("story", FieldTypeString, "")
+
+	if small, large := count.EstimateMaxTokens(nil), story.EstimateMaxTokens(nil); small >= large {
+		t.Errorf("int output budget %d should be below two string outputs %d", small, large)
+	}
+	if got, want := story.EstimateMaxTokens(nil), maxTokensOverhead+2*FieldTokenBudgets[FieldTypeString]; got != want {
+		t.Errorf("EstimateMaxTokens(nil) = %d, want %d", got, want)
+	}
+
+	got := story.EstimateMaxTokens(map[string]int{"title": 20, "story": 8000})
+	if want := maxTokensOverhead + 8020; got != want {
+		t.Errorf("EstimateMaxTokens(budgets) = %d, want %d", got, want)
+	}
+}
+
+func TestSignature_ResolveMaxTokens(t *testing.T) {
+	sig := NewSignature("Count").AddOutput("count", FieldTypeInt, "")
+
+	if got := sig.ResolveMaxTokens(500, nil); got != 500 {
+		t.Errorf("ResolveMaxTokens(500, nil) = %d, want the configured 500", got)
+	}
+	if got, want := sig.ResolveMaxTokens(0, nil), sig.EstimateMaxTokens(nil); got != want {
+		t.Errorf("ResolveMaxTokens(0, nil) = %d, want the estimate %d", got, want)
+	}
+	if got, want := sig.ResolveMaxTokens(500, map[string]int{"count": 8}), maxTokensOverhead+8; got != want {
+		t.Errorf("ResolveMaxTokens(500, budgets) = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import "context"
+
+// LMFunc is the signature of a Generate call, used as a middleware handler
+type LMFunc func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error)
+
+// Middleware wraps an LMFunc with custom logic (redaction, logging, mocking, ...)
+// A middleware may modify messages and options before calling next, inspect or
+// modify the result afterwards, or short-circuit by not calling next at all.
+type Middleware func(next LMFunc) LMFunc
+
+// MiddlewareLM applies a middleware chain to every Generate and Stream call of an LM
+type MiddlewareLM struct {
+	lm         LM
+	middleware []Middleware
+}
+
+// NewMiddlewareLM wraps lm with middleware. The first middleware is the outermost.
+func NewMiddlewareLM(lm LM, middleware ...Middleware) LM {
+	if len(middleware) == 0 {
+		return lm
+	}
+	return &MiddlewareLM{lm: lm, middleware: middleware}
+}
+
+// chain composes the middleware around the terminal handler
+func (m *MiddlewareLM) chain(terminal LMFunc) LMFunc {
+	handler := terminal
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		handler = m.middleware[i](handler)
+	}
+	return handler
+}
+
+// Generate runs the middleware chain around the underlying LM's Generate
+func (m *MiddlewareLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	return m.chain(m.lm.Generate)(ctx, messages, options)
+}
+
+// Stream runs the middleware chain around the underlying LM's Stream.
+// Chunks are forwarded as they arrive; middleware sees the accumulated result
+// once the stream ends, so response mutations do not affect delivered chunks.
+// A middleware that short-circuits has its result delivered as a single chunk.
+func (m *MiddlewareLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	chunkChan := make(chan Chunk)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+
+		streamed := false
+		terminal := func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			streamed = true
+			return m.forwardStream(ctx, messages, options, chunkChan)
+		}
+
+		result, err := m.chain(terminal)(ctx, messages, options)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		if streamed || result == nil {
+			return
+		}
+
+		select {
+		case chunkChan <- Chunk{
+			Content:      result.Content,
+			ToolCalls:    result.ToolCalls,
+			FinishReason: result.FinishReason,
+			Usage:        result.Usage,
+		}:
+		case <-ctx.Done():
+			errChan <- ctx.Err()
+		}
+	}()
+
+	return chunkChan, errChan
+}
+
+// forwardStream streams from the underlying LM into out and returns the accumulated result
+func (m *MiddlewareLM) forwardStream(ctx context.Context, messages []Message, options *GenerateOptions, out chan<- Chunk) (*GenerateResult, error) {
+	inChunks, inErrs := m.lm.Stream(ctx, messages, options)
+
+	result := &GenerateResult{}
+	for chunk := range inChunks {
+		result.Content += chunk.Content
+		result.ToolCalls = append(result.ToolCalls, chunk.ToolCalls...)
+		if chunk.FinishReason != "" {
+			result.FinishReason = chunk.FinishReason
+		}
+		if chunk.Usage.TotalTokens > 0 {
+			result.Usage = chunk.Usage
+		}
+
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if err := <-inErrs; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Name returns the underlying LM's name
+func (m *MiddlewareLM) Name() string {
+	return m.lm.Name()
+}
+
+// SupportsJSON returns whether the underlying LM supports JSON
+func (m *MiddlewareLM) SupportsJSON() bool {
+	return m.lm.SupportsJSON()
+}
+
+// SupportsTools returns whether the underlying LM supports tools
+func (m *MiddlewareLM) SupportsTools() bool {
+	return m.lm.SupportsTools()
+}
+
+// Capabilities reports the wrapped LM's capabilities
+func (m *MiddlewareLM) Capabilities() Capabilities {
+	return CapabilitiesOf(m.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (m *MiddlewareLM) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, m.lm)
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingMiddleware appends name to calls before and after invoking next
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			*calls = append(*calls, name+":before")
+			result, err := next(ctx, messages, options)
+			*calls = append(*calls, name+":after")
+			return result, err
+		}
+	}
+}
+
+// drainStream collects all chunk content and the first error of a stream
+func drainStream(chunks <-chan Chunk, errs <-chan error) (string, error) {
+	var content strings.Builder
+	for chunk := range chunks {
+		content.WriteString(chunk.Content)
+	}
+	return content.String(), <-errs
+}
+
+func TestMiddlewareLM_Generate(t *testing.T) {
+	var seen []Message
+	base := &mockWrapperLM{
+		name: "base",
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			seen = messages
+			return &GenerateResult{Content: "response"}, nil
+		},
+	}
+
+	var calls []string
+	redact := func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			redacted := make([]Message, len(messages))
+			for i, msg := range messages {
+				msg.Content = strings.ReplaceAll(msg.Content, "secret", "[REDACTED]")
+				redacted[i] = msg
+			}
+			return next(ctx, redacted, options)
+		}
+	}
+	upper := func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			result, err := next(ctx, messages, options)
+			if err != nil {
+				return nil, err
+			}
+			result.Content = strings.ToUpper(result.Content)
+			return result, nil
+		}
+	}
+
+	lm := NewMiddlewareLM(base,
+		recordingMiddleware("outer", &calls),
+		redact,
+		upper,
+		recordingMiddleware("inner", &calls),
+	)
+
+	result, err := lm.Generate(context.Background(), []Message{{Role: "user", Content: "my secret"}}, nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Content != "RESPONSE" {
+		t.Errorf("expected response mutation, got %q", result.Content)
+	}
+	if seen[0].Content != "my [REDACTED]" {
+		t.Errorf("expected redacted prompt, got %q", seen[0].Content)
+	}
+
+	want := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("call order = %v, want %v", calls, want)
+	}
+	if lm.Name() != "base" {
+		t.Errorf("Name() = %q, want base", lm.Name())
+	}
+}
+
+func TestMiddlewareLM_ShortCircuit(t *testing.T) {
+	base := failingLM("base", errors.New("should not be called"))
+	canned := func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			return &GenerateResult{Content: "canned", FinishReason: "stop"}, nil
+		}
+	}
+
+	lm := NewMiddlewareLM(base, canned)
+
+	result, err := lm.Generate(context.Background(), nil, nil)
+	if err != nil || result.Content != "canned" {
+		t.Fatalf("Generate() = %v, %v; want canned response", result, err)
+	}
+
+	content, err := drainStream(lm.Stream(context.Background(), nil, nil))
+	if err != nil || content != "canned" {
+		t.Errorf("Stream() = %q, %v; want canned single chunk", content, err)
+	}
+}
+
+func TestMiddlewareLM_Stream(t *testing.T) {
+	tests := []struct {
+		name      string
+		streamErr error
+		wantErr   bool
+	}{
+		{"success", nil, false},
+		{"stream error", errors.New("stream broke"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &mockStreamLM{
+				mockWrapperLM: mockWrapperLM{name: "base"},
+				chunks:        []string{"Hel", "lo"},
+				streamErr:     tt.streamErr,
+			}
+
+			var observed string
+			var observedErr error
+			observe := func(next LMFunc) LMFunc {
+				return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+					result, err := next(ctx, messages, options)
+					observedErr = err
+					if result != nil {
+						observed = result.Content
+					}
+					return result, err
+				}
+			}
+
+			content, err := drainStream(NewMiddlewareLM(base, observe).Stream(context.Background(), nil, nil))
+			if content != "Hello" {
+				t.Errorf("streamed content = %q, want Hello", content)
+			}
+			if (err != nil) != tt.wantErr || (observedErr != nil) != tt.wantErr {
+				t.Errorf("stream err = %v, middleware err = %v, wantErr %v", err, observedErr, tt.wantErr)
+			}
+			if !tt.wantErr && observed != "Hello" {
+				t.Errorf("middleware saw %q, want accumulated Hello", observed)
+			}
+		})
+	}
+}
+
+func TestNewMiddlewareLM_NoMiddleware(t *testing.T) {
+	base := &mockWrapperLM{name: "base"}
+	if lm := NewMiddlewareLM(base); lm != LM(base) {
+		t.Error("expected LM to be returned unwrapped without middleware")
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	noop := func(next LMFunc) LMFunc { return next }
+	Configure(WithMiddleware(noop), WithMiddleware(noop, noop))
+
+	if got := len(GetSettings().Middleware); got != 3 {
+		t.Errorf("expected 3 middleware, got %d", got)
+	}
+
+	ResetConfig()
+	if GetSettings().Middleware != nil {
+		t.Error("expected ResetConfig to clear middleware")
+	}
+}
+
+func TestNewLM_AppliesMiddleware(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	RegisterLM("middlewareprovider", func(model string) LM {
+		return failingLM(model, errors.New("provider should be short-circuited"))
+	})
+
+	canned := func(next LMFunc) LMFunc {
+		return func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			return &GenerateResult{Content: "canned"}, nil
+		}
+	}
+	Configure(WithMiddleware(canned))
+
+	lm, err := NewLM(context.Background(), "middlewareprovider/test-model")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	result, err := lm.Generate(context.Background(), nil, nil)
+	if err != nil || result.Content != "canned" {
+		t.Errorf("Generate() = %v, %v; want canned response", result, err)
+	}
+}
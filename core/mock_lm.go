@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// mockChunkSize is the number of characters per streamed chunk
+const mockChunkSize = 16
+
+// PromptMatcher reports whether a prompt should receive a programmed response
+type PromptMatcher func(messages []Message) bool
+
+// PromptContains matches prompts where any message contains substr
+func PromptContains(substr string) PromptMatcher {
+	return func(messages []Message) bool {
+		for _, msg := range messages {
+			if strings.Contains(msg.Content, substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MockResponse is a canned LM response
+type MockResponse struct {
+	Content   string
+	Reasoning string // Returned as GenerateResult.Reasoning, like a reasoning model's thinking channel
+	ToolCalls []ToolCall
+	Err       error // Simulated provider error; Content and ToolCalls are ignored when set
+}
+
+// mockRule pairs a prompt matcher with its response
+type mockRule struct {
+	matcher  PromptMatcher
+	response MockResponse
+}
+
+// MockRule configures the response for prompts matching a PromptMatcher
+type MockRule struct {
+	lm      *MockLM
+	matcher PromptMatcher
+}
+
+// Respond answers matching prompts with content
+func (r *MockRule) Respond(content string) *MockLM {
+	return r.RespondWith(MockResponse{Content: content})
+}
+
+// RespondWith answers matching prompts with response
+func (r *MockRule) RespondWith(response MockResponse) *MockLM {
+	r.lm.mu.Lock()
+	defer r.lm.mu.Unlock()
+	r.lm.rules = append(r.lm.rules, mockRule{matcher: r.matcher, response: response})
+	return r.lm
+}
+
+// FailWith answers matching prompts with err
+func (r *MockRule) FailWith(err error) *MockLM {
+	return r.RespondWith(MockResponse{Err: err})
+}
+
+// MockLM is a programmable LM for deterministic tests without network access.
+// Responses are resolved in order: FailWith, matching OnPrompt rules (first
+// registered wins), RespondSequence, then the default set by Respond.
+type MockLM struct {
+	mu            sync.Mutex
+	name          string
+	supportsJSON  bool
+	supportsTools bool
+	err           error
+	rules         []mockRule
+	sequence      []MockResponse
+	next          int
+	fallback      *MockResponse
+	calls         [][]Message
+}
+
+// NewMockLM creates a mock LM that supports JSON and tools
+func NewMockLM() *MockLM {
+	return &MockLM{
+		name:          "mock",
+		supportsJSON:  true,
+		supportsTools: true,
+	}
+}
+
+// WithName sets the model name reported by Name
+func (m *MockLM) WithName(name string) *MockLM {
+	m.name = name
+	return m
+}
+
+// WithJSONSupport sets whether the mock reports JSON mode support
+func (m *MockLM) WithJSONSupport(supported bool) *MockLM {
+	m.supportsJSON = supported
+	return m
+}
+
+// WithToolSupport sets whether the mock reports tool calling support
+func (m *MockLM) WithToolSupport(supported bool) *MockLM {
+	m.supportsTools = supported
+	return m
+}
+
+// OnPrompt starts a rule for prompts matching matcher
+func (m *MockLM) OnPrompt(matcher PromptMatcher) *MockRule {
+	return &MockRule{lm: m, matcher: matcher}
+}
+
+// Respond sets the default response for prompts not handled by rules or the sequence
+func (m *MockLM) Respond(content string) *MockLM {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = &MockResponse{Content: content}
+	return m
+}
+
+// RespondSequence returns responses in order, one per call, for multi-call flows like ReAct
+// Calls beyond the sequence fall through to the default response, or fail if none is set
+func (m *MockLM) RespondSequence(responses ...MockResponse) *MockLM {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sequence = append(m.sequence, responses...)
+	return m
+}
+
+// FailWith makes every call fail with err, simulating a provider outage
+func (m *MockLM) FailWith(err error) *MockLM {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+	return m
+}
+
+// Calls returns the messages of every call received so far
+func (m *MockLM) Calls() [][]Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([][]Message, len(m.calls))
+	copy(calls, m.calls)
+	return calls
+}
+
+// CallCount returns the number of calls received so far
+func (m *MockLM) CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+// respond records the call and resolves the programmed response
+func (m *MockLM) respond(messages []Message) (MockResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, append([]Message(nil), messages...))
+
+	if m.err != nil {
+		return MockResponse{}, m.err
+	}
+	for _, rule := range m.rules {
+		if rule.matcher(messages) {
+			return rule.response, rule.response.Err
+		}
+	}
+	if m.next < len(m.sequence) {
+		response := m.sequence[m.next]
+		m.next++
+		return response, response.Err
+	}
+	if m.fallback != nil {
+		return *m.fallback, nil
+	}
+	return MockResponse{}, errors.New("mock LM: no response configured for prompt")
+}
+
+// Generate returns the programmed response with estimated usage
+func (m *MockLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	response, err := m.respond(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	finishReason := "stop"
+	if len(response.ToolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+
+	promptTokens := CountMessageTokens(m.name, messages)
+	completionTokens := CountTokens(m.name, response.Content)
+	for _, tc := range response.ToolCalls {
+		completionTokens += CountTokens(m.name, tc.Name) + CountTokens(m.name, fmt.Sprintf("%v", tc.Arguments))
+	}
+
+	return &GenerateResult{
+		Content:      response.Content,
+		Reasoning:    response.Reasoning,
+		ToolCalls:    response.ToolCalls,
+		FinishReason: finishReason,
+		Usage: Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}, nil
+}
+
+// Stream delivers the programmed response in fixed-size chunks
+// Tool calls, finish reason and usage are sent with the final chunk
+func (m *MockLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	chunkChan := make(chan Chunk)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+
+		result, err := m.Generate(ctx, messages, options)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		content := []rune(result.Content)
+		for start := 0; ; start += mockChunkSize {
+			end := min(start+mockChunkSize, len(content))
+			chunk := Chunk{Content: string(content[start:end])}
+			last := end == len(content)
+			if last {
+				chunk.ToolCalls = result.ToolCalls
+				chunk.FinishReason = result.FinishReason
+				chunk.Usage = result.Usage
+			}
+
+			select {
+			case chunkChan <- chunk:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+			if last {
+				return
+			}
+		}
+	}()
+
+	return chunkChan, errChan
+}
+
+// Name returns the mock model name
+func (m *MockLM) Name() string {
+	return m.name
+}
+
+// SupportsJSON returns whether the mock reports JSON mode support
+func (m *MockLM) SupportsJSON() bool {
+	return m.supportsJSON
+}
+
+// SupportsTools returns whether the mock reports tool calling support
+func (m *MockLM) SupportsTools() bool {
+	return m.supportsTools
+}
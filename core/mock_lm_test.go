@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMockLM_Resolution(t *testing.T) {
+	providerErr := errors.New("API request failed with status 503: unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(*MockLM)
+		prompts []string
+		want    []string // expected content per call, or "error: <substr>"
+	}{
+		{
+			name:    "no configuration fails",
+			setup:   func(m *MockLM) {},
+			prompts: []string{"hi"},
+			want:    []string{"error: no response configured"},
+		},
+		{
+			name:    "default response",
+			setup:   func(m *MockLM) { m.Respond("default") },
+			prompts: []string{"a", "b"},
+			want:    []string{"default", "default"},
+		},
+		{
+			name: "prompt rules win over default",
+			setup: func(m *MockLM) {
+				m.OnPrompt(PromptContains("weather")).Respond("sunny").
+					OnPrompt(PromptContains("fail")).FailWith(providerErr).
+					Respond("default")
+			},
+			prompts: []string{"what is the weather?", "please fail", "other"},
+			want:    []string{"sunny", "error: status 503", "default"},
+		},
+		{
+			name: "sequence then default",
+			setup: func(m *MockLM) {
+				m.RespondSequence(MockResponse{Content: "first"}, MockResponse{Content: "second"}).Respond("after")
+			},
+			prompts: []string{"x", "x", "x"},
+			want:    []string{"first", "second", "after"},
+		},
+		{
+			name:    "sequence exhausted without default fails",
+			setup:   func(m *MockLM) { m.RespondSequence(MockResponse{Content: "only"}) },
+			prompts: []string{"x", "x"},
+			want:    []string{"only", "error: no response configured"},
+		},
+		{
+			name:    "fail with overrides everything",
+			setup:   func(m *MockLM) { m.Respond("default").FailWith(providerErr) },
+			prompts: []string{"x"},
+			want:    []string{"error: status 503"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := NewMockLM()
+			tt.setup(lm)
+
+			for i, prompt := range tt.prompts {
+				result, err := lm.Generate(context.Background(), []Message{{Role: "user", Content: prompt}}, nil)
+				if wantErr, ok := strings.CutPrefix(tt.want[i], "error: "); ok {
+					if err == nil || !strings.Contains(err.Error(), wantErr) {
+						t.Errorf("call %d: expected error containing %q, got %v", i, wantErr, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("call %d: unexpected error %v", i, err)
+				}
+				if result.Content != tt.want[i] {
+					t.Errorf("call %d: content = %q, want %q", i, result.Content, tt.want[i])
+				}
+			}
+
+			if lm.CallCount() != len(tt.prompts) {
+				t.Errorf("CallCount() = %d, want %d", lm.CallCount(), len(tt.prompts))
+			}
+		})
+	}
+}
+
+func TestMockLM_UsageAndToolCalls(t *testing.T) {
+	lm := NewMockLM().RespondSequence(MockResponse{
+		ToolCalls: []ToolCall{{ID: "1", Name: "search", Arguments: map[string]any{"query": "go"}}},
+	})
+
+	messages := []Message{{Role: "user", Content: strings.Repeat("a", 40)}}
+	result, err := lm.Generate(context.Background(), messages, nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if result.FinishReason != "tool_calls" || len(result.ToolCalls) != 1 {
+		t.Errorf("expected tool call response, got %+v", result)
+	}
+	if result.Usage.PromptTokens != EstimateMessageTokens(messages) {
+		t.Errorf("PromptTokens = %d, want %d", result.Usage.PromptTokens, EstimateMessageTokens(messages))
+	}
+	if result.Usage.CompletionTokens <= 0 || result.Usage.TotalTokens != result.Usage.PromptTokens+result.Usage.CompletionTokens {
+		t.Errorf("implausible usage: %+v", result.Usage)
+	}
+	if calls := lm.Calls(); len(calls) != 1 || calls[0][0].Content != messages[0].Content {
+		t.Errorf("expected call to be recorded, got %+v", calls)
+	}
+}
+
+func TestMockLM_Stream(t *testing.T) {
+	content := strings.Repeat("streamed content ", 5)
+	lm := NewMockLM().Respond(content)
+
+	chunks, errs := lm.Stream(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
+
+	var got strings.Builder
+	var last Chunk
+	count := 0
+	for chunk := range chunks {
+		got.WriteString(chunk.Content)
+		last = chunk
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if got.String() != content {
+		t.Errorf("streamed content = %q, want %q", got.String(), content)
+	}
+	if count < 2 {
+		t.Errorf("expected content to be chunked, got %d chunks", count)
+	}
+	if last.FinishReason != "stop" || last.Usage.TotalTokens == 0 {
+		t.Errorf("expected final chunk to carry finish reason and usage, got %+v", last)
+	}
+}
+
+func TestMockLM_StreamError(t *testing.T) {
+	lm := NewMockLM().FailWith(errors.New("boom"))
+
+	chunks, errs := lm.Stream(context.Background(), nil, nil)
+	for range chunks {
+		t.Error("expected no chunks")
+	}
+	if err := <-errs; err == nil || err.Error() != "boom" {
+		t.Errorf("expected boom, got %v", err)
+	}
+}
+
+func TestMockLM_Capabilities(t *testing.T) {
+	lm := NewMockLM()
+	if lm.Name() != "mock" || !lm.SupportsJSON() || !lm.SupportsTools() {
+		t.Errorf("unexpected defaults: name=%s json=%v tools=%v", lm.Name(), lm.SupportsJSON(), lm.SupportsTools())
+	}
+
+	lm.WithName("custom").WithJSONSupport(false).WithToolSupport(false)
+	if lm.Name() != "custom" || lm.SupportsJSON() || lm.SupportsTools() {
+		t.Errorf("capability setters not applied: name=%s json=%v tools=%v", lm.Name(), lm.SupportsJSON(), lm.SupportsTools())
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// modelAlias is the provider and provider-specific model a registered alias stands for
+type modelAlias struct {
+	provider string
+	model    string
+}
+
+// modelFamily maps bare model names starting with prefix to a provider
+// org is prepended for providers that namespace models by organization (e.g. OpenRouter).
+type modelFamily struct {
+	prefix   string
+	provider string
+	org      string
+}
+
+var (
+	modelAliases   = make(map[string]modelAlias)
+	modelAliasLock sync.RWMutex
+
+	// modelFamilies infers the provider of bare model names, first match wins
+	modelFamilies = []modelFamily{
+		{"gpt-", "openai", ""},
+		{"chatgpt-", "openai", ""},
+		{"o1", "openai", ""},
+		{"o3", "openai", ""},
+		{"o4", "openai", ""},
+		{"text-embedding-", "openai", ""},
+		{"claude-", "openrouter", "anthropic/"},
+		{"gemini-", "openrouter", "google/"},
+		{"llama-", "openrouter", "meta-llama/"},
+		{"mistral-", "openrouter", "mistralai/"},
+		{"qwen", "openrouter", "qwen/"},
+		{"deepseek-", "openrouter", "deepseek/"},
+		{"glm-", "openrouter", "z-ai/"},
+	}
+)
+
+// RegisterModelAlias makes name resolve to actualModel on provider in NewLM, e.g.
+// RegisterModelAlias("claude-3-5-sonnet", "openrouter", "anthropic/claude-3.5-sonnet").
+// Aliases take precedence over every other resolution rule, including provider prefixes.
+func RegisterModelAlias(name, provider, actualModel string) {
+	modelAliasLock.Lock()
+	defer modelAliasLock.Unlock()
+	modelAliases[name] = modelAlias{provider: provider, model: actualModel}
+}
+
+// ResolveModel splits a model string into provider and provider-specific model.
+// A registered alias wins; otherwise "provider/model" is split at the first slash.
+// A bare model name without a slash is matched against known model families
+// ("gpt-4o" -> openai, "claude-3-5-sonnet" -> openrouter "anthropic/claude-3-5-sonnet"),
+// falling back to the provider configured with WithProvider.
+func ResolveModel(model string) (provider, actualModel string, err error) {
+	modelAliasLock.RLock()
+	alias, ok := modelAliases[model]
+	modelAliasLock.RUnlock()
+	if ok {
+		return alias.provider, alias.model, nil
+	}
+
+	if provider, actualModel, ok := strings.Cut(model, "/"); ok {
+		return provider, actualModel, nil
+	}
+
+	for _, family := range modelFamilies {
+		if strings.HasPrefix(model, family.prefix) {
+			return family.provider, family.org + model, nil
+		}
+	}
+
+	if provider := GetSettings().DefaultProvider; provider != "" {
+		return provider, model, nil
+	}
+	return "", "", fmt.Errorf("cannot infer provider for model %q: use the 'provider/model' format (e.g., 'openai/gpt-4o' or 'openrouter/z-ai/glm-4.6'), configure dsgo.WithProvider, or register it with dsgo.RegisterModelAlias", model)
+}
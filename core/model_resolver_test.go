@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolveModel(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	tests := []struct {
+		model        string
+		wantProvider string
+		wantModel    string
+	}{
+		{"openai/gpt-4o", "openai", "gpt-4o"},
+		{"openrouter/z-ai/glm-4.6", "openrouter", "z-ai/glm-4.6"},
+		{"gpt-4o", "openai", "gpt-4o"},
+		{"o3-mini", "openai", "o3-mini"},
+		{"claude-3-5-sonnet", "openrouter", "anthropic/claude-3-5-sonnet"},
+		{"gemini-2.5-flash", "openrouter", "google/gemini-2.5-flash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			provider, model, err := ResolveModel(tt.model)
+			if err != nil {
+				t.Fatalf("ResolveModel() error = %v", err)
+			}
+			if provider != tt.wantProvider || model != tt.wantModel {
+				t.Errorf("ResolveModel() = %q, %q, want %q, %q", provider, model, tt.wantProvider, tt.wantModel)
+			}
+		})
+	}
+
+	if _, _, err := ResolveModel("my-finetune"); err == nil {
+		t.Error("ResolveModel() should fail for an unknown bare model without a default provider")
+	}
+
+	Configure(WithProvider("openrouter"))
+	if provider, model, err := ResolveModel("my-finetune"); err != nil || provider != "openrouter" || model != "my-finetune" {
+		t.Errorf("ResolveModel() = %q, %q, %v, want the default provider", provider, model, err)
+	}
+}
+
+func TestRegisterModelAlias(t *testing.T) {
+	defer func() {
+		modelAliasLock.Lock()
+		delete(modelAliases, "claude-3-5-sonnet")
+		delete(modelAliases, "fast")
+		modelAliasLock.Unlock()
+	}()
+
+	RegisterModelAlias("claude-3-5-sonnet", "openrouter", "anthropic/claude-3.5-sonnet")
+	RegisterModelAlias("fast", "alias-provider", "tiny-model")
+
+	if provider, model, _ := ResolveModel("claude-3-5-sonnet"); provider != "openrouter" || model != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("ResolveModel() = %q, %q, want the registered alias", provider, model)
+	}
+
+	registryLock.Lock()
+	original := lmRegistry["alias-provider"]
+	registryLock.Unlock()
+	defer func() {
+		registryLock.Lock()
+		if original == nil {
+			delete(lmRegistry, "alias-provider")
+		} else {
+			lmRegistry["alias-provider"] = original
+		}
+		registryLock.Unlock()
+	}()
+
+	var created string
+	RegisterLM("alias-provider", func(model string) LM {
+		created = model
+		return &mockLM{}
+	})
+	if _, err := NewLM(context.Background(), "fast"); err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	if created != "tiny-model" {
+		t.Errorf("factory got model %q, want tiny-model", created)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import "context"
+
+// optionOverridesKey is the context key for GenerateOptions overrides
+type optionOverridesKey struct{}
+
+// WithOptionOverride returns ctx carrying an override that modules apply to their
+// GenerateOptions before every LM call made under ctx. Overrides run in the order
+// they were added, after the module's own options, so they also change the cache key.
+// BestOfN uses this to give each candidate its own temperature.
+func WithOptionOverride(ctx context.Context, override func(*GenerateOptions)) context.Context {
+	existing, _ := ctx.Value(optionOverridesKey{}).([]func(*GenerateOptions))
+	overrides := make([]func(*GenerateOptions), 0, len(existing)+1)
+	overrides = append(overrides, existing...)
+	overrides = append(overrides, override)
+	return context.WithValue(ctx, optionOverridesKey{}, overrides)
+}
+
+// ApplyOptionOverrides applies the overrides attached to ctx to options in place
+func ApplyOptionOverrides(ctx context.Context, options *GenerateOptions) {
+	if ctx == nil || options == nil {
+		return
+	}
+	overrides, _ := ctx.Value(optionOverridesKey{}).([]func(*GenerateOptions))
+	for _, override := range overrides {
+		override(options)
+	}
+}
+
+// callAdapterKey is the context key for a per-call adapter override
+type callAdapterKey struct{}
+
+// WithCallAdapter returns ctx carrying an adapter that modules use instead of their
+// own for calls made under ctx, so one hard input can be retried with a more
+// permissive adapter without building a second module
+func WithCallAdapter(ctx context.Context, adapter Adapter) context.Context {
+	return context.WithValue(ctx, callAdapterKey{}, adapter)
+}
+
+// CallAdapter returns the adapter attached to ctx, or fallback when there is none
+func CallAdapter(ctx context.Context, fallback Adapter) Adapter {
+	if ctx == nil {
+		return fallback
+	}
+	if adapter, ok := ctx.Value(callAdapterKey{}).(Adapter); ok && adapter != nil {
+		return adapter
+	}
+	return fallback
+}
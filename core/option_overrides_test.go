@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOptionOverrides(t *testing.T) {
+	options := DefaultGenerateOptions()
+	ApplyOptionOverrides(context.Background(), options)
+	if options.Temperature != DefaultGenerateOptions().Temperature {
+		t.Error("a bare context must not change options")
+	}
+
+	ctx := WithOptionOverride(context.Background(), func(o *GenerateOptions) {
+		o.Temperature = 0.3
+		o.MaxTokens = 100
+	})
+	nested := WithOptionOverride(ctx, func(o *GenerateOptions) { o.Temperature = 0.9 })
+
+	ApplyOptionOverrides(nested, options)
+	if options.Temperature != 0.9 || options.MaxTokens != 100 {
+		t.Errorf("expected overrides applied in order, got temperature %v, max tokens %d", options.Temperature, options.MaxTokens)
+	}
+
+	parent := DefaultGenerateOptions()
+	ApplyOptionOverrides(ctx, parent)
+	if parent.Temperature != 0.3 {
+		t.Errorf("nested overrides must not leak into the parent context, got %v", parent.Temperature)
+	}
+}
+
+func TestCallAdapter(t *testing.T) {
+	module := NewChatAdapter()
+	if got := CallAdapter(context.Background(), module); got != module {
+		t.Error("a bare context must return the module's adapter")
+	}
+
+	override := NewJSONAdapter()
+	ctx := WithCallAdapter(context.Background(), override)
+	if got := CallAdapter(ctx, module); got != override {
+		t.Error("expected the per-call adapter")
+	}
+	if got := CallAdapter(WithCallAdapter(ctx, nil), module); got != module {
+		t.Error("a nil override must fall back to the module's adapter")
+	}
+}
@@ -1,5 +1,14 @@
 package core
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 // Prediction wraps module outputs with metadata and provenance
 type Prediction struct {
 	// Core output
@@ -9,11 +18,14 @@ type Prediction struct {
 	Rationale   string           // Reasoning trace (for CoT, etc.)
 	Score       float64          // Confidence/quality score
 	Completions []map[string]any // Alternative completions (for BestOfN)
-	Usage       Usage            // Token usage statistics
+	Scores      []float64        // Scores parallel to Completions (for BestOfN)
+	Usage       Usage            // Token usage statistics (for composite modules, the sum over all sub-calls)
+	SubUsage    map[string]Usage // Usage per step, candidate, attempt or iteration of a composite module
 
 	// Provenance
-	ModuleName string         // Name of module that generated this
-	Inputs     map[string]any // Original inputs
+	ModuleName string                 // Name of module that generated this
+	Inputs     map[string]any         // Original inputs
+	Steps      map[string]*Prediction // Per-step predictions of a named Program pipeline
 
 	// Adapter metrics (for diagnostics and monitoring)
 	AdapterUsed   string // Name of the adapter that successfully parsed the response
@@ -23,14 +35,59 @@ type Prediction struct {
 
 	// Parse diagnostics (for partial outputs and validation tracking)
 	ParseDiagnostics *ValidationDiagnostics // Validation diagnostics for partial outputs
+
+	// Metadata holds parse details such as "json_repaired" (true when malformed JSON was repaired)
+	// and "ignored_fields" (output keys the model returned that are not in the signature)
+	Metadata map[string]any
 }
 
+// jsonRepairedKey marks outputs parsed from repaired JSON; NewPrediction moves it to Metadata
+const jsonRepairedKey = "__json_repaired"
+
+// ignoredFieldsKey lists output keys not in the signature; NewPrediction moves it to Metadata
+const ignoredFieldsKey = "__ignored_fields"
+
 // NewPrediction creates a new prediction from outputs
+// Internal parse markers in outputs are moved to Metadata
 func NewPrediction(outputs map[string]any) *Prediction {
-	return &Prediction{
+	p := &Prediction{
 		Outputs:     outputs,
 		Completions: []map[string]any{},
 	}
+
+	if repaired, ok := outputs[jsonRepairedKey].(bool); ok {
+		delete(outputs, jsonRepairedKey)
+		p.WithMetadata("json_repaired", repaired)
+	}
+	if ignored, ok := outputs[ignoredFieldsKey].([]string); ok {
+		delete(outputs, ignoredFieldsKey)
+		p.WithMetadata("ignored_fields", ignored)
+	}
+
+	return p
+}
+
+// WithMetadata sets a metadata value on the prediction
+func (p *Prediction) WithMetadata(key string, value any) *Prediction {
+	if p.Metadata == nil {
+		p.Metadata = make(map[string]any)
+	}
+	p.Metadata[key] = value
+	return p
+}
+
+// WithStep records the prediction of a named pipeline step
+func (p *Prediction) WithStep(name string, step *Prediction) *Prediction {
+	if p.Steps == nil {
+		p.Steps = make(map[string]*Prediction)
+	}
+	p.Steps[name] = step
+	return p
+}
+
+// Step returns the prediction of a named pipeline step, or nil if there is none
+func (p *Prediction) Step(name string) *Prediction {
+	return p.Steps[name]
 }
 
 // WithRationale adds reasoning trace to the prediction
@@ -51,12 +108,43 @@ func (p *Prediction) WithCompletions(completions []map[string]any) *Prediction {
 	return p
 }
 
+// WithScores adds scores parallel to Completions
+func (p *Prediction) WithScores(scores []float64) *Prediction {
+	p.Scores = scores
+	return p
+}
+
+// GetCandidate returns the i-th completion as a standalone prediction with its own score
+// For BestOfN with ReturnAll, candidates are sorted by score descending (0 = best)
+func (p *Prediction) GetCandidate(i int) (*Prediction, bool) {
+	if i < 0 || i >= len(p.Completions) {
+		return nil, false
+	}
+
+	candidate := NewPrediction(p.Completions[i]).
+		WithModuleName(p.ModuleName).
+		WithInputs(p.Inputs)
+	if i < len(p.Scores) {
+		candidate.Score = p.Scores[i]
+	}
+	return candidate, true
+}
+
 // WithUsage adds token usage statistics
 func (p *Prediction) WithUsage(usage Usage) *Prediction {
 	p.Usage = usage
 	return p
 }
 
+// WithSubUsage records the usage of one step, candidate, attempt or iteration
+func (p *Prediction) WithSubUsage(name string, usage Usage) *Prediction {
+	if p.SubUsage == nil {
+		p.SubUsage = make(map[string]Usage)
+	}
+	p.SubUsage[name] = usage
+	return p
+}
+
 // WithModuleName records which module generated this prediction
 func (p *Prediction) WithModuleName(name string) *Prediction {
 	p.ModuleName = name
@@ -96,29 +184,180 @@ func (p *Prediction) GetString(key string) (string, bool) {
 	return str, ok
 }
 
-// GetFloat retrieves a float value from outputs
+// GetJSON decodes the output field key into target, a pointer to a struct, slice, map
+// or other JSON-decodable value. It handles both outputs already parsed into Go values
+// and strings holding JSON (optionally inside a ``` code fence), so FieldTypeJSON
+// outputs can be consumed without a type switch. A string that is not JSON decodes
+// as a JSON string. It returns an error when the field is missing or does not fit target.
+func (p *Prediction) GetJSON(key string, target any) error {
+	val, ok := p.Outputs[key]
+	if !ok {
+		return fmt.Errorf("output field %q not found", key)
+	}
+
+	var data []byte
+	switch v := val.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	case string:
+		if trimmed := trimCodeFence(v); json.Valid([]byte(trimmed)) {
+			data = []byte(trimmed)
+		}
+	}
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(val); err != nil {
+			return fmt.Errorf("output field %q: %w", key, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("output field %q: %w", key, err)
+	}
+	return nil
+}
+
+// trimCodeFence returns s without surrounding whitespace and a ``` or ```json fence
+func trimCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if body, ok := strings.CutPrefix(s, "```"); ok {
+		if body, ok = strings.CutSuffix(body, "```"); ok {
+			if newline := strings.IndexByte(body, '\n'); newline >= 0 && !strings.ContainsAny(body[:newline], "{[\"") {
+				body = body[newline+1:]
+			}
+			s = strings.TrimSpace(body)
+		}
+	}
+	return s
+}
+
+// GetFloat retrieves a float value from outputs, coercing what models commonly return
+// Besides numbers it accepts numeric strings ("0.85", ".9"), percentages ("85%" = 0.85)
+// and a leading number followed by text ("7.5 stars"). Use GetFloatStrict to disable coercion.
 func (p *Prediction) GetFloat(key string) (float64, bool) {
 	val, ok := p.Outputs[key]
 	if !ok {
 		return 0, false
 	}
-	f, ok := val.(float64)
-	return f, ok
+	if f, ok := numericValue(val); ok {
+		return f, true
+	}
+	s, ok := val.(string)
+	if !ok {
+		return 0, false
+	}
+
+	s = strings.TrimSpace(s)
+	if percent, found := strings.CutSuffix(s, "%"); found {
+		f, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		return f / 100, err == nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	if m := leadingNumberPattern.FindString(s); m != "" {
+		f, err := strconv.ParseFloat(m, 64)
+		return f, err == nil
+	}
+	return 0, false
 }
 
-// GetInt retrieves an int value from outputs
+// GetFloatStrict retrieves a float value only if it is stored as a number
+func (p *Prediction) GetFloatStrict(key string) (float64, bool) {
+	val, ok := p.Outputs[key]
+	if !ok {
+		return 0, false
+	}
+	return numericValue(val)
+}
+
+// GetInt retrieves an int value from outputs, coercing what models commonly return
+// Floats are truncated; strings may hold an integer ("8"), a float ("8.0") or start
+// with one ("8/10", "8 out of 10"). Use GetIntStrict to disable coercion.
 func (p *Prediction) GetInt(key string) (int, bool) {
 	val, ok := p.Outputs[key]
 	if !ok {
 		return 0, false
 	}
+	if i, ok := intValue(val); ok {
+		return i, true
+	}
+	if f, ok := numericValue(val); ok {
+		return int(f), true
+	}
+	s, ok := val.(string)
+	if !ok {
+		return 0, false
+	}
 
-	// Handle both int and float64 (from JSON)
+	s = strings.TrimSpace(s)
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return int(f), true
+	}
+	if m := leadingIntPattern.FindString(s); m != "" {
+		i, err := strconv.Atoi(m)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// GetIntStrict retrieves an int value only if it is stored as a whole number
+// JSON numbers decode as float64, so whole float64 values are accepted; 8.5 is not.
+func (p *Prediction) GetIntStrict(key string) (int, bool) {
+	val, ok := p.Outputs[key]
+	if !ok {
+		return 0, false
+	}
+	if i, ok := intValue(val); ok {
+		return i, true
+	}
+	f, ok := numericValue(val)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// intValue returns integer types as int without a lossy float64 round trip
+func intValue(val any) (int, bool) {
 	switch v := val.(type) {
 	case int:
 		return v, true
-	case float64:
+	case int64:
 		return int(v), true
+	case int32:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+var (
+	leadingNumberPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)`)
+	leadingIntPattern    = regexp.MustCompile(`^[+-]?\d+`)
+)
+
+// numericValue converts Go numeric types to float64
+func numericValue(val any) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
 	default:
 		return 0, false
 	}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiffChange describes how a field differs between two predictions
+type DiffChange string
+
+const (
+	DiffChanged DiffChange = "changed" // Present in both with different values
+	DiffAdded   DiffChange = "added"   // Only present in b
+	DiffRemoved DiffChange = "removed" // Only present in a
+)
+
+// FieldDiff is a field that differs between two predictions
+type FieldDiff struct {
+	Field  string     // Output field name, or "usage.<name>" for usage statistics
+	A      any        // Value in a (nil when added)
+	B      any        // Value in b (nil when removed)
+	Change DiffChange // How the field differs
+}
+
+// String formats the diff as "field: a -> b"
+func (d FieldDiff) String() string {
+	switch d.Change {
+	case DiffAdded:
+		return fmt.Sprintf("%s: added %#v", d.Field, d.B)
+	case DiffRemoved:
+		return fmt.Sprintf("%s: removed %#v", d.Field, d.A)
+	default:
+		return fmt.Sprintf("%s: %#v -> %#v", d.Field, d.A, d.B)
+	}
+}
+
+// DiffOptions configures DiffPredictions
+type DiffOptions struct {
+	// FloatTolerance is the largest absolute difference at which two numbers are equal
+	FloatTolerance float64
+	// StringSimilarity, when above 0, makes strings equal when their edit-distance
+	// similarity (1 - distance / length of the longer string) reaches it
+	StringSimilarity float64
+	// IgnoreFields lists fields to skip; "usage" skips every usage statistic
+	IgnoreFields []string
+}
+
+// DiffOption is a functional option for DiffPredictions
+type DiffOption func(*DiffOptions)
+
+// DiffFloatTolerance treats numbers within tolerance of each other as equal
+func DiffFloatTolerance(tolerance float64) DiffOption {
+	return func(o *DiffOptions) {
+		o.FloatTolerance = tolerance
+	}
+}
+
+// DiffStringSimilarity treats strings at least threshold similar (0-1) as equal, so
+// rewordings of a field such as "The capital is Paris." and "The capital is Paris" pass
+func DiffStringSimilarity(threshold float64) DiffOption {
+	return func(o *DiffOptions) {
+		o.StringSimilarity = threshold
+	}
+}
+
+// DiffIgnoreFields skips volatile fields, e.g. DiffIgnoreFields("usage") or
+// DiffIgnoreFields("usage.latency", "timestamp")
+func DiffIgnoreFields(fields ...string) DiffOption {
+	return func(o *DiffOptions) {
+		o.IgnoreFields = append(o.IgnoreFields, fields...)
+	}
+}
+
+// DiffPredictions compares the outputs and usage of two predictions, e.g. a golden
+// prediction decoded with json.Unmarshal and a fresh one, and returns the fields that
+// differ sorted by name (empty when they match). Numbers compare by value, so 3 equals
+// 3.0; maps and slices compare element by element with the same rules.
+func DiffPredictions(a, b *Prediction, opts ...DiffOption) []FieldDiff {
+	options := DiffOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if a == nil {
+		a = &Prediction{}
+	}
+	if b == nil {
+		b = &Prediction{}
+	}
+
+	fieldsA, fieldsB := diffFields(a), diffFields(b)
+	names := make([]string, 0, len(fieldsA)+len(fieldsB))
+	for name := range fieldsA {
+		names = append(names, name)
+	}
+	for name := range fieldsB {
+		if _, ok := fieldsA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := []FieldDiff{}
+	for _, name := range names {
+		if options.ignored(name) {
+			continue
+		}
+		valueA, inA := fieldsA[name]
+		valueB, inB := fieldsB[name]
+		switch {
+		case !inA:
+			diffs = append(diffs, FieldDiff{Field: name, B: valueB, Change: DiffAdded})
+		case !inB:
+			diffs = append(diffs, FieldDiff{Field: name, A: valueA, Change: DiffRemoved})
+		case !options.equal(valueA, valueB):
+			diffs = append(diffs, FieldDiff{Field: name, A: valueA, B: valueB, Change: DiffChanged})
+		}
+	}
+	return diffs
+}
+
+// diffFields flattens the compared fields of p into one map
+func diffFields(p *Prediction) map[string]any {
+	fields := make(map[string]any, len(p.Outputs)+5)
+	for name, value := range p.Outputs {
+		fields[name] = value
+	}
+	fields["usage.prompt_tokens"] = p.Usage.PromptTokens
+	fields["usage.completion_tokens"] = p.Usage.CompletionTokens
+	fields["usage.total_tokens"] = p.Usage.TotalTokens
+	fields["usage.cost"] = p.Usage.Cost
+	fields["usage.latency"] = p.Usage.Latency
+	return fields
+}
+
+// ignored reports whether field or one of its dotted parents is ignored
+func (o DiffOptions) ignored(field string) bool {
+	for _, ignore := range o.IgnoreFields {
+		if field == ignore || strings.HasPrefix(field, ignore+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares two values with the tolerances of o
+func (o DiffOptions) equal(a, b any) bool {
+	if fa, ok := numericValue(a); ok {
+		fb, ok := numericValue(b)
+		return ok && math.Abs(fa-fb) <= o.FloatTolerance
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && (sa == sb || o.StringSimilarity > 0 && stringSimilarity(sa, sb) >= o.StringSimilarity)
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice:
+		if va.Len() != vb.Len() {
+			return false
+		}
+		for i := 0; i < va.Len(); i++ {
+			if !o.equal(va.Index(i).Interface(), vb.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	case va.Kind() == reflect.Map && vb.Kind() == reflect.Map:
+		if va.Len() != vb.Len() || va.Type().Key() != vb.Type().Key() {
+			return false
+		}
+		for _, key := range va.MapKeys() {
+			eb := vb.MapIndex(key)
+			if !eb.IsValid() || !o.equal(va.MapIndex(key).Interface(), eb.Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// stringSimilarity returns 1 - editDistance / length of the longer string, in runes
+func stringSimilarity(a, b string) float64 {
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
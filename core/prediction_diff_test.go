@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffPredictions(t *testing.T) {
+	golden := NewPrediction(map[string]any{
+		"answer":     "The capital of France is Paris.",
+		"confidence": 0.90,
+		"count":      3,
+		"tags":       []any{"geo", "europe"},
+		"source":     "atlas",
+	}).WithUsage(Usage{PromptTokens: 100, TotalTokens: 120, Latency: 400})
+	current := NewPrediction(map[string]any{
+		"answer":     "The capital of France is Paris",
+		"confidence": 0.92,
+		"count":      3.0,
+		"tags":       []string{"geo", "europe"},
+		"year":       2024,
+	}).WithUsage(Usage{PromptTokens: 100, TotalTokens: 120, Latency: 650})
+
+	tests := []struct {
+		name string
+		opts []DiffOption
+		want map[string]DiffChange
+	}{
+		{"strict", nil, map[string]DiffChange{
+			"answer": DiffChanged, "confidence": DiffChanged, "source": DiffRemoved,
+			"year": DiffAdded, "usage.latency": DiffChanged,
+		}},
+		{"tolerant", []DiffOption{DiffFloatTolerance(0.05), DiffStringSimilarity(0.9), DiffIgnoreFields("usage")}, map[string]DiffChange{
+			"source": DiffRemoved, "year": DiffAdded,
+		}},
+		{"ignore one usage field", []DiffOption{DiffIgnoreFields("usage.latency", "source", "year")}, map[string]DiffChange{
+			"answer": DiffChanged, "confidence": DiffChanged,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := DiffPredictions(golden, current, tt.opts...)
+			got := make(map[string]DiffChange, len(diffs))
+			for _, diff := range diffs {
+				got[diff.Field] = diff.Change
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffPredictions() = %v, want %v", diffs, tt.want)
+			}
+			for field, change := range tt.want {
+				if got[field] != change {
+					t.Errorf("DiffPredictions()[%q] = %q, want %q (diffs %v)", field, got[field], change, diffs)
+				}
+			}
+			for i := 1; i < len(diffs); i++ {
+				if diffs[i-1].Field > diffs[i].Field {
+					t.Errorf("diffs not sorted: %v", diffs)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffPredictions_GoldenRoundTrip(t *testing.T) {
+	prediction := NewPrediction(map[string]any{
+		"answer":  "42",
+		"score":   7.0,
+		"details": map[string]any{"items": []any{1, 2.5, "three"}},
+	}).WithUsage(Usage{TotalTokens: 15, Cost: 0.001})
+
+	data, err := json.Marshal(prediction)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var golden Prediction
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if diffs := DiffPredictions(&golden, prediction); len(diffs) != 0 {
+		t.Errorf("DiffPredictions() = %v, want no differences", diffs)
+	}
+
+	changed := NewPrediction(map[string]any{
+		"answer":  "42",
+		"score":   7.0,
+		"details": map[string]any{"items": []any{1, 2.5, "four"}},
+	}).WithUsage(prediction.Usage)
+	diffs := DiffPredictions(&golden, changed)
+	if len(diffs) != 1 || diffs[0].Field != "details" || diffs[0].Change != DiffChanged {
+		t.Errorf("DiffPredictions() = %v, want details changed", diffs)
+	}
+}
+
+func TestFieldDiff_String(t *testing.T) {
+	tests := []struct {
+		diff FieldDiff
+		want string
+	}{
+		{FieldDiff{Field: "answer", A: "a", B: "b", Change: DiffChanged}, `answer: "a" -> "b"`},
+		{FieldDiff{Field: "year", B: 2024, Change: DiffAdded}, "year: added 2024"},
+		{FieldDiff{Field: "source", A: "atlas", Change: DiffRemoved}, `source: removed "atlas"`},
+	}
+	for _, tt := range tests {
+		if got := tt.diff.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// predictionJSON is the serialized form of a Prediction
+type predictionJSON struct {
+	Outputs     map[string]any   `json:"outputs"`
+	Rationale   string           `json:"rationale,omitempty"`
+	Score       float64          `json:"score,omitempty"`
+	Completions []map[string]any `json:"completions,omitempty"`
+	Scores      []float64        `json:"scores,omitempty"`
+	Usage       Usage            `json:"usage"`
+	SubUsage    map[string]Usage `json:"sub_usage,omitempty"`
+
+	ModuleName string                 `json:"module_name,omitempty"`
+	Inputs     map[string]any         `json:"inputs,omitempty"`
+	Steps      map[string]*Prediction `json:"steps,omitempty"`
+
+	AdapterUsed   string `json:"adapter_used,omitempty"`
+	ParseSuccess  bool   `json:"parse_success,omitempty"`
+	ParseAttempts int    `json:"parse_attempts,omitempty"`
+	FallbackUsed  bool   `json:"fallback_used,omitempty"`
+
+	ParseDiagnostics *diagnosticsJSON `json:"parse_diagnostics,omitempty"`
+	Metadata         map[string]any   `json:"metadata,omitempty"`
+}
+
+// diagnosticsJSON stores ValidationDiagnostics errors as their messages
+type diagnosticsJSON struct {
+	MissingFields []string          `json:"missing_fields,omitempty"`
+	TypeErrors    map[string]string `json:"type_errors,omitempty"`
+	ClassErrors   map[string]string `json:"class_errors,omitempty"`
+}
+
+// MarshalJSON encodes the complete prediction (outputs, usage, rationale, completions,
+// steps, adapter metrics and metadata) so it can be cached, logged or replayed.
+// Floats are always written with a decimal point so that UnmarshalJSON restores ints
+// as int and floats as float64, at any depth of Outputs, Inputs, Completions and Metadata.
+func (p *Prediction) MarshalJSON() ([]byte, error) {
+	wire := predictionJSON{
+		Outputs:       typedJSONMap(p.Outputs),
+		Rationale:     p.Rationale,
+		Score:         p.Score,
+		Scores:        p.Scores,
+		Usage:         p.Usage,
+		SubUsage:      p.SubUsage,
+		ModuleName:    p.ModuleName,
+		Inputs:        typedJSONMap(p.Inputs),
+		Steps:         p.Steps,
+		AdapterUsed:   p.AdapterUsed,
+		ParseSuccess:  p.ParseSuccess,
+		ParseAttempts: p.ParseAttempts,
+		FallbackUsed:  p.FallbackUsed,
+		Metadata:      typedJSONMap(p.Metadata),
+	}
+	for _, completion := range p.Completions {
+		wire.Completions = append(wire.Completions, typedJSONMap(completion))
+	}
+	if d := p.ParseDiagnostics; d != nil {
+		wire.ParseDiagnostics = &diagnosticsJSON{
+			MissingFields: d.MissingFields,
+			TypeErrors:    errorMessages(d.TypeErrors),
+			ClassErrors:   errorMessages(d.ClassErrors),
+		}
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON decodes a prediction written by MarshalJSON. Numbers decode as int
+// when written without a decimal point or exponent and as float64 otherwise; objects
+// and arrays decode as map[string]any and []any.
+func (p *Prediction) UnmarshalJSON(data []byte) error {
+	var wire predictionJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&wire); err != nil {
+		return err
+	}
+
+	*p = Prediction{
+		Outputs:       untypedJSONMap(wire.Outputs),
+		Rationale:     wire.Rationale,
+		Score:         wire.Score,
+		Completions:   make([]map[string]any, 0, len(wire.Completions)),
+		Scores:        wire.Scores,
+		Usage:         wire.Usage,
+		SubUsage:      wire.SubUsage,
+		ModuleName:    wire.ModuleName,
+		Inputs:        untypedJSONMap(wire.Inputs),
+		Steps:         wire.Steps,
+		AdapterUsed:   wire.AdapterUsed,
+		ParseSuccess:  wire.ParseSuccess,
+		ParseAttempts: wire.ParseAttempts,
+		FallbackUsed:  wire.FallbackUsed,
+		Metadata:      untypedJSONMap(wire.Metadata),
+	}
+	if p.Outputs == nil {
+		p.Outputs = map[string]any{}
+	}
+	for _, completion := range wire.Completions {
+		p.Completions = append(p.Completions, untypedJSONMap(completion))
+	}
+	if d := wire.ParseDiagnostics; d != nil {
+		p.ParseDiagnostics = &ValidationDiagnostics{
+			MissingFields: d.MissingFields,
+			TypeErrors:    messageErrors(d.TypeErrors),
+			ClassErrors:   messageErrors(d.ClassErrors),
+		}
+	}
+	return nil
+}
+
+// jsonFloat marshals a float with a decimal point (3 → 3.0) so it decodes as a float again
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return json.Marshal(v) // reports the unsupported value
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return []byte(s), nil
+}
+
+// typedJSONMap returns m with floats wrapped in jsonFloat, recursively
+func typedJSONMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = typedJSONValue(v)
+	}
+	return out
+}
+
+func typedJSONValue(v any) any {
+	switch val := v.(type) {
+	case float64:
+		return jsonFloat(val)
+	case float32:
+		return jsonFloat(val)
+	case map[string]any:
+		return typedJSONMap(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = typedJSONValue(item)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = typedJSONMap(item)
+		}
+		return out
+	}
+	return v
+}
+
+// untypedJSONMap converts the json.Numbers of a UseNumber decode to int or float64, recursively
+func untypedJSONMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	for k, v := range m {
+		m[k] = untypedJSONValue(v)
+	}
+	return m
+}
+
+func untypedJSONValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(string(val), ".eE") {
+			if i, err := strconv.Atoi(string(val)); err == nil {
+				return i
+			}
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		return untypedJSONMap(val)
+	case []any:
+		for i, item := range val {
+			val[i] = untypedJSONValue(item)
+		}
+		return val
+	}
+	return v
+}
+
+func errorMessages(errs map[string]error) map[string]string {
+	if errs == nil {
+		return nil
+	}
+	out := make(map[string]string, len(errs))
+	for k, err := range errs {
+		out[k] = err.Error()
+	}
+	return out
+}
+
+func messageErrors(messages map[string]string) map[string]error {
+	if messages == nil {
+		return nil
+	}
+	out := make(map[string]error, len(messages))
+	for k, msg := range messages {
+		out[k] = errors.New(msg)
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrediction_JSONRoundTrip(t *testing.T) {
+	step := NewPrediction(map[string]any{"query": "go generics"}).WithModuleName("Predict")
+	original := NewPrediction(map[string]any{
+		"answer":     "42",
+		"count":      7,
+		"confidence": 0.95,
+		"whole":      3.0,
+		"done":       true,
+		"missing":    nil,
+		"details": map[string]any{
+			"items": []any{1.0, 2.5, "three", map[string]any{"n": 4.0}},
+			"depth": 2.0,
+		},
+	}).
+		WithRationale("step by step").
+		WithScore(0.8).
+		WithCompletions([]map[string]any{{"answer": "42", "votes": 3}, {"answer": "41", "votes": 1}}).
+		WithScores([]float64{0.8, 0.2}).
+		WithUsage(Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Cost: 0.0012, Latency: 340}).
+		WithSubUsage("candidate_0", Usage{TotalTokens: 15}).
+		WithModuleName("BestOfN").
+		WithInputs(map[string]any{"question": "meaning of life", "limit": 2}).
+		WithStep("search", step).
+		WithAdapterMetrics("JSONAdapter", 2, true).
+		WithParseDiagnostics(&ValidationDiagnostics{
+			MissingFields: []string{"sources"},
+			TypeErrors:    map[string]error{"count": errors.New("expected int")},
+		}).
+		WithMetadata("json_repaired", true).
+		WithMetadata("attempt", 1)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var restored Prediction
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(restored.Outputs, original.Outputs) {
+		t.Errorf("Outputs = %#v, want %#v", restored.Outputs, original.Outputs)
+	}
+	if !reflect.DeepEqual(restored.Completions, original.Completions) {
+		t.Errorf("Completions = %#v, want %#v", restored.Completions, original.Completions)
+	}
+	if !reflect.DeepEqual(restored.Inputs, original.Inputs) || !reflect.DeepEqual(restored.Metadata, original.Metadata) {
+		t.Errorf("Inputs/Metadata = %#v / %#v", restored.Inputs, restored.Metadata)
+	}
+	if restored.Rationale != original.Rationale || restored.Score != original.Score ||
+		!reflect.DeepEqual(restored.Scores, original.Scores) ||
+		restored.Usage != original.Usage || !reflect.DeepEqual(restored.SubUsage, original.SubUsage) ||
+		restored.ModuleName != original.ModuleName {
+		t.Errorf("restored = %+v", restored)
+	}
+	if restored.AdapterUsed != "JSONAdapter" || restored.ParseAttempts != 2 || restored.ParseSuccess || !restored.FallbackUsed {
+		t.Errorf("adapter metrics = %q %d %v %v", restored.AdapterUsed, restored.ParseAttempts, restored.ParseSuccess, restored.FallbackUsed)
+	}
+	if s := restored.Step("search"); s == nil || s.ModuleName != "Predict" || s.Outputs["query"] != "go generics" {
+		t.Errorf("Step(search) = %+v", s)
+	}
+	diag := restored.ParseDiagnostics
+	if diag == nil || !reflect.DeepEqual(diag.MissingFields, []string{"sources"}) || diag.TypeErrors["count"].Error() != "expected int" {
+		t.Errorf("ParseDiagnostics = %+v", diag)
+	}
+
+	again, err := json.Marshal(&restored)
+	if err != nil {
+		t.Fatalf("Marshal() of restored error = %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("second round trip differs:\n%s\n%s", data, again)
+	}
+}
+
+func TestPrediction_JSONNumberTypes(t *testing.T) {
+	data, err := json.Marshal(NewPrediction(map[string]any{"i": 3, "f": 3.0, "big": 1e21, "small": 1.5e-7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"f":3.0`) || !strings.Contains(string(data), `"i":3`) {
+		t.Errorf("floats should keep a decimal point: %s", data)
+	}
+
+	var p Prediction
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"i": 3, "f": 3.0, "big": 1e21, "small": 1.5e-7} {
+		if got := p.Outputs[key]; got != want {
+			t.Errorf("Outputs[%q] = %#v (%T), want %#v (%T)", key, got, got, want, want)
+		}
+	}
+
+	// Hand-written JSON follows the same rule
+	if err := json.Unmarshal([]byte(`{"outputs": {"n": 12345678901, "x": 2.0}}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Outputs["n"] != 12345678901 || p.Outputs["x"] != 2.0 || p.Completions == nil {
+		t.Errorf("Outputs = %#v, Completions = %#v", p.Outputs, p.Completions)
+	}
+}
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -37,6 +41,66 @@ func TestPrediction_WithMethods(t *testing.T) {
 	}
 }
 
+func TestPrediction_GetFloat_Coercion(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		want   float64
+		wantOk bool
+	}{
+		{"numeric string", "0.85", 0.85, true},
+		{"leading dot", ".9", 0.9, true},
+		{"percentage", "85%", 0.85, true},
+		{"percentage with space", "42.5 %", 0.425, true},
+		{"int value", 3, 3, true},
+		{"leading number with unit", "7.5 stars", 7.5, true},
+		{"json number", json.Number("0.25"), 0.25, true},
+		{"words", "high", 0, false},
+		{"bad percentage", "lots%", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrediction(map[string]any{"score": tt.value})
+			got, ok := p.GetFloat("score")
+			if ok != tt.wantOk || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("GetFloat() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPrediction_StrictGetters(t *testing.T) {
+	p := NewPrediction(map[string]any{
+		"whole":      8.0,
+		"fraction":   8.5,
+		"int":        7,
+		"string_num": "8",
+	})
+
+	if v, ok := p.GetIntStrict("whole"); !ok || v != 8 {
+		t.Errorf("GetIntStrict(whole) = %v, %v; want 8, true", v, ok)
+	}
+	if v, ok := p.GetIntStrict("int"); !ok || v != 7 {
+		t.Errorf("GetIntStrict(int) = %v, %v; want 7, true", v, ok)
+	}
+	if _, ok := p.GetIntStrict("fraction"); ok {
+		t.Error("GetIntStrict should reject a fractional value")
+	}
+	if _, ok := p.GetIntStrict("string_num"); ok {
+		t.Error("GetIntStrict should not parse strings")
+	}
+	if v, ok := p.GetFloatStrict("int"); !ok || v != 7 {
+		t.Errorf("GetFloatStrict(int) = %v, %v; want 7, true", v, ok)
+	}
+	if _, ok := p.GetFloatStrict("string_num"); ok {
+		t.Error("GetFloatStrict should not parse strings")
+	}
+	if _, ok := p.GetFloatStrict("missing"); ok {
+		t.Error("GetFloatStrict should return false for a missing key")
+	}
+}
+
 func TestPrediction_GetMethods(t *testing.T) {
 	p := NewPrediction(map[string]any{
 		"text":   "hello",
@@ -89,6 +153,51 @@ func TestPrediction_WithCompletions(t *testing.T) {
 	}
 }
 
+func TestPrediction_GetCandidate(t *testing.T) {
+	p := NewPrediction(map[string]any{"answer": "best"}).
+		WithCompletions([]map[string]any{
+			{"answer": "best"},
+			{"answer": "runner-up"},
+		}).
+		WithScores([]float64{0.9, 0.4}).
+		WithModuleName("BestOfN").
+		WithInputs(map[string]any{"q": "x"})
+
+	tests := []struct {
+		name       string
+		index      int
+		wantOK     bool
+		wantAnswer string
+		wantScore  float64
+	}{
+		{"best", 0, true, "best", 0.9},
+		{"second", 1, true, "runner-up", 0.4},
+		{"out of range", 2, false, "", 0},
+		{"negative", -1, false, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate, ok := p.GetCandidate(tt.index)
+			if ok != tt.wantOK {
+				t.Fatalf("GetCandidate(%d) ok = %v, want %v", tt.index, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if answer, _ := candidate.GetString("answer"); answer != tt.wantAnswer {
+				t.Errorf("answer = %q, want %q", answer, tt.wantAnswer)
+			}
+			if candidate.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", candidate.Score, tt.wantScore)
+			}
+			if candidate.ModuleName != "BestOfN" || candidate.Inputs["q"] != "x" {
+				t.Error("candidate should carry module name and inputs")
+			}
+		})
+	}
+}
+
 func TestPrediction_GetInt(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -100,6 +209,13 @@ func TestPrediction_GetInt(t *testing.T) {
 		{"float64 value", 42.0, 42, true},
 		{"string value", "not an int", 0, false},
 		{"missing key", nil, 0, false},
+		{"numeric string", " 8 ", 8, true},
+		{"float string", "8.0", 8, true},
+		{"score with denominator", "8/10", 8, true},
+		{"leading integer with text", "7 out of 10", 7, true},
+		{"negative string", "-3", -3, true},
+		{"json number", json.Number("12"), 12, true},
+		{"text before number", "about 8", 0, false},
 	}
 
 	for _, tt := range tests {
@@ -134,6 +250,48 @@ func TestPrediction_Get(t *testing.T) {
 	}
 }
 
+func TestPrediction_GetJSON(t *testing.T) {
+	type testCase struct {
+		Input    []int `json:"input"`
+		Expected int   `json:"expected"`
+	}
+	want := []testCase{{Input: []int{1, 2}, Expected: 3}}
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"parsed", []any{map[string]any{"input": []any{1.0, 2.0}, "expected": 3.0}}},
+		{"JSON string", `[{"input": [1, 2], "expected": 3}]`},
+		{"fenced JSON string", "```json\n[{\"input\": [1, 2], \"expected\": 3}]\n```"},
+		{"raw message", json.RawMessage(`[{"input": [1, 2], "expected": 3}]`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []testCase
+			if err := NewPrediction(map[string]any{"tests": tt.value}).GetJSON("tests", &got); err != nil {
+				t.Fatalf("GetJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetJSON() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	p := NewPrediction(map[string]any{"name": "plain text", "tests": "not json"})
+	var name string
+	if err := p.GetJSON("name", &name); err != nil || name != "plain text" {
+		t.Errorf("GetJSON() on plain text = %q, %v", name, err)
+	}
+	var cases []testCase
+	if err := p.GetJSON("tests", &cases); err == nil {
+		t.Error("GetJSON() should fail when the value does not fit the target")
+	}
+	if err := p.GetJSON("missing", &cases); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("GetJSON() on a missing field error = %v", err)
+	}
+}
+
 func TestPrediction_WithUsage(t *testing.T) {
 	usage := Usage{
 		PromptTokens:     100,
@@ -740,3 +898,47 @@ func TestPredictionGetStringStripMarkers(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPrediction_JSONRepairedMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputs      map[string]any
+		wantMetadata bool
+	}{
+		{"repaired", map[string]any{"answer": "42", jsonRepairedKey: true}, true},
+		{"clean", map[string]any{"answer": "42"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred := NewPrediction(tt.outputs)
+
+			if _, leaked := pred.Outputs[jsonRepairedKey]; leaked {
+				t.Error("internal repair marker leaked into outputs")
+			}
+			repaired, ok := pred.Metadata["json_repaired"].(bool)
+			if ok != tt.wantMetadata || (ok && !repaired) {
+				t.Errorf("Metadata[json_repaired] = %v (present %v), want present %v", repaired, ok, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+func TestJSONAdapter_RepairFlowsToPrediction(t *testing.T) {
+	sig := NewSignature("").
+		AddOutput("answer", FieldTypeString, "").
+		AddOutput("confidence", FieldTypeFloat, "")
+
+	outputs, err := NewJSONAdapter().Parse(sig, "```json\n{'answer': '42', \"confidence\": 0.9,\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	pred := NewPrediction(outputs)
+	if pred.Metadata["json_repaired"] != true {
+		t.Errorf("expected json_repaired metadata, got %v", pred.Metadata)
+	}
+	if pred.Outputs["answer"] != "42" || pred.Outputs["confidence"] != 0.9 {
+		t.Errorf("unexpected outputs: %v", pred.Outputs)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import "github.com/assagman/dsgo/internal/cost"
+
+// ModelPricing is the price of a model in USD per million tokens
+type ModelPricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// RegisterPricing sets the global price used to compute Usage.Cost for model
+// Prices are in USD per million input (prompt) and output (completion) tokens
+func RegisterPricing(model string, inputPerMTok, outputPerMTok float64) {
+	cost.DefaultCalculator.SetModelPricing(model, cost.ModelPricing{
+		PromptPrice:     inputPerMTok,
+		CompletionPrice: outputPerMTok,
+	})
+}
+
+// PricingFor returns the price of model, preferring WithPricing overrides over the registry
+func PricingFor(model string) (ModelPricing, bool) {
+	if pricing, ok := GetSettings().Pricing[model]; ok {
+		return pricing, true
+	}
+
+	pricing, ok := cost.DefaultCalculator.GetPricing(model)
+	if !ok {
+		return ModelPricing{}, false
+	}
+	return ModelPricing{InputPerMTok: pricing.PromptPrice, OutputPerMTok: pricing.CompletionPrice}, true
+}
+
+// CalculateCost computes the USD cost of a call from its token counts
+// It returns false when no pricing is known for model
+func CalculateCost(model string, promptTokens, completionTokens int) (float64, bool) {
+	pricing, ok := PricingFor(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*pricing.InputPerMTok + float64(completionTokens)*pricing.OutputPerMTok) / 1_000_000, true
+}
+
+// FillCost sets usage.Cost from token counts when the provider did not report a cost
+func FillCost(model string, usage *Usage) {
+	if usage == nil || usage.Cost > 0 {
+		return
+	}
+	if c, ok := CalculateCost(model, usage.PromptTokens, usage.CompletionTokens); ok {
+		usage.Cost = c
+	}
+}
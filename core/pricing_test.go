@@ -0,0 +1,79 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateCost(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	RegisterPricing("acme/registered-model", 1.0, 2.0)
+	Configure(WithPricing("acme/override-model", 10.0, 20.0), WithPricing("gpt-4o", 0, 0))
+
+	tests := []struct {
+		name   string
+		model  string
+		want   float64
+		wantOK bool
+	}{
+		{"default table", "openai/gpt-4o-mini", 0.00045, true},
+		{"bare model name", "gpt-4o-mini", 0.00045, true},
+		{"registered", "acme/registered-model", 0.002, true},
+		{"settings override", "acme/override-model", 0.02, true},
+		{"override beats default", "gpt-4o", 0, true},
+		{"unknown", "acme/unknown-model", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CalculateCost(tt.model, 1000, 500)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CalculateCost(%q) = (%f, %v), want (%f, %v)", tt.model, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFillCost(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+	Configure(WithPricing("priced-model", 1.0, 1.0))
+
+	tests := []struct {
+		name  string
+		model string
+		usage Usage
+		want  float64
+	}{
+		{"computes missing cost", "priced-model", Usage{PromptTokens: 1_000_000}, 1.0},
+		{"keeps provider cost", "priced-model", Usage{PromptTokens: 1_000_000, Cost: 0.5}, 0.5},
+		{"unknown model stays zero", "unpriced-model", Usage{PromptTokens: 1_000_000}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := tt.usage
+			FillCost(tt.model, &usage)
+			if math.Abs(usage.Cost-tt.want) > 1e-9 {
+				t.Errorf("Cost = %f, want %f", usage.Cost, tt.want)
+			}
+		})
+	}
+
+	FillCost("priced-model", nil) // must not panic
+}
+
+func TestWithPricing_Reset(t *testing.T) {
+	ResetConfig()
+	Configure(WithPricing("temp-model", 1, 1))
+	if _, ok := GetSettings().Pricing["temp-model"]; !ok {
+		t.Fatal("expected pricing override in settings")
+	}
+
+	ResetConfig()
+	if GetSettings().Pricing != nil {
+		t.Error("expected ResetConfig to clear pricing overrides")
+	}
+}
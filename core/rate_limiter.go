@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimitDecreaseFactor is the multiplicative decrease applied on a 429 response
+	rateLimitDecreaseFactor = 0.5
+	// rateLimitIncreaseFraction is the fraction of the configured rate restored per success
+	rateLimitIncreaseFraction = 0.1
+	// rateLimitMinFraction is the lowest fraction of the configured rate adaptation may reach
+	rateLimitMinFraction = 0.05
+)
+
+// RateLimiter is a token-bucket limiter used to self-throttle LM calls.
+// When adaptive, the refill rate is halved on rate-limit errors and
+// recovers additively on successful calls (AIMD).
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64 // current tokens per second
+	maxRate  float64 // configured tokens per second
+	burst    float64
+	tokens   float64
+	last     time.Time
+	adaptive bool
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a token-bucket limiter allowing rps requests per second
+// with bursts of up to burst requests. A burst below 1 is treated as 1.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rps,
+		maxRate: rps,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    time.Now(),
+		now:     time.Now,
+	}
+}
+
+// WithAdaptive enables or disables AIMD rate adaptation on 429 responses
+func (r *RateLimiter) WithAdaptive(adaptive bool) *RateLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adaptive = adaptive
+	return r
+}
+
+// Wait blocks until a token is available or the context is done.
+// A non-positive rate disables limiting.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		if r.rate <= 0 {
+			r.mu.Unlock()
+			return ctx.Err()
+		}
+		r.refill()
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Rate returns the current refill rate in requests per second
+func (r *RateLimiter) Rate() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rate
+}
+
+// OnRateLimited multiplicatively decreases the rate (adaptive mode only)
+func (r *RateLimiter) OnRateLimited() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.adaptive {
+		return
+	}
+	r.refill()
+	minRate := r.maxRate * rateLimitMinFraction
+	r.rate *= rateLimitDecreaseFactor
+	if r.rate < minRate {
+		r.rate = minRate
+	}
+}
+
+// OnSuccess additively increases the rate back toward the configured rate (adaptive mode only)
+func (r *RateLimiter) OnSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.adaptive || r.rate >= r.maxRate {
+		return
+	}
+	r.refill()
+	r.rate += r.maxRate * rateLimitIncreaseFraction
+	if r.rate > r.maxRate {
+		r.rate = r.maxRate
+	}
+}
+
+// refill adds tokens accrued since the last refill; caller must hold mu
+func (r *RateLimiter) refill() {
+	now := r.now()
+	elapsed := now.Sub(r.last).Seconds()
+	r.last = now
+	if elapsed <= 0 {
+		return
+	}
+	r.tokens += elapsed * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+}
+
+// matches reports whether the limiter was created with the given configuration
+func (r *RateLimiter) matches(rps float64, burst int, adaptive bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+	return r.maxRate == rps && r.burst == float64(burst) && r.adaptive == adaptive
+}
+
+// RateLimitedLM wraps an LM so every call first acquires a token from a RateLimiter
+type RateLimitedLM struct {
+	lm      LM
+	limiter *RateLimiter
+}
+
+// NewRateLimitedLM wraps lm with the given limiter
+func NewRateLimitedLM(lm LM, limiter *RateLimiter) LM {
+	return &RateLimitedLM{
+		lm:      lm,
+		limiter: limiter,
+	}
+}
+
+// Generate waits for a token, then delegates to the underlying LM
+func (r *RateLimitedLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := r.lm.Generate(ctx, messages, options)
+	r.observe(err)
+	return result, err
+}
+
+// Stream waits for a token, then delegates to the underlying LM
+func (r *RateLimitedLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		chunkChan := make(chan Chunk)
+		errChan := make(chan error, 1)
+		close(chunkChan)
+		errChan <- err
+		close(errChan)
+		return chunkChan, errChan
+	}
+
+	chunkChan, inErrChan := r.lm.Stream(ctx, messages, options)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(errChan)
+		var streamErr error
+		for err := range inErrChan {
+			if err != nil && streamErr == nil {
+				streamErr = err
+			}
+			errChan <- err
+		}
+		r.observe(streamErr)
+	}()
+
+	return chunkChan, errChan
+}
+
+// Name returns the underlying LM's name
+func (r *RateLimitedLM) Name() string {
+	return r.lm.Name()
+}
+
+// SupportsJSON returns whether the underlying LM supports JSON
+func (r *RateLimitedLM) SupportsJSON() bool {
+	return r.lm.SupportsJSON()
+}
+
+// SupportsTools returns whether the underlying LM supports tools
+func (r *RateLimitedLM) SupportsTools() bool {
+	return r.lm.SupportsTools()
+}
+
+// Capabilities reports the wrapped LM's capabilities
+func (r *RateLimitedLM) Capabilities() Capabilities {
+	return CapabilitiesOf(r.lm)
+}
+
+// HealthCheck checks the wrapped LM
+func (r *RateLimitedLM) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, r.lm)
+}
+
+// observe feeds the call outcome back into the limiter for adaptation
+func (r *RateLimitedLM) observe(err error) {
+	switch {
+	case err == nil:
+		r.limiter.OnSuccess()
+	case isRateLimitError(err):
+		r.limiter.OnRateLimited()
+	}
+}
+
+// isRateLimitError reports whether err represents an HTTP 429 response
+func isRateLimitError(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+var (
+	providerLimiters   = make(map[string]*RateLimiter)
+	providerLimitersMu sync.Mutex
+)
+
+// providerRateLimiter returns the limiter shared by all LMs of a provider,
+// creating a new one when none exists or the configuration changed.
+func providerRateLimiter(provider string, rps float64, burst int, adaptive bool) *RateLimiter {
+	providerLimitersMu.Lock()
+	defer providerLimitersMu.Unlock()
+
+	if limiter, ok := providerLimiters[provider]; ok && limiter.matches(rps, burst, adaptive) {
+		return limiter
+	}
+
+	limiter := NewRateLimiter(rps, burst).WithAdaptive(adaptive)
+	providerLimiters[provider] = limiter
+	return limiter
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	limiter := NewRateLimiter(1, 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("burst of 3 should not block, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_BlocksUntilRefill(t *testing.T) {
+	limiter := NewRateLimiter(20, 1)
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("second Wait() should block ~50ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextCancellation(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRateLimiter_Adaptive(t *testing.T) {
+	tests := []struct {
+		name     string
+		adaptive bool
+		want     float64
+	}{
+		{name: "adaptive halves rate", adaptive: true, want: 5},
+		{name: "non-adaptive keeps rate", adaptive: false, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(10, 1).WithAdaptive(tt.adaptive)
+			limiter.OnRateLimited()
+			if got := limiter.Rate(); got != tt.want {
+				t.Errorf("Rate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("recovers additively up to configured rate", func(t *testing.T) {
+		limiter := NewRateLimiter(10, 1).WithAdaptive(true)
+		limiter.OnRateLimited()
+		limiter.OnSuccess()
+		if got := limiter.Rate(); got != 6 {
+			t.Errorf("Rate() = %v, want 6", got)
+		}
+		for i := 0; i < 10; i++ {
+			limiter.OnSuccess()
+		}
+		if got := limiter.Rate(); got != 10 {
+			t.Errorf("Rate() = %v, want 10", got)
+		}
+	})
+
+	t.Run("floors at minimum rate", func(t *testing.T) {
+		limiter := NewRateLimiter(10, 1).WithAdaptive(true)
+		for i := 0; i < 20; i++ {
+			limiter.OnRateLimited()
+		}
+		if got := limiter.Rate(); got != 10*rateLimitMinFraction {
+			t.Errorf("Rate() = %v, want %v", got, 10*rateLimitMinFraction)
+		}
+	})
+}
+
+func TestRateLimitedLM_Generate(t *testing.T) {
+	t.Run("adapts on 429", func(t *testing.T) {
+		limiter := NewRateLimiter(10, 5).WithAdaptive(true)
+		lm := NewRateLimitedLM(&mockWrapperLM{
+			name: "test",
+			generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+				return nil, errors.New("API request failed with status 429: too many requests")
+			},
+		}, limiter)
+
+		_, err := lm.Generate(context.Background(), nil, nil)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if got := limiter.Rate(); got != 5 {
+			t.Errorf("Rate() = %v, want 5", got)
+		}
+	})
+
+	t.Run("returns context error when cancelled", func(t *testing.T) {
+		limiter := NewRateLimiter(0.1, 1)
+		_ = limiter.Wait(context.Background())
+
+		called := false
+		lm := NewRateLimitedLM(&mockWrapperLM{
+			name: "test",
+			generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+				called = true
+				return &GenerateResult{}, nil
+			},
+		}, limiter)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := lm.Generate(ctx, nil, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Generate() error = %v, want context.Canceled", err)
+		}
+		if called {
+			t.Error("underlying LM should not be called when context is cancelled")
+		}
+	})
+}
+
+func TestNewLM_WithRateLimit(t *testing.T) {
+	originalRegistry := make(map[string]LMFactory)
+	registryLock.Lock()
+	for k, v := range lmRegistry {
+		originalRegistry[k] = v
+	}
+	registryLock.Unlock()
+
+	defer func() {
+		registryLock.Lock()
+		lmRegistry = originalRegistry
+		registryLock.Unlock()
+		ResetConfig()
+	}()
+
+	RegisterLM("ratelimit-provider", func(model string) LM {
+		return &mockLM{}
+	})
+
+	ResetConfig()
+	Configure(WithRateLimit(5, 2))
+
+	ctx := context.Background()
+	lm1, err := NewLM(ctx, "ratelimit-provider/model-a")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	lm2, err := NewLM(ctx, "ratelimit-provider/model-b")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+
+	rl1, ok := lm1.(*RateLimitedLM)
+	if !ok {
+		t.Fatalf("expected *RateLimitedLM, got %T", lm1)
+	}
+	rl2, ok := lm2.(*RateLimitedLM)
+	if !ok {
+		t.Fatalf("expected *RateLimitedLM, got %T", lm2)
+	}
+	if rl1.limiter != rl2.limiter {
+		t.Error("expected LMs of the same provider to share a limiter")
+	}
+}
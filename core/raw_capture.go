@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultArtifactDir is used when DSGO_SAVE_RAW_RESPONSES is set without DSGO_ARTIFACT_DIR
+const defaultArtifactDir = "test_matrix_logs"
+
+// RawExchange is one provider HTTP request/response pair captured for debugging
+type RawExchange struct {
+	Provider   string
+	Model      string
+	RequestID  string      // Namespaces the file; empty saves directly under the capture directory
+	Request    any         // Request body sent to the provider
+	StatusCode int         // HTTP status of the response
+	Header     http.Header // Response headers
+	Body       []byte      // Raw response body
+	Err        error       // Error returned for this exchange (API, decode or parse failure), if any
+}
+
+// RawCaptureDir returns the directory raw exchanges are saved to ("" = capture disabled)
+// WithRawResponseCapture takes precedence; otherwise DSGO_SAVE_RAW_RESPONSES=true enables
+// capture into $DSGO_ARTIFACT_DIR/raw (default test_matrix_logs/raw).
+func RawCaptureDir() string {
+	globalSettings.mu.RLock()
+	dir := globalSettings.RawResponseDir
+	globalSettings.mu.RUnlock()
+	if dir != "" {
+		return dir
+	}
+
+	if enabled, _ := strconv.ParseBool(os.Getenv("DSGO_SAVE_RAW_RESPONSES")); !enabled {
+		return ""
+	}
+	base := os.Getenv("DSGO_ARTIFACT_DIR")
+	if base == "" {
+		base = defaultArtifactDir
+	}
+	return filepath.Join(base, "raw")
+}
+
+// CaptureRawExchange saves exchange as JSON when raw response capture is enabled
+// Files are written to <dir>/<request id>/<timestamp>_<provider>_<model>.json.
+// It returns the file path, or "" when capture is disabled.
+func CaptureRawExchange(exchange RawExchange) (string, error) {
+	dir := RawCaptureDir()
+	if dir == "" {
+		return "", nil
+	}
+	if exchange.RequestID != "" {
+		dir = filepath.Join(dir, safeFileName(exchange.RequestID))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	record := map[string]any{
+		"timestamp":  now.Format(time.RFC3339Nano),
+		"request_id": exchange.RequestID,
+		"provider":   exchange.Provider,
+		"model":      exchange.Model,
+		"example":    os.Getenv("DSGO_EXAMPLE"),
+		"request":    exchange.Request,
+		"http": map[string]any{
+			"status":  exchange.StatusCode,
+			"headers": exchange.Header,
+		},
+	}
+	// Keep JSON bodies structured so the file is readable; anything else is stored verbatim
+	var response any
+	if err := json.Unmarshal(exchange.Body, &response); err == nil {
+		record["response"] = response
+	} else {
+		record["response_text"] = string(exchange.Body)
+	}
+	if exchange.Err != nil {
+		record["error"] = exchange.Err.Error()
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%s_%s.json", now.Format("20060102_150405.000000"), safeFileName(exchange.Provider), safeFileName(exchange.Model))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// safeFileName makes s a single file name: path separators and colons, which appear in
+// model IDs, become "_", and so do "." and "..", so a request ID cannot leave the capture dir
+func safeFileName(s string) string {
+	s = strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(s)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
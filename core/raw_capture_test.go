@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRawCaptureDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		save     string
+		artifact string
+		want     string
+	}{
+		{"disabled", "", "", "", ""},
+		{"configured", "captures", "", "", "captures"},
+		{"configured wins over env", "captures", "true", "artifacts", "captures"},
+		{"env with artifact dir", "", "true", "artifacts", filepath.Join("artifacts", "raw")},
+		{"env default dir", "", "1", "", filepath.Join("test_matrix_logs", "raw")},
+		{"env disabled", "", "false", "artifacts", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetConfig()
+			defer ResetConfig()
+			t.Setenv("DSGO_SAVE_RAW_RESPONSES", tt.save)
+			t.Setenv("DSGO_ARTIFACT_DIR", tt.artifact)
+			if tt.dir != "" {
+				Configure(WithRawResponseCapture(tt.dir))
+			}
+
+			if got := RawCaptureDir(); got != tt.want {
+				t.Errorf("RawCaptureDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureRawExchange(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+	t.Setenv("DSGO_SAVE_RAW_RESPONSES", "")
+
+	if path, err := CaptureRawExchange(RawExchange{Provider: "openai"}); path != "" || err != nil {
+		t.Fatalf("expected no capture when disabled, got (%q, %v)", path, err)
+	}
+
+	dir := t.TempDir()
+	Configure(WithRawResponseCapture(dir))
+
+	path, err := CaptureRawExchange(RawExchange{
+		Provider:   "openrouter",
+		Model:      "anthropic/claude-3.5-sonnet",
+		RequestID:  "req-123",
+		Request:    map[string]any{"model": "anthropic/claude-3.5-sonnet"},
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Test": []string{"1"}},
+		Body:       []byte("not json"),
+		Err:        errors.New("failed to decode response"),
+	})
+	if err != nil {
+		t.Fatalf("CaptureRawExchange() error = %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, "req-123") {
+		t.Errorf("expected file namespaced by request ID, got %s", path)
+	}
+	if !strings.HasSuffix(path, "_openrouter_anthropic_claude-3.5-sonnet.json") {
+		t.Errorf("unexpected file name %s", filepath.Base(path))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if record["request_id"] != "req-123" || record["response_text"] != "not json" || record["error"] != "failed to decode response" {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if request, _ := record["request"].(map[string]any); request["model"] != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("expected request body in record, got %v", record["request"])
+	}
+}
+
+func TestCaptureRawExchange_StaysInDir(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+	dir := filepath.Join(t.TempDir(), "raw")
+	Configure(WithRawResponseCapture(dir))
+
+	for _, requestID := range []string{"..", ".", "../escape", `..\escape`} {
+		path, err := CaptureRawExchange(RawExchange{Provider: "openai", Model: "gpt-4o", RequestID: requestID})
+		if err != nil {
+			t.Fatalf("CaptureRawExchange(%q) error = %v", requestID, err)
+		}
+		if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.Dir(rel) == "." {
+			t.Errorf("request ID %q wrote %s, want a subdirectory of %s", requestID, path, dir)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// thinkBlockPattern matches the <think>/<thinking> blocks reasoning models emit inline
+var thinkBlockPattern = regexp.MustCompile(`(?is)<(think|thinking)>(.*?)</(?:think|thinking)>`)
+
+// SplitReasoning separates inline reasoning from the answer in content
+// It extracts <think>...</think> (or <thinking>) blocks, and the text before a
+// dangling </think> from models that omit the opening tag. An unclosed <think>
+// means the output was cut off mid-thought, so everything after it is reasoning.
+func SplitReasoning(content string) (reasoning, answer string) {
+	var thoughts []string
+	answer = thinkBlockPattern.ReplaceAllStringFunc(content, func(block string) string {
+		thoughts = append(thoughts, strings.TrimSpace(thinkBlockPattern.FindStringSubmatch(block)[2]))
+		return ""
+	})
+
+	lower := strings.ToLower(answer)
+	if end := strings.Index(lower, "</think>"); end >= 0 {
+		thoughts = append(thoughts, strings.TrimSpace(answer[:end]))
+		answer = answer[end+len("</think>"):]
+	} else if start := strings.Index(lower, "<think>"); start >= 0 {
+		thoughts = append(thoughts, strings.TrimSpace(answer[start+len("<think>"):]))
+		answer = answer[:start]
+	}
+
+	if len(thoughts) == 0 {
+		return "", content
+	}
+	return strings.Join(thoughts, "\n\n"), strings.TrimSpace(answer)
+}
+
+// ResultReasoning returns the reasoning of a generation and its content without inline reasoning
+// Reasoning returned separately by the provider takes precedence over <think> blocks.
+func ResultReasoning(result *GenerateResult) (reasoning, content string) {
+	reasoning, content = SplitReasoning(result.Content)
+	if result.Reasoning != "" {
+		reasoning = result.Reasoning
+	}
+	return reasoning, content
+}
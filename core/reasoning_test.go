@@ -0,0 +1,61 @@
+package core
+
+import "testing"
+
+func TestSplitReasoning(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantReasoning string
+		wantAnswer    string
+	}{
+		{"no reasoning", `{"answer": "42"}`, "", `{"answer": "42"}`},
+		{"think block", "<think>\nThe user wants {a number}.\n</think>\n{\"answer\": \"42\"}", "The user wants {a number}.", `{"answer": "42"}`},
+		{"thinking block", "<thinking>hmm</thinking>[[ ## answer ## ]]\n42", "hmm", "[[ ## answer ## ]]\n42"},
+		{"multiple blocks", "<think>one</think>A<think>two</think>B", "one\n\ntwo", "AB"},
+		{"missing opening tag", "first I consider {x}</think>\n{\"answer\": \"42\"}", "first I consider {x}", `{"answer": "42"}`},
+		{"unclosed block", "<think>still thinking about {", "still thinking about {", ""},
+		{"case insensitive", "<THINK>loud</THINK>ok", "loud", "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasoning, answer := SplitReasoning(tt.content)
+			if reasoning != tt.wantReasoning {
+				t.Errorf("reasoning = %q, want %q", reasoning, tt.wantReasoning)
+			}
+			if answer != tt.wantAnswer {
+				t.Errorf("answer = %q, want %q", answer, tt.wantAnswer)
+			}
+		})
+	}
+}
+
+func TestResultReasoning_PrefersProviderReasoning(t *testing.T) {
+	reasoning, content := ResultReasoning(&GenerateResult{Reasoning: "channel", Content: "<think>inline</think>done"})
+	if reasoning != "channel" || content != "done" {
+		t.Errorf("ResultReasoning() = %q, %q; want channel, done", reasoning, content)
+	}
+}
+
+func TestAdapters_Parse_StripThinking(t *testing.T) {
+	sig := NewSignature("Test").AddOutput("answer", FieldTypeString, "")
+
+	tests := []struct {
+		adapter Adapter
+		content string
+	}{
+		{NewJSONAdapter(), "<think>Maybe {\"answer\": \"wrong\"}? No.</think>\n{\"answer\": \"right\"}"},
+		{NewChatAdapter(), "<think>Draft:\n[[ ## answer ## ]]\nwrong</think>\n[[ ## answer ## ]]\nright"},
+	}
+
+	for _, tt := range tests {
+		outputs, err := tt.adapter.Parse(sig, tt.content)
+		if err != nil {
+			t.Fatalf("%T.Parse() error = %v", tt.adapter, err)
+		}
+		if outputs["answer"] != "right" {
+			t.Errorf("%T answer = %v, want right", tt.adapter, outputs["answer"])
+		}
+	}
+}
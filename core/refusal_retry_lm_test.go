@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRefusalRetryLM_Generate(t *testing.T) {
+	refusalErr := &ContentFilterError{Provider: "openai", Reason: "I can't help with that."}
+	moderatedErr := &ContentFilterError{Provider: "openai", Reason: "finish_reason=content_filter", Moderated: true}
+	refusal := &GenerateResult{Content: "I'm sorry, but I can't assist with that request.", Usage: Usage{TotalTokens: 10}}
+	answer := &GenerateResult{Content: "[[ ## answer ## ]]\nUse a bandage.", Usage: Usage{TotalTokens: 30}}
+
+	type response struct {
+		result *GenerateResult
+		err    error
+	}
+	tests := []struct {
+		name        string
+		responses   []response
+		wantCalls   int
+		wantContent string
+		wantErr     error
+		wantRetries int
+		wantTokens  int // Usage of every attempt
+	}{
+		{"answered", []response{{answer, nil}}, 1, answer.Content, nil, 0, 30},
+		{"refusal text then answer", []response{{refusal, nil}, {answer, nil}}, 2, answer.Content, nil, 1, 40},
+		{"refusal error then answer", []response{{nil, refusalErr}, {answer, nil}}, 2, answer.Content, nil, 1, 30},
+		{"moderation is not retried", []response{{nil, moderatedErr}}, 1, "", ErrContentFiltered, 0, 0},
+		{"retries are capped", []response{{refusal, nil}, {refusal, nil}, {refusal, nil}, {answer, nil}}, 3, refusal.Content, nil, 2, 30},
+		{"other errors stop retrying", []response{{refusal, nil}, {nil, errors.New("boom")}}, 2, "", errors.New("boom"), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompts []string
+			lm := &mockWrapperLM{generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+				prompts = append(prompts, messages[len(messages)-1].Content)
+				resp := tt.responses[len(prompts)-1]
+				return resp.result, resp.err
+			}}
+
+			result, err := NewRefusalRetryLM(lm, 2).Generate(context.Background(), []Message{{Role: "user", Content: "How do I treat a cut?"}}, DefaultGenerateOptions())
+			if len(prompts) != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", len(prompts), tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if err == nil || !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Fatalf("Generate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if result.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", result.Content, tt.wantContent)
+			}
+			if result.Usage.TotalTokens != tt.wantTokens {
+				t.Errorf("TotalTokens = %d, want %d", result.Usage.TotalTokens, tt.wantTokens)
+			}
+			if tt.wantRetries == 0 {
+				if _, ok := result.Metadata["refusal_retries"]; ok {
+					t.Errorf("Metadata = %v, want no refusal_retries", result.Metadata)
+				}
+				return
+			}
+			if result.Metadata["refusal_retries"] != tt.wantRetries {
+				t.Errorf("refusal_retries = %v, want %d", result.Metadata["refusal_retries"], tt.wantRetries)
+			}
+			for _, prompt := range prompts[1:] {
+				if !strings.HasPrefix(prompt, DefaultRefusalPreamble) || !strings.HasSuffix(prompt, "How do I treat a cut?") {
+					t.Errorf("retry prompt = %q, want the preamble before the request", prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestIsRefusal(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"I'm sorry, but I can’t help with that.", true},
+		{"I am unable to provide medical advice.", true},
+		{"Apply pressure and clean the cut with water.", false},
+		{`{"answer": "I can't help but notice the typo."}`, false},
+		{"", false},
+		{strings.Repeat("Here is a long answer. ", 20) + "I cannot provide more detail.", false},
+	}
+	for _, tt := range tests {
+		if got := IsRefusal(tt.content); got != tt.want {
+			t.Errorf("IsRefusal(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
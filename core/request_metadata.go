@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestHeadersKey is the context key for per-call request headers
+type requestHeadersKey struct{}
+
+// requestMetadataKey is the context key for per-call request metadata
+type requestMetadataKey struct{}
+
+// WithCallRequestHeaders returns ctx carrying headers that providers add to the HTTP
+// requests of LM calls made under ctx, e.g. a trace ID for a downstream gateway.
+// They merge with WithRequestHeaders and headers already on ctx, the new values taking
+// precedence. Authorization cannot be set this way.
+func WithCallRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, requestHeadersKey{}, mergeStringMaps(contextStringMap(ctx, requestHeadersKey{}), headers))
+}
+
+// WithCallRequestMetadata returns ctx carrying metadata sent with the LM calls made
+// under ctx to providers that accept it, merged like WithCallRequestHeaders
+func WithCallRequestMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, mergeStringMaps(contextStringMap(ctx, requestMetadataKey{}), metadata))
+}
+
+// RequestHeaders returns the headers for a provider request made under ctx: the
+// WithRequestHeaders setting overlaid with WithCallRequestHeaders, without Authorization
+// (nil when there are none)
+func RequestHeaders(ctx context.Context) map[string]string {
+	globalSettings.mu.RLock()
+	headers := mergeStringMaps(globalSettings.RequestHeaders, contextStringMap(ctx, requestHeadersKey{}))
+	globalSettings.mu.RUnlock()
+
+	for name := range headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			delete(headers, name)
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// RequestMetadata returns the metadata for a provider request made under ctx: the
+// WithRequestMetadata setting overlaid with WithCallRequestMetadata (nil when there is none)
+func RequestMetadata(ctx context.Context) map[string]string {
+	globalSettings.mu.RLock()
+	defer globalSettings.mu.RUnlock()
+	metadata := mergeStringMaps(globalSettings.RequestMetadata, contextStringMap(ctx, requestMetadataKey{}))
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// SetRequestHeaders sets the RequestHeaders for ctx on req
+// Providers call it after setting their own headers, so configured headers win over
+// defaults such as OpenRouter's X-Title while authentication stays untouched.
+func SetRequestHeaders(ctx context.Context, req *http.Request) {
+	for name, value := range RequestHeaders(ctx) {
+		req.Header.Set(name, value)
+	}
+}
+
+// contextStringMap returns the string map stored under key in ctx, or nil
+func contextStringMap(ctx context.Context, key any) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	values, _ := ctx.Value(key).(map[string]string)
+	return values
+}
+
+// mergeStringMaps returns a new map with the entries of base overlaid with overrides
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// copyStringMap returns a copy of m, or nil when m is nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	return mergeStringMaps(nil, m)
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestHeaders(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	if got := RequestHeaders(context.Background()); got != nil {
+		t.Errorf("RequestHeaders() = %v, want nil without configuration", got)
+	}
+
+	Configure(WithRequestHeaders(map[string]string{"X-Title": "app", "X-Trace-Id": "global", "Authorization": "Bearer other"}))
+	ctx := WithCallRequestHeaders(context.Background(), map[string]string{"X-Trace-Id": "outer"})
+	ctx = WithCallRequestHeaders(ctx, map[string]string{"authorization": "Bearer nested", "X-Span": "inner"})
+
+	got := RequestHeaders(ctx)
+	want := map[string]string{"X-Title": "app", "X-Trace-Id": "outer", "X-Span": "inner"}
+	if len(got) != len(want) {
+		t.Fatalf("RequestHeaders() = %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("RequestHeaders()[%q] = %q, want %q", name, got[name], value)
+		}
+	}
+}
+
+func TestRequestMetadata(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	metadata := map[string]string{"team": "search"}
+	Configure(WithRequestMetadata(metadata))
+	metadata["team"] = "changed after Configure"
+
+	got := RequestMetadata(WithCallRequestMetadata(context.Background(), map[string]string{"user": "u-1"}))
+	if len(got) != 2 || got["team"] != "search" || got["user"] != "u-1" {
+		t.Errorf("RequestMetadata() = %v, want the configured and per-call metadata", got)
+	}
+	if settings := GetSettings(); settings.RequestMetadata["team"] != "search" {
+		t.Errorf("GetSettings().RequestMetadata = %v", settings.RequestMetadata)
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"io"
+	"net/http"
+)
+
+// limitResponses returns a copy of client whose response bodies fail with a
+// *ResponseTooLargeError once they exceed limit bytes
+func limitResponses(client *http.Client, provider string, limit int64) *http.Client {
+	limited := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited.Transport = &limitedTransport{base: base, provider: provider, limit: limit}
+	return &limited
+}
+
+// limitedTransport wraps response bodies in a limitedBody
+// The limit is enforced while reading rather than in RoundTrip, so an oversized response
+// is not mistaken for a network error and retried.
+type limitedTransport struct {
+	base     http.RoundTripper
+	provider string
+	limit    int64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = &limitedBody{
+		body:      resp.Body,
+		remaining: t.limit,
+		err:       &ResponseTooLargeError{Provider: t.provider, Limit: t.limit},
+		// A declared length over the limit fails on the first read instead of after limit bytes
+		exceeded: resp.ContentLength > t.limit,
+	}
+	return resp, nil
+}
+
+// limitedBody reads at most remaining bytes of body, then fails with err
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	err       error
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, b.err
+	}
+	if b.remaining <= 0 {
+		// Probe for one more byte: a body ending exactly at the limit is fine
+		var probe [1]byte
+		n, err := b.body.Read(probe[:])
+		if n > 0 {
+			b.exceeded = true
+			return 0, b.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
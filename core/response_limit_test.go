@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	body := strings.Repeat("x", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			// Chunked, without a Content-Length, like a streamed response
+			for i := 0; i < 10; i++ {
+				_, _ = w.Write([]byte(body[:10]))
+				w.(http.Flusher).Flush()
+			}
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	get := func(limit int64, path string) ([]byte, error) {
+		Configure(WithMaxResponseBytes(limit))
+		resp, err := HTTPClientFor("openai").Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return io.ReadAll(resp.Body)
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		path    string
+		wantErr bool
+	}{
+		{"unlimited", 0, "/", false},
+		{"exactly at the limit", 100, "/", false},
+		{"declared length over the limit", 99, "/", true},
+		{"streamed body over the limit", 55, "/stream", true},
+		{"streamed body within the limit", 100, "/stream", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := get(tt.limit, tt.path)
+			if !tt.wantErr {
+				if err != nil || string(data) != body {
+					t.Errorf("ReadAll() = %d bytes, %v, want the whole body", len(data), err)
+				}
+				return
+			}
+			var tooLarge *ResponseTooLargeError
+			if !errors.Is(err, ErrResponseTooLarge) || !errors.As(err, &tooLarge) || tooLarge.Provider != "openai" {
+				t.Errorf("ReadAll() error = %v, want a ResponseTooLargeError", err)
+			}
+			if int64(len(data)) > tt.limit {
+				t.Errorf("read %d bytes, want at most the limit %d", len(data), tt.limit)
+			}
+		})
+	}
+
+	Configure(WithMaxResponseBytes(10))
+	custom := &http.Client{}
+	Configure(WithHTTPClient(custom))
+	if client := HTTPClientFor("openai"); client == custom || client.Transport == nil || custom.Transport != nil {
+		t.Error("HTTPClientFor() should limit a copy of the configured client")
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -18,26 +21,107 @@ type Settings struct {
 	// DefaultModel is the default model identifier (e.g., "gpt-4", "meta-llama/llama-3.3-70b-instruct").
 	DefaultModel string
 
-	// DefaultTimeout is the default timeout for LM calls.
+	// DefaultTimeout is the default timeout for LM calls (a per-Generate context deadline; 0 = none).
 	DefaultTimeout time.Duration
 
 	// APIKey stores provider-specific API keys.
 	APIKey map[string]string
 
+	// APIKeyPools rotates requests across several API keys per provider (see WithAPIKeys).
+	APIKeyPools map[string]*KeyPool
+
 	// MaxRetries sets the default number of retries for failed LM calls.
 	MaxRetries int
 
+	// Backoff sets the delays between those retries (zero fields = defaults, see BackoffConfig).
+	Backoff BackoffConfig
+
 	// EnableTracing enables detailed tracing and diagnostics.
 	EnableTracing bool
 
 	// Collector is the default collector for LM observability.
 	Collector Collector
 
+	// ShutdownContext flushes and closes Collector once it is done (nil = never).
+	ShutdownContext context.Context
+
 	// DefaultCache is the global cache instance (auto-wired to LM instances).
 	DefaultCache Cache
 
 	// CacheTTL is the cache time-to-live (0 = no expiry).
 	CacheTTL time.Duration
+
+	// RateLimit is the per-provider request rate in requests per second (0 = unlimited).
+	RateLimit float64
+
+	// RateLimitBurst is the maximum number of requests allowed in a burst.
+	RateLimitBurst int
+
+	// AdaptiveRateLimit lowers the rate on 429 responses and recovers it on success.
+	AdaptiveRateLimit bool
+
+	// HedgeAfter issues a second, identical Generate request when the first is still running after this delay (0 = no hedging).
+	HedgeAfter time.Duration
+
+	// DeduplicateRequests makes concurrent, identical Generate calls share one in-flight request.
+	DeduplicateRequests bool
+
+	// RefusalRetries retries Generate calls the model refused with a clarifying preamble (0 = off).
+	RefusalRetries int
+
+	// ContextWindow overrides the model context length in tokens (0 = use model registry).
+	ContextWindow int
+
+	// TruncationPolicy controls how prompts exceeding the context window are handled (0 = drop demos only).
+	TruncationPolicy TruncationPolicy
+
+	// DemoDropOrder controls which demos are dropped first to fit the context window.
+	DemoDropOrder DemoDropOrder
+
+	// Middleware wraps every Generate and Stream call of LMs created by NewLM (first is outermost).
+	Middleware []Middleware
+
+	// Pricing overrides per-model prices (USD per million tokens) used to compute Usage.Cost.
+	Pricing map[string]ModelPricing
+
+	// Region is the cloud region for region-scoped providers such as bedrock (empty = provider default, e.g. AWS_REGION).
+	Region string
+
+	// TraceOnErrorDir is where modules write a JSON trace of failed Forward calls (empty = disabled).
+	TraceOnErrorDir string
+
+	// RawResponseDir is where providers save every raw request/response exchange (empty = DSGO_SAVE_RAW_RESPONSES or disabled).
+	RawResponseDir string
+
+	// DryRun makes modules (and LMs created by NewLM) return the assembled prompt instead of calling the provider.
+	DryRun bool
+
+	// DryRunOutput receives the prompt of every dry-run call (nil = not printed).
+	DryRunOutput io.Writer
+
+	// HTTPClient is used by providers for their HTTP requests (nil = a default client).
+	HTTPClient *http.Client
+
+	// ProviderHTTPClients overrides HTTPClient per provider name (e.g. "openai").
+	ProviderHTTPClients map[string]*http.Client
+
+	// MaxResponseBytes caps the body size of every provider HTTP response (0 = unlimited).
+	MaxResponseBytes int64
+
+	// RequestHeaders are added to every provider HTTP request, e.g. X-Title for OpenRouter.
+	RequestHeaders map[string]string
+
+	// RequestMetadata is sent with every request to providers that accept metadata (OpenAI).
+	RequestMetadata map[string]string
+
+	// BaseURL is the API base URL of the default provider, e.g. an OpenAI-compatible gateway (empty = <PROVIDER>_BASE_URL or the provider default).
+	BaseURL string
+
+	// ProviderBaseURLs overrides BaseURL per provider name (e.g. "openai").
+	ProviderBaseURLs map[string]string
+
+	// InstructionPrefix is prepended by adapters to every system message, ahead of the signature instruction.
+	InstructionPrefix string
 }
 
 // globalSettings is the singleton instance of Settings.
@@ -59,17 +143,80 @@ func GetSettings() Settings {
 		apiKeyCopy[k] = v
 	}
 
+	var keyPoolsCopy map[string]*KeyPool
+	if globalSettings.APIKeyPools != nil {
+		keyPoolsCopy = make(map[string]*KeyPool, len(globalSettings.APIKeyPools))
+		for k, v := range globalSettings.APIKeyPools {
+			keyPoolsCopy[k] = v
+		}
+	}
+
+	middlewareCopy := append([]Middleware(nil), globalSettings.Middleware...)
+
+	var pricingCopy map[string]ModelPricing
+	if globalSettings.Pricing != nil {
+		pricingCopy = make(map[string]ModelPricing, len(globalSettings.Pricing))
+		for k, v := range globalSettings.Pricing {
+			pricingCopy[k] = v
+		}
+	}
+
+	var httpClientsCopy map[string]*http.Client
+	if globalSettings.ProviderHTTPClients != nil {
+		httpClientsCopy = make(map[string]*http.Client, len(globalSettings.ProviderHTTPClients))
+		for k, v := range globalSettings.ProviderHTTPClients {
+			httpClientsCopy[k] = v
+		}
+	}
+
+	var baseURLsCopy map[string]string
+	if globalSettings.ProviderBaseURLs != nil {
+		baseURLsCopy = make(map[string]string, len(globalSettings.ProviderBaseURLs))
+		for k, v := range globalSettings.ProviderBaseURLs {
+			baseURLsCopy[k] = v
+		}
+	}
+
 	return Settings{
 		DefaultLM:       globalSettings.DefaultLM,
 		DefaultProvider: globalSettings.DefaultProvider,
 		DefaultModel:    globalSettings.DefaultModel,
 		DefaultTimeout:  globalSettings.DefaultTimeout,
 		APIKey:          apiKeyCopy,
+		APIKeyPools:     keyPoolsCopy,
 		MaxRetries:      globalSettings.MaxRetries,
+		Backoff:         globalSettings.Backoff,
 		EnableTracing:   globalSettings.EnableTracing,
 		Collector:       globalSettings.Collector,
+		ShutdownContext: globalSettings.ShutdownContext,
 		DefaultCache:    globalSettings.DefaultCache,
 		CacheTTL:        globalSettings.CacheTTL,
+
+		RateLimit:         globalSettings.RateLimit,
+		RateLimitBurst:    globalSettings.RateLimitBurst,
+		AdaptiveRateLimit: globalSettings.AdaptiveRateLimit,
+		HedgeAfter:        globalSettings.HedgeAfter,
+		ContextWindow:     globalSettings.ContextWindow,
+		TruncationPolicy:  globalSettings.TruncationPolicy,
+		Middleware:        middlewareCopy,
+		Pricing:           pricingCopy,
+		Region:            globalSettings.Region,
+		TraceOnErrorDir:   globalSettings.TraceOnErrorDir,
+		RawResponseDir:    globalSettings.RawResponseDir,
+		DryRun:            globalSettings.DryRun,
+		DryRunOutput:      globalSettings.DryRunOutput,
+
+		HTTPClient:          globalSettings.HTTPClient,
+		ProviderHTTPClients: httpClientsCopy,
+		BaseURL:             globalSettings.BaseURL,
+		ProviderBaseURLs:    baseURLsCopy,
+		DeduplicateRequests: globalSettings.DeduplicateRequests,
+		RefusalRetries:      globalSettings.RefusalRetries,
+		InstructionPrefix:   globalSettings.InstructionPrefix,
+		DemoDropOrder:       globalSettings.DemoDropOrder,
+		MaxResponseBytes:    globalSettings.MaxResponseBytes,
+		RequestHeaders:      copyStringMap(globalSettings.RequestHeaders),
+		RequestMetadata:     copyStringMap(globalSettings.RequestMetadata),
 	}
 }
 
@@ -149,9 +296,36 @@ func (s *Settings) Reset() {
 	s.DefaultModel = ""
 	s.DefaultTimeout = 30 * time.Second
 	s.APIKey = make(map[string]string)
+	s.APIKeyPools = nil
 	s.MaxRetries = 3
+	s.Backoff = BackoffConfig{}
 	s.EnableTracing = false
 	s.Collector = nil
+	s.ShutdownContext = nil
 	s.DefaultCache = nil
 	s.CacheTTL = 0
+	s.RateLimit = 0
+	s.RateLimitBurst = 0
+	s.AdaptiveRateLimit = false
+	s.HedgeAfter = 0
+	s.DeduplicateRequests = false
+	s.RefusalRetries = 0
+	s.ContextWindow = 0
+	s.TruncationPolicy = 0
+	s.DemoDropOrder = DropLastDemoFirst
+	s.Middleware = nil
+	s.Pricing = nil
+	s.Region = ""
+	s.TraceOnErrorDir = ""
+	s.RawResponseDir = ""
+	s.DryRun = false
+	s.DryRunOutput = nil
+	s.HTTPClient = nil
+	s.ProviderHTTPClients = nil
+	s.MaxResponseBytes = 0
+	s.RequestHeaders = nil
+	s.RequestMetadata = nil
+	s.BaseURL = ""
+	s.ProviderBaseURLs = nil
+	s.InstructionPrefix = ""
 }
@@ -20,6 +20,20 @@ const (
 	FieldTypeDatetime FieldType = "datetime"
 )
 
+// EnumMatching controls how class output values are matched to their classes
+type EnumMatching int
+
+const (
+	// EnumFuzzy (the default) matches like EnumNormalized, then finds a class as a whole
+	// word in the value ("The sentiment is positive"); values naming several classes fail
+	EnumFuzzy EnumMatching = iota
+	// EnumNormalized matches case-insensitively after stripping quotes, brackets, trailing
+	// punctuation and prefixes such as "answer:" ("Positive.", "`positive`")
+	EnumNormalized
+	// EnumExact accepts only a class (or alias) exactly as declared, ignoring surrounding whitespace
+	EnumExact
+)
+
 // Field represents a signature field (input or output)
 type Field struct {
 	Name         string
@@ -35,6 +49,7 @@ type Signature struct {
 	Description  string
 	InputFields  []Field
 	OutputFields []Field
+	EnumMatching EnumMatching // How class output values are matched to their classes
 }
 
 // NewSignature creates a new signature with description
@@ -102,25 +117,190 @@ func (s *Signature) AddClassOutput(name string, classes []string, description st
 	return s
 }
 
+// WithEnumMatching sets how class output values are matched to their classes;
+// a value that matches no class fails validation
+func (s *Signature) WithEnumMatching(mode EnumMatching) *Signature {
+	s.EnumMatching = mode
+	return s
+}
+
+// Clone creates a deep copy of the signature
+func (s *Signature) Clone() *Signature {
+	return &Signature{
+		Description:  s.Description,
+		InputFields:  cloneFields(s.InputFields),
+		OutputFields: cloneFields(s.OutputFields),
+		EnumMatching: s.EnumMatching,
+	}
+}
+
+// Extend appends the input and output fields of other, in other's order
+// It returns an error and leaves s unchanged if a field name already exists in the
+// same section; use ExtendOverride to replace such fields instead.
+func (s *Signature) Extend(other *Signature) (*Signature, error) {
+	for _, field := range other.InputFields {
+		if indexOfField(s.InputFields, field.Name) >= 0 {
+			return s, fmt.Errorf("cannot extend signature: input field %q already exists", field.Name)
+		}
+	}
+	for _, field := range other.OutputFields {
+		if indexOfField(s.OutputFields, field.Name) >= 0 {
+			return s, fmt.Errorf("cannot extend signature: output field %q already exists", field.Name)
+		}
+	}
+	return s.ExtendOverride(other), nil
+}
+
+// ExtendOverride merges the fields of other into s
+// A field whose name already exists replaces the existing one in its original position;
+// new fields are appended in other's order.
+func (s *Signature) ExtendOverride(other *Signature) *Signature {
+	s.InputFields = mergeFields(s.InputFields, other.InputFields)
+	s.OutputFields = mergeFields(s.OutputFields, other.OutputFields)
+	return s
+}
+
+// WithoutOutput returns a copy of the signature without the named output field
+func (s *Signature) WithoutOutput(name string) *Signature {
+	derived := s.Clone()
+	if i := indexOfField(derived.OutputFields, name); i >= 0 {
+		derived.OutputFields = append(derived.OutputFields[:i], derived.OutputFields[i+1:]...)
+	}
+	return derived
+}
+
+// OnlyOutputs returns a copy of the signature keeping just the named output fields
+// Fields keep the signature's order; names that do not exist are ignored.
+func (s *Signature) OnlyOutputs(names ...string) *Signature {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	derived := s.Clone()
+	outputs := make([]Field, 0, len(names))
+	for _, field := range derived.OutputFields {
+		if keep[field.Name] {
+			outputs = append(outputs, field)
+		}
+	}
+	derived.OutputFields = outputs
+	return derived
+}
+
+// mergeFields returns base with extra merged in (same-named fields replaced in place)
+func mergeFields(base, extra []Field) []Field {
+	merged := cloneFields(base)
+	for _, field := range cloneFields(extra) {
+		if i := indexOfField(merged, field.Name); i >= 0 {
+			merged[i] = field
+			continue
+		}
+		merged = append(merged, field)
+	}
+	return merged
+}
+
+// cloneFields deep-copies fields so derived signatures do not share class slices or alias maps
+func cloneFields(fields []Field) []Field {
+	cloned := make([]Field, len(fields))
+	for i, field := range fields {
+		if field.Classes != nil {
+			field.Classes = append([]string(nil), field.Classes...)
+		}
+		if field.ClassAliases != nil {
+			aliases := make(map[string]string, len(field.ClassAliases))
+			for k, v := range field.ClassAliases {
+				aliases[k] = v
+			}
+			field.ClassAliases = aliases
+		}
+		cloned[i] = field
+	}
+	return cloned
+}
+
+// indexOfField returns the position of the named field, or -1
+func indexOfField(fields []Field, name string) int {
+	for i := range fields {
+		if fields[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 // ValidateInputs validates that all required inputs are present and of correct type
+// A missing input is reported together with the closest unknown input name, so a
+// misspelled key such as "questoin" points at the field it was meant for.
 func (s *Signature) ValidateInputs(inputs map[string]any) error {
 	for _, field := range s.InputFields {
 		value, exists := inputs[field.Name]
 		if !exists && !field.Optional {
-			return fmt.Errorf("missing required input field: %s", field.Name)
+			if guess := s.closestUnknownInput(field.Name, inputs); guess != "" {
+				return fmt.Errorf("missing required input %q (got unknown input %q)", field.Name, guess)
+			}
+			return fmt.Errorf("missing required input %q", field.Name)
 		}
 		if !exists {
 			continue
 		}
 
-		// Basic type validation
-		if err := s.validateFieldType(field, value); err != nil {
-			return err
+		if value == nil {
+			if field.Optional {
+				continue
+			}
+			return fmt.Errorf("input %q cannot be nil", field.Name)
+		}
+		if expected := fieldTypeMismatch(field, value); expected != "" {
+			return fmt.Errorf("input %q expected %s, got %T", field.Name, expected, value)
 		}
 	}
 	return nil
 }
 
+// closestUnknownInput returns the input key that is not a signature field and is
+// nearest to name by edit distance, or "" when no key is plausibly a misspelling
+func (s *Signature) closestUnknownInput(name string, inputs map[string]any) string {
+	best, bestDist := "", len(name)/2+1
+	for key := range inputs {
+		if indexOfField(s.InputFields, key) >= 0 {
+			continue
+		}
+		dist := editDistance(strings.ToLower(key), strings.ToLower(name))
+		if dist < bestDist || (dist == bestDist && best != "" && key < best) {
+			best, bestDist = key, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein (optimal string alignment) distance
+// between a and b, counting an adjacent transposition as one edit
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
+
 // GetOutputField returns the output field with the given name, or nil if not found
 func (s *Signature) GetOutputField(name string) *Field {
 	for i := range s.OutputFields {
@@ -147,6 +327,11 @@ func (d *ValidationDiagnostics) HasErrors() bool {
 func (s *Signature) ValidateOutputs(outputs map[string]any) error {
 	for _, field := range s.OutputFields {
 		value, exists := outputs[field.Name]
+		if exists && field.Optional && isBlankOutput(value) {
+			// A null or empty optional output is treated as omitted
+			delete(outputs, field.Name)
+			exists = false
+		}
 		if !exists && !field.Optional {
 			return fmt.Errorf("missing required output field: %s", field.Name)
 		}
@@ -157,18 +342,12 @@ func (s *Signature) ValidateOutputs(outputs map[string]any) error {
 		// Validate class types
 		if field.Type == FieldTypeClass && len(field.Classes) > 0 {
 			valueStr := fmt.Sprintf("%v", value)
-			// Normalize the value for comparison
-			normalized := normalizeClassValue(valueStr, field)
-			valid := false
-			for _, class := range field.Classes {
-				if strings.EqualFold(normalized, class) {
-					valid = true
-					// Update output with normalized value (use the class name from the list)
-					outputs[field.Name] = class
-					break
-				}
-			}
-			if !valid {
+			// Match the value to a class under the signature's EnumMatching mode
+			class, valid := matchClass(valueStr, field, s.EnumMatching)
+			if valid {
+				// Update output with normalized value (use the class name from the list)
+				outputs[field.Name] = class
+			} else {
 				return fmt.Errorf("field %s has invalid class value: %v (must be one of %v)", field.Name, valueStr, field.Classes)
 			}
 		}
@@ -181,6 +360,15 @@ func (s *Signature) ValidateOutputs(outputs map[string]any) error {
 	return nil
 }
 
+// isBlankOutput reports whether a parsed output value is null or an empty/whitespace string
+func isBlankOutput(value any) bool {
+	if value == nil {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && strings.TrimSpace(str) == ""
+}
+
 // ValidateOutputsPartial performs validation but allows missing fields and captures diagnostics.
 // Missing required fields are set to nil in the outputs map.
 func (s *Signature) ValidateOutputsPartial(outputs map[string]any) *ValidationDiagnostics {
@@ -192,6 +380,10 @@ func (s *Signature) ValidateOutputsPartial(outputs map[string]any) *ValidationDi
 
 	for _, field := range s.OutputFields {
 		value, exists := outputs[field.Name]
+		if exists && field.Optional && isBlankOutput(value) {
+			delete(outputs, field.Name)
+			continue
+		}
 		if !exists && !field.Optional {
 			diag.MissingFields = append(diag.MissingFields, field.Name)
 			outputs[field.Name] = nil // Set to nil for partial output
@@ -209,18 +401,12 @@ func (s *Signature) ValidateOutputsPartial(outputs map[string]any) *ValidationDi
 		// Validate class types
 		if field.Type == FieldTypeClass && len(field.Classes) > 0 {
 			valueStr := fmt.Sprintf("%v", value)
-			// Normalize the value for comparison
-			normalized := normalizeClassValue(valueStr, field)
-			valid := false
-			for _, class := range field.Classes {
-				if strings.EqualFold(normalized, class) {
-					valid = true
-					// Update output with normalized value (use the class name from the list)
-					outputs[field.Name] = class
-					break
-				}
-			}
-			if !valid {
+			// Match the value to a class under the signature's EnumMatching mode
+			class, valid := matchClass(valueStr, field, s.EnumMatching)
+			if valid {
+				// Update output with normalized value (use the class name from the list)
+				outputs[field.Name] = class
+			} else {
 				diag.ClassErrors[field.Name] = fmt.Errorf("invalid class value: %v (must be one of %v)", valueStr, field.Classes)
 			}
 		}
@@ -241,13 +427,30 @@ func (s *Signature) validateFieldType(field Field, value any) error {
 		}
 		return fmt.Errorf("field %s cannot be nil", field.Name)
 	}
+	if expected := fieldTypeMismatch(field, value); expected != "" {
+		return fmt.Errorf("field %s expected %s, got %T", field.Name, expected, value)
+	}
+	return nil
+}
 
+// fieldTypeMismatch returns a description of the type field expects when value is
+// not compatible with it, or "" when it is
+func fieldTypeMismatch(field Field, value any) string {
 	kind := reflect.TypeOf(value).Kind()
 
 	switch field.Type {
-	case FieldTypeString, FieldTypeClass, FieldTypeImage, FieldTypeDatetime:
+	case FieldTypeString, FieldTypeClass, FieldTypeDatetime:
 		if kind != reflect.String {
-			return fmt.Errorf("field %s expected string, got %T", field.Name, value)
+			return "string"
+		}
+
+	case FieldTypeImage:
+		// Accept URL strings, raw bytes, or ImageContent
+		switch value.(type) {
+		case string, []byte, ImageContent, *ImageContent:
+			// OK
+		default:
+			return "image ([]byte, URL string or ImageContent)"
 		}
 
 	case FieldTypeInt:
@@ -258,7 +461,7 @@ func (s *Signature) validateFieldType(field Field, value any) error {
 		case reflect.Float64:
 			// OK - adapters will coerce to int
 		default:
-			return fmt.Errorf("field %s expected int-like (int/int32/int64/float64), got %T", field.Name, value)
+			return "int"
 		}
 
 	case FieldTypeFloat:
@@ -269,12 +472,12 @@ func (s *Signature) validateFieldType(field Field, value any) error {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			// OK - can convert to float
 		default:
-			return fmt.Errorf("field %s expected float-like (float32/float64/int), got %T", field.Name, value)
+			return "float"
 		}
 
 	case FieldTypeBool:
 		if kind != reflect.Bool {
-			return fmt.Errorf("field %s expected bool, got %T", field.Name, value)
+			return "bool"
 		}
 
 	case FieldTypeJSON:
@@ -283,59 +486,109 @@ func (s *Signature) validateFieldType(field Field, value any) error {
 		case reflect.Map, reflect.Slice, reflect.String:
 			// OK
 		default:
-			return fmt.Errorf("field %s expected JSON (map/slice/string), got %T", field.Name, value)
+			return "JSON (map/slice/string)"
 		}
 	}
-	return nil
+	return ""
 }
 
-// normalizeClassValue normalizes a class value for comparison using case-insensitive matching and aliases
+// normalizeClassValue normalizes a class value with EnumFuzzy matching, returning value
+// unchanged when it matches no class
 func normalizeClassValue(value string, field Field) string {
-	v := strings.ToLower(strings.TrimSpace(value))
-
-	// Strip common decorations (parentheses, quotes, brackets)
-	v = strings.Trim(v, "()[]{}\"'`")
-	v = strings.TrimSpace(v)
+	if class, ok := matchClass(value, field, EnumFuzzy); ok {
+		return class
+	}
+	return value
+}
 
-	// Remove common prefixes that models might add
-	// e.g., "(one of: positive)" → "positive", "one: negative" → "negative"
-	prefixes := []string{"one of:", "one of", "one:", "one", "answer:", "result:"}
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(v, prefix) {
-			v = strings.TrimSpace(strings.TrimPrefix(v, prefix))
-			// Strip any remaining decorations after removing prefix
-			v = strings.Trim(v, "()[]{}\"'`:, ")
-			v = strings.TrimSpace(v)
-			break
+// matchClass returns the class of field that value names under mode, or false
+func matchClass(value string, field Field, mode EnumMatching) (string, bool) {
+	if mode == EnumExact {
+		v := strings.TrimSpace(value)
+		for _, class := range field.Classes {
+			if v == class {
+				return class, true
+			}
+		}
+		if class, ok := field.ClassAliases[v]; ok {
+			return class, true
 		}
+		return "", false
 	}
 
-	// Check if there's an exact case-insensitive match first
+	v := stripClassDecorations(value)
+
+	// Case-insensitive match against the classes, then the aliases
 	for _, class := range field.Classes {
 		if strings.EqualFold(v, class) {
-			return class
+			return class, true
+		}
+	}
+	for alias, class := range field.ClassAliases {
+		if strings.EqualFold(v, alias) {
+			return class, true
 		}
 	}
+	if mode != EnumFuzzy {
+		return "", false
+	}
 
-	// If no exact match, try word-boundary matching for each class
-	// This handles cases like "(positive)" or "answer: positive" but not "invalid" matching "a"
+	// Word-boundary search, so "the sentiment is positive" matches but "invalid" does not
+	// match "a". A class contained in a longer matching class ("positive" in "very
+	// positive") yields to it; any other pair of matches is ambiguous.
+	var matches []string
 	for _, class := range field.Classes {
-		lowerClass := strings.ToLower(class)
-		// Only match if class appears as a complete word (surrounded by non-letters)
-		if containsWord(v, lowerClass) {
-			return class
+		if containsWord(v, strings.ToLower(class)) {
+			matches = append(matches, class)
 		}
 	}
-
-	// Check aliases
-	if field.ClassAliases != nil {
-		if normalized, ok := field.ClassAliases[v]; ok {
-			return normalized
+	var best string
+	for _, class := range matches {
+		contained := false
+		for _, other := range matches {
+			if other != class && containsWord(strings.ToLower(other), strings.ToLower(class)) {
+				contained = true
+				break
+			}
+		}
+		if contained {
+			continue
 		}
+		if best != "" {
+			return "", false
+		}
+		best = class
 	}
+	return best, best != ""
+}
 
-	// Return original if no match found
-	return value
+// classDecorations are the characters models wrap class values in
+const classDecorations = "()[]{}\"'`"
+
+// stripClassDecorations lowercases value and strips decorations models add around a
+// class: quotes, brackets, trailing punctuation and prefixes such as "answer:"
+func stripClassDecorations(value string) string {
+	v := strings.ToLower(strings.TrimSpace(value))
+
+	// Strip common decorations (parentheses, quotes, brackets) and trailing punctuation
+	v = strings.TrimLeft(v, classDecorations)
+	v = strings.TrimRight(v, classDecorations+".!?,;:")
+	v = strings.TrimSpace(v)
+
+	// Remove common prefixes that models might add
+	// e.g., "(one of: positive)" → "positive", "one: negative" → "negative"
+	prefixes := []string{"one of:", "one of", "one:", "one", "answer:", "result:"}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(v, prefix) {
+			v = strings.TrimSpace(strings.TrimPrefix(v, prefix))
+			// Strip any remaining decorations after removing prefix
+			v = strings.TrimLeft(v, classDecorations+":, ")
+			v = strings.TrimRight(v, classDecorations+".!?,;: ")
+			v = strings.TrimSpace(v)
+			break
+		}
+	}
+	return v
 }
 
 // containsWord checks if word appears as a complete word in s (not just a substring)
@@ -344,17 +597,25 @@ func normalizeClassValue(value string, field Field) string {
 //	containsWord("(positive)", "positive") = true
 //	containsWord("invalid", "a") = false (a is not a complete word)
 func containsWord(s, word string) bool {
-	idx := strings.Index(s, word)
-	if idx == -1 {
+	if word == "" {
 		return false
 	}
-
-	// Check if word has proper boundaries (start/end or non-letter characters)
-	hasStartBoundary := idx == 0 || !isLetter(rune(s[idx-1]))
-	endIdx := idx + len(word)
-	hasEndBoundary := endIdx == len(s) || !isLetter(rune(s[endIdx]))
-
-	return hasStartBoundary && hasEndBoundary
+	for offset := 0; ; {
+		idx := strings.Index(s[offset:], word)
+		if idx == -1 {
+			return false
+		}
+		idx += offset
+
+		// Check if word has proper boundaries (start/end or non-letter characters)
+		hasStartBoundary := idx == 0 || !isLetter(rune(s[idx-1]))
+		endIdx := idx + len(word)
+		hasEndBoundary := endIdx == len(s) || !isLetter(rune(s[endIdx]))
+		if hasStartBoundary && hasEndBoundary {
+			return true
+		}
+		offset = idx + 1
+	}
 }
 
 // isLetter checks if a rune is a letter (for word boundary detection)
@@ -363,7 +624,9 @@ func isLetter(r rune) bool {
 }
 
 // SignatureToJSONSchema generates a JSON schema from the signature's output fields
-// This enables structured output mode for OpenAI/OpenRouter compatible LMs
+// This enables structured output mode for OpenAI/OpenRouter compatible LMs.
+// Field descriptions become property "description"s and class fields an "enum" of their
+// canonical classes, so models that follow the schema see the same guidance as the prompt.
 func (s *Signature) SignatureToJSONSchema() map[string]any {
 	properties := make(map[string]any)
 	required := []string{}
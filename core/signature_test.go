@@ -57,6 +57,48 @@ func TestSignature_ValidateInputs(t *testing.T) {
 	}
 }
 
+func TestSignature_ValidateInputs_Messages(t *testing.T) {
+	sig := NewSignature("Test").
+		AddInput("question", FieldTypeString, "Question").
+		AddInput("depth_level", FieldTypeInt, "Depth")
+
+	tests := []struct {
+		name   string
+		inputs map[string]any
+		want   string
+	}{
+		{
+			name:   "missing input",
+			inputs: map[string]any{"depth_level": 2},
+			want:   `missing required input "question"`,
+		},
+		{
+			name:   "misspelled input",
+			inputs: map[string]any{"questoin": "why?", "depth_level": 2},
+			want:   `missing required input "question" (got unknown input "questoin")`,
+		},
+		{
+			name:   "wrong type",
+			inputs: map[string]any{"question": "why?", "depth_level": "deep"},
+			want:   `input "depth_level" expected int, got string`,
+		},
+		{
+			name:   "nil value",
+			inputs: map[string]any{"question": nil, "depth_level": 2},
+			want:   `input "question" cannot be nil`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sig.ValidateInputs(tt.inputs)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ValidateInputs() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestSignature_ValidateOutputs(t *testing.T) {
 	sig := NewSignature("Test").
 		AddOutput("required", FieldTypeString, "Required field").
@@ -635,6 +677,57 @@ func TestField_ClassAliases(t *testing.T) {
 	}
 }
 
+func TestSignature_WithEnumMatching(t *testing.T) {
+	newSig := func(mode EnumMatching) *Signature {
+		return NewSignature("Classify").
+			AddClassOutput("sentiment", []string{"positive", "negative", "neutral", "very positive"}, "").
+			WithEnumMatching(mode)
+	}
+
+	tests := []struct {
+		name  string
+		mode  EnumMatching
+		input string
+		want  string // "" = validation fails
+	}{
+		{"exact match", EnumExact, "positive", "positive"},
+		{"exact rejects case", EnumExact, "Positive", ""},
+		{"exact rejects punctuation", EnumExact, "positive.", ""},
+		{"normalized case", EnumNormalized, "Positive", "positive"},
+		{"normalized punctuation", EnumNormalized, "positive.", "positive"},
+		{"normalized decorations", EnumNormalized, "`Negative`!", "negative"},
+		{"normalized rejects sentence", EnumNormalized, "The sentiment is positive", ""},
+		{"fuzzy sentence", EnumFuzzy, "The sentiment is positive", "positive"},
+		{"fuzzy prefers longer class", EnumFuzzy, "It is very positive.", "very positive"},
+		{"fuzzy ambiguous", EnumFuzzy, "positive or negative", ""},
+		{"fuzzy no class", EnumFuzzy, "mixed feelings", ""},
+		{"fuzzy word boundary", EnumFuzzy, "positively neutral", "neutral"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := map[string]any{"sentiment": tt.input}
+			err := newSig(tt.mode).ValidateOutputs(outputs)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("ValidateOutputs(%q) = nil, want an invalid class error (got %v)", tt.input, outputs["sentiment"])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateOutputs(%q) error = %v", tt.input, err)
+			}
+			if outputs["sentiment"] != tt.want {
+				t.Errorf("sentiment = %v, want %q", outputs["sentiment"], tt.want)
+			}
+		})
+	}
+
+	if newSig(EnumExact).Clone().EnumMatching != EnumExact {
+		t.Error("Clone() should keep EnumMatching")
+	}
+}
+
 func TestSignature_ValidateOutputsPartial_WithOptional(t *testing.T) {
 	sig := NewSignature("Test").
 		AddOutput("required1", FieldTypeString, "").
@@ -899,6 +992,8 @@ func TestSignature_validateFieldType_DirectCalls(t *testing.T) {
 		{"class as int", Field{Name: "f", Type: FieldTypeClass}, 42, true},
 		{"image as string", Field{Name: "f", Type: FieldTypeImage}, "url", false},
 		{"image as int", Field{Name: "f", Type: FieldTypeImage}, 42, true},
+		{"image as bytes", Field{Name: "f", Type: FieldTypeImage}, []byte{0x89, 'P', 'N', 'G'}, false},
+		{"image as ImageContent", Field{Name: "f", Type: FieldTypeImage}, NewImageFromURL("https://example.com/a.png"), false},
 		{"datetime as string", Field{Name: "f", Type: FieldTypeDatetime}, "2024-01-01", false},
 		{"datetime as int", Field{Name: "f", Type: FieldTypeDatetime}, 42, true},
 
@@ -1013,6 +1108,25 @@ func TestSignatureToJSONSchema(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "descriptions and enums carry through",
+			sig: NewSignature("Triage").
+				AddClassOutput("priority", []string{"low", "high"}, "How urgent the ticket is").
+				AddOutput("summary", FieldTypeString, "One-line summary"),
+			validate: func(t *testing.T, schema map[string]any) {
+				props := schema["properties"].(map[string]any)
+				priority := props["priority"].(map[string]any)
+				if priority["description"] != "How urgent the ticket is" {
+					t.Errorf("priority description = %v", priority["description"])
+				}
+				if enum, _ := priority["enum"].([]string); len(enum) != 2 || enum[0] != "low" || enum[1] != "high" {
+					t.Errorf("priority enum = %v, want [low high]", priority["enum"])
+				}
+				if summary := props["summary"].(map[string]any); summary["description"] != "One-line summary" {
+					t.Errorf("summary description = %v", summary["description"])
+				}
+			},
+		},
 		{
 			name: "optional fields",
 			sig: NewSignature("Test").
@@ -1461,3 +1575,127 @@ func BenchmarkSignature_ConcurrentValidation(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+func fieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func TestSignature_Extend(t *testing.T) {
+	base := NewSignature("Base").
+		AddInput("text", FieldTypeString, "Text").
+		AddInput("language", FieldTypeString, "Language")
+	extra := NewSignature("Extra").
+		AddInput("question", FieldTypeString, "Question").
+		AddOutput("answer", FieldTypeString, "Answer").
+		AddOutput("confidence", FieldTypeFloat, "Confidence")
+
+	sig := NewSignature("Answer about text").AddInput("context", FieldTypeString, "Context")
+	if _, err := sig.Extend(base); err != nil {
+		t.Fatalf("Extend(base) error: %v", err)
+	}
+	if _, err := sig.Extend(extra); err != nil {
+		t.Fatalf("Extend(extra) error: %v", err)
+	}
+
+	// Insertion order must be deterministic so prompts are stable
+	if got := fmt.Sprint(fieldNames(sig.InputFields)); got != "[context text language question]" {
+		t.Errorf("input order = %s", got)
+	}
+	if got := fmt.Sprint(fieldNames(sig.OutputFields)); got != "[answer confidence]" {
+		t.Errorf("output order = %s", got)
+	}
+	if sig.Description != "Answer about text" {
+		t.Errorf("Description changed to %q", sig.Description)
+	}
+}
+
+func TestSignature_Extend_Collision(t *testing.T) {
+	sig := NewSignature("Sig").
+		AddInput("text", FieldTypeString, "Text").
+		AddOutput("answer", FieldTypeString, "Answer")
+	other := NewSignature("Other").
+		AddInput("extra", FieldTypeString, "Extra").
+		AddOutput("answer", FieldTypeInt, "Numeric answer")
+
+	if _, err := sig.Extend(other); err == nil {
+		t.Fatal("expected collision error")
+	}
+	if len(sig.InputFields) != 1 || len(sig.OutputFields) != 1 || sig.OutputFields[0].Type != FieldTypeString {
+		t.Errorf("signature modified on collision: %+v", sig)
+	}
+
+	sig.ExtendOverride(other)
+	if got := fmt.Sprint(fieldNames(sig.InputFields)); got != "[text extra]" {
+		t.Errorf("input order = %s", got)
+	}
+	if len(sig.OutputFields) != 1 || sig.OutputFields[0].Type != FieldTypeInt {
+		t.Errorf("expected answer to be overridden in place, got %+v", sig.OutputFields)
+	}
+}
+
+func TestSignature_DerivedOutputs(t *testing.T) {
+	sig := NewSignature("Analyze").
+		AddInput("text", FieldTypeString, "Text").
+		AddOutput("summary", FieldTypeString, "Summary").
+		AddClassOutput("sentiment", []string{"positive", "negative"}, "Sentiment").
+		AddOutput("keywords", FieldTypeJSON, "Keywords")
+
+	tests := []struct {
+		name    string
+		derived *Signature
+		want    string
+	}{
+		{name: "without output", derived: sig.WithoutOutput("sentiment"), want: "[summary keywords]"},
+		{name: "without missing output", derived: sig.WithoutOutput("missing"), want: "[summary sentiment keywords]"},
+		{name: "only outputs keeps signature order", derived: sig.OnlyOutputs("keywords", "summary"), want: "[summary keywords]"},
+		{name: "only outputs ignores unknown", derived: sig.OnlyOutputs("sentiment", "missing"), want: "[sentiment]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(fieldNames(tt.derived.OutputFields)); got != tt.want {
+				t.Errorf("outputs = %s, want %s", got, tt.want)
+			}
+			if got := fmt.Sprint(fieldNames(tt.derived.InputFields)); got != "[text]" {
+				t.Errorf("inputs = %s, want [text]", got)
+			}
+		})
+	}
+
+	// Deriving must not touch the original signature or share its class slices
+	derived := sig.OnlyOutputs("sentiment")
+	derived.OutputFields[0].Classes[0] = "changed"
+	if got := fmt.Sprint(fieldNames(sig.OutputFields)); got != "[summary sentiment keywords]" {
+		t.Errorf("original outputs = %s", got)
+	}
+	if sig.OutputFields[1].Classes[0] != "positive" {
+		t.Errorf("original classes modified: %v", sig.OutputFields[1].Classes)
+	}
+}
+
+func TestSignature_ValidateOutputs_BlankOptional(t *testing.T) {
+	sig := NewSignature("test").
+		AddOutput("answer", FieldTypeString, "").
+		AddOptionalOutput("note", FieldTypeString, "").
+		AddOptionalOutput("confidence", FieldTypeFloat, "")
+
+	outputs := map[string]any{"answer": "yes", "note": "  ", "confidence": nil}
+	if err := sig.ValidateOutputs(outputs); err != nil {
+		t.Fatalf("ValidateOutputs() error = %v", err)
+	}
+	if _, ok := outputs["note"]; ok {
+		t.Error("blank optional output should be removed")
+	}
+	if _, ok := outputs["confidence"]; ok {
+		t.Error("null optional output should be removed")
+	}
+
+	// Required fields are not relaxed
+	if err := sig.ValidateOutputs(map[string]any{"note": "x"}); err == nil {
+		t.Error("expected error for missing required output")
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// CoalesceChunks merges the chunks of a stream so consumers that re-render on every
+// chunk see fewer, larger updates. Buffered chunks are delivered once flushInterval
+// has passed since the first of them arrived, or once their content and reasoning
+// reach minBytes; a non-positive value disables that trigger, and with both disabled
+// in is returned unchanged. A chunk with a FinishReason is never merged: pending
+// content is delivered first, then the finish chunk as is. Merged chunks concatenate
+// Content and Reasoning, collect ToolCalls and keep the latest non-zero Usage.
+// The returned channel closes after in closes or ctx is done.
+func CoalesceChunks(ctx context.Context, in <-chan Chunk, flushInterval time.Duration, minBytes int) <-chan Chunk {
+	if flushInterval <= 0 && minBytes <= 0 {
+		return in
+	}
+
+	out := make(chan Chunk)
+	go func() {
+		defer close(out)
+
+		var pending Chunk
+		buffered := false
+		var timer *time.Timer
+		var flushAt <-chan time.Time
+
+		send := func(chunk Chunk) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				flushAt = nil
+			}
+			if !buffered {
+				return true
+			}
+			chunk := pending
+			pending, buffered = Chunk{}, false
+			return send(chunk)
+		}
+
+		for {
+			select {
+			case chunk, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				if chunk.FinishReason != "" {
+					if !flush() || !send(chunk) {
+						return
+					}
+					continue
+				}
+
+				mergeChunk(&pending, chunk)
+				buffered = true
+				if minBytes > 0 && len(pending.Content)+len(pending.Reasoning) >= minBytes {
+					if !flush() {
+						return
+					}
+					continue
+				}
+				if flushInterval > 0 && flushAt == nil {
+					timer = time.NewTimer(flushInterval)
+					flushAt = timer.C
+				}
+			case <-flushAt:
+				flushAt = nil
+				if !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// mergeChunk appends chunk to pending
+func mergeChunk(pending *Chunk, chunk Chunk) {
+	pending.Content += chunk.Content
+	pending.Reasoning += chunk.Reasoning
+	pending.ToolCalls = append(pending.ToolCalls, chunk.ToolCalls...)
+	if chunk.Usage != (Usage{}) {
+		pending.Usage = chunk.Usage
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// feed returns a channel delivering chunks, then closing
+func feed(chunks ...Chunk) <-chan Chunk {
+	in := make(chan Chunk, len(chunks))
+	for _, c := range chunks {
+		in <- c
+	}
+	close(in)
+	return in
+}
+
+func collect(out <-chan Chunk) []Chunk {
+	var got []Chunk
+	for c := range out {
+		got = append(got, c)
+	}
+	return got
+}
+
+func TestCoalesceChunks_Disabled(t *testing.T) {
+	in := feed(Chunk{Content: "a"})
+	if out := CoalesceChunks(context.Background(), in, 0, 0); out != in {
+		t.Error("CoalesceChunks() with both triggers off should return the input channel")
+	}
+}
+
+func TestCoalesceChunks_MinBytes(t *testing.T) {
+	in := feed(
+		Chunk{Content: "ab"},
+		Chunk{Reasoning: "cd"},
+		Chunk{Content: "ef", ToolCalls: []ToolCall{{ID: "1"}}},
+		Chunk{Content: "g", Usage: Usage{TotalTokens: 3}},
+		Chunk{FinishReason: "stop", Usage: Usage{TotalTokens: 5}},
+	)
+
+	got := collect(CoalesceChunks(context.Background(), in, 0, 4))
+	if len(got) != 3 {
+		t.Fatalf("got %d chunks, want 3: %+v", len(got), got)
+	}
+	if got[0].Content != "ab" || got[0].Reasoning != "cd" {
+		t.Errorf("first chunk = %+v, want the first two chunks merged", got[0])
+	}
+	if got[1].Content != "efg" || len(got[1].ToolCalls) != 1 || got[1].Usage.TotalTokens != 3 || got[1].FinishReason != "" {
+		t.Errorf("second chunk = %+v, want pending content flushed before the finish", got[1])
+	}
+	if got[2].FinishReason != "stop" || got[2].Content != "" || got[2].Usage.TotalTokens != 5 {
+		t.Errorf("last chunk = %+v, want the unmerged finish chunk", got[2])
+	}
+}
+
+func TestCoalesceChunks_FlushInterval(t *testing.T) {
+	in := make(chan Chunk)
+	out := CoalesceChunks(context.Background(), in, 20*time.Millisecond, 0)
+
+	in <- Chunk{Content: "a"}
+	in <- Chunk{Content: "b"}
+	select {
+	case c := <-out:
+		if c.Content != "ab" {
+			t.Errorf("flushed chunk = %+v, want merged content \"ab\"", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending chunk was not flushed after the interval")
+	}
+
+	in <- Chunk{Content: "c"}
+	close(in)
+	if got := collect(out); len(got) != 1 || got[0].Content != "c" {
+		t.Errorf("chunks after close = %+v, want the pending chunk", got)
+	}
+}
+
+func TestCoalesceChunks_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan Chunk)
+	out := CoalesceChunks(ctx, in, time.Hour, 0)
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("expected no chunks after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("output was not closed after cancel")
+	}
+}
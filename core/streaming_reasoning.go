@@ -0,0 +1,266 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// reasoningBoundaryPattern matches the tokens where streamed content switches between
+// reasoning and answer: chat field markers, think tags and the opening of a JSON "reasoning" value
+var reasoningBoundaryPattern = reasoningBoundary(DefaultReasoningField, "", "")
+
+// reasoningBoundary returns reasoningBoundaryPattern for a reasoning field named field and
+// chat field markers delimited by open and close (empty for the default [[ ## ... ## ]])
+func reasoningBoundary(field, open, close string) *regexp.Regexp {
+	marker := `\[\[\s*##\s*(\w+)\s*##\s*\]\]`
+	if open != "" || close != "" {
+		marker = regexp.QuoteMeta(open) + `\s*(\w+)\s*` + regexp.QuoteMeta(close)
+	}
+	return regexp.MustCompile(marker + `|<(/?)think(?:ing)?>|"` + regexp.QuoteMeta(field) + `"\s*:\s*"`)
+}
+
+// thinkTags are the inline tags reasoning models wrap their thinking in
+var thinkTags = []string{"<think>", "</think>", "<thinking>", "</thinking>"}
+
+// StreamingReasoningSplitter separates streamed model output into reasoning and answer text
+// It recognises the chat adapter's [[ ## reasoning ## ]] section (or custom markers, see
+// ForAdapter), the JSON adapter's "reasoning" value and <think> blocks, even when a
+// boundary is split across chunks.
+// Field markers and think tags are removed from both streams.
+type StreamingReasoningSplitter struct {
+	field         string         // Name of the reasoning field
+	fieldOpen     string         // Custom chat marker text before a field name ("" = default)
+	fieldClose    string         // Custom chat marker text after a field name ("" = default)
+	boundary      *regexp.Regexp // reasoningBoundaryPattern for field and markers
+	pending       string
+	inReasoning   bool // Inside a reasoning section or think block
+	inJSONValue   bool // Inside the JSON "reasoning" string
+	inThinkBlock  bool // Reasoning started by <think>, ended only by </think>
+	reasoningText strings.Builder
+	answerText    strings.Builder
+}
+
+// NewStreamingReasoningSplitter creates a splitter that starts in the answer
+func NewStreamingReasoningSplitter() *StreamingReasoningSplitter {
+	return &StreamingReasoningSplitter{field: DefaultReasoningField, boundary: reasoningBoundaryPattern}
+}
+
+// WithReasoningField makes the splitter recognise a reasoning field renamed with an
+// adapter's WithReasoningField, e.g. "scratchpad"
+func (s *StreamingReasoningSplitter) WithReasoningField(name string) *StreamingReasoningSplitter {
+	s.field = ReasoningFieldOrDefault(name)
+	s.updateBoundary()
+	return s
+}
+
+// WithFieldDelimiter makes the splitter recognise chat field markers set with
+// ChatAdapter.WithFieldDelimiter, e.g. <<<reasoning>>>
+func (s *StreamingReasoningSplitter) WithFieldDelimiter(open, close string) *StreamingReasoningSplitter {
+	s.fieldOpen, s.fieldClose = strings.TrimSpace(open), strings.TrimSpace(close)
+	if s.fieldOpen == strings.TrimSpace(defaultFieldOpen) && s.fieldClose == strings.TrimSpace(defaultFieldClose) {
+		s.fieldOpen, s.fieldClose = "", ""
+	}
+	s.updateBoundary()
+	return s
+}
+
+// ForAdapter configures the splitter for the field markers of adapter: a ChatAdapter,
+// or the first ChatAdapter in a FallbackAdapter's chain
+func (s *StreamingReasoningSplitter) ForAdapter(adapter Adapter) *StreamingReasoningSplitter {
+	adapters := []Adapter{adapter}
+	if fallback, ok := adapter.(*FallbackAdapter); ok {
+		adapters = fallback.adapters
+	}
+	for _, a := range adapters {
+		if chat, ok := a.(*ChatAdapter); ok {
+			return s.WithFieldDelimiter(chat.FieldOpen, chat.FieldClose)
+		}
+	}
+	return s
+}
+
+// updateBoundary rebuilds the boundary pattern after the field or markers change
+func (s *StreamingReasoningSplitter) updateBoundary() {
+	if s.field == DefaultReasoningField && s.fieldOpen == "" && s.fieldClose == "" {
+		s.boundary = reasoningBoundaryPattern
+	} else {
+		s.boundary = reasoningBoundary(s.field, s.fieldOpen, s.fieldClose)
+	}
+}
+
+// Process consumes a chunk of raw content and returns the reasoning and answer text
+// that can be shown so far; text that may be the start of a boundary is held back
+func (s *StreamingReasoningSplitter) Process(content string) (reasoning, answer string) {
+	s.pending += content
+	s.reasoningText.Reset()
+	s.answerText.Reset()
+
+	for s.pending != "" {
+		if s.inJSONValue {
+			if !s.consumeJSONValue() {
+				break
+			}
+			continue
+		}
+
+		loc := s.boundary.FindStringSubmatchIndex(s.pending)
+		if loc == nil {
+			hold := s.holdBack()
+			s.emit(s.pending[:hold])
+			s.pending = s.pending[hold:]
+			break
+		}
+
+		s.emit(s.pending[:loc[0]])
+		token := s.pending[loc[0]:loc[1]]
+		switch {
+		case loc[2] >= 0: // Chat field marker
+			if !s.inThinkBlock {
+				s.inReasoning = s.pending[loc[2]:loc[3]] == s.field
+			}
+		case strings.HasPrefix(token, "<"): // Think tag
+			closing := loc[5] > loc[4]
+			s.inThinkBlock = !closing
+			s.inReasoning = !closing
+		default: // JSON "reasoning" value
+			if s.inThinkBlock {
+				s.emit(token)
+			} else {
+				s.inJSONValue = true
+			}
+		}
+		s.pending = s.pending[loc[1]:]
+	}
+
+	return s.reasoningText.String(), s.answerText.String()
+}
+
+// Flush returns any held-back text once the stream has ended
+func (s *StreamingReasoningSplitter) Flush() (reasoning, answer string) {
+	s.reasoningText.Reset()
+	s.answerText.Reset()
+	if s.inJSONValue {
+		s.reasoningText.WriteString(s.pending)
+	} else {
+		s.emit(s.pending)
+	}
+	s.pending = ""
+	return s.reasoningText.String(), s.answerText.String()
+}
+
+// emit writes text to the stream for the current section
+func (s *StreamingReasoningSplitter) emit(text string) {
+	if s.inReasoning {
+		s.reasoningText.WriteString(text)
+	} else {
+		s.answerText.WriteString(text)
+	}
+}
+
+// consumeJSONValue decodes the pending part of the JSON "reasoning" string into the
+// reasoning stream, reporting false when more input is needed to continue
+func (s *StreamingReasoningSplitter) consumeJSONValue() bool {
+	for i := 0; i < len(s.pending); i++ {
+		switch c := s.pending[i]; c {
+		case '"':
+			s.inJSONValue = false
+			s.pending = s.pending[i+1:]
+			return true
+		case '\\':
+			if i+1 >= len(s.pending) {
+				s.pending = s.pending[i:]
+				return false
+			}
+			i++
+			switch e := s.pending[i]; e {
+			case 'n':
+				s.reasoningText.WriteByte('\n')
+			case 't':
+				s.reasoningText.WriteByte('\t')
+			case 'r':
+			case 'u':
+				if i+4 >= len(s.pending) {
+					s.pending = s.pending[i-1:]
+					return false
+				}
+				if code, err := strconv.ParseUint(s.pending[i+1:i+5], 16, 32); err == nil {
+					s.reasoningText.WriteRune(rune(code))
+					i += 4
+				} else {
+					s.reasoningText.WriteByte(e)
+				}
+			default:
+				s.reasoningText.WriteByte(e)
+			}
+		default:
+			s.reasoningText.WriteByte(c)
+		}
+	}
+	s.pending = ""
+	return false
+}
+
+// holdBack returns the index from which pending could still become a boundary token
+func (s *StreamingReasoningSplitter) holdBack() int {
+	for i := 0; i < len(s.pending); i++ {
+		rest := s.pending[i:]
+		if s.fieldOpen != "" && couldBeFieldMarker(rest, s.fieldOpen, s.fieldClose) {
+			return i
+		}
+		switch s.pending[i] {
+		case '[':
+			if s.fieldOpen == "" && s.fieldClose == "" && couldBeMarker(rest) {
+				return i
+			}
+		case '<':
+			for _, tag := range thinkTags {
+				if strings.HasPrefix(tag, rest) {
+					return i
+				}
+			}
+		case '"':
+			if couldBeJSONReasoningKey(rest, s.field) {
+				return i
+			}
+		}
+	}
+	return len(s.pending)
+}
+
+// couldBeFieldMarker reports whether s is a prefix of a custom field marker:
+// open, a field name and close, with optional spaces around the name
+func couldBeFieldMarker(s, open, close string) bool {
+	if len(s) <= len(open) {
+		return strings.HasPrefix(open, s)
+	}
+	if !strings.HasPrefix(s, open) {
+		return false
+	}
+	rest := strings.TrimLeft(s[len(open):], " \t")
+	rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+	rest = strings.TrimLeft(rest, " \t")
+	return len(rest) < len(close) && strings.HasPrefix(close, rest)
+}
+
+// couldBeJSONReasoningKey reports whether s is a prefix of `"<field>": "`
+func couldBeJSONReasoningKey(s, field string) bool {
+	key := `"` + field + `"`
+	if len(s) <= len(key) {
+		return strings.HasPrefix(key, s)
+	}
+	if !strings.HasPrefix(s, key) {
+		return false
+	}
+	rest := strings.TrimLeft(s[len(key):], " \t\r\n")
+	if rest == "" {
+		return true
+	}
+	if rest[0] != ':' {
+		return false
+	}
+	return strings.TrimLeft(rest[1:], " \t\r\n") == ""
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamingReasoningSplitter(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantReasoning string
+		wantAnswer    string
+	}{
+		{
+			name:          "chat markers",
+			content:       "[[ ## reasoning ## ]]\n2 + 2 is 4.\n\n[[ ## answer ## ]]\n4\n\n[[ ## completed ## ]]",
+			wantReasoning: "2 + 2 is 4.",
+			wantAnswer:    "4",
+		},
+		{
+			name:          "json reasoning value",
+			content:       `{"reasoning": "say \"hi\"\nthen é", "answer": "hi"}`,
+			wantReasoning: "say \"hi\"\nthen é",
+			wantAnswer:    `{, "answer": "hi"}`,
+		},
+		{
+			name:          "think block",
+			content:       "<think>let me see [[ ## answer ## ]] not yet</think>\n[[ ## answer ## ]]\nyes",
+			wantReasoning: "let me see  not yet",
+			wantAnswer:    "yes",
+		},
+		{
+			name:          "no reasoning",
+			content:       "[[ ## answer ## ]]\nplain [1] text < 3",
+			wantAnswer:    "plain [1] text < 3",
+			wantReasoning: "",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, size := range []int{1, 3, 7, len(tt.content)} {
+			splitter := NewStreamingReasoningSplitter()
+			var reasoning, answer strings.Builder
+			for start := 0; start < len(tt.content); start += size {
+				end := min(start+size, len(tt.content))
+				r, a := splitter.Process(tt.content[start:end])
+				reasoning.WriteString(r)
+				answer.WriteString(a)
+			}
+			r, a := splitter.Flush()
+			reasoning.WriteString(r)
+			answer.WriteString(a)
+
+			if got := strings.TrimSpace(reasoning.String()); got != tt.wantReasoning {
+				t.Errorf("%s (chunk size %d): reasoning = %q, want %q", tt.name, size, got, tt.wantReasoning)
+			}
+			if got := strings.TrimSpace(answer.String()); got != tt.wantAnswer {
+				t.Errorf("%s (chunk size %d): answer = %q, want %q", tt.name, size, got, tt.wantAnswer)
+			}
+		}
+	}
+}
+
+func TestStreamingReasoningSplitter_CustomField(t *testing.T) {
+	content := "[[ ## scratchpad ## ]]\nArea is w * h.\n\n[[ ## answer ## ]]\n12"
+	for _, asJSON := range []bool{false, true} {
+		if asJSON {
+			content = `{"scratchpad": "Area is w * h.", "answer": "12"}`
+		}
+		splitter := NewStreamingReasoningSplitter().WithReasoningField("scratchpad")
+		var reasoning, answer strings.Builder
+		for start := 0; start < len(content); start += 3 {
+			r, a := splitter.Process(content[start:min(start+3, len(content))])
+			reasoning.WriteString(r)
+			answer.WriteString(a)
+		}
+		r, a := splitter.Flush()
+		reasoning.WriteString(r)
+		answer.WriteString(a)
+
+		if got := strings.TrimSpace(reasoning.String()); got != "Area is w * h." {
+			t.Errorf("json=%v: reasoning = %q", asJSON, got)
+		}
+		if strings.Contains(answer.String(), "w * h") {
+			t.Errorf("json=%v: answer = %q, want no reasoning", asJSON, answer.String())
+		}
+	}
+}
+
+func TestStreamingReasoningSplitter_CustomDelimiter(t *testing.T) {
+	content := "<<<reasoning>>>\n2 + 2 is 4, not <5.\n\n<<< answer >>>\n4\n\n<<<completed>>>"
+	adapters := map[string]Adapter{
+		"chat":     NewChatAdapter().WithFieldDelimiter("<<<", ">>>"),
+		"fallback": NewFallbackAdapterWithChain(NewJSONAdapter(), NewChatAdapter().WithFieldDelimiter("<<<", ">>>")),
+	}
+	for name, adapter := range adapters {
+		for _, size := range []int{1, 3, 7, len(content)} {
+			splitter := NewStreamingReasoningSplitter().ForAdapter(adapter)
+			var reasoning, answer strings.Builder
+			for start := 0; start < len(content); start += size {
+				r, a := splitter.Process(content[start:min(start+size, len(content))])
+				reasoning.WriteString(r)
+				answer.WriteString(a)
+			}
+			r, a := splitter.Flush()
+			reasoning.WriteString(r)
+			answer.WriteString(a)
+
+			if got := strings.TrimSpace(reasoning.String()); got != "2 + 2 is 4, not <5." {
+				t.Errorf("%s (chunk size %d): reasoning = %q", name, size, got)
+			}
+			if got := strings.TrimSpace(answer.String()); got != "4" {
+				t.Errorf("%s (chunk size %d): answer = %q, want 4", name, size, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import "context"
+
+// tagsKey is the context key for request tags
+type tagsKey struct{}
+
+// WithTags attaches tags (e.g. user_id, feature) to ctx for observability.
+// Tags flow through nested modules and are recorded on every HistoryEntry
+// produced under ctx. Nested calls merge with tags already on ctx, with the
+// new values taking precedence.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := TagsFromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// TagsFromContext returns a copy of the tags attached to ctx, or nil if there are none
+func TagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, ok := ctx.Value(tagsKey{}).(map[string]string)
+	if !ok || len(tags) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithTags(t *testing.T) {
+	if TagsFromContext(context.Background()) != nil {
+		t.Error("expected no tags on a bare context")
+	}
+
+	ctx := WithTags(context.Background(), map[string]string{"user_id": "u1", "feature": "search"})
+	nested := WithTags(ctx, map[string]string{"feature": "summarize", "step": "2"})
+
+	want := map[string]string{"user_id": "u1", "feature": "summarize", "step": "2"}
+	got := TagsFromContext(nested)
+	if len(got) != len(want) {
+		t.Fatalf("TagsFromContext() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("tag %q = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if TagsFromContext(ctx)["feature"] != "search" {
+		t.Error("nested WithTags must not modify the parent context's tags")
+	}
+
+	got["user_id"] = "mutated"
+	if TagsFromContext(nested)["user_id"] != "u1" {
+		t.Error("TagsFromContext must return a copy")
+	}
+}
+
+func TestLMWrapper_RecordsTags(t *testing.T) {
+	collector := NewMemoryCollector(10)
+	lm := NewLMWrapper(&mockWrapperLM{
+		name: "test-model",
+		generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+			return &GenerateResult{Content: "ok"}, nil
+		},
+	}, collector)
+
+	calls := []struct {
+		tags map[string]string
+	}{
+		{map[string]string{"user_id": "alice", "feature": "chat"}},
+		{map[string]string{"user_id": "bob", "feature": "chat"}},
+		{map[string]string{"user_id": "alice", "feature": "search"}},
+		{nil},
+	}
+	for _, call := range calls {
+		ctx := context.Background()
+		if call.tags != nil {
+			ctx = WithTags(ctx, call.tags)
+		}
+		if _, err := lm.Generate(ctx, []Message{{Role: "user", Content: "hi"}}, DefaultGenerateOptions()); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+
+	if got := len(collector.GetByTag("user_id", "alice")); got != 2 {
+		t.Errorf("expected 2 entries for alice, got %d", got)
+	}
+	if got := len(collector.GetByTag("feature", "chat")); got != 2 {
+		t.Errorf("expected 2 chat entries, got %d", got)
+	}
+	if got := len(collector.GetByTag("user_id", "carol")); got != 0 {
+		t.Errorf("expected no entries for carol, got %d", got)
+	}
+	if last := collector.GetLast(1)[0]; last.Tags != nil {
+		t.Errorf("expected untagged call to have no tags, got %v", last.Tags)
+	}
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"unicode"
+)
+
+// TiktokenTokenizer counts tokens with byte-pair encoding ranks in tiktoken's
+// ".tiktoken" format (one "base64-token rank" pair per line), e.g. cl100k_base or o200k_base.
+// Text is split with cl100k_base's pre-tokenization rules, so counts are exact for
+// cl100k_base models and a close estimate for o200k_base.
+type TiktokenTokenizer struct {
+	ranks map[string]int
+}
+
+// NewTiktokenTokenizer reads BPE ranks from r
+func NewTiktokenTokenizer(r io.Reader) (*TiktokenTokenizer, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		token, rank, ok := bytes.Cut(text, []byte(" "))
+		if !ok {
+			return nil, fmt.Errorf("invalid tiktoken rank at line %d", line)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(string(token))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tiktoken token at line %d: %w", line, err)
+		}
+		n, err := strconv.Atoi(string(rank))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tiktoken rank at line %d: %w", line, err)
+		}
+		ranks[string(decoded)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tiktoken ranks: %w", err)
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("tiktoken ranks are empty")
+	}
+	return &TiktokenTokenizer{ranks: ranks}, nil
+}
+
+// LoadTiktokenTokenizer reads BPE ranks from a ".tiktoken" file
+func LoadTiktokenTokenizer(path string) (*TiktokenTokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tiktoken ranks: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return NewTiktokenTokenizer(f)
+}
+
+// Count returns the number of BPE tokens in text
+func (t *TiktokenTokenizer) Count(model, text string) int {
+	runes := []rune(text)
+	total := 0
+	for start := 0; start < len(runes); {
+		end := nextPretoken(runes, start)
+		total += t.countPiece([]byte(string(runes[start:end])))
+		start = end
+	}
+	return total
+}
+
+// CountMessages returns the prompt token count of messages
+func (t *TiktokenTokenizer) CountMessages(model string, messages []Message) int {
+	return countMessageTokens(t, model, messages)
+}
+
+// Encode returns the BPE token IDs (ranks) of text
+func (t *TiktokenTokenizer) Encode(model, text string) []int {
+	runes := []rune(text)
+	var tokens []int
+	for start := 0; start < len(runes); {
+		end := nextPretoken(runes, start)
+		piece := []byte(string(runes[start:end]))
+		parts := t.mergePiece(piece)
+		for i := 0; i+1 < len(parts); i++ {
+			if rank, ok := t.ranks[string(piece[parts[i]:parts[i+1]])]; ok {
+				tokens = append(tokens, rank)
+			}
+		}
+		start = end
+	}
+	return tokens
+}
+
+// countPiece runs byte-pair merges on one pre-token and returns the resulting token count
+func (t *TiktokenTokenizer) countPiece(piece []byte) int {
+	if _, ok := t.ranks[string(piece)]; ok {
+		return 1
+	}
+	return len(t.mergePiece(piece)) - 1
+}
+
+// mergePiece runs byte-pair merges on one pre-token and returns the start offset of
+// each resulting token, plus len(piece)
+func (t *TiktokenTokenizer) mergePiece(piece []byte) []int {
+	if _, ok := t.ranks[string(piece)]; ok {
+		return []int{0, len(piece)}
+	}
+
+	// parts holds the start offset of each current token, plus len(piece)
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+	for len(parts) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(parts); i++ {
+			if rank, ok := t.ranks[string(piece[parts[i]:parts[i+2]])]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
+
+// nextPretoken returns the end of the pre-token starting at i, following cl100k_base's pattern:
+// contractions | [^\r\n\p{L}\p{N}]?\p{L}+ | \p{N}{1,3} | ' '?[^\s\p{L}\p{N}]+[\r\n]* |
+// \s*[\r\n]+ | \s+(?!\S) | \s+
+func nextPretoken(r []rune, i int) int {
+	n := len(r)
+
+	// Contractions: 's 't 're 've 'm 'll 'd (case-insensitive)
+	if r[i] == '\'' && i+1 < n {
+		if i+2 < n {
+			switch string(unicode.ToLower(r[i+1])) + string(unicode.ToLower(r[i+2])) {
+			case "re", "ve", "ll":
+				return i + 3
+			}
+		}
+		switch unicode.ToLower(r[i+1]) {
+		case 's', 't', 'm', 'd':
+			return i + 2
+		}
+	}
+
+	// Letters, optionally preceded by one other non-newline character
+	if !isNewline(r[i]) && !unicode.IsLetter(r[i]) && !unicode.IsNumber(r[i]) && i+1 < n && unicode.IsLetter(r[i+1]) {
+		return scanWhile(r, i+1, unicode.IsLetter)
+	}
+	if unicode.IsLetter(r[i]) {
+		return scanWhile(r, i, unicode.IsLetter)
+	}
+
+	// Up to three digits
+	if unicode.IsNumber(r[i]) {
+		j := i
+		for j < n && j-i < 3 && unicode.IsNumber(r[j]) {
+			j++
+		}
+		return j
+	}
+
+	// Punctuation run, optionally preceded by a space and followed by newlines
+	j := i
+	if r[j] == ' ' {
+		j++
+	}
+	if k := scanWhile(r, j, isPunct); k > j {
+		return scanWhile(r, k, isNewline)
+	}
+
+	// Whitespace
+	end := scanWhile(r, i, unicode.IsSpace)
+	if end == i {
+		return i + 1
+	}
+	for k := end - 1; k >= i; k-- {
+		if isNewline(r[k]) {
+			return k + 1
+		}
+	}
+	if end < n && end-i > 1 {
+		// Leave the last space to prefix the following word
+		return end - 1
+	}
+	return end
+}
+
+// scanWhile returns the first index at or after i whose rune does not satisfy pred
+func scanWhile(r []rune, i int, pred func(rune) bool) int {
+	for i < len(r) && pred(r[i]) {
+		i++
+	}
+	return i
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+func isPunct(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tokenizer counts tokens for context-window budgeting and usage estimation
+// The model name lets one implementation serve several encodings; it may be empty.
+type Tokenizer interface {
+	Count(model, text string) int
+	CountMessages(model string, messages []Message) int
+}
+
+// TokenEncoder is implemented by tokenizers that can map text to token IDs,
+// which logit_bias needs (TiktokenTokenizer implements it)
+type TokenEncoder interface {
+	Encode(model, text string) []int
+}
+
+// HeuristicTokenizer estimates about one token per four characters
+// It is the default and needs no vocabulary, but can be off by 20-30% for code or non-English text.
+type HeuristicTokenizer struct{}
+
+// Count approximates the token count of text
+func (HeuristicTokenizer) Count(model, text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// CountMessages approximates the prompt token count of messages
+func (t HeuristicTokenizer) CountMessages(model string, messages []Message) int {
+	return countMessageTokens(t, model, messages)
+}
+
+var (
+	tokenizer   Tokenizer = HeuristicTokenizer{}
+	tokenizerMu sync.RWMutex
+)
+
+// SetTokenizer replaces the tokenizer used for token estimates across dsgo
+// (context-window truncation, EstimateUsage, mock usage). Passing nil restores the heuristic.
+func SetTokenizer(t Tokenizer) {
+	if t == nil {
+		t = HeuristicTokenizer{}
+	}
+	tokenizerMu.Lock()
+	defer tokenizerMu.Unlock()
+	tokenizer = t
+}
+
+// GetTokenizer returns the configured tokenizer
+func GetTokenizer() Tokenizer {
+	tokenizerMu.RLock()
+	defer tokenizerMu.RUnlock()
+	return tokenizer
+}
+
+// CountTokens counts the tokens of text for model with the configured tokenizer
+func CountTokens(model, text string) int {
+	return GetTokenizer().Count(model, text)
+}
+
+// CountMessageTokens counts the prompt tokens of messages for model with the configured tokenizer
+func CountMessageTokens(model string, messages []Message) int {
+	return GetTokenizer().CountMessages(model, messages)
+}
+
+// countMessageTokens sums content and tool-call tokens plus fixed per-message and per-image overheads
+// Tokenizer implementations share it so message framing is counted the same way.
+func countMessageTokens(t Tokenizer, model string, messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += messageTokenOverhead + t.Count(model, msg.Content)
+		total += len(msg.Images) * imageTokenEstimate
+		for _, tc := range msg.ToolCalls {
+			total += t.Count(model, tc.Name) + t.Count(model, fmt.Sprintf("%v", tc.Arguments))
+		}
+	}
+	return total
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// countingTokenizer counts whitespace-separated words, to check that callers consult SetTokenizer
+type countingTokenizer struct{ models []string }
+
+func (c *countingTokenizer) Count(model, text string) int {
+	c.models = append(c.models, model)
+	return len(strings.Fields(text))
+}
+
+func (c *countingTokenizer) CountMessages(model string, messages []Message) int {
+	return countMessageTokens(c, model, messages)
+}
+
+func TestSetTokenizer(t *testing.T) {
+	defer SetTokenizer(nil)
+
+	custom := &countingTokenizer{}
+	SetTokenizer(custom)
+
+	if got := EstimateTokens("one two three"); got != 3 {
+		t.Errorf("EstimateTokens() = %d, want 3", got)
+	}
+	messages := []Message{{Role: "user", Content: "hello there"}}
+	if got := CountMessageTokens("gpt-4o", messages); got != messageTokenOverhead+2 {
+		t.Errorf("CountMessageTokens() = %d, want %d", got, messageTokenOverhead+2)
+	}
+	if custom.models[len(custom.models)-1] != "gpt-4o" {
+		t.Errorf("model not passed to tokenizer, got %q", custom.models[len(custom.models)-1])
+	}
+
+	// The mock LM reports usage with the configured tokenizer
+	result, err := NewMockLM().Respond("a b c d").Generate(t.Context(), messages, DefaultGenerateOptions())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if result.Usage.CompletionTokens != 4 {
+		t.Errorf("CompletionTokens = %d, want 4", result.Usage.CompletionTokens)
+	}
+
+	SetTokenizer(nil)
+	if _, ok := GetTokenizer().(HeuristicTokenizer); !ok {
+		t.Errorf("SetTokenizer(nil) should restore the heuristic, got %T", GetTokenizer())
+	}
+}
+
+func TestNextPretoken(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"I'm here, they've gone", []string{"I", "'m", " here", ",", " they", "'ve", " gone"}},
+		{"12345 apples", []string{"123", "45", " apples"}},
+		{"a  b", []string{"a", " ", " b"}},
+		{"x = 1;\n\ny", []string{"x", " =", " ", "1", ";\n\n", "y"}},
+		{"line\n  next", []string{"line", "\n", " ", " next"}},
+		{"end   ", []string{"end", "   "}},
+		{"$value", []string{"$value"}},
+		{"héllo wörld", []string{"héllo", " wörld"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			runes := []rune(tt.text)
+			var got []string
+			for start := 0; start < len(runes); {
+				end := nextPretoken(runes, start)
+				got = append(got, string(runes[start:end]))
+				start = end
+			}
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("pretokens = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func tiktokenRanks(tokens ...string) string {
+	var sb strings.Builder
+	for i, tok := range tokens {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), i)
+	}
+	return sb.String()
+}
+
+func TestTiktokenTokenizer(t *testing.T) {
+	ranks := tiktokenRanks("a", "b", "c", " ", "ab", " ab", "abc")
+	tok, err := NewTiktokenTokenizer(strings.NewReader(ranks))
+	if err != nil {
+		t.Fatalf("NewTiktokenTokenizer() error: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"abc", 1},     // whole piece is a token
+		{"abab", 2},    // "ab" + "ab"
+		{"ab ab", 2},   // "ab" + " ab"
+		{"cba", 3},     // no merges apply
+		{"abcab c", 4}, // "abc" "ab" + " c" -> " " "c"
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := tok.Count("gpt-4", tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+	if got := tok.Encode("gpt-4", "abcab c"); fmt.Sprint(got) != "[6 4 3 2]" {
+		t.Errorf("Encode(%q) = %v, want [6 4 3 2]", "abcab c", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.tiktoken")
+	if err := os.WriteFile(path, []byte(ranks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTiktokenTokenizer(path)
+	if err != nil {
+		t.Fatalf("LoadTiktokenTokenizer() error: %v", err)
+	}
+	if got := loaded.CountMessages("gpt-4", []Message{{Role: "user", Content: "abc"}}); got != messageTokenOverhead+1 {
+		t.Errorf("CountMessages() = %d, want %d", got, messageTokenOverhead+1)
+	}
+}
+
+func TestNewTiktokenTokenizer_Invalid(t *testing.T) {
+	for _, input := range []string{"", "YWI=", "!!! 1", "YWI= x"} {
+		if _, err := NewTiktokenTokenizer(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+	if _, err := LoadTiktokenTokenizer(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
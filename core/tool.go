@@ -37,7 +37,10 @@ type Tool struct {
 	Name        string
 	Description string
 	Parameters  []ToolParameter
-	Function    ToolFunction `json:"-"` // Exclude from JSON serialization
+	// ResultSchema optionally declares the fields of the tool's result; results
+	// are validated and normalized against it before being returned
+	ResultSchema []ToolParameter `json:"-"`
+	Function     ToolFunction    `json:"-"` // Exclude from JSON serialization
 }
 
 // ToolFunction is the actual function implementation
@@ -88,6 +91,55 @@ func (t *Tool) AddArrayParameter(name, description string, elementType string, r
 	return t
 }
 
+// WithResultSchema declares the expected result fields of the tool
+// Results (maps or structs) are JSON-normalized and validated against the schema in Execute
+func (t *Tool) WithResultSchema(fields ...ToolParameter) *Tool {
+	t.ResultSchema = fields
+	return t
+}
+
+// ParametersSchema returns the JSON schema of the tool's parameters exactly as providers
+// send it: an object with each parameter's declared type, description and enum
+func (t *Tool) ParametersSchema() map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for _, param := range t.Parameters {
+		prop := map[string]any{
+			"type":        param.Type,
+			"description": param.Description,
+		}
+		if len(param.Enum) > 0 {
+			prop["enum"] = param.Enum
+		}
+		properties[param.Name] = prop
+
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// FunctionSchema returns the function definition sent to OpenAI-compatible providers:
+// {"type": "function", "function": {"name", "description", "parameters"}}. Print it as
+// JSON to debug why a model won't call the tool.
+func (t *Tool) FunctionSchema() map[string]any {
+	return map[string]any{
+		"type": "function",
+		"function": map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"parameters":  t.ParametersSchema(),
+		},
+	}
+}
+
 // normalizeParamType maps type synonyms to canonical types
 func normalizeParamType(t string) ParamType {
 	switch strings.ToLower(t) {
@@ -110,8 +162,13 @@ func normalizeParamType(t string) ParamType {
 
 // Validate validates the arguments against the tool's parameters
 func (t *Tool) Validate(args map[string]any) error {
+	return t.validateValues(t.Parameters, args)
+}
+
+// validateValues validates values against a parameter list
+func (t *Tool) validateValues(params []ToolParameter, args map[string]any) error {
 	// Check required parameters are present
-	for _, param := range t.Parameters {
+	for _, param := range params {
 		if param.Required {
 			if _, exists := args[param.Name]; !exists {
 				return fmt.Errorf("missing required parameter: %s", param.Name)
@@ -120,7 +177,7 @@ func (t *Tool) Validate(args map[string]any) error {
 	}
 
 	// Validate type for each parameter
-	for _, param := range t.Parameters {
+	for _, param := range params {
 		val, exists := args[param.Name]
 		if !exists {
 			continue // Skip optional parameters
@@ -209,18 +266,51 @@ func (t *Tool) Execute(ctx context.Context, args map[string]any) (any, error) {
 		return nil, fmt.Errorf("argument validation failed: %w", err)
 	}
 
-	return t.Function(ctx, normalizedArgs)
+	result, err := t.Function(ctx, normalizedArgs)
+	if err != nil || len(t.ResultSchema) == 0 {
+		return result, err
+	}
+
+	validated, err := t.validateResult(result)
+	if err != nil {
+		return nil, fmt.Errorf("result validation failed: %w", err)
+	}
+	return validated, nil
+}
+
+// validateResult normalizes a result to a JSON object and validates it against ResultSchema
+func (t *Tool) validateResult(result any) (map[string]any, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("result is not JSON-serializable: %w", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected an object result, got %T", result)
+	}
+
+	normalized := t.normalizeValues(t.ResultSchema, fields)
+	if err := t.validateValues(t.ResultSchema, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
 }
 
 // normalizeArguments converts arguments to match their expected parameter types
 // Supports: string, int, float, bool, json, array
 func (t *Tool) normalizeArguments(args map[string]any) map[string]any {
+	return t.normalizeValues(t.Parameters, args)
+}
+
+// normalizeValues converts values to match the types of a parameter list
+func (t *Tool) normalizeValues(params []ToolParameter, args map[string]any) map[string]any {
 	normalized := make(map[string]any)
 
 	for key, value := range args {
 		// Find the parameter definition
 		var paramType ParamType
-		for _, param := range t.Parameters {
+		for _, param := range params {
 			if param.Name == key {
 				paramType = normalizeParamType(param.Type)
 				break
@@ -464,3 +554,28 @@ func (t *Tool) normalizeToArray(value any) any {
 		return value
 	}
 }
+
+// FormatToolResult renders a tool result as the observation text fed back to the model
+// Strings are used verbatim; maps, slices and structs are JSON-marshaled for consistent output
+func FormatToolResult(result any) string {
+	switch v := result.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(result)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if data, err := json.Marshal(result); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", result)
+}
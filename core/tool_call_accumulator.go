@@ -0,0 +1,76 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/assagman/dsgo/internal/jsonutil"
+)
+
+// ToolCallAccumulator assembles streamed tool calls from their deltas
+// Providers stream each call's arguments as JSON fragments keyed by the call's index;
+// the accumulator buffers them so a tool is never dispatched with truncated arguments.
+type ToolCallAccumulator struct {
+	calls   []*pendingToolCall // In order of first appearance
+	byIndex map[int]*pendingToolCall
+}
+
+type pendingToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// NewToolCallAccumulator creates an empty accumulator
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return &ToolCallAccumulator{byIndex: make(map[int]*pendingToolCall)}
+}
+
+// Add records one delta for the call at index
+// The ID and name are usually only sent with the first delta; arguments are appended.
+func (a *ToolCallAccumulator) Add(index int, id, name, arguments string) {
+	call, ok := a.byIndex[index]
+	if !ok {
+		call = &pendingToolCall{}
+		a.byIndex[index] = call
+		a.calls = append(a.calls, call)
+	}
+	if id != "" {
+		call.id = id
+	}
+	if name != "" {
+		call.name = name
+	}
+	call.arguments.WriteString(arguments)
+}
+
+// Pending reports whether any tool call deltas are buffered
+func (a *ToolCallAccumulator) Pending() bool {
+	return len(a.calls) > 0
+}
+
+// Flush parses the buffered calls into complete ToolCalls and resets the accumulator
+// Call it once the stream signals the calls are complete (a finish reason or end of stream).
+func (a *ToolCallAccumulator) Flush() ([]ToolCall, error) {
+	calls := a.calls
+	a.calls = nil
+	a.byIndex = make(map[int]*pendingToolCall)
+
+	result := make([]ToolCall, 0, len(calls))
+	for _, call := range calls {
+		args := map[string]any{}
+		// Zero-parameter tools may stream no arguments at all
+		if raw := strings.TrimSpace(call.arguments.String()); raw != "" {
+			if err := json.Unmarshal([]byte(jsonutil.RepairJSON(raw)), &args); err != nil {
+				return nil, fmt.Errorf("failed to parse streamed arguments for tool %q: %w", call.name, err)
+			}
+		}
+		result = append(result, ToolCall{
+			ID:        call.id,
+			Name:      call.name,
+			Arguments: args,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolCallAccumulator_AssemblesFragments(t *testing.T) {
+	acc := NewToolCallAccumulator()
+	acc.Add(0, "call_1", "search", `{"que`)
+	acc.Add(1, "call_2", "clock", "")
+	acc.Add(0, "", "", `ry": "go `)
+	acc.Add(0, "", "", `channels", "limit": 3}`)
+
+	if !acc.Pending() {
+		t.Fatal("expected pending calls")
+	}
+
+	calls, err := acc.Flush()
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls, want 2", len(calls))
+	}
+	if calls[0].ID != "call_1" || calls[0].Name != "search" {
+		t.Errorf("first call = %+v", calls[0])
+	}
+	if calls[0].Arguments["query"] != "go channels" || calls[0].Arguments["limit"] != float64(3) {
+		t.Errorf("first call arguments = %v", calls[0].Arguments)
+	}
+	if calls[1].Name != "clock" || len(calls[1].Arguments) != 0 {
+		t.Errorf("zero-argument call = %+v", calls[1])
+	}
+	if acc.Pending() {
+		t.Error("Flush() should reset the accumulator")
+	}
+}
+
+func TestToolCallAccumulator_InvalidArguments(t *testing.T) {
+	acc := NewToolCallAccumulator()
+	acc.Add(0, "call_1", "search", `not json at all`)
+
+	_, err := acc.Flush()
+	if err == nil || !strings.Contains(err.Error(), `"search"`) {
+		t.Errorf("Flush() error = %v, want parse error naming the tool", err)
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// toolLMKey is the context key for the LM of the agent executing a tool
+type toolLMKey struct{}
+
+// ToolUsage accumulates the usage of LM calls a tool makes through LMFromContext
+type ToolUsage struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Total returns the usage recorded so far
+func (u *ToolUsage) Total() Usage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.usage
+}
+
+func (u *ToolUsage) add(usage Usage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.Add(usage)
+}
+
+// WithToolLM returns ctx for executing a tool on behalf of an agent running on lm with
+// options, so the tool can call LMFromContext and OptionsFromContext. The returned
+// ToolUsage collects the usage of those calls for the agent to account for.
+// Request ID, tags, option overrides and deadline already travel on ctx.
+func WithToolLM(ctx context.Context, lm LM, options *GenerateOptions) (context.Context, *ToolUsage) {
+	usage := &ToolUsage{}
+	return context.WithValue(ctx, toolLMKey{}, &toolLM{lm: lm, options: options.Copy(), usage: usage}), usage
+}
+
+// LMFromContext returns the LM of the agent executing the current tool, so the tool's
+// own LM calls use the same model and wrappers (cache, collector, rate limit) and count
+// toward the agent's usage. Calls with nil options use the agent's options (see
+// OptionsFromContext). It returns false outside a tool call.
+func LMFromContext(ctx context.Context) (LM, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	lm, ok := ctx.Value(toolLMKey{}).(*toolLM)
+	return lm, ok
+}
+
+// OptionsFromContext returns a copy of the generate options of the agent executing the
+// current tool (temperature, reasoning effort, ...), or DefaultGenerateOptions outside a
+// tool call. Pass them to a nested module with WithOptions to keep it consistent.
+func OptionsFromContext(ctx context.Context) *GenerateOptions {
+	if ctx != nil {
+		if lm, ok := ctx.Value(toolLMKey{}).(*toolLM); ok && lm.options != nil {
+			return lm.options.Copy()
+		}
+	}
+	return DefaultGenerateOptions()
+}
+
+// toolLM is the agent's LM as seen by a tool: it fills in the agent's options and
+// records usage
+type toolLM struct {
+	lm      LM
+	options *GenerateOptions
+	usage   *ToolUsage
+}
+
+// Generate calls the agent's LM, with the agent's options when options is nil
+func (t *toolLM) Generate(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+	if options == nil {
+		options = t.options.Copy()
+	}
+	result, err := t.lm.Generate(ctx, messages, options)
+	if result != nil {
+		t.usage.add(result.Usage)
+	}
+	return result, err
+}
+
+// Stream records the usage reported by the stream's chunks
+func (t *toolLM) Stream(ctx context.Context, messages []Message, options *GenerateOptions) (<-chan Chunk, <-chan error) {
+	if options == nil {
+		options = t.options.Copy()
+	}
+	in, errs := t.lm.Stream(ctx, messages, options)
+	out := make(chan Chunk)
+	go func() {
+		defer close(out)
+		var usage Usage
+		defer func() { t.usage.add(usage) }()
+		for chunk := range in {
+			if chunk.Usage != (Usage{}) {
+				usage = chunk.Usage
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs
+}
+
+// Name returns the agent LM's name
+func (t *toolLM) Name() string {
+	return t.lm.Name()
+}
+
+// SupportsJSON reports whether the agent's LM supports JSON mode
+func (t *toolLM) SupportsJSON() bool {
+	return t.lm.SupportsJSON()
+}
+
+// SupportsTools reports whether the agent's LM supports tool calling
+func (t *toolLM) SupportsTools() bool {
+	return t.lm.SupportsTools()
+}
+
+// Capabilities reports the agent LM's capabilities
+func (t *toolLM) Capabilities() Capabilities {
+	return CapabilitiesOf(t.lm)
+}
+
+// HealthCheck checks the agent's LM
+func (t *toolLM) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, t.lm)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLMFromContext(t *testing.T) {
+	if _, ok := LMFromContext(context.Background()); ok {
+		t.Error("LMFromContext() should find no LM outside a tool call")
+	}
+	if options := OptionsFromContext(context.Background()); options.Temperature != DefaultGenerateOptions().Temperature {
+		t.Errorf("OptionsFromContext() = %+v, want the defaults outside a tool call", options)
+	}
+
+	var received *GenerateOptions
+	base := &mockWrapperLM{name: "agent-model", generateFunc: func(ctx context.Context, messages []Message, options *GenerateOptions) (*GenerateResult, error) {
+		received = options
+		return &GenerateResult{Content: "ok", Usage: Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}}, nil
+	}}
+	agentOptions := DefaultGenerateOptions()
+	agentOptions.Temperature = 0.2
+	ctx, usage := WithToolLM(context.Background(), base, agentOptions)
+	agentOptions.Temperature = 0.9 // later changes to the agent's options don't leak into the tool
+
+	lm, ok := LMFromContext(ctx)
+	if !ok || lm.Name() != "agent-model" {
+		t.Fatalf("LMFromContext() = %v, %v, want the agent's LM", lm, ok)
+	}
+	if _, err := lm.Generate(ctx, []Message{{Role: "user", Content: "hi"}}, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if received == nil || received.Temperature != 0.2 {
+		t.Errorf("Generate() with nil options sent %+v, want the agent's options", received)
+	}
+
+	own := DefaultGenerateOptions()
+	own.Temperature = 0
+	if _, err := lm.Generate(ctx, []Message{{Role: "user", Content: "hi"}}, own); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if received.Temperature != 0 {
+		t.Errorf("Generate() sent temperature %v, want the tool's own options", received.Temperature)
+	}
+
+	if total := usage.Total(); total.TotalTokens != 10 {
+		t.Errorf("ToolUsage.Total() = %+v, want both calls counted", total)
+	}
+	if options := OptionsFromContext(ctx); options.Temperature != 0.2 {
+		t.Errorf("OptionsFromContext() temperature = %v, want 0.2", options.Temperature)
+	}
+}
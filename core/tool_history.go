@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/assagman/dsgo/internal/ids"
+)
+
+// CollectToolCall records a tool execution that started at start with the collector
+// configured by WithCollector, if any. requestID links the entry to the module call
+// that ran the tool; result is the observation returned to the model. Collection is
+// best effort and never fails the tool call.
+func CollectToolCall(ctx context.Context, requestID string, call ToolCall, result string, err error, start time.Time) {
+	globalSettings.mu.RLock()
+	collector := globalSettings.Collector
+	globalSettings.mu.RUnlock()
+	if collector == nil {
+		return
+	}
+
+	entry := &HistoryEntry{
+		ID:        ids.NewUUID(),
+		Kind:      HistoryEntryKindTool,
+		Timestamp: start,
+		RequestID: requestID,
+		Tags:      TagsFromContext(ctx),
+		Tool: &ToolMeta{
+			Name:       call.Name,
+			CallID:     call.ID,
+			Arguments:  call.Arguments,
+			Result:     result,
+			DurationMs: time.Since(start).Milliseconds(),
+		},
+	}
+	if err != nil {
+		entry.Error = &ErrorMeta{
+			Message: err.Error(),
+			Type:    "tool_error",
+		}
+	}
+	_ = collector.Collect(entry)
+}
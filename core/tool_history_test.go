@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectToolCall(t *testing.T) {
+	ResetConfig()
+	defer ResetConfig()
+
+	call := ToolCall{ID: "call_1", Name: "search", Arguments: map[string]any{"query": "go"}}
+
+	// Without a collector the call is a no-op
+	CollectToolCall(context.Background(), "req-1", call, "result", nil, time.Now())
+
+	collector := NewMemoryCollector(10)
+	Configure(WithCollector(collector))
+	ctx := WithTags(context.Background(), map[string]string{"user_id": "u1"})
+
+	CollectToolCall(ctx, "req-1", call, "3 results", nil, time.Now().Add(-20*time.Millisecond))
+	CollectToolCall(ctx, "req-1", call, "Error executing tool: boom", errors.New("boom"), time.Now())
+
+	entries := collector.GetAll()
+	if len(entries) != 2 {
+		t.Fatalf("collected %d entries, want 2", len(entries))
+	}
+	ok := entries[0]
+	if ok.Kind != HistoryEntryKindTool || ok.RequestID != "req-1" || ok.Tags["user_id"] != "u1" || ok.Error != nil {
+		t.Errorf("entry = %+v", ok)
+	}
+	if ok.Tool == nil || ok.Tool.Name != "search" || ok.Tool.CallID != "call_1" || ok.Tool.Result != "3 results" || ok.Tool.DurationMs < 20 {
+		t.Errorf("tool = %+v", ok.Tool)
+	}
+	if failed := entries[1]; failed.Error == nil || failed.Error.Type != "tool_error" || failed.Error.Message != "boom" {
+		t.Errorf("failed entry error = %+v", failed.Error)
+	}
+
+	data, err := json.Marshal(ok)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"kind":"tool"`, `"request_id":"req-1"`, `"name":"search"`, `"arguments":{"query":"go"}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s missing %s", data, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ToolRegistry is a catalog of tools shared across agents. Register tools once, then
+// compose each agent's toolset by name with Subset (or take every tool with Tools).
+// It is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string // Registration order, so toolsets are listed deterministically
+}
+
+// NewToolRegistry creates an empty tool registry
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]Tool)}
+}
+
+// Register adds tools to the registry. A tool registered under an existing name
+// replaces the previous one and keeps its position.
+func (r *ToolRegistry) Register(tools ...*Tool) *ToolRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tool := range tools {
+		if tool == nil || tool.Name == "" {
+			panic("Register: tool must have a name")
+		}
+		if _, exists := r.tools[tool.Name]; !exists {
+			r.order = append(r.order, tool.Name)
+		}
+		r.tools[tool.Name] = *tool
+	}
+	return r
+}
+
+// Get returns a copy of the tool registered under name
+func (r *ToolRegistry) Get(name string) (*Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	if !ok {
+		return nil, false
+	}
+	return &tool, true
+}
+
+// Names returns the registered tool names in registration order
+func (r *ToolRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// Tools returns every registered tool in registration order, ready for NewReAct
+func (r *ToolRegistry) Tools() []Tool {
+	return r.Subset(r.Names()...)
+}
+
+// Subset returns the named tools in the given order, ready for NewReAct.
+// It panics on a name that is not registered, as a toolset naming a missing
+// tool is a programming error.
+func (r *ToolRegistry) Subset(names ...string) []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, 0, len(names))
+	for _, name := range names {
+		tool, ok := r.tools[name]
+		if !ok {
+			panic(fmt.Sprintf("Subset: unknown tool %q", name))
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestToolRegistry(t *testing.T) {
+	search := NewTool("search", "Search the web", func(ctx context.Context, args map[string]any) (any, error) {
+		return "results", nil
+	}).AddParameter("query", "string", "Search query", true)
+	calc := NewTool("calculator", "Evaluate arithmetic", nil)
+	date := NewTool("date", "Current date", nil)
+
+	registry := NewToolRegistry().Register(search, calc, date)
+
+	got, ok := registry.Get("search")
+	if !ok || got.Name != "search" || len(got.Parameters) != 1 {
+		t.Fatalf("Get(search) = %+v, %v", got, ok)
+	}
+	if result, err := got.Execute(context.Background(), map[string]any{"query": "go"}); err != nil || result != "results" {
+		t.Errorf("Execute() = %v, %v", result, err)
+	}
+	if _, ok := registry.Get("missing"); ok {
+		t.Error("Get(missing) should report false")
+	}
+
+	subset := registry.Subset("date", "search")
+	if len(subset) != 2 || subset[0].Name != "date" || subset[1].Name != "search" {
+		t.Errorf("Subset() = %+v, want date and search in order", subset)
+	}
+
+	// Re-registering replaces the tool in place
+	registry.Register(NewTool("calculator", "Evaluate math", nil))
+	tools := registry.Tools()
+	if len(tools) != 3 || tools[1].Name != "calculator" || tools[1].Description != "Evaluate math" {
+		t.Errorf("Tools() = %+v, want the replaced calculator second", tools)
+	}
+}
+
+func TestToolRegistry_SubsetUnknownPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Subset() with an unknown name should panic")
+		}
+	}()
+	NewToolRegistry().Subset("missing")
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -1225,3 +1227,124 @@ func TestTool_NormalizeArguments_NumberCoercion(t *testing.T) {
 		})
 	}
 }
+
+func TestTool_Execute_ResultSchema(t *testing.T) {
+	type stats struct {
+		Mean  float64 `json:"mean"`
+		Count int     `json:"count"`
+	}
+
+	schema := []ToolParameter{
+		{Name: "mean", Type: "float", Required: true},
+		{Name: "count", Type: "int", Required: true},
+	}
+
+	tests := []struct {
+		name      string
+		result    any
+		wantErr   string
+		wantCount int64
+	}{
+		{
+			name:      "struct result is normalized",
+			result:    stats{Mean: 2.5, Count: 4},
+			wantCount: 4,
+		},
+		{
+			name:      "map result with float count is coerced",
+			result:    map[string]any{"mean": 2.5, "count": 4.0},
+			wantCount: 4,
+		},
+		{
+			name:    "missing field",
+			result:  map[string]any{"mean": 2.5},
+			wantErr: "missing required parameter: count",
+		},
+		{
+			name:    "wrong type",
+			result:  map[string]any{"mean": "high", "count": 4},
+			wantErr: "mean has invalid type",
+		},
+		{
+			name:    "non-object result",
+			result:  "mean is 2.5",
+			wantErr: "expected an object result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := NewTool("stats", "Compute stats", func(ctx context.Context, args map[string]any) (any, error) {
+				return tt.result, nil
+			}).WithResultSchema(schema...)
+
+			result, err := tool.Execute(context.Background(), map[string]any{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if !strings.Contains(err.Error(), "result validation failed") {
+					t.Errorf("expected result validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			fields, ok := result.(map[string]any)
+			if !ok {
+				t.Fatalf("expected normalized map result, got %T", result)
+			}
+			if fields["count"] != tt.wantCount || fields["mean"] != 2.5 {
+				t.Errorf("unexpected normalized result: %#v", fields)
+			}
+		})
+	}
+}
+
+func TestTool_Execute_ResultSchema_ToolErrorPassesThrough(t *testing.T) {
+	toolErr := errors.New("boom")
+	tool := NewTool("stats", "Compute stats", func(ctx context.Context, args map[string]any) (any, error) {
+		return nil, toolErr
+	}).WithResultSchema(ToolParameter{Name: "mean", Type: "float", Required: true})
+
+	if _, err := tool.Execute(context.Background(), map[string]any{}); !errors.Is(err, toolErr) {
+		t.Errorf("expected tool error to pass through, got %v", err)
+	}
+}
+
+type stringerResult struct{}
+
+func (stringerResult) String() string { return "custom" }
+
+func TestFormatToolResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"string", "plain text", "plain text"},
+		{"bytes", []byte("raw"), "raw"},
+		{"error", errors.New("failed"), "failed"},
+		{"stringer", stringerResult{}, "custom"},
+		{"number", 42, "42"},
+		{"map", map[string]any{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{"slice", []string{"x", "y"}, `["x","y"]`},
+		{"struct", struct {
+			Name string `json:"name"`
+		}{"dsgo"}, `{"name":"dsgo"}`},
+		{"struct pointer", &struct {
+			Rows int `json:"rows"`
+		}{3}, `{"rows":3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatToolResult(tt.result); got != tt.want {
+				t.Errorf("FormatToolResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Package dsgo is the batteries-included distribution with all standard providers.
-// It imports dsgo/core and automatically registers all built-in providers (OpenAI, OpenRouter).
+// It imports dsgo/core and automatically registers all built-in providers (OpenAI, OpenRouter, Bedrock).
 //
 // For minimal dependencies, use github.com/assagman/dsgo/core directly.
 package dsgo
@@ -9,6 +9,7 @@ import (
 	"github.com/assagman/dsgo/internal/env"
 
 	// Import all standard providers to trigger their init() registration
+	_ "github.com/assagman/dsgo/providers/bedrock"
 	_ "github.com/assagman/dsgo/providers/openai"
 	_ "github.com/assagman/dsgo/providers/openrouter"
 )
@@ -21,59 +22,221 @@ func init() {
 
 // Re-export all core types
 type (
-	LM                    = core.LM
-	Message               = core.Message
-	GenerateOptions       = core.GenerateOptions
-	GenerateResult        = core.GenerateResult
-	Field                 = core.Field
-	Signature             = core.Signature
-	Prediction            = core.Prediction
-	History               = core.History
-	HistoryEntry          = core.HistoryEntry
-	Example               = core.Example
-	Tool                  = core.Tool
-	ToolCall              = core.ToolCall
-	Settings              = core.Settings
-	Option                = core.Option
-	Collector             = core.Collector
-	Cache                 = core.Cache
-	ValidationDiagnostics = core.ValidationDiagnostics
-	Module                = core.Module
-	Adapter               = core.Adapter
-	Chunk                 = core.Chunk
-	Usage                 = core.Usage
-	LMFactory             = core.LMFactory
+	LM                         = core.LM
+	Message                    = core.Message
+	GenerateOptions            = core.GenerateOptions
+	GenerateResult             = core.GenerateResult
+	Field                      = core.Field
+	Signature                  = core.Signature
+	Prediction                 = core.Prediction
+	History                    = core.History
+	HistoryEntry               = core.HistoryEntry
+	ToolMeta                   = core.ToolMeta
+	ToolUsage                  = core.ToolUsage
+	Example                    = core.Example
+	Tool                       = core.Tool
+	ToolRegistry               = core.ToolRegistry
+	ToolParameter              = core.ToolParameter
+	ToolCall                   = core.ToolCall
+	FieldDiff                  = core.FieldDiff
+	DiffChange                 = core.DiffChange
+	DiffOptions                = core.DiffOptions
+	DiffOption                 = core.DiffOption
+	Settings                   = core.Settings
+	Option                     = core.Option
+	Collector                  = core.Collector
+	MemoryCollector            = core.MemoryCollector
+	FileCollector              = core.FileCollector
+	CompositeCollector         = core.CompositeCollector
+	Cache                      = core.Cache
+	ValidationDiagnostics      = core.ValidationDiagnostics
+	Module                     = core.Module
+	Adapter                    = core.Adapter
+	Chunk                      = core.Chunk
+	ToolCallAccumulator        = core.ToolCallAccumulator
+	StreamingReasoningSplitter = core.StreamingReasoningSplitter
+	Usage                      = core.Usage
+	LMFactory                  = core.LMFactory
+	RateLimiter                = core.RateLimiter
+	FallbackLM                 = core.FallbackLM
+	ImageContent               = core.ImageContent
+	TruncationPolicy           = core.TruncationPolicy
+	DemoDropOrder              = core.DemoDropOrder
+	AssemblyReport             = core.AssemblyReport
+	InstructionPlacement       = core.InstructionPlacement
+	LMFunc                     = core.LMFunc
+	Middleware                 = core.Middleware
+	CircuitBreaker             = core.CircuitBreaker
+	HedgedLM                   = core.HedgedLM
+	DedupLM                    = core.DedupLM
+	RefusalRetryLM             = core.RefusalRetryLM
+	CircuitConfig              = core.CircuitConfig
+	CircuitState               = core.CircuitState
+	BatchRequest               = core.BatchRequest
+	BatchResult                = core.BatchResult
+	BatchLM                    = core.BatchLM
+	Capabilities               = core.Capabilities
+	RawExchange                = core.RawExchange
+	CapableLM                  = core.CapableLM
+	HealthCheckLM              = core.HealthCheckLM
+	ModelPricing               = core.ModelPricing
+	APIError                   = core.APIError
+	RateLimitError             = core.RateLimitError
+	AuthError                  = core.AuthError
+	ParseError                 = core.ParseError
+	ContentFilterError         = core.ContentFilterError
+	ResponseTooLargeError      = core.ResponseTooLargeError
+	EmptyResponseError         = core.EmptyResponseError
+	KeyPool                    = core.KeyPool
+	KeyStrategy                = core.KeyStrategy
+	BackoffConfig              = core.BackoffConfig
+	Embedder                   = core.Embedder
+	EmbedderFunc               = core.EmbedderFunc
+	DemoSelector               = core.DemoSelector
+	KNNDemoSelector            = core.KNNDemoSelector
+	Tokenizer                  = core.Tokenizer
+	HeuristicTokenizer         = core.HeuristicTokenizer
+	TiktokenTokenizer          = core.TiktokenTokenizer
+	TokenEncoder               = core.TokenEncoder
+	EnumMatching               = core.EnumMatching
+	MockLM                     = core.MockLM
+	MockRule                   = core.MockRule
+	MockResponse               = core.MockResponse
+	PromptMatcher              = core.PromptMatcher
 )
 
 // Re-export all functions
 var (
-	NewLM               = core.NewLM
-	NewSignature        = core.NewSignature
-	NewPrediction       = core.NewPrediction
-	NewHistory          = core.NewHistory
-	NewHistoryWithLimit = core.NewHistoryWithLimit
-	NewExample          = core.NewExample
-	NewTool             = core.NewTool
-	Configure           = core.Configure
-	GetSettings         = core.GetSettings
-	ResetConfig         = core.ResetConfig
-	WithProvider        = core.WithProvider
-	WithModel           = core.WithModel
-	WithTimeout         = core.WithTimeout
-	WithLM              = core.WithLM
-	WithAPIKey          = core.WithAPIKey
-	WithMaxRetries      = core.WithMaxRetries
-	WithTracing         = core.WithTracing
-	WithCollector       = core.WithCollector
-	WithCache           = core.WithCache
-	WithCacheTTL        = core.WithCacheTTL
-	GenerateCacheKey    = core.GenerateCacheKey
-	NewFallbackAdapter  = core.NewFallbackAdapter
-	NewJSONAdapter      = core.NewJSONAdapter
-	NewChatAdapter      = core.NewChatAdapter
-	NewTwoStepAdapter   = core.NewTwoStepAdapter
-	RegisterLM          = core.RegisterLM
-	NewLMWrapper        = core.NewLMWrapper
+	NewLM                         = core.NewLM
+	NewSignature                  = core.NewSignature
+	NewPrediction                 = core.NewPrediction
+	DiffPredictions               = core.DiffPredictions
+	DiffFloatTolerance            = core.DiffFloatTolerance
+	DiffStringSimilarity          = core.DiffStringSimilarity
+	DiffIgnoreFields              = core.DiffIgnoreFields
+	DefaultGenerateOptions        = core.DefaultGenerateOptions
+	NewToolCallAccumulator        = core.NewToolCallAccumulator
+	NewHistory                    = core.NewHistory
+	NewHistoryWithLimit           = core.NewHistoryWithLimit
+	LoadHistory                   = core.LoadHistory
+	NewExample                    = core.NewExample
+	NewTool                       = core.NewTool
+	NewToolRegistry               = core.NewToolRegistry
+	WithToolLM                    = core.WithToolLM
+	LMFromContext                 = core.LMFromContext
+	OptionsFromContext            = core.OptionsFromContext
+	FormatToolResult              = core.FormatToolResult
+	Configure                     = core.Configure
+	GetSettings                   = core.GetSettings
+	ResetConfig                   = core.ResetConfig
+	WithProvider                  = core.WithProvider
+	WithModel                     = core.WithModel
+	WithTimeout                   = core.WithTimeout
+	WithLM                        = core.WithLM
+	WithAPIKey                    = core.WithAPIKey
+	WithMaxRetries                = core.WithMaxRetries
+	WithBackoff                   = core.WithBackoff
+	WithTracing                   = core.WithTracing
+	WithCollector                 = core.WithCollector
+	NewMemoryCollector            = core.NewMemoryCollector
+	NewFileCollector              = core.NewFileCollector
+	NewCompositeCollector         = core.NewCompositeCollector
+	WithShutdownContext           = core.WithShutdownContext
+	Shutdown                      = core.Shutdown
+	WithCache                     = core.WithCache
+	WithCacheTTL                  = core.WithCacheTTL
+	GenerateCacheKey              = core.GenerateCacheKey
+	NewFallbackAdapter            = core.NewFallbackAdapter
+	DefaultAdapterFor             = core.DefaultAdapterFor
+	UsesJSONMode                  = core.UsesJSONMode
+	NewJSONAdapter                = core.NewJSONAdapter
+	NewChatAdapter                = core.NewChatAdapter
+	SplitReasoning                = core.SplitReasoning
+	NewStreamingReasoningSplitter = core.NewStreamingReasoningSplitter
+	CoalesceChunks                = core.CoalesceChunks
+	NewTwoStepAdapter             = core.NewTwoStepAdapter
+	RegisterLM                    = core.RegisterLM
+	RegisterModelAlias            = core.RegisterModelAlias
+	ResolveModel                  = core.ResolveModel
+	NewLMWrapper                  = core.NewLMWrapper
+	WithRateLimit                 = core.WithRateLimit
+	WithAdaptiveRateLimit         = core.WithAdaptiveRateLimit
+	NewRateLimiter                = core.NewRateLimiter
+	NewRateLimitedLM              = core.NewRateLimitedLM
+	NewFallbackLM                 = core.NewFallbackLM
+	NewCircuitBreaker             = core.NewCircuitBreaker
+	NewHedgedLM                   = core.NewHedgedLM
+	WithHedging                   = core.WithHedging
+	NewDedupLM                    = core.NewDedupLM
+	WithRequestDeduplication      = core.WithRequestDeduplication
+	NewRefusalRetryLM             = core.NewRefusalRetryLM
+	WithRefusalRetry              = core.WithRefusalRetry
+	IsRefusal                     = core.IsRefusal
+	WithTraceOnError              = core.WithTraceOnError
+	WithRawResponseCapture        = core.WithRawResponseCapture
+	CaptureRawExchange            = core.CaptureRawExchange
+	BatchGenerate                 = core.BatchGenerate
+	NewImageFromURL               = core.NewImageFromURL
+	NewImageFromBytes             = core.NewImageFromBytes
+	RegisterVisionModel           = core.RegisterVisionModel
+	CapabilitiesOf                = core.CapabilitiesOf
+	HealthCheck                   = core.HealthCheck
+	WithContextWindow             = core.WithContextWindow
+	WithTruncationPolicy          = core.WithTruncationPolicy
+	WithDemoDropOrder             = core.WithDemoDropOrder
+	RegisterContextWindow         = core.RegisterContextWindow
+	WithMiddleware                = core.WithMiddleware
+	NewMiddlewareLM               = core.NewMiddlewareLM
+	EstimateTokens                = core.EstimateTokens
+	EstimateMessageTokens         = core.EstimateMessageTokens
+	WithTags                      = core.WithTags
+	TagsFromContext               = core.TagsFromContext
+	WithOptionOverride            = core.WithOptionOverride
+	WithCallAdapter               = core.WithCallAdapter
+	WithDryRun                    = core.WithDryRun
+	WithDryRunOutput              = core.WithDryRunOutput
+	WithCallDryRun                = core.WithCallDryRun
+	IsDryRun                      = core.IsDryRun
+	WithAPIKeys                   = core.WithAPIKeys
+	NewKeyPool                    = core.NewKeyPool
+	WithFeedback                  = core.WithFeedback
+	FeedbackFromContext           = core.FeedbackFromContext
+	RegisterPricing               = core.RegisterPricing
+	WithPricing                   = core.WithPricing
+	WithRegion                    = core.WithRegion
+	WithHTTPClient                = core.WithHTTPClient
+	WithProviderHTTPClient        = core.WithProviderHTTPClient
+	WithMaxResponseBytes          = core.WithMaxResponseBytes
+	WithRequestHeaders            = core.WithRequestHeaders
+	WithRequestMetadata           = core.WithRequestMetadata
+	WithCallRequestHeaders        = core.WithCallRequestHeaders
+	WithCallRequestMetadata       = core.WithCallRequestMetadata
+	WithBaseURL                   = core.WithBaseURL
+	WithProviderBaseURL           = core.WithProviderBaseURL
+	WithGlobalInstructionPrefix   = core.WithGlobalInstructionPrefix
+	CalculateCost                 = core.CalculateCost
+	NewAPIError                   = core.NewAPIError
+	StatusCode                    = core.StatusCode
+	NewEmbedder                   = core.NewEmbedder
+	RegisterEmbedder              = core.RegisterEmbedder
+	NewKNNDemoSelector            = core.NewKNNDemoSelector
+	CosineSimilarity              = core.CosineSimilarity
+	SetTokenizer                  = core.SetTokenizer
+	GetTokenizer                  = core.GetTokenizer
+	CountTokens                   = core.CountTokens
+	CountMessageTokens            = core.CountMessageTokens
+	NewTiktokenTokenizer          = core.NewTiktokenTokenizer
+	LoadTiktokenTokenizer         = core.LoadTiktokenTokenizer
+	NewMockLM                     = core.NewMockLM
+	PromptContains                = core.PromptContains
+
+	ErrContextWindowExceeded = core.ErrContextWindowExceeded
+	ErrCircuitOpen           = core.ErrCircuitOpen
+	ErrToolsUnsupported      = core.ErrToolsUnsupported
+	ErrContentFiltered       = core.ErrContentFiltered
+	ErrModelNotFound         = core.ErrModelNotFound
+	ErrResponseTooLarge      = core.ErrResponseTooLarge
+	ErrEmptyResponse         = core.ErrEmptyResponse
 )
 
 // Re-export constants
@@ -84,4 +247,41 @@ const (
 	FieldTypeBool   = core.FieldTypeBool
 	FieldTypeClass  = core.FieldTypeClass
 	FieldTypeJSON   = core.FieldTypeJSON
+	FieldTypeImage  = core.FieldTypeImage
+
+	EnumFuzzy      = core.EnumFuzzy
+	EnumNormalized = core.EnumNormalized
+	EnumExact      = core.EnumExact
+
+	DropDemos       = core.DropDemos
+	DropHistory     = core.DropHistory
+	TruncationError = core.TruncationError
+
+	DropLastDemoFirst     = core.DropLastDemoFirst
+	DropFirstDemoFirst    = core.DropFirstDemoFirst
+	DropLowestWeightFirst = core.DropLowestWeightFirst
+
+	InstructionSystem = core.InstructionSystem
+	InstructionUser   = core.InstructionUser
+
+	DefaultReasoningField = core.DefaultReasoningField
+
+	CircuitClosed   = core.CircuitClosed
+	CircuitOpen     = core.CircuitOpen
+	CircuitHalfOpen = core.CircuitHalfOpen
+
+	KeyRoundRobin           = core.KeyRoundRobin
+	KeyLeastRecentlyLimited = core.KeyLeastRecentlyLimited
+
+	FinishReasonDryRun = core.FinishReasonDryRun
+
+	ReasoningEffortLow    = core.ReasoningEffortLow
+	ReasoningEffortMedium = core.ReasoningEffortMedium
+	ReasoningEffortHigh   = core.ReasoningEffortHigh
+
+	HistoryEntryKindTool = core.HistoryEntryKindTool
+
+	DiffChanged = core.DiffChanged
+	DiffAdded   = core.DiffAdded
+	DiffRemoved = core.DiffRemoved
 )
@@ -0,0 +1,35 @@
+package dsgo_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/assagman/dsgo"
+	"github.com/assagman/dsgo/module"
+)
+
+func ExampleNewMockLM() {
+	lm := dsgo.NewMockLM().
+		OnPrompt(dsgo.PromptContains("weather")).Respond(`{"answer": "sunny"}`).
+		RespondSequence(
+			dsgo.MockResponse{Content: `{"answer": "42"}`},
+		)
+
+	sig := dsgo.NewSignature("Answer the question").
+		AddInput("question", dsgo.FieldTypeString, "").
+		AddOutput("answer", dsgo.FieldTypeString, "")
+	predict := module.NewPredict(sig, lm)
+
+	for _, question := range []string{"What's the weather?", "What is six times seven?"} {
+		prediction, err := predict.Forward(context.Background(), map[string]any{"question": question})
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		answer, _ := prediction.GetString("answer")
+		fmt.Println(answer)
+	}
+	// Output:
+	// sunny
+	// 42
+}
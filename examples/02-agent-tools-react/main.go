@@ -112,7 +112,9 @@ func main() {
 		},
 	).AddParameter("city", "string", "City name", true)
 
-	tools := []dsgo.Tool{*searchTool, *currencyTool, *timezoneTool}
+	// A registry keeps one catalog of tools; each agent picks its toolset by name
+	registry := dsgo.NewToolRegistry().Register(searchTool, currencyTool, timezoneTool)
+	tools := registry.Subset("search", "convert_currency", "local_time")
 
 	// Setup ReAct agent
 	model := os.Getenv("EXAMPLES_DEFAULT_MODEL")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strings"
 
@@ -103,37 +104,18 @@ func main() {
 		AddInput("tone", dsgo.FieldTypeString, "Desired tone").
 		AddOutput("opening", dsgo.FieldTypeString, "Opening paragraph")
 
-	// Custom scorer: prefer shorter, direct openings
-	scorer := func(inputs map[string]interface{}, pred *dsgo.Prediction) (float64, error) {
-		opening, ok := pred.GetString("opening")
-		if !ok {
-			return 0.0, nil
-		}
-		words := len(strings.Fields(opening))
-
-		// Penalize overly long openings
-		lengthScore := 1.0
-		if words > 50 {
-			lengthScore = 0.5
-		} else if words > 30 {
-			lengthScore = 0.8
-		}
-
-		// Reward directness (check for key phrases)
-		directnessScore := 0.5
-		lowerOpening := strings.ToLower(opening)
-		if strings.Contains(lowerOpening, "review") && strings.Contains(lowerOpening, "feedback") {
-			directnessScore = 1.0
-		}
-
-		return (lengthScore + directnessScore) / 2.0, nil
-	}
+	// Scorer: prefer openings of about 30 words that mention the review and feedback
+	scorer := module.CombineScorers(
+		module.WeightedScorer{Scorer: module.LengthScorer("opening", 30, 0.02), Weight: 0.5},
+		module.WeightedScorer{Scorer: module.ContainsScorer("opening", "review", "feedback"), Weight: 0.5},
+	)
 
 	openingPredict := module.NewPredict(openingSig, lm)
 	bestof := module.NewBestOfN(openingPredict, 5).
 		WithScorer(scorer).
-		WithThreshold(0.85). // Early-stop if score >= 0.85
-		WithReturnAll(true)  // Return all candidates for analysis
+		WithThreshold(0.85).            // Early-stop if score >= 0.85
+		WithTemperatureRange(0.3, 1.1). // Spread candidates for diversity
+		WithReturnAll(true)             // Return all candidates for analysis
 
 	bestofResult, err := bestof.Forward(stepBCtx, map[string]interface{}{
 		"outline": outline,
@@ -148,7 +130,11 @@ func main() {
 	stoppedEarly := candidateCount < 5
 
 	fmt.Printf("Generated %d candidate(s), stopped early: %v\n", candidateCount, stoppedEarly)
-	fmt.Printf("Best score: %.2f\n\n", bestofResult.Score)
+	fmt.Printf("Best score: %.2f\n", bestofResult.Score)
+	if runnerUp, ok := bestofResult.GetCandidate(1); ok {
+		fmt.Printf("Runner-up score: %.2f\n", runnerUp.Score)
+	}
+	fmt.Println()
 	fmt.Printf("Best opening:\n%s\n", opening)
 	usageB := bestofResult.Usage
 	fmt.Printf("Usage: Prompt %d tokens, Completion %d tokens\n", usageB.PromptTokens, usageB.CompletionTokens)
@@ -160,7 +146,7 @@ func main() {
 	fmt.Println("\n=== Step C: Refine for Tone (Refine) ===")
 	stepCCtx, stepCSpan := observe.Start(ctx, observe.SpanKindModule, "stepC_refine", map[string]interface{}{
 		"module":     "refine",
-		"iterations": 2,
+		"iterations": 3,
 	})
 
 	refineSig := dsgo.NewSignature("Refine email to match tone and style constraints").
@@ -168,8 +154,21 @@ func main() {
 		AddInput("constraints", dsgo.FieldTypeString, "Style constraints").
 		AddOutput("refined", dsgo.FieldTypeString, "Refined email")
 
+	// Score drafts on the "under 100 words" constraint so refinement stops once it is met
+	// and keeps the best draft instead of the last one
+	wordLimitScorer := func(inputs map[string]any, pred *dsgo.Prediction) (float64, error) {
+		refined, _ := pred.GetString("refined")
+		words := len(strings.Fields(refined))
+		if words == 0 {
+			return 0, nil
+		}
+		return math.Min(1, 100/float64(words)), nil
+	}
+
 	refine := module.NewRefine(refineSig, lm).
-		WithMaxIterations(2)
+		WithMaxIterations(3).
+		WithScorer(wordLimitScorer).
+		WithTargetScore(1)
 
 	draftEmail := opening + "\n\n[Body sections...]\n\nBest regards,\nAlex"
 
@@ -183,6 +182,7 @@ func main() {
 
 	refined, _ := refineResult.GetString("refined")
 	fmt.Printf("\nRefined email:\n%s\n", refined)
+	fmt.Printf("Iteration scores: %v (best: %.2f)\n", refineResult.Scores, refineResult.Score)
 	usageC := refineResult.Usage
 	fmt.Printf("Usage: Prompt %d tokens, Completion %d tokens\n", usageC.PromptTokens, usageC.CompletionTokens)
 	totalPromptTokens += usageC.PromptTokens
@@ -52,12 +52,13 @@ func main() {
 		AddOutput("code", dsgo.FieldTypeString, "Complete Python function with test calls").
 		AddOutput("explanation", dsgo.FieldTypeString, "Explanation of the algorithm")
 
+	// Budget output tokens per field: code generation needs room (minimax-m2 needs more)
 	pot := module.NewProgramOfThought(potSig, lm, "python").
 		WithAllowExecution(true). // Enable code execution
-		WithExecutionTimeout(10)  // 10 second safety timeout
-
-	// Increase max tokens for code generation (minimax-m2 needs more)
-	pot.Options.MaxTokens = 20000
+		WithExecutionTimeout(10). // 10 second safety timeout
+		WithMaxTokensPerField(map[string]int{"code": 16000, "explanation": 3000})
+	// Binary search needs little deliberation; high-effort defaults can time out
+	pot.Options.ReasoningEffort = dsgo.ReasoningEffortLow
 
 	planResult, err := pot.Forward(step1Ctx, map[string]interface{}{
 		"problem":    "Write a function binary_search that takes a sorted array and a target value, and returns the index of the target if found, or -1 if not found.",
@@ -110,39 +111,21 @@ func main() {
 	}
 
 	language, _ := constraintsResult.GetString("language")
-	testInputs, _ := constraintsResult.Get("test_inputs")
-	expectedOutputs, _ := constraintsResult.Get("expected_outputs")
+	var testInputs, expectedOutputs any
+	if err := constraintsResult.GetJSON("test_inputs", &testInputs); err != nil {
+		log.Fatal(err)
+	}
+	if err := constraintsResult.GetJSON("expected_outputs", &expectedOutputs); err != nil {
+		log.Fatal(err)
+	}
 
 	fmt.Printf("Test Cases:\n")
 	fmt.Printf("  Language: %s\n", language)
 
-	// Pretty print test inputs
-	if testInputsStr, ok := testInputs.(string); ok {
-		var testInputsParsed interface{}
-		if err := json.Unmarshal([]byte(testInputsStr), &testInputsParsed); err == nil {
-			testInputsJSON, _ := json.MarshalIndent(testInputsParsed, "    ", "  ")
-			fmt.Printf("  Test Inputs:\n%s\n", string(testInputsJSON))
-		} else {
-			fmt.Printf("  Test Inputs: %s\n", testInputsStr)
-		}
-	} else {
-		testInputsJSON, _ := json.MarshalIndent(testInputs, "    ", "  ")
-		fmt.Printf("  Test Inputs:\n%s\n", string(testInputsJSON))
-	}
-
-	// Pretty print expected outputs
-	if expectedOutputsStr, ok := expectedOutputs.(string); ok {
-		var expectedOutputsParsed interface{}
-		if err := json.Unmarshal([]byte(expectedOutputsStr), &expectedOutputsParsed); err == nil {
-			expectedOutputsJSON, _ := json.MarshalIndent(expectedOutputsParsed, "    ", "  ")
-			fmt.Printf("  Expected Outputs:\n%s\n", string(expectedOutputsJSON))
-		} else {
-			fmt.Printf("  Expected Outputs: %s\n", expectedOutputsStr)
-		}
-	} else {
-		expectedOutputsJSON, _ := json.MarshalIndent(expectedOutputs, "    ", "  ")
-		fmt.Printf("  Expected Outputs:\n%s\n", string(expectedOutputsJSON))
-	}
+	testInputsJSON, _ := json.MarshalIndent(testInputs, "    ", "  ")
+	fmt.Printf("  Test Inputs:\n%s\n", string(testInputsJSON))
+	expectedOutputsJSON, _ := json.MarshalIndent(expectedOutputs, "    ", "  ")
+	fmt.Printf("  Expected Outputs:\n%s\n", string(expectedOutputsJSON))
 	usage2 := constraintsResult.Usage
 	fmt.Printf("Usage: Prompt %d tokens, Completion %d tokens\n", usage2.PromptTokens, usage2.CompletionTokens)
 	totalPromptTokens += usage2.PromptTokens
@@ -181,11 +164,21 @@ func main() {
 
 	finalSolutionPredict := module.NewPredict(finalSolutionSig, lm)
 
-	// Create Program - testing pipeline
+	// Create Program - testing pipeline with explicit data mapping between steps
 	program := module.NewProgram("code_testing_pipeline").
-		AddModule(testRunnerPredict).
-		AddModule(testExecutionPredict).
-		AddModule(finalSolutionPredict)
+		AddStep("test_runner", testRunnerPredict, map[string]string{
+			"language":         "language",
+			"code":             "code",
+			"test_inputs":      "test_inputs",
+			"expected_outputs": "expected_outputs",
+		}).
+		AddStep("execute", testExecutionPredict, map[string]string{
+			"test_code": "test_runner.test_code",
+		}).
+		AddStep("finalize", finalSolutionPredict, map[string]string{
+			"code":         "code",
+			"test_results": "execute.test_results",
+		})
 
 	programInputs := map[string]interface{}{
 		"language":         language,
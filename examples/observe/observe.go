@@ -60,6 +60,7 @@ type CacheInfo struct {
 type Span struct {
 	id        string
 	parentID  string
+	parent    *Span
 	runID     string
 	kind      SpanKind
 	operation string
@@ -171,6 +172,7 @@ func Start(ctx context.Context, kind SpanKind, operation string, fields map[stri
 	span := &Span{
 		id:        generateID(),
 		parentID:  parentID,
+		parent:    parent,
 		runID:     runID,
 		kind:      kind,
 		operation: operation,
@@ -308,17 +310,13 @@ func (l *Logger) getSymbol(op, level string) string {
 	return "•"
 }
 
+// getDepth counts the span's ancestors. Spans started from a context that carries
+// a parent span (including in worker goroutines of parallel modules) nest under it.
 func (l *Logger) getDepth(span *Span) int {
 	depth := 0
-	parentID := span.parentID
-
-	// Simple depth calculation based on parent
-	for parentID != "" {
+	for p := span.parent; p != nil; p = p.parent {
 		depth++
-		// In a real implementation, track parent spans in a map
-		break
 	}
-
 	return depth
 }
 
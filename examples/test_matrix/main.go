@@ -17,6 +17,8 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/assagman/dsgo"
 )
 
 // Demonstrates: Comprehensive test matrix for all examples across multiple models
@@ -187,6 +189,9 @@ func main() {
 	}
 	fmt.Println()
 
+	// Fail fast on bad credentials or unknown models instead of discovering them mid-run
+	preflight(selectedModels)
+
 	start := time.Now()
 
 	// Circuit breaker with timeout
@@ -223,6 +228,26 @@ func main() {
 	}
 }
 
+// preflight health-checks every selected model and exits when one is unreachable
+func preflight(models []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var failed []string
+	for _, model := range models {
+		lm, err := dsgo.NewLM(ctx, model)
+		if err == nil {
+			err = dsgo.HealthCheck(ctx, lm)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", shortModel(model), err))
+		}
+	}
+	if len(failed) > 0 {
+		fatal("Preflight health check failed:\n  %s", strings.Join(failed, "\n  "))
+	}
+}
+
 func runTests(cb *breaker, root string, models, examples []string, timeout time.Duration, verbose bool, maxC int) []testResult {
 	var results []testResult
 	var mu sync.Mutex
@@ -415,6 +440,12 @@ func classifyError(r *testResult) {
 		case strings.Contains(lowerOut, "panic"):
 			r.errorType = "PANIC"
 			r.errorMsg = extractPanic(output)
+		case strings.Contains(lowerOut, "content filtered"):
+			r.errorType = "CONTENT_FILTER"
+			r.errorMsg = extractError(output)
+		case strings.Contains(lowerOut, "empty response"):
+			r.errorType = "EMPTY_RESPONSE"
+			r.errorMsg = extractError(output)
 		case strings.Contains(lowerOut, "failed to parse output") || strings.Contains(lowerOut, "no json object found"):
 			r.errorType = "PARSER_ERROR"
 			r.errorMsg = extractParserError(output)
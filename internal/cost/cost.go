@@ -1,6 +1,10 @@
 package cost
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"sync"
+)
 
 // ModelPricing represents the pricing for a model
 type ModelPricing struct {
@@ -11,6 +15,34 @@ type ModelPricing struct {
 // defaultPricing contains pricing for common models
 var defaultPricing = map[string]ModelPricing{
 	// OpenAI models
+	"gpt-4o": {
+		PromptPrice:     2.5,
+		CompletionPrice: 10,
+	},
+	"gpt-4o-mini": {
+		PromptPrice:     0.15,
+		CompletionPrice: 0.60,
+	},
+	"gpt-4.1": {
+		PromptPrice:     2.00,
+		CompletionPrice: 8.00,
+	},
+	"gpt-4.1-mini": {
+		PromptPrice:     0.40,
+		CompletionPrice: 1.60,
+	},
+	"gpt-4.1-nano": {
+		PromptPrice:     0.10,
+		CompletionPrice: 0.40,
+	},
+	"gpt-4-turbo": {
+		PromptPrice:     10.00,
+		CompletionPrice: 30.00,
+	},
+	"o3-mini": {
+		PromptPrice:     1.10,
+		CompletionPrice: 4.40,
+	},
 	"openai/gpt-oss-120b:exacto": {
 		PromptPrice:     0.05,
 		CompletionPrice: 0.24,
@@ -63,10 +95,33 @@ var defaultPricing = map[string]ModelPricing{
 		PromptPrice:     0.06,
 		CompletionPrice: 0.06,
 	},
+	// Anthropic models
+	"anthropic/claude-3.5-sonnet": {
+		PromptPrice:     3.00,
+		CompletionPrice: 15.00,
+	},
+	"anthropic/claude-3.5-haiku": {
+		PromptPrice:     0.80,
+		CompletionPrice: 4.00,
+	},
+	"anthropic/claude-sonnet-4": {
+		PromptPrice:     3.00,
+		CompletionPrice: 15.00,
+	},
+	// Google models
+	"google/gemini-2.5-flash": {
+		PromptPrice:     0.30,
+		CompletionPrice: 2.50,
+	},
+	"google/gemini-2.5-pro": {
+		PromptPrice:     1.25,
+		CompletionPrice: 10.00,
+	},
 }
 
 // Calculator calculates costs for LM usage
 type Calculator struct {
+	mu      sync.RWMutex
 	pricing map[string]ModelPricing
 }
 
@@ -84,12 +139,17 @@ func NewCalculator() *Calculator {
 
 // SetModelPricing sets custom pricing for a model
 func (c *Calculator) SetModelPricing(model string, pricing ModelPricing) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.pricing[model] = pricing
 }
 
 // Calculate calculates the cost for the given usage
 // Returns cost in USD
 func (c *Calculator) Calculate(model string, promptTokens, completionTokens int) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	pricing, ok := c.pricing[model]
 	if !ok {
 		// Try to find a match by prefix or partial match
@@ -103,6 +163,7 @@ func (c *Calculator) Calculate(model string, promptTokens, completionTokens int)
 }
 
 // findPricingByPattern attempts to find pricing by matching model name patterns
+// Callers must hold c.mu
 func (c *Calculator) findPricingByPattern(model string) ModelPricing {
 	modelLower := strings.ToLower(model)
 
@@ -111,11 +172,26 @@ func (c *Calculator) findPricingByPattern(model string) ModelPricing {
 		return pricing
 	}
 
+	// Iterate keys in a stable order so ambiguous matches are deterministic
+	keys := make([]string, 0, len(c.pricing))
+	for key := range c.pricing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Match the model name without organization (e.g. "gpt-4o" and "openai/gpt-4o")
+	modelBase := lastSegment(modelLower)
+	for _, key := range keys {
+		if lastSegment(strings.ToLower(key)) == modelBase {
+			return c.pricing[key]
+		}
+	}
+
 	// Try to find by prefix or contains
-	for key, pricing := range c.pricing {
+	for _, key := range keys {
 		keyLower := strings.ToLower(key)
 		if strings.Contains(modelLower, keyLower) || strings.Contains(keyLower, modelLower) {
-			return pricing
+			return c.pricing[key]
 		}
 	}
 
@@ -123,8 +199,19 @@ func (c *Calculator) findPricingByPattern(model string) ModelPricing {
 	return ModelPricing{}
 }
 
+// lastSegment returns the part of a model name after the last "/"
+func lastSegment(model string) string {
+	if idx := strings.LastIndex(model, "/"); idx != -1 {
+		return model[idx+1:]
+	}
+	return model
+}
+
 // HasPricing checks if pricing is available for a model
 func (c *Calculator) HasPricing(model string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if _, ok := c.pricing[model]; ok {
 		return true
 	}
@@ -133,6 +220,9 @@ func (c *Calculator) HasPricing(model string) bool {
 
 // GetPricing returns the pricing for a model
 func (c *Calculator) GetPricing(model string) (ModelPricing, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if pricing, ok := c.pricing[model]; ok {
 		return pricing, true
 	}
@@ -209,3 +209,17 @@ func TestGetPricing_PatternMatch(t *testing.T) {
 		t.Errorf("CompletionPrice = %f, want 0.40", pricing.CompletionPrice)
 	}
 }
+
+func TestFindPricingByPattern_ModelBaseName(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetModelPricing("acme/widget-large", ModelPricing{PromptPrice: 1, CompletionPrice: 1})
+	calc.SetModelPricing("acme/widget-large-v2", ModelPricing{PromptPrice: 5, CompletionPrice: 5})
+
+	// The organization-less name must resolve deterministically to its exact base match
+	for i := 0; i < 20; i++ {
+		pricing, ok := calc.GetPricing("other-org/widget-large")
+		if !ok || pricing.PromptPrice != 1 {
+			t.Fatalf("GetPricing() = %+v, %v; want widget-large pricing", pricing, ok)
+		}
+	}
+}
@@ -274,6 +274,8 @@ func fixJSONNewlines(jsonStr string) string {
 // - Missing quotes around keys
 // - Trailing commas
 // - Smart quotes (""”)
+// - JSON5-style // and /* */ comments
+// - Truncated output (unterminated strings, missing closing braces/brackets)
 // - Extra whitespace
 //
 // Returns the repaired JSON string. If repair is not possible, returns original.
@@ -293,7 +295,7 @@ func RepairJSON(jsonStr string) string {
 			jsonStr = jsonStr[:idx]
 		}
 	}
-	jsonStr = strings.TrimSpace(jsonStr)
+	jsonStr = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(jsonStr), "```"))
 
 	// Replace smart quotes with regular quotes
 	replacer := strings.NewReplacer(
@@ -309,9 +311,15 @@ func RepairJSON(jsonStr string) string {
 	// Fix single quotes to double quotes (carefully)
 	jsonStr = fixSingleQuotes(jsonStr)
 
+	// Remove JSON5-style comments
+	jsonStr = stripComments(jsonStr)
+
 	// Fix unquoted keys: {key: "value"} -> {"key": "value"}
 	jsonStr = fixUnquotedKeys(jsonStr)
 
+	// Close unterminated strings, braces and brackets from truncated output
+	jsonStr = balanceBrackets(jsonStr)
+
 	// Remove trailing commas before } or ]
 	jsonStr = removeTrailingCommas(jsonStr)
 
@@ -493,6 +501,124 @@ func removeTrailingCommas(jsonStr string) string {
 	return result.String()
 }
 
+// stripComments removes // line and /* block */ comments outside of strings
+func stripComments(jsonStr string) string {
+	var result strings.Builder
+	inString := false
+	escape := false
+
+	for i := 0; i < len(jsonStr); i++ {
+		ch := jsonStr[i]
+
+		if inString {
+			result.WriteByte(ch)
+			if escape {
+				escape = false
+			} else if ch == '\\' {
+				escape = true
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if ch == '"' {
+			inString = true
+			result.WriteByte(ch)
+			continue
+		}
+
+		if ch == '/' && i+1 < len(jsonStr) {
+			switch jsonStr[i+1] {
+			case '/':
+				for i < len(jsonStr) && jsonStr[i] != '\n' {
+					i++
+				}
+				if i < len(jsonStr) {
+					result.WriteByte('\n')
+				}
+				continue
+			case '*':
+				end := strings.Index(jsonStr[i+2:], "*/")
+				if end < 0 {
+					return result.String()
+				}
+				i += end + 3
+				continue
+			}
+		}
+
+		result.WriteByte(ch)
+	}
+
+	return result.String()
+}
+
+// balanceBrackets closes an unterminated string and any unclosed braces or
+// brackets, dropping a dangling comma or completing a dangling key with null
+func balanceBrackets(jsonStr string) string {
+	var stack []byte
+	inString := false
+	escape := false
+
+	for i := 0; i < len(jsonStr); i++ {
+		ch := jsonStr[i]
+
+		if inString {
+			if escape {
+				escape = false
+			} else if ch == '\\' {
+				escape = true
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) > 0 && stack[len(stack)-1] == ch {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if !inString && len(stack) == 0 {
+		return jsonStr
+	}
+
+	var result strings.Builder
+	result.WriteString(jsonStr)
+	if inString {
+		if escape {
+			// Drop a dangling escape character so the closing quote is not escaped
+			trimmed := strings.TrimSuffix(result.String(), "\\")
+			result.Reset()
+			result.WriteString(trimmed)
+		}
+		result.WriteByte('"')
+	}
+
+	repaired := strings.TrimRight(result.String(), " \t\n\r")
+	repaired = strings.TrimSuffix(repaired, ",")
+	if strings.HasSuffix(repaired, ":") {
+		repaired += " null"
+	}
+
+	result.Reset()
+	result.WriteString(repaired)
+	for i := len(stack) - 1; i >= 0; i-- {
+		result.WriteByte(stack[i])
+	}
+	return result.String()
+}
+
 // isWhitespace checks if a character is JSON whitespace
 func isWhitespace(ch byte) bool {
 	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
@@ -269,6 +269,41 @@ func TestRepairJSON(t *testing.T) {
 			input: `{"key": "value"}`,
 			want:  map[string]any{"key": "value"},
 		},
+		{
+			name:  "truncated missing closing brace",
+			input: `{"answer": "42", "confidence": 0.9`,
+			want:  map[string]any{"answer": "42", "confidence": 0.9},
+		},
+		{
+			name:  "truncated inside string",
+			input: `{"answer": "The answer is forty`,
+			want:  map[string]any{"answer": "The answer is forty"},
+		},
+		{
+			name:  "truncated nested object with dangling comma",
+			input: `{"result": {"score": 1,`,
+			want:  map[string]any{"result": map[string]any{"score": float64(1)}},
+		},
+		{
+			name:  "truncated after key",
+			input: `{"answer": "yes", "reason":`,
+			want:  map[string]any{"answer": "yes", "reason": nil},
+		},
+		{
+			name:  "json5 comments",
+			input: "{\n  // the final answer\n  \"answer\": \"42\", /* confident */ \"confidence\": 1\n}",
+			want:  map[string]any{"answer": "42", "confidence": float64(1)},
+		},
+		{
+			name:  "comment markers inside strings are kept",
+			input: `{"url": "https://example.com/a", 'note': "/* not a comment */",}`,
+			want:  map[string]any{"url": "https://example.com/a", "note": "/* not a comment */"},
+		},
+		{
+			name:  "unterminated code fence",
+			input: "```json\n{\"answer\": \"42\",}\n```",
+			want:  map[string]any{"answer": "42"},
+		},
 		{
 			name:  "multiple trailing commas",
 			input: `{"a": "1", "b": "2",}`,
@@ -9,6 +9,8 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,9 @@ const (
 	InitialBackoff = 1 * time.Second
 	MaxBackoff     = 30 * time.Second
 	JitterFactor   = 0.1
+
+	// MaxRetryAfter caps how long a server-provided Retry-After can delay a retry
+	MaxRetryAfter = 60 * time.Second
 )
 
 // IsRetryable checks if an HTTP status code is retryable
@@ -28,15 +33,60 @@ func IsRetryable(statusCode int) bool {
 		statusCode == http.StatusGatewayTimeout // 504
 }
 
+// Policy is an exponential backoff schedule: attempt n waits
+// InitialDelay * Multiplier^n, capped at MaxDelay, plus or minus Jitter of it.
+// Zero fields take the package defaults; a negative Jitter or MaxRetries means none.
+type Policy struct {
+	InitialDelay time.Duration // Delay before the first retry (default InitialBackoff)
+	Multiplier   float64       // Growth factor per retry (default 2)
+	MaxDelay     time.Duration // Upper bound of a delay before jitter (default MaxBackoff)
+	Jitter       float64       // Random spread as a fraction of the delay (default JitterFactor)
+	MaxRetries   int           // Retries after the first attempt (default MaxRetries)
+}
+
+// withDefaults fills zero fields with the package defaults and maps negative values to zero
+func (p Policy) withDefaults() Policy {
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = InitialBackoff
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = MaxBackoff
+	}
+	switch {
+	case p.Jitter == 0:
+		p.Jitter = JitterFactor
+	case p.Jitter < 0:
+		p.Jitter = 0
+	}
+	switch {
+	case p.MaxRetries == 0:
+		p.MaxRetries = MaxRetries
+	case p.MaxRetries < 0:
+		p.MaxRetries = 0
+	}
+	return p
+}
+
 // HTTPFunc is a function that performs an HTTP request
 type HTTPFunc func() (*http.Response, error)
 
-// WithExponentialBackoff executes an HTTP request with exponential backoff retry logic
+// WithExponentialBackoff executes an HTTP request with the default retry policy
 func WithExponentialBackoff(ctx context.Context, fn HTTPFunc) (*http.Response, error) {
+	return WithBackoff(ctx, Policy{}, fn)
+}
+
+// WithBackoff executes an HTTP request, retrying network errors, 429 and 5xx responses
+// on the schedule of policy
+func WithBackoff(ctx context.Context, policy Policy, fn HTTPFunc) (*http.Response, error) {
+	policy = policy.withDefaults()
 	var lastErr error
 	var resp *http.Response
+	var retryAfter time.Duration
 
-	for attempt := 0; attempt <= MaxRetries; attempt++ {
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
 		// Check context before attempting
 		if err := ctx.Err(); err != nil {
 			if lastErr != nil {
@@ -58,6 +108,7 @@ func WithExponentialBackoff(ctx context.Context, fn HTTPFunc) (*http.Response, e
 		if lastErr != nil {
 			// Network error - retry
 			shouldRetry = true
+			retryAfter = 0
 		} else if resp != nil && IsRetryable(resp.StatusCode) {
 			// Check if this is a permanent failure (quota exhaustion)
 			// Don't retry on quota/billing issues
@@ -66,20 +117,20 @@ func WithExponentialBackoff(ctx context.Context, fn HTTPFunc) (*http.Response, e
 			}
 			// Retryable status code (transient rate limit)
 			shouldRetry = true
+			retryAfter = ParseRetryAfter(resp.Header)
 			// Close the body to reuse connection
 			_ = resp.Body.Close()
 		}
 
 		// Don't retry if this was the last attempt
-		if !shouldRetry || attempt == MaxRetries {
+		if !shouldRetry || attempt == policy.MaxRetries {
 			if lastErr != nil {
 				return nil, fmt.Errorf("request failed after %d attempts: %w", attempt+1, lastErr)
 			}
 			return resp, nil
 		}
 
-		// Calculate backoff with exponential growth and jitter
-		backoff := calculateBackoff(attempt)
+		backoff := policy.retryDelay(attempt, retryAfter)
 
 		// Wait with context awareness
 		select {
@@ -94,23 +145,52 @@ func WithExponentialBackoff(ctx context.Context, fn HTTPFunc) (*http.Response, e
 	}
 
 	if lastErr != nil {
-		return nil, fmt.Errorf("request failed after %d attempts: %w", MaxRetries+1, lastErr)
+		return nil, fmt.Errorf("request failed after %d attempts: %w", policy.MaxRetries+1, lastErr)
 	}
 	return resp, nil
 }
 
-// calculateBackoff computes exponential backoff with jitter
-func calculateBackoff(attempt int) time.Duration {
-	// Exponential: initialBackoff * 2^attempt
-	backoff := float64(InitialBackoff) * math.Pow(2, float64(attempt))
+// retryDelay returns the server's Retry-After (capped at MaxRetryAfter) when present,
+// otherwise exponential backoff with jitter
+func (p Policy) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, MaxRetryAfter)
+	}
+	return p.Delay(attempt)
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+// It returns 0 when the header is absent, invalid or in the past
+func ParseRetryAfter(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Delay computes the exponential backoff with jitter before retry attempt+1
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+
+	// Exponential: InitialDelay * Multiplier^attempt
+	backoff := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt))
 
-	// Cap at MaxBackoff
-	if backoff > float64(MaxBackoff) {
-		backoff = float64(MaxBackoff)
+	// Cap at MaxDelay
+	if backoff > float64(p.MaxDelay) {
+		backoff = float64(p.MaxDelay)
 	}
 
-	// Add jitter: ±10% randomness
-	jitter := backoff * JitterFactor * (2*rand.Float64() - 1)
+	// Add jitter: ±Jitter randomness
+	jitter := backoff * p.Jitter * (2*rand.Float64() - 1)
 	backoff += jitter
 
 	return time.Duration(backoff)
@@ -194,7 +194,7 @@ func TestWithExponentialBackoff_Non200Success(t *testing.T) {
 	_ = resp.Body.Close()
 }
 
-func TestCalculateBackoff(t *testing.T) {
+func TestPolicyDelay_Defaults(t *testing.T) {
 	tests := []struct {
 		attempt     int
 		minExpected time.Duration
@@ -209,15 +209,159 @@ func TestCalculateBackoff(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			backoff := calculateBackoff(tt.attempt)
+			backoff := Policy{}.Delay(tt.attempt)
 			if backoff < tt.minExpected || backoff > tt.maxExpected {
-				t.Errorf("calculateBackoff(%d) = %v, want between %v and %v",
+				t.Errorf("Delay(%d) = %v, want between %v and %v",
 					tt.attempt, backoff, tt.minExpected, tt.maxExpected)
 			}
 		})
 	}
 }
 
+func TestPolicyDelay_Configured(t *testing.T) {
+	policy := Policy{InitialDelay: 200 * time.Millisecond, Multiplier: 3, MaxDelay: 5 * time.Second, Jitter: 0.2}
+	base := []time.Duration{
+		200 * time.Millisecond,
+		600 * time.Millisecond,
+		1800 * time.Millisecond,
+		5 * time.Second, // 5.4s capped
+		5 * time.Second,
+	}
+
+	for attempt, want := range base {
+		low, high := time.Duration(float64(want)*0.8), time.Duration(float64(want)*1.2)
+		for range 50 {
+			if got := policy.Delay(attempt); got < low || got > high {
+				t.Fatalf("Delay(%d) = %v, want within 20%% of %v", attempt, got, want)
+			}
+		}
+	}
+
+	exact := Policy{InitialDelay: time.Second, Multiplier: 1.5, Jitter: -1}
+	if got := exact.Delay(2); got != 2250*time.Millisecond {
+		t.Errorf("Delay(2) without jitter = %v, want 2.25s", got)
+	}
+}
+
+func TestWithBackoff_MaxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		wantCalls  int
+	}{
+		{"default", 0, MaxRetries + 1},
+		{"one retry", 1, 2},
+		{"no retries", -1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			policy := Policy{InitialDelay: time.Millisecond, MaxRetries: tt.maxRetries}
+			resp, err := WithBackoff(context.Background(), policy, func() (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			})
+			if err != nil {
+				t.Fatalf("WithBackoff() error = %v", err)
+			}
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want 503", resp.StatusCode)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempt     int
+		retryAfter  time.Duration
+		minExpected time.Duration
+		maxExpected time.Duration
+	}{
+		{"no header uses backoff", 0, 0, 500 * time.Millisecond, 2 * time.Second},
+		{"header longer than backoff", 0, 5 * time.Second, 5 * time.Second, 5 * time.Second},
+		{"header shorter than backoff", 3, time.Second, time.Second, time.Second},
+		{"header capped", 0, 10 * time.Minute, MaxRetryAfter, MaxRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := Policy{}.retryDelay(tt.attempt, tt.retryAfter)
+			if delay < tt.minExpected || delay > tt.maxExpected {
+				t.Errorf("retryDelay(%d, %v) = %v, want between %v and %v",
+					tt.attempt, tt.retryAfter, delay, tt.minExpected, tt.maxExpected)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		minExpected time.Duration
+		maxExpected time.Duration
+	}{
+		{"seconds", "12", 12 * time.Second, 12 * time.Second},
+		{"http date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{"past date", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{"zero seconds", "0", 0, 0},
+		{"invalid", "soon", 0, 0},
+		{"absent", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			got := ParseRetryAfter(header)
+			if got < tt.minExpected || got > tt.maxExpected {
+				t.Errorf("ParseRetryAfter(%q) = %v, want between %v and %v", tt.value, got, tt.minExpected, tt.maxExpected)
+			}
+		})
+	}
+}
+
+func TestWithExponentialBackoff_HonorsRetryAfter(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if callCount == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	start := time.Now()
+
+	resp, err := WithExponentialBackoff(context.Background(), func() (*http.Response, error) {
+		return client.Get(server.URL)
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retry, got error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// The first backoff is ~1s; Retry-After asks for 2s
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("Expected retry to wait at least 2s for Retry-After, waited %v", elapsed)
+	}
+	if callCount != 2 {
+		t.Errorf("Expected 2 calls, got %d", callCount)
+	}
+}
+
 func TestWithExponentialBackoff_MixedErrors(t *testing.T) {
 	callCount := 0
 	ctx := context.Background()
@@ -185,3 +185,12 @@ func LogPredictionEnd(ctx context.Context, moduleName string, duration time.Dura
 		globalLogger.Debug(ctx, "Prediction completed", fields)
 	}
 }
+
+// LogDemosDropped warns that demos were dropped to fit the model's context window
+func LogDemosDropped(ctx context.Context, moduleName string, dropped, kept int) {
+	globalLogger.Warn(ctx, "Dropped demos to fit context window", map[string]any{
+		"module":        moduleName,
+		"dropped_demos": dropped,
+		"demo_count":    kept,
+	})
+}
@@ -0,0 +1,151 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
+)
+
+// AssertionFunc checks a prediction and returns an error describing what is wrong with it
+// The error message is shown to the model as corrective feedback, so phrase it as an instruction.
+type AssertionFunc func(prediction *core.Prediction) error
+
+// Assert runs a module and checks its prediction against an assertion (dspy.Assert analog).
+//
+// When the assertion fails, the module is re-run with the failed output and the
+// assertion message appended to the prompt as corrective feedback, up to
+// MaxRetries times. If every attempt fails, Forward returns an error wrapping the
+// last assertion error, or with SoftFail (dspy.Suggest) the last prediction with
+// Metadata["assertion_error"] set.
+//
+// Feedback reaches the LM through the context (see core.WithFeedback), so the
+// inner module may be any Predict, ChainOfThought, ReAct or ProgramOfThought,
+// or a Program composed of them.
+type Assert struct {
+	Module     core.Module
+	Assertion  AssertionFunc
+	MaxRetries int           // Re-runs after the first failed attempt
+	SoftFail   bool          // Return the last prediction instead of an error
+	Timeout    time.Duration // Deadline for each Forward (0 = none)
+}
+
+// NewAssert creates an Assert module that retries module up to twice on assertion failure
+func NewAssert(module core.Module, assertion AssertionFunc) *Assert {
+	return &Assert{
+		Module:     module,
+		Assertion:  assertion,
+		MaxRetries: 2,
+	}
+}
+
+// WithMaxRetries sets how many times the module is re-run after a failed assertion
+func (a *Assert) WithMaxRetries(n int) *Assert {
+	a.MaxRetries = n
+	return a
+}
+
+// WithSoftFail sets whether exhausting retries returns the last prediction instead of an error
+func (a *Assert) WithSoftFail(soft bool) *Assert {
+	a.SoftFail = soft
+	return a
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (a *Assert) WithTimeout(timeout time.Duration) *Assert {
+	a.Timeout = timeout
+	return a
+}
+
+// GetSignature returns the inner module's signature
+func (a *Assert) GetSignature() *core.Signature {
+	if a.Module == nil {
+		return nil
+	}
+	return a.Module.GetSignature()
+}
+
+// Forward runs the module until its prediction passes the assertion or retries run out
+func (a *Assert) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Assert", inputs, a.forward)
+}
+
+// forward is Forward without error tracing
+func (a *Assert) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	ctx = logging.EnsureRequestID(ctx)
+	startTime := time.Now()
+	logging.LogPredictionStart(ctx, "Assert", "Assertion-checked execution")
+
+	var predErr error
+	defer func() {
+		logging.LogPredictionEnd(ctx, "Assert", time.Since(startTime), predErr)
+	}()
+
+	if a.Assertion == nil {
+		predErr = fmt.Errorf("assertion function is required")
+		return nil, predErr
+	}
+
+	var totalUsage core.Usage
+	subUsage := make(map[string]core.Usage)
+	var prediction *core.Prediction
+	var assertErr error
+	attemptCtx := ctx
+
+	for attempt := 0; attempt <= max(a.MaxRetries, 0); attempt++ {
+		pred, err := a.Module.Forward(attemptCtx, inputs)
+		if err != nil {
+			predErr = fmt.Errorf("attempt %d failed: %w", attempt+1, err)
+			return nil, predErr
+		}
+		totalUsage.Add(pred.Usage)
+		subUsage[fmt.Sprintf("attempt_%d", attempt+1)] = pred.Usage
+		prediction = pred
+
+		assertErr = a.Assertion(pred)
+		if assertErr == nil {
+			prediction.SubUsage = subUsage
+			return prediction.
+				WithUsage(totalUsage).
+				WithMetadata("assertion_attempts", attempt+1), nil
+		}
+
+		// Feedback replaces the previous attempt's, keeping any from an enclosing Assert
+		attemptCtx = core.WithFeedback(ctx, a.assertionFeedback(pred, assertErr))
+	}
+
+	attempts := max(a.MaxRetries, 0) + 1
+	if !a.SoftFail {
+		predErr = fmt.Errorf("assertion failed after %d attempts: %w", attempts, assertErr)
+		return nil, predErr
+	}
+	prediction.SubUsage = subUsage
+	return prediction.
+		WithUsage(totalUsage).
+		WithMetadata("assertion_attempts", attempts).
+		WithMetadata("assertion_error", assertErr.Error()), nil
+}
+
+// assertionFeedback describes a failed attempt so the model can correct it
+func (a *Assert) assertionFeedback(prediction *core.Prediction, assertErr error) string {
+	var fields []core.Field
+	if sig := a.Module.GetSignature(); sig != nil {
+		fields = sig.OutputFields
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Your previous response was:\n")
+	for _, k := range core.OrderedFieldKeys(fields, prediction.Outputs) {
+		fmt.Fprintf(&sb, "%s: %v\n", k, prediction.Outputs[k])
+	}
+	fmt.Fprintf(&sb, "\nIt failed this requirement: %s\n", assertErr)
+	sb.WriteString("Respond again, fixing the problem while keeping the required output format.")
+	return sb.String()
+}
@@ -0,0 +1,160 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// sequenceLM returns answers in order (repeating the last) and records the prompts it saw
+func sequenceLM(answers ...string) (*MockLM, *[][]core.Message) {
+	var seen [][]core.Message
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			seen = append(seen, messages)
+			answer := answers[min(len(seen), len(answers))-1]
+			return &core.GenerateResult{
+				Content: fmt.Sprintf(`{"answer": %q}`, answer),
+				Usage:   core.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+			}, nil
+		},
+	}
+	return lm, &seen
+}
+
+var errTooLong = errors.New("answer must be at most 5 characters")
+
+func shortAnswer(pred *core.Prediction) error {
+	if answer, _ := pred.GetString("answer"); len(answer) > 5 {
+		return errTooLong
+	}
+	return nil
+}
+
+func assertSignature() *core.Signature {
+	return core.NewSignature("Answer briefly").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+}
+
+func TestAssert_RetriesWithFeedback(t *testing.T) {
+	lm, seen := sequenceLM("far too long", "short")
+	a := NewAssert(NewPredict(assertSignature(), lm), shortAnswer)
+
+	pred, err := a.Forward(context.Background(), map[string]any{"question": "q"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["answer"] != "short" {
+		t.Errorf("answer = %v, want short", pred.Outputs["answer"])
+	}
+	if pred.Metadata["assertion_attempts"] != 2 {
+		t.Errorf("assertion_attempts = %v, want 2", pred.Metadata["assertion_attempts"])
+	}
+	if pred.Usage.TotalTokens != 24 {
+		t.Errorf("TotalTokens = %d, want usage summed over attempts (24)", pred.Usage.TotalTokens)
+	}
+
+	if len(*seen) != 2 {
+		t.Fatalf("LM called %d times, want 2", len(*seen))
+	}
+	first, retry := (*seen)[0], (*seen)[1]
+	if len(retry) != len(first)+1 {
+		t.Fatalf("retry prompt has %d messages, want %d", len(retry), len(first)+1)
+	}
+	feedback := retry[len(retry)-1]
+	if feedback.Role != "user" || !strings.Contains(feedback.Content, "far too long") || !strings.Contains(feedback.Content, errTooLong.Error()) {
+		t.Errorf("feedback message = %+v", feedback)
+	}
+}
+
+func TestAssert_ExhaustedRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		softFail bool
+	}{
+		{"hard fail", false},
+		{"soft fail", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm, seen := sequenceLM("far too long", "still too long")
+			a := NewAssert(NewPredict(assertSignature(), lm), shortAnswer).
+				WithMaxRetries(1).
+				WithSoftFail(tt.softFail)
+
+			pred, err := a.Forward(context.Background(), map[string]any{"question": "q"})
+			if len(*seen) != 2 {
+				t.Errorf("LM called %d times, want 2", len(*seen))
+			}
+			// Only the latest failure is fed back
+			if last := (*seen)[1]; strings.Contains(last[len(last)-1].Content, "still") {
+				t.Errorf("unexpected feedback: %s", last[len(last)-1].Content)
+			}
+
+			if !tt.softFail {
+				if !errors.Is(err, errTooLong) {
+					t.Fatalf("Forward() error = %v, want wrapping errTooLong", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if pred.Outputs["answer"] != "still too long" {
+				t.Errorf("answer = %v, want last attempt", pred.Outputs["answer"])
+			}
+			if pred.Metadata["assertion_error"] != errTooLong.Error() {
+				t.Errorf("assertion_error = %v", pred.Metadata["assertion_error"])
+			}
+		})
+	}
+}
+
+func TestAssert_ModuleError(t *testing.T) {
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	a := NewAssert(NewPredict(assertSignature(), lm), shortAnswer)
+	if _, err := a.Forward(context.Background(), map[string]any{"question": "q"}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Forward() error = %v, want module error", err)
+	}
+
+	if _, err := NewAssert(NewPredict(assertSignature(), lm), nil).Forward(context.Background(), nil); err == nil {
+		t.Error("expected error for nil assertion")
+	}
+}
+
+func TestAssert_Nested(t *testing.T) {
+	lm, seen := sequenceLM("far too long", "Short", "short")
+	inner := NewAssert(NewPredict(assertSignature(), lm), shortAnswer)
+	outer := NewAssert(inner, func(pred *core.Prediction) error {
+		if answer, _ := pred.GetString("answer"); answer != strings.ToLower(answer) {
+			return errors.New("answer must be lowercase")
+		}
+		return nil
+	})
+
+	pred, err := outer.Forward(context.Background(), map[string]any{"question": "q"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["answer"] != "short" {
+		t.Errorf("answer = %v, want short", pred.Outputs["answer"])
+	}
+	if outer.GetSignature() != inner.GetSignature() {
+		t.Error("GetSignature() should return the inner module's signature")
+	}
+	// The outer feedback stays on the prompt while the inner module retries
+	last := (*seen)[len(*seen)-1]
+	if !strings.Contains(last[len(last)-1].Content, "lowercase") {
+		t.Errorf("outer feedback missing from final prompt: %s", last[len(last)-1].Content)
+	}
+}
@@ -3,33 +3,27 @@ package module
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
 
+// DefaultMaxConcurrency bounds how many parallel BestOfN candidates run at once,
+// keeping large N below typical provider rate limits
+const DefaultMaxConcurrency = 4
+
 // ScoringFunction evaluates the quality of a prediction
 type ScoringFunction func(inputs map[string]any, prediction *core.Prediction) (float64, error)
 
 // BestOfN executes a module N times and returns the best result.
 //
-// IMPORTANT: When using WithParallel(true), ensure the module is stateless
-// or provide N independent module instances. Modules that maintain internal
-// state (e.g., History in Predict or ChainOfThought) will cause data races
-// when shared across goroutines.
-//
-// Safe parallel usage patterns:
-//   - Use stateless modules (no internal state mutation)
-//   - Create N independent instances of stateful modules
-//   - Use separate History instances for each parallel execution
-//
-// Example with independent instances:
-//
-//	modules := make([]core.Module, n)
-//	for i := 0; i < n; i++ {
-//	    modules[i] = module.NewPredict(sig, lm) // Each has its own History
-//	}
-//	// Execute with BestOfN wrapping each independently
+// With WithParallel(true), a Predict or ChainOfThought module with History runs
+// each candidate on its own clone of the history, so candidates never see each
+// other's turns; only the winning candidate's turn is recorded in the original
+// History. Other modules are shared across goroutines and must not keep
+// unsynchronized state of their own.
 type BestOfN struct {
 	Module      core.Module
 	N           int
@@ -38,6 +32,13 @@ type BestOfN struct {
 	ReturnAll   bool
 	MaxFailures int     // Maximum number of failures before giving up
 	Threshold   float64 // Early-stop if score meets or exceeds this threshold
+	// CancelOnThreshold cancels in-flight parallel candidates once one meets the threshold
+	CancelOnThreshold bool
+	// TemperatureFunc returns the temperature for candidate i of n (nil keeps the module's options)
+	TemperatureFunc func(i, n int) float64
+	Timeout         time.Duration // Deadline for each Forward (0 = none)
+	// MaxConcurrency bounds candidates in flight when Parallel (<= 0 = all N at once)
+	MaxConcurrency int
 }
 
 // BestOfNResult contains the results of BestOfN execution (deprecated - use Prediction.Completions)
@@ -59,6 +60,9 @@ func NewBestOfN(module core.Module, n int) *BestOfN {
 		ReturnAll:   false,
 		MaxFailures: n / 2, // Allow up to half the attempts to fail
 		Threshold:   0,     // No threshold by default
+
+		CancelOnThreshold: true,
+		MaxConcurrency:    DefaultMaxConcurrency,
 	}
 }
 
@@ -69,13 +73,19 @@ func (b *BestOfN) WithScorer(scorer ScoringFunction) *BestOfN {
 }
 
 // WithParallel enables parallel execution.
-// WARNING: Only use with stateless modules or independent instances.
-// See BestOfN type documentation for safe usage patterns.
+// See the BestOfN type documentation for how History is handled.
 func (b *BestOfN) WithParallel(parallel bool) *BestOfN {
 	b.Parallel = parallel
 	return b
 }
 
+// WithMaxConcurrency sets how many candidates run at once when parallel
+// (default DefaultMaxConcurrency; n <= 0 launches all N together)
+func (b *BestOfN) WithMaxConcurrency(n int) *BestOfN {
+	b.MaxConcurrency = n
+	return b
+}
+
 // WithReturnAll enables returning all results, not just the best
 func (b *BestOfN) WithReturnAll(returnAll bool) *BestOfN {
 	b.ReturnAll = returnAll
@@ -94,6 +104,57 @@ func (b *BestOfN) WithThreshold(threshold float64) *BestOfN {
 	return b
 }
 
+// WithCancelOnThreshold controls whether in-flight parallel candidates are
+// cancelled once one meets the threshold (default true)
+func (b *BestOfN) WithCancelOnThreshold(cancel bool) *BestOfN {
+	b.CancelOnThreshold = cancel
+	return b
+}
+
+// WithTemperatureRange spreads candidate temperatures evenly from min to max
+// so candidates are more diverse than N calls with identical options
+func (b *BestOfN) WithTemperatureRange(min, max float64) *BestOfN {
+	b.TemperatureFunc = func(i, n int) float64 {
+		if n <= 1 {
+			return min
+		}
+		return min + (max-min)*float64(i)/float64(n-1)
+	}
+	return b
+}
+
+// WithTemperatureFunc sets a custom per-candidate temperature schedule
+func (b *BestOfN) WithTemperatureFunc(fn func(i, n int) float64) *BestOfN {
+	b.TemperatureFunc = fn
+	return b
+}
+
+// candidateContext applies candidate i's temperature to every LM call made under ctx
+// The override changes the effective options, and therefore the cache key, of each candidate
+func (b *BestOfN) candidateContext(ctx context.Context, i int) context.Context {
+	if b.TemperatureFunc == nil {
+		return ctx
+	}
+	temperature := b.TemperatureFunc(i, b.N)
+	return core.WithOptionOverride(ctx, func(options *core.GenerateOptions) {
+		options.Temperature = temperature
+	})
+}
+
+// recordTemperature notes candidate i's temperature on its prediction
+func (b *BestOfN) recordTemperature(prediction *core.Prediction, i int) {
+	if b.TemperatureFunc != nil {
+		prediction.WithMetadata("temperature", b.TemperatureFunc(i, b.N))
+	}
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (b *BestOfN) WithTimeout(timeout time.Duration) *BestOfN {
+	b.Timeout = timeout
+	return b
+}
+
 // GetSignature returns the module's signature
 func (b *BestOfN) GetSignature() *core.Signature {
 	return b.Module.GetSignature()
@@ -101,6 +162,14 @@ func (b *BestOfN) GetSignature() *core.Signature {
 
 // Forward executes the module N times and returns the best result
 func (b *BestOfN) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "BestOfN", inputs, b.forward)
+}
+
+// forward is Forward without error tracing
+func (b *BestOfN) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, b.Timeout)
+	defer cancel()
+
 	if b.Scorer == nil {
 		return nil, fmt.Errorf("scorer function must be set")
 	}
@@ -116,13 +185,15 @@ func (b *BestOfN) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 }
 
 func (b *BestOfN) forwardSequential(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
-	var allPredictions []*core.Prediction
+	var candidates []scoredCandidate
 	var bestPrediction *core.Prediction
 	bestScore := -1.0
 	failureCount := 0
+	var totalUsage core.Usage
+	subUsage := make(map[string]core.Usage)
 
 	for i := 0; i < b.N; i++ {
-		prediction, err := b.Module.Forward(ctx, inputs)
+		prediction, err := b.Module.Forward(b.candidateContext(ctx, i), inputs)
 		if err != nil {
 			failureCount++
 			if failureCount > b.MaxFailures {
@@ -130,6 +201,10 @@ func (b *BestOfN) forwardSequential(ctx context.Context, inputs map[string]any)
 			}
 			continue
 		}
+		candidateUsage := prediction.Usage
+		totalUsage.Add(candidateUsage)
+		subUsage[candidateKey(i)] = candidateUsage
+		b.recordTemperature(prediction, i)
 
 		score, err := b.Scorer(inputs, prediction)
 		if err != nil {
@@ -140,7 +215,7 @@ func (b *BestOfN) forwardSequential(ctx context.Context, inputs map[string]any)
 			continue
 		}
 
-		allPredictions = append(allPredictions, prediction)
+		candidates = append(candidates, scoredCandidate{prediction: prediction, score: score})
 
 		if bestPrediction == nil || score > bestScore {
 			bestPrediction = prediction
@@ -157,16 +232,14 @@ func (b *BestOfN) forwardSequential(ctx context.Context, inputs map[string]any)
 		return nil, fmt.Errorf("all %d attempts failed", b.N)
 	}
 
-	// Set score on best prediction
+	// Set score on best prediction and report usage across all completed candidates
 	bestPrediction.Score = bestScore
+	bestPrediction.Usage = totalUsage
+	bestPrediction.SubUsage = subUsage
 
-	// If ReturnAll is enabled, add all completions
+	// If ReturnAll is enabled, add all completions sorted by score
 	if b.ReturnAll {
-		var completions []map[string]any
-		for _, pred := range allPredictions {
-			completions = append(completions, pred.Outputs)
-		}
-		bestPrediction.Completions = completions
+		applyRankedCompletions(bestPrediction, candidates)
 	}
 
 	return bestPrediction, nil
@@ -174,32 +247,63 @@ func (b *BestOfN) forwardSequential(ctx context.Context, inputs map[string]any)
 
 func (b *BestOfN) forwardParallel(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
 	type result struct {
+		index      int
 		prediction *core.Prediction
 		score      float64
 		err        error
+		history    *historyFork
 	}
 
+	// Derived context lets us abort in-flight candidates once the threshold is met
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	results := make(chan result, b.N)
 	var wg sync.WaitGroup
 
+	// Candidates wait for a slot so at most MaxConcurrency are in flight
+	var slots chan struct{}
+	if b.MaxConcurrency > 0 && b.MaxConcurrency < b.N {
+		slots = make(chan struct{}, b.MaxConcurrency)
+	}
+
 	for i := 0; i < b.N; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 
-			prediction, err := b.Module.Forward(ctx, inputs)
+			if slots != nil {
+				// Take a free slot first so a select race never skips candidates that could run
+				select {
+				case slots <- struct{}{}:
+				default:
+					select {
+					case slots <- struct{}{}:
+					case <-runCtx.Done():
+						// Never started: the threshold was met or the caller gave up
+						results <- result{index: i, err: runCtx.Err()}
+						return
+					}
+				}
+				defer func() { <-slots }()
+			}
+
+			// Each candidate records into its own History so turns never interleave
+			mod, history := forkHistory(b.Module)
+			prediction, err := mod.Forward(b.candidateContext(runCtx, i), inputs)
 			if err != nil {
-				results <- result{err: err}
+				results <- result{index: i, err: err}
 				return
 			}
+			b.recordTemperature(prediction, i)
 
 			score, err := b.Scorer(inputs, prediction)
 			if err != nil {
-				results <- result{err: err}
+				results <- result{index: i, prediction: prediction, err: err}
 				return
 			}
 
-			results <- result{prediction: prediction, score: score}
+			results <- result{index: i, prediction: prediction, score: score, history: history}
 		}()
 	}
 
@@ -210,23 +314,46 @@ func (b *BestOfN) forwardParallel(ctx context.Context, inputs map[string]any) (*
 	}()
 
 	// Collect results
-	var allPredictions []*core.Prediction
+	var candidates []scoredCandidate
 	var bestPrediction *core.Prediction
+	var bestHistory *historyFork
 	bestScore := -1.0
 	failureCount := 0
+	thresholdMet := false
+	var totalUsage core.Usage
+	subUsage := make(map[string]core.Usage)
 
 	for res := range results {
+		// Completed candidates consumed tokens even if scoring failed
+		if res.prediction != nil {
+			totalUsage.Add(res.prediction.Usage)
+			subUsage[candidateKey(res.index)] = res.prediction.Usage
+		}
+
 		if res.err != nil {
+			// Candidates aborted because another met the threshold are not failures
+			if thresholdMet && b.CancelOnThreshold && ctx.Err() == nil {
+				continue
+			}
 			failureCount++
 			continue
 		}
 
-		allPredictions = append(allPredictions, res.prediction)
+		candidates = append(candidates, scoredCandidate{prediction: res.prediction, score: res.score})
 
 		if bestPrediction == nil || res.score > bestScore {
 			bestPrediction = res.prediction
+			bestHistory = res.history
 			bestScore = res.score
 		}
+
+		// Early stop: abort remaining in-flight candidates
+		if b.Threshold > 0 && res.score >= b.Threshold && !thresholdMet {
+			thresholdMet = true
+			if b.CancelOnThreshold {
+				cancel()
+			}
+		}
 	}
 
 	if failureCount > b.MaxFailures {
@@ -237,21 +364,52 @@ func (b *BestOfN) forwardParallel(ctx context.Context, inputs map[string]any) (*
 		return nil, fmt.Errorf("all %d attempts failed", b.N)
 	}
 
-	// Set score on best prediction
+	// Only the winning candidate's turn becomes part of the conversation
+	bestHistory.commit()
+
+	// Set score on best prediction and report usage across all completed candidates
 	bestPrediction.Score = bestScore
+	bestPrediction.Usage = totalUsage
+	bestPrediction.SubUsage = subUsage
 
-	// If ReturnAll is enabled, add all completions
+	// If ReturnAll is enabled, add all completions sorted by score
 	if b.ReturnAll {
-		var completions []map[string]any
-		for _, pred := range allPredictions {
-			completions = append(completions, pred.Outputs)
-		}
-		bestPrediction.Completions = completions
+		applyRankedCompletions(bestPrediction, candidates)
 	}
 
 	return bestPrediction, nil
 }
 
+// scoredCandidate pairs a successful candidate with its score
+type scoredCandidate struct {
+	prediction *core.Prediction
+	score      float64
+}
+
+// applyRankedCompletions sets Completions and Scores sorted by score descending
+// Ties keep generation order so results are deterministic for sequential runs
+func applyRankedCompletions(prediction *core.Prediction, candidates []scoredCandidate) {
+	ranked := make([]scoredCandidate, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	completions := make([]map[string]any, 0, len(ranked))
+	scores := make([]float64, 0, len(ranked))
+	for _, c := range ranked {
+		completions = append(completions, c.prediction.Outputs)
+		scores = append(scores, c.score)
+	}
+	prediction.Completions = completions
+	prediction.Scores = scores
+}
+
+// candidateKey names candidate i in Prediction.SubUsage
+func candidateKey(i int) string {
+	return fmt.Sprintf("candidate_%d", i)
+}
+
 // DefaultScorer returns a simple length-based scorer
 // This is a basic scorer that prefers longer outputs
 func DefaultScorer() ScoringFunction {
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
@@ -203,6 +205,53 @@ func TestBestOfN_Forward_Parallel(t *testing.T) {
 	}
 }
 
+func TestBestOfN_Parallel_MaxConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		wantMaxPeak int32
+	}{
+		{"bounded", 3, 3},
+		{"unbounded", 0, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, peak, calls atomic.Int32
+			module := &MockModule{
+				ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+					calls.Add(1)
+					n := inFlight.Add(1)
+					defer inFlight.Add(-1)
+					for {
+						old := peak.Load()
+						if n <= old || peak.CompareAndSwap(old, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return core.NewPrediction(map[string]interface{}{"result": "test"}), nil
+				},
+			}
+
+			bon := NewBestOfN(module, 10).WithScorer(DefaultScorer()).WithParallel(true).WithMaxConcurrency(tt.limit)
+			if _, err := bon.Forward(context.Background(), map[string]interface{}{}); err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			if calls.Load() != 10 {
+				t.Errorf("expected 10 candidates, got %d", calls.Load())
+			}
+			if peak.Load() > tt.wantMaxPeak {
+				t.Errorf("peak concurrency = %d, want at most %d", peak.Load(), tt.wantMaxPeak)
+			}
+			if peak.Load() < 2 {
+				t.Errorf("expected candidates to overlap, peak = %d", peak.Load())
+			}
+		})
+	}
+}
+
 func TestBestOfN_Forward_ParallelWithFailures(t *testing.T) {
 	callCount := 0
 	var mu sync.Mutex
@@ -307,6 +356,63 @@ func TestBestOfN_Forward_ParallelReturnAll(t *testing.T) {
 	if len(outputs.Completions) != 3 {
 		t.Errorf("Expected 3 completions, got %d", len(outputs.Completions))
 	}
+
+	assertRankedCompletions(t, outputs)
+}
+
+func TestBestOfN_ReturnAll_SortedByScore(t *testing.T) {
+	values := []int{2, 5, 1, 4}
+	callCount := 0
+	module := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			v := values[callCount]
+			callCount++
+			return core.NewPrediction(map[string]interface{}{"value": v}), nil
+		},
+	}
+
+	scorer := func(inputs map[string]interface{}, prediction *core.Prediction) (float64, error) {
+		return float64(prediction.Outputs["value"].(int)), nil
+	}
+
+	outputs, err := NewBestOfN(module, 4).WithScorer(scorer).WithReturnAll(true).
+		Forward(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	assertRankedCompletions(t, outputs)
+
+	wantScores := []float64{5, 4, 2, 1}
+	for i, want := range wantScores {
+		if outputs.Scores[i] != want {
+			t.Errorf("Scores[%d] = %v, want %v", i, outputs.Scores[i], want)
+		}
+	}
+
+	best, ok := outputs.GetCandidate(0)
+	if !ok || best.Outputs["value"] != 5 || best.Score != 5 {
+		t.Errorf("GetCandidate(0) = %+v, want value 5 with score 5", best)
+	}
+	if outputs.Outputs["value"] != best.Outputs["value"] {
+		t.Error("winner should be the first candidate")
+	}
+}
+
+// assertRankedCompletions checks Completions and Scores are parallel and sorted descending
+func assertRankedCompletions(t *testing.T, prediction *core.Prediction) {
+	t.Helper()
+	if len(prediction.Scores) != len(prediction.Completions) {
+		t.Fatalf("Scores (%d) should parallel Completions (%d)", len(prediction.Scores), len(prediction.Completions))
+	}
+	for i := 1; i < len(prediction.Scores); i++ {
+		if prediction.Scores[i] > prediction.Scores[i-1] {
+			t.Errorf("Scores not sorted descending: %v", prediction.Scores)
+		}
+	}
+	if len(prediction.Scores) > 0 && prediction.Scores[0] != prediction.Score {
+		t.Errorf("Scores[0] = %v, want best score %v", prediction.Scores[0], prediction.Score)
+	}
 }
 
 func TestBestOfN_GetSignature(t *testing.T) {
@@ -648,3 +754,177 @@ func BenchmarkBestOfN_ParallelReturnAll(b *testing.B) {
 		}
 	}
 }
+
+func TestBestOfN_Parallel_CancelOnThreshold(t *testing.T) {
+	var started, cancelled atomic.Int32
+	module := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			n := started.Add(1)
+			if n == 1 {
+				// First candidate finishes immediately and meets the threshold
+				return core.NewPrediction(map[string]interface{}{"score": 100}).
+					WithUsage(core.Usage{TotalTokens: 10}), nil
+			}
+			select {
+			case <-ctx.Done():
+				cancelled.Add(1)
+				return nil, ctx.Err()
+			case <-time.After(2 * time.Second):
+				return core.NewPrediction(map[string]interface{}{"score": 1}).
+					WithUsage(core.Usage{TotalTokens: 10}), nil
+			}
+		},
+	}
+
+	scorer := func(inputs map[string]interface{}, prediction *core.Prediction) (float64, error) {
+		return float64(prediction.Outputs["score"].(int)), nil
+	}
+
+	// Unbounded so every other candidate is in flight when the threshold is met
+	bon := NewBestOfN(module, 5).WithScorer(scorer).WithParallel(true).WithThreshold(50).WithMaxConcurrency(0)
+	start := time.Now()
+	result, err := bon.Forward(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected in-flight candidates to be cancelled, took %v", elapsed)
+	}
+	if cancelled.Load() != 4 {
+		t.Errorf("expected 4 cancelled candidates, got %d", cancelled.Load())
+	}
+	if result.Score != 100 {
+		t.Errorf("expected best score 100, got %v", result.Score)
+	}
+	if result.Usage.TotalTokens != 10 {
+		t.Errorf("expected usage from the completed candidate only (10), got %d", result.Usage.TotalTokens)
+	}
+}
+
+func TestBestOfN_Parallel_NoCancelOnThreshold(t *testing.T) {
+	module := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			time.Sleep(10 * time.Millisecond)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return core.NewPrediction(map[string]interface{}{"score": 100}).
+				WithUsage(core.Usage{TotalTokens: 10}), nil
+		},
+	}
+
+	scorer := func(inputs map[string]interface{}, prediction *core.Prediction) (float64, error) {
+		return float64(prediction.Outputs["score"].(int)), nil
+	}
+
+	bon := NewBestOfN(module, 3).WithScorer(scorer).WithParallel(true).
+		WithThreshold(50).WithCancelOnThreshold(false)
+	result, err := bon.Forward(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("expected usage summed over all 3 candidates (30), got %d", result.Usage.TotalTokens)
+	}
+	if len(result.SubUsage) != 3 || result.SubUsage["candidate_2"].TotalTokens != 10 {
+		t.Errorf("expected per-candidate usage, got %+v", result.SubUsage)
+	}
+}
+
+func TestBestOfN_TemperatureSchedule(t *testing.T) {
+	sig := core.NewSignature("Write a tagline").
+		AddInput("product", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+	inputs := map[string]interface{}{"product": "coffee"}
+	scorer := func(inputs map[string]any, prediction *core.Prediction) (float64, error) { return 1, nil }
+
+	tests := []struct {
+		name     string
+		parallel bool
+		schedule func(b *BestOfN) *BestOfN
+		want     []float64
+	}{
+		{
+			name:     "range sequential",
+			schedule: func(b *BestOfN) *BestOfN { return b.WithTemperatureRange(0.2, 1.0) },
+			want:     []float64{0.2, 0.4, 0.6, 0.8, 1.0},
+		},
+		{
+			name:     "custom func parallel",
+			parallel: true,
+			schedule: func(b *BestOfN) *BestOfN {
+				return b.WithTemperatureFunc(func(i, n int) float64 { return float64(i) / 10 })
+			},
+			want: []float64{0, 0.1, 0.2, 0.3, 0.4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			seen := make(map[float64]bool)
+			lm := &MockLM{
+				SupportsJSONVal: true,
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					mu.Lock()
+					seen[options.Temperature] = true
+					mu.Unlock()
+					return &core.GenerateResult{Content: `{"answer": "Wake up"}`}, nil
+				},
+			}
+
+			best := tt.schedule(NewBestOfN(NewPredict(sig, lm), 5).WithScorer(scorer).WithParallel(tt.parallel))
+			pred, err := best.Forward(context.Background(), inputs)
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			if len(seen) != len(tt.want) {
+				t.Errorf("saw temperatures %v, want %v", seen, tt.want)
+			}
+			for _, temp := range tt.want {
+				found := false
+				for s := range seen {
+					if s > temp-1e-9 && s < temp+1e-9 {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("temperature %v was not used, saw %v", temp, seen)
+				}
+			}
+			if _, ok := pred.Metadata["temperature"].(float64); !ok {
+				t.Errorf("expected candidate temperature in metadata, got %v", pred.Metadata)
+			}
+		})
+	}
+}
+
+func TestBestOfN_TemperatureScheduleAvoidsCacheCollapse(t *testing.T) {
+	sig := core.NewSignature("Answer").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+	inputs := map[string]interface{}{"question": "Name a color"}
+	scorer := func(inputs map[string]any, prediction *core.Prediction) (float64, error) { return 1, nil }
+
+	lm := &cachingLM{cache: core.NewLMCache(10)}
+	if _, err := NewBestOfN(NewPredict(sig, lm), 3).WithScorer(scorer).Forward(context.Background(), inputs); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if lm.calls != 1 {
+		t.Fatalf("identical candidates should share one cache entry, got %d LM calls", lm.calls)
+	}
+
+	pred, err := NewBestOfN(NewPredict(sig, lm), 3).WithScorer(scorer).WithTemperatureRange(0.5, 1.5).WithReturnAll(true).
+		Forward(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if lm.calls != 4 {
+		t.Errorf("scheduled temperatures should give each candidate its own cache key, got %d LM calls", lm.calls)
+	}
+	if len(pred.Completions) != 3 {
+		t.Errorf("expected 3 completions, got %d", len(pred.Completions))
+	}
+}
@@ -0,0 +1,134 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// Branch routes inputs to one of several modules based on a classifier output field.
+// It implements core.Module, so classify-then-route can be composed inside a Program:
+//
+//	router := module.NewBranch("category").
+//	    WithClassifier(classifier).
+//	    Case("technical", techModule).
+//	    Case("billing", billingModule).
+//	    Default(generalModule)
+//
+// Without a classifier the field is read from the inputs, e.g. the output of a
+// previous Program step. Case values match case-insensitively, ignoring
+// surrounding whitespace.
+type Branch struct {
+	Field         string
+	Classifier    core.Module
+	Cases         map[string]core.Module
+	DefaultModule core.Module
+	Timeout       time.Duration // Deadline for each Forward (0 = none)
+}
+
+// NewBranch creates a branch that dispatches on the named classifier output field
+func NewBranch(classifierOutputField string) *Branch {
+	return &Branch{
+		Field: classifierOutputField,
+		Cases: make(map[string]core.Module),
+	}
+}
+
+// WithClassifier sets the module run first to produce the branch field
+func (b *Branch) WithClassifier(classifier core.Module) *Branch {
+	b.Classifier = classifier
+	return b
+}
+
+// Case routes to module when the branch field equals value
+func (b *Branch) Case(value string, module core.Module) *Branch {
+	b.Cases[normalizeBranchValue(value)] = module
+	return b
+}
+
+// Default sets the module used when no case matches
+func (b *Branch) Default(module core.Module) *Branch {
+	b.DefaultModule = module
+	return b
+}
+
+// Forward runs the classifier (if any) and dispatches to the matching case.
+// The selected module receives the inputs merged with the classifier outputs.
+// The returned prediction records the selected case in Metadata["branch"].
+func (b *Branch) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Branch", inputs, b.forward)
+}
+
+// forward is Forward without error tracing
+func (b *Branch) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, b.Timeout)
+	defer cancel()
+
+	routeInputs := inputs
+	var classifierUsage core.Usage
+
+	if b.Classifier != nil {
+		classification, err := b.Classifier.Forward(ctx, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("branch classifier failed: %w", err)
+		}
+		classifierUsage = classification.Usage
+
+		routeInputs = make(map[string]any, len(inputs)+len(classification.Outputs))
+		for k, v := range inputs {
+			routeInputs[k] = v
+		}
+		for k, v := range classification.Outputs {
+			routeInputs[k] = v
+		}
+	}
+
+	value, ok := routeInputs[b.Field]
+	if !ok {
+		return nil, fmt.Errorf("branch field %q not found in inputs or classifier outputs", b.Field)
+	}
+
+	selected := normalizeBranchValue(fmt.Sprint(value))
+	target, ok := b.Cases[selected]
+	if !ok {
+		if b.DefaultModule == nil {
+			return nil, fmt.Errorf("branch has no case for %s=%q and no default", b.Field, value)
+		}
+		target = b.DefaultModule
+		selected = "default"
+	}
+
+	prediction, err := target.Forward(ctx, routeInputs)
+	if err != nil {
+		return nil, fmt.Errorf("branch case %q failed: %w", selected, err)
+	}
+
+	caseUsage := prediction.Usage
+	prediction.Usage.Add(classifierUsage)
+
+	return prediction.
+		WithSubUsage("classifier", classifierUsage).
+		WithSubUsage(selected, caseUsage).
+		WithMetadata("branch", selected), nil
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (b *Branch) WithTimeout(timeout time.Duration) *Branch {
+	b.Timeout = timeout
+	return b
+}
+
+// GetSignature returns nil because cases may produce different outputs
+// Each case validates its own outputs
+func (b *Branch) GetSignature() *core.Signature {
+	return nil
+}
+
+// normalizeBranchValue makes case matching insensitive to case and surrounding whitespace
+func normalizeBranchValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,139 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// routeModule returns a module that outputs its own name as "handled_by"
+func routeModule(name string) *MockModule {
+	return &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return core.NewPrediction(map[string]interface{}{"handled_by": name}).
+				WithUsage(core.Usage{PromptTokens: 10, TotalTokens: 10}), nil
+		},
+	}
+}
+
+func TestBranch_Forward(t *testing.T) {
+	tests := []struct {
+		name       string
+		category   interface{}
+		withDef    bool
+		wantModule string
+		wantBranch string
+		wantErr    string
+	}{
+		{"exact case", "technical", true, "tech", "technical", ""},
+		{"case insensitive", "  Billing\n", true, "billing", "billing", ""},
+		{"default", "sales", true, "general", "default", ""},
+		{"no match without default", "sales", false, "", "", `no case for category="sales"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classifier := &MockModule{
+				ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+					return core.NewPrediction(map[string]interface{}{"category": tt.category}).
+						WithUsage(core.Usage{PromptTokens: 5, TotalTokens: 5}), nil
+				},
+			}
+
+			branch := NewBranch("category").
+				WithClassifier(classifier).
+				Case("technical", routeModule("tech")).
+				Case("billing", routeModule("billing"))
+			if tt.withDef {
+				branch.Default(routeModule("general"))
+			}
+
+			pred, err := branch.Forward(context.Background(), map[string]interface{}{"ticket": "help"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			if pred.Outputs["handled_by"] != tt.wantModule {
+				t.Errorf("handled_by = %v, want %s", pred.Outputs["handled_by"], tt.wantModule)
+			}
+			if pred.Metadata["branch"] != tt.wantBranch {
+				t.Errorf("Metadata[branch] = %v, want %s", pred.Metadata["branch"], tt.wantBranch)
+			}
+			if pred.Usage.TotalTokens != 15 {
+				t.Errorf("expected classifier usage to be included, got %d", pred.Usage.TotalTokens)
+			}
+		})
+	}
+}
+
+func TestBranch_ReadsFieldFromInputsInProgram(t *testing.T) {
+	classify := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return core.NewPrediction(map[string]interface{}{"category": "billing"}), nil
+		},
+	}
+
+	var routedInputs map[string]interface{}
+	billing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			routedInputs = inputs
+			return core.NewPrediction(map[string]interface{}{"reply": "refund issued"}), nil
+		},
+	}
+
+	program := NewProgram("support").
+		AddModule(classify).
+		AddModule(NewBranch("category").Case("billing", billing).Default(routeModule("general")))
+
+	pred, err := program.Forward(context.Background(), map[string]interface{}{"ticket": "charged twice"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["reply"] != "refund issued" {
+		t.Errorf("expected billing reply, got %v", pred.Outputs)
+	}
+	if routedInputs["ticket"] != "charged twice" || routedInputs["category"] != "billing" {
+		t.Errorf("expected routed module to receive inputs and classification, got %v", routedInputs)
+	}
+}
+
+func TestBranch_Errors(t *testing.T) {
+	boom := errors.New("boom")
+	failing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return nil, boom
+		},
+	}
+
+	tests := []struct {
+		name    string
+		branch  *Branch
+		wantErr string
+	}{
+		{"missing field", NewBranch("category").Default(routeModule("general")), `branch field "category" not found`},
+		{"classifier error", NewBranch("category").WithClassifier(failing), "branch classifier failed: boom"},
+		{"case error", NewBranch("ticket").Case("x", failing), `branch case "x" failed: boom`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.branch.Forward(context.Background(), map[string]interface{}{"ticket": "x"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if NewBranch("category").GetSignature() != nil {
+		t.Error("expected nil signature")
+	}
+}
@@ -2,12 +2,17 @@ package module
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
 
 // ChainOfThought module encourages step-by-step reasoning
+// Like Predict, a configured ChainOfThought is safe for concurrent use as long as
+// concurrent calls don't share a History.
 type ChainOfThought struct {
 	Signature *core.Signature
 	LM        core.LM
@@ -15,6 +20,23 @@ type ChainOfThought struct {
 	Adapter   core.Adapter
 	History   *core.History  // Optional conversation history
 	Demos     []core.Example // Optional few-shot examples
+	Timeout   time.Duration  // Deadline for each Forward and Stream (0 = none)
+
+	MaxDemos     int // Cap on demos used per Forward (0 = all)
+	DemoMaxChars int // Truncate string demo values to this many characters (0 = no limit)
+
+	SkipInputValidation bool // Send inputs without checking them against the signature
+
+	FlushInterval time.Duration // Coalesce Stream chunks over this interval (0 = off)
+	MinChunkBytes int           // Coalesce Stream chunks until they hold this much text (0 = off)
+
+	MaxTokensPerField map[string]int // Completion token budgets per output field (see WithMaxTokensPerField)
+
+	PartialStreamOnError bool // Deliver the streamed text on a final parse failure (see WithPartialStreamOnError)
+
+	RationaleField  string // Output field the reasoning is requested in (empty = "reasoning")
+	RationalePrompt string // Instruction asking for the reasoning (empty = think step-by-step)
+	HideRationale   bool   // Record only the answer in History (see WithHideRationale)
 }
 
 // NewChainOfThought creates a new ChainOfThought module
@@ -33,12 +55,61 @@ func (cot *ChainOfThought) WithOptions(options *core.GenerateOptions) *ChainOfTh
 	return cot
 }
 
+// WithMaxTokensPerField sizes MaxTokens from per-output-field token budgets, replacing
+// any fixed Options.MaxTokens. Fields without a budget get core.FieldTokenBudgets for their type.
+func (cot *ChainOfThought) WithMaxTokensPerField(budgets map[string]int) *ChainOfThought {
+	cot.MaxTokensPerField = budgets
+	return cot
+}
+
 // WithAdapter sets a custom adapter
+// A rationale field or prompt set earlier is applied to it.
 func (cot *ChainOfThought) WithAdapter(adapter core.Adapter) *ChainOfThought {
 	cot.Adapter = adapter
+	cot.configureRationale()
+	return cot
+}
+
+// WithRationaleField names the field the model writes its reasoning in, e.g. "scratchpad"
+// The reasoning still populates Prediction.Rationale and is removed from the outputs
+// unless the signature declares the field.
+func (cot *ChainOfThought) WithRationaleField(name string) *ChainOfThought {
+	cot.RationaleField = name
+	cot.configureRationale()
+	return cot
+}
+
+// WithRationalePrompt replaces the step-by-step instruction with a domain-specific one,
+// e.g. "First identify the relevant formula, then apply it."
+func (cot *ChainOfThought) WithRationalePrompt(prompt string) *ChainOfThought {
+	cot.RationalePrompt = prompt
+	cot.configureRationale()
+	return cot
+}
+
+// WithHideRationale records only the answer fields, not the reasoning, as the assistant
+// turn in History, so later turns don't resend it when only the answers matter
+func (cot *ChainOfThought) WithHideRationale(hide bool) *ChainOfThought {
+	cot.HideRationale = hide
 	return cot
 }
 
+// configureRationale applies the rationale field and prompt to the adapter
+// Adapters without a reasoning field, such as TwoStepAdapter, are left unchanged.
+func (cot *ChainOfThought) configureRationale() {
+	if cot.RationaleField == "" && cot.RationalePrompt == "" {
+		return
+	}
+	switch a := cot.Adapter.(type) {
+	case *core.FallbackAdapter:
+		a.WithReasoningField(cot.RationaleField).WithReasoningPrompt(cot.RationalePrompt)
+	case *core.JSONAdapter:
+		a.WithReasoningField(cot.RationaleField).WithReasoningPrompt(cot.RationalePrompt)
+	case *core.ChatAdapter:
+		a.WithReasoningField(cot.RationaleField).WithReasoningPrompt(cot.RationalePrompt)
+	}
+}
+
 // WithHistory sets conversation history for multi-turn interactions
 func (cot *ChainOfThought) WithHistory(history *core.History) *ChainOfThought {
 	cot.History = history
@@ -51,6 +122,62 @@ func (cot *ChainOfThought) WithDemos(demos []core.Example) *ChainOfThought {
 	return cot
 }
 
+// WithPartialStreamOnError makes Stream send a prediction with the streamed text in
+// Metadata["raw_content"] and its Rationale when the final output cannot be parsed,
+// alongside the error (see Predict.WithPartialStreamOnError)
+func (cot *ChainOfThought) WithPartialStreamOnError(enabled bool) *ChainOfThought {
+	cot.PartialStreamOnError = enabled
+	return cot
+}
+
+// WithMaxDemos uses at most n of the demos per call
+func (cot *ChainOfThought) WithMaxDemos(n int) *ChainOfThought {
+	cot.MaxDemos = n
+	return cot
+}
+
+// WithDemoTruncation cuts string demo values to maxChars characters to keep prompts bounded
+func (cot *ChainOfThought) WithDemoTruncation(maxChars int) *ChainOfThought {
+	cot.DemoMaxChars = maxChars
+	return cot
+}
+
+// WithStrictInputs controls whether inputs are checked against the signature before
+// the LM is called (the default). Pass false to send inputs through unchecked.
+func (cot *ChainOfThought) WithStrictInputs(strict bool) *ChainOfThought {
+	cot.SkipInputValidation = !strict
+	return cot
+}
+
+// validateInputs checks inputs against the signature unless strict inputs are disabled
+func (cot *ChainOfThought) validateInputs(inputs map[string]any) error {
+	if cot.SkipInputValidation {
+		return nil
+	}
+	return cot.Signature.ValidateInputs(inputs)
+}
+
+// WithTimeout sets a deadline for each Forward and Stream call, in place of the global
+// default timeout; an earlier deadline on the caller's context still applies.
+func (cot *ChainOfThought) WithTimeout(timeout time.Duration) *ChainOfThought {
+	cot.Timeout = timeout
+	return cot
+}
+
+// WithFlushInterval makes Stream merge chunks and deliver them at most every interval,
+// for consumers that re-render on each chunk. Finish chunks are never delayed.
+func (cot *ChainOfThought) WithFlushInterval(interval time.Duration) *ChainOfThought {
+	cot.FlushInterval = interval
+	return cot
+}
+
+// WithMinChunkBytes makes Stream merge chunks until they hold at least n bytes of text
+// (see core.CoalesceChunks). Finish chunks are never delayed.
+func (cot *ChainOfThought) WithMinChunkBytes(n int) *ChainOfThought {
+	cot.MinChunkBytes = n
+	return cot
+}
+
 // GetSignature returns the module's signature
 func (cot *ChainOfThought) GetSignature() *core.Signature {
 	return cot.Signature
@@ -58,41 +185,34 @@ func (cot *ChainOfThought) GetSignature() *core.Signature {
 
 // Forward executes the chain of thought reasoning
 func (cot *ChainOfThought) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
-	if err := cot.Signature.ValidateInputs(inputs); err != nil {
-		return nil, fmt.Errorf("input validation failed: %w", err)
-	}
+	return withErrorTrace(ctx, "ChainOfThought", inputs, cot.forward)
+}
 
-	// Use adapter to format messages with demos
-	newMessages, err := cot.Adapter.Format(cot.Signature, inputs, cot.Demos)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format messages: %w", err)
+// forward is Forward without error tracing
+func (cot *ChainOfThought) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, cot.Timeout)
+	defer cancel()
+
+	if err := cot.validateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
 	}
 
-	// Build final message list
-	var messages []core.Message
+	adapter := core.CallAdapter(ctx, cot.Adapter)
 
-	// Prepend history if available
-	if cot.History != nil && !cot.History.IsEmpty() {
-		historyMessages := cot.Adapter.FormatHistory(cot.History)
-		messages = append(messages, historyMessages...)
+	// Format messages with demos and history, fitting the model's context window
+	messages, newMessages, report, err := cot.assemblePrompt(ctx, adapter, inputs)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add new messages
-	messages = append(messages, newMessages...)
+	options := cot.generateOptions(ctx, adapter)
 
-	// Copy options to avoid mutation
-	options := cot.Options.Copy()
-	if cot.LM.SupportsJSON() {
-		if _, isJSON := cot.Adapter.(*core.JSONAdapter); isJSON {
-			options.ResponseFormat = "json"
-			// Auto-generate JSON schema from signature for structured outputs
-			if options.ResponseSchema == nil {
-				options.ResponseSchema = cot.Signature.SignatureToJSONSchema()
-			}
-		}
+	if result, ok := core.DryRun(ctx, cot.LM.Name(), messages, options); ok {
+		return dryRunPrediction("ChainOfThought", inputs, result), nil
 	}
 
 	result, err := cot.LM.Generate(ctx, messages, options)
+	recordCall(ctx, "ChainOfThought", messages, result, err)
 	if err != nil {
 		return nil, fmt.Errorf("LM generation failed: %w", err)
 	}
@@ -109,11 +229,165 @@ func (cot *ChainOfThought) Forward(ctx context.Context, inputs map[string]any) (
 
 	// Check for empty content with finish_reason=stop (actual error)
 	if result.Content == "" && result.FinishReason == "stop" {
-		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop (model error)")
+		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop: %w", core.ErrEmptyResponse)
 	}
 
+	// Reasoning models return their thinking separately or inline in <think> blocks
+	modelReasoning, content := core.ResultReasoning(result)
+
+	prediction, err := cot.finishPrediction(adapter, inputs, newMessages, content, modelReasoning, result.Usage)
+	if err != nil {
+		return nil, err
+	}
+	withDemoReport(prediction, report)
+	return prediction, nil
+}
+
+// generateOptions copies the module options for one call, applying context overrides and JSON mode
+func (cot *ChainOfThought) generateOptions(ctx context.Context, adapter core.Adapter) *core.GenerateOptions {
+	options := cot.Options.Copy()
+	options.MaxTokens = cot.maxTokens()
+	core.ApplyOptionOverrides(ctx, options)
+	if cot.LM.SupportsJSON() {
+		if core.UsesJSONMode(adapter) {
+			options.ResponseFormat = "json"
+			// Auto-generate JSON schema from signature when the model supports structured outputs
+			if options.ResponseSchema == nil && core.CapabilitiesOf(cot.LM).SupportsJSONSchema {
+				options.ResponseSchema = cot.Signature.SignatureToJSONSchema()
+			}
+		}
+	}
+	core.ConstrainEnums(adapter, cot.LM, cot.Signature, options)
+	return options
+}
+
+// Stream executes the chain of thought with streaming output
+// Each chunk carries reasoning text in Reasoning and answer text in Content, so a UI can
+// show the thinking before the answer; field markers are removed from both. The final
+// prediction has the complete Rationale and outputs, as from Forward.
+func (cot *ChainOfThought) Stream(ctx context.Context, inputs map[string]any) (*StreamResult, error) {
+	if err := cot.validateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
+	}
+
+	adapter := core.CallAdapter(ctx, cot.Adapter)
+
+	// The deadline covers the whole stream, so the goroutine below cancels it when done
+	ctx, cancel := withTimeout(ctx, cot.Timeout)
+
+	// Format messages with demos and history, fitting the model's context window
+	messages, newMessages, report, err := cot.assemblePrompt(ctx, adapter, inputs)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	options := cot.generateOptions(ctx, adapter)
+	if result, ok := core.DryRun(ctx, cot.LM.Name(), messages, options); ok {
+		cancel()
+		return dryRunStream(dryRunPrediction("ChainOfThought", inputs, result)), nil
+	}
+	chunkChan, errChan := cot.LM.Stream(ctx, messages, options)
+	chunkChan = core.CoalesceChunks(ctx, chunkChan, cot.FlushInterval, cot.MinChunkBytes)
+
+	progress := newStreamProgress(cot.LM.Name(), time.Now())
+	outputChunks := make(chan core.Chunk)
+	predictionChan := make(chan *core.Prediction, 1)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer cancel()
+		defer close(outputChunks)
+		defer close(predictionChan)
+		defer close(errorChan)
+
+		// send forwards a chunk unless the caller has gone away
+		send := func(chunk core.Chunk) bool {
+			select {
+			case outputChunks <- chunk:
+			case <-ctx.Done():
+				errorChan <- fmt.Errorf("LM streaming failed: %w", ctx.Err())
+				return false
+			}
+			if options.StreamCallback != nil {
+				options.StreamCallback(chunk)
+			}
+			return true
+		}
+
+		splitter := core.NewStreamingReasoningSplitter().WithReasoningField(cot.RationaleField).ForAdapter(adapter)
+		var content, modelReasoning strings.Builder
+		var finalUsage core.Usage
+
+		for chunk := range chunkChan {
+			content.WriteString(chunk.Content)
+			modelReasoning.WriteString(chunk.Reasoning)
+			if chunk.Usage.TotalTokens > 0 {
+				finalUsage = chunk.Usage
+			}
+
+			reasoning, answer := splitter.Process(chunk.Content)
+			out := core.Chunk{
+				Content:      answer,
+				Reasoning:    chunk.Reasoning + reasoning,
+				FinishReason: chunk.FinishReason,
+				Usage:        chunk.Usage,
+			}
+			if out.Content != "" || out.Reasoning != "" || out.FinishReason != "" || out.Usage.TotalTokens != 0 {
+				if !send(out) {
+					return
+				}
+			}
+			progress.observe(chunk)
+		}
+
+		if reasoning, answer := splitter.Flush(); reasoning != "" || answer != "" {
+			if !send(core.Chunk{Content: answer, Reasoning: reasoning}) {
+				return
+			}
+		}
+
+		// Check for streaming errors
+		select {
+		case err := <-errChan:
+			if err != nil {
+				errorChan <- fmt.Errorf("LM streaming failed: %w", err)
+				return
+			}
+		default:
+		}
+
+		// Parse the full response exactly as Forward does
+		thinking, answer := core.SplitReasoning(content.String())
+		if modelReasoning.Len() > 0 {
+			thinking = modelReasoning.String()
+		}
+		prediction, err := cot.finishPrediction(adapter, inputs, newMessages, answer, thinking, finalUsage)
+		if err != nil {
+			if cot.PartialStreamOnError {
+				predictionChan <- partialStreamPrediction("ChainOfThought", inputs, answer, finalUsage, err).
+					WithRationale(thinking)
+			}
+			errorChan <- err
+			return
+		}
+		withDemoReport(prediction, report)
+		predictionChan <- prediction
+	}()
+
+	return &StreamResult{
+		Chunks:     outputChunks,
+		Prediction: predictionChan,
+		Errors:     errorChan,
+		progress:   progress,
+	}, nil
+}
+
+// finishPrediction parses content into a prediction with its rationale and records the turn in History
+// modelReasoning is the model's own thinking, used when the outputs carry no reasoning field.
+func (cot *ChainOfThought) finishPrediction(adapter core.Adapter, inputs map[string]any, newMessages []core.Message, content, modelReasoning string, usage core.Usage) (*core.Prediction, error) {
 	// Use adapter to parse output
-	outputs, err := cot.Adapter.Parse(cot.Signature, result.Content)
+	outputs, err := adapter.Parse(cot.Signature, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse output: %w", err)
 	}
@@ -125,13 +399,14 @@ func (cot *ChainOfThought) Forward(ctx context.Context, inputs map[string]any) (
 	// Extract adapter metadata
 	adapterUsed, parseAttempts, fallbackUsed := core.ExtractAdapterMetadata(outputs)
 
-	// Extract rationale from outputs
-	rationale := ""
-	if reasoning, exists := outputs["reasoning"]; exists {
+	// Extract rationale from outputs, falling back to the model's own reasoning
+	rationale := modelReasoning
+	rationaleField := core.ReasoningFieldOrDefault(cot.RationaleField)
+	if reasoning, exists := outputs[rationaleField]; exists {
 		rationale = fmt.Sprintf("%v", reasoning)
 		// Remove reasoning from outputs if not part of signature
-		if cot.Signature.GetOutputField("reasoning") == nil {
-			delete(outputs, "reasoning")
+		if cot.Signature.GetOutputField(rationaleField) == nil {
+			delete(outputs, rationaleField)
 		}
 	}
 
@@ -145,16 +420,19 @@ func (cot *ChainOfThought) Forward(ctx context.Context, inputs map[string]any) (
 		}
 
 		// Add assistant response
+		if cot.HideRationale {
+			content = answerContent(cot.Signature, outputs)
+		}
 		cot.History.Add(core.Message{
 			Role:    "assistant",
-			Content: result.Content,
+			Content: content,
 		})
 	}
 
 	// Build Prediction object with rationale
 	prediction := core.NewPrediction(outputs).
 		WithRationale(rationale).
-		WithUsage(result.Usage).
+		WithUsage(usage).
 		WithModuleName("ChainOfThought").
 		WithInputs(inputs)
 
@@ -165,3 +443,72 @@ func (cot *ChainOfThought) Forward(ctx context.Context, inputs map[string]any) (
 
 	return prediction, nil
 }
+
+// answerContent renders the signature's output fields as a JSON object, leaving out the
+// rationale and adapter metadata
+func answerContent(sig *core.Signature, outputs map[string]any) string {
+	answer := make(map[string]any, len(sig.OutputFields))
+	for _, field := range sig.OutputFields {
+		if value, ok := outputs[field.Name]; ok {
+			answer[field.Name] = value
+		}
+	}
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return fmt.Sprintf("%v", answer)
+	}
+	return string(data)
+}
+
+// assemblePrompt formats inputs, demos and history into messages, applying the
+// configured context-window truncation policy, and appends corrective feedback from an
+// enclosing Assert, if any
+func (cot *ChainOfThought) assemblePrompt(ctx context.Context, adapter core.Adapter, inputs map[string]any) ([]core.Message, []core.Message, core.AssemblyReport, error) {
+	messages, newMessages, report, err := assemble(ctx, "ChainOfThought", cot.LM.Name(), core.PromptAssembly{
+		Adapter:   adapter,
+		Signature: cot.Signature,
+		Inputs:    inputs,
+		Demos:     core.LimitDemos(cot.Demos, cot.MaxDemos, cot.DemoMaxChars),
+		History:   cot.History,
+		MaxTokens: cot.maxTokens(),
+	})
+	if err != nil {
+		return nil, nil, report, err
+	}
+	return core.ApplyFeedback(ctx, messages), newMessages, report, nil
+}
+
+// maxTokens returns the configured MaxTokens, or an estimate from the signature that
+// leaves room for the rationale when MaxTokens is unset or per-field budgets are given
+func (cot *ChainOfThought) maxTokens() int {
+	if cot.Options.MaxTokens > 0 && cot.MaxTokensPerField == nil {
+		return cot.Options.MaxTokens
+	}
+	return cot.Signature.EstimateMaxTokens(cot.MaxTokensPerField) + core.FieldTokenBudgets[core.FieldTypeString]
+}
+
+// BuildPrompt returns the exact messages Forward would send for inputs,
+// without calling the LM
+func (cot *ChainOfThought) BuildPrompt(inputs map[string]any) ([]core.Message, error) {
+	return cot.BuildPromptContext(context.Background(), inputs)
+}
+
+// BuildPromptContext is BuildPrompt for a Forward call made with ctx, honouring a
+// per-call adapter and Assert feedback on ctx like Forward does
+func (cot *ChainOfThought) BuildPromptContext(ctx context.Context, inputs map[string]any) ([]core.Message, error) {
+	if err := cot.validateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
+	}
+	messages, _, _, err := cot.assemblePrompt(ctx, core.CallAdapter(ctx, cot.Adapter), inputs)
+	return messages, err
+}
+
+// EstimateUsage estimates the prompt tokens Forward would consume for inputs,
+// without calling the LM
+func (cot *ChainOfThought) EstimateUsage(inputs map[string]any) (core.Usage, error) {
+	messages, err := cot.BuildPrompt(inputs)
+	if err != nil {
+		return core.Usage{}, err
+	}
+	return estimatePromptUsage(cot.LM.Name(), messages, cot.Options.Tools), nil
+}
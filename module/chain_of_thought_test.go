@@ -3,10 +3,13 @@ package module
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/assagman/dsgo/core"
+	_ "github.com/assagman/dsgo/providers/openai"
 )
 
 func TestChainOfThought_Forward_Success(t *testing.T) {
@@ -104,7 +107,7 @@ func TestChainOfThought_BuildPrompt(t *testing.T) {
 
 	lm := &MockLM{
 		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
-			content := messages[0].Content
+			content := messages[len(messages)-1].Content
 			if !contains(content, "step-by-step") {
 				t.Error("Prompt should include step-by-step instruction")
 			}
@@ -271,3 +274,230 @@ func TestChainOfThought_FinishReasonHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestChainOfThought_Stream_SeparatesReasoning(t *testing.T) {
+	sig := core.NewSignature("Solve the math problem").
+		AddInput("problem", core.FieldTypeString, "Problem").
+		AddOutput("answer", core.FieldTypeInt, "Answer")
+
+	lm := &mockStreamingLM{
+		chunks: []core.Chunk{
+			{Content: "[[ ## reas"},
+			{Content: "oning ## ]]\n17 * 3 = 51, "},
+			{Content: "plus 4 is 55.\n\n[[ ## ans"},
+			{Content: "wer ## ]]\n55\n\n[[ ## completed ## ]]"},
+			{FinishReason: "stop", Usage: core.Usage{TotalTokens: 42}},
+		},
+	}
+
+	cot := NewChainOfThought(sig, lm)
+	result, err := cot.Stream(context.Background(), map[string]any{"problem": "17 * 3 + 4"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	var reasoning, answer strings.Builder
+	for chunk := range result.Chunks {
+		reasoning.WriteString(chunk.Reasoning)
+		answer.WriteString(chunk.Content)
+	}
+	if err := <-result.Errors; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if got := strings.TrimSpace(reasoning.String()); got != "17 * 3 = 51, plus 4 is 55." {
+		t.Errorf("streamed reasoning = %q", got)
+	}
+	if got := strings.TrimSpace(answer.String()); got != "55" {
+		t.Errorf("streamed answer = %q, want %q", got, "55")
+	}
+
+	prediction := <-result.Prediction
+	if prediction == nil {
+		t.Fatal("expected a final prediction")
+	}
+	if prediction.Rationale != "17 * 3 = 51, plus 4 is 55." {
+		t.Errorf("Rationale = %q", prediction.Rationale)
+	}
+	if got, _ := prediction.GetInt("answer"); got != 55 {
+		t.Errorf("answer = %v, want 55", prediction.Outputs["answer"])
+	}
+	if _, ok := prediction.Outputs["reasoning"]; ok {
+		t.Error("reasoning should not remain in outputs")
+	}
+	if prediction.Usage.TotalTokens != 42 {
+		t.Errorf("usage = %d, want 42", prediction.Usage.TotalTokens)
+	}
+}
+
+func TestChainOfThought_RationaleField(t *testing.T) {
+	sig := core.NewSignature("Compute the area").
+		AddInput("shape", core.FieldTypeString, "").
+		AddOutput("area", core.FieldTypeString, "")
+
+	var prompt string
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			prompt = messages[len(messages)-1].Content
+			return &core.GenerateResult{
+				Content: "[[ ## scratchpad ## ]]\nArea is w * h = 3 * 4.\n\n[[ ## area ## ]]\n12",
+			}, nil
+		},
+	}
+	history := core.NewHistory()
+	cot := NewChainOfThought(sig, lm).
+		WithRationaleField("scratchpad").
+		WithRationalePrompt("First identify the relevant formula.").
+		WithHideRationale(true).
+		WithHistory(history)
+
+	pred, err := cot.Forward(context.Background(), map[string]any{"shape": "3x4 rectangle"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if !strings.Contains(prompt, "First identify the relevant formula.") || strings.Contains(prompt, "step-by-step before") {
+		t.Errorf("prompt should carry the custom rationale prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "[[ ## scratchpad ## ]]") {
+		t.Errorf("prompt should request the scratchpad field, got:\n%s", prompt)
+	}
+	if pred.Rationale != "Area is w * h = 3 * 4." {
+		t.Errorf("Rationale = %q", pred.Rationale)
+	}
+	if _, ok := pred.Outputs["scratchpad"]; ok {
+		t.Error("scratchpad should not remain in outputs")
+	}
+
+	messages := history.Get()
+	if len(messages) != 2 {
+		t.Fatalf("history has %d messages, want 2", len(messages))
+	}
+	if answer := messages[1].Content; strings.Contains(answer, "w * h") || !strings.Contains(answer, "12") {
+		t.Errorf("history answer = %q, want the answer without the rationale", answer)
+	}
+}
+
+func TestChainOfThought_Stream_ProviderReasoning(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+
+	// A reasoning model streams its thinking in reasoning_content, apart from the answer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"choices":[{"index":0,"delta":{"reasoning_content":"17 * 3 = 51, "}}]}`,
+			`{"choices":[{"index":0,"delta":{"reasoning_content":"plus 4 is 55."}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"[[ ## answer ## ]]\n55\n\n"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"[[ ## completed ## ]]"},"finish_reason":"stop"}]}`,
+		} {
+			_, _ = w.Write([]byte("data: " + data + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+	core.Configure(core.WithAPIKey("openai", "test-key"), core.WithProviderBaseURL("openai", server.URL))
+
+	lm, err := core.NewLM(context.Background(), "openai/deepseek-reasoner")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	sig := core.NewSignature("Solve the math problem").
+		AddInput("problem", core.FieldTypeString, "Problem").
+		AddOutput("answer", core.FieldTypeInt, "Answer")
+
+	result, err := NewChainOfThought(sig, lm).Stream(context.Background(), map[string]any{"problem": "17 * 3 + 4"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	var reasoning, answer strings.Builder
+	for chunk := range result.Chunks {
+		reasoning.WriteString(chunk.Reasoning)
+		answer.WriteString(chunk.Content)
+	}
+	if err := <-result.Errors; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if got := reasoning.String(); got != "17 * 3 = 51, plus 4 is 55." {
+		t.Errorf("streamed reasoning = %q", got)
+	}
+	if got := strings.TrimSpace(answer.String()); got != "55" {
+		t.Errorf("streamed answer = %q, want %q", got, "55")
+	}
+	prediction := <-result.Prediction
+	if prediction == nil {
+		t.Fatal("expected a final prediction")
+	}
+	if prediction.Rationale != "17 * 3 = 51, plus 4 is 55." {
+		t.Errorf("Rationale = %q", prediction.Rationale)
+	}
+	if got, _ := prediction.GetInt("answer"); got != 55 {
+		t.Errorf("answer = %v, want 55", prediction.Outputs["answer"])
+	}
+}
+
+func TestChainOfThought_Stream_CustomFieldDelimiter(t *testing.T) {
+	sig := core.NewSignature("Solve the math problem").
+		AddInput("problem", core.FieldTypeString, "Problem").
+		AddOutput("answer", core.FieldTypeInt, "Answer")
+	lm := &mockStreamingLM{chunks: []core.Chunk{
+		{Content: "<<<reas"},
+		{Content: "oning>>>\n17 * 3 = 51, plus 4 is 55.\n\n<<"},
+		{Content: "<answer>>>\n55"},
+		{FinishReason: "stop"},
+	}}
+
+	adapter := core.NewChatAdapter().WithReasoning(true).WithFieldDelimiter("<<<", ">>>")
+	result, err := NewChainOfThought(sig, lm).WithAdapter(adapter).
+		Stream(context.Background(), map[string]any{"problem": "17 * 3 + 4"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	var reasoning, answer strings.Builder
+	for chunk := range result.Chunks {
+		reasoning.WriteString(chunk.Reasoning)
+		answer.WriteString(chunk.Content)
+	}
+	if err := <-result.Errors; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if got := strings.TrimSpace(reasoning.String()); got != "17 * 3 = 51, plus 4 is 55." {
+		t.Errorf("streamed reasoning = %q", got)
+	}
+	if got := strings.TrimSpace(answer.String()); got != "55" {
+		t.Errorf("streamed answer = %q, want %q", got, "55")
+	}
+}
+
+func TestChainOfThought_Stream_RationaleField(t *testing.T) {
+	sig := core.NewSignature("Compute the area").
+		AddInput("shape", core.FieldTypeString, "").
+		AddOutput("area", core.FieldTypeString, "")
+	lm := &mockStreamingLM{chunks: []core.Chunk{
+		{Content: "[[ ## scratch"},
+		{Content: "pad ## ]]\nArea is 3 * 4.\n\n[[ ## area ## ]]\n12"},
+		{FinishReason: "stop"},
+	}}
+
+	result, err := NewChainOfThought(sig, lm).WithRationaleField("scratchpad").
+		Stream(context.Background(), map[string]any{"shape": "3x4 rectangle"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	var reasoning strings.Builder
+	for chunk := range result.Chunks {
+		reasoning.WriteString(chunk.Reasoning)
+	}
+	if err := <-result.Errors; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if got := strings.TrimSpace(reasoning.String()); got != "Area is 3 * 4." {
+		t.Errorf("streamed reasoning = %q", got)
+	}
+	if pred := <-result.Prediction; pred == nil || pred.Rationale != "Area is 3 * 4." {
+		t.Errorf("prediction = %+v, want the scratchpad as Rationale", pred)
+	}
+}
@@ -0,0 +1,141 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExecutionResult is the outcome of running generated code
+type ExecutionResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// CodeExecutor runs the code ProgramOfThought generates. Implementations decide where
+// it runs (a local subprocess, a container, a remote sandbox), so sandboxing is the
+// caller's choice. ctx carries the execution timeout. A program that runs and exits
+// non-zero is reported through ExitCode; an error means it could not be run at all.
+type CodeExecutor interface {
+	Execute(ctx context.Context, code string) (*ExecutionResult, error)
+}
+
+// CodeExecutorFunc adapts a function to CodeExecutor
+type CodeExecutorFunc func(ctx context.Context, code string) (*ExecutionResult, error)
+
+// Execute calls f(ctx, code)
+func (f CodeExecutorFunc) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
+	return f(ctx, code)
+}
+
+// CommandExecutor runs code by passing it as the last argument of a command,
+// e.g. python3 -c <code>. It runs without isolation; see poexec.DockerExecutor
+// for a sandboxed container.
+type CommandExecutor struct {
+	Command string
+	Args    []string // Arguments before the code
+	Dir     string   // Working directory (empty = current)
+	Env     []string // Environment (nil = inherit)
+}
+
+// NewCommandExecutor creates an executor that runs command args... <code>
+func NewCommandExecutor(command string, args ...string) *CommandExecutor {
+	return &CommandExecutor{Command: command, Args: args}
+}
+
+// NewPythonExecutor runs code with the local python3 interpreter
+func NewPythonExecutor() *CommandExecutor {
+	return NewCommandExecutor("python3", "-c")
+}
+
+// NewJavaScriptExecutor runs code with the local node runtime
+func NewJavaScriptExecutor() *CommandExecutor {
+	return NewCommandExecutor("node", "-e")
+}
+
+// Execute runs the command with code as its final argument
+func (e *CommandExecutor) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
+	args := append(append([]string(nil), e.Args...), code)
+	cmd := exec.CommandContext(ctx, e.Command, args...)
+	cmd.Dir = e.Dir
+	cmd.Env = e.Env
+	return runCommand(cmd)
+}
+
+// GoExecutor runs code as a Go program with go run in a temporary module directory
+type GoExecutor struct {
+	GoBinary string // go command to use (empty = "go" from PATH)
+}
+
+// NewGoExecutor creates an executor that runs code with the local Go toolchain
+func NewGoExecutor() *GoExecutor {
+	return &GoExecutor{}
+}
+
+// Execute writes code to main.go and runs it
+// A snippet without a package clause is placed in package main.
+func (e *GoExecutor) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
+	dir, err := os.MkdirTemp("", "dsgo-pot-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if !strings.HasPrefix(strings.TrimSpace(code), "package ") {
+		code = "package main\n\n" + code
+	}
+	// A module of its own keeps go run independent of any module around the temp dir
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module program\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write program: %w", err)
+	}
+
+	goBinary := e.GoBinary
+	if goBinary == "" {
+		goBinary = "go"
+	}
+	cmd := exec.CommandContext(ctx, goBinary, "run", "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=")
+	return runCommand(cmd)
+}
+
+// runCommand runs cmd, capturing stdout, stderr and the exit code
+func runCommand(cmd *exec.Cmd) (*ExecutionResult, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on pipes held open by grandchildren after the command is killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	result := &ExecutionResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	return result, err
+}
+
+// defaultExecutor returns the local executor for language, or nil when there is none
+func defaultExecutor(language string) CodeExecutor {
+	switch language {
+	case "python":
+		return NewPythonExecutor()
+	case "javascript":
+		return NewJavaScriptExecutor()
+	case "go":
+		return NewGoExecutor()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package module
+
+import "github.com/assagman/dsgo/core"
+
+// dryRunPrediction builds the prediction of a dry-run call: no outputs, the estimated
+// prompt usage, and the assembled messages and options from the canned result's Metadata
+func dryRunPrediction(moduleName string, inputs map[string]any, result *core.GenerateResult) *core.Prediction {
+	prediction := core.NewPrediction(map[string]any{}).
+		WithUsage(result.Usage).
+		WithModuleName(moduleName).
+		WithInputs(inputs)
+	for key, value := range result.Metadata {
+		prediction.WithMetadata(key, value)
+	}
+	return prediction
+}
+
+// isDryRun reports whether prediction came from a dry-run call, so composite modules
+// stop instead of acting on its empty outputs
+func isDryRun(prediction *core.Prediction) bool {
+	dryRun, _ := prediction.Metadata["dry_run"].(bool)
+	return dryRun
+}
+
+// dryRunStream delivers a dry-run prediction as a finished stream
+func dryRunStream(prediction *core.Prediction) *StreamResult {
+	chunks := make(chan core.Chunk, 1)
+	predictions := make(chan *core.Prediction, 1)
+	errs := make(chan error)
+	chunks <- core.Chunk{FinishReason: core.FinishReasonDryRun, Usage: prediction.Usage}
+	predictions <- prediction
+	close(chunks)
+	close(predictions)
+	close(errs)
+	return &StreamResult{Chunks: chunks, Prediction: predictions, Errors: errs}
+}
@@ -0,0 +1,79 @@
+package module
+
+import (
+	"context"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func TestModules_DryRun(t *testing.T) {
+	sig := core.NewSignature("Answer questions").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	search := core.NewTool("search", "Search the web", func(ctx context.Context, args map[string]any) (any, error) {
+		t.Error("tools must not run during a dry run")
+		return "", nil
+	})
+
+	tests := []struct {
+		name  string
+		build func(lm core.LM) core.Module
+	}{
+		{"Predict", func(lm core.LM) core.Module { return NewPredict(sig, lm) }},
+		{"ChainOfThought", func(lm core.LM) core.Module { return NewChainOfThought(sig, lm) }},
+		{"ReAct", func(lm core.LM) core.Module { return NewReAct(sig, lm, []core.Tool{*search}) }},
+		{"Refine", func(lm core.LM) core.Module { return NewRefine(sig, lm) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := core.NewMockLM().WithToolSupport(true).Respond(`{"answer": "real"}`)
+			ctx := core.WithCallDryRun(context.Background(), true)
+
+			pred, err := tt.build(lm).Forward(ctx, map[string]any{"question": "What is Go?"})
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if lm.CallCount() != 0 {
+				t.Errorf("LM called %d times during a dry run", lm.CallCount())
+			}
+			if pred.ModuleName != tt.name || len(pred.Outputs) != 0 || pred.Metadata["dry_run"] != true {
+				t.Errorf("prediction = %+v, want an empty dry-run prediction from %s", pred, tt.name)
+			}
+			messages, _ := pred.Metadata["messages"].([]core.Message)
+			if len(messages) == 0 || !contains(messages[len(messages)-1].Content, "What is Go?") {
+				t.Errorf("Metadata[messages] = %v, want the assembled prompt", messages)
+			}
+			if pred.Usage.PromptTokens == 0 {
+				t.Error("dry run should estimate prompt tokens")
+			}
+		})
+	}
+}
+
+func TestPredict_Stream_DryRun(t *testing.T) {
+	sig := core.NewSignature("Answer questions").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	lm := core.NewMockLM().Respond(`{"answer": "real"}`)
+
+	result, err := NewPredict(sig, lm).Stream(core.WithCallDryRun(context.Background(), true), map[string]any{"question": "What is Go?"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	for chunk := range result.Chunks {
+		if chunk.FinishReason != core.FinishReasonDryRun {
+			t.Errorf("chunk = %+v, want a dry-run finish", chunk)
+		}
+	}
+	if pred := <-result.Prediction; pred == nil || pred.Metadata["dry_run"] != true {
+		t.Errorf("prediction = %+v, want a dry-run prediction", pred)
+	}
+	if err := <-result.Errors; err != nil {
+		t.Errorf("Errors = %v", err)
+	}
+	if lm.CallCount() != 0 {
+		t.Errorf("LM called %d times during a dry run", lm.CallCount())
+	}
+}
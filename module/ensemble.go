@@ -0,0 +1,345 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
+)
+
+// EnsembleAggregator combines the outputs of the ensemble members that succeeded
+// weights[i] is the weight of outputs[i]; sig is the first member's signature (may be nil).
+type EnsembleAggregator func(sig *core.Signature, outputs []map[string]any, weights []float64) (map[string]any, error)
+
+// Ensemble runs the same inputs through several modules, typically backed by
+// different models, and combines their outputs into one prediction.
+//
+// Unlike BestOfN (one module sampled N times, best candidate wins), every member
+// votes: by default numeric fields are a weighted average and all other fields a
+// weighted majority vote (see WeightedVote). Each member's prediction is available
+// via Step("member_<i>") and its outputs in Completions, with the member weights in
+// Scores; Metadata["agreement"] holds, per output field, the share of weight that
+// produced exactly the ensemble value.
+type Ensemble struct {
+	members    []core.Module
+	weights    []float64
+	aggregator EnsembleAggregator
+	timeout    time.Duration // Deadline for each Forward (0 = none)
+}
+
+// NewEnsemble creates an ensemble of members with the given weights
+// A nil weights slice gives every member the same weight.
+func NewEnsemble(members []core.Module, weights []float64) *Ensemble {
+	return &Ensemble{
+		members:    members,
+		weights:    weights,
+		aggregator: WeightedVote,
+	}
+}
+
+// WithAggregator sets how member outputs are combined (default WeightedVote)
+func (e *Ensemble) WithAggregator(aggregator EnsembleAggregator) *Ensemble {
+	e.aggregator = aggregator
+	return e
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (e *Ensemble) WithTimeout(timeout time.Duration) *Ensemble {
+	e.timeout = timeout
+	return e
+}
+
+// GetSignature returns the first member's signature; members are expected to share it
+func (e *Ensemble) GetSignature() *core.Signature {
+	if len(e.members) == 0 {
+		return nil
+	}
+	return e.members[0].GetSignature()
+}
+
+// Forward runs all members concurrently and aggregates their outputs
+// Failed members are reported in Metadata["failed_members"]; Forward only errors if all fail.
+func (e *Ensemble) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Ensemble", inputs, e.forward)
+}
+
+// forward is Forward without error tracing
+func (e *Ensemble) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancelTimeout := withTimeout(ctx, e.timeout)
+	defer cancelTimeout()
+
+	ctx = logging.EnsureRequestID(ctx)
+	startTime := time.Now()
+	logging.LogPredictionStart(ctx, "Ensemble", "Ensemble execution")
+
+	var predErr error
+	defer func() {
+		logging.LogPredictionEnd(ctx, "Ensemble", time.Since(startTime), predErr)
+	}()
+
+	weights, err := e.memberWeights()
+	if err != nil {
+		predErr = err
+		return nil, predErr
+	}
+
+	predictions := make([]*core.Prediction, len(e.members))
+	errs := make([]error, len(e.members))
+	var wg sync.WaitGroup
+	for i, m := range e.members {
+		wg.Add(1)
+		go func(i int, m core.Module) {
+			defer wg.Done()
+			pred, err := m.Forward(ctx, inputs)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s failed: %w", memberKey(i), err)
+				return
+			}
+			predictions[i] = pred
+		}(i, m)
+	}
+	wg.Wait()
+
+	var outputs []map[string]any
+	var outputWeights []float64
+	var usage core.Usage
+	failed := make(map[string]string)
+	for i, pred := range predictions {
+		if pred == nil {
+			failed[memberKey(i)] = errs[i].Error()
+			continue
+		}
+		outputs = append(outputs, pred.Outputs)
+		outputWeights = append(outputWeights, weights[i])
+		usage.Add(pred.Usage)
+	}
+	if len(outputs) == 0 {
+		predErr = fmt.Errorf("all ensemble members failed: %w", errors.Join(errs...))
+		return nil, predErr
+	}
+
+	aggregated, err := e.aggregator(e.GetSignature(), outputs, outputWeights)
+	if err != nil {
+		predErr = fmt.Errorf("ensemble aggregation failed: %w", err)
+		return nil, predErr
+	}
+
+	prediction := core.NewPrediction(aggregated).
+		WithModuleName("Ensemble").
+		WithInputs(inputs).
+		WithUsage(usage).
+		WithMetadata("agreement", agreement(aggregated, outputs, outputWeights))
+	for i, pred := range predictions {
+		if pred != nil {
+			prediction.WithStep(memberKey(i), pred).WithSubUsage(memberKey(i), pred.Usage)
+		}
+	}
+	prediction.Completions = outputs
+	prediction.Scores = outputWeights
+	if len(failed) > 0 {
+		prediction.WithMetadata("failed_members", failed)
+	}
+	return prediction, nil
+}
+
+// memberWeights validates the configuration and returns one weight per member
+func (e *Ensemble) memberWeights() ([]float64, error) {
+	if len(e.members) == 0 {
+		return nil, fmt.Errorf("ensemble has no members")
+	}
+	if e.aggregator == nil {
+		return nil, fmt.Errorf("ensemble aggregator is nil")
+	}
+	if e.weights == nil {
+		weights := make([]float64, len(e.members))
+		for i := range weights {
+			weights[i] = 1
+		}
+		return weights, nil
+	}
+	if len(e.weights) != len(e.members) {
+		return nil, fmt.Errorf("ensemble has %d members but %d weights", len(e.members), len(e.weights))
+	}
+	for i, w := range e.weights {
+		if w < 0 || math.IsNaN(w) {
+			return nil, fmt.Errorf("ensemble weight %d is invalid: %v", i, w)
+		}
+	}
+	return e.weights, nil
+}
+
+// memberKey names member i in Prediction.Steps and SubUsage
+func memberKey(i int) string {
+	return fmt.Sprintf("member_%d", i)
+}
+
+// WeightedVote is the default EnsembleAggregator
+// Int and float fields (or, without a signature field, keys whose values are all
+// numbers) are averaged by weight, ints rounded; every other field takes the value
+// with the most weight behind it, ties going to the earlier member. Members that
+// did not return a field do not take part in its vote.
+func WeightedVote(sig *core.Signature, outputs []map[string]any, weights []float64) (map[string]any, error) {
+	result := make(map[string]any)
+	for _, key := range outputKeys(sig, outputs) {
+		var fieldType core.FieldType
+		if sig != nil {
+			if field := sig.GetOutputField(key); field != nil {
+				fieldType = field.Type
+			}
+		}
+
+		switch {
+		case fieldType == core.FieldTypeInt || fieldType == core.FieldTypeFloat ||
+			(fieldType == "" && allNumeric(key, outputs)):
+			avg, ok := weightedAverage(key, outputs, weights)
+			if !ok {
+				return nil, fmt.Errorf("no member returned a number for field %q", key)
+			}
+			if fieldType == core.FieldTypeInt {
+				result[key] = int(math.Round(avg))
+			} else {
+				result[key] = avg
+			}
+		default:
+			result[key] = majority(key, outputs, weights)
+		}
+	}
+	return result, nil
+}
+
+// outputKeys returns signature output fields first, then any other keys, in first-seen order
+func outputKeys(sig *core.Signature, outputs []map[string]any) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	if sig != nil {
+		for _, field := range sig.OutputFields {
+			for _, out := range outputs {
+				if _, ok := out[field.Name]; ok && !seen[field.Name] {
+					seen[field.Name] = true
+					keys = append(keys, field.Name)
+				}
+			}
+		}
+	}
+	for _, out := range outputs {
+		var extra []string
+		for k := range out {
+			if !seen[k] {
+				seen[k] = true
+				extra = append(extra, k)
+			}
+		}
+		sort.Strings(extra)
+		keys = append(keys, extra...)
+	}
+	return keys
+}
+
+// allNumeric reports whether every member value for key is a number
+func allNumeric(key string, outputs []map[string]any) bool {
+	found := false
+	for _, out := range outputs {
+		v, ok := out[key]
+		if !ok {
+			continue
+		}
+		if _, ok := toFloat(v); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// weightedAverage averages the numeric values for key by member weight
+func weightedAverage(key string, outputs []map[string]any, weights []float64) (float64, bool) {
+	var sum, total float64
+	for i, out := range outputs {
+		f, ok := toFloat(out[key])
+		if !ok {
+			continue
+		}
+		sum += f * weights[i]
+		total += weights[i]
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total, true
+}
+
+// majority returns the value for key with the most weight behind it
+func majority(key string, outputs []map[string]any, weights []float64) any {
+	tally := make(map[string]float64)
+	var order []string
+	values := make(map[string]any)
+	for i, out := range outputs {
+		v, ok := out[key]
+		if !ok {
+			continue
+		}
+		k := fmt.Sprint(v)
+		if _, seen := values[k]; !seen {
+			values[k] = v
+			order = append(order, k)
+		}
+		tally[k] += weights[i]
+	}
+
+	if len(order) == 0 {
+		return nil
+	}
+	best := order[0]
+	for _, k := range order[1:] {
+		if tally[k] > tally[best] {
+			best = k
+		}
+	}
+	return values[best]
+}
+
+// agreement returns, per aggregated field, the share of member weight that produced exactly that value
+func agreement(aggregated map[string]any, outputs []map[string]any, weights []float64) map[string]float64 {
+	result := make(map[string]float64, len(aggregated))
+	for key, value := range aggregated {
+		want := fmt.Sprint(value)
+		var agree, total float64
+		for i, out := range outputs {
+			v, ok := out[key]
+			if !ok {
+				continue
+			}
+			total += weights[i]
+			if fmt.Sprint(v) == want {
+				agree += weights[i]
+			}
+		}
+		if total > 0 {
+			result[key] = agree / total
+		}
+	}
+	return result
+}
+
+// toFloat converts numeric output values to float64
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
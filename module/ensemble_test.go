@@ -0,0 +1,154 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func ensembleSignature() *core.Signature {
+	return core.NewSignature("Classify a review").
+		AddInput("review", core.FieldTypeString, "").
+		AddClassOutput("sentiment", []string{"positive", "negative", "neutral"}, "").
+		AddOutput("stars", core.FieldTypeInt, "").
+		AddOutput("confidence", core.FieldTypeFloat, "")
+}
+
+// memberModule returns a member with the shared ensemble signature
+func memberModule(outputs map[string]interface{}, tokens int) *MockModule {
+	m := outputModule(outputs, tokens)
+	m.SignatureValue = ensembleSignature()
+	return m
+}
+
+func TestEnsemble_WeightedVote(t *testing.T) {
+	ensemble := NewEnsemble([]core.Module{
+		memberModule(map[string]interface{}{"sentiment": "positive", "stars": 4, "confidence": 0.8}, 10),
+		memberModule(map[string]interface{}{"sentiment": "negative", "stars": 2, "confidence": 0.6}, 20),
+		memberModule(map[string]interface{}{"sentiment": "negative", "stars": 2, "confidence": 0.5}, 30),
+	}, []float64{3, 1, 1})
+
+	pred, err := ensemble.Forward(context.Background(), map[string]interface{}{"review": "great"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	// positive carries 3 of 5 weight; numbers are weighted averages
+	if pred.Outputs["sentiment"] != "positive" {
+		t.Errorf("sentiment = %v, want positive", pred.Outputs["sentiment"])
+	}
+	if pred.Outputs["stars"] != 3 { // (4*3 + 2 + 2) / 5 = 3.2
+		t.Errorf("stars = %v, want 3", pred.Outputs["stars"])
+	}
+	if c, _ := pred.Outputs["confidence"].(float64); c < 0.70-1e-9 || c > 0.70+1e-9 { // (2.4 + 0.6 + 0.5) / 5
+		t.Errorf("confidence = %v, want 0.7", pred.Outputs["confidence"])
+	}
+
+	if pred.Usage.TotalTokens != 60 {
+		t.Errorf("Usage.TotalTokens = %d, want 60", pred.Usage.TotalTokens)
+	}
+	if len(pred.Completions) != 3 || pred.Step("member_1").Outputs["sentiment"] != "negative" {
+		t.Errorf("member outputs not inspectable: completions=%v", pred.Completions)
+	}
+	if pred.SubUsage["member_2"].TotalTokens != 30 {
+		t.Errorf("SubUsage[member_2] = %+v", pred.SubUsage["member_2"])
+	}
+	agreement := pred.Metadata["agreement"].(map[string]float64)
+	if agreement["sentiment"] != 0.6 {
+		t.Errorf("agreement[sentiment] = %v, want 0.6", agreement["sentiment"])
+	}
+}
+
+func TestEnsemble_EqualWeightsTieGoesToFirstMember(t *testing.T) {
+	ensemble := NewEnsemble([]core.Module{
+		memberModule(map[string]interface{}{"sentiment": "neutral"}, 1),
+		memberModule(map[string]interface{}{"sentiment": "positive"}, 1),
+	}, nil)
+
+	pred, err := ensemble.Forward(context.Background(), map[string]interface{}{"review": "ok"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["sentiment"] != "neutral" {
+		t.Errorf("sentiment = %v, want neutral", pred.Outputs["sentiment"])
+	}
+}
+
+func TestEnsemble_PartialFailure(t *testing.T) {
+	failing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return nil, errors.New("rate limited")
+		},
+	}
+	ensemble := NewEnsemble([]core.Module{
+		failing,
+		memberModule(map[string]interface{}{"sentiment": "negative"}, 5),
+	}, []float64{10, 1})
+
+	pred, err := ensemble.Forward(context.Background(), map[string]interface{}{"review": "bad"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["sentiment"] != "negative" {
+		t.Errorf("sentiment = %v, want negative", pred.Outputs["sentiment"])
+	}
+	failed := pred.Metadata["failed_members"].(map[string]string)
+	if !strings.Contains(failed["member_0"], "rate limited") {
+		t.Errorf("failed_members = %v", failed)
+	}
+}
+
+func TestEnsemble_CustomAggregator(t *testing.T) {
+	ensemble := NewEnsemble([]core.Module{
+		memberModule(map[string]interface{}{"sentiment": "positive"}, 1),
+		memberModule(map[string]interface{}{"sentiment": "negative"}, 1),
+	}, nil).WithAggregator(func(sig *core.Signature, outputs []map[string]any, weights []float64) (map[string]any, error) {
+		// Unanimous or neutral
+		for _, out := range outputs[1:] {
+			if out["sentiment"] != outputs[0]["sentiment"] {
+				return map[string]any{"sentiment": "neutral"}, nil
+			}
+		}
+		return outputs[0], nil
+	})
+
+	pred, err := ensemble.Forward(context.Background(), map[string]interface{}{"review": "mixed"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Outputs["sentiment"] != "neutral" {
+		t.Errorf("sentiment = %v, want neutral", pred.Outputs["sentiment"])
+	}
+}
+
+func TestEnsemble_Errors(t *testing.T) {
+	failing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	member := memberModule(map[string]interface{}{"sentiment": "positive"}, 1)
+
+	tests := []struct {
+		name     string
+		ensemble *Ensemble
+		wantErr  string
+	}{
+		{"no members", NewEnsemble(nil, nil), "no members"},
+		{"weight count mismatch", NewEnsemble([]core.Module{member}, []float64{1, 2}), "1 members but 2 weights"},
+		{"negative weight", NewEnsemble([]core.Module{member}, []float64{-1}), "weight 0 is invalid"},
+		{"all members fail", NewEnsemble([]core.Module{failing, failing}, nil), "all ensemble members failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.ensemble.Forward(context.Background(), map[string]interface{}{"review": "x"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Forward() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
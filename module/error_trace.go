@@ -0,0 +1,172 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
+)
+
+// ErrorTrace is the JSON document written for a failed Forward when
+// core.WithTraceOnError is configured
+type ErrorTrace struct {
+	Time      time.Time      `json:"time"`
+	RequestID string         `json:"request_id"`
+	Module    string         `json:"module"`           // Outermost module that was called
+	Stages    []string       `json:"stages,omitempty"` // Modules the error passed through, innermost first
+	Inputs    map[string]any `json:"inputs"`
+	Calls     []TraceCall    `json:"calls"` // Every LM call made during the Forward, in order
+	// ParseAttempts lists each adapter's failure when the error was a parse error
+	ParseAttempts []string `json:"parse_attempts,omitempty"`
+	// ToolSchemas are the function schemas of the tools offered by ReAct agents in the Forward
+	ToolSchemas []map[string]any `json:"tool_schemas,omitempty"`
+	Error       string           `json:"error"`
+}
+
+// TraceCall is one LM call in an ErrorTrace
+type TraceCall struct {
+	Module       string          `json:"module"`
+	Messages     []core.Message  `json:"messages"` // Assembled prompt
+	Response     string          `json:"response,omitempty"`
+	ToolCalls    []core.ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string          `json:"finish_reason,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// traceRecorder collects the calls of one top-level Forward
+// It is shared by nested and concurrent modules, hence the mutex.
+type traceRecorder struct {
+	mu          sync.Mutex
+	calls       []TraceCall
+	stages      []string
+	toolSchemas []map[string]any
+}
+
+type traceRecorderKey struct{}
+
+// withErrorTrace runs forward, writing an ErrorTrace to the configured directory if it fails
+// Nested modules only record themselves as a stage; the outermost one writes the file.
+func withErrorTrace(ctx context.Context, module string, inputs map[string]any, forward func(context.Context, map[string]any) (*core.Prediction, error)) (*core.Prediction, error) {
+	if recorder, ok := ctx.Value(traceRecorderKey{}).(*traceRecorder); ok {
+		prediction, err := forward(ctx, inputs)
+		if err != nil {
+			recorder.mu.Lock()
+			recorder.stages = append(recorder.stages, module)
+			recorder.mu.Unlock()
+		}
+		return prediction, err
+	}
+
+	dir := core.GetSettings().TraceOnErrorDir
+	if dir == "" {
+		return forward(ctx, inputs)
+	}
+
+	ctx = logging.EnsureRequestID(ctx)
+	recorder := &traceRecorder{}
+	prediction, err := forward(context.WithValue(ctx, traceRecorderKey{}, recorder), inputs)
+	if err != nil {
+		recorder.mu.Lock()
+		trace := ErrorTrace{
+			Time:      time.Now(),
+			RequestID: logging.GetRequestID(ctx),
+			Module:    module,
+			Stages:    append(recorder.stages, module),
+			Inputs:    inputs,
+			Calls:     recorder.calls,
+			Error:     err.Error(),
+		}
+		trace.ToolSchemas = recorder.toolSchemas
+		recorder.mu.Unlock()
+
+		var parseErr *core.ParseError
+		if errors.As(err, &parseErr) {
+			trace.ParseAttempts = parseErr.Attempts
+			if len(trace.ParseAttempts) == 0 {
+				trace.ParseAttempts = []string{fmt.Sprintf("%s: %v", parseErr.Adapter, parseErr.Err)}
+			}
+		}
+		writeErrorTrace(ctx, dir, trace)
+	}
+	return prediction, err
+}
+
+// recordCall adds an LM call to the trace being collected, if any
+func recordCall(ctx context.Context, module string, messages []core.Message, result *core.GenerateResult, err error) {
+	recorder, ok := ctx.Value(traceRecorderKey{}).(*traceRecorder)
+	if !ok {
+		return
+	}
+
+	call := TraceCall{
+		Module:   module,
+		Messages: append([]core.Message(nil), messages...),
+	}
+	if result != nil {
+		call.Response = result.Content
+		call.ToolCalls = result.ToolCalls
+		call.FinishReason = result.FinishReason
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	recorder.mu.Lock()
+	recorder.calls = append(recorder.calls, call)
+	recorder.mu.Unlock()
+}
+
+// recordToolSchemas adds the tool schemas of an agent to the trace being collected, if any
+// Schemas are only built when a trace is being collected, and identical schemas from
+// agents run more than once are kept once.
+func recordToolSchemas(ctx context.Context, schemas func() []map[string]any) {
+	recorder, ok := ctx.Value(traceRecorderKey{}).(*traceRecorder)
+	if !ok {
+		return
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, schema := range schemas() {
+		duplicate := false
+		for _, recorded := range recorder.toolSchemas {
+			if reflect.DeepEqual(recorded, schema) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			recorder.toolSchemas = append(recorder.toolSchemas, schema)
+		}
+	}
+}
+
+// writeErrorTrace saves trace as <dir>/<time>-<module>-<request id>.json
+// Failures are logged rather than returned so they never mask the module error.
+func writeErrorTrace(ctx context.Context, dir string, trace ErrorTrace) {
+	logger := logging.GetLogger()
+
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	name := fmt.Sprintf("%s-%s-%s.json", trace.Time.Format("20060102T150405.000"), strings.ToLower(trace.Module), trace.RequestID)
+	path := filepath.Join(dir, name)
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		logger.Warn(ctx, "Failed to write error trace", map[string]any{"dir": dir, "error": err.Error()})
+		return
+	}
+	logger.Info(ctx, "Wrote error trace", map[string]any{"path": path})
+}
@@ -0,0 +1,162 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// readTraces returns the error traces written to dir
+func readTraces(t *testing.T, dir string) []ErrorTrace {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	var traces []ErrorTrace
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		var trace ErrorTrace
+		if err := json.Unmarshal(data, &trace); err != nil {
+			t.Fatalf("invalid trace %s: %v", entry.Name(), err)
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+func TestErrorTrace_WrittenOnFailure(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	dir := t.TempDir()
+	core.Configure(core.WithTraceOnError(dir))
+
+	classify := core.NewSignature("Classify").
+		AddInput("text", core.FieldTypeString, "Text").
+		AddOutput("label", core.FieldTypeString, "Label")
+	score := core.NewSignature("Score").
+		AddInput("label", core.FieldTypeString, "Label").
+		AddOutput("score", core.FieldTypeInt, "Score")
+
+	good := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{Content: `{"label": "news"}`, FinishReason: "stop"}, nil
+		},
+	}
+	garbled := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{Content: "I cannot comply", FinishReason: "stop"}, nil
+		},
+	}
+
+	program := NewProgram("pipeline").
+		AddStep("classify", NewPredict(classify, good), nil).
+		AddStep("score", NewPredict(score, garbled), nil)
+
+	_, err := program.Forward(context.Background(), map[string]any{"text": "Markets rallied"})
+	if err == nil {
+		t.Fatal("expected Forward to fail")
+	}
+
+	traces := readTraces(t, dir)
+	if len(traces) != 1 {
+		t.Fatalf("expected one trace from the outermost module, got %d", len(traces))
+	}
+	trace := traces[0]
+
+	if trace.Module != "Program" || strings.Join(trace.Stages, ",") != "Predict,Program" {
+		t.Errorf("module = %q, stages = %v, want Program with stages Predict,Program", trace.Module, trace.Stages)
+	}
+	if trace.RequestID == "" {
+		t.Error("expected a request ID")
+	}
+	if trace.Inputs["text"] != "Markets rallied" {
+		t.Errorf("inputs = %v", trace.Inputs)
+	}
+	if len(trace.Calls) != 2 {
+		t.Fatalf("expected both LM calls, got %d", len(trace.Calls))
+	}
+	last := trace.Calls[1]
+	if last.Module != "Predict" || last.Response != "I cannot comply" || len(last.Messages) == 0 {
+		t.Errorf("unexpected failing call: %+v", last)
+	}
+	if len(trace.ParseAttempts) < 2 {
+		t.Errorf("expected one parse attempt per fallback adapter, got %v", trace.ParseAttempts)
+	}
+	if trace.Error != err.Error() {
+		t.Errorf("trace error = %q, want %q", trace.Error, err.Error())
+	}
+}
+
+func TestErrorTrace_ToolSchemas(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	dir := t.TempDir()
+	core.Configure(core.WithTraceOnError(dir))
+
+	sig := core.NewSignature("Look up").
+		AddInput("query", core.FieldTypeString, "Query").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	search := core.NewTool("search", "Search the web", nil).
+		AddParameter("query", "string", "Search query", true)
+	react := NewReAct(sig, core.NewMockLM().FailWith(errors.New("provider down")), []core.Tool{*search})
+
+	if _, err := react.Forward(context.Background(), map[string]any{"query": "dsgo"}); err == nil {
+		t.Fatal("expected Forward to fail")
+	}
+
+	traces := readTraces(t, dir)
+	if len(traces) != 1 {
+		t.Fatalf("expected one trace, got %d", len(traces))
+	}
+	want, _ := json.Marshal(react.ToolSchemas())
+	got, _ := json.Marshal(traces[0].ToolSchemas)
+	if string(got) != string(want) {
+		t.Errorf("trace tool schemas = %s, want %s", got, want)
+	}
+}
+
+func TestErrorTrace_NotWritten(t *testing.T) {
+	sig := core.NewSignature("Classify").
+		AddInput("text", core.FieldTypeString, "Text").
+		AddOutput("label", core.FieldTypeString, "Label")
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{Content: `{"label": "news"}`}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		inputs  map[string]any
+	}{
+		{"success", true, map[string]any{"text": "ok"}},
+		{"disabled", false, map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core.ResetConfig()
+			defer core.ResetConfig()
+			dir := t.TempDir()
+			if tt.enabled {
+				core.Configure(core.WithTraceOnError(dir))
+			}
+
+			_, _ = NewPredict(sig, lm).Forward(context.Background(), tt.inputs)
+			if traces := readTraces(t, dir); len(traces) != 0 {
+				t.Errorf("expected no traces, got %d", len(traces))
+			}
+		})
+	}
+}
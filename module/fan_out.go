@@ -0,0 +1,194 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
+)
+
+// FanOut runs several different modules on the same inputs concurrently and
+// merges their outputs into one prediction (fan-out/fan-in).
+//
+// Unlike Parallel (one module across many inputs) and BestOfN (one module N
+// times), each branch of a FanOut is a different module, e.g. sentiment, topic
+// and entity extraction over one document. Output keys produced by more than
+// one module are namespaced as "<name>.<key>"; each module's prediction is also
+// available via Step(name). Modules added without a name are called "module_<i>".
+type FanOut struct {
+	modules  []core.Module
+	names    []string
+	failFast bool
+	timeout  time.Duration // Deadline for each Forward (0 = none)
+}
+
+// NewFanOut creates a FanOut over modules
+func NewFanOut(modules ...core.Module) *FanOut {
+	f := &FanOut{}
+	for _, m := range modules {
+		f.Add("", m)
+	}
+	return f
+}
+
+// Add adds a named module; an empty name defaults to "module_<i>"
+func (f *FanOut) Add(name string, module core.Module) *FanOut {
+	if name == "" {
+		name = fmt.Sprintf("module_%d", len(f.modules))
+	}
+	f.modules = append(f.modules, module)
+	f.names = append(f.names, name)
+	return f
+}
+
+// WithFailFast sets whether the first module error cancels the remaining modules.
+// When disabled (the default), every module runs to completion, failures are
+// reported in Metadata["failed_modules"] and Forward only errors if all fail.
+func (f *FanOut) WithFailFast(on bool) *FanOut {
+	f.failFast = on
+	return f
+}
+
+// Forward runs all modules concurrently and merges their outputs
+func (f *FanOut) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "FanOut", inputs, f.forward)
+}
+
+// forward is Forward without error tracing
+func (f *FanOut) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancelTimeout := withTimeout(ctx, f.timeout)
+	defer cancelTimeout()
+
+	ctx = logging.EnsureRequestID(ctx)
+	startTime := time.Now()
+	logging.LogPredictionStart(ctx, "FanOut", "Fan-out execution")
+
+	var predErr error
+	defer func() {
+		logging.LogPredictionEnd(ctx, "FanOut", time.Since(startTime), predErr)
+	}()
+
+	if len(f.modules) == 0 {
+		predErr = fmt.Errorf("fan-out has no modules")
+		return nil, predErr
+	}
+	if err := f.validateNames(); err != nil {
+		predErr = err
+		return nil, predErr
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	predictions := make([]*core.Prediction, len(f.modules))
+	errs := make([]error, len(f.modules))
+	var firstErr error
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for i, m := range f.modules {
+		wg.Add(1)
+		go func(i int, m core.Module) {
+			defer wg.Done()
+
+			pred, err := m.Forward(ctx, inputs)
+			if err != nil {
+				errs[i] = fmt.Errorf("module %q failed: %w", f.names[i], err)
+				if f.failFast {
+					once.Do(func() {
+						firstErr = errs[i]
+						cancel()
+					})
+				}
+				return
+			}
+			predictions[i] = pred
+		}(i, m)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		predErr = firstErr
+		return nil, predErr
+	}
+
+	failed := make(map[string]string)
+	for i, err := range errs {
+		if err != nil {
+			failed[f.names[i]] = err.Error()
+		}
+	}
+	if len(failed) == len(f.modules) {
+		predErr = fmt.Errorf("all fan-out modules failed: %w", errors.Join(errs...))
+		return nil, predErr
+	}
+
+	prediction := f.merge(predictions).WithInputs(inputs)
+	if len(failed) > 0 {
+		prediction.WithMetadata("failed_modules", failed)
+	}
+	return prediction, nil
+}
+
+// merge combines module predictions, namespacing output keys produced by more than one module
+func (f *FanOut) merge(predictions []*core.Prediction) *core.Prediction {
+	keyCount := make(map[string]int)
+	for _, pred := range predictions {
+		if pred == nil {
+			continue
+		}
+		for k := range pred.Outputs {
+			keyCount[k]++
+		}
+	}
+
+	outputs := make(map[string]any)
+	var usage core.Usage
+	merged := core.NewPrediction(outputs).WithModuleName("FanOut")
+
+	for i, pred := range predictions {
+		if pred == nil {
+			continue
+		}
+		for k, v := range pred.Outputs {
+			if keyCount[k] > 1 {
+				k = f.names[i] + "." + k
+			}
+			outputs[k] = v
+		}
+
+		usage.Add(pred.Usage)
+
+		merged.WithStep(f.names[i], pred).WithSubUsage(f.names[i], pred.Usage)
+	}
+
+	return merged.WithUsage(usage)
+}
+
+// validateNames rejects duplicate module names, which would make namespacing ambiguous
+func (f *FanOut) validateNames() error {
+	seen := make(map[string]bool, len(f.names))
+	for _, name := range f.names {
+		if seen[name] {
+			return fmt.Errorf("duplicate fan-out module name %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (f *FanOut) WithTimeout(timeout time.Duration) *FanOut {
+	f.timeout = timeout
+	return f
+}
+
+// GetSignature returns nil because the merged outputs span several module signatures
+func (f *FanOut) GetSignature() *core.Signature {
+	return nil
+}
@@ -0,0 +1,143 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// outputModule returns a module producing outputs with fixed usage
+func outputModule(outputs map[string]interface{}, tokens int) *MockModule {
+	return &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return core.NewPrediction(outputs).WithUsage(core.Usage{TotalTokens: tokens}), nil
+		},
+	}
+}
+
+func TestFanOut_MergesOutputs(t *testing.T) {
+	fan := NewFanOut(
+		outputModule(map[string]interface{}{"sentiment": "positive", "confidence": 0.9}, 10),
+		outputModule(map[string]interface{}{"topic": "sports", "confidence": 0.7}, 20),
+	).Add("entities", outputModule(map[string]interface{}{"entities": []string{"Messi"}}, 30))
+
+	pred, err := fan.Forward(context.Background(), map[string]interface{}{"document": "text"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"sentiment":           "positive",
+		"topic":               "sports",
+		"module_0.confidence": 0.9,
+		"module_1.confidence": 0.7,
+	}
+	for k, v := range want {
+		if pred.Outputs[k] != v {
+			t.Errorf("Outputs[%q] = %v, want %v", k, pred.Outputs[k], v)
+		}
+	}
+	if _, ok := pred.Outputs["confidence"]; ok {
+		t.Error("colliding key should only appear namespaced")
+	}
+	if pred.Usage.TotalTokens != 60 {
+		t.Errorf("TotalTokens = %d, want 60", pred.Usage.TotalTokens)
+	}
+	if pred.Step("entities") == nil || pred.Step("module_1").Outputs["topic"] != "sports" {
+		t.Errorf("expected per-module predictions, got %v", pred.Steps)
+	}
+}
+
+func TestFanOut_RunsConcurrently(t *testing.T) {
+	var running, peak int32
+	slow := func() *MockModule {
+		return &MockModule{
+			ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return core.NewPrediction(map[string]interface{}{}), nil
+			},
+		}
+	}
+
+	if _, err := NewFanOut(slow(), slow(), slow()).Forward(context.Background(), nil); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if peak < 2 {
+		t.Errorf("expected modules to run concurrently, peak concurrency %d", peak)
+	}
+}
+
+func TestFanOut_Failures(t *testing.T) {
+	failing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	blocking := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				return core.NewPrediction(map[string]interface{}{"late": true}), nil
+			}
+		},
+	}
+
+	t.Run("partial results without fail fast", func(t *testing.T) {
+		pred, err := NewFanOut(outputModule(map[string]interface{}{"ok": true}, 1)).
+			Add("broken", failing).
+			Forward(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if pred.Outputs["ok"] != true {
+			t.Errorf("expected successful outputs, got %v", pred.Outputs)
+		}
+		failed, _ := pred.Metadata["failed_modules"].(map[string]string)
+		if !strings.Contains(failed["broken"], "boom") {
+			t.Errorf("expected failure to be reported, got %v", pred.Metadata)
+		}
+	})
+
+	t.Run("fail fast cancels the rest", func(t *testing.T) {
+		start := time.Now()
+		_, err := NewFanOut(blocking).Add("broken", failing).WithFailFast(true).Forward(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), `module "broken" failed: boom`) {
+			t.Errorf("expected broken module error, got %v", err)
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Error("expected remaining modules to be cancelled")
+		}
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		_, err := NewFanOut(failing, failing).Forward(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "all fan-out modules failed") {
+			t.Errorf("expected all-failed error, got %v", err)
+		}
+	})
+
+	t.Run("configuration errors", func(t *testing.T) {
+		if _, err := NewFanOut().Forward(context.Background(), nil); err == nil {
+			t.Error("expected error for empty fan-out")
+		}
+		_, err := NewFanOut().Add("a", failing).Add("a", failing).Forward(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), `duplicate fan-out module name "a"`) {
+			t.Errorf("expected duplicate name error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package module
+
+import "github.com/assagman/dsgo/core"
+
+// historyForker is implemented by modules that can run on a private copy of their History
+type historyForker interface {
+	forkHistory() (core.Module, *historyFork)
+}
+
+// historyFork is a private copy of a module's History for one concurrent run
+// Runs on separate forks never see each other's turns; commit records one run's
+// turns in the original history.
+type historyFork struct {
+	original *core.History
+	clone    *core.History
+	base     int // Messages in clone before the run
+}
+
+// newHistoryFork copies original into an unlimited history so the new turns can be told apart
+func newHistoryFork(original *core.History) *historyFork {
+	clone := core.NewHistory()
+	for _, msg := range original.Get() {
+		clone.Add(msg)
+	}
+	return &historyFork{original: original, clone: clone, base: clone.Len()}
+}
+
+// commit appends the messages added during the run to the original history
+func (f *historyFork) commit() {
+	if f == nil {
+		return
+	}
+	messages := f.clone.Get()
+	for _, msg := range messages[f.base:] {
+		f.original.Add(msg)
+	}
+}
+
+// forkHistory returns m running on a fork of its History, or m itself (and a nil
+// fork) when it keeps no History
+func forkHistory(m core.Module) (core.Module, *historyFork) {
+	if forker, ok := m.(historyForker); ok {
+		return forker.forkHistory()
+	}
+	return m, nil
+}
+
+// forkHistory returns a copy of p that records into a fork of its History
+func (p *Predict) forkHistory() (core.Module, *historyFork) {
+	if p.History == nil {
+		return p, nil
+	}
+	fork := newHistoryFork(p.History)
+	clone := *p
+	clone.History = fork.clone
+	return &clone, fork
+}
+
+// forkHistory returns a copy of cot that records into a fork of its History
+func (cot *ChainOfThought) forkHistory() (core.Module, *historyFork) {
+	if cot.History == nil {
+		return cot, nil
+	}
+	fork := newHistoryFork(cot.History)
+	clone := *cot
+	clone.History = fork.clone
+	return &clone, fork
+}
@@ -0,0 +1,123 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// historyPredict returns a Predict with a two-message History whose LM numbers
+// its answers and fails the test if a call sees another call's turn
+func historyPredict(t *testing.T) (*Predict, *core.History) {
+	t.Helper()
+	sig := core.NewSignature("Chat").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	var calls atomic.Int32
+	lm := &MockLM{
+		SupportsJSONVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			assistantTurns := 0
+			for _, msg := range messages {
+				if msg.Role == "assistant" {
+					assistantTurns++
+				}
+			}
+			if assistantTurns != 1 {
+				t.Errorf("LM saw %d assistant turns, want only the original 1", assistantTurns)
+			}
+			n := calls.Add(1)
+			return &core.GenerateResult{Content: fmt.Sprintf(`{"answer": "answer %d"}`, n)}, nil
+		},
+	}
+
+	history := core.NewHistory()
+	history.AddUserMessage("hello")
+	history.AddAssistantMessage("hi, how can I help?")
+	return NewPredict(sig, lm).WithHistory(history), history
+}
+
+func TestBestOfN_Parallel_HistoryIsolation(t *testing.T) {
+	predict, history := historyPredict(t)
+
+	scorer := func(inputs map[string]any, prediction *core.Prediction) (float64, error) {
+		answer, _ := prediction.GetString("answer")
+		var n float64
+		_, err := fmt.Sscanf(answer, "answer %g", &n)
+		return n, err
+	}
+
+	bon := NewBestOfN(predict, 8).WithScorer(scorer).WithParallel(true).WithMaxConcurrency(0)
+	result, err := bon.Forward(context.Background(), map[string]any{"question": "what's new?"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	// Only the winning candidate's turn is recorded
+	if history.Len() != 4 {
+		t.Fatalf("history has %d messages, want 4", history.Len())
+	}
+	last := history.GetLast(1)[0]
+	if last.Role != "assistant" || last.Content == "" {
+		t.Errorf("last message = %+v, want the winning assistant turn", last)
+	}
+	if answer, _ := result.GetString("answer"); answer != "answer 8" {
+		t.Errorf("best answer = %q, want %q", answer, "answer 8")
+	}
+}
+
+func TestParallel_SharedModuleHistoryIsolation(t *testing.T) {
+	predict, history := historyPredict(t)
+
+	parallel := NewParallel(predict).WithMaxWorkers(4)
+	_, err := parallel.Forward(context.Background(), map[string]any{
+		"_batch": []map[string]any{
+			{"question": "a"}, {"question": "b"}, {"question": "c"}, {"question": "d"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if history.Len() != 2 {
+		t.Errorf("history has %d messages, want the original 2", history.Len())
+	}
+}
+
+func TestForkHistory(t *testing.T) {
+	t.Run("module without history is returned as is", func(t *testing.T) {
+		predict := NewPredict(core.NewSignature("Test"), &MockLM{})
+		mod, fork := forkHistory(predict)
+		if mod != predict || fork != nil {
+			t.Error("expected the original module and no fork")
+		}
+		fork.commit() // nil fork is a no-op
+	})
+
+	t.Run("commit appends only new messages within the original limit", func(t *testing.T) {
+		original := core.NewHistoryWithLimit(3)
+		original.AddUserMessage("one")
+		original.AddAssistantMessage("two")
+
+		cot := NewChainOfThought(core.NewSignature("Test"), &MockLM{}).WithHistory(original)
+		mod, fork := forkHistory(cot)
+		if mod == core.Module(cot) {
+			t.Fatal("expected a copy of the module")
+		}
+		mod.(*ChainOfThought).History.AddUserMessage("three")
+		mod.(*ChainOfThought).History.AddAssistantMessage("four")
+		if original.Len() != 2 {
+			t.Fatalf("fork leaked into original before commit: %d messages", original.Len())
+		}
+
+		fork.commit()
+		got := original.Get()
+		if len(got) != 3 || got[0].Content != "two" || got[2].Content != "four" {
+			t.Errorf("original after commit = %+v", got)
+		}
+	})
+}
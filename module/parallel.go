@@ -28,15 +28,11 @@ type ParallelMetrics struct {
 
 // Parallel executes a module across multiple inputs concurrently.
 //
-// IMPORTANT: When using a single shared module instance with WithMaxWorkers > 1,
-// ensure the module is stateless. Modules that maintain internal state
-// (e.g., Predict with History) MUST use either NewParallelWithFactory or
-// NewParallelWithInstances to provide isolated instances per task/worker.
-//
-// Safe parallel usage patterns:
-//   - Use stateless modules (no internal state mutation)
-//   - Create N independent instances via factory function
-//   - Provide pre-created instances array
+// A Predict or ChainOfThought with History shared via NewParallel runs each task
+// on its own clone of the history, so tasks never see each other's turns and the
+// original History is left unchanged. Other stateful modules should come from
+// NewParallelWithFactory or NewParallelWithInstances so each task gets an
+// isolated instance.
 //
 // Input modes:
 //   - Batch: inputs["_batch"] = []map[string]any
@@ -56,11 +52,11 @@ type Parallel struct {
 	onlySuccessful bool
 	batchKey       string
 	repeat         int
+	timeout        time.Duration // Deadline for each Forward (0 = none)
 }
 
 // NewParallel creates a Parallel module with a shared module instance.
-// WARNING: Only use with stateless modules. For modules with state (e.g., History),
-// use NewParallelWithFactory or NewParallelWithInstances instead.
+// The module must be safe to share; see the Parallel type documentation.
 func NewParallel(module core.Module) *Parallel {
 	return &Parallel{
 		module:         module,
@@ -157,6 +153,13 @@ func (p *Parallel) WithRepeat(n int) *Parallel {
 	return p
 }
 
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (p *Parallel) WithTimeout(timeout time.Duration) *Parallel {
+	p.timeout = timeout
+	return p
+}
+
 // GetSignature returns the wrapped module's signature
 func (p *Parallel) GetSignature() *core.Signature {
 	if p.module != nil {
@@ -175,6 +178,14 @@ func (p *Parallel) GetSignature() *core.Signature {
 
 // Forward executes the module in parallel across expanded inputs
 func (p *Parallel) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Parallel", inputs, p.forward)
+}
+
+// forward is Forward without error tracing
+func (p *Parallel) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancelTimeout := withTimeout(ctx, p.timeout)
+	defer cancelTimeout()
+
 	ctx = logging.EnsureRequestID(ctx)
 	startTime := time.Now()
 	logging.LogPredictionStart(ctx, "Parallel", "Parallel execution")
@@ -226,7 +237,9 @@ func (p *Parallel) Forward(ctx context.Context, inputs map[string]any) (*core.Pr
 		if len(p.instances) > 0 {
 			return p.instances[i%len(p.instances)]
 		}
-		return p.module
+		// The shared module runs each task on its own History clone
+		mod, _ := forkHistory(p.module)
+		return mod
 	}
 
 	// Start workers
@@ -311,13 +324,14 @@ func (p *Parallel) Forward(ctx context.Context, inputs map[string]any) (*core.Pr
 		return nil, predErr
 	}
 
-	// Aggregate usage
+	// Aggregate usage, with a per-task breakdown
 	totalUsage := core.Usage{}
-	for _, s := range successes {
-		totalUsage.TotalTokens += s.Usage.TotalTokens
-		totalUsage.PromptTokens += s.Usage.PromptTokens
-		totalUsage.CompletionTokens += s.Usage.CompletionTokens
-		totalUsage.Cost += s.Usage.Cost
+	subUsage := make(map[string]core.Usage, len(successes))
+	for i, s := range perIdx {
+		if s != nil {
+			totalUsage.Add(s.Usage)
+			subUsage[fmt.Sprintf("task_%d", i)] = s.Usage
+		}
 	}
 
 	// Calculate metrics
@@ -347,6 +361,7 @@ func (p *Parallel) Forward(ctx context.Context, inputs map[string]any) (*core.Pr
 		WithUsage(totalUsage).
 		WithModuleName("Parallel").
 		WithInputs(inputs)
+	prediction.SubUsage = subUsage
 
 	// Add completions if requested
 	if p.returnAll {
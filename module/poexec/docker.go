@@ -0,0 +1,151 @@
+// Package poexec provides sandboxed code executors for module.ProgramOfThought.
+package poexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/assagman/dsgo/internal/ids"
+	"github.com/assagman/dsgo/module"
+)
+
+// dockerRunFailed is the exit code docker run uses when the container could not be started
+const dockerRunFailed = 125
+
+// teardownTimeout bounds the docker rm -f that removes a container after a run
+const teardownTimeout = 10 * time.Second
+
+// Limits caps the resources of a sandbox container. Zero values leave the Docker default.
+type Limits struct {
+	Memory    string  // Memory limit, e.g. "256m" (also caps swap)
+	CPUs      float64 // Number of CPUs, e.g. 0.5
+	PIDs      int     // Maximum number of processes
+	TmpfsSize string  // Size of the writable /tmp, e.g. "16m"
+}
+
+// DefaultLimits returns the limits NewDockerExecutor starts with
+func DefaultLimits() Limits {
+	return Limits{Memory: "256m", CPUs: 1, PIDs: 64, TmpfsSize: "16m"}
+}
+
+// DockerExecutor runs generated code in a locked-down, throwaway container: no
+// network, a read-only root filesystem with a small tmpfs at /tmp, all capabilities
+// dropped, no privilege escalation, a non-root user and resource limits. The
+// container is removed after every run, including when ctx times out.
+type DockerExecutor struct {
+	Image   string   // Image to run, e.g. "python:3.12-slim"
+	Command []string // Command the code is appended to (default python3 -c)
+	User    string   // User to run as (default "65534:65534", nobody)
+	Limits  Limits
+	Docker  string // docker CLI to use (empty = "docker" from PATH)
+}
+
+// NewDockerExecutor creates a sandboxed executor for image. command is what the code
+// is appended to, e.g. "node", "-e"; it defaults to python3 -c.
+func NewDockerExecutor(image string, command ...string) *DockerExecutor {
+	if len(command) == 0 {
+		command = []string{"python3", "-c"}
+	}
+	return &DockerExecutor{
+		Image:   image,
+		Command: command,
+		User:    "65534:65534",
+		Limits:  DefaultLimits(),
+	}
+}
+
+// WithLimits sets the container resource limits
+func (e *DockerExecutor) WithLimits(limits Limits) *DockerExecutor {
+	e.Limits = limits
+	return e
+}
+
+// WithUser sets the user the code runs as
+func (e *DockerExecutor) WithUser(user string) *DockerExecutor {
+	e.User = user
+	return e
+}
+
+// Execute runs code in a new container and returns its output
+func (e *DockerExecutor) Execute(ctx context.Context, code string) (*module.ExecutionResult, error) {
+	if e.Image == "" {
+		return nil, errors.New("docker executor: image is required")
+	}
+
+	name := "dsgo-pot-" + ids.NewShortID()
+	// Killing the docker client on timeout leaves the container running, so remove it
+	// explicitly: on cancellation and again once the run is over
+	defer e.remove(name)
+
+	cmd := exec.CommandContext(ctx, e.docker(), e.runArgs(name, code)...)
+	cmd.Cancel = func() error {
+		e.remove(name)
+		return cmd.Process.Kill()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	result := &module.ExecutionResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		if exitErr.ExitCode() == dockerRunFailed && ctx.Err() == nil {
+			return result, fmt.Errorf("docker run failed: %s", strings.TrimSpace(result.Stderr))
+		}
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	return result, err
+}
+
+// runArgs builds the docker run arguments for a container called name
+func (e *DockerExecutor) runArgs(name, code string) []string {
+	args := []string{
+		"run", "--rm", "--name", name,
+		"--network", "none",
+		"--read-only",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+	}
+	if e.User != "" {
+		args = append(args, "--user", e.User)
+	}
+	if e.Limits.Memory != "" {
+		args = append(args, "--memory", e.Limits.Memory, "--memory-swap", e.Limits.Memory)
+	}
+	if e.Limits.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(e.Limits.CPUs, 'f', -1, 64))
+	}
+	if e.Limits.PIDs > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(e.Limits.PIDs))
+	}
+	tmpfs := "/tmp:rw,noexec,nosuid"
+	if e.Limits.TmpfsSize != "" {
+		tmpfs += ",size=" + e.Limits.TmpfsSize
+	}
+	args = append(args, "--tmpfs", tmpfs, e.Image)
+	args = append(args, e.Command...)
+	return append(args, code)
+}
+
+// remove force-removes container name, ignoring errors (it is usually gone already)
+func (e *DockerExecutor) remove(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), teardownTimeout)
+	defer cancel()
+	_ = exec.CommandContext(ctx, e.docker(), "rm", "-f", name).Run()
+}
+
+func (e *DockerExecutor) docker() string {
+	if e.Docker == "" {
+		return "docker"
+	}
+	return e.Docker
+}
@@ -0,0 +1,127 @@
+package poexec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/assagman/dsgo/module"
+)
+
+var _ module.CodeExecutor = (*DockerExecutor)(nil)
+
+// fakeDocker writes a docker stand-in that logs each invocation to a file and
+// runs body for "docker run"
+func fakeDocker(t *testing.T, body string) (docker, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	log = filepath.Join(dir, "calls.log")
+	docker = filepath.Join(dir, "docker")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\nif [ \"$1\" = run ]; then\n" + body + "\nfi\n"
+	if err := os.WriteFile(docker, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return docker, log
+}
+
+func readCalls(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestDockerExecutor_RunArgs(t *testing.T) {
+	e := NewDockerExecutor("python:3.12-slim").WithLimits(Limits{Memory: "128m", CPUs: 0.5, PIDs: 32, TmpfsSize: "8m"})
+	got := strings.Join(e.runArgs("box", "print(1)"), " ")
+
+	for _, want := range []string{
+		"run --rm --name box",
+		"--network none",
+		"--read-only",
+		"--cap-drop ALL",
+		"--security-opt no-new-privileges",
+		"--user 65534:65534",
+		"--memory 128m --memory-swap 128m",
+		"--cpus 0.5",
+		"--pids-limit 32",
+		"--tmpfs /tmp:rw,noexec,nosuid,size=8m",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args missing %q: %s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "python:3.12-slim python3 -c print(1)") {
+		t.Errorf("args should end with image, command and code: %s", got)
+	}
+
+	bare := (&DockerExecutor{Image: "img", Command: []string{"node", "-e"}}).runArgs("box", "x")
+	if joined := strings.Join(bare, " "); strings.Contains(joined, "--memory") || strings.Contains(joined, "--user") {
+		t.Errorf("zero limits and user should be omitted: %s", joined)
+	}
+}
+
+func TestDockerExecutor_Execute(t *testing.T) {
+	docker, log := fakeDocker(t, `echo out; echo err >&2; exit 3`)
+	e := NewDockerExecutor("img")
+	e.Docker = docker
+
+	result, err := e.Execute(context.Background(), "print(1)")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Stdout != "out\n" || result.Stderr != "err\n" || result.ExitCode != 3 {
+		t.Errorf("result = %+v", result)
+	}
+
+	calls := readCalls(t, log)
+	if len(calls) != 2 || !strings.HasPrefix(calls[1], "rm -f dsgo-pot-") {
+		t.Errorf("container should be removed after the run, calls = %q", calls)
+	}
+}
+
+func TestDockerExecutor_Execute_RunFailure(t *testing.T) {
+	docker, _ := fakeDocker(t, `echo "Unable to find image" >&2; exit 125`)
+	e := NewDockerExecutor("missing")
+	e.Docker = docker
+
+	if _, err := e.Execute(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "Unable to find image") {
+		t.Errorf("Execute() error = %v, want docker run failure", err)
+	}
+	if _, err := NewDockerExecutor("").Execute(context.Background(), "x"); err == nil {
+		t.Error("Execute() without image should fail")
+	}
+}
+
+func TestDockerExecutor_Execute_TimeoutRemovesContainer(t *testing.T) {
+	docker, log := fakeDocker(t, `exec sleep 10`)
+	e := NewDockerExecutor("img")
+	e.Docker = docker
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := e.Execute(ctx, "while True: pass"); err == nil {
+		t.Error("Execute() should fail on timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() took %v after timeout", elapsed)
+	}
+
+	calls := readCalls(t, log)
+	name := strings.Fields(calls[0])[3]
+	removed := 0
+	for _, call := range calls[1:] {
+		if call == "rm -f "+name {
+			removed++
+		}
+	}
+	if removed == 0 {
+		t.Errorf("container %s not removed, calls = %q", name, calls)
+	}
+}
@@ -12,6 +12,12 @@ import (
 )
 
 // Predict is the basic prediction module
+//
+// Once configured, a Predict is safe for concurrent use: Forward and Stream copy
+// Options per call and never modify the module, so one instance can serve many
+// requests. Configure it (With* methods, fields) before sharing it. A shared History
+// is guarded, but concurrent calls interleave their turns in it; give each
+// conversation its own History (see NewParallelWithFactory).
 type Predict struct {
 	Signature *core.Signature
 	LM        core.LM
@@ -19,6 +25,22 @@ type Predict struct {
 	Adapter   core.Adapter
 	History   *core.History  // Optional conversation history
 	Demos     []core.Example // Optional few-shot examples
+	// DemoSelector picks demos per input at Forward time, replacing Demos when set
+	DemoSelector core.DemoSelector
+	MaxDemos     int           // Cap on demos used per Forward (0 = all)
+	DemoMaxChars int           // Truncate string demo values to this many characters (0 = no limit)
+	Timeout      time.Duration // Deadline for each Forward and Stream (0 = none)
+	// SkipInputValidation sends inputs to the LM without checking them against the signature
+	SkipInputValidation bool
+	// FlushInterval and MinChunkBytes coalesce Stream chunks (0 = forward every provider chunk)
+	FlushInterval time.Duration
+	MinChunkBytes int
+	// MaxTokensPerField budgets completion tokens per output field; with Options.MaxTokens
+	// unset (0) or budgets set, MaxTokens is estimated from the signature
+	MaxTokensPerField map[string]int
+	// PartialStreamOnError makes Stream deliver the streamed text on a final parse failure
+	// (see WithPartialStreamOnError)
+	PartialStreamOnError bool
 }
 
 // NewPredict creates a new Predict module
@@ -27,7 +49,7 @@ func NewPredict(signature *core.Signature, lm core.LM) *Predict {
 		Signature: signature,
 		LM:        lm,
 		Options:   core.DefaultGenerateOptions(),
-		Adapter:   core.NewFallbackAdapter(), // Use fallback adapter for robustness
+		Adapter:   core.DefaultAdapterFor(lm), // JSON-first for structured-output models, chat-first otherwise
 	}
 }
 
@@ -37,6 +59,13 @@ func (p *Predict) WithOptions(options *core.GenerateOptions) *Predict {
 	return p
 }
 
+// WithMaxTokensPerField sizes MaxTokens from per-output-field token budgets, replacing
+// any fixed Options.MaxTokens. Fields without a budget get core.FieldTokenBudgets for their type.
+func (p *Predict) WithMaxTokensPerField(budgets map[string]int) *Predict {
+	p.MaxTokensPerField = budgets
+	return p
+}
+
 // WithAdapter sets a custom adapter
 func (p *Predict) WithAdapter(adapter core.Adapter) *Predict {
 	p.Adapter = adapter
@@ -55,6 +84,71 @@ func (p *Predict) WithDemos(demos []core.Example) *Predict {
 	return p
 }
 
+// WithDynamicDemos selects the k pool examples most similar to each input
+// (by embedding cosine similarity) instead of using a fixed demo set.
+// Pool embeddings are computed on the first call and cached.
+func (p *Predict) WithDynamicDemos(pool []core.Example, embedder core.Embedder, k int) *Predict {
+	p.DemoSelector = core.NewKNNDemoSelector(pool, embedder, k)
+	return p
+}
+
+// WithMaxDemos uses at most n demos per call, taken in order from Demos or the DemoSelector
+func (p *Predict) WithMaxDemos(n int) *Predict {
+	p.MaxDemos = n
+	return p
+}
+
+// WithDemoTruncation cuts string demo values to maxChars characters to keep prompts bounded
+func (p *Predict) WithDemoTruncation(maxChars int) *Predict {
+	p.DemoMaxChars = maxChars
+	return p
+}
+
+// WithStrictInputs controls whether inputs are checked against the signature before
+// the LM is called (the default). Pass false to send inputs through unchecked.
+func (p *Predict) WithStrictInputs(strict bool) *Predict {
+	p.SkipInputValidation = !strict
+	return p
+}
+
+// validateInputs checks inputs against the signature unless strict inputs are disabled
+func (p *Predict) validateInputs(inputs map[string]any) error {
+	if p.SkipInputValidation {
+		return nil
+	}
+	return p.Signature.ValidateInputs(inputs)
+}
+
+// WithTimeout sets a deadline for each Forward and Stream call
+// It overrides the global default timeout (core.WithTimeout), even when it is longer;
+// an earlier deadline on the caller's context still ends the call first.
+func (p *Predict) WithTimeout(timeout time.Duration) *Predict {
+	p.Timeout = timeout
+	return p
+}
+
+// WithFlushInterval makes Stream merge chunks and deliver them at most every interval,
+// for consumers that re-render on each chunk. Finish chunks are never delayed.
+func (p *Predict) WithFlushInterval(interval time.Duration) *Predict {
+	p.FlushInterval = interval
+	return p
+}
+
+// WithMinChunkBytes makes Stream merge chunks until they hold at least n bytes of text
+// (see core.CoalesceChunks). Finish chunks are never delayed.
+func (p *Predict) WithMinChunkBytes(n int) *Predict {
+	p.MinChunkBytes = n
+	return p
+}
+
+// WithPartialStreamOnError makes Stream send a prediction even when the streamed output
+// cannot be parsed, alongside the error: it has no outputs, ParseSuccess false, and the
+// streamed text in Metadata["raw_content"] (see partialStreamPrediction)
+func (p *Predict) WithPartialStreamOnError(enabled bool) *Predict {
+	p.PartialStreamOnError = enabled
+	return p
+}
+
 // GetSignature returns the module's signature
 func (p *Predict) GetSignature() *core.Signature {
 	return p.Signature
@@ -62,6 +156,14 @@ func (p *Predict) GetSignature() *core.Signature {
 
 // Forward executes the prediction
 func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Predict", inputs, p.forward)
+}
+
+// forward is Forward without error tracing
+func (p *Predict) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, p.Timeout)
+	defer cancel()
+
 	// Ensure context has a request ID
 	ctx = logging.EnsureRequestID(ctx)
 
@@ -73,51 +175,59 @@ func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 		logging.LogPredictionEnd(ctx, "Predict", time.Since(startTime), predErr)
 	}()
 
-	if err := p.Signature.ValidateInputs(inputs); err != nil {
+	if err := p.validateInputs(inputs); err != nil {
 		predErr = fmt.Errorf("input validation failed: %w", err)
 		return nil, predErr
 	}
 
-	// Use adapter to format messages with demos
-	newMessages, err := p.Adapter.Format(p.Signature, inputs, p.Demos)
+	// Format messages with demos and history, fitting the model's context window
+	messages, newMessages, report, err := p.assemblePrompt(ctx, inputs)
 	if err != nil {
-		predErr = fmt.Errorf("failed to format messages: %w", err)
+		predErr = err
 		return nil, predErr
 	}
 
-	// Build final message list
-	var messages []core.Message
-
-	// Prepend history if available
-	if p.History != nil && !p.History.IsEmpty() {
-		historyMessages := p.Adapter.FormatHistory(p.History)
-		messages = append(messages, historyMessages...)
-	}
-
-	// Add new messages
-	messages = append(messages, newMessages...)
-
 	// Copy options to avoid mutation
 	options := p.Options.Copy()
-	// Only force JSON mode for JSONAdapter (not ChatAdapter or FallbackAdapter)
-	if p.LM.SupportsJSON() {
-		if _, isJSON := p.Adapter.(*core.JSONAdapter); isJSON {
+	options.MaxTokens = p.Signature.ResolveMaxTokens(options.MaxTokens, p.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
+	adapter := core.CallAdapter(ctx, p.Adapter)
+	// Function-calling mode forces a return_result call whose arguments are the outputs
+	functionCalling := core.UseFunctionCalling(adapter, p.LM)
+	if functionCalling {
+		options.Tools = append(options.Tools, core.ReturnResultTool(p.Signature))
+		options.ToolChoice = core.ReturnResultToolName
+	} else if p.LM.SupportsJSON() {
+		// Only force JSON mode when the adapter formats for JSON (not ChatAdapter or the chat-first fallback)
+		if core.UsesJSONMode(adapter) {
 			options.ResponseFormat = "json"
-			// Auto-generate JSON schema from signature for structured outputs
-			if options.ResponseSchema == nil {
+			// Auto-generate JSON schema from signature when the model supports structured outputs
+			if options.ResponseSchema == nil && core.CapabilitiesOf(p.LM).SupportsJSONSchema {
 				options.ResponseSchema = p.Signature.SignatureToJSONSchema()
 			}
 		}
 	}
+	core.ConstrainEnums(adapter, p.LM, p.Signature, options)
+
+	if result, ok := core.DryRun(ctx, p.LM.Name(), messages, options); ok {
+		return dryRunPrediction("Predict", inputs, result), nil
+	}
 
 	result, err := p.LM.Generate(ctx, messages, options)
+	recordCall(ctx, "Predict", messages, result, err)
 	if err != nil {
 		predErr = fmt.Errorf("LM generation failed: %w", err)
 		return nil, predErr
 	}
 
+	// In function-calling mode the outputs arrive as the return_result arguments
+	returnedContent, returned := "", false
+	if functionCalling {
+		returnedContent, returned = core.ReturnResultContent(result)
+	}
+
 	// Handle finish_reason: Predict doesn't support tool execution loops
-	if result.FinishReason == "tool_calls" {
+	if result.FinishReason == "tool_calls" && !returned {
 		predErr = fmt.Errorf("model requested tool execution (finish_reason=tool_calls) but Predict module doesn't support tool loops - use React module instead")
 		return nil, predErr
 	}
@@ -129,13 +239,19 @@ func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 	}
 
 	// Check for empty content with finish_reason=stop (actual error)
-	if result.Content == "" && result.FinishReason == "stop" {
-		predErr = fmt.Errorf("model returned empty content despite finish_reason=stop (model error)")
+	if result.Content == "" && result.FinishReason == "stop" && !returned {
+		predErr = fmt.Errorf("model returned empty content despite finish_reason=stop: %w", core.ErrEmptyResponse)
 		return nil, predErr
 	}
 
+	// Reasoning models return their thinking separately or inline in <think> blocks
+	reasoning, content := core.ResultReasoning(result)
+	if returned {
+		content = returnedContent
+	}
+
 	// Use adapter to parse output
-	outputs, err := p.Adapter.Parse(p.Signature, result.Content)
+	outputs, err := adapter.Parse(p.Signature, content)
 	if err != nil {
 		predErr = fmt.Errorf("failed to parse output: %w", err)
 		return nil, predErr
@@ -158,7 +274,7 @@ func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 		// Add assistant response
 		p.History.Add(core.Message{
 			Role:    "assistant",
-			Content: result.Content,
+			Content: content,
 		})
 	}
 
@@ -167,9 +283,11 @@ func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 
 	// Build Prediction object
 	prediction := core.NewPrediction(outputs).
+		WithRationale(reasoning).
 		WithUsage(result.Usage).
 		WithModuleName("Predict").
 		WithInputs(inputs)
+	withDemoReport(prediction, report)
 
 	// Add adapter metrics if available
 	if adapterUsed != "" {
@@ -179,11 +297,116 @@ func (p *Predict) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 	return prediction, nil
 }
 
+// assemblePrompt formats inputs, demos and history into messages with the adapter for
+// ctx, applying the configured context-window truncation policy, and appends corrective
+// feedback from an enclosing Assert, if any
+func (p *Predict) assemblePrompt(ctx context.Context, inputs map[string]any) ([]core.Message, []core.Message, core.AssemblyReport, error) {
+	demos, err := p.DemosFor(ctx, inputs)
+	if err != nil {
+		return nil, nil, core.AssemblyReport{}, err
+	}
+
+	messages, newMessages, report, err := assemble(ctx, "Predict", p.LM.Name(), core.PromptAssembly{
+		Adapter:   core.CallAdapter(ctx, p.Adapter),
+		Signature: p.Signature,
+		Inputs:    inputs,
+		Demos:     demos,
+		History:   p.History,
+		MaxTokens: p.Signature.ResolveMaxTokens(p.Options.MaxTokens, p.MaxTokensPerField),
+	})
+	if err != nil {
+		return nil, nil, report, err
+	}
+	return core.ApplyFeedback(ctx, messages), newMessages, report, nil
+}
+
+// assemble assembles a module prompt for model, warning when demos had to be
+// dropped to fit the context window
+func assemble(ctx context.Context, moduleName, model string, assembly core.PromptAssembly) ([]core.Message, []core.Message, core.AssemblyReport, error) {
+	messages, newMessages, report, err := assembly.AssembleWithReport(model)
+	if err == nil && report.DroppedDemos > 0 {
+		logging.LogDemosDropped(ctx, moduleName, report.DroppedDemos, report.Demos)
+	}
+	return messages, newMessages, report, err
+}
+
+// withDemoReport records the demos rendered in the prompt in
+// Metadata["demo_count"], and any dropped ones in Metadata["dropped_demos"]
+func withDemoReport(prediction *core.Prediction, report core.AssemblyReport) {
+	prediction.WithMetadata("demo_count", report.Demos)
+	if report.DroppedDemos > 0 {
+		prediction.WithMetadata("dropped_demos", report.DroppedDemos)
+	}
+}
+
+// DemosFor returns the demos Forward would render for inputs, after selection,
+// MaxDemos and DemoMaxChars (the context-window policy may still drop some)
+func (p *Predict) DemosFor(ctx context.Context, inputs map[string]any) ([]core.Example, error) {
+	demos := p.Demos
+	if p.DemoSelector != nil {
+		selected, err := p.DemoSelector.SelectDemos(ctx, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("demo selection failed: %w", err)
+		}
+		demos = selected
+	}
+	return core.LimitDemos(demos, p.MaxDemos, p.DemoMaxChars), nil
+}
+
+// BuildPrompt returns the exact messages Forward would send for inputs,
+// without calling the LM
+func (p *Predict) BuildPrompt(inputs map[string]any) ([]core.Message, error) {
+	return p.BuildPromptContext(context.Background(), inputs)
+}
+
+// BuildPromptContext is BuildPrompt for a Forward call made with ctx, so it includes a
+// per-call adapter (core.WithCallAdapter) and feedback from an enclosing Assert
+func (p *Predict) BuildPromptContext(ctx context.Context, inputs map[string]any) ([]core.Message, error) {
+	if err := p.validateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
+	}
+	messages, _, _, err := p.assemblePrompt(ctx, inputs)
+	return messages, err
+}
+
+// EstimateUsage estimates the prompt tokens Forward would consume for inputs,
+// without calling the LM
+func (p *Predict) EstimateUsage(inputs map[string]any) (core.Usage, error) {
+	messages, err := p.BuildPrompt(inputs)
+	if err != nil {
+		return core.Usage{}, err
+	}
+	return estimatePromptUsage(p.LM.Name(), messages, p.Options.Tools), nil
+}
+
+// estimatePromptUsage approximates usage for a prompt that has not been sent
+func estimatePromptUsage(model string, messages []core.Message, tools []core.Tool) core.Usage {
+	promptTokens := core.CountMessageTokens(model, messages) + core.EstimateToolTokens(tools)
+	return core.Usage{
+		PromptTokens: promptTokens,
+		TotalTokens:  promptTokens,
+	}
+}
+
 // StreamResult represents the result of a streaming prediction
 type StreamResult struct {
 	Chunks     <-chan core.Chunk       // Channel for receiving streaming chunks
 	Prediction <-chan *core.Prediction // Channel for receiving final prediction (sent after stream completes)
 	Errors     <-chan error            // Channel for receiving errors
+
+	progress *streamProgress // Token progress for OnProgress, nil without a live stream
+}
+
+// partialStreamPrediction salvages a stream whose final output failed to parse: no
+// outputs, the streamed text without field markers in Metadata["raw_content"] and the
+// error message in Metadata["parse_error"]. The error itself goes to Errors.
+func partialStreamPrediction(moduleName string, inputs map[string]any, content string, usage core.Usage, err error) *core.Prediction {
+	return core.NewPrediction(map[string]any{}).
+		WithUsage(usage).
+		WithModuleName(moduleName).
+		WithInputs(inputs).
+		WithMetadata("raw_content", core.StripMarkers(content)).
+		WithMetadata("parse_error", err.Error())
 }
 
 // Stream executes the prediction with streaming output
@@ -191,6 +414,8 @@ type StreamResult struct {
 // The chunks channel emits incremental content in real-time
 // The prediction channel emits the final parsed prediction after the stream completes
 // The errors channel emits any errors that occur during streaming or parsing
+// When the final output cannot be parsed, the error wraps a *core.ParseError holding the
+// raw output, and Prediction is closed without a value unless WithPartialStreamOnError is set.
 func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamResult, error) {
 	// Ensure context has a request ID
 	ctx = logging.EnsureRequestID(ctx)
@@ -198,52 +423,57 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 	startTime := time.Now()
 	logging.LogPredictionStart(ctx, "Predict.Stream", p.Signature.Description)
 
-	if err := p.Signature.ValidateInputs(inputs); err != nil {
+	if err := p.validateInputs(inputs); err != nil {
 		logging.LogPredictionEnd(ctx, "Predict.Stream", time.Since(startTime), err)
 		return nil, fmt.Errorf("input validation failed: %w", err)
 	}
 
-	// Use adapter to format messages with demos
-	newMessages, err := p.Adapter.Format(p.Signature, inputs, p.Demos)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format messages: %w", err)
-	}
-
-	// Build final message list
-	var messages []core.Message
+	// The deadline covers the whole stream, so the goroutine below cancels it when done
+	ctx, cancel := withTimeout(ctx, p.Timeout)
 
-	// Prepend history if available
-	if p.History != nil && !p.History.IsEmpty() {
-		historyMessages := p.Adapter.FormatHistory(p.History)
-		messages = append(messages, historyMessages...)
+	// Format messages with demos and history, fitting the model's context window
+	messages, newMessages, report, err := p.assemblePrompt(ctx, inputs)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
 
-	// Add new messages
-	messages = append(messages, newMessages...)
-
 	// Copy options to avoid mutation
 	options := p.Options.Copy()
-	// Only force JSON mode for JSONAdapter (not ChatAdapter or FallbackAdapter)
+	options.MaxTokens = p.Signature.ResolveMaxTokens(options.MaxTokens, p.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
+	adapter := core.CallAdapter(ctx, p.Adapter)
+	// Only force JSON mode when the adapter formats for JSON (not ChatAdapter or the chat-first fallback)
 	if p.LM.SupportsJSON() {
-		if _, isJSON := p.Adapter.(*core.JSONAdapter); isJSON {
+		if core.UsesJSONMode(adapter) {
 			options.ResponseFormat = "json"
-			// Auto-generate JSON schema from signature for structured outputs
-			if options.ResponseSchema == nil {
+			// Auto-generate JSON schema from signature when the model supports structured outputs
+			if options.ResponseSchema == nil && core.CapabilitiesOf(p.LM).SupportsJSONSchema {
 				options.ResponseSchema = p.Signature.SignatureToJSONSchema()
 			}
 		}
 	}
+	core.ConstrainEnums(adapter, p.LM, p.Signature, options)
+
+	if result, ok := core.DryRun(ctx, p.LM.Name(), messages, options); ok {
+		cancel()
+		logging.LogPredictionEnd(ctx, "Predict.Stream", time.Since(startTime), nil)
+		return dryRunStream(dryRunPrediction("Predict", inputs, result)), nil
+	}
 
 	// Call LM Stream
 	chunkChan, errChan := p.LM.Stream(ctx, messages, options)
+	chunkChan = core.CoalesceChunks(ctx, chunkChan, p.FlushInterval, p.MinChunkBytes)
 
 	// Create result channels
+	progress := newStreamProgress(p.LM.Name(), startTime)
 	outputChunks := make(chan core.Chunk)
 	predictionChan := make(chan *core.Prediction, 1)
 	errorChan := make(chan error, 1)
 
 	// Start goroutine to handle streaming and final parsing
 	go func() {
+		defer cancel()
 		defer close(outputChunks)
 		defer close(predictionChan)
 		defer close(errorChan)
@@ -256,6 +486,7 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 		// Use StreamingBuffer for automatic recovery
 		streamBuffer := core.NewStreamingBuffer()
 		markerFilter := core.NewStreamingMarkerFilter()
+		var modelReasoning strings.Builder
 		var finalUsage core.Usage
 
 		// Forward chunks and accumulate content
@@ -268,16 +499,25 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 				cleanChunk.Content = markerFilter.ProcessChunk(chunk.Content)
 			}
 
-			// Forward clean chunk to caller
-			outputChunks <- cleanChunk
+			// Forward clean chunk to caller; a cancelled context ends the stream (and the
+			// underlying request) even if the caller has stopped reading
+			select {
+			case outputChunks <- cleanChunk:
+			case <-ctx.Done():
+				streamErr = fmt.Errorf("LM streaming failed: %w", ctx.Err())
+				errorChan <- streamErr
+				return
+			}
 
 			// Call user callback if provided (with clean chunk)
 			if options.StreamCallback != nil {
 				options.StreamCallback(cleanChunk)
 			}
+			progress.observe(chunk)
 
 			// Accumulate original content with streaming buffer (for parsing)
 			streamBuffer.Write(chunk.Content)
+			modelReasoning.WriteString(chunk.Reasoning)
 
 			// Capture final metadata
 			if chunk.Usage.TotalTokens > 0 {
@@ -290,7 +530,13 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 			remaining := markerFilter.Flush()
 			if remaining != "" {
 				flushChunk := core.Chunk{Content: remaining}
-				outputChunks <- flushChunk
+				select {
+				case outputChunks <- flushChunk:
+				case <-ctx.Done():
+					streamErr = fmt.Errorf("LM streaming failed: %w", ctx.Err())
+					errorChan <- streamErr
+					return
+				}
 				if options.StreamCallback != nil {
 					options.StreamCallback(flushChunk)
 				}
@@ -308,12 +554,25 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 		default:
 		}
 
-		// Finalize streaming buffer (applies recovery fixes)
-		content := streamBuffer.Finalize()
-		outputs, err := p.Adapter.Parse(p.Signature, content)
+		// Finalize streaming buffer (applies recovery fixes), then separate reasoning as Forward does
+		reasoning, content := core.SplitReasoning(streamBuffer.Finalize())
+		if modelReasoning.Len() > 0 {
+			reasoning = modelReasoning.String()
+		}
+
+		// fail reports a final parse failure, salvaging the streamed text if configured
+		fail := func(err error) {
+			streamErr = err
+			if p.PartialStreamOnError {
+				predictionChan <- partialStreamPrediction("Predict", inputs, content, finalUsage, err).
+					WithRationale(reasoning)
+			}
+			errorChan <- err
+		}
+
+		outputs, err := adapter.Parse(p.Signature, content)
 		if err != nil {
-			streamErr = fmt.Errorf("failed to parse output: %w", err)
-			errorChan <- streamErr
+			fail(fmt.Errorf("failed to parse output: %w", err))
 			return
 		}
 
@@ -327,8 +586,7 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 			for field, err := range diag.TypeErrors {
 				errMsgs = append(errMsgs, fmt.Sprintf("%s: %v", field, err))
 			}
-			streamErr = fmt.Errorf("output validation failed with type errors: %v", strings.Join(errMsgs, "; "))
-			errorChan <- streamErr
+			fail(&core.ParseError{Raw: content, Err: fmt.Errorf("output validation failed with type errors: %v", strings.Join(errMsgs, "; "))})
 			return
 		}
 
@@ -353,9 +611,11 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 
 		// Build Prediction object
 		prediction := core.NewPrediction(outputs).
+			WithRationale(reasoning).
 			WithUsage(finalUsage).
 			WithModuleName("Predict").
 			WithInputs(inputs)
+		withDemoReport(prediction, report)
 
 		// Add adapter metrics if available
 		if adapterUsed != "" {
@@ -375,5 +635,6 @@ func (p *Predict) Stream(ctx context.Context, inputs map[string]any) (*StreamRes
 		Chunks:     outputChunks,
 		Prediction: predictionChan,
 		Errors:     errorChan,
+		progress:   progress,
 	}, nil
 }
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
 )
 
 func TestPredict_Forward_Success(t *testing.T) {
@@ -53,6 +56,114 @@ func TestPredict_Forward_InvalidInput(t *testing.T) {
 	}
 }
 
+func TestPredict_WithStrictInputs(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	calls := 0
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			calls++
+			return &core.GenerateResult{Content: `{"answer": "42"}`}, nil
+		},
+	}
+	inputs := map[string]any{"question": 42}
+
+	p := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter())
+	_, err := p.Forward(context.Background(), inputs)
+	if err == nil || !strings.Contains(err.Error(), `input "question" expected string, got int`) {
+		t.Fatalf("Forward() error = %v, want type mismatch", err)
+	}
+	if calls != 0 {
+		t.Errorf("LM called %d times for invalid inputs, want 0", calls)
+	}
+
+	p.WithStrictInputs(false)
+	if _, err := p.Forward(context.Background(), inputs); err != nil {
+		t.Fatalf("Forward() with strict inputs disabled error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("LM called %d times, want 1", calls)
+	}
+}
+
+func TestPredict_WithCallAdapter(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	var formats []string
+	lm := &MockLM{
+		SupportsJSONVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			formats = append(formats, options.ResponseFormat)
+			return &core.GenerateResult{Content: "[[ ## answer ## ]]\n42"}, nil
+		},
+	}
+	inputs := map[string]any{"question": "What is the answer?"}
+
+	p := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter())
+	_, _ = p.Forward(context.Background(), inputs)
+
+	ctx := core.WithCallAdapter(context.Background(), core.NewChatAdapter())
+	prediction, err := p.Forward(ctx, inputs)
+	if err != nil {
+		t.Fatalf("Forward() with per-call chat adapter error = %v", err)
+	}
+	if prediction.Outputs["answer"] != "42" {
+		t.Errorf("answer = %v, want 42", prediction.Outputs["answer"])
+	}
+	if formats[0] != "json" || formats[1] == "json" {
+		t.Errorf("response formats = %q, want JSON only for the module's own adapter", formats)
+	}
+	if _, isJSON := p.Adapter.(*core.JSONAdapter); !isJSON {
+		t.Error("per-call adapter must not replace the module's adapter")
+	}
+}
+
+func TestPredict_MaxTokensFromSignature(t *testing.T) {
+	sig := core.NewSignature("Write").
+		AddInput("topic", core.FieldTypeString, "").
+		AddOutput("story", core.FieldTypeString, "")
+
+	var sent int
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			sent = options.MaxTokens
+			return &core.GenerateResult{Content: `{"story": "Once upon a time"}`}, nil
+		},
+	}
+	inputs := map[string]any{"topic": "dragons"}
+
+	p := NewPredict(sig, lm)
+	p.Options.MaxTokens = 0
+	if _, err := p.Forward(context.Background(), inputs); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if want := sig.EstimateMaxTokens(nil); sent != want {
+		t.Errorf("MaxTokens = %d, want the signature estimate %d", sent, want)
+	}
+
+	p.WithMaxTokensPerField(map[string]int{"story": 6000})
+	if _, err := p.Forward(context.Background(), inputs); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if want := sig.EstimateMaxTokens(map[string]int{"story": 6000}); sent != want {
+		t.Errorf("MaxTokens = %d, want the per-field estimate %d", sent, want)
+	}
+
+	// A fixed MaxTokens is kept when no budgets are set
+	fixed := NewPredict(sig, lm)
+	fixed.Options.MaxTokens = 300
+	if _, err := fixed.Forward(context.Background(), inputs); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if sent != 300 {
+		t.Errorf("MaxTokens = %d, want the configured 300", sent)
+	}
+}
+
 func TestPredict_Forward_LMError(t *testing.T) {
 	sig := core.NewSignature("Test").
 		AddInput("question", core.FieldTypeString, "Question")
@@ -94,7 +205,45 @@ func TestPredict_Forward_ParseError(t *testing.T) {
 	})
 
 	if err == nil {
-		t.Error("Forward() should error on parse failure when multiple fields required")
+		t.Fatal("Forward() should error on parse failure when multiple fields required")
+	}
+
+	var parseErr *core.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *core.ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Raw != "invalid json without structure" {
+		t.Errorf("ParseError.Raw = %q", parseErr.Raw)
+	}
+}
+
+func TestPredict_Forward_RepairsTruncatedJSON(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer").
+		AddOutput("confidence", core.FieldTypeString, "Confidence level")
+
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{
+				Content: "```json\n{\"answer\": \"42\", \"confidence\": \"high\",",
+			}, nil
+		},
+	}
+
+	p := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter())
+	pred, err := p.Forward(context.Background(), map[string]interface{}{
+		"question": "test",
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if pred.Outputs["answer"] != "42" || pred.Outputs["confidence"] != "high" {
+		t.Errorf("unexpected outputs: %v", pred.Outputs)
+	}
+	if pred.Metadata["json_repaired"] != true {
+		t.Errorf("expected json_repaired metadata, got %v", pred.Metadata)
 	}
 }
 
@@ -275,6 +424,144 @@ func TestPredict_JSONSchemaAutoGeneration(t *testing.T) {
 	}
 }
 
+// capableMockLM is a MockLM that reports explicit capabilities
+type capableMockLM struct {
+	*MockLM
+	caps core.Capabilities
+}
+
+func (m *capableMockLM) Capabilities() core.Capabilities {
+	return m.caps
+}
+
+func TestPredict_JSONSchemaRequiresCapability(t *testing.T) {
+	sig := core.NewSignature("Classify").
+		AddInput("text", core.FieldTypeString, "Text").
+		AddOutput("label", core.FieldTypeString, "Label")
+
+	var format string
+	var schema map[string]any
+	lm := &capableMockLM{
+		MockLM: &MockLM{
+			SupportsJSONVal: true,
+			GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+				format, schema = options.ResponseFormat, options.ResponseSchema
+				return &core.GenerateResult{Content: `{"label": "ok"}`}, nil
+			},
+		},
+		// JSON mode without structured outputs, e.g. gpt-3.5-turbo
+		caps: core.Capabilities{SupportsJSON: true},
+	}
+
+	if _, err := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter()).Forward(context.Background(), map[string]any{"text": "x"}); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if format != "json" {
+		t.Errorf("ResponseFormat = %q, want json", format)
+	}
+	if schema != nil {
+		t.Errorf("expected no ResponseSchema for a model without JSON schema support, got %v", schema)
+	}
+}
+
+func TestPredict_FunctionCalling(t *testing.T) {
+	sig := core.NewSignature("Answer the question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer").
+		AddOutput("confidence", core.FieldTypeFloat, "Confidence")
+
+	t.Run("outputs come from the return_result call", func(t *testing.T) {
+		lm := &MockLM{
+			SupportsJSONVal:  true,
+			SupportsToolsVal: true,
+			GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+				if options.ToolChoice != core.ReturnResultToolName {
+					t.Errorf("ToolChoice = %q, want %q", options.ToolChoice, core.ReturnResultToolName)
+				}
+				if len(options.Tools) != 1 || options.Tools[0].Name != core.ReturnResultToolName {
+					t.Errorf("Tools = %+v, want only return_result", options.Tools)
+				}
+				if options.ResponseFormat == "json" {
+					t.Error("JSON mode should not be forced alongside function calling")
+				}
+				return &core.GenerateResult{
+					FinishReason: "tool_calls",
+					ToolCalls: []core.ToolCall{{
+						ID:        "call_1",
+						Name:      core.ReturnResultToolName,
+						Arguments: map[string]any{"answer": "42", "confidence": 0.9},
+					}},
+				}, nil
+			},
+		}
+
+		p := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter().WithFunctionCalling(true))
+		pred, err := p.Forward(context.Background(), map[string]any{"question": "What is the answer?"})
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if pred.Outputs["answer"] != "42" || pred.Outputs["confidence"] != 0.9 {
+			t.Errorf("outputs = %v", pred.Outputs)
+		}
+	})
+
+	t.Run("falls back to JSON without tool support", func(t *testing.T) {
+		lm := &MockLM{
+			SupportsJSONVal: true,
+			GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+				if len(options.Tools) != 0 || options.ResponseFormat != "json" {
+					t.Errorf("expected prompt-based JSON mode, got tools=%d format=%q", len(options.Tools), options.ResponseFormat)
+				}
+				return &core.GenerateResult{Content: `{"answer": "42", "confidence": 0.5}`, FinishReason: "stop"}, nil
+			},
+		}
+
+		p := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter().WithFunctionCalling(true))
+		pred, err := p.Forward(context.Background(), map[string]any{"question": "What is the answer?"})
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if pred.Outputs["answer"] != "42" {
+			t.Errorf("answer = %v, want 42", pred.Outputs["answer"])
+		}
+	})
+}
+
+func TestPredict_ReasoningModelRationale(t *testing.T) {
+	sig := core.NewSignature("Classify").
+		AddInput("text", core.FieldTypeString, "Text").
+		AddOutput("label", core.FieldTypeString, "Label")
+
+	tests := []struct {
+		name   string
+		result *core.GenerateResult
+	}{
+		{"inline think block", &core.GenerateResult{Content: "<think>It says {label: spam}? Looks fine.</think>\n{\"label\": \"ham\"}", FinishReason: "stop"}},
+		{"separate reasoning channel", &core.GenerateResult{Content: `{"label": "ham"}`, Reasoning: "Looks fine.", FinishReason: "stop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := &MockLM{
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					return tt.result, nil
+				},
+			}
+
+			pred, err := NewPredict(sig, lm).WithAdapter(core.NewJSONAdapter()).Forward(context.Background(), map[string]any{"text": "x"})
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if label, _ := pred.GetString("label"); label != "ham" {
+				t.Errorf("label = %q, want ham", label)
+			}
+			if !strings.Contains(pred.Rationale, "Looks fine.") {
+				t.Errorf("Rationale = %q, want the model's reasoning", pred.Rationale)
+			}
+		})
+	}
+}
+
 func TestPredict_JSONSchemaWithOptionalFields(t *testing.T) {
 	sig := core.NewSignature("Optional fields test").
 		AddInput("query", core.FieldTypeString, "Query").
@@ -350,6 +637,52 @@ func TestPredict_JSONSchemaNotGeneratedWithChatAdapter(t *testing.T) {
 	}
 }
 
+func TestPredict_DefaultAdapterFromCapabilities(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	tests := []struct {
+		name         string
+		supportsJSON bool
+		adapter      core.Adapter
+		wantJSONMode bool
+		wantAttempts int
+	}{
+		{"structured-output model starts with JSON", true, nil, true, 1},
+		{"other models start with chat markers", false, nil, false, 1},
+		{"explicit adapter wins", true, core.NewFallbackAdapter(), false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := &MockLM{
+				SupportsJSONVal: tt.supportsJSON,
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					if got := options.ResponseFormat == "json"; got != tt.wantJSONMode {
+						t.Errorf("JSON mode = %v, want %v", got, tt.wantJSONMode)
+					}
+					if tt.wantJSONMode || tt.adapter != nil {
+						return &core.GenerateResult{Content: `{"answer": "42"}`}, nil
+					}
+					return &core.GenerateResult{Content: "[[ ## answer ## ]]\n42"}, nil
+				},
+			}
+			p := NewPredict(sig, lm)
+			if tt.adapter != nil {
+				p.WithAdapter(tt.adapter)
+			}
+
+			prediction, err := p.Forward(context.Background(), map[string]any{"question": "What is the answer?"})
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if prediction.ParseAttempts != tt.wantAttempts {
+				t.Errorf("ParseAttempts = %d, want %d", prediction.ParseAttempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
 func TestPredict_CustomSchemaOverridesAutoGeneration(t *testing.T) {
 	sig := core.NewSignature("Test").
 		AddInput("text", core.FieldTypeString, "Text").
@@ -557,6 +890,43 @@ func TestPredict_Stream_Success(t *testing.T) {
 	}
 }
 
+// TestPredict_Stream_MinChunkBytes tests that Stream coalesces small chunks
+func TestPredict_Stream_MinChunkBytes(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+
+	mockLM := &mockStreamingLM{
+		chunks: []core.Chunk{
+			{Content: "answer: "},
+			{Content: "Hello "},
+			{Content: "World"},
+			{FinishReason: "stop", Usage: core.Usage{TotalTokens: 10}},
+		},
+	}
+
+	result, err := NewPredict(sig, mockLM).WithMinChunkBytes(12).Stream(context.Background(), map[string]any{
+		"question": "Say hello",
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var contents []string
+	for chunk := range result.Chunks {
+		contents = append(contents, chunk.Content)
+	}
+	want := []string{"answer: Hello ", "World", ""}
+	if strings.Join(contents, "|") != strings.Join(want, "|") {
+		t.Errorf("chunks = %q, want %q", contents, want)
+	}
+
+	prediction := <-result.Prediction
+	if answer, _ := prediction.GetString("answer"); answer != "Hello World" {
+		t.Errorf("Expected answer 'Hello World', got '%s'", answer)
+	}
+}
+
 // TestPredict_Stream_WithCallback tests streaming with callback
 func TestPredict_Stream_WithCallback(t *testing.T) {
 	sig := core.NewSignature("Test").
@@ -915,6 +1285,57 @@ func TestPredict_Stream_WithHistory(t *testing.T) {
 	}
 }
 
+func TestPredict_Stream_ReasoningModelRationale(t *testing.T) {
+	sig := core.NewSignature("Classify").
+		AddInput("text", core.FieldTypeString, "Text").
+		AddOutput("label", core.FieldTypeString, "Label")
+
+	tests := []struct {
+		name   string
+		chunks []core.Chunk
+	}{
+		{"inline think block", []core.Chunk{
+			{Content: "<think>It says {label: spam}? "},
+			{Content: "Looks fine.</think>\n{\"label\": "},
+			{Content: `"ham"}`, FinishReason: "stop"},
+		}},
+		{"separate reasoning channel", []core.Chunk{
+			{Reasoning: "Looks fine."},
+			{Content: `{"label": "ham"}`, FinishReason: "stop"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := core.NewHistory()
+			result, err := NewPredict(sig, &mockStreamingLM{chunks: tt.chunks}).
+				WithAdapter(core.NewJSONAdapter()).
+				WithHistory(history).
+				Stream(context.Background(), map[string]any{"text": "x"})
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			for range result.Chunks {
+			}
+			if err := <-result.Errors; err != nil {
+				t.Fatalf("stream error = %v", err)
+			}
+			pred := <-result.Prediction
+
+			if label, _ := pred.GetString("label"); label != "ham" {
+				t.Errorf("label = %q, want ham", label)
+			}
+			if !strings.Contains(pred.Rationale, "Looks fine.") {
+				t.Errorf("Rationale = %q, want the model's reasoning", pred.Rationale)
+			}
+			messages := history.Get()
+			if last := messages[len(messages)-1]; strings.Contains(last.Content, "think>") || strings.Contains(last.Content, "Looks fine") {
+				t.Errorf("history answer = %q, want it without the reasoning", last.Content)
+			}
+		})
+	}
+}
+
 // MockLMForFallback is a mock LM that can return different response formats
 type MockLMForFallback struct {
 	ResponseFormat string // "chat", "json", or "invalid"
@@ -1261,8 +1682,8 @@ func TestPredict_WithDemos(t *testing.T) {
 		t.Fatal("Expected messages to be captured")
 	}
 
-	// Check that prompt includes examples
-	promptContent := capturedMessages[0].Content
+	// Check that prompt includes examples (after the system instruction)
+	promptContent := capturedMessages[1].Content
 	if !strings.Contains(promptContent, "Example") {
 		t.Error("Prompt should include demo examples")
 	}
@@ -1271,7 +1692,110 @@ func TestPredict_WithDemos(t *testing.T) {
 	}
 }
 
+// warnLogger records the fields of Warn calls
+type warnLogger struct {
+	logging.NoOpLogger
+	warnings []map[string]any
+}
+
+func (l *warnLogger) Warn(ctx context.Context, msg string, fields map[string]any) {
+	l.warnings = append(l.warnings, fields)
+}
+
+func TestPredict_DropsDemosToFitContextWindow(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	logger := &warnLogger{}
+	previous := logging.GetLogger()
+	logging.SetLogger(logger)
+	defer logging.SetLogger(previous)
+
+	sig := core.NewSignature("Classify sentiment").
+		AddInput("text", core.FieldTypeString, "Text to classify").
+		AddOutput("sentiment", core.FieldTypeString, "positive or negative")
+	padding := strings.Repeat("x", 2000)
+	demos := []core.Example{
+		*core.NewExample(map[string]any{"text": "first " + padding}, map[string]any{"sentiment": "positive"}),
+		*core.NewExample(map[string]any{"text": "second " + padding}, map[string]any{"sentiment": "negative"}),
+		*core.NewExample(map[string]any{"text": "third " + padding}, map[string]any{"sentiment": "negative"}),
+	}
+
+	var capturedMessages []core.Message
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			capturedMessages = messages
+			return &core.GenerateResult{Content: `{"sentiment": "positive"}`}, nil
+		},
+	}
+	p := NewPredict(sig, lm).WithDemos(demos)
+	p.Options.MaxTokens = 100
+
+	// Room for a single demo
+	core.Configure(core.WithContextWindow(900))
+	prediction, err := p.Forward(context.Background(), map[string]any{"text": "Great experience!"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if prediction.Metadata["demo_count"] != 1 || prediction.Metadata["dropped_demos"] != 2 {
+		t.Errorf("metadata = %v, want demo_count 1 and dropped_demos 2", prediction.Metadata)
+	}
+	var prompt strings.Builder
+	for _, msg := range capturedMessages {
+		prompt.WriteString(msg.Content)
+	}
+	if !strings.Contains(prompt.String(), "first") || strings.Contains(prompt.String(), "third") {
+		t.Error("expected the last demos to be dropped first")
+	}
+	if len(logger.warnings) != 1 || logger.warnings[0]["dropped_demos"] != 2 {
+		t.Errorf("warnings = %v, want one naming 2 dropped demos", logger.warnings)
+	}
+}
+
 // TestPredict_WithHistoryAndDemos tests both features together
+func TestPredict_DemoLimits(t *testing.T) {
+	sig := core.NewSignature("Classify the movie").
+		AddInput("plot", core.FieldTypeString, "Plot").
+		AddOutput("genre", core.FieldTypeString, "Genre")
+	demos := []core.Example{
+		{Inputs: map[string]any{"plot": strings.Repeat("x", 500)}, Outputs: map[string]any{"genre": "drama"}},
+		{Inputs: map[string]any{"plot": "short"}, Outputs: map[string]any{"genre": "comedy"}},
+		{Inputs: map[string]any{"plot": "unused"}, Outputs: map[string]any{"genre": "horror"}},
+	}
+
+	p := NewPredict(sig, &MockLM{}).
+		WithAdapter(core.NewJSONAdapter()).
+		WithDemos(demos).
+		WithMaxDemos(2).
+		WithDemoTruncation(20)
+
+	used, err := p.DemosFor(context.Background(), map[string]any{"plot": "new"})
+	if err != nil {
+		t.Fatalf("DemosFor() error = %v", err)
+	}
+	if len(used) != 2 {
+		t.Fatalf("DemosFor() returned %d demos, want 2", len(used))
+	}
+	if plot := used[0].Inputs["plot"].(string); plot != strings.Repeat("x", 20)+"..." {
+		t.Errorf("first demo plot = %q, want truncated to 20 characters", plot)
+	}
+
+	messages, err := p.BuildPrompt(map[string]any{"plot": "new"})
+	if err != nil {
+		t.Fatalf("BuildPrompt() error = %v", err)
+	}
+	var prompt strings.Builder
+	for _, msg := range messages {
+		prompt.WriteString(msg.Content)
+	}
+	if strings.Contains(prompt.String(), strings.Repeat("x", 21)) {
+		t.Error("prompt contains the untruncated demo value")
+	}
+	if strings.Contains(prompt.String(), "unused") {
+		t.Error("prompt contains a demo beyond MaxDemos")
+	}
+}
+
 func TestPredict_WithHistoryAndDemos(t *testing.T) {
 	sig := core.NewSignature("Classify").
 		AddInput("text", core.FieldTypeString, "Text").
@@ -1307,13 +1831,13 @@ func TestPredict_WithHistoryAndDemos(t *testing.T) {
 		t.Fatalf("Forward() error = %v", err)
 	}
 
-	// First message should be system from history
-	if capturedMessages[0].Role != "system" {
-		t.Error("First message should be system message from history")
+	// The signature instruction leads, followed by the system message from history
+	if capturedMessages[0].Role != "system" || capturedMessages[1].Role != "system" {
+		t.Error("First messages should be the instruction and the system message from history")
 	}
 
 	// Subsequent message should contain demos and current input
-	promptContent := capturedMessages[1].Content
+	promptContent := capturedMessages[2].Content
 	if !strings.Contains(promptContent, "Example") {
 		t.Error("Prompt should include examples from demos")
 	}
@@ -1402,8 +1926,8 @@ func TestChainOfThought_WithDemos(t *testing.T) {
 		t.Fatalf("Forward() error = %v", err)
 	}
 
-	// Verify demos were included in first message
-	promptContent := capturedMessages[0].Content
+	// Verify demos were included in first message after the system instruction
+	promptContent := capturedMessages[1].Content
 	if !strings.Contains(promptContent, "Example") {
 		t.Error("Prompt should include demo examples")
 	}
@@ -1529,3 +2053,580 @@ func TestPredict_FinishReasonHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestPredict_BuildPromptAndEstimateUsage(t *testing.T) {
+	sig := core.NewSignature("Answer the question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	called := false
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			called = true
+			return &core.GenerateResult{Content: `{"answer": "4"}`}, nil
+		},
+	}
+
+	history := core.NewHistory()
+	history.AddUserMessage("earlier question")
+	history.AddAssistantMessage("earlier answer")
+	demos := []core.Example{
+		*core.NewExample(map[string]any{"question": "1+1?"}, map[string]any{"answer": "2"}),
+	}
+
+	predict := NewPredict(sig, lm).WithHistory(history).WithDemos(demos)
+	inputs := map[string]any{"question": "What is 2+2?"}
+
+	messages, err := predict.BuildPrompt(inputs)
+	if err != nil {
+		t.Fatalf("BuildPrompt() error = %v", err)
+	}
+	if len(messages) < 4 || messages[0].Role != "system" || messages[1].Content != "earlier question" {
+		t.Fatalf("expected the instruction then history to lead the prompt, got %+v", messages)
+	}
+
+	var prompt strings.Builder
+	for _, msg := range messages {
+		prompt.WriteString(msg.Content)
+	}
+	for _, want := range []string{"What is 2+2?", "1+1?", "answer"} {
+		if !strings.Contains(prompt.String(), want) {
+			t.Errorf("expected prompt to contain %q", want)
+		}
+	}
+
+	usage, err := predict.EstimateUsage(inputs)
+	if err != nil {
+		t.Fatalf("EstimateUsage() error = %v", err)
+	}
+	if want := core.EstimateMessageTokens(messages); usage.PromptTokens != want || usage.TotalTokens != want {
+		t.Errorf("EstimateUsage() = %+v, want %d prompt tokens", usage, want)
+	}
+
+	if called {
+		t.Error("BuildPrompt/EstimateUsage must not call the LM")
+	}
+	if history.Len() != 2 {
+		t.Errorf("expected history to be untouched, got %d messages", history.Len())
+	}
+
+	if _, err := predict.EstimateUsage(map[string]any{}); err == nil {
+		t.Error("expected input validation error")
+	}
+}
+
+func TestModules_BuildPromptContext_MatchesForward(t *testing.T) {
+	sig := core.NewSignature("Answer the question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	var sent []core.Message
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			sent = messages
+			return &core.GenerateResult{Content: `{"reasoning": "easy", "answer": "4"}`}, nil
+		},
+	}
+
+	// A per-call adapter and Assert feedback both change what Forward sends
+	ctx := core.WithCallAdapter(context.Background(), core.NewJSONAdapter())
+	ctx = core.WithFeedback(ctx, "Answer with digits only.")
+	inputs := map[string]any{"question": "What is 2+2?"}
+
+	predict := NewPredict(sig, lm)
+	cot := NewChainOfThought(sig, lm)
+	tests := []struct {
+		name    string
+		build   func() ([]core.Message, error)
+		forward func() (*core.Prediction, error)
+	}{
+		{"Predict", func() ([]core.Message, error) { return predict.BuildPromptContext(ctx, inputs) },
+			func() (*core.Prediction, error) { return predict.Forward(ctx, inputs) }},
+		{"ChainOfThought", func() ([]core.Message, error) { return cot.BuildPromptContext(ctx, inputs) },
+			func() (*core.Prediction, error) { return cot.Forward(ctx, inputs) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			built, err := tt.build()
+			if err != nil {
+				t.Fatalf("BuildPromptContext() error = %v", err)
+			}
+			if _, err := tt.forward(); err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if !reflect.DeepEqual(built, sent) {
+				t.Errorf("BuildPromptContext() = %+v\nForward sent %+v", built, sent)
+			}
+			if last := built[len(built)-1]; !strings.Contains(last.Content, "Answer with digits only.") {
+				t.Errorf("expected the Assert feedback last, got %+v", last)
+			}
+		})
+	}
+}
+
+// cachingLM caches Generate results the way providers do, keyed on the assembled prompt
+type cachingLM struct {
+	cache *core.LMCache
+	calls int
+}
+
+func (c *cachingLM) Generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	key := core.GenerateCacheKey(c.Name(), messages, options)
+	if cached, ok := c.cache.Get(key); ok {
+		return cached, nil
+	}
+	c.calls++
+	result := &core.GenerateResult{Content: fmt.Sprintf(`{"answer": "call %d"}`, c.calls)}
+	c.cache.Set(key, result)
+	return result, nil
+}
+
+func (c *cachingLM) Stream(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (<-chan core.Chunk, <-chan error) {
+	return nil, nil
+}
+
+func (c *cachingLM) Name() string        { return "caching" }
+func (c *cachingLM) SupportsJSON() bool  { return true }
+func (c *cachingLM) SupportsTools() bool { return false }
+
+func TestPredict_CacheKeyIncludesDemosAndHistory(t *testing.T) {
+	sig := core.NewSignature("Answer").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+	inputs := map[string]interface{}{"question": "What is 2+2?"}
+
+	demosA := []core.Example{*core.NewExample(map[string]any{"question": "1+1?"}, map[string]any{"answer": "2"})}
+	demosB := []core.Example{*core.NewExample(map[string]any{"question": "3+3?"}, map[string]any{"answer": "6"})}
+
+	lm := &cachingLM{cache: core.NewLMCache(10)}
+	run := func(p *Predict) {
+		t.Helper()
+		if _, err := p.Forward(context.Background(), inputs); err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+	}
+
+	run(NewPredict(sig, lm).WithDemos(demosA))
+	run(NewPredict(sig, lm).WithDemos(demosA))
+	if lm.calls != 1 {
+		t.Fatalf("identical prompts should hit the cache, got %d LM calls", lm.calls)
+	}
+
+	run(NewPredict(sig, lm).WithDemos(demosB))
+	if lm.calls != 2 {
+		t.Errorf("changing demos should miss the cache, got %d LM calls", lm.calls)
+	}
+
+	history := core.NewHistory()
+	history.AddUserMessage("earlier turn")
+	run(NewPredict(sig, lm).WithDemos(demosB).WithHistory(history))
+	if lm.calls != 3 {
+		t.Errorf("adding history should miss the cache, got %d LM calls", lm.calls)
+	}
+}
+
+func TestPredict_WithDynamicDemos(t *testing.T) {
+	sig := core.NewSignature("Classify genre").
+		AddInput("text", core.FieldTypeString, "Book blurb").
+		AddOutput("genre", core.FieldTypeString, "Genre")
+
+	pool := []core.Example{
+		*core.NewExample(map[string]any{"text": "starship crew"}, map[string]any{"genre": "scifi"}),
+		*core.NewExample(map[string]any{"text": "murder mystery"}, map[string]any{"genre": "mystery"}),
+	}
+	// One axis per keyword: "starship" vs "murder"
+	embedder := core.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		vectors := make([][]float64, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float64{float64(strings.Count(text, "starship")), float64(strings.Count(text, "murder"))}
+		}
+		return vectors, nil
+	})
+
+	var prompt string
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			var sb strings.Builder
+			for _, m := range messages {
+				sb.WriteString(m.Content)
+			}
+			prompt = sb.String()
+			return &core.GenerateResult{Content: `{"genre": "mystery"}`}, nil
+		},
+	}
+
+	p := NewPredict(sig, lm).WithDynamicDemos(pool, embedder, 1)
+	if _, err := p.Forward(context.Background(), map[string]any{"text": "a murder at sea"}); err != nil {
+		t.Fatalf("Forward() error: %v", err)
+	}
+	if !strings.Contains(prompt, "murder mystery") || strings.Contains(prompt, "starship crew") {
+		t.Errorf("expected only the nearest demo in the prompt, got:\n%s", prompt)
+	}
+
+	failing := core.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		return nil, errors.New("embeddings down")
+	})
+	p = NewPredict(sig, lm).WithDynamicDemos(pool, failing, 1)
+	if _, err := p.Forward(context.Background(), map[string]any{"text": "x"}); err == nil || !strings.Contains(err.Error(), "demo selection failed") {
+		t.Errorf("expected demo selection error, got %v", err)
+	}
+}
+
+func TestModules_WithTimeout(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	blockingLM := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	timeout := 20 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		module core.Module
+	}{
+		{name: "Predict", module: NewPredict(sig, blockingLM).WithTimeout(timeout)},
+		{name: "ChainOfThought", module: NewChainOfThought(sig, blockingLM).WithTimeout(timeout)},
+		{name: "Refine", module: NewRefine(sig, blockingLM).WithTimeout(timeout)},
+		{name: "Program", module: NewProgram("p").AddModule(NewPredict(sig, blockingLM)).WithTimeout(timeout)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.module.Forward(context.Background(), map[string]any{"question": "q"})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected deadline exceeded, got %v", err)
+			}
+		})
+	}
+
+	// A module without a timeout leaves the caller's context untouched
+	var hasDeadline bool
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			_, hasDeadline = ctx.Deadline()
+			return &core.GenerateResult{Content: `{"answer": "42"}`}, nil
+		},
+	}
+	if _, err := NewPredict(sig, lm).Forward(context.Background(), map[string]any{"question": "q"}); err != nil {
+		t.Fatalf("Forward() error: %v", err)
+	}
+	if hasDeadline {
+		t.Error("expected no deadline without WithTimeout")
+	}
+}
+
+func TestModules_WithTimeout_OverridesDefaultTimeout(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	core.Configure(core.WithTimeout(10 * time.Millisecond))
+
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	// Like the providers, the LM bounds its call with the default timeout
+	slowLM := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			ctx, cancel := core.WithDefaultTimeout(ctx)
+			defer cancel()
+			select {
+			case <-time.After(50 * time.Millisecond):
+				return &core.GenerateResult{Content: `{"answer": "42"}`}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+
+	if _, err := NewPredict(sig, slowLM).Forward(context.Background(), map[string]any{"question": "q"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the 10ms default timeout without WithTimeout, got %v", err)
+	}
+	if _, err := NewPredict(sig, slowLM).WithTimeout(time.Second).Forward(context.Background(), map[string]any{"question": "q"}); err != nil {
+		t.Errorf("expected WithTimeout(1s) to override the 10ms default, got %v", err)
+	}
+}
+
+// endlessStreamLM streams chunks until its context is cancelled
+type endlessStreamLM struct {
+	MockLM
+	running atomic.Int32
+}
+
+func (m *endlessStreamLM) Stream(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (<-chan core.Chunk, <-chan error) {
+	chunkChan := make(chan core.Chunk)
+	errChan := make(chan error, 1)
+	m.running.Add(1)
+
+	go func() {
+		defer m.running.Add(-1)
+		defer close(chunkChan)
+		defer close(errChan)
+		for {
+			select {
+			case chunkChan <- core.Chunk{Content: "tok "}:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return chunkChan, errChan
+}
+
+func TestPredict_Stream_CancelStopsGeneration(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+
+	lm := &endlessStreamLM{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result, err := NewPredict(sig, lm).Stream(ctx, map[string]any{"question": "q"})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	// Read a little, then stop reading and cancel
+	<-result.Chunks
+	<-result.Chunks
+	cancel()
+
+	select {
+	case err := <-result.Errors:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+
+	select {
+	case _, ok := <-result.Chunks:
+		for ok {
+			_, ok = <-result.Chunks
+		}
+	case <-time.After(time.Second):
+		t.Fatal("chunk channel was not closed after cancel")
+	}
+
+	// The LM stream goroutine must exit too
+	deadline := time.Now().Add(time.Second)
+	for lm.running.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("LM stream goroutine leaked after cancel")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestModules_Stream_WithTimeout(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+	timeout := 20 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		stream func(lm core.LM) (*StreamResult, error)
+	}{
+		{"Predict", func(lm core.LM) (*StreamResult, error) {
+			return NewPredict(sig, lm).WithTimeout(timeout).Stream(context.Background(), map[string]any{"question": "q"})
+		}},
+		{"ChainOfThought", func(lm core.LM) (*StreamResult, error) {
+			return NewChainOfThought(sig, lm).WithTimeout(timeout).Stream(context.Background(), map[string]any{"question": "q"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.stream(&endlessStreamLM{})
+			if err != nil {
+				t.Fatalf("Stream failed: %v", err)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for range result.Chunks {
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("stream did not stop at the module timeout")
+			}
+			if err := <-result.Errors; !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected deadline exceeded, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPredict_MissingOptionalOutputs(t *testing.T) {
+	sig := core.NewSignature("Answer with optional extras").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "").
+		AddOptionalOutput("optional_field", core.FieldTypeString, "").
+		AddOptionalOutput("score", core.FieldTypeFloat, "").
+		AddClassOutput("tone", []string{"formal", "casual"}, "")
+	sig.OutputFields[len(sig.OutputFields)-1].Optional = true
+
+	tests := []struct {
+		name    string
+		adapter core.Adapter
+		content string
+		wantErr bool
+	}{
+		{"json required only", core.NewJSONAdapter(), `{"answer": "42"}`, false},
+		{"json optional null", core.NewJSONAdapter(), `{"answer": "42", "optional_field": null, "score": null, "tone": null}`, false},
+		{"json optional empty", core.NewJSONAdapter(), `{"answer": "42", "optional_field": "", "tone": ""}`, false},
+		{"chat required only", core.NewChatAdapter(), "[[ ## answer ## ]]\n42\n", false},
+		{"chat optional empty", core.NewChatAdapter(), "[[ ## answer ## ]]\n42\n\n[[ ## optional_field ## ]]\n\n[[ ## tone ## ]]\n", false},
+		{"fallback required only", core.NewFallbackAdapter(), "[[ ## answer ## ]]\n42\n", false},
+		{"json missing required", core.NewJSONAdapter(), `{"optional_field": "note"}`, true},
+		{"chat missing required", core.NewChatAdapter(), "[[ ## optional_field ## ]]\nnote\n", true},
+		{"fallback missing required", core.NewFallbackAdapter(), "[[ ## optional_field ## ]]\nnote\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := &MockLM{
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					return &core.GenerateResult{Content: tt.content, FinishReason: "stop"}, nil
+				},
+			}
+
+			pred, err := NewPredict(sig, lm).WithAdapter(tt.adapter).Forward(context.Background(), map[string]any{"question": "q"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for missing required output, got %v", pred.Outputs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			if answer, ok := pred.GetString("answer"); !ok || answer != "42" {
+				t.Errorf("GetString(answer) = (%q, %v), want (\"42\", true)", answer, ok)
+			}
+			if value, ok := pred.GetString("optional_field"); value != "" || ok {
+				t.Errorf("GetString(optional_field) = (%q, %v), want (\"\", false)", value, ok)
+			}
+			for _, key := range []string{"score", "tone"} {
+				if _, ok := pred.Get(key); ok {
+					t.Errorf("omitted optional output %q should be absent, got %v", key, pred.Outputs[key])
+				}
+			}
+			if pred.FallbackUsed {
+				t.Error("missing optional outputs should not trigger adapter fallback")
+			}
+		})
+	}
+}
+
+// TestPredict_ConcurrentForward_SharedModule shares one configured Predict and
+// ChainOfThought across goroutines, as a server handling many requests would;
+// run with -race to check for shared mutable state
+func TestPredict_ConcurrentForward_SharedModule(t *testing.T) {
+	sig := core.NewSignature("Classify sentiment").
+		AddInput("text", core.FieldTypeString, "Text to classify").
+		AddClassOutput("sentiment", []string{"positive", "negative"}, "Sentiment")
+	lm := &MockLM{
+		SupportsJSONVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			if options.ResponseFormat == "json" {
+				return &core.GenerateResult{Content: `{"sentiment": "positive"}`}, nil
+			}
+			return &core.GenerateResult{Content: "[[ ## sentiment ## ]]\npositive"}, nil
+		},
+	}
+	demos := []core.Example{
+		*core.NewExample(map[string]any{"text": "I love it"}, map[string]any{"sentiment": "positive"}),
+		*core.NewExample(map[string]any{"text": "I hate it"}, map[string]any{"sentiment": "negative"}),
+	}
+
+	modules := map[string]core.Module{
+		"Predict":        NewPredict(sig, lm).WithDemos(demos).WithDemoTruncation(5).WithMaxTokensPerField(map[string]int{"sentiment": 10}),
+		"ChainOfThought": NewChainOfThought(sig, lm).WithDemos(demos),
+	}
+	for name, m := range modules {
+		t.Run(name, func(t *testing.T) {
+			const n = 50
+			var wg sync.WaitGroup
+			errs := make(chan error, n)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					prediction, err := m.Forward(context.Background(), map[string]any{"text": fmt.Sprintf("review %d", i)})
+					if err != nil {
+						errs <- err
+						return
+					}
+					if prediction.Inputs["text"] != fmt.Sprintf("review %d", i) {
+						errs <- fmt.Errorf("call %d got the prediction of %v", i, prediction.Inputs["text"])
+					}
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPredict_Stream_UnparseableOutput(t *testing.T) {
+	sig := core.NewSignature("Count").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("count", core.FieldTypeInt, "").
+		AddOutput("unit", core.FieldTypeString, "")
+	newLM := func() core.LM {
+		return &mockStreamingLM{chunks: []core.Chunk{
+			{Content: "I'm not sure, "},
+			{Content: "maybe a dozen?", FinishReason: "stop", Usage: core.Usage{TotalTokens: 12}},
+		}}
+	}
+
+	for _, partial := range []bool{false, true} {
+		t.Run(fmt.Sprintf("partial=%v", partial), func(t *testing.T) {
+			result, err := NewPredict(sig, newLM()).
+				WithPartialStreamOnError(partial).
+				Stream(context.Background(), map[string]any{"question": "How many eggs?"})
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			for range result.Chunks {
+			}
+
+			var parseErr *core.ParseError
+			if err := <-result.Errors; !errors.As(err, &parseErr) || !strings.Contains(parseErr.Raw, "maybe a dozen?") {
+				t.Fatalf("stream error = %v, want a *core.ParseError with the raw output", err)
+			}
+
+			prediction, ok := <-result.Prediction
+			if !partial {
+				if ok {
+					t.Errorf("Prediction = %v, want the channel closed without a value", prediction)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected a partial prediction")
+			}
+			if prediction.Metadata["raw_content"] != "I'm not sure, maybe a dozen?" || prediction.Metadata["parse_error"] == nil {
+				t.Errorf("Metadata = %v, want the raw content and parse error", prediction.Metadata)
+			}
+			if len(prediction.Outputs) != 0 || prediction.ParseSuccess || prediction.Usage.TotalTokens != 12 {
+				t.Errorf("prediction = %+v, want no outputs and the stream usage", prediction)
+			}
+		})
+	}
+}
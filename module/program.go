@@ -3,55 +3,105 @@ package module
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
 
 // Program represents a composable pipeline of modules
 type Program struct {
-	modules []core.Module
+	steps   []programStep
 	name    string
+	timeout time.Duration // Deadline for each Forward (0 = none)
+}
+
+// programStep is a module in the pipeline, optionally named with an explicit input mapping
+type programStep struct {
+	name     string
+	module   core.Module
+	inputMap map[string]string // nil passes all accumulated fields implicitly
 }
 
 // NewProgram creates a new program
 func NewProgram(name string) *Program {
 	return &Program{
-		name:    name,
-		modules: []core.Module{},
+		name:  name,
+		steps: []programStep{},
 	}
 }
 
 // AddModule adds a module to the program pipeline
+// The module receives the original inputs merged with all previous outputs
 func (p *Program) AddModule(module core.Module) *Program {
-	p.modules = append(p.modules, module)
+	p.steps = append(p.steps, programStep{module: module})
+	return p
+}
+
+// AddStep adds a named module with an explicit input mapping to the pipeline.
+// inputMap maps each input field of the module to a source key: an original
+// input or previous output ("summary"), or a field of a named step
+// ("extract.summary"). Only mapped fields are passed to the module, so a step
+// cannot accidentally consume an unrelated field with the same name.
+// The step's prediction is available on the result via Step(name).
+func (p *Program) AddStep(name string, module core.Module, inputMap map[string]string) *Program {
+	p.steps = append(p.steps, programStep{name: name, module: module, inputMap: inputMap})
 	return p
 }
 
 // Forward executes the program by running modules in sequence
 // Each module's outputs become available as inputs to subsequent modules
 func (p *Program) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
-	if len(p.modules) == 0 {
+	return withErrorTrace(ctx, "Program", inputs, p.forward)
+}
+
+// forward is Forward without error tracing
+func (p *Program) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, p.timeout)
+	defer cancel()
+
+	if len(p.steps) == 0 {
 		return nil, fmt.Errorf("program has no modules")
 	}
+	if err := p.validateSteps(); err != nil {
+		return nil, err
+	}
 
 	currentInputs := inputs
 	finalOutputs := make(map[string]any)
+	stepPredictions := make(map[string]*core.Prediction)
 	var lastPrediction *core.Prediction
 	var totalUsage core.Usage
+	subUsage := make(map[string]core.Usage, len(p.steps))
+
+	for i, step := range p.steps {
+		label := step.label(i)
+
+		stepInputs := currentInputs
+		if step.inputMap != nil {
+			mapped, err := resolveStepInputs(step, currentInputs, stepPredictions)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", label, err)
+			}
+			stepInputs = mapped
+		}
 
-	for i, module := range p.modules {
-		prediction, err := module.Forward(ctx, currentInputs)
+		prediction, err := step.module.Forward(ctx, stepInputs)
 		if err != nil {
-			return nil, fmt.Errorf("module %d failed: %w", i, err)
+			return nil, fmt.Errorf("%s failed: %w", label, err)
 		}
 
 		// Validate outputs against module signature to catch malformed data early
-		if sig := module.GetSignature(); sig != nil {
+		if sig := step.module.GetSignature(); sig != nil {
 			if err := sig.ValidateOutputs(prediction.Outputs); err != nil {
-				return nil, fmt.Errorf("module %d produced invalid outputs: %w", i, err)
+				return nil, fmt.Errorf("%s produced invalid outputs: %w", label, err)
 			}
 		}
 
+		if step.name != "" {
+			stepPredictions[step.name] = prediction
+		}
+
 		// Accumulate outputs from all modules
 		for k, v := range prediction.Outputs {
 			finalOutputs[k] = v
@@ -61,9 +111,8 @@ func (p *Program) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 		lastPrediction = prediction
 
 		// Accumulate usage stats
-		totalUsage.PromptTokens += prediction.Usage.PromptTokens
-		totalUsage.CompletionTokens += prediction.Usage.CompletionTokens
-		totalUsage.TotalTokens += prediction.Usage.TotalTokens
+		totalUsage.Add(prediction.Usage)
+		subUsage[step.usageKey(i)] = prediction.Usage
 
 		// Merge outputs into inputs for next module
 		// This allows modules to access both original inputs and previous outputs
@@ -82,6 +131,11 @@ func (p *Program) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 		WithUsage(totalUsage).
 		WithModuleName(p.name).
 		WithInputs(inputs)
+	finalPrediction.SubUsage = subUsage
+
+	for name, prediction := range stepPredictions {
+		finalPrediction.WithStep(name, prediction)
+	}
 
 	// Carry over rationale from last prediction if available
 	if lastPrediction != nil && lastPrediction.Rationale != "" {
@@ -91,12 +145,91 @@ func (p *Program) Forward(ctx context.Context, inputs map[string]any) (*core.Pre
 	return finalPrediction, nil
 }
 
+// validateSteps rejects duplicate step names and mappings that reference later or unknown steps
+func (p *Program) validateSteps() error {
+	seen := make(map[string]bool)
+	for i, step := range p.steps {
+		for field, source := range step.inputMap {
+			if stepName, _, ok := strings.Cut(source, "."); ok && !seen[stepName] && p.hasStep(stepName) {
+				return fmt.Errorf("%s: input %q references step %q before it runs", step.label(i), field, stepName)
+			}
+		}
+		if step.name == "" {
+			continue
+		}
+		if seen[step.name] {
+			return fmt.Errorf("duplicate step name %q", step.name)
+		}
+		seen[step.name] = true
+	}
+	return nil
+}
+
+// hasStep reports whether the program has a step with the given name
+func (p *Program) hasStep(name string) bool {
+	for _, step := range p.steps {
+		if step.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// label identifies a step in error messages
+func (s programStep) label(index int) string {
+	if s.name != "" {
+		return fmt.Sprintf("step %q", s.name)
+	}
+	return fmt.Sprintf("module %d", index)
+}
+
+// usageKey names the step in Prediction.SubUsage: its name, or "module_<i>" when unnamed
+func (s programStep) usageKey(index int) string {
+	if s.name != "" {
+		return s.name
+	}
+	return fmt.Sprintf("module_%d", index)
+}
+
+// resolveStepInputs builds a step's inputs from its input mapping
+// Sources of the form "step.field" read from that step's outputs; other
+// sources read from the original inputs merged with previous outputs
+func resolveStepInputs(step programStep, available map[string]any, steps map[string]*core.Prediction) (map[string]any, error) {
+	inputs := make(map[string]any, len(step.inputMap))
+	for field, source := range step.inputMap {
+		if stepName, key, ok := strings.Cut(source, "."); ok {
+			if prediction, exists := steps[stepName]; exists {
+				value, found := prediction.Outputs[key]
+				if !found {
+					return nil, fmt.Errorf("input %q maps to %q, but step %q has no output %q", field, source, stepName, key)
+				}
+				inputs[field] = value
+				continue
+			}
+		}
+
+		value, found := available[source]
+		if !found {
+			return nil, fmt.Errorf("input %q maps to unknown key %q", field, source)
+		}
+		inputs[field] = value
+	}
+	return inputs, nil
+}
+
+// WithTimeout sets a deadline for each Forward call, shared by all steps
+// It replaces the global default timeout for those calls; a caller deadline still applies.
+func (p *Program) WithTimeout(timeout time.Duration) *Program {
+	p.timeout = timeout
+	return p
+}
+
 // GetSignature returns the signature of the last module in the pipeline
 func (p *Program) GetSignature() *core.Signature {
-	if len(p.modules) == 0 {
+	if len(p.steps) == 0 {
 		return nil
 	}
-	return p.modules[len(p.modules)-1].GetSignature()
+	return p.steps[len(p.steps)-1].module.GetSignature()
 }
 
 // Name returns the program name
@@ -106,5 +239,5 @@ func (p *Program) Name() string {
 
 // ModuleCount returns the number of modules in the program
 func (p *Program) ModuleCount() int {
-	return len(p.modules)
+	return len(p.steps)
 }
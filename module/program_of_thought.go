@@ -2,8 +2,8 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -18,7 +18,11 @@ type ProgramOfThought struct {
 	Options          *core.GenerateOptions
 	Language         string // "python", "javascript", "go"
 	AllowExecution   bool
-	ExecutionTimeout int // seconds
+	ExecutionTimeout int           // seconds
+	Executor         CodeExecutor  // Runs generated code (nil = local runtime for Language)
+	Timeout          time.Duration // Deadline for each Forward (0 = none)
+	// MaxTokensPerField budgets completion tokens per output field (see WithMaxTokensPerField)
+	MaxTokensPerField map[string]int
 }
 
 // NewProgramOfThought creates a new ProgramOfThought module
@@ -39,6 +43,13 @@ func (pot *ProgramOfThought) WithOptions(options *core.GenerateOptions) *Program
 	return pot
 }
 
+// WithMaxTokensPerField sizes MaxTokens from per-output-field token budgets, replacing
+// any fixed Options.MaxTokens. Fields without a budget get core.FieldTokenBudgets for their type.
+func (pot *ProgramOfThought) WithMaxTokensPerField(budgets map[string]int) *ProgramOfThought {
+	pot.MaxTokensPerField = budgets
+	return pot
+}
+
 // WithAllowExecution enables code execution (use with caution!)
 func (pot *ProgramOfThought) WithAllowExecution(allow bool) *ProgramOfThought {
 	pot.AllowExecution = allow
@@ -51,6 +62,20 @@ func (pot *ProgramOfThought) WithExecutionTimeout(seconds int) *ProgramOfThought
 	return pot
 }
 
+// WithExecutor sets how generated code is run when execution is allowed, e.g. in a
+// container or remote sandbox instead of a local subprocess
+func (pot *ProgramOfThought) WithExecutor(executor CodeExecutor) *ProgramOfThought {
+	pot.Executor = executor
+	return pot
+}
+
+// WithTimeout sets a deadline for each Forward call
+// It applies on top of any deadline already on the caller's context.
+func (pot *ProgramOfThought) WithTimeout(timeout time.Duration) *ProgramOfThought {
+	pot.Timeout = timeout
+	return pot
+}
+
 // GetSignature returns the module's signature
 func (pot *ProgramOfThought) GetSignature() *core.Signature {
 	return pot.Signature
@@ -58,6 +83,14 @@ func (pot *ProgramOfThought) GetSignature() *core.Signature {
 
 // Forward executes the program of thought
 func (pot *ProgramOfThought) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "ProgramOfThought", inputs, pot.forward)
+}
+
+// forward is Forward without error tracing
+func (pot *ProgramOfThought) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, pot.Timeout)
+	defer cancel()
+
 	if err := pot.Signature.ValidateInputs(inputs); err != nil {
 		return nil, fmt.Errorf("input validation failed: %w", err)
 	}
@@ -71,17 +104,27 @@ func (pot *ProgramOfThought) Forward(ctx context.Context, inputs map[string]any)
 		{Role: "user", Content: prompt},
 	}
 
+	// Append corrective feedback from an enclosing Assert, if any
+	messages = core.ApplyFeedback(ctx, messages)
+
 	// Copy options to avoid mutation
 	options := pot.Options.Copy()
+	options.MaxTokens = pot.Signature.ResolveMaxTokens(options.MaxTokens, pot.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
 	// ProgramOfThought uses FallbackAdapter but prefers JSON for reliable parsing
 	// Force JSON mode to ensure models follow the format specification
 	options.ResponseFormat = "json"
-	// Auto-generate JSON schema from signature for structured outputs
-	if options.ResponseSchema == nil {
+	// Auto-generate JSON schema from signature when the model supports structured outputs
+	if options.ResponseSchema == nil && core.CapabilitiesOf(pot.LM).SupportsJSONSchema {
 		options.ResponseSchema = pot.Signature.SignatureToJSONSchema()
 	}
 
+	if result, ok := core.DryRun(ctx, pot.LM.Name(), messages, options); ok {
+		return dryRunPrediction("ProgramOfThought", inputs, result), nil
+	}
+
 	result, err := pot.LM.Generate(ctx, messages, options)
+	recordCall(ctx, "ProgramOfThought", messages, result, err)
 	if err != nil {
 		return nil, fmt.Errorf("LM generation failed: %w", err)
 	}
@@ -98,7 +141,7 @@ func (pot *ProgramOfThought) Forward(ctx context.Context, inputs map[string]any)
 
 	// Check for empty content with finish_reason=stop (actual error)
 	if result.Content == "" && result.FinishReason == "stop" {
-		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop (model error)")
+		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop: %w", core.ErrEmptyResponse)
 	}
 
 	// Use FallbackAdapter to parse output
@@ -129,14 +172,21 @@ func (pot *ProgramOfThought) Forward(ctx context.Context, inputs map[string]any)
 	// Extract adapter metadata
 	adapterUsed, parseAttempts, fallbackUsed := core.ExtractAdapterMetadata(outputs)
 
-	// Execute code if enabled
+	// Execute code if enabled: stdout becomes execution_result, and a failure to run,
+	// a timeout or a non-zero exit (with its stderr) becomes execution_error
 	if pot.AllowExecution {
 		if code, exists := outputs["code"]; exists {
-			executionResult, err := pot.executeCode(ctx, fmt.Sprintf("%v", code))
-			if err != nil {
+			execResult, err := pot.executeCode(ctx, fmt.Sprintf("%v", code))
+			if execResult != nil && execResult.Stdout != "" {
+				outputs["execution_result"] = execResult.Stdout
+			}
+			switch {
+			case err != nil:
 				outputs["execution_error"] = err.Error()
-			} else {
-				outputs["execution_result"] = executionResult
+			case execResult.ExitCode != 0:
+				outputs["execution_error"] = fmt.Sprintf("exit code %d: %s", execResult.ExitCode, strings.TrimSpace(execResult.Stderr))
+			case execResult.Stdout == "":
+				outputs["execution_result"] = ""
 			}
 		}
 	}
@@ -227,34 +277,30 @@ func (pot *ProgramOfThought) buildPrompt(inputs map[string]any) (string, error)
 	return prompt.String(), nil
 }
 
-func (pot *ProgramOfThought) executeCode(ctx context.Context, code string) (string, error) {
-	// Create a timeout context for code execution
-	execCtx, cancel := context.WithTimeout(ctx, time.Duration(pot.ExecutionTimeout)*time.Second)
-	defer cancel()
+// executeCode runs code with the configured executor under ExecutionTimeout
+func (pot *ProgramOfThought) executeCode(ctx context.Context, code string) (*ExecutionResult, error) {
+	executor := pot.Executor
+	if executor == nil {
+		if executor = defaultExecutor(pot.Language); executor == nil {
+			return nil, fmt.Errorf("unsupported language: %s (set a CodeExecutor with WithExecutor)", pot.Language)
+		}
+	}
 
-	var cmd *exec.Cmd
-
-	switch pot.Language {
-	case "python":
-		cmd = exec.CommandContext(execCtx, "python3", "-c", code)
-	case "javascript":
-		cmd = exec.CommandContext(execCtx, "node", "-e", code)
-	case "go":
-		// Go requires a file, so we'll skip execution for now
-		return "", fmt.Errorf("go code execution not yet supported")
-	default:
-		return "", fmt.Errorf("unsupported language: %s", pot.Language)
+	execCtx := ctx
+	if pot.ExecutionTimeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, time.Duration(pot.ExecutionTimeout)*time.Second)
+		defer cancel()
 	}
 
-	output, err := cmd.CombinedOutput()
+	result, err := executor.Execute(execCtx, code)
+	if errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return result, fmt.Errorf("execution timeout after %d seconds", pot.ExecutionTimeout)
+	}
 	if err != nil {
-		if execCtx.Err() == context.DeadlineExceeded {
-			return string(output), fmt.Errorf("execution timeout after %d seconds", pot.ExecutionTimeout)
-		}
-		return string(output), fmt.Errorf("execution failed: %w", err)
+		return result, fmt.Errorf("execution failed: %w", err)
 	}
-
-	return string(output), nil
+	return result, nil
 }
 
 // extractTextOutputs attempts to extract output fields from raw text when structured parsing fails
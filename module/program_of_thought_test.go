@@ -3,9 +3,9 @@ package module
 import (
 	"context"
 	"errors"
-	"testing"
-
+	"os/exec"
 	"strings"
+	"testing"
 
 	"github.com/assagman/dsgo/core"
 )
@@ -140,12 +140,97 @@ func TestProgramOfThought_ExecuteCode_UnsupportedLanguage(t *testing.T) {
 	}
 }
 
-func TestProgramOfThought_ExecuteCode_GoNotSupported(t *testing.T) {
+func TestProgramOfThought_ExecuteCode_Go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
 	pot := NewProgramOfThought(core.NewSignature("Test"), &MockLM{}, "go")
 
-	_, err := pot.executeCode(context.Background(), "package main")
-	if err == nil {
-		t.Error("executeCode should error on Go (not yet supported)")
+	result, err := pot.executeCode(context.Background(), "import \"fmt\"\n\nfunc main() { fmt.Println(6 * 7) }")
+	if err != nil {
+		t.Fatalf("executeCode() error = %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "42" || result.ExitCode != 0 {
+		t.Errorf("executeCode() = %+v, want stdout 42 and exit code 0", result)
+	}
+}
+
+func TestProgramOfThought_WithExecutor(t *testing.T) {
+	sig := core.NewSignature("Calculate").
+		AddInput("problem", core.FieldTypeString, "Problem").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{
+				Content: `{"code": "(print 42)", "answer": "42", "explanation": "Prints the answer"}`,
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		result     *ExecutionResult
+		err        error
+		wantResult string
+		wantError  string
+	}{
+		{
+			name:       "success",
+			result:     &ExecutionResult{Stdout: "42\n"},
+			wantResult: "42\n",
+		},
+		{
+			name:       "non-zero exit",
+			result:     &ExecutionResult{Stdout: "partial\n", Stderr: "boom\n", ExitCode: 2},
+			wantResult: "partial\n",
+			wantError:  "exit code 2: boom",
+		},
+		{
+			name:      "sandbox unavailable",
+			err:       errors.New("sandbox unreachable"),
+			wantError: "execution failed: sandbox unreachable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCode string
+			executor := CodeExecutorFunc(func(ctx context.Context, code string) (*ExecutionResult, error) {
+				gotCode = code
+				return tt.result, tt.err
+			})
+			pot := NewProgramOfThought(sig, lm, "lisp").WithAllowExecution(true).WithExecutor(executor)
+
+			prediction, err := pot.Forward(context.Background(), map[string]any{"problem": "6*7"})
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if gotCode != "(print 42)" {
+				t.Errorf("executor got code %q", gotCode)
+			}
+			if got, _ := prediction.Outputs["execution_result"].(string); got != tt.wantResult {
+				t.Errorf("execution_result = %q, want %q", got, tt.wantResult)
+			}
+			if got, _ := prediction.Outputs["execution_error"].(string); got != tt.wantError {
+				t.Errorf("execution_error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProgramOfThought_ExecutionTimeout(t *testing.T) {
+	executor := CodeExecutorFunc(func(ctx context.Context, code string) (*ExecutionResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	pot := NewProgramOfThought(core.NewSignature("Test"), &MockLM{}, "python").
+		WithExecutor(executor).
+		WithExecutionTimeout(1)
+
+	_, err := pot.executeCode(context.Background(), "while True: pass")
+	if err == nil || !strings.Contains(err.Error(), "execution timeout after 1 seconds") {
+		t.Errorf("executeCode() error = %v, want execution timeout", err)
 	}
 }
 
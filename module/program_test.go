@@ -3,6 +3,7 @@ package module
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/assagman/dsgo/core"
@@ -194,3 +195,147 @@ func TestProgram_Forward_ValidationSuccess(t *testing.T) {
 		t.Error("Should complete full pipeline")
 	}
 }
+
+func TestProgram_AddStep_ExplicitMapping(t *testing.T) {
+	extract := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return core.NewPrediction(map[string]interface{}{"summary": "extracted", "label": "from-extract"}), nil
+		},
+	}
+	classify := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return core.NewPrediction(map[string]interface{}{"label": "from-classify"}), nil
+		},
+	}
+
+	var received map[string]interface{}
+	report := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			received = inputs
+			return core.NewPrediction(map[string]interface{}{"report": "done"}), nil
+		},
+	}
+
+	program := NewProgram("pipeline").
+		AddStep("extract", extract, map[string]string{"text": "document"}).
+		AddStep("classify", classify, map[string]string{"text": "extract.summary"}).
+		AddStep("report", report, map[string]string{
+			"summary":  "summary",
+			"category": "extract.label",
+			"source":   "document",
+		})
+
+	pred, err := program.Forward(context.Background(), map[string]interface{}{
+		"document": "raw text",
+		"noise":    "should not leak",
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	want := map[string]interface{}{"summary": "extracted", "category": "from-extract", "source": "raw text"}
+	if len(received) != len(want) {
+		t.Errorf("report received %v, want exactly %v", received, want)
+	}
+	for k, v := range want {
+		if received[k] != v {
+			t.Errorf("report input %q = %v, want %v", k, received[k], v)
+		}
+	}
+
+	if pred.Step("extract") == nil || pred.Step("extract").Outputs["label"] != "from-extract" {
+		t.Errorf("expected extract step outputs, got %v", pred.Step("extract"))
+	}
+	if pred.Step("classify").Outputs["label"] != "from-classify" {
+		t.Errorf("expected classify step outputs, got %v", pred.Step("classify").Outputs)
+	}
+	if pred.Step("missing") != nil {
+		t.Error("expected nil for unknown step")
+	}
+}
+
+func TestProgram_AddStep_Errors(t *testing.T) {
+	noop := &MockModule{}
+
+	tests := []struct {
+		name    string
+		program *Program
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			program: NewProgram("p").AddStep("a", noop, map[string]string{"text": "missing"}),
+			wantErr: `step "a": input "text" maps to unknown key "missing"`,
+		},
+		{
+			name: "unknown step output",
+			program: NewProgram("p").
+				AddStep("a", noop, nil).
+				AddStep("b", noop, map[string]string{"text": "a.missing"}),
+			wantErr: `step "a" has no output "missing"`,
+		},
+		{
+			name: "forward reference",
+			program: NewProgram("p").
+				AddStep("a", noop, map[string]string{"text": "b.result"}).
+				AddStep("b", noop, nil),
+			wantErr: `references step "b" before it runs`,
+		},
+		{
+			name: "duplicate name",
+			program: NewProgram("p").
+				AddStep("a", noop, nil).
+				AddStep("a", noop, nil),
+			wantErr: `duplicate step name "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.program.Forward(context.Background(), map[string]interface{}{"input": "x"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Forward() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProgram_AddStep_StepErrorNamed(t *testing.T) {
+	failing := &MockModule{
+		ForwardFunc: func(ctx context.Context, inputs map[string]interface{}) (*core.Prediction, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	_, err := NewProgram("p").AddStep("extract", failing, nil).Forward(context.Background(), map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), `step "extract" failed: boom`) {
+		t.Errorf("expected named step error, got %v", err)
+	}
+}
+
+func TestProgram_UsageAggregation(t *testing.T) {
+	stage := func(output string, usage core.Usage) *MockModule {
+		return &MockModule{
+			ForwardFunc: func(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+				return core.NewPrediction(map[string]any{output: "ok"}).WithUsage(usage), nil
+			},
+		}
+	}
+
+	program := NewProgram("pipeline").
+		AddStep("draft", stage("draft", core.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Cost: 0.5}), nil).
+		AddModule(stage("final", core.Usage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, Cost: 0.25}))
+
+	pred, err := program.Forward(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	want := core.Usage{PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, Cost: 0.75}
+	if pred.Usage != want {
+		t.Errorf("Usage = %+v, want sum over stages %+v", pred.Usage, want)
+	}
+	if pred.SubUsage["draft"].TotalTokens != 15 || pred.SubUsage["module_1"].TotalTokens != 30 {
+		t.Errorf("SubUsage = %+v, want entries for draft and module_1", pred.SubUsage)
+	}
+}
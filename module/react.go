@@ -7,8 +7,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
 )
 
 const (
@@ -26,6 +29,25 @@ type ReAct struct {
 	Demos         []core.Example // Optional few-shot examples
 	MaxIterations int
 	Verbose       bool
+	// LoopDetection skips repeated tool calls with identical arguments and
+	// nudges the model toward a different approach or a final answer
+	LoopDetection bool
+	// ForceAnswerOnMaxIterations lets all MaxIterations use tools, then makes
+	// one extra call asking for the best answer given the observations so far
+	ForceAnswerOnMaxIterations bool
+	// Timeout bounds each Forward, including all iterations (0 = no module deadline)
+	Timeout time.Duration
+	// PerIterationTimeout bounds the LM call and tool executions of a single iteration
+	PerIterationTimeout time.Duration
+	// MaxTokensPerField budgets completion tokens per output field (see WithMaxTokensPerField)
+	MaxTokensPerField map[string]int
+	// ProgressGuard is consulted after each tool-using iteration and can stop the run early
+	ProgressGuard ProgressGuard
+	// DirectAnswerTool names a tool whose result is the final answer (see WithDirectToolAnswer)
+	DirectAnswerTool string
+
+	snapshotMu sync.Mutex
+	snapshot   []byte // Latest checkpoint of the running or last run, see Snapshot
 }
 
 // NewReAct creates a new ReAct module
@@ -55,6 +77,13 @@ func (r *ReAct) WithOptions(options *core.GenerateOptions) *ReAct {
 	return r
 }
 
+// WithMaxTokensPerField sizes MaxTokens from per-output-field token budgets, replacing
+// any fixed Options.MaxTokens. Fields without a budget get core.FieldTokenBudgets for their type.
+func (r *ReAct) WithMaxTokensPerField(budgets map[string]int) *ReAct {
+	r.MaxTokensPerField = budgets
+	return r
+}
+
 // WithAdapter sets a custom adapter
 func (r *ReAct) WithAdapter(adapter core.Adapter) *ReAct {
 	r.Adapter = adapter
@@ -79,6 +108,53 @@ func (r *ReAct) WithMaxIterations(max int) *ReAct {
 	return r
 }
 
+// WithLoopDetection enables detection of repeated (tool, arguments) calls
+func (r *ReAct) WithLoopDetection(enabled bool) *ReAct {
+	r.LoopDetection = enabled
+	return r
+}
+
+// WithProgressGuard sets a guard consulted after each tool-using iteration with the
+// traces so far; when it returns true the run stops and the best answer is extracted
+// from the transcript, with Metadata["stop_reason"] = "progress_guard".
+// NoNewInformationGuard(k) stops runs whose last k iterations learned nothing new.
+func (r *ReAct) WithProgressGuard(guard ProgressGuard) *ReAct {
+	r.ProgressGuard = guard
+	return r
+}
+
+// WithDirectToolAnswer ends the run as soon as the named tool succeeds, mapping its result
+// to the outputs without another LM call: a map (or JSON object) result provides the output
+// fields by name, and any other result fills the only output field of the signature.
+// Use it for authoritative tools such as calculators, whose output the model could only
+// distort. A result that does not map to valid outputs is an observation as usual.
+func (r *ReAct) WithDirectToolAnswer(toolName string) *ReAct {
+	r.DirectAnswerTool = toolName
+	return r
+}
+
+// WithForceAnswerOnMaxIterations makes one final answer call after MaxIterations
+// tool-using iterations instead of spending the last iteration on the answer
+func (r *ReAct) WithForceAnswerOnMaxIterations(force bool) *ReAct {
+	r.ForceAnswerOnMaxIterations = force
+	return r
+}
+
+// WithTimeout sets a deadline for each Forward, overriding the global default timeout
+// and longer deadlines from the caller
+func (r *ReAct) WithTimeout(timeout time.Duration) *ReAct {
+	r.Timeout = timeout
+	return r
+}
+
+// WithPerIterationTimeout bounds each Thought -> Action -> Observation iteration
+// so a single stuck LM call or tool does not consume the whole Forward budget.
+// A tool that times out is reported to the model as an error observation.
+func (r *ReAct) WithPerIterationTimeout(timeout time.Duration) *ReAct {
+	r.PerIterationTimeout = timeout
+	return r
+}
+
 // WithVerbose enables verbose logging
 func (r *ReAct) WithVerbose(verbose bool) *ReAct {
 	r.Verbose = verbose
@@ -90,49 +166,185 @@ func (r *ReAct) GetSignature() *core.Signature {
 	return r.Signature
 }
 
+// ToolSchemas returns the function schemas of the agent's tools, including finish, exactly
+// as they are sent to tool-calling models (see core.Tool.FunctionSchema). Marshal them to
+// JSON to check how a tool's parameters were serialized when a model won't call it.
+func (r *ReAct) ToolSchemas() []map[string]any {
+	schemas := make([]map[string]any, 0, len(r.Tools))
+	for i := range r.Tools {
+		schemas = append(schemas, r.Tools[i].FunctionSchema())
+	}
+	return schemas
+}
+
 // Forward executes the ReAct loop
 func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "ReAct", inputs, func(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+		recordToolSchemas(ctx, r.ToolSchemas)
+		return r.run(ctx, inputs, func(ReActEvent) {})
+	})
+}
+
+// assemblePrompt formats the ReAct system prompt, inputs, demos and history
+// into messages, applying the configured context-window truncation policy
+func (r *ReAct) assemblePrompt(ctx context.Context, inputs map[string]any) ([]core.Message, []core.Message, error) {
+	var prefix []core.Message
+	if systemPrompt := r.buildSystemPrompt(); systemPrompt != "" {
+		prefix = append(prefix, core.Message{Role: "system", Content: systemPrompt})
+	}
+
+	messages, newMessages, _, err := assemble(ctx, "ReAct", r.LM.Name(), core.PromptAssembly{
+		Adapter:   r.Adapter,
+		Signature: r.Signature,
+		Inputs:    inputs,
+		Demos:     r.Demos,
+		History:   r.History,
+		Prefix:    prefix,
+		MaxTokens: r.Signature.ResolveMaxTokens(r.Options.MaxTokens, r.MaxTokensPerField),
+	})
+	return messages, newMessages, err
+}
+
+// BuildPrompt returns the initial messages the ReAct loop would send for inputs,
+// without calling the LM
+func (r *ReAct) BuildPrompt(inputs map[string]any) ([]core.Message, error) {
+	if err := r.Signature.ValidateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
+	}
+	messages, _, err := r.assemblePrompt(context.Background(), inputs)
+	return messages, err
+}
+
+// EstimateUsage estimates the prompt tokens of the first ReAct iteration,
+// including tool schemas, without calling the LM
+func (r *ReAct) EstimateUsage(inputs map[string]any) (core.Usage, error) {
+	messages, err := r.BuildPrompt(inputs)
+	if err != nil {
+		return core.Usage{}, err
+	}
+
+	var tools []core.Tool
+	if r.LM.SupportsTools() {
+		tools = r.Tools
+	}
+	return estimatePromptUsage(r.LM.Name(), messages, tools), nil
+}
+
+// run executes the ReAct loop, reporting progress through emit
+func (r *ReAct) run(ctx context.Context, inputs map[string]any, emit func(ReActEvent)) (*core.Prediction, error) {
 	if err := r.Signature.ValidateInputs(inputs); err != nil {
 		return nil, fmt.Errorf("input validation failed: %w", err)
 	}
 
-	// Use adapter to format messages with demos
-	newMessages, err := r.Adapter.Format(r.Signature, inputs, r.Demos)
+	// Format messages with system prompt, demos and history, fitting the model's context window
+	messages, newMessages, err := r.assemblePrompt(ctx, inputs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to format messages: %w", err)
+		return nil, err
 	}
+	// Append corrective feedback from an enclosing Assert, if any
+	messages = core.ApplyFeedback(ctx, messages)
+
+	state := &reactState{
+		Version:     reactSnapshotVersion,
+		Inputs:      inputs,
+		Messages:    messages,
+		NewMessages: newMessages,
+		SeenCalls:   make(map[string]bool),
+		SubUsage:    make(map[string]core.Usage),
+	}
+	return r.execute(ctx, state, emit)
+}
 
-	// Build initial message list
-	var messages []core.Message
+// execute runs the loop from state under the module deadline, marking the
+// checkpointed run as done once it produces a prediction
+func (r *ReAct) execute(ctx context.Context, state *reactState, emit func(ReActEvent)) (*core.Prediction, error) {
+	// Without native tool calling the model could never act, so fail clearly instead of degrading
+	if r.hasActionTools() && !core.CapabilitiesOf(r.LM).SupportsTools {
+		return nil, fmt.Errorf("%w: %s cannot call ReAct tools", core.ErrToolsUnsupported, r.LM.Name())
+	}
 
-	// Add system prompt for ReAct pattern
-	systemPrompt := r.buildSystemPrompt()
-	if systemPrompt != "" {
-		messages = append(messages, core.Message{Role: "system", Content: systemPrompt})
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	prediction, err := r.loop(ctx, state, emit)
+	if err == nil {
+		state.Done = true
+		r.checkpoint(state)
 	}
+	return prediction, err
+}
 
-	// Prepend history if available
-	if r.History != nil && !r.History.IsEmpty() {
-		historyMessages := r.Adapter.FormatHistory(r.History)
-		messages = append(messages, historyMessages...)
+// loop runs Thought -> Action -> Observation iterations starting from state,
+// checkpointing after every LM response and tool observation
+func (r *ReAct) loop(ctx context.Context, state *reactState, emit func(ReActEvent)) (*core.Prediction, error) {
+	inputs := state.Inputs
+
+	// extract salvages an answer from the transcript so far, counting the extraction call
+	extract := func() (*core.Prediction, error) {
+		pred, err := r.runExtract(ctx, state.Messages, inputs)
+		if err != nil {
+			return nil, err
+		}
+		state.Usage.Add(pred.Usage)
+		state.SubUsage["extract"] = pred.Usage
+		return state.withUsage(pred), nil
 	}
 
-	// Add new messages from adapter
-	messages = append(messages, newMessages...)
+	// guardStop ends a run the progress guard gave up on with the best answer so far
+	guardStop := func() (*core.Prediction, error) {
+		if r.Verbose {
+			fmt.Println("\n⚠️  Progress guard stopped the run - running extraction")
+		}
+		pred, err := extract()
+		if err != nil {
+			return nil, err
+		}
+		if pred.Metadata == nil {
+			pred.Metadata = make(map[string]any)
+		}
+		pred.Metadata["stop_reason"] = "progress_guard"
+		return pred, nil
+	}
 
-	// Track observations for stagnation detection
-	var lastObservation string
-	var finalMode bool
+	// Forcing an answer adds a dedicated final iteration after the tool-using ones
+	maxIterations := r.MaxIterations
+	if r.ForceAnswerOnMaxIterations {
+		maxIterations++
+	}
+
+	// Each iteration gets its own deadline; the previous one is released when the next starts
+	cancelIteration := context.CancelFunc(func() {})
+	defer func() { cancelIteration() }()
 
 	// ReAct loop: Thought -> Action -> Observation
-	for i := 0; i < r.MaxIterations; i++ {
+	for ; state.Iteration < maxIterations; state.Iteration++ {
+		i := state.Iteration
+		r.checkpoint(state)
+
+		cancelIteration()
+		var iterCtx context.Context
+		iterCtx, cancelIteration = withTimeout(ctx, r.PerIterationTimeout)
+
 		if r.Verbose {
 			fmt.Printf("\n=== ReAct Iteration %d ===\n", i+1)
 		}
 
+		started := time.Now()
+
+		// A run resumed mid-iteration finishes the tool calls the model already made
+		if pending := state.pendingToolCalls(); len(pending) > 0 {
+			if prediction := r.runToolCalls(iterCtx, state, i, pending, emit); prediction != nil {
+				return prediction, nil
+			}
+			if r.guardStops(state, i, core.Usage{}, started) {
+				return guardStop()
+			}
+			continue
+		}
+
 		// Activate final mode on last iteration
-		if i == r.MaxIterations-1 {
-			finalMode = true
+		if i == maxIterations-1 {
+			state.FinalMode = true
 			if r.Verbose {
 				fmt.Println("⚠️  Final iteration - forcing final answer mode")
 			}
@@ -140,23 +352,25 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 
 		// Copy options to avoid mutation
 		options := r.Options.Copy()
+		options.MaxTokens = r.Signature.ResolveMaxTokens(options.MaxTokens, r.MaxTokensPerField)
+		core.ApplyOptionOverrides(ctx, options)
 
 		// In final mode, disable tools and inject instruction for final answer
-		if finalMode {
+		if state.FinalMode {
 			options.Tools = nil
 			options.ToolChoice = "none"
 
 			// Inject user message to prompt for final answer
 			finalPrompt := r.buildFinalAnswerPrompt()
-			messages = append(messages, core.Message{
+			state.Messages = append(state.Messages, core.Message{
 				Role:    "user",
 				Content: finalPrompt,
 			})
 
 			if r.LM.SupportsJSON() {
 				options.ResponseFormat = "json"
-				// Auto-generate JSON schema from signature for structured outputs
-				if options.ResponseSchema == nil {
+				// Auto-generate JSON schema from signature when the model supports structured outputs
+				if options.ResponseSchema == nil && core.CapabilitiesOf(r.LM).SupportsJSONSchema {
 					options.ResponseSchema = r.Signature.SignatureToJSONSchema()
 				}
 			}
@@ -170,19 +384,27 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 
 		// Enable JSON mode when tools are not used (for final answer)
 		if r.LM.SupportsJSON() && len(options.Tools) == 0 {
-			if _, isJSON := r.Adapter.(*core.JSONAdapter); isJSON {
+			if core.UsesJSONMode(r.Adapter) {
 				options.ResponseFormat = "json"
-				// Auto-generate JSON schema from signature for structured outputs
-				if options.ResponseSchema == nil {
+				// Auto-generate JSON schema from signature when the model supports structured outputs
+				if options.ResponseSchema == nil && core.CapabilitiesOf(r.LM).SupportsJSONSchema {
 					options.ResponseSchema = r.Signature.SignatureToJSONSchema()
 				}
 			}
 		}
 
-		result, err := r.LM.Generate(ctx, messages, options)
+		// A dry run stops at the first call: the loop cannot go on without the model's answer
+		if result, ok := core.DryRun(iterCtx, r.LM.Name(), state.Messages, options); ok {
+			return dryRunPrediction("ReAct", inputs, result), nil
+		}
+
+		result, err := r.LM.Generate(iterCtx, state.Messages, options)
+		recordCall(iterCtx, "ReAct", state.Messages, result, err)
 		if err != nil {
 			return nil, fmt.Errorf("LM generation failed at iteration %d: %w", i+1, err)
 		}
+		state.Usage.Add(result.Usage)
+		state.SubUsage[fmt.Sprintf("iteration_%d", i+1)] = result.Usage
 
 		// If no tool calls, this should be the final answer
 		if len(result.ToolCalls) == 0 {
@@ -198,15 +420,15 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 			outputs, err := r.Adapter.Parse(r.Signature, cleanedContent)
 			if err != nil {
 				// If in early iterations and parsing fails, guide model to use tools instead of accepting bad output
-				if !finalMode && i < r.MaxIterations-2 {
+				if !state.FinalMode && i < maxIterations-2 {
 					if r.Verbose {
 						fmt.Println("⚠️  Parsing failed and tools available - requesting tool use")
 					}
-					messages = append(messages, core.Message{
+					state.Messages = append(state.Messages, core.Message{
 						Role:    "assistant",
 						Content: result.Content,
 					})
-					messages = append(messages, core.Message{
+					state.Messages = append(state.Messages, core.Message{
 						Role:    "user",
 						Content: "Please use the available tools to gather the information needed, then provide a complete answer in the requested format. Do not include any meta-commentary or explanations - just the answer.",
 					})
@@ -214,16 +436,16 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 				}
 
 				// If in final mode and parsing fails, run extraction (P1)
-				if finalMode {
+				if state.FinalMode {
 					if r.Verbose {
 						fmt.Println("⚠️  Final answer parsing failed - running extraction")
 					}
-					return r.runExtract(ctx, messages, inputs)
+					return extract()
 				}
 
 				// FALLBACK: If structured parsing fails, attempt text extraction for string fields
 				// This makes ReAct resilient to less capable models that don't follow structured formats
-				extractedOutputs := r.extractTextOutputs(cleanedContent, messages)
+				extractedOutputs := r.extractTextOutputs(cleanedContent, state.Messages)
 				if len(extractedOutputs) > 0 {
 					if r.Verbose {
 						fmt.Println("⚠️  Structured parsing failed - falling back to raw text extraction")
@@ -234,7 +456,7 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 					if r.Verbose {
 						fmt.Println("⚠️  All parsing failed - running extraction")
 					}
-					return r.runExtract(ctx, messages, inputs)
+					return extract()
 				}
 			}
 
@@ -250,7 +472,7 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 				if r.Verbose {
 					fmt.Printf("⚠️  Output validation failed: %v - running extraction\n", err)
 				}
-				return r.runExtract(ctx, messages, inputs)
+				return extract()
 			}
 
 			// Extract adapter metadata
@@ -269,7 +491,7 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 			// Update history if present
 			if r.History != nil {
 				// Add only the new user message(s) (not from history)
-				for _, msg := range newMessages {
+				for _, msg := range state.NewMessages {
 					if msg.Role == "user" {
 						r.History.Add(msg)
 					}
@@ -282,10 +504,11 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 				})
 			}
 
+			emit(ReActEvent{Type: ReActEventFinalAnswerChunk, Iteration: i + 1, Content: core.StripMarkers(result.Content)})
+
 			// Build Prediction object
-			prediction := core.NewPrediction(outputs).
+			prediction := state.withUsage(core.NewPrediction(outputs)).
 				WithRationale(rationale).
-				WithUsage(result.Usage).
 				WithModuleName("ReAct").
 				WithInputs(inputs)
 
@@ -298,7 +521,7 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 		}
 
 		// Add assistant's response with tool calls
-		messages = append(messages, core.Message{
+		state.Messages = append(state.Messages, core.Message{
 			Role:      "assistant",
 			Content:   result.Content,
 			ToolCalls: result.ToolCalls,
@@ -307,59 +530,69 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 		if r.Verbose {
 			fmt.Printf("Thought: %s\n", core.StripMarkers(result.Content))
 		}
+		if result.Content != "" {
+			emit(ReActEvent{Type: ReActEventThoughtChunk, Iteration: i + 1, Content: core.StripMarkers(result.Content)})
+		}
 
-		// Execute tool calls and add observations
-		var currentObservation string
-		for _, toolCall := range result.ToolCalls {
-			if r.Verbose {
-				fmt.Printf("Action: %s(%v)\n", toolCall.Name, toolCall.Arguments)
-			}
+		// Record the tool calls before executing them, so a resumed run never repeats the LM call
+		r.checkpoint(state)
 
-			// Check if this is a "finish" tool call - treat as final answer
-			if strings.ToLower(toolCall.Name) == "finish" {
-				if r.Verbose {
-					fmt.Println("Finish tool called - extracting final answer")
-				}
+		if prediction := r.runToolCalls(iterCtx, state, i, result.ToolCalls, emit); prediction != nil {
+			return prediction, nil
+		}
+		if r.guardStops(state, i, result.Usage, started) {
+			return guardStop()
+		}
+	}
 
-				// Extract outputs from finish tool arguments
-				outputs := make(map[string]any)
-				for k, v := range toolCall.Arguments {
-					outputs[k] = v
-				}
+	// Max iterations exceeded - run extraction to salvage an answer (P1)
+	if r.Verbose {
+		fmt.Printf("\n⚠️  Exceeded maximum iterations (%d) - running extraction\n", maxIterations)
+	}
+	return extract()
+}
 
-				// Validate outputs match signature
-				if err := r.Signature.ValidateOutputs(outputs); err != nil {
-					// If finish tool args don't match signature, continue and let model try again
-					observation := fmt.Sprintf("Error: finish tool arguments don't match required outputs: %v", err)
-					messages = append(messages, core.Message{
-						Role:    "tool",
-						Content: observation,
-						ToolID:  toolCall.ID,
-					})
-					if r.Verbose {
-						fmt.Printf("Observation: %s\n", observation)
-					}
-					currentObservation = observation
-					continue
-				}
+// guardStops records the trace of tool-using iteration i and reports whether the
+// progress guard wants to stop the run
+func (r *ReAct) guardStops(state *reactState, i int, usage core.Usage, started time.Time) bool {
+	state.recordTrace(i, usage, started)
+	if r.ProgressGuard == nil || state.FinalMode {
+		return false
+	}
+	return r.ProgressGuard(append([]IterationTrace(nil), state.Traces...))
+}
 
-				// Build prediction and return
-				prediction := core.NewPrediction(outputs).
-					WithUsage(result.Usage).
-					WithModuleName("ReAct").
-					WithInputs(inputs)
+// runToolCalls executes the tool calls of iteration i and records their observations,
+// returning a prediction when the model called the finish tool with valid outputs
+func (r *ReAct) runToolCalls(iterCtx context.Context, state *reactState, i int, toolCalls []core.ToolCall, emit func(ReActEvent)) *core.Prediction {
+	// Tools reach the agent's LM and options through their context (core.LMFromContext)
+	toolOptions := r.Options.Copy()
+	core.ApplyOptionOverrides(iterCtx, toolOptions)
 
-				return prediction, nil
+	// Execute tool calls and add observations
+	var currentObservation string
+	for _, toolCall := range toolCalls {
+		if r.Verbose {
+			fmt.Printf("Action: %s(%v)\n", toolCall.Name, toolCall.Arguments)
+		}
+
+		// Check if this is a "finish" tool call - treat as final answer
+		if strings.ToLower(toolCall.Name) == "finish" {
+			if r.Verbose {
+				fmt.Println("Finish tool called - extracting final answer")
 			}
 
-			tool := r.findTool(toolCall.Name)
-			if tool == nil {
-				observation := fmt.Sprintf("Error: Tool '%s' not found", toolCall.Name)
-				messages = append(messages, core.Message{
-					Role:    "tool",
-					Content: observation,
-					ToolID:  toolCall.ID,
-				})
+			// Extract outputs from finish tool arguments
+			outputs := make(map[string]any)
+			for k, v := range toolCall.Arguments {
+				outputs[k] = v
+			}
+
+			// Validate outputs match signature
+			if err := r.Signature.ValidateOutputs(outputs); err != nil {
+				// If finish tool args don't match signature, continue and let model try again
+				observation := fmt.Sprintf("Error: finish tool arguments don't match required outputs: %v", err)
+				r.addObservation(state, toolCall.ID, observation)
 				if r.Verbose {
 					fmt.Printf("Observation: %s\n", observation)
 				}
@@ -367,57 +600,169 @@ func (r *ReAct) Forward(ctx context.Context, inputs map[string]any) (*core.Predi
 				continue
 			}
 
-			result, err := tool.Execute(ctx, toolCall.Arguments)
-			if err != nil {
-				observation := fmt.Sprintf("Error executing tool: %v", err)
-				messages = append(messages, core.Message{
-					Role:    "tool",
-					Content: observation,
-					ToolID:  toolCall.ID,
+			if answer, err := json.Marshal(outputs); err == nil {
+				emit(ReActEvent{Type: ReActEventFinalAnswerChunk, Iteration: i + 1, Content: string(answer)})
+			}
+
+			// Build prediction and return
+			prediction := state.withUsage(core.NewPrediction(outputs)).
+				WithModuleName("ReAct").
+				WithInputs(state.Inputs)
+
+			return prediction
+		}
+
+		emit(ReActEvent{
+			Type:       ReActEventToolCallStarted,
+			Iteration:  i + 1,
+			ToolName:   toolCall.Name,
+			ToolCallID: toolCall.ID,
+			ToolArgs:   toolCall.Arguments,
+		})
+
+		if r.LoopDetection {
+			key := toolCallKey(toolCall)
+			if state.SeenCalls[key] {
+				observation := fmt.Sprintf("You already called %s with these arguments; try a different approach or give your final answer.", toolCall.Name)
+				emit(ReActEvent{
+					Type:       ReActEventToolResult,
+					Iteration:  i + 1,
+					ToolName:   toolCall.Name,
+					ToolCallID: toolCall.ID,
+					Output:     observation,
 				})
+				r.addObservation(state, toolCall.ID, observation)
 				if r.Verbose {
-					fmt.Printf("Observation: %s\n", observation)
+					fmt.Printf("⚠️  Loop detected - skipping repeated call to %s\n", toolCall.Name)
 				}
 				currentObservation = observation
 				continue
 			}
+			state.SeenCalls[key] = true
+		}
 
-			observation := fmt.Sprintf("%v", result)
-			messages = append(messages, core.Message{
-				Role:    "tool",
-				Content: observation,
-				ToolID:  toolCall.ID,
+		tool := r.findTool(toolCall.Name)
+		if tool == nil {
+			observation := fmt.Sprintf("Error: Tool '%s' not found", toolCall.Name)
+			emit(ReActEvent{
+				Type:       ReActEventToolResult,
+				Iteration:  i + 1,
+				ToolName:   toolCall.Name,
+				ToolCallID: toolCall.ID,
+				Output:     observation,
+				Err:        fmt.Errorf("tool '%s' not found", toolCall.Name),
 			})
+			r.addObservation(state, toolCall.ID, observation)
 			if r.Verbose {
 				fmt.Printf("Observation: %s\n", observation)
 			}
 			currentObservation = observation
+			continue
 		}
 
-		// Detect stagnation: if same observation appears twice in a row, force final answer
-		if currentObservation != "" && currentObservation == lastObservation {
+		start := time.Now()
+		toolCtx, toolUsage := core.WithToolLM(iterCtx, r.LM, toolOptions)
+		result, err := tool.Execute(toolCtx, toolCall.Arguments)
+		state.addToolUsage(toolCall.Name, toolUsage.Total())
+		if err != nil {
+			observation := fmt.Sprintf("Error executing tool: %v", err)
+			core.CollectToolCall(iterCtx, logging.GetRequestID(iterCtx), toolCall, observation, err, start)
+			emit(ReActEvent{
+				Type:       ReActEventToolResult,
+				Iteration:  i + 1,
+				ToolName:   toolCall.Name,
+				ToolCallID: toolCall.ID,
+				Output:     observation,
+				Err:        err,
+			})
+			r.addObservation(state, toolCall.ID, observation)
 			if r.Verbose {
-				fmt.Println("\n⚠️  Stagnation detected - activating final mode")
+				fmt.Printf("Observation: %s\n", observation)
+			}
+			currentObservation = observation
+			continue
+		}
+
+		observation := core.FormatToolResult(result)
+		core.CollectToolCall(iterCtx, logging.GetRequestID(iterCtx), toolCall, observation, nil, start)
+		emit(ReActEvent{
+			Type:       ReActEventToolResult,
+			Iteration:  i + 1,
+			ToolName:   toolCall.Name,
+			ToolCallID: toolCall.ID,
+			Output:     observation,
+		})
+		r.addObservation(state, toolCall.ID, observation)
+		if r.Verbose {
+			fmt.Printf("Observation: %s\n", observation)
+		}
+		currentObservation = observation
+
+		if r.DirectAnswerTool != "" && toolCall.Name == r.DirectAnswerTool {
+			if prediction := r.directAnswer(state, i, toolCall.Name, result, emit); prediction != nil {
+				return prediction
 			}
-			finalMode = true
-			messages = append(messages, core.Message{
-				Role:    "user",
-				Content: "You've received the same observation twice. Please provide your final answer now as a JSON object with all required fields. Do not call any more tools.",
-			})
 		}
-		lastObservation = currentObservation
 	}
 
-	// Max iterations exceeded - run extraction to salvage an answer (P1)
+	// Detect stagnation: if same observation appears twice in a row, force final answer
+	if currentObservation != "" && currentObservation == state.LastObservation {
+		if r.Verbose {
+			fmt.Println("\n⚠️  Stagnation detected - activating final mode")
+		}
+		state.FinalMode = true
+		state.Messages = append(state.Messages, core.Message{
+			Role:    "user",
+			Content: "You've received the same observation twice. Please provide your final answer now as a JSON object with all required fields. Do not call any more tools.",
+		})
+	}
+	state.LastObservation = currentObservation
+	return nil
+}
+
+// directAnswer turns the result of the direct answer tool into the final prediction,
+// or returns nil when it does not map to valid outputs
+func (r *ReAct) directAnswer(state *reactState, i int, toolName string, result any, emit func(ReActEvent)) *core.Prediction {
+	outputs := r.toolResultOutputs(result)
+	if outputs != nil {
+		outputs = core.NormalizeOutputKeys(r.Signature, coerceBasicTypes(r.Signature, outputs))
+	}
+	if outputs == nil || r.Signature.ValidateOutputs(outputs) != nil {
+		if r.Verbose {
+			fmt.Printf("⚠️  %s result does not match the outputs - continuing\n", toolName)
+		}
+		return nil
+	}
 	if r.Verbose {
-		fmt.Printf("\n⚠️  Exceeded maximum iterations (%d) - running extraction\n", r.MaxIterations)
+		fmt.Printf("%s returned the final answer\n", toolName)
 	}
-	return r.runExtract(ctx, messages, inputs)
+
+	if answer, err := json.Marshal(outputs); err == nil {
+		emit(ReActEvent{Type: ReActEventFinalAnswerChunk, Iteration: i + 1, Content: string(answer)})
+	}
+
+	return state.withUsage(core.NewPrediction(outputs)).
+		WithModuleName("ReAct").
+		WithInputs(state.Inputs).
+		WithMetadata("direct_tool_answer", toolName)
+}
+
+// toolResultOutputs maps a tool result to output fields: maps and JSON objects by key,
+// anything else to the only output field. It returns nil when neither applies.
+func (r *ReAct) toolResultOutputs(result any) map[string]any {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(core.FormatToolResult(result)), &object); err == nil && object != nil {
+		return object
+	}
+	if len(r.Signature.OutputFields) != 1 || result == nil {
+		return nil
+	}
+	return map[string]any{r.Signature.OutputFields[0].Name: result}
 }
 
 func (r *ReAct) buildSystemPrompt() string {
 	// Don't build system prompt if only the finish tool exists (no real tools)
-	if len(r.Tools) == 0 || (len(r.Tools) == 1 && r.Tools[0].Name == "finish") {
+	if !r.hasActionTools() {
 		return ""
 	}
 
@@ -468,6 +813,21 @@ func (r *ReAct) buildFinalAnswerPrompt() string {
 	return prompt.String()
 }
 
+// toolCallKey identifies a tool call by name and arguments for loop detection
+// json.Marshal sorts map keys, so equal arguments produce equal keys
+func toolCallKey(toolCall core.ToolCall) string {
+	args, err := json.Marshal(toolCall.Arguments)
+	if err != nil {
+		args = []byte(fmt.Sprintf("%v", toolCall.Arguments))
+	}
+	return toolCall.Name + ":" + string(args)
+}
+
+// hasActionTools reports whether any tool besides finish is configured
+func (r *ReAct) hasActionTools() bool {
+	return len(r.Tools) > 1 || (len(r.Tools) == 1 && r.Tools[0].Name != "finish")
+}
+
 func (r *ReAct) findTool(name string) *core.Tool {
 	for i := range r.Tools {
 		if r.Tools[i].Name == name {
@@ -726,18 +1086,22 @@ func (r *ReAct) runExtract(ctx context.Context, messages []core.Message, inputs
 
 	// Copy options and force JSON mode
 	options := r.Options.Copy()
+	options.MaxTokens = r.Signature.ResolveMaxTokens(options.MaxTokens, r.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
 	options.Tools = nil
 	options.ToolChoice = "none"
 
 	if r.LM.SupportsJSON() {
 		options.ResponseFormat = "json"
-		if options.ResponseSchema == nil {
+		if options.ResponseSchema == nil && core.CapabilitiesOf(r.LM).SupportsJSONSchema {
 			options.ResponseSchema = r.Signature.SignatureToJSONSchema()
 		}
 	}
+	core.ConstrainEnums(r.Adapter, r.LM, r.Signature, options)
 
 	// Generate extraction
 	result, err := r.LM.Generate(ctx, extractMessages, options)
+	recordCall(ctx, "ReAct", extractMessages, result, err)
 	if err != nil {
 		return nil, fmt.Errorf("extraction generation failed: %w", err)
 	}
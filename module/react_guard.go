@@ -0,0 +1,68 @@
+package module
+
+import (
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// IterationTrace records one tool-using ReAct iteration, for a ProgressGuard
+type IterationTrace struct {
+	Iteration    int             `json:"iteration"` // 1-based
+	Thought      string          `json:"thought"`   // The model's text alongside its tool calls
+	ToolCalls    []core.ToolCall `json:"tool_calls"`
+	Observations []string        `json:"observations"` // Tool results fed back to the model, in call order
+	Usage        core.Usage      `json:"usage"`        // Usage of the iteration's LM call
+	Duration     time.Duration   `json:"duration"`     // LM call plus tool execution
+}
+
+// ProgressGuard inspects the iterations of a ReAct run after each one and reports
+// whether to stop early; ReAct then extracts the best answer from the transcript so far
+type ProgressGuard func(iterations []IterationTrace) bool
+
+// NoNewInformationGuard stops a run once its last k iterations observed nothing new:
+// every observation repeats one seen earlier in the run. A k below 1 is treated as 1.
+func NoNewInformationGuard(k int) ProgressGuard {
+	if k < 1 {
+		k = 1
+	}
+	return func(iterations []IterationTrace) bool {
+		if len(iterations) <= k {
+			return false
+		}
+		seen := make(map[string]bool)
+		for _, trace := range iterations[:len(iterations)-k] {
+			for _, obs := range trace.Observations {
+				seen[obs] = true
+			}
+		}
+		for _, trace := range iterations[len(iterations)-k:] {
+			for _, obs := range trace.Observations {
+				if !seen[obs] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// recordTrace appends the trace of iteration i, whose LM response is the last
+// assistant message of state followed by its tool observations
+func (s *reactState) recordTrace(i int, usage core.Usage, started time.Time) {
+	trace := IterationTrace{Iteration: i + 1, Usage: usage, Duration: time.Since(started)}
+	for j := len(s.Messages) - 1; j >= 0; j-- {
+		msg := s.Messages[j]
+		if msg.Role == "assistant" {
+			trace.Thought = msg.Content
+			trace.ToolCalls = msg.ToolCalls
+			for _, obs := range s.Messages[j+1:] {
+				if obs.Role == "tool" {
+					trace.Observations = append(trace.Observations, obs.Content)
+				}
+			}
+			break
+		}
+	}
+	s.Traces = append(s.Traces, trace)
+}
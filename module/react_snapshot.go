@@ -0,0 +1,132 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// reactSnapshotVersion is bumped when the snapshot format changes incompatibly
+const reactSnapshotVersion = 1
+
+// reactState is the resumable state of a ReAct run: the scratchpad of thoughts,
+// tool calls and observations, plus the loop bookkeeping needed to continue it
+type reactState struct {
+	Version         int                   `json:"version"`
+	Inputs          map[string]any        `json:"inputs"`
+	Messages        []core.Message        `json:"messages"`
+	NewMessages     []core.Message        `json:"new_messages"` // Messages to add to History on completion
+	Iteration       int                   `json:"iteration"`    // 0-based iteration in progress
+	FinalMode       bool                  `json:"final_mode"`
+	LastObservation string                `json:"last_observation"`
+	SeenCalls       map[string]bool       `json:"seen_calls"`
+	Usage           core.Usage            `json:"usage"`
+	SubUsage        map[string]core.Usage `json:"sub_usage"`
+	Traces          []IterationTrace      `json:"traces,omitempty"` // Completed tool-using iterations
+	Done            bool                  `json:"done"`
+}
+
+// withUsage attaches the usage accumulated so far to a prediction
+func (s *reactState) withUsage(pred *core.Prediction) *core.Prediction {
+	pred.Usage = s.Usage
+	pred.SubUsage = s.SubUsage
+	return pred
+}
+
+// addToolUsage counts the LM calls a tool made through core.LMFromContext
+// toward the run, per tool in SubUsage["tool_<name>"]
+func (s *reactState) addToolUsage(name string, usage core.Usage) {
+	if usage == (core.Usage{}) {
+		return
+	}
+	s.Usage.Add(usage)
+	key := "tool_" + name
+	toolUsage := s.SubUsage[key]
+	toolUsage.Add(usage)
+	s.SubUsage[key] = toolUsage
+}
+
+// pendingToolCalls returns the tool calls of the last assistant message that
+// have no observation yet, which happens when a run stopped mid-iteration
+// Observations are appended in call order, so the answered calls are a prefix.
+func (s *reactState) pendingToolCalls() []core.ToolCall {
+	answered := 0
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		msg := s.Messages[i]
+		switch {
+		case msg.Role == "tool":
+			answered++
+		case msg.Role == "assistant" && len(msg.ToolCalls) > answered:
+			return msg.ToolCalls[answered:]
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// addObservation records a tool result and checkpoints it, so a resumed run
+// does not execute the tool again
+func (r *ReAct) addObservation(state *reactState, toolID, observation string) {
+	state.Messages = append(state.Messages, core.Message{
+		Role:    "tool",
+		Content: observation,
+		ToolID:  toolID,
+	})
+	r.checkpoint(state)
+}
+
+// checkpoint stores a serialized copy of state for Snapshot
+func (r *ReAct) checkpoint(state *reactState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		// Unserializable tool arguments or inputs; keep the previous checkpoint
+		return
+	}
+	r.snapshotMu.Lock()
+	r.snapshot = data
+	r.snapshotMu.Unlock()
+}
+
+// Snapshot returns the serialized state of the most recent run: its inputs,
+// thoughts, tool calls and observations, iteration count and usage so far.
+// It is safe to call while Forward is running, e.g. from another goroutine or a
+// tool, and captures every completed LM call and tool execution. If the module
+// runs concurrently, the snapshot reflects whichever run checkpointed last.
+func (r *ReAct) Snapshot() ([]byte, error) {
+	r.snapshotMu.Lock()
+	defer r.snapshotMu.Unlock()
+	if r.snapshot == nil {
+		return nil, fmt.Errorf("no ReAct run to snapshot")
+	}
+	return append([]byte(nil), r.snapshot...), nil
+}
+
+// Resume continues a run from a Snapshot, e.g. after a crash or in a new process.
+// Recorded tool results are reused rather than executed again; tool calls the
+// model made but that had not finished are executed before the next LM call.
+// Inputs are restored from JSON, so numbers come back as float64.
+func (r *ReAct) Resume(ctx context.Context, snapshot []byte) (*core.Prediction, error) {
+	var state reactState
+	if err := json.Unmarshal(snapshot, &state); err != nil {
+		return nil, fmt.Errorf("invalid ReAct snapshot: %w", err)
+	}
+	if state.Version != reactSnapshotVersion {
+		return nil, fmt.Errorf("unsupported ReAct snapshot version %d (want %d)", state.Version, reactSnapshotVersion)
+	}
+	if state.Done {
+		return nil, fmt.Errorf("ReAct snapshot is of a completed run")
+	}
+	if state.SeenCalls == nil {
+		state.SeenCalls = make(map[string]bool)
+	}
+	if state.SubUsage == nil {
+		state.SubUsage = make(map[string]core.Usage)
+	}
+
+	return withErrorTrace(ctx, "ReAct", state.Inputs, func(ctx context.Context, _ map[string]any) (*core.Prediction, error) {
+		return r.execute(ctx, &state, func(ReActEvent) {})
+	})
+}
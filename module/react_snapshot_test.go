@@ -0,0 +1,185 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func newSnapshotSignature() *core.Signature {
+	return core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+}
+
+func TestReAct_SnapshotResume_AfterLMFailure(t *testing.T) {
+	charges := 0
+	charge := core.NewTool("charge", "Charge the card", func(ctx context.Context, args map[string]any) (any, error) {
+		charges++
+		return "charged", nil
+	})
+
+	calls := 0
+	failing := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			calls++
+			if calls == 1 {
+				return &core.GenerateResult{
+					Content:   "Charging",
+					ToolCalls: []core.ToolCall{{ID: "1", Name: "charge", Arguments: map[string]any{"amount": 5}}},
+					Usage:     core.Usage{TotalTokens: 10},
+				}, nil
+			}
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	react := NewReAct(newSnapshotSignature(), failing, []core.Tool{*charge})
+	if _, err := react.Forward(context.Background(), map[string]any{"question": "pay"}); err == nil {
+		t.Fatal("expected Forward to fail")
+	}
+	snapshot, err := react.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	var sawObservation bool
+	recovered := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			for _, msg := range messages {
+				if msg.Role == "tool" && msg.Content == "charged" {
+					sawObservation = true
+				}
+			}
+			return &core.GenerateResult{Content: `{"answer": "paid"}`, Usage: core.Usage{TotalTokens: 7}}, nil
+		},
+	}
+
+	// A fresh module stands in for a restarted process
+	resumed := NewReAct(newSnapshotSignature(), recovered, []core.Tool{*charge})
+	pred, err := resumed.Resume(context.Background(), snapshot)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	if pred.Outputs["answer"] != "paid" {
+		t.Errorf("answer = %v, want paid", pred.Outputs["answer"])
+	}
+	if charges != 1 {
+		t.Errorf("charge tool executed %d times, want 1", charges)
+	}
+	if !sawObservation {
+		t.Error("expected the recorded observation to be sent to the LM")
+	}
+	if pred.Usage.TotalTokens != 17 {
+		t.Errorf("TotalTokens = %d, want 17 (usage before and after resuming)", pred.Usage.TotalTokens)
+	}
+	if _, ok := pred.SubUsage["iteration_2"]; !ok {
+		t.Errorf("expected the resumed call to be counted as iteration 2, got %v", pred.SubUsage)
+	}
+}
+
+func TestReAct_SnapshotResume_MidIteration(t *testing.T) {
+	var snapshot []byte
+	var react *ReAct
+	executed := map[string]int{}
+
+	first := core.NewTool("first", "First step", func(ctx context.Context, args map[string]any) (any, error) {
+		executed["first"]++
+		return "first done", nil
+	})
+	second := core.NewTool("second", "Second step", func(ctx context.Context, args map[string]any) (any, error) {
+		executed["second"]++
+		if snapshot == nil {
+			// Simulate a crash while the second tool runs
+			var err error
+			if snapshot, err = react.Snapshot(); err != nil {
+				t.Fatalf("Snapshot() error = %v", err)
+			}
+			return nil, errors.New("crashed")
+		}
+		return "second done", nil
+	})
+
+	calls := 0
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			calls++
+			if calls == 1 {
+				return &core.GenerateResult{
+					ToolCalls: []core.ToolCall{
+						{ID: "a", Name: "first", Arguments: map[string]any{}},
+						{ID: "b", Name: "second", Arguments: map[string]any{}},
+					},
+				}, nil
+			}
+			last := messages[len(messages)-1]
+			if last.Role != "tool" || last.ToolID != "b" {
+				t.Errorf("expected the second tool's observation last, got %+v", last)
+			}
+			return &core.GenerateResult{Content: `{"answer": "done"}`}, nil
+		},
+	}
+
+	react = NewReAct(newSnapshotSignature(), lm, []core.Tool{*first, *second})
+	if _, err := react.Forward(context.Background(), map[string]any{"question": "go"}); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	calls = 1
+	pred, err := react.Resume(context.Background(), snapshot)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if pred.Outputs["answer"] != "done" {
+		t.Errorf("answer = %v, want done", pred.Outputs["answer"])
+	}
+	if executed["first"] != 1 || executed["second"] != 2 {
+		t.Errorf("executions = %v, want first once and only second re-run", executed)
+	}
+}
+
+func TestReAct_Snapshot_Errors(t *testing.T) {
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{Content: `{"answer": "ok"}`}, nil
+		},
+	}
+	react := NewReAct(newSnapshotSignature(), lm, nil)
+
+	if _, err := react.Snapshot(); err == nil {
+		t.Error("expected error before any run")
+	}
+
+	if _, err := react.Forward(context.Background(), map[string]any{"question": "q"}); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	snapshot, err := react.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		snapshot []byte
+		wantErr  string
+	}{
+		{"completed run", snapshot, "completed"},
+		{"malformed", []byte("{"), "invalid"},
+		{"unknown version", []byte(`{"version": 99}`), "version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := react.Resume(context.Background(), tt.snapshot)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resume() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
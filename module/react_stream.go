@@ -0,0 +1,94 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
+)
+
+// ReActEventType identifies the kind of event emitted by ReAct.Stream
+type ReActEventType string
+
+const (
+	// ReActEventThoughtChunk carries the model's reasoning for an iteration
+	ReActEventThoughtChunk ReActEventType = "thought_chunk"
+	// ReActEventToolCallStarted is emitted before a tool is executed
+	ReActEventToolCallStarted ReActEventType = "tool_call_started"
+	// ReActEventToolResult carries a tool's output, or its error
+	ReActEventToolResult ReActEventType = "tool_result"
+	// ReActEventFinalAnswerChunk carries the final answer content
+	ReActEventFinalAnswerChunk ReActEventType = "final_answer_chunk"
+)
+
+// ReActEvent is a structured progress event from a streaming ReAct run
+type ReActEvent struct {
+	Type       ReActEventType
+	Iteration  int            // 1-based ReAct iteration
+	Content    string         // Thought or final answer text
+	ToolName   string         // Tool events only
+	ToolCallID string         // Tool events only
+	ToolArgs   map[string]any // ToolCallStarted only
+	Output     string         // ToolResult only: observation fed back to the model
+	Err        error          // ToolResult only: tool execution error, if any
+}
+
+// ReActStreamResult represents the result of a streaming ReAct run
+type ReActStreamResult struct {
+	Events     <-chan ReActEvent       // Channel for receiving progress events; read it until closed
+	Prediction <-chan *core.Prediction // Channel for receiving final prediction (sent after the loop completes)
+	Errors     <-chan error            // Channel for receiving fatal errors
+}
+
+// Stream executes the ReAct loop and reports progress as structured events
+// Thoughts are emitted per iteration rather than per token, since tool calls
+// require complete responses. Tool execution errors are delivered as
+// ToolResult events and fed back to the model; only fatal errors (e.g. LM
+// failures) are sent on the errors channel. All channels are closed when done.
+// Events is unbuffered and the run waits for each event to be read, so read Events
+// until it is closed before waiting on Prediction or Errors; a caller that stops
+// reading early must cancel ctx to end the run.
+func (r *ReAct) Stream(ctx context.Context, inputs map[string]any) (*ReActStreamResult, error) {
+	// Ensure context has a request ID
+	ctx = logging.EnsureRequestID(ctx)
+
+	if err := r.Signature.ValidateInputs(inputs); err != nil {
+		return nil, fmt.Errorf("input validation failed: %w", err)
+	}
+
+	events := make(chan ReActEvent)
+	predictionChan := make(chan *core.Prediction, 1)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(predictionChan)
+		defer close(errorChan)
+
+		startTime := time.Now()
+		logging.LogPredictionStart(ctx, "ReAct.Stream", r.Signature.Description)
+
+		emit := func(event ReActEvent) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		}
+
+		prediction, err := r.run(ctx, inputs, emit)
+		logging.LogPredictionEnd(ctx, "ReAct.Stream", time.Since(startTime), err)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		predictionChan <- prediction
+	}()
+
+	return &ReActStreamResult{
+		Events:     events,
+		Prediction: predictionChan,
+		Errors:     errorChan,
+	}, nil
+}
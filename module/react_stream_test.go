@@ -0,0 +1,179 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// collectReActStream drains all channels of a ReAct stream
+func collectReActStream(t *testing.T, result *ReActStreamResult) ([]ReActEvent, *core.Prediction, error) {
+	t.Helper()
+	var events []ReActEvent
+	for event := range result.Events {
+		events = append(events, event)
+	}
+	return events, <-result.Prediction, <-result.Errors
+}
+
+func TestReAct_Stream_Events(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	callCount := 0
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			callCount++
+			if callCount == 1 {
+				return &core.GenerateResult{
+					Content: "Let me search",
+					ToolCalls: []core.ToolCall{
+						{ID: "1", Name: "search", Arguments: map[string]any{"query": "test"}},
+						{ID: "2", Name: "failing_tool", Arguments: map[string]any{}},
+					},
+				}, nil
+			}
+			return &core.GenerateResult{
+				Content: `{"answer": "final answer"}`,
+			}, nil
+		},
+	}
+
+	searchTool := core.NewTool("search", "Search for info", func(ctx context.Context, args map[string]any) (any, error) {
+		return "search result", nil
+	})
+	failingTool := core.NewTool("failing_tool", "Fails", func(ctx context.Context, args map[string]any) (any, error) {
+		return nil, errors.New("tool failed")
+	})
+
+	react := NewReAct(sig, lm, []core.Tool{*searchTool, *failingTool})
+	result, err := react.Stream(context.Background(), map[string]any{"question": "test"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	events, prediction, streamErr := collectReActStream(t, result)
+	if streamErr != nil {
+		t.Fatalf("unexpected stream error: %v", streamErr)
+	}
+	if prediction == nil || prediction.Outputs["answer"] != "final answer" {
+		t.Fatalf("expected final prediction, got %+v", prediction)
+	}
+
+	wantTypes := []ReActEventType{
+		ReActEventThoughtChunk,
+		ReActEventToolCallStarted,
+		ReActEventToolResult,
+		ReActEventToolCallStarted,
+		ReActEventToolResult,
+		ReActEventFinalAnswerChunk,
+	}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %d: %+v", len(wantTypes), len(events), events)
+	}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %d type = %s, want %s", i, events[i].Type, want)
+		}
+	}
+
+	if events[1].ToolName != "search" || events[1].ToolArgs["query"] != "test" {
+		t.Errorf("unexpected tool call event: %+v", events[1])
+	}
+	if events[2].Output != "search result" || events[2].Err != nil {
+		t.Errorf("unexpected tool result event: %+v", events[2])
+	}
+	if events[4].Err == nil || !strings.Contains(events[4].Output, "tool failed") {
+		t.Errorf("expected tool error delivered as event, got %+v", events[4])
+	}
+	if events[5].Iteration != 2 {
+		t.Errorf("final answer iteration = %d, want 2", events[5].Iteration)
+	}
+}
+
+func TestReAct_Stream_LMError(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return nil, errors.New("LM failed")
+		},
+	}
+
+	react := NewReAct(sig, lm, nil)
+	result, err := react.Stream(context.Background(), map[string]any{"question": "test"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	_, prediction, streamErr := collectReActStream(t, result)
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "LM failed") {
+		t.Errorf("expected LM error on errors channel, got %v", streamErr)
+	}
+	if prediction != nil {
+		t.Error("expected no prediction on error")
+	}
+}
+
+func TestReAct_Stream_CancelWithoutReadingEvents(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return &core.GenerateResult{
+				Content:   "Let me search",
+				ToolCalls: []core.ToolCall{{ID: "1", Name: "search", Arguments: map[string]any{}}},
+			}, nil
+		},
+	}
+	searchTool := core.NewTool("search", "Search for info", func(ctx context.Context, args map[string]any) (any, error) {
+		return "search result", nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := NewReAct(sig, lm, []core.Tool{*searchTool}).Stream(ctx, map[string]any{"question": "test"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	// The run is blocked on the first unread event; cancelling ctx must end it
+	cancel()
+	select {
+	case streamErr := <-result.Errors:
+		if !errors.Is(streamErr, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", streamErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("run did not end after cancel while Events was unread")
+	}
+	for range result.Events {
+	}
+	if prediction := <-result.Prediction; prediction != nil {
+		t.Errorf("expected no prediction after cancel, got %+v", prediction)
+	}
+}
+
+func TestReAct_Stream_InvalidInput(t *testing.T) {
+	sig := core.NewSignature("Test").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	react := NewReAct(sig, &MockLM{}, nil)
+	if _, err := react.Stream(context.Background(), map[string]any{}); err == nil {
+		t.Error("expected input validation error")
+	}
+}
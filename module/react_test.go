@@ -2,12 +2,16 @@ package module
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
 )
 
 func TestReAct_Forward_NoTools(t *testing.T) {
@@ -80,6 +84,30 @@ func TestReAct_Forward_WithToolCalls(t *testing.T) {
 	}
 }
 
+func TestNewReAct_ToolRegistrySubset(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	registry := core.NewToolRegistry().Register(
+		core.NewTool("search", "Search the web", nil),
+		core.NewTool("calculator", "Evaluate arithmetic", nil),
+		core.NewTool("date", "Current date", nil),
+	)
+
+	react := NewReAct(sig, &MockLM{}, registry.Subset("search", "date"))
+
+	var names []string
+	for _, tool := range react.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "search,date,finish" {
+		t.Errorf("tools = %v, want the subset plus finish", names)
+	}
+	if got := registry.Names(); len(got) != 3 {
+		t.Errorf("registry names = %v, the agent must not change the catalog", got)
+	}
+}
+
 func TestCoerceBasicTypes(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -508,6 +536,7 @@ func TestReAct_Forward_ToolError(t *testing.T) {
 
 	callCount := 0
 	lm := &MockLM{
+		SupportsToolsVal: true,
 		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
 			callCount++
 			if callCount == 1 {
@@ -1449,3 +1478,627 @@ func TestReAct_WithMethods(t *testing.T) {
 		t.Error("WithDemos should set demos")
 	}
 }
+
+func TestReAct_EstimateUsage_IncludesTools(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	searchTool := core.NewTool("search", "Search the web for up-to-date information", func(ctx context.Context, args map[string]any) (any, error) {
+		return "result", nil
+	}).AddParameter("query", "string", "Search query", true)
+
+	inputs := map[string]any{"question": "test"}
+
+	tests := []struct {
+		name          string
+		supportsTools bool
+		wantTools     bool
+	}{
+		{"tools supported", true, true},
+		{"tools unsupported", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			react := NewReAct(sig, &MockLM{SupportsToolsVal: tt.supportsTools}, []core.Tool{*searchTool})
+
+			messages, err := react.BuildPrompt(inputs)
+			if err != nil {
+				t.Fatalf("BuildPrompt() error = %v", err)
+			}
+			if messages[0].Role != "system" || !strings.Contains(messages[0].Content, "search") {
+				t.Errorf("expected ReAct system prompt first, got %+v", messages[0])
+			}
+
+			usage, err := react.EstimateUsage(inputs)
+			if err != nil {
+				t.Fatalf("EstimateUsage() error = %v", err)
+			}
+
+			want := core.EstimateMessageTokens(messages)
+			if tt.wantTools {
+				want += core.EstimateToolTokens(react.Tools)
+			}
+			if usage.PromptTokens != want {
+				t.Errorf("PromptTokens = %d, want %d", usage.PromptTokens, want)
+			}
+		})
+	}
+}
+
+func TestReAct_StructToolResultIsJSON(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	var observation string
+	callCount := 0
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			callCount++
+			if callCount == 1 {
+				return &core.GenerateResult{
+					ToolCalls: []core.ToolCall{{ID: "1", Name: "stats", Arguments: map[string]any{}}},
+				}, nil
+			}
+			observation = messages[len(messages)-1].Content
+			return &core.GenerateResult{Content: `{"answer": "done"}`}, nil
+		},
+	}
+
+	type stats struct {
+		Mean float64 `json:"mean"`
+	}
+	statsTool := core.NewTool("stats", "Compute stats", func(ctx context.Context, args map[string]any) (any, error) {
+		return stats{Mean: 2.5}, nil
+	})
+
+	react := NewReAct(sig, lm, []core.Tool{*statsTool})
+	if _, err := react.Forward(context.Background(), map[string]any{"question": "test"}); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if observation != `{"mean":2.5}` {
+		t.Errorf("expected JSON observation, got %q", observation)
+	}
+}
+
+func TestReAct_LoopDetection(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	tests := []struct {
+		name          string
+		loopDetection bool
+		wantExecs     int
+		wantNudge     bool
+	}{
+		{"disabled executes repeats", false, 2, false},
+		{"enabled skips repeats", true, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastToolMessage string
+			callCount := 0
+			lm := &MockLM{
+				SupportsToolsVal: true,
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					callCount++
+					if callCount <= 2 {
+						return &core.GenerateResult{
+							ToolCalls: []core.ToolCall{{
+								ID:        fmt.Sprintf("call_%d", callCount),
+								Name:      "search",
+								Arguments: map[string]any{"query": "same", "limit": 5},
+							}},
+						}, nil
+					}
+					lastToolMessage = messages[len(messages)-1].Content
+					return &core.GenerateResult{Content: `{"answer": "done"}`}, nil
+				},
+			}
+
+			execs := 0
+			searchTool := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+				execs++
+				return fmt.Sprintf("result %d", execs), nil
+			})
+
+			react := NewReAct(sig, lm, []core.Tool{*searchTool}).WithLoopDetection(tt.loopDetection)
+			if _, err := react.Forward(context.Background(), map[string]any{"question": "test"}); err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			if execs != tt.wantExecs {
+				t.Errorf("tool executed %d times, want %d", execs, tt.wantExecs)
+			}
+			if got := strings.Contains(lastToolMessage, "You already called search"); got != tt.wantNudge {
+				t.Errorf("loop nudge present = %v, want %v (message %q)", got, tt.wantNudge, lastToolMessage)
+			}
+		})
+	}
+}
+
+func TestReAct_ForceAnswerOnMaxIterations(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	tests := []struct {
+		name          string
+		force         bool
+		wantToolCalls int
+	}{
+		{"default spends last iteration on the answer", false, 1},
+		{"force adds a final answer call", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolCalls := 0
+			lm := &MockLM{
+				SupportsToolsVal: true,
+				GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+					if len(options.Tools) == 0 {
+						return &core.GenerateResult{Content: `{"answer": "best effort"}`}, nil
+					}
+					toolCalls++
+					return &core.GenerateResult{
+						ToolCalls: []core.ToolCall{{
+							ID:        fmt.Sprintf("call_%d", toolCalls),
+							Name:      "search",
+							Arguments: map[string]any{"query": fmt.Sprintf("q%d", toolCalls)},
+						}},
+					}, nil
+				},
+			}
+
+			searchTool := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+				return "result for " + args["query"].(string), nil
+			})
+
+			react := NewReAct(sig, lm, []core.Tool{*searchTool}).
+				WithMaxIterations(2).
+				WithForceAnswerOnMaxIterations(tt.force)
+
+			prediction, err := react.Forward(context.Background(), map[string]any{"question": "test"})
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+			if prediction.Outputs["answer"] != "best effort" {
+				t.Errorf("unexpected answer: %v", prediction.Outputs["answer"])
+			}
+			if toolCalls != tt.wantToolCalls {
+				t.Errorf("tool-enabled iterations = %d, want %d", toolCalls, tt.wantToolCalls)
+			}
+		})
+	}
+}
+
+func TestReAct_WithCoreMockLM(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := core.NewMockLM().RespondSequence(
+		core.MockResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "search", Arguments: map[string]any{"query": "dsgo"}}}},
+		core.MockResponse{Content: `{"answer": "a Go port of DSPy"}`},
+	)
+
+	searchTool := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+		return "DSGo is a Go port of DSPy", nil
+	}).AddParameter("query", "string", "Query", true)
+
+	prediction, err := NewReAct(sig, lm, []core.Tool{*searchTool}).
+		Forward(context.Background(), map[string]any{"question": "What is DSGo?"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if prediction.Outputs["answer"] != "a Go port of DSPy" {
+		t.Errorf("unexpected answer: %v", prediction.Outputs["answer"])
+	}
+	if prediction.Usage.TotalTokens == 0 {
+		t.Error("expected mock usage to be reported")
+	}
+
+	calls := lm.Calls()
+	if len(calls) != 2 || calls[1][len(calls[1])-1].Content != "DSGo is a Go port of DSPy" {
+		t.Errorf("expected tool observation in second call, got %+v", calls)
+	}
+}
+
+func TestReAct_PerIterationTimeout(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	callCount := 0
+	var lmDeadlines []time.Time
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			callCount++
+			deadline, _ := ctx.Deadline()
+			lmDeadlines = append(lmDeadlines, deadline)
+			if callCount == 1 {
+				return &core.GenerateResult{
+					ToolCalls: []core.ToolCall{{ID: "1", Name: "slow", Arguments: map[string]any{}}},
+				}, nil
+			}
+			return &core.GenerateResult{Content: `{"answer": "done"}`}, nil
+		},
+	}
+
+	// The stuck tool is cut off by its iteration's deadline and reported as an observation
+	slowTool := core.NewTool("slow", "Never returns", func(ctx context.Context, args map[string]any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	start := time.Now()
+	react := NewReAct(sig, lm, []core.Tool{*slowTool}).
+		WithTimeout(5 * time.Second).
+		WithPerIterationTimeout(50 * time.Millisecond)
+	prediction, err := react.Forward(context.Background(), map[string]any{"question": "q"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if prediction.Outputs["answer"] != "done" {
+		t.Errorf("Expected answer 'done', got %v", prediction.Outputs["answer"])
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stuck tool was not cut off by the iteration deadline (took %v)", elapsed)
+	}
+	if len(lmDeadlines) != 2 || !lmDeadlines[1].After(lmDeadlines[0]) {
+		t.Errorf("expected a fresh deadline per iteration, got %v", lmDeadlines)
+	}
+}
+
+func TestReAct_WithTimeout(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	react := NewReAct(sig, lm, nil).WithTimeout(20 * time.Millisecond)
+	_, err := react.Forward(context.Background(), map[string]any{"question": "q"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestReAct_UsageSumsIterations(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	callCount := 0
+	lm := &MockLM{
+		SupportsToolsVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			callCount++
+			usage := core.Usage{PromptTokens: 10 * callCount, CompletionTokens: 5, TotalTokens: 10*callCount + 5}
+			if callCount == 1 {
+				return &core.GenerateResult{
+					Content:   "Let me search",
+					ToolCalls: []core.ToolCall{{ID: "1", Name: "search", Arguments: map[string]any{"query": "test"}}},
+					Usage:     usage,
+				}, nil
+			}
+			return &core.GenerateResult{Content: `{"answer": "final answer"}`, Usage: usage}, nil
+		},
+	}
+
+	searchTool := core.NewTool("search", "Search for info", func(ctx context.Context, args map[string]any) (any, error) {
+		return "search result", nil
+	})
+
+	pred, err := NewReAct(sig, lm, []core.Tool{*searchTool}).Forward(context.Background(), map[string]any{"question": "test"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if pred.Usage.PromptTokens != 30 || pred.Usage.TotalTokens != 40 {
+		t.Errorf("Usage = %+v, want the sum of both iterations", pred.Usage)
+	}
+	if pred.SubUsage["iteration_1"].PromptTokens != 10 || pred.SubUsage["iteration_2"].PromptTokens != 20 {
+		t.Errorf("SubUsage = %+v, want one entry per iteration", pred.SubUsage)
+	}
+}
+
+func TestReAct_RequiresToolSupport(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	called := false
+	lm := &MockLM{
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			called = true
+			return &core.GenerateResult{Content: `{"answer": "guess"}`}, nil
+		},
+	}
+	search := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+		return "result", nil
+	})
+
+	_, err := NewReAct(sig, lm, []core.Tool{*search}).Forward(context.Background(), map[string]any{"question": "q"})
+	if !errors.Is(err, core.ErrToolsUnsupported) {
+		t.Fatalf("expected ErrToolsUnsupported, got %v", err)
+	}
+	if called {
+		t.Error("LM should not be called when tools cannot be used")
+	}
+
+	// Only the injected finish tool: the model just answers
+	if _, err := NewReAct(sig, lm, nil).Forward(context.Background(), map[string]any{"question": "q"}); err != nil {
+		t.Errorf("expected ReAct without tools to run, got %v", err)
+	}
+}
+
+func TestReAct_WithProgressGuard(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	search := func(query string) core.MockResponse {
+		return core.MockResponse{ToolCalls: []core.ToolCall{{ID: query, Name: "search", Arguments: map[string]any{"query": query}}}}
+	}
+	lm := core.NewMockLM().
+		RespondSequence(search("a1"), search("b1"), search("a2"), search("b2")).
+		Respond(`{"answer": "best guess"}`)
+
+	// Results alternate between two pages, so nothing new is learned after the second iteration
+	searchTool := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+		return "page " + args["query"].(string)[:1], nil
+	}).AddParameter("query", "string", "Query", true)
+
+	var traces []IterationTrace
+	guard := NoNewInformationGuard(2)
+	prediction, err := NewReAct(sig, lm, []core.Tool{*searchTool}).
+		WithMaxIterations(10).
+		WithProgressGuard(func(iterations []IterationTrace) bool {
+			traces = iterations
+			return guard(iterations)
+		}).
+		Forward(context.Background(), map[string]any{"question": "What is DSGo?"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	if len(traces) != 4 {
+		t.Fatalf("guard saw %d iterations, want to stop after 4", len(traces))
+	}
+	if traces[0].Iteration != 1 || traces[0].ToolCalls[0].Name != "search" || traces[3].Observations[0] != "page b" {
+		t.Errorf("unexpected traces: %+v", traces)
+	}
+	if prediction.Outputs["answer"] != "best guess" || prediction.Metadata["stop_reason"] != "progress_guard" {
+		t.Errorf("prediction = %+v, want the extracted answer stopped by the guard", prediction)
+	}
+	if calls := lm.CallCount(); calls != 5 {
+		t.Errorf("LM called %d times, want 4 iterations plus extraction", calls)
+	}
+}
+
+func TestNoNewInformationGuard(t *testing.T) {
+	trace := func(observations ...string) IterationTrace {
+		return IterationTrace{Observations: observations}
+	}
+	guard := NoNewInformationGuard(2)
+
+	tests := []struct {
+		name       string
+		iterations []IterationTrace
+		want       bool
+	}{
+		{"too few iterations", []IterationTrace{trace("a"), trace("a")}, false},
+		{"last two repeat", []IterationTrace{trace("a"), trace("b"), trace("a"), trace("b")}, true},
+		{"one new observation", []IterationTrace{trace("a"), trace("b"), trace("a"), trace("a", "c")}, false},
+		{"only the last repeats", []IterationTrace{trace("a"), trace("b"), trace("c"), trace("a")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guard(tt.iterations); got != tt.want {
+				t.Errorf("guard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReAct_CollectsToolCalls(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	collector := core.NewMemoryCollector(10)
+	core.Configure(core.WithCollector(collector))
+
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	lm := core.NewMockLM().
+		RespondSequence(core.MockResponse{ToolCalls: []core.ToolCall{
+			{ID: "1", Name: "search", Arguments: map[string]any{"query": "dsgo"}},
+			{ID: "2", Name: "broken", Arguments: map[string]any{}},
+		}}).
+		Respond(`{"answer": "a Go port of DSPy"}`)
+	search := core.NewTool("search", "Search", func(ctx context.Context, args map[string]any) (any, error) {
+		return "DSGo is a Go port of DSPy", nil
+	}).AddParameter("query", "string", "Query", true)
+	broken := core.NewTool("broken", "Always fails", func(ctx context.Context, args map[string]any) (any, error) {
+		return nil, errors.New("backend down")
+	})
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	if _, err := NewReAct(sig, lm, []core.Tool{*search, *broken}).Forward(ctx, map[string]any{"question": "What is DSGo?"}); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	var tools []*core.HistoryEntry
+	for _, entry := range collector.GetAll() {
+		if entry.Kind == core.HistoryEntryKindTool {
+			tools = append(tools, entry)
+		}
+	}
+	if len(tools) != 2 {
+		t.Fatalf("collected %d tool entries, want 2", len(tools))
+	}
+	if tools[0].RequestID != "req-42" || tools[0].Tool.Name != "search" || tools[0].Tool.Arguments["query"] != "dsgo" || !strings.Contains(tools[0].Tool.Result, "Go port") {
+		t.Errorf("search entry = %+v, tool = %+v", tools[0], tools[0].Tool)
+	}
+	if tools[1].Tool.Name != "broken" || tools[1].Error == nil || tools[1].Error.Message != "backend down" {
+		t.Errorf("broken entry = %+v, error = %+v", tools[1], tools[1].Error)
+	}
+}
+
+func TestReAct_ToolsUseAgentLM(t *testing.T) {
+	sig := core.NewSignature("Answer question").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	lm := core.NewMockLM().
+		RespondSequence(
+			core.MockResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "summarize", Arguments: map[string]any{"text": "long text"}}}},
+			core.MockResponse{Content: `{"summary": "short"}`},
+		).
+		Respond(`{"answer": "short"}`)
+
+	summarize := core.NewTool("summarize", "Summarize text", func(ctx context.Context, args map[string]any) (any, error) {
+		if id := logging.GetRequestID(ctx); id != "req-7" {
+			t.Errorf("tool request ID = %q, want the agent's", id)
+		}
+		options := core.OptionsFromContext(ctx)
+		if options.Temperature != 0.3 {
+			t.Errorf("tool options temperature = %v, want the agent's 0.3", options.Temperature)
+		}
+		toolLM, ok := core.LMFromContext(ctx)
+		if !ok {
+			t.Fatal("LMFromContext() found no LM in the tool context")
+		}
+		summarySig := core.NewSignature("Summarize").
+			AddInput("text", core.FieldTypeString, "Text").
+			AddOutput("summary", core.FieldTypeString, "Summary")
+		pred, err := NewPredict(summarySig, toolLM).WithOptions(options).Forward(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return pred.Outputs["summary"], nil
+	}).AddParameter("text", "string", "Text", true)
+
+	agent := NewReAct(sig, lm, []core.Tool{*summarize})
+	agent.Options.Temperature = 0.3
+	ctx := logging.WithRequestID(context.Background(), "req-7")
+	pred, err := agent.Forward(ctx, map[string]any{"question": "Summarize it"})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	toolUsage := pred.SubUsage["tool_summarize"]
+	if toolUsage.TotalTokens == 0 {
+		t.Fatalf("SubUsage = %+v, want the tool's LM call counted", pred.SubUsage)
+	}
+	iterations := pred.SubUsage["iteration_1"].TotalTokens + pred.SubUsage["iteration_2"].TotalTokens
+	if pred.Usage.TotalTokens != iterations+toolUsage.TotalTokens {
+		t.Errorf("Usage = %d tokens, want iterations (%d) plus the tool (%d)", pred.Usage.TotalTokens, iterations, toolUsage.TotalTokens)
+	}
+}
+
+func TestReAct_WithDirectToolAnswer(t *testing.T) {
+	calculate := core.NewTool("calculate", "Evaluate an expression", func(ctx context.Context, args map[string]any) (any, error) {
+		if args["expression"] == "1/0" {
+			return "undefined", nil
+		}
+		return 391, nil
+	}).AddParameter("expression", "string", "Expression", true)
+
+	t.Run("single output", func(t *testing.T) {
+		sig := core.NewSignature("Compute").
+			AddInput("question", core.FieldTypeString, "Question").
+			AddOutput("result", core.FieldTypeInt, "Result")
+		lm := core.NewMockLM().
+			RespondSequence(core.MockResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "calculate", Arguments: map[string]any{"expression": "17*23"}}}})
+
+		pred, err := NewReAct(sig, lm, []core.Tool{*calculate}).
+			WithDirectToolAnswer("calculate").
+			Forward(context.Background(), map[string]any{"question": "17 times 23?"})
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if pred.Outputs["result"] != 391 || pred.Metadata["direct_tool_answer"] != "calculate" {
+			t.Errorf("prediction = %v / %v, want the tool result", pred.Outputs, pred.Metadata)
+		}
+		if calls := len(lm.Calls()); calls != 1 {
+			t.Errorf("LM calls = %d, want 1 (no final answer round)", calls)
+		}
+	})
+
+	t.Run("invalid result continues", func(t *testing.T) {
+		sig := core.NewSignature("Compute").
+			AddInput("question", core.FieldTypeString, "Question").
+			AddOutput("result", core.FieldTypeInt, "Result").
+			AddOutput("explanation", core.FieldTypeString, "Explanation")
+		lm := core.NewMockLM().
+			RespondSequence(core.MockResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "calculate", Arguments: map[string]any{"expression": "1/0"}}}}).
+			Respond(`{"result": 0, "explanation": "division by zero"}`)
+
+		pred, err := NewReAct(sig, lm, []core.Tool{*calculate}).
+			WithDirectToolAnswer("calculate").
+			Forward(context.Background(), map[string]any{"question": "1/0?"})
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if pred.Outputs["explanation"] != "division by zero" || pred.Metadata["direct_tool_answer"] != nil {
+			t.Errorf("prediction = %v / %v, want the model's answer", pred.Outputs, pred.Metadata)
+		}
+	})
+}
+
+func TestReAct_ToolSchemas(t *testing.T) {
+	sig := core.NewSignature("Analyze").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+	analyze := core.NewTool("analyze", "Analyze a dataset", nil).
+		AddParameter("dataset", "string", "Dataset name", true).
+		AddParameter("normalize", "bool", "Normalize values first", false).
+		AddEnumParameter("method", "Statistic", []string{"mean", "median"}, true)
+
+	react := NewReAct(sig, core.NewMockLM(), []core.Tool{*analyze})
+	schemas := react.ToolSchemas()
+	if len(schemas) != len(react.Tools) {
+		t.Fatalf("ToolSchemas() = %d schemas, want one per tool (%d)", len(schemas), len(react.Tools))
+	}
+
+	data, err := json.Marshal(schemas[0])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var schema struct {
+		Function struct {
+			Name       string `json:"name"`
+			Parameters struct {
+				Properties map[string]struct {
+					Type string   `json:"type"`
+					Enum []string `json:"enum"`
+				} `json:"properties"`
+				Required []string `json:"required"`
+			} `json:"parameters"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	params := schema.Function.Parameters
+	if schema.Function.Name != "analyze" || params.Properties["normalize"].Type != "bool" ||
+		len(params.Properties["method"].Enum) != 2 || strings.Join(params.Required, ",") != "dataset,method" {
+		t.Errorf("schema = %s, want the parameters as declared", data)
+	}
+	if !reflect.DeepEqual(schemas[0], analyze.FunctionSchema()) {
+		t.Errorf("schema = %v, want the tool's FunctionSchema", schemas[0])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
@@ -16,7 +17,14 @@ type Refine struct {
 	Options         *core.GenerateOptions
 	Adapter         core.Adapter
 	MaxIterations   int
-	RefinementField string // Field name to use for refinement feedback
+	RefinementField string        // Field name to use for refinement feedback
+	Timeout         time.Duration // Deadline for each Forward (0 = none)
+	// MaxTokensPerField budgets completion tokens per output field (see WithMaxTokensPerField)
+	MaxTokensPerField map[string]int
+	// Scorer scores every iteration; refinement then keeps going until TargetScore is met
+	// and returns the best-scoring iteration (nil = refine blindly, return the last)
+	Scorer      ScoringFunction
+	TargetScore float64 // Stop once an iteration scores at least this (0 = run all iterations)
 }
 
 // NewRefine creates a new Refine module
@@ -37,6 +45,13 @@ func (r *Refine) WithOptions(options *core.GenerateOptions) *Refine {
 	return r
 }
 
+// WithMaxTokensPerField sizes MaxTokens from per-output-field token budgets, replacing
+// any fixed Options.MaxTokens. Fields without a budget get core.FieldTokenBudgets for their type.
+func (r *Refine) WithMaxTokensPerField(budgets map[string]int) *Refine {
+	r.MaxTokensPerField = budgets
+	return r
+}
+
 // WithAdapter sets a custom adapter
 func (r *Refine) WithAdapter(adapter core.Adapter) *Refine {
 	r.Adapter = adapter
@@ -55,6 +70,27 @@ func (r *Refine) WithRefinementField(field string) *Refine {
 	return r
 }
 
+// WithTimeout sets a deadline for each Forward call, covering every refinement attempt
+// It replaces the global default timeout for those calls; a caller deadline still applies.
+func (r *Refine) WithTimeout(timeout time.Duration) *Refine {
+	r.Timeout = timeout
+	return r
+}
+
+// WithScorer scores each iteration with scorer. Refinement then runs without a
+// feedback input too, telling the model its previous score, and Forward returns
+// the best-scoring iteration with every score in Prediction.Scores.
+func (r *Refine) WithScorer(scorer ScoringFunction) *Refine {
+	r.Scorer = scorer
+	return r
+}
+
+// WithTargetScore stops refinement once an iteration scores at least target (needs WithScorer)
+func (r *Refine) WithTargetScore(target float64) *Refine {
+	r.TargetScore = target
+	return r
+}
+
 // GetSignature returns the module's signature
 func (r *Refine) GetSignature() *core.Signature {
 	return r.Signature
@@ -62,6 +98,14 @@ func (r *Refine) GetSignature() *core.Signature {
 
 // Forward executes the refinement loop
 func (r *Refine) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return withErrorTrace(ctx, "Refine", inputs, r.forward)
+}
+
+// forward is Forward without error tracing
+func (r *Refine) forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+
 	if err := r.Signature.ValidateInputs(inputs); err != nil {
 		return nil, fmt.Errorf("input validation failed: %w", err)
 	}
@@ -72,10 +116,31 @@ func (r *Refine) Forward(ctx context.Context, inputs map[string]any) (*core.Pred
 		return nil, fmt.Errorf("initial prediction failed: %w", err)
 	}
 
+	// Refinement needs the initial outputs, so a dry run stops here
+	if isDryRun(prediction) {
+		return prediction, nil
+	}
+
+	// Usage is summed over the initial prediction and every refinement
+	usage := prediction.Usage
+	subUsage := map[string]core.Usage{"initial": prediction.Usage}
+	withUsage := func(pred *core.Prediction) *core.Prediction {
+		pred.Usage = usage
+		pred.SubUsage = subUsage
+		return pred
+	}
+
 	// Check if feedback is provided for refinement
 	feedback, hasFeedback := inputs[r.RefinementField]
+	if r.Scorer != nil {
+		best, err := r.refineScored(ctx, inputs, prediction, feedback, hasFeedback, &usage, subUsage)
+		if err != nil {
+			return nil, err
+		}
+		return withUsage(best), nil
+	}
 	if !hasFeedback || r.MaxIterations <= 1 {
-		return prediction, nil
+		return withUsage(prediction), nil
 	}
 
 	// Refinement loop
@@ -84,13 +149,63 @@ func (r *Refine) Forward(ctx context.Context, inputs map[string]any) (*core.Pred
 		refined, err := r.generateRefinement(ctx, inputs, prediction.Outputs, fmt.Sprintf("%v", feedback))
 		if err != nil {
 			// If refinement fails, return the last valid prediction
-			return prediction, nil
+			return withUsage(prediction), nil
 		}
 
+		usage.Add(refined.Usage)
+		subUsage[fmt.Sprintf("refinement_%d", i+1)] = refined.Usage
 		prediction = refined
 	}
 
-	return prediction, nil
+	return withUsage(prediction), nil
+}
+
+// refineScored refines until an iteration meets TargetScore or MaxIterations is
+// reached, returning the best-scoring iteration. The score of every iteration is
+// recorded in Scores and the winner's index in Metadata["best_iteration"].
+func (r *Refine) refineScored(ctx context.Context, inputs map[string]any, prediction *core.Prediction, feedback any, hasFeedback bool, usage *core.Usage, subUsage map[string]core.Usage) (*core.Prediction, error) {
+	var scores []float64
+	best, bestIteration := prediction, 0
+	for i := 0; ; i++ {
+		score, err := r.Scorer(inputs, prediction)
+		if err != nil {
+			return nil, fmt.Errorf("scoring iteration %d failed: %w", i, err)
+		}
+		scores = append(scores, score)
+		if score > scores[bestIteration] {
+			best, bestIteration = prediction, i
+		}
+
+		if (r.TargetScore > 0 && score >= r.TargetScore) || i >= r.MaxIterations-1 {
+			break
+		}
+
+		instruction := r.scoreFeedback(score)
+		if hasFeedback {
+			instruction = fmt.Sprintf("%v\n\n%s", feedback, instruction)
+		}
+		refined, err := r.generateRefinement(ctx, inputs, prediction.Outputs, instruction)
+		if err != nil {
+			// If refinement fails, return the best valid prediction
+			break
+		}
+		usage.Add(refined.Usage)
+		subUsage[fmt.Sprintf("refinement_%d", i+1)] = refined.Usage
+		prediction = refined
+	}
+
+	best.Score = scores[bestIteration]
+	best.Scores = scores
+	best.WithMetadata("best_iteration", bestIteration)
+	return best, nil
+}
+
+// scoreFeedback tells the model how its previous output scored
+func (r *Refine) scoreFeedback(score float64) string {
+	if r.TargetScore > 0 {
+		return fmt.Sprintf("Your previous output scored %g; the target is %g. Improve it to reach the target.", score, r.TargetScore)
+	}
+	return fmt.Sprintf("Your previous output scored %g. Improve it to score higher.", score)
 }
 
 func (r *Refine) generatePrediction(ctx context.Context, inputs map[string]any, previousOutput map[string]any) (*core.Prediction, error) {
@@ -105,8 +220,8 @@ func (r *Refine) generatePrediction(ctx context.Context, inputs map[string]any,
 
 		// Add previous output
 		prompt.WriteString("--- Previous Output ---\n")
-		for k, v := range previousOutput {
-			prompt.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+		for _, k := range core.OrderedFieldKeys(r.Signature.OutputFields, previousOutput) {
+			prompt.WriteString(fmt.Sprintf("%s: %v\n", k, previousOutput[k]))
 		}
 		prompt.WriteString("\n")
 
@@ -155,17 +270,25 @@ func (r *Refine) generatePrediction(ctx context.Context, inputs map[string]any,
 
 	// Copy options to avoid mutation
 	options := r.Options.Copy()
+	options.MaxTokens = r.Signature.ResolveMaxTokens(options.MaxTokens, r.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
 	if r.LM.SupportsJSON() {
-		if _, isJSON := r.Adapter.(*core.JSONAdapter); isJSON {
+		if core.UsesJSONMode(r.Adapter) {
 			options.ResponseFormat = "json"
-			// Auto-generate JSON schema from signature for structured outputs
-			if options.ResponseSchema == nil {
+			// Auto-generate JSON schema from signature when the model supports structured outputs
+			if options.ResponseSchema == nil && core.CapabilitiesOf(r.LM).SupportsJSONSchema {
 				options.ResponseSchema = r.Signature.SignatureToJSONSchema()
 			}
 		}
 	}
+	core.ConstrainEnums(r.Adapter, r.LM, r.Signature, options)
+
+	if result, ok := core.DryRun(ctx, r.LM.Name(), messages, options); ok {
+		return dryRunPrediction("Refine", inputs, result), nil
+	}
 
 	result, err := r.LM.Generate(ctx, messages, options)
+	recordCall(ctx, "Refine", messages, result, err)
 	if err != nil {
 		return nil, fmt.Errorf("LM generation failed: %w", err)
 	}
@@ -182,7 +305,7 @@ func (r *Refine) generatePrediction(ctx context.Context, inputs map[string]any,
 
 	// Check for empty content with finish_reason=stop (actual error)
 	if result.Content == "" && result.FinishReason == "stop" {
-		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop (model error)")
+		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop: %w", core.ErrEmptyResponse)
 	}
 
 	// Use adapter to parse output
@@ -220,8 +343,8 @@ func (r *Refine) generateRefinement(ctx context.Context, inputs map[string]any,
 
 	// Add previous output
 	prompt.WriteString("--- Previous Output ---\n")
-	for k, v := range previousOutput {
-		prompt.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+	for _, k := range core.OrderedFieldKeys(r.Signature.OutputFields, previousOutput) {
+		prompt.WriteString(fmt.Sprintf("%s: %v\n", k, previousOutput[k]))
 	}
 	prompt.WriteString("\n")
 
@@ -264,17 +387,21 @@ func (r *Refine) generateRefinement(ctx context.Context, inputs map[string]any,
 
 	// Copy options to avoid mutation
 	options := r.Options.Copy()
+	options.MaxTokens = r.Signature.ResolveMaxTokens(options.MaxTokens, r.MaxTokensPerField)
+	core.ApplyOptionOverrides(ctx, options)
 	if r.LM.SupportsJSON() {
-		if _, isJSON := r.Adapter.(*core.JSONAdapter); isJSON {
+		if core.UsesJSONMode(r.Adapter) {
 			options.ResponseFormat = "json"
-			// Auto-generate JSON schema from signature for structured outputs
-			if options.ResponseSchema == nil {
+			// Auto-generate JSON schema from signature when the model supports structured outputs
+			if options.ResponseSchema == nil && core.CapabilitiesOf(r.LM).SupportsJSONSchema {
 				options.ResponseSchema = r.Signature.SignatureToJSONSchema()
 			}
 		}
 	}
+	core.ConstrainEnums(r.Adapter, r.LM, r.Signature, options)
 
 	result, err := r.LM.Generate(ctx, messages, options)
+	recordCall(ctx, "Refine", messages, result, err)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +418,7 @@ func (r *Refine) generateRefinement(ctx context.Context, inputs map[string]any,
 
 	// Check for empty content with finish_reason=stop (actual error)
 	if result.Content == "" && result.FinishReason == "stop" {
-		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop (model error)")
+		return nil, fmt.Errorf("model returned empty content despite finish_reason=stop: %w", core.ErrEmptyResponse)
 	}
 
 	// Use adapter to parse output
@@ -592,3 +592,97 @@ func TestRefine_FinishReasonHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestRefine_UsageSumsIterations(t *testing.T) {
+	sig := core.NewSignature("Generate answer").
+		AddInput("question", core.FieldTypeString, "Question").
+		AddInput("feedback", core.FieldTypeString, "Feedback").
+		AddOutput("answer", core.FieldTypeString, "Answer")
+
+	lm := &MockLM{
+		SupportsJSONVal: true,
+		GenerateFunc: func(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+			return &core.GenerateResult{
+				Content: `{"answer": "refined"}`,
+				Usage:   core.Usage{PromptTokens: 8, CompletionTokens: 2, TotalTokens: 10},
+			}, nil
+		},
+	}
+
+	pred, err := NewRefine(sig, lm).WithMaxIterations(3).Forward(context.Background(), map[string]any{
+		"question": "test",
+		"feedback": "be concise",
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if pred.Usage.TotalTokens != 30 {
+		t.Errorf("TotalTokens = %d, want 30 (initial + 2 refinements)", pred.Usage.TotalTokens)
+	}
+	for _, key := range []string{"initial", "refinement_1", "refinement_2"} {
+		if pred.SubUsage[key].TotalTokens != 10 {
+			t.Errorf("SubUsage[%q] = %+v, want 10 tokens", key, pred.SubUsage[key])
+		}
+	}
+}
+
+func TestRefine_WithScorer(t *testing.T) {
+	sig := core.NewSignature("Write a slogan").
+		AddInput("product", core.FieldTypeString, "Product").
+		AddOutput("slogan", core.FieldTypeString, "Slogan")
+
+	scores := map[string]float64{"ok": 0.5, "great": 0.9, "worse": 0.2, "best": 0.95}
+	scorer := func(inputs map[string]any, pred *core.Prediction) (float64, error) {
+		slogan, _ := pred.GetString("slogan")
+		return scores[slogan], nil
+	}
+	respond := func(slogans ...string) *core.MockLM {
+		var responses []core.MockResponse
+		for _, s := range slogans {
+			responses = append(responses, core.MockResponse{Content: `{"slogan": "` + s + `"}`})
+		}
+		return core.NewMockLM().RespondSequence(responses...)
+	}
+	inputs := map[string]any{"product": "coffee"}
+
+	t.Run("returns the best iteration", func(t *testing.T) {
+		lm := respond("ok", "great", "worse")
+		pred, err := NewRefine(sig, lm).WithMaxIterations(3).WithScorer(scorer).Forward(context.Background(), inputs)
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if slogan, _ := pred.GetString("slogan"); slogan != "great" || pred.Score != 0.9 {
+			t.Errorf("got %q scored %v, want the best iteration", slogan, pred.Score)
+		}
+		if len(pred.Scores) != 3 || pred.Scores[2] != 0.2 || pred.Metadata["best_iteration"] != 1 {
+			t.Errorf("Scores = %v, best_iteration = %v", pred.Scores, pred.Metadata["best_iteration"])
+		}
+		if lm.CallCount() != 3 {
+			t.Errorf("LM called %d times, want 3 even without a feedback input", lm.CallCount())
+		}
+		if last := lm.Calls()[1]; !contains(last[len(last)-1].Content, "scored 0.5") {
+			t.Error("refinement prompt should report the previous score")
+		}
+	})
+
+	t.Run("stops at the target score", func(t *testing.T) {
+		lm := respond("ok", "great", "best")
+		pred, err := NewRefine(sig, lm).WithMaxIterations(5).WithScorer(scorer).WithTargetScore(0.8).Forward(context.Background(), inputs)
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		if slogan, _ := pred.GetString("slogan"); slogan != "great" || lm.CallCount() != 2 {
+			t.Errorf("got %q after %d calls, want to stop at the first output meeting the target", slogan, lm.CallCount())
+		}
+		if pred.SubUsage == nil || len(pred.Scores) != 2 {
+			t.Errorf("Scores = %v, SubUsage = %v", pred.Scores, pred.SubUsage)
+		}
+	})
+
+	t.Run("scorer error", func(t *testing.T) {
+		failing := func(map[string]any, *core.Prediction) (float64, error) { return 0, errors.New("judge down") }
+		if _, err := NewRefine(sig, respond("ok")).WithScorer(failing).Forward(context.Background(), inputs); err == nil || !contains(err.Error(), "judge down") {
+			t.Errorf("Forward() error = %v, want the scorer error", err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package module
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// WeightedScorer pairs a scorer with its weight in CombineScorers
+type WeightedScorer struct {
+	Scorer ScoringFunction
+	Weight float64
+}
+
+// CombineScorers returns a scorer summing the scores of scorers, each multiplied by
+// its weight. The first scorer error fails the combined score.
+func CombineScorers(scorers ...WeightedScorer) ScoringFunction {
+	scorers = append([]WeightedScorer(nil), scorers...)
+	return func(inputs map[string]any, prediction *core.Prediction) (float64, error) {
+		total := 0.0
+		for _, weighted := range scorers {
+			score, err := weighted.Scorer(inputs, prediction)
+			if err != nil {
+				return 0, err
+			}
+			total += weighted.Weight * score
+		}
+		return total, nil
+	}
+}
+
+// WeightedFieldScorer returns a scorer for outputs that rate themselves, summing the
+// named numeric output fields multiplied by their weights, e.g.
+// WeightedFieldScorer(map[string]float64{"hook_strength": 0.4, "seo_score": 0.6}).
+// Values are read with Prediction.GetFloat, so "0.8" and "80%" count as numbers;
+// a missing or non-numeric field is an error.
+func WeightedFieldScorer(weights map[string]float64) ScoringFunction {
+	// Sum in a fixed order so equal predictions always score exactly the same
+	fields := make([]string, 0, len(weights))
+	for field := range weights {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	copied := make(map[string]float64, len(weights))
+	for field, weight := range weights {
+		copied[field] = weight
+	}
+
+	return func(inputs map[string]any, prediction *core.Prediction) (float64, error) {
+		total := 0.0
+		for _, field := range fields {
+			value, ok := prediction.GetFloat(field)
+			if !ok {
+				if _, exists := prediction.Outputs[field]; !exists {
+					return 0, fmt.Errorf("score field '%s' not found in outputs", field)
+				}
+				return 0, fmt.Errorf("score field '%s' is not numeric: %v", field, prediction.Outputs[field])
+			}
+			total += copied[field] * value
+		}
+		return total, nil
+	}
+}
+
+// LengthScorer returns a scorer preferring a string output field of about ideal words.
+// The score is 1 at ideal and drops by penalty for every word above or below it, down to 0.
+func LengthScorer(field string, ideal int, penalty float64) ScoringFunction {
+	return func(inputs map[string]any, prediction *core.Prediction) (float64, error) {
+		text, err := stringField(prediction, field)
+		if err != nil {
+			return 0, err
+		}
+		deviation := math.Abs(float64(len(strings.Fields(text)) - ideal))
+		return math.Max(0, 1-penalty*deviation), nil
+	}
+}
+
+// ContainsScorer returns a scorer rewarding a string output field for mentioning
+// phrases: the score is the fraction of phrases it contains, ignoring case (1 when
+// phrases is empty).
+func ContainsScorer(field string, phrases ...string) ScoringFunction {
+	lowered := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		lowered[i] = strings.ToLower(phrase)
+	}
+
+	return func(inputs map[string]any, prediction *core.Prediction) (float64, error) {
+		text, err := stringField(prediction, field)
+		if err != nil {
+			return 0, err
+		}
+		if len(lowered) == 0 {
+			return 1, nil
+		}
+		text = strings.ToLower(text)
+		found := 0
+		for _, phrase := range lowered {
+			if strings.Contains(text, phrase) {
+				found++
+			}
+		}
+		return float64(found) / float64(len(lowered)), nil
+	}
+}
+
+// stringField returns the string output field of prediction
+func stringField(prediction *core.Prediction, field string) (string, error) {
+	value, exists := prediction.Outputs[field]
+	if !exists {
+		return "", fmt.Errorf("field '%s' not found in outputs", field)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field '%s' is not a string: %T", field, value)
+	}
+	return text, nil
+}
@@ -0,0 +1,95 @@
+package module
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func TestWeightedFieldScorer(t *testing.T) {
+	scorer := WeightedFieldScorer(map[string]float64{"hook_strength": 0.4, "seo_score": 0.6})
+
+	tests := []struct {
+		name    string
+		outputs map[string]any
+		want    float64
+		wantErr bool
+	}{
+		{"numbers", map[string]any{"hook_strength": 0.5, "seo_score": 1}, 0.8, false},
+		{"numeric strings", map[string]any{"hook_strength": "50%", "seo_score": "1.0"}, 0.8, false},
+		{"missing field", map[string]any{"hook_strength": 0.5}, 0, true},
+		{"non-numeric field", map[string]any{"hook_strength": 0.5, "seo_score": "great"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := scorer(nil, core.NewPrediction(tt.outputs))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(score-tt.want) > 1e-9 {
+				t.Errorf("score = %v, want %v", score, tt.want)
+			}
+		})
+	}
+}
+
+func TestLengthScorer(t *testing.T) {
+	scorer := LengthScorer("opening", 4, 0.25)
+
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"one two three four", 1},
+		{"one two three", 0.75},
+		{"one two three four five six", 0.5},
+		{"far too many words to be anywhere near ideal", 0},
+	}
+	for _, tt := range tests {
+		score, err := scorer(nil, core.NewPrediction(map[string]any{"opening": tt.text}))
+		if err != nil {
+			t.Fatalf("LengthScorer(%q) error = %v", tt.text, err)
+		}
+		if score != tt.want {
+			t.Errorf("LengthScorer(%q) = %v, want %v", tt.text, score, tt.want)
+		}
+	}
+
+	if _, err := scorer(nil, core.NewPrediction(map[string]any{"opening": 42})); err == nil {
+		t.Error("expected an error for a non-string field")
+	}
+}
+
+func TestContainsScorer(t *testing.T) {
+	scorer := ContainsScorer("opening", "Review", "feedback")
+
+	score, err := scorer(nil, core.NewPrediction(map[string]any{"opening": "Please review the draft."}))
+	if err != nil || score != 0.5 {
+		t.Errorf("ContainsScorer() = %v, %v, want 0.5", score, err)
+	}
+	if _, err := scorer(nil, core.NewPrediction(map[string]any{})); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
+
+func TestCombineScorers(t *testing.T) {
+	scorer := CombineScorers(
+		WeightedScorer{Scorer: LengthScorer("opening", 3, 0.5), Weight: 0.5},
+		WeightedScorer{Scorer: ContainsScorer("opening", "review"), Weight: 0.5},
+	)
+
+	score, err := scorer(nil, core.NewPrediction(map[string]any{"opening": "Please review this"}))
+	if err != nil || score != 1 {
+		t.Errorf("CombineScorers() = %v, %v, want 1", score, err)
+	}
+
+	errBoom := errors.New("boom")
+	failing := CombineScorers(WeightedScorer{Weight: 1, Scorer: func(map[string]any, *core.Prediction) (float64, error) {
+		return 0, errBoom
+	}})
+	if _, err := failing(nil, core.NewPrediction(nil)); !errors.Is(err, errBoom) {
+		t.Errorf("CombineScorers() error = %v, want the scorer error", err)
+	}
+}
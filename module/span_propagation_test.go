@@ -0,0 +1,134 @@
+package module
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// testSpan is a minimal tracing span carried in the context, like an observe or OTel span
+type testSpan struct {
+	id, parent int
+	name       string
+}
+
+type testSpanKey struct{}
+
+// spanRecorder collects spans from concurrent goroutines
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []testSpan
+}
+
+// start records a span whose parent is the span in ctx and returns ctx carrying it
+func (r *spanRecorder) start(ctx context.Context, name string) context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parent, _ := ctx.Value(testSpanKey{}).(int)
+	id := len(r.spans) + 1
+	r.spans = append(r.spans, testSpan{id: id, parent: parent, name: name})
+	return context.WithValue(ctx, testSpanKey{}, id)
+}
+
+// children returns the spans whose parent is id
+func (r *spanRecorder) children(id int) []testSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []testSpan
+	for _, s := range r.spans {
+		if s.parent == id {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// spanned wraps a module so each Forward runs in a span named name
+type spanned struct {
+	core.Module
+	recorder *spanRecorder
+	name     string
+}
+
+func (s *spanned) Forward(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+	return s.Module.Forward(s.recorder.start(ctx, s.name), inputs)
+}
+
+func TestParallelModules_PropagateSpanContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		build   func(candidate core.Module) core.Module
+		inputs  map[string]any
+	}{
+		{
+			name:    "BestOfN",
+			workers: 4,
+			build: func(candidate core.Module) core.Module {
+				return NewBestOfN(candidate, 4).WithScorer(DefaultScorer()).WithParallel(true)
+			},
+			inputs: map[string]any{},
+		},
+		{
+			name:    "Parallel",
+			workers: 3,
+			build: func(candidate core.Module) core.Module {
+				return NewParallel(candidate).WithMaxWorkers(3)
+			},
+			inputs: map[string]any{"_batch": []map[string]any{{}, {}, {}}},
+		},
+		{
+			name:    "Ensemble",
+			workers: 3,
+			build: func(candidate core.Module) core.Module {
+				return NewEnsemble([]core.Module{candidate, candidate, candidate}, nil)
+			},
+			inputs: map[string]any{},
+		},
+		{
+			name:    "FanOut",
+			workers: 2,
+			build: func(candidate core.Module) core.Module {
+				return NewFanOut(candidate, candidate)
+			},
+			inputs: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &spanRecorder{}
+			leaf := &MockModule{
+				ForwardFunc: func(ctx context.Context, inputs map[string]any) (*core.Prediction, error) {
+					recorder.start(ctx, "lm")
+					return core.NewPrediction(map[string]any{"result": "ok"}), nil
+				},
+			}
+			candidate := &spanned{Module: leaf, recorder: recorder, name: "candidate"}
+			root := &spanned{Module: tt.build(candidate), recorder: recorder, name: "module"}
+
+			if _, err := root.Forward(context.Background(), tt.inputs); err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			top := recorder.children(0)
+			if len(top) != 1 || top[0].name != "module" {
+				t.Fatalf("root spans = %+v, want a single module span", top)
+			}
+			candidates := recorder.children(top[0].id)
+			if len(candidates) != tt.workers {
+				t.Fatalf("module span has %d children, want %d candidates: %+v", len(candidates), tt.workers, recorder.spans)
+			}
+			for _, c := range candidates {
+				if c.name != "candidate" {
+					t.Errorf("unexpected child %q of the module span", c.name)
+				}
+				if lm := recorder.children(c.id); len(lm) != 1 || lm[0].name != "lm" {
+					t.Errorf("candidate span %d children = %+v, want one lm span", c.id, lm)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package module
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// ProgressFunc receives the completion tokens streamed so far and the time since Stream was called
+type ProgressFunc func(tokensSoFar int, elapsed time.Duration)
+
+// streamProgress tracks the tokens of a stream for its OnProgress callback
+// The callback is set by the caller while the streaming goroutine reads it; the counts
+// are only touched by the streaming goroutine.
+type streamProgress struct {
+	model    string
+	started  time.Time
+	callback atomic.Pointer[ProgressFunc]
+
+	estimated int // Tokens counted from chunk content
+	reported  int // Completion tokens from provider usage, preferred when present
+}
+
+func newStreamProgress(model string, started time.Time) *streamProgress {
+	return &streamProgress{model: model, started: started}
+}
+
+// observe counts a chunk the stream has delivered and reports progress
+// Providers that send usage mid-stream give exact counts; otherwise the chunk content
+// is counted with the configured tokenizer (core.CountTokens).
+func (p *streamProgress) observe(chunk core.Chunk) {
+	if p == nil {
+		return
+	}
+	p.estimated += core.CountTokens(p.model, chunk.Content+chunk.Reasoning)
+	if chunk.Usage.CompletionTokens > 0 {
+		p.reported = chunk.Usage.CompletionTokens
+	}
+
+	callback := p.callback.Load()
+	if callback == nil {
+		return
+	}
+	tokens := p.estimated
+	if p.reported > 0 {
+		tokens = p.reported
+	}
+	(*callback)(tokens, time.Since(p.started))
+}
+
+// OnProgress registers fn to be called after each chunk is delivered, e.g. to drive a
+// progress bar for a long generation. Tokens are the provider's completion tokens when
+// it reports usage mid-stream, and are otherwise estimated from the chunk content.
+// fn runs on the streaming goroutine once the chunk has been handed over, so it never
+// delays a chunk already sent but should return quickly. Register it before reading
+// Chunks to see every chunk. It is a no-op for results without a live stream (dry runs).
+func (r *StreamResult) OnProgress(fn ProgressFunc) *StreamResult {
+	if r.progress != nil && fn != nil {
+		r.progress.callback.Store(&fn)
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package module
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func TestStreamResult_OnProgress(t *testing.T) {
+	sig := core.NewSignature("Write").
+		AddInput("topic", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+
+	tests := []struct {
+		name   string
+		chunks []core.Chunk
+		last   int // Tokens reported for the last chunk (0 = estimated)
+	}{
+		{"estimated", []core.Chunk{
+			{Content: "answer: Once upon "},
+			{Content: "a time there was "},
+			{Content: "a streaming model.", FinishReason: "stop"},
+		}, 0},
+		{"provider usage", []core.Chunk{
+			{Content: "answer: Once upon "},
+			{Content: "a time.", FinishReason: "stop", Usage: core.Usage{CompletionTokens: 42, TotalTokens: 60}},
+		}, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewPredict(sig, &mockStreamingLM{chunks: tt.chunks}).
+				Stream(context.Background(), map[string]any{"topic": "stories"})
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+
+			var tokens []int
+			var elapsed time.Duration
+			result.OnProgress(func(tokensSoFar int, since time.Duration) {
+				tokens = append(tokens, tokensSoFar)
+				elapsed = since
+			})
+			for range result.Chunks {
+			}
+			if err := <-result.Errors; err != nil {
+				t.Fatalf("stream error = %v", err)
+			}
+
+			if len(tokens) != len(tt.chunks) {
+				t.Fatalf("progress calls = %d, want one per chunk (%d)", len(tokens), len(tt.chunks))
+			}
+			for i := 1; i < len(tokens); i++ {
+				if tokens[i] <= tokens[i-1] {
+					t.Errorf("tokens = %v, want them to grow with every chunk", tokens)
+				}
+			}
+			if tt.last > 0 && tokens[len(tokens)-1] != tt.last {
+				t.Errorf("last progress = %d tokens, want the reported %d", tokens[len(tokens)-1], tt.last)
+			}
+			if elapsed <= 0 {
+				t.Errorf("elapsed = %v, want the time since Stream", elapsed)
+			}
+		})
+	}
+}
+
+func TestStreamResult_OnProgress_ChainOfThought(t *testing.T) {
+	sig := core.NewSignature("Solve").
+		AddInput("question", core.FieldTypeString, "").
+		AddOutput("answer", core.FieldTypeString, "")
+	lm := &mockStreamingLM{chunks: []core.Chunk{
+		{Content: `{"reasoning": "Two plus two is four.", `},
+		{Content: `"answer": "4"}`, FinishReason: "stop"},
+	}}
+
+	result, err := NewChainOfThought(sig, lm).Stream(context.Background(), map[string]any{"question": "2+2?"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	calls := 0
+	result.OnProgress(func(int, time.Duration) { calls++ })
+	for range result.Chunks {
+	}
+	<-result.Errors
+
+	if calls != 2 {
+		t.Errorf("progress calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,15 @@
+package module
+
+import (
+	"context"
+	"time"
+)
+
+// withTimeout bounds ctx by timeout when it is positive
+// The returned cancel func must always be called.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
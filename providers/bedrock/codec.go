@@ -0,0 +1,481 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/internal/jsonutil"
+)
+
+const (
+	anthropicVersion = "bedrock-2023-05-31"
+	defaultMaxTokens = 4096
+)
+
+// codec translates between DSGo messages and a model family's native Bedrock payload
+type codec interface {
+	supportsTools() bool
+	encodeRequest(messages []core.Message, options *core.GenerateOptions) (map[string]any, error)
+	decodeResponse(body []byte) (*core.GenerateResult, error)
+	newStreamDecoder() streamDecoder
+}
+
+// streamDecoder decodes the JSON payloads of InvokeModelWithResponseStream chunks
+// Decoders may keep state across chunks (e.g. partial tool call arguments)
+type streamDecoder interface {
+	decodeChunk(payload []byte) (core.Chunk, error)
+}
+
+// codecs maps model ID prefixes to their payload codec
+var codecs = []struct {
+	prefix string
+	codec  codec
+}{
+	{"anthropic.", anthropicCodec{}},
+	{"amazon.titan-text", titanCodec{}},
+	{"meta.llama", llamaCodec{}},
+}
+
+// inferenceProfilePrefixes are the region prefixes of cross-region inference profile IDs
+var inferenceProfilePrefixes = []string{"us.", "eu.", "apac.", "us-gov."}
+
+// codecFor returns the codec for a Bedrock model ID or inference profile ID
+func codecFor(modelID string) (codec, error) {
+	id := modelID
+	for _, prefix := range inferenceProfilePrefixes {
+		if strings.HasPrefix(id, prefix) {
+			id = strings.TrimPrefix(id, prefix)
+			break
+		}
+	}
+	for _, c := range codecs {
+		if strings.HasPrefix(id, c.prefix) {
+			return c.codec, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported bedrock model family: %s (supported: anthropic.*, amazon.titan-text-*, meta.llama*)", modelID)
+}
+
+// --- Anthropic (Claude) ---
+
+type anthropicCodec struct{}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []map[string]any `json:"content"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type     string         `json:"type"`
+		Text     string         `json:"text"`
+		Thinking string         `json:"thinking"` // Extended thinking blocks
+		ID       string         `json:"id"`
+		Name     string         `json:"name"`
+		Input    map[string]any `json:"input"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (anthropicCodec) supportsTools() bool { return true }
+
+func (anthropicCodec) encodeRequest(messages []core.Message, options *core.GenerateOptions) (map[string]any, error) {
+	maxTokens := options.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+	// Claude counts extended thinking toward max_tokens, which must exceed the budget,
+	// so the budget is reserved on top of the answer tokens
+	thinking := options.ThinkingBudgetTokens > 0
+	if thinking {
+		maxTokens += options.ThinkingBudgetTokens
+	}
+	req := map[string]any{
+		"anthropic_version": anthropicVersion,
+		"max_tokens":        maxTokens,
+	}
+
+	var system []string
+	converted := make([]*anthropicMessage, 0, len(messages))
+	for _, msg := range messages {
+		role := "user"
+		var blocks []map[string]any
+
+		switch msg.Role {
+		case "system":
+			system = append(system, msg.Content)
+			continue
+		case "tool":
+			blocks = append(blocks, map[string]any{
+				"type":        "tool_result",
+				"tool_use_id": msg.ToolID,
+				"content":     msg.Content,
+			})
+		case "assistant":
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := tc.Arguments
+				if input == nil {
+					input = map[string]any{}
+				}
+				blocks = append(blocks, map[string]any{
+					"type":  "tool_use",
+					"id":    tc.ID,
+					"name":  tc.Name,
+					"input": input,
+				})
+			}
+		default:
+			blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+		}
+
+		// Claude requires alternating roles, so merge consecutive messages (e.g. several tool results)
+		if n := len(converted); n > 0 && converted[n-1].Role == role {
+			converted[n-1].Content = append(converted[n-1].Content, blocks...)
+			continue
+		}
+		converted = append(converted, &anthropicMessage{Role: role, Content: blocks})
+	}
+
+	req["messages"] = converted
+	if len(system) > 0 {
+		req["system"] = strings.Join(system, "\n\n")
+	}
+	// Extended thinking rejects sampling changes; Claude has no effort level, so ReasoningEffort is ignored
+	if thinking {
+		req["thinking"] = map[string]any{"type": "enabled", "budget_tokens": options.ThinkingBudgetTokens}
+	} else {
+		if options.Temperature > 0 {
+			req["temperature"] = options.Temperature
+		}
+		if options.TopP > 0 && options.TopP != 1.0 {
+			req["top_p"] = options.TopP
+		}
+	}
+	if len(options.Stop) > 0 {
+		req["stop_sequences"] = options.Stop
+	}
+
+	if len(options.Tools) > 0 && options.ToolChoice != "none" {
+		tools := make([]map[string]any, 0, len(options.Tools))
+		for _, tool := range options.Tools {
+			tools = append(tools, convertAnthropicTool(&tool))
+		}
+		req["tools"] = tools
+
+		if options.ToolChoice != "" && options.ToolChoice != "auto" {
+			req["tool_choice"] = map[string]any{"type": "tool", "name": options.ToolChoice}
+		}
+	}
+
+	return req, nil
+}
+
+func convertAnthropicTool(tool *core.Tool) map[string]any {
+	return map[string]any{
+		"name":         tool.Name,
+		"description":  tool.Description,
+		"input_schema": tool.ParametersSchema(),
+	}
+}
+
+func (anthropicCodec) decodeResponse(body []byte) (*core.GenerateResult, error) {
+	var resp anthropicResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	result := &core.GenerateResult{
+		FinishReason: anthropicFinishReason(resp.StopReason),
+		Usage: core.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}
+
+	var text, thinking strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			thinking.WriteString(block.Thinking)
+		case "tool_use":
+			result.ToolCalls = append(result.ToolCalls, core.ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: block.Input,
+			})
+		}
+	}
+	result.Content = text.String()
+	result.Reasoning = thinking.String()
+
+	return result, nil
+}
+
+func (anthropicCodec) newStreamDecoder() streamDecoder {
+	return &anthropicStreamDecoder{tools: make(map[int]*partialToolCall)}
+}
+
+// partialToolCall accumulates a tool_use block streamed as input_json_delta fragments
+type partialToolCall struct {
+	id   string
+	name string
+	args strings.Builder
+}
+
+type anthropicStreamDecoder struct {
+	tools map[int]*partialToolCall
+}
+
+func (d *anthropicStreamDecoder) decodeChunk(payload []byte) (core.Chunk, error) {
+	var event struct {
+		Type         string `json:"type"`
+		Index        int    `json:"index"`
+		ContentBlock struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"content_block"`
+		Delta struct {
+			Type        string `json:"type"`
+			Text        string `json:"text"`
+			Thinking    string `json:"thinking"`
+			PartialJSON string `json:"partial_json"`
+			StopReason  string `json:"stop_reason"`
+		} `json:"delta"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return core.Chunk{}, fmt.Errorf("failed to parse stream chunk: %w", err)
+	}
+
+	var chunk core.Chunk
+	switch event.Type {
+	case "content_block_start":
+		if event.ContentBlock.Type == "tool_use" {
+			d.tools[event.Index] = &partialToolCall{id: event.ContentBlock.ID, name: event.ContentBlock.Name}
+		}
+	case "content_block_delta":
+		switch event.Delta.Type {
+		case "text_delta":
+			chunk.Content = event.Delta.Text
+		case "thinking_delta":
+			chunk.Reasoning = event.Delta.Thinking
+		case "input_json_delta":
+			if tool, ok := d.tools[event.Index]; ok {
+				tool.args.WriteString(event.Delta.PartialJSON)
+			}
+		}
+	case "content_block_stop":
+		tool, ok := d.tools[event.Index]
+		if !ok {
+			break
+		}
+		delete(d.tools, event.Index)
+
+		args := map[string]any{}
+		if raw := tool.args.String(); raw != "" {
+			if err := json.Unmarshal([]byte(jsonutil.RepairJSON(raw)), &args); err != nil {
+				return core.Chunk{}, fmt.Errorf("failed to parse tool arguments (after repair): %w", err)
+			}
+		}
+		chunk.ToolCalls = []core.ToolCall{{ID: tool.id, Name: tool.name, Arguments: args}}
+	case "message_delta":
+		chunk.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+	}
+	return chunk, nil
+}
+
+// anthropicFinishReason maps Claude stop reasons to DSGo finish reasons
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "refusal":
+		return core.FinishReasonContentFilter
+	}
+	return reason
+}
+
+// --- Amazon Titan Text ---
+
+type titanCodec struct{}
+
+func (titanCodec) supportsTools() bool { return false }
+
+func (titanCodec) encodeRequest(messages []core.Message, options *core.GenerateOptions) (map[string]any, error) {
+	var prompt strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			prompt.WriteString(msg.Content)
+		case "assistant":
+			prompt.WriteString("Bot: " + msg.Content)
+		default:
+			prompt.WriteString("User: " + msg.Content)
+		}
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("Bot:")
+
+	config := map[string]any{}
+	if options.MaxTokens > 0 {
+		config["maxTokenCount"] = options.MaxTokens
+	}
+	if options.Temperature > 0 {
+		config["temperature"] = options.Temperature
+	}
+	if options.TopP > 0 && options.TopP != 1.0 {
+		config["topP"] = options.TopP
+	}
+	if len(options.Stop) > 0 {
+		config["stopSequences"] = options.Stop
+	}
+
+	return map[string]any{
+		"inputText":            prompt.String(),
+		"textGenerationConfig": config,
+	}, nil
+}
+
+func (titanCodec) decodeResponse(body []byte) (*core.GenerateResult, error) {
+	var resp struct {
+		InputTextTokenCount int `json:"inputTextTokenCount"`
+		Results             []struct {
+			TokenCount       int    `json:"tokenCount"`
+			OutputText       string `json:"outputText"`
+			CompletionReason string `json:"completionReason"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("no results in response")
+	}
+
+	first := resp.Results[0]
+	return &core.GenerateResult{
+		Content:      strings.TrimSpace(first.OutputText),
+		FinishReason: titanFinishReason(first.CompletionReason),
+		Usage: core.Usage{
+			PromptTokens:     resp.InputTextTokenCount,
+			CompletionTokens: first.TokenCount,
+			TotalTokens:      resp.InputTextTokenCount + first.TokenCount,
+		},
+	}, nil
+}
+
+func (c titanCodec) newStreamDecoder() streamDecoder { return c }
+
+func (titanCodec) decodeChunk(payload []byte) (core.Chunk, error) {
+	var event struct {
+		OutputText       string `json:"outputText"`
+		CompletionReason string `json:"completionReason"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return core.Chunk{}, fmt.Errorf("failed to parse stream chunk: %w", err)
+	}
+	return core.Chunk{
+		Content:      event.OutputText,
+		FinishReason: titanFinishReason(event.CompletionReason),
+	}, nil
+}
+
+// titanFinishReason maps Titan completion reasons to DSGo finish reasons
+func titanFinishReason(reason string) string {
+	switch reason {
+	case "FINISH", "STOP_CRITERIA_MET":
+		return "stop"
+	case "LENGTH":
+		return "length"
+	case "CONTENT_FILTERED":
+		return core.FinishReasonContentFilter
+	}
+	return strings.ToLower(reason)
+}
+
+// --- Meta Llama ---
+
+type llamaCodec struct{}
+
+func (llamaCodec) supportsTools() bool { return false }
+
+// encodeRequest renders messages with the Llama 3 chat template
+func (llamaCodec) encodeRequest(messages []core.Message, options *core.GenerateOptions) (map[string]any, error) {
+	var prompt strings.Builder
+	prompt.WriteString("<|begin_of_text|>")
+	for _, msg := range messages {
+		role := msg.Role
+		if role == "tool" {
+			role = "ipython"
+		}
+		prompt.WriteString("<|start_header_id|>" + role + "<|end_header_id|>\n\n")
+		prompt.WriteString(msg.Content)
+		prompt.WriteString("<|eot_id|>")
+	}
+	prompt.WriteString("<|start_header_id|>assistant<|end_header_id|>\n\n")
+
+	req := map[string]any{"prompt": prompt.String()}
+	if options.MaxTokens > 0 {
+		req["max_gen_len"] = options.MaxTokens
+	}
+	if options.Temperature > 0 {
+		req["temperature"] = options.Temperature
+	}
+	if options.TopP > 0 && options.TopP != 1.0 {
+		req["top_p"] = options.TopP
+	}
+	return req, nil
+}
+
+type llamaResponse struct {
+	Generation           string `json:"generation"`
+	PromptTokenCount     int    `json:"prompt_token_count"`
+	GenerationTokenCount int    `json:"generation_token_count"`
+	StopReason           string `json:"stop_reason"`
+}
+
+func (llamaCodec) decodeResponse(body []byte) (*core.GenerateResult, error) {
+	var resp llamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &core.GenerateResult{
+		Content:      strings.TrimSpace(resp.Generation),
+		FinishReason: resp.StopReason,
+		Usage: core.Usage{
+			PromptTokens:     resp.PromptTokenCount,
+			CompletionTokens: resp.GenerationTokenCount,
+			TotalTokens:      resp.PromptTokenCount + resp.GenerationTokenCount,
+		},
+	}, nil
+}
+
+func (c llamaCodec) newStreamDecoder() streamDecoder { return c }
+
+func (llamaCodec) decodeChunk(payload []byte) (core.Chunk, error) {
+	var event llamaResponse
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return core.Chunk{}, fmt.Errorf("failed to parse stream chunk: %w", err)
+	}
+	return core.Chunk{
+		Content:      event.Generation,
+		FinishReason: event.StopReason,
+	}, nil
+}
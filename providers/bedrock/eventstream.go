@@ -0,0 +1,116 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	eventPreludeLength = 12 // total length, headers length, prelude CRC
+	eventTrailerLength = 4  // message CRC
+	maxEventLength     = 16 * 1024 * 1024
+)
+
+// eventMessage is a decoded message from the AWS event stream encoding
+// Only string header values are kept; other header types are skipped
+type eventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readEventMessage reads one binary event stream message from r
+// It returns io.EOF when the stream ends cleanly between messages
+func readEventMessage(r io.Reader) (*eventMessage, error) {
+	prelude := make([]byte, eventPreludeLength)
+	if _, err := io.ReadFull(r, prelude); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated event stream prelude")
+		}
+		return nil, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, fmt.Errorf("event stream prelude checksum mismatch")
+	}
+	if totalLength < eventPreludeLength+eventTrailerLength || totalLength > maxEventLength ||
+		headersLength > totalLength-eventPreludeLength-eventTrailerLength {
+		return nil, fmt.Errorf("invalid event stream message length %d", totalLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(r, message[eventPreludeLength:]); err != nil {
+		return nil, fmt.Errorf("truncated event stream message: %w", err)
+	}
+
+	crcOffset := totalLength - eventTrailerLength
+	if crc32.ChecksumIEEE(message[:crcOffset]) != binary.BigEndian.Uint32(message[crcOffset:]) {
+		return nil, fmt.Errorf("event stream message checksum mismatch")
+	}
+
+	headersEnd := eventPreludeLength + headersLength
+	headers, err := decodeEventHeaders(message[eventPreludeLength:headersEnd])
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventMessage{
+		Headers: headers,
+		Payload: message[headersEnd:crcOffset],
+	}, nil
+}
+
+// decodeEventHeaders decodes the header block of an event stream message
+func decodeEventHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, fmt.Errorf("truncated event stream header")
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true / false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // int16
+			size = 2
+		case 4: // int32
+			size = 4
+		case 5, 8: // int64, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // byte array, string
+			if len(data) < 2 {
+				return nil, fmt.Errorf("truncated event stream header %q", name)
+			}
+			length := int(binary.BigEndian.Uint16(data[0:2]))
+			data = data[2:]
+			if len(data) < length {
+				return nil, fmt.Errorf("truncated event stream header %q", name)
+			}
+			if valueType == 7 {
+				headers[name] = string(data[:length])
+			}
+			size = length
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %d for %q", valueType, name)
+		}
+
+		if len(data) < size {
+			return nil, fmt.Errorf("truncated event stream header %q", name)
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,374 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/internal/retry"
+	"github.com/assagman/dsgo/logging"
+)
+
+func init() {
+	core.RegisterLM("bedrock", func(model string) core.LM {
+		return newBedrock(model)
+	})
+}
+
+// bedrock implements the LM interface for models served by AWS Bedrock
+// The payload format is chosen per model family (see codecFor)
+type bedrock struct {
+	Model   string
+	Region  string
+	BaseURL string // Defaults to the regional bedrock-runtime endpoint
+	Client  *http.Client
+	Cache   core.Cache
+}
+
+// newBedrock creates a new Bedrock LM
+// The region comes from dsgo.WithRegion, then AWS_REGION, then AWS_DEFAULT_REGION
+func newBedrock(model string) *bedrock {
+	region := core.GetSettings().Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return &bedrock{
+		Model:   model,
+		Region:  region,
+		BaseURL: core.BaseURLFor("bedrock", ""),
+		Client:  core.HTTPClientFor("bedrock"),
+	}
+}
+
+// Name returns the model name
+func (b *bedrock) Name() string {
+	return b.Model
+}
+
+// SupportsJSON indicates Bedrock has no native JSON mode for these model families
+func (b *bedrock) SupportsJSON() bool {
+	return false
+}
+
+// SupportsTools reports whether the model family supports tool calling
+func (b *bedrock) SupportsTools() bool {
+	c, err := codecFor(b.Model)
+	return err == nil && c.supportsTools()
+}
+
+// Capabilities reports what the model supports
+// Image inputs and JSON mode are not implemented by this provider yet.
+func (b *bedrock) Capabilities() core.Capabilities {
+	window, _ := core.ContextWindowFor(b.Model)
+	return core.Capabilities{
+		SupportsTools:     b.SupportsTools(),
+		SupportsStreaming: true,
+		MaxContextTokens:  window,
+	}
+}
+
+// HealthCheck sends a one-token request, bypassing the cache, to verify credentials,
+// region and model access; the bedrock runtime has no cheaper models endpoint
+func (b *bedrock) HealthCheck(ctx context.Context) error {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
+
+	c, err := codecFor(b.Model)
+	if err != nil {
+		return err
+	}
+	resp, err := b.invoke(ctx, c, "invoke", []core.Message{{Role: "user", Content: "ping"}}, &core.GenerateOptions{MaxTokens: 1})
+	if core.StatusCode(err) == http.StatusNotFound {
+		return fmt.Errorf("%w: bedrock does not serve %q in %s: %w", core.ErrModelNotFound, b.Model, b.Region, err)
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// SetCache sets the cache instance for this LM
+func (b *bedrock) SetCache(cache core.Cache) {
+	b.Cache = cache
+}
+
+// Generate generates a response using InvokeModel, retrying empty completions
+// (see core.RetryEmptyResponses)
+func (b *bedrock) Generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	return core.RetryEmptyResponses(ctx, "bedrock", func() (*core.GenerateResult, error) {
+		return b.generate(ctx, messages, options)
+	})
+}
+
+// generate makes one Generate request
+func (b *bedrock) generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
+	startTime := time.Now()
+
+	// Calculate prompt length for logging
+	promptLength := 0
+	for _, msg := range messages {
+		promptLength += len(msg.Content)
+	}
+
+	// Log API request start
+	logging.LogAPIRequest(ctx, b.Model, promptLength)
+
+	if core.HasImages(messages) {
+		return nil, fmt.Errorf("%w: %s (the bedrock provider does not support image inputs yet)", core.ErrImageInputUnsupported, b.Model)
+	}
+
+	// Check cache if available
+	if b.Cache != nil {
+		cacheKey := core.GenerateCacheKey(b.Model, messages, options)
+		if cached, ok := b.Cache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	c, err := codecFor(b.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.invoke(ctx, c, "invoke", messages, options)
+	if err != nil {
+		logging.LogAPIError(ctx, b.Model, err)
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logging.LogAPIError(ctx, b.Model, err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	result, err := c.decodeResponse(body)
+	if core.RawCaptureDir() != "" {
+		// The request is re-encoded only when capturing; encoding is deterministic
+		request, _ := c.encodeRequest(messages, options)
+		b.captureRaw(ctx, request, resp, body, err)
+	}
+	if err == nil {
+		err = core.CheckContentFilter("bedrock", result.FinishReason, "")
+	}
+	if err != nil {
+		logging.LogAPIError(ctx, b.Model, err)
+		return nil, err
+	}
+	core.FillCost(b.Model, &result.Usage)
+
+	result.Metadata = map[string]any{}
+	if requestID := resp.Header.Get("X-Amzn-Requestid"); requestID != "" {
+		result.Metadata["request_id"] = requestID
+	}
+
+	// Log API response
+	logging.LogAPIResponse(ctx, b.Model, resp.StatusCode, time.Since(startTime), result.Usage)
+
+	// Store in cache if available; empty completions are retried instead
+	if b.Cache != nil && !core.IsEmptyResult(result) {
+		cacheKey := core.GenerateCacheKey(b.Model, messages, options)
+		b.Cache.Set(cacheKey, result)
+	}
+
+	return result, nil
+}
+
+// Stream generates a streaming response using InvokeModelWithResponseStream
+func (b *bedrock) Stream(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (<-chan core.Chunk, <-chan error) {
+	chunkChan := make(chan core.Chunk)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+
+		if core.HasImages(messages) {
+			errChan <- fmt.Errorf("%w: %s (the bedrock provider does not support image inputs yet)", core.ErrImageInputUnsupported, b.Model)
+			return
+		}
+
+		c, err := codecFor(b.Model)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		resp, err := b.invoke(ctx, c, "invoke-with-response-stream", messages, options)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		decoder := c.newStreamDecoder()
+		for {
+			msg, err := readEventMessage(resp.Body)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					errChan <- ctx.Err()
+					return
+				}
+				errChan <- fmt.Errorf("stream reading error: %w", err)
+				return
+			}
+
+			if msg.Headers[":message-type"] == "exception" {
+				errChan <- fmt.Errorf("stream error %s: %s", msg.Headers[":exception-type"], string(msg.Payload))
+				return
+			}
+			if msg.Headers[":event-type"] != "chunk" {
+				continue
+			}
+
+			var event struct {
+				Bytes []byte `json:"bytes"` // base64-encoded model payload
+			}
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				errChan <- fmt.Errorf("failed to parse stream event: %w", err)
+				return
+			}
+
+			chunk, err := decoder.decodeChunk(event.Bytes)
+			if err == nil {
+				err = core.CheckContentFilter("bedrock", chunk.FinishReason, "")
+			}
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			// Bedrock appends invocation metrics to the final chunk of every model family
+			var metrics struct {
+				InvocationMetrics *struct {
+					InputTokenCount  int `json:"inputTokenCount"`
+					OutputTokenCount int `json:"outputTokenCount"`
+				} `json:"amazon-bedrock-invocationMetrics"`
+			}
+			if err := json.Unmarshal(event.Bytes, &metrics); err == nil && metrics.InvocationMetrics != nil {
+				chunk.Usage = core.Usage{
+					PromptTokens:     metrics.InvocationMetrics.InputTokenCount,
+					CompletionTokens: metrics.InvocationMetrics.OutputTokenCount,
+					TotalTokens:      metrics.InvocationMetrics.InputTokenCount + metrics.InvocationMetrics.OutputTokenCount,
+				}
+				core.FillCost(b.Model, &chunk.Usage)
+			}
+
+			if chunk.Content == "" && chunk.Reasoning == "" && chunk.FinishReason == "" && len(chunk.ToolCalls) == 0 && chunk.Usage.TotalTokens == 0 {
+				continue
+			}
+
+			select {
+			case chunkChan <- chunk:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return chunkChan, errChan
+}
+
+// invoke encodes the request, signs it and sends it to the given model action
+// Non-200 responses are returned as errors
+func (b *bedrock) invoke(ctx context.Context, c codec, action string, messages []core.Message, options *core.GenerateOptions) (*http.Response, error) {
+	if b.Region == "" {
+		return nil, fmt.Errorf("AWS region is required for bedrock: set AWS_REGION or use dsgo.WithRegion")
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := c.encodeRequest(messages, options)
+	if err != nil {
+		return nil, err
+	}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint, err := b.endpoint(action)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint.String(), bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if action == "invoke-with-response-stream" {
+			req.Header.Set("Accept", "application/vnd.amazon.eventstream")
+		}
+		core.SetRequestHeaders(ctx, req)
+		// Sign on every attempt so retries carry a fresh timestamp
+		signRequest(req, bodyBytes, creds, b.Region, serviceName, time.Now())
+		return b.Client.Do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		err := core.NewAPIError("bedrock", resp.StatusCode, string(body), resp.Header)
+		b.captureRaw(ctx, reqBody, resp, body, err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// captureRaw saves the exchange when raw response capture is enabled (see core.WithRawResponseCapture)
+func (b *bedrock) captureRaw(ctx context.Context, request any, resp *http.Response, body []byte, err error) {
+	_, saveErr := core.CaptureRawExchange(core.RawExchange{
+		Provider:   "bedrock",
+		Model:      b.Model,
+		RequestID:  logging.GetRequestID(ctx),
+		Request:    request,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Err:        err,
+	})
+	if saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save raw exchange: %v\n", saveErr)
+	}
+}
+
+// endpoint builds the model action URL, escaping the model ID (which may contain ':')
+func (b *bedrock) endpoint(action string) (*url.URL, error) {
+	base := b.BaseURL
+	if base == "" {
+		base = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", b.Region)
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bedrock endpoint %q: %w", base, err)
+	}
+	u.Path = "/model/" + b.Model + "/" + action
+	u.RawPath = "/model/" + uriEncode(b.Model) + "/" + action
+	return u, nil
+}
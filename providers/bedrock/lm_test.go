@@ -0,0 +1,530 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// encodeEventMessage encodes a message in the AWS event stream format with string headers
+func encodeEventMessage(headers map[string]string, payload []byte) []byte {
+	var hdr bytes.Buffer
+	for name, value := range headers {
+		hdr.WriteByte(byte(len(name)))
+		hdr.WriteString(name)
+		hdr.WriteByte(7)
+		_ = binary.Write(&hdr, binary.BigEndian, uint16(len(value)))
+		hdr.WriteString(value)
+	}
+
+	total := eventPreludeLength + hdr.Len() + len(payload) + eventTrailerLength
+	msg := make([]byte, 0, total)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(total))
+	msg = binary.BigEndian.AppendUint32(msg, uint32(hdr.Len()))
+	msg = binary.BigEndian.AppendUint32(msg, crc32.ChecksumIEEE(msg))
+	msg = append(msg, hdr.Bytes()...)
+	msg = append(msg, payload...)
+	return binary.BigEndian.AppendUint32(msg, crc32.ChecksumIEEE(msg))
+}
+
+// chunkEvent wraps a model payload as a Bedrock "chunk" event
+func chunkEvent(payload string) []byte {
+	body, _ := json.Marshal(map[string][]byte{"bytes": []byte(payload)})
+	return encodeEventMessage(map[string]string{
+		":message-type": "event",
+		":event-type":   "chunk",
+		":content-type": "application/json",
+	}, body)
+}
+
+// newTestBedrock returns a bedrock LM pointed at server with static credentials
+func newTestBedrock(t *testing.T, model string, server *httptest.Server) *bedrock {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	b := newBedrock(model)
+	b.Region = "us-west-2"
+	b.BaseURL = server.URL
+	return b
+}
+
+func TestNewLM_Bedrock(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	lm, err := core.NewLM(context.Background(), "bedrock/anthropic.claude-3-5-sonnet-20240620-v1:0")
+	if err != nil {
+		t.Fatalf("NewLM() error = %v", err)
+	}
+	if lm.Name() != "anthropic.claude-3-5-sonnet-20240620-v1:0" {
+		t.Errorf("Name() = %s", lm.Name())
+	}
+	if !lm.SupportsTools() || lm.SupportsJSON() {
+		t.Error("expected tool support without native JSON mode for Claude")
+	}
+
+	if got := newBedrock("meta.llama3-8b-instruct-v1:0").Region; got != "eu-west-1" {
+		t.Errorf("Region = %q, want AWS_REGION", got)
+	}
+	core.Configure(core.WithRegion("ap-northeast-1"))
+	if got := newBedrock("meta.llama3-8b-instruct-v1:0").Region; got != "ap-northeast-1" {
+		t.Errorf("Region = %q, want WithRegion to take precedence", got)
+	}
+}
+
+func TestBedrock_Generate_Codecs(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		response   string
+		checkReq   func(t *testing.T, req map[string]any)
+		wantText   string
+		wantFinish string
+		wantUsage  core.Usage
+	}{
+		{
+			name:     "anthropic",
+			model:    "anthropic.claude-3-5-sonnet-20240620-v1:0",
+			response: `{"content":[{"type":"text","text":"Paris"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`,
+			checkReq: func(t *testing.T, req map[string]any) {
+				if req["anthropic_version"] != anthropicVersion || req["system"] != "Be brief." {
+					t.Errorf("unexpected request: %v", req)
+				}
+				msgs := req["messages"].([]any)
+				if len(msgs) != 1 || msgs[0].(map[string]any)["role"] != "user" {
+					t.Errorf("expected a single user message, got %v", msgs)
+				}
+			},
+			wantText:   "Paris",
+			wantFinish: "stop",
+			wantUsage:  core.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+		},
+		{
+			name:     "titan",
+			model:    "amazon.titan-text-express-v1",
+			response: `{"inputTextTokenCount":8,"results":[{"tokenCount":2,"outputText":" Paris","completionReason":"LENGTH"}]}`,
+			checkReq: func(t *testing.T, req map[string]any) {
+				if !strings.HasSuffix(req["inputText"].(string), "User: Capital of France?\n\nBot:") {
+					t.Errorf("unexpected inputText: %q", req["inputText"])
+				}
+				if req["textGenerationConfig"].(map[string]any)["maxTokenCount"] != float64(64) {
+					t.Errorf("unexpected config: %v", req["textGenerationConfig"])
+				}
+			},
+			wantText:   "Paris",
+			wantFinish: "length",
+			wantUsage:  core.Usage{PromptTokens: 8, CompletionTokens: 2, TotalTokens: 10},
+		},
+		{
+			name:     "llama via inference profile",
+			model:    "us.meta.llama3-1-8b-instruct-v1:0",
+			response: `{"generation":"Paris","prompt_token_count":20,"generation_token_count":1,"stop_reason":"stop"}`,
+			checkReq: func(t *testing.T, req map[string]any) {
+				prompt := req["prompt"].(string)
+				if !strings.Contains(prompt, "<|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|>") ||
+					!strings.HasSuffix(prompt, "<|start_header_id|>assistant<|end_header_id|>\n\n") {
+					t.Errorf("unexpected prompt: %q", prompt)
+				}
+				if req["max_gen_len"] != float64(64) {
+					t.Errorf("unexpected max_gen_len: %v", req["max_gen_len"])
+				}
+			},
+			wantText:   "Paris",
+			wantFinish: "stop",
+			wantUsage:  core.Usage{PromptTokens: 20, CompletionTokens: 1, TotalTokens: 21},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/model/" + uriEncode(tt.model) + "/invoke"; r.URL.EscapedPath() != want {
+					t.Errorf("path = %s, want %s", r.URL.EscapedPath(), want)
+				}
+				if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+					!strings.Contains(r.Header.Get("Authorization"), "/us-west-2/bedrock/aws4_request") {
+					t.Errorf("unexpected Authorization: %s", r.Header.Get("Authorization"))
+				}
+
+				var req map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("failed to decode request: %v", err)
+				}
+				tt.checkReq(t, req)
+
+				w.Header().Set("X-Amzn-Requestid", "req-1")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			b := newTestBedrock(t, tt.model, server)
+			options := core.DefaultGenerateOptions()
+			options.MaxTokens = 64
+			result, err := b.Generate(context.Background(), []core.Message{
+				{Role: "system", Content: "Be brief."},
+				{Role: "user", Content: "Capital of France?"},
+			}, options)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if result.Content != tt.wantText || result.FinishReason != tt.wantFinish {
+				t.Errorf("result = %q (%s), want %q (%s)", result.Content, result.FinishReason, tt.wantText, tt.wantFinish)
+			}
+			if result.Usage.PromptTokens != tt.wantUsage.PromptTokens ||
+				result.Usage.CompletionTokens != tt.wantUsage.CompletionTokens ||
+				result.Usage.TotalTokens != tt.wantUsage.TotalTokens {
+				t.Errorf("Usage = %+v, want %+v", result.Usage, tt.wantUsage)
+			}
+			if result.Metadata["request_id"] != "req-1" {
+				t.Errorf("expected request_id metadata, got %v", result.Metadata)
+			}
+		})
+	}
+}
+
+func TestBedrock_Generate_AnthropicTools(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&captured)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"tu_2","name":"weather","input":{"city":"Oslo"}}],"stop_reason":"tool_use","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	b := newTestBedrock(t, "anthropic.claude-3-haiku-20240307-v1:0", server)
+	options := core.DefaultGenerateOptions()
+	options.Tools = []core.Tool{*core.NewTool("weather", "Get weather", nil).AddParameter("city", "string", "City", true)}
+
+	result, err := b.Generate(context.Background(), []core.Message{
+		{Role: "user", Content: "Weather in Paris and Rome?"},
+		{Role: "assistant", ToolCalls: []core.ToolCall{
+			{ID: "tu_0", Name: "weather", Arguments: map[string]any{"city": "Paris"}},
+			{ID: "tu_1", Name: "weather", Arguments: map[string]any{"city": "Rome"}},
+		}},
+		{Role: "tool", ToolID: "tu_0", Content: "sunny"},
+		{Role: "tool", ToolID: "tu_1", Content: "rainy"},
+	}, options)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if result.FinishReason != "tool_calls" || len(result.ToolCalls) != 1 || result.ToolCalls[0].Arguments["city"] != "Oslo" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	msgs := captured["messages"].([]any)
+	if len(msgs) != 3 {
+		t.Fatalf("expected tool results merged into one user message, got %d messages", len(msgs))
+	}
+	results := msgs[2].(map[string]any)["content"].([]any)
+	if len(results) != 2 || results[1].(map[string]any)["tool_use_id"] != "tu_1" {
+		t.Errorf("unexpected tool results: %v", results)
+	}
+	tools := captured["tools"].([]any)
+	if tools[0].(map[string]any)["input_schema"] == nil {
+		t.Errorf("expected input_schema on tool, got %v", tools[0])
+	}
+}
+
+func TestBedrock_Generate_AnthropicThinking(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&captured)
+		_, _ = w.Write([]byte(`{"content":[{"type":"thinking","thinking":"Add them."},{"type":"text","text":"4"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	b := newTestBedrock(t, "anthropic.claude-sonnet-4-20250514-v1:0", server)
+	options := core.DefaultGenerateOptions()
+	options.MaxTokens = 1000
+	options.Temperature = 0.2
+	options.ThinkingBudgetTokens = 2000
+
+	result, err := b.Generate(context.Background(), []core.Message{{Role: "user", Content: "2+2?"}}, options)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Reasoning != "Add them." || result.Content != "4" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	thinking, _ := captured["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != float64(2000) {
+		t.Errorf("thinking = %v, want enabled with a 2000 token budget", captured["thinking"])
+	}
+	if captured["max_tokens"] != float64(3000) {
+		t.Errorf("max_tokens = %v, want the budget reserved on top of MaxTokens", captured["max_tokens"])
+	}
+	if _, ok := captured["temperature"]; ok {
+		t.Error("temperature should not be sent with extended thinking")
+	}
+}
+
+func TestBedrock_Stream(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		events     []string
+		wantText   string
+		wantFinish string
+		wantTools  int
+	}{
+		{
+			name:  "anthropic",
+			model: "anthropic.claude-3-5-sonnet-20240620-v1:0",
+			events: []string{
+				`{"type":"message_start","message":{"usage":{"input_tokens":10}}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"lo"}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"tu_1","name":"search"}}`,
+				`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+				`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"go\"}"}}`,
+				`{"type":"content_block_stop","index":1}`,
+				`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":5}}`,
+				`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":5}}`,
+			},
+			wantText:   "Hello",
+			wantFinish: "tool_calls",
+			wantTools:  1,
+		},
+		{
+			name:  "titan",
+			model: "amazon.titan-text-lite-v1",
+			events: []string{
+				`{"outputText":"Hel","index":0}`,
+				`{"outputText":"lo","index":0,"completionReason":"FINISH","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":5}}`,
+			},
+			wantText:   "Hello",
+			wantFinish: "stop",
+		},
+		{
+			name:  "llama",
+			model: "meta.llama3-8b-instruct-v1:0",
+			events: []string{
+				`{"generation":"Hel","stop_reason":null}`,
+				`{"generation":"lo","stop_reason":"stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":5}}`,
+			},
+			wantText:   "Hello",
+			wantFinish: "stop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/invoke-with-response-stream") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+				for _, event := range tt.events {
+					_, _ = w.Write(chunkEvent(event))
+				}
+			}))
+			defer server.Close()
+
+			b := newTestBedrock(t, tt.model, server)
+			chunks, errs := b.Stream(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions())
+
+			var text strings.Builder
+			var finish string
+			var usage core.Usage
+			var toolCalls []core.ToolCall
+			for chunk := range chunks {
+				text.WriteString(chunk.Content)
+				toolCalls = append(toolCalls, chunk.ToolCalls...)
+				if chunk.FinishReason != "" {
+					finish = chunk.FinishReason
+				}
+				if chunk.Usage.TotalTokens > 0 {
+					usage = chunk.Usage
+				}
+			}
+			if err := <-errs; err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+
+			if text.String() != tt.wantText || finish != tt.wantFinish {
+				t.Errorf("got %q (%s), want %q (%s)", text.String(), finish, tt.wantText, tt.wantFinish)
+			}
+			if usage.PromptTokens != 10 || usage.CompletionTokens != 5 || usage.TotalTokens != 15 {
+				t.Errorf("Usage = %+v", usage)
+			}
+			if len(toolCalls) != tt.wantTools {
+				t.Fatalf("expected %d tool calls, got %v", tt.wantTools, toolCalls)
+			}
+			if tt.wantTools > 0 && toolCalls[0].Arguments["q"] != "go" {
+				t.Errorf("unexpected tool call: %+v", toolCalls[0])
+			}
+		})
+	}
+}
+
+func TestBedrock_Stream_AnthropicThinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, event := range []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":10}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Add "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"them."}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"4"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+			`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":5}}`,
+		} {
+			_, _ = w.Write(chunkEvent(event))
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBedrock(t, "anthropic.claude-3-7-sonnet-20250219-v1:0", server)
+	chunks, errs := b.Stream(context.Background(), []core.Message{{Role: "user", Content: "2+2?"}}, core.DefaultGenerateOptions())
+
+	var text, reasoning strings.Builder
+	for chunk := range chunks {
+		text.WriteString(chunk.Content)
+		reasoning.WriteString(chunk.Reasoning)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if reasoning.String() != "Add them." || text.String() != "4" {
+		t.Errorf("got reasoning %q and text %q, want %q and %q", reasoning.String(), text.String(), "Add them.", "4")
+	}
+}
+
+func TestBedrock_Stream_Exception(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(chunkEvent(`{"outputText":"partial"}`))
+		_, _ = w.Write(encodeEventMessage(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, []byte(`{"message":"slow down"}`)))
+	}))
+	defer server.Close()
+
+	b := newTestBedrock(t, "amazon.titan-text-express-v1", server)
+	chunks, errs := b.Stream(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions())
+	for range chunks {
+	}
+	err := <-errs
+	if err == nil || !strings.Contains(err.Error(), "throttlingException") {
+		t.Errorf("expected throttling error, got %v", err)
+	}
+}
+
+func TestBedrock_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"The security token included in the request is invalid."}`))
+	}))
+	defer server.Close()
+
+	messages := []core.Message{{Role: "user", Content: "hi"}}
+
+	b := newTestBedrock(t, "anthropic.claude-3-haiku-20240307-v1:0", server)
+	if _, err := b.Generate(context.Background(), messages, core.DefaultGenerateOptions()); err == nil ||
+		!strings.Contains(err.Error(), "API request failed with status 403") {
+		t.Errorf("expected 403 error, got %v", err)
+	}
+
+	b.Region = ""
+	if _, err := b.Generate(context.Background(), messages, core.DefaultGenerateOptions()); err == nil ||
+		!strings.Contains(err.Error(), "AWS region is required") {
+		t.Errorf("expected missing region error, got %v", err)
+	}
+
+	unsupported := newTestBedrock(t, "cohere.command-r-v1:0", server)
+	if _, err := unsupported.Generate(context.Background(), messages, core.DefaultGenerateOptions()); err == nil ||
+		!strings.Contains(err.Error(), "unsupported bedrock model family") {
+		t.Errorf("expected unsupported family error, got %v", err)
+	}
+
+	images := []core.Message{{Role: "user", Content: "hi", Images: []core.ImageContent{core.NewImageFromURL("https://example.com/a.png")}}}
+	if _, err := b.Generate(context.Background(), images, core.DefaultGenerateOptions()); !errors.Is(err, core.ErrImageInputUnsupported) {
+		t.Errorf("expected ErrImageInputUnsupported, got %v", err)
+	}
+}
+
+func TestBedrock_Generate_ContentFiltered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"content":[],"stop_reason":"refusal","usage":{"input_tokens":12,"output_tokens":0}}`))
+	}))
+	defer server.Close()
+
+	b := newTestBedrock(t, "anthropic.claude-3-haiku-20240307-v1:0", server)
+	_, err := b.Generate(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions())
+	var filterErr *core.ContentFilterError
+	if !errors.As(err, &filterErr) || filterErr.Provider != "bedrock" {
+		t.Fatalf("expected bedrock *core.ContentFilterError, got %v", err)
+	}
+}
+
+func TestReadEventMessage(t *testing.T) {
+	valid := encodeEventMessage(map[string]string{":event-type": "chunk"}, []byte("payload"))
+
+	msg, err := readEventMessage(bytes.NewReader(valid))
+	if err != nil {
+		t.Fatalf("readEventMessage() error = %v", err)
+	}
+	if msg.Headers[":event-type"] != "chunk" || string(msg.Payload) != "payload" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if _, err := readEventMessage(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("expected io.EOF on empty stream, got %v", err)
+	}
+
+	corrupt := append([]byte{}, valid...)
+	corrupt[len(corrupt)-6] ^= 0xff
+	if _, err := readEventMessage(bytes.NewReader(corrupt)); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+
+	if _, err := readEventMessage(bytes.NewReader(valid[:len(valid)-3])); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
+
+func TestBedrock_HealthCheck(t *testing.T) {
+	model := "anthropic.claude-3-haiku-20240307-v1:0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/"+uriEncode(model)+"/invoke" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Could not resolve the foundation model"}`))
+			return
+		}
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["max_tokens"] != 1.0 {
+			t.Errorf("max_tokens = %v, want 1", req["max_tokens"])
+		}
+		_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "p"}], "stop_reason": "max_tokens", "usage": {"input_tokens": 8, "output_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	if err := newTestBedrock(t, model, server).HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+	if err := newTestBedrock(t, "anthropic.claude-9", server).HealthCheck(context.Background()); !errors.Is(err, core.ErrModelNotFound) {
+		t.Errorf("HealthCheck() error = %v, want ErrModelNotFound", err)
+	}
+}
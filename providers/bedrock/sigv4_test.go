@@ -0,0 +1,130 @@
+package bedrock
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignRequest_AWSTestSuiteVector(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signRequest(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSignRequest_SessionTokenAndContentType(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://bedrock-runtime.us-east-1.amazonaws.com/model/x/invoke", nil)
+	req.Header.Set("Content-Type", "application/json")
+	signRequest(req, []byte("{}"), credentials{AccessKeyID: "AK", SecretAccessKey: "SK", SessionToken: "TOKEN"}, "us-east-1", serviceName, time.Now())
+
+	if req.Header.Get("X-Amz-Security-Token") != "TOKEN" {
+		t.Error("expected session token header")
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers: %s", auth)
+	}
+}
+
+func TestCanonicalURI_DoubleEncodesModelID(t *testing.T) {
+	b := &bedrock{Model: "anthropic.claude-3-5-sonnet-20240620-v1:0", BaseURL: "https://bedrock-runtime.us-east-1.amazonaws.com"}
+	u, err := b.endpoint("invoke")
+	if err != nil {
+		t.Fatalf("endpoint() error = %v", err)
+	}
+	if got := u.String(); got != "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/invoke" {
+		t.Errorf("endpoint = %s", got)
+	}
+
+	parsed, _ := url.Parse(u.String())
+	if got := canonicalURI(parsed); got != "/model/anthropic.claude-3-5-sonnet-20240620-v1%253A0/invoke" {
+		t.Errorf("canonicalURI = %s", got)
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+	content := `[default]
+aws_access_key_id = DEFAULTKEY
+aws_secret_access_key = defaultsecret
+
+[work]
+aws_access_key_id=WORKKEY
+aws_secret_access_key=worksecret
+aws_session_token=worktoken
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    credentials
+		wantErr bool
+	}{
+		{
+			name: "environment takes precedence",
+			env:  map[string]string{"AWS_ACCESS_KEY_ID": "ENVKEY", "AWS_SECRET_ACCESS_KEY": "envsecret", "AWS_SESSION_TOKEN": "envtoken"},
+			want: credentials{AccessKeyID: "ENVKEY", SecretAccessKey: "envsecret", SessionToken: "envtoken"},
+		},
+		{
+			name: "default profile",
+			want: credentials{AccessKeyID: "DEFAULTKEY", SecretAccessKey: "defaultsecret"},
+		},
+		{
+			name: "named profile",
+			env:  map[string]string{"AWS_PROFILE": "work"},
+			want: credentials{AccessKeyID: "WORKKEY", SecretAccessKey: "worksecret", SessionToken: "worktoken"},
+		},
+		{
+			name:    "missing profile",
+			env:     map[string]string{"AWS_PROFILE": "missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE"} {
+				t.Setenv(key, "")
+			}
+			t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := loadCredentials()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCredentials() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("loadCredentials() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
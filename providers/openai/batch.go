@@ -0,0 +1,324 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/internal/retry"
+	"github.com/assagman/dsgo/logging"
+)
+
+const (
+	batchEndpoint          = "/v1/chat/completions"
+	batchCompletionWindow  = "24h"
+	defaultBatchPollPeriod = 30 * time.Second
+
+	// batchCostFactor reflects the Batch API's 50% discount on computed costs
+	batchCostFactor = 0.5
+)
+
+// batchJob is the subset of the OpenAI batch object used for polling
+type batchJob struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	OutputFileID string `json:"output_file_id"`
+	ErrorFileID  string `json:"error_file_id"`
+	Errors       *struct {
+		Data []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"errors"`
+}
+
+// batchOutputLine is one line of a batch output or error file
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GenerateBatch runs requests through the OpenAI Batch API
+// It uploads a JSONL file, creates a batch job, polls until the job finishes and
+// maps the output back to request IDs. Requests missing from the output (e.g. when
+// the job expired) are returned with an error. With a key pool, one key serves the
+// whole job, since files can only be read with the key that uploaded them.
+func (o *openAI) GenerateBatch(ctx context.Context, requests []core.BatchRequest) ([]core.BatchResult, error) {
+	results := make([]core.BatchResult, len(requests))
+	index := make(map[string]int, len(requests))
+
+	var input bytes.Buffer
+	for i, req := range requests {
+		results[i].ID = req.ID
+		if err := o.checkImageSupport(req.Messages); err != nil {
+			results[i].Err = err
+			continue
+		}
+		line, err := json.Marshal(map[string]any{
+			"custom_id": req.ID,
+			"method":    "POST",
+			"url":       batchEndpoint,
+			"body":      o.buildRequest(req.Messages, req.Options),
+		})
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to marshal request: %w", err)
+			continue
+		}
+		input.Write(line)
+		input.WriteByte('\n')
+		index[req.ID] = i
+	}
+	if len(index) == 0 {
+		return results, nil
+	}
+
+	logging.LogAPIRequest(ctx, o.Model, input.Len())
+
+	apiKey := o.APIKey
+	if o.Keys != nil {
+		apiKey = o.Keys.Next()
+	}
+
+	fileID, err := o.uploadBatchFile(ctx, apiKey, input.Bytes())
+	if err != nil {
+		logging.LogAPIError(ctx, o.Model, err)
+		return nil, err
+	}
+
+	var job batchJob
+	if err := o.batchRequest(ctx, apiKey, "POST", "/batches", map[string]any{
+		"input_file_id":     fileID,
+		"endpoint":          batchEndpoint,
+		"completion_window": batchCompletionWindow,
+	}, &job); err != nil {
+		logging.LogAPIError(ctx, o.Model, err)
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	if err := o.waitForBatch(ctx, apiKey, &job); err != nil {
+		logging.LogAPIError(ctx, o.Model, err)
+		return nil, err
+	}
+	if job.Status == "failed" {
+		err := fmt.Errorf("batch %s failed%s", job.ID, batchErrorSummary(&job))
+		logging.LogAPIError(ctx, o.Model, err)
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(index))
+	for _, fileID := range []string{job.OutputFileID, job.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		lines, err := o.downloadBatchFile(ctx, apiKey, fileID)
+		if err != nil {
+			logging.LogAPIError(ctx, o.Model, err)
+			return nil, err
+		}
+		for _, line := range lines {
+			i, ok := index[line.CustomID]
+			if !ok {
+				continue
+			}
+			found[line.CustomID] = true
+			results[i].Result, results[i].Err = o.parseBatchLine(line, job.ID)
+		}
+	}
+
+	for id, i := range index {
+		if !found[id] {
+			results[i].Err = fmt.Errorf("batch %s ended with status %q without a result for this request", job.ID, job.Status)
+		}
+	}
+
+	return results, nil
+}
+
+// parseBatchLine converts one output line into a result or a per-request error
+func (o *openAI) parseBatchLine(line batchOutputLine, batchID string) (*core.GenerateResult, error) {
+	if line.Error != nil {
+		return nil, fmt.Errorf("batch request failed (%s): %s", line.Error.Code, line.Error.Message)
+	}
+	if line.Response == nil {
+		return nil, fmt.Errorf("batch output has no response")
+	}
+	if line.Response.StatusCode != http.StatusOK {
+		return nil, core.NewAPIError("openai", line.Response.StatusCode, string(line.Response.Body), nil)
+	}
+
+	var apiResp openAIResponse
+	if err := json.Unmarshal(line.Response.Body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	result, err := o.parseResponse(&apiResp)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage.Cost *= batchCostFactor
+	result.Metadata = map[string]any{"batch_id": batchID}
+	return result, nil
+}
+
+// waitForBatch polls the batch job until it reaches a terminal status
+func (o *openAI) waitForBatch(ctx context.Context, apiKey string, job *batchJob) error {
+	interval := o.BatchPollInterval
+	if interval <= 0 {
+		interval = defaultBatchPollPeriod
+	}
+
+	for {
+		switch job.Status {
+		case "completed", "failed", "expired", "cancelled":
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("batch %s not finished (status %q): %w", job.ID, job.Status, ctx.Err())
+		case <-time.After(interval):
+		}
+
+		if err := o.batchRequest(ctx, apiKey, "GET", "/batches/"+job.ID, nil, job); err != nil {
+			return fmt.Errorf("failed to poll batch %s: %w", job.ID, err)
+		}
+	}
+}
+
+// uploadBatchFile uploads JSONL input with purpose "batch" and returns the file ID
+func (o *openAI) uploadBatchFile(ctx context.Context, apiKey string, data []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("purpose", "batch"); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	resp, err := o.doBatchHTTP(ctx, apiKey, "POST", "/files", body.Bytes(), writer.FormDataContentType())
+	if err != nil {
+		return "", fmt.Errorf("failed to upload batch file: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return "", fmt.Errorf("failed to decode upload response: %w", err)
+	}
+	return file.ID, nil
+}
+
+// downloadBatchFile fetches and parses a JSONL output or error file
+func (o *openAI) downloadBatchFile(ctx context.Context, apiKey, fileID string) ([]batchOutputLine, error) {
+	resp, err := o.doBatchHTTP(ctx, apiKey, "GET", "/files/"+fileID+"/content", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download batch file %s: %w", fileID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var lines []batchOutputLine
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line batchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("failed to parse batch file %s: %w", fileID, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s: %w", fileID, err)
+	}
+	return lines, nil
+}
+
+// batchRequest sends a JSON request to the batch endpoints and decodes the response into out
+func (o *openAI) batchRequest(ctx context.Context, apiKey, method, path string, payload any, out any) error {
+	var body []byte
+	contentType := ""
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		contentType = "application/json"
+	}
+
+	resp, err := o.doBatchHTTP(ctx, apiKey, method, path, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doBatchHTTP performs a request authenticated with apiKey, with retries; non-200 responses are errors
+func (o *openAI) doBatchHTTP(ctx context.Context, apiKey, method, path string, body []byte, contentType string) (*http.Response, error) {
+	resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (*http.Response, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, o.BaseURL+path, reader)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		core.SetRequestHeaders(ctx, req)
+		resp, err := o.Client.Do(req)
+		o.Keys.Observe(apiKey, resp) // park a rate-limited key for other calls
+		return resp, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, core.NewAPIError("openai", resp.StatusCode, string(respBody), resp.Header)
+	}
+	return resp, nil
+}
+
+// batchErrorSummary formats the job-level errors of a failed batch
+func batchErrorSummary(job *batchJob) string {
+	if job.Errors == nil || len(job.Errors.Data) == 0 {
+		return ""
+	}
+	first := job.Errors.Data[0]
+	return fmt.Sprintf(": %s: %s", first.Code, first.Message)
+}
@@ -0,0 +1,177 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/assagman/dsgo/core"
+)
+
+// fakeBatchServer emulates the Files and Batches endpoints of the OpenAI API
+// Every request must be authenticated with apiKey.
+func fakeBatchServer(t *testing.T, apiKey, finalStatus string, respond func(customID string, body map[string]any) string) *httptest.Server {
+	t.Helper()
+	var polls int32
+	var outputs, errorLines []string
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+apiKey {
+			t.Errorf("Authorization = %q on %s, want the key %q", got, r.URL.Path, apiKey)
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/files":
+			if r.FormValue("purpose") != "batch" {
+				t.Errorf("purpose = %q, want batch", r.FormValue("purpose"))
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("missing upload: %v", err)
+			}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var line struct {
+					CustomID string         `json:"custom_id"`
+					URL      string         `json:"url"`
+					Body     map[string]any `json:"body"`
+				}
+				if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+					t.Fatalf("invalid JSONL line: %v", err)
+				}
+				if line.URL != batchEndpoint || line.Body["model"] != "gpt-4o-mini" {
+					t.Errorf("unexpected batch line: %+v", line)
+				}
+				if out := respond(line.CustomID, line.Body); strings.Contains(out, `"error"`) {
+					errorLines = append(errorLines, out)
+				} else if out != "" {
+					outputs = append(outputs, out)
+				}
+			}
+			_, _ = w.Write([]byte(`{"id":"file-in"}`))
+		case r.Method == "POST" && r.URL.Path == "/batches":
+			var req map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req["input_file_id"] != "file-in" || req["completion_window"] != "24h" {
+				t.Errorf("unexpected batch request: %v", req)
+			}
+			_, _ = w.Write([]byte(`{"id":"batch-1","status":"validating"}`))
+		case r.Method == "GET" && r.URL.Path == "/batches/batch-1":
+			status := "in_progress"
+			if atomic.AddInt32(&polls, 1) > 1 {
+				status = finalStatus
+			}
+			_, _ = fmt.Fprintf(w, `{"id":"batch-1","status":%q,"output_file_id":"file-out","error_file_id":"file-err","errors":{"data":[{"code":"invalid_file","message":"bad input"}]}}`, status)
+		case r.Method == "GET" && r.URL.Path == "/files/file-out/content":
+			_, _ = w.Write([]byte(strings.Join(outputs, "\n")))
+		case r.Method == "GET" && r.URL.Path == "/files/file-err/content":
+			_, _ = w.Write([]byte(strings.Join(errorLines, "\n")))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func completionLine(customID, content string) string {
+	return fmt.Sprintf(`{"custom_id":%q,"response":{"status_code":200,"body":{"choices":[{"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}}}`, customID, content)
+}
+
+func TestOpenAI_GenerateBatch(t *testing.T) {
+	core.RegisterPricing("gpt-4o-mini", 1.0, 1.0)
+
+	server := fakeBatchServer(t, "test-key", "completed", func(customID string, body map[string]any) string {
+		switch customID {
+		case "bad":
+			return `{"custom_id":"bad","response":{"status_code":400,"body":{"error":{"message":"invalid"}}}}`
+		case "dropped":
+			return ""
+		default:
+			msgs := body["messages"].([]any)
+			return completionLine(customID, "echo "+msgs[0].(map[string]any)["content"].(string))
+		}
+	})
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o-mini", BaseURL: server.URL, Client: &http.Client{}, BatchPollInterval: time.Millisecond}
+	requests := []core.BatchRequest{
+		{ID: "b", Messages: []core.Message{{Role: "user", Content: "two"}}},
+		{ID: "a", Messages: []core.Message{{Role: "user", Content: "one"}}},
+		{ID: "bad", Messages: []core.Message{{Role: "user", Content: "x"}}},
+		{ID: "dropped", Messages: []core.Message{{Role: "user", Content: "y"}}},
+	}
+
+	results, err := core.BatchGenerate(context.Background(), lm, requests)
+	if err != nil {
+		t.Fatalf("BatchGenerate() error = %v", err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(results), len(requests))
+	}
+
+	for i, want := range []string{"echo two", "echo one"} {
+		if results[i].ID != requests[i].ID || results[i].Err != nil || results[i].Result.Content != want {
+			t.Errorf("results[%d] = %+v, want %q", i, results[i], want)
+		}
+	}
+	if results[0].Result.Metadata["batch_id"] != "batch-1" {
+		t.Errorf("expected batch_id metadata, got %v", results[0].Result.Metadata)
+	}
+	// Batch calls are billed at half the synchronous price: 15 tokens at $1/M, halved
+	if cost := results[0].Result.Usage.Cost; cost < 7.4e-6 || cost > 7.6e-6 {
+		t.Errorf("Usage.Cost = %g, want batch-discounted cost", cost)
+	}
+
+	wantErrs := map[int]string{
+		2: "API request failed with status 400",
+		3: `ended with status "completed" without a result`,
+	}
+	for i, want := range wantErrs {
+		if results[i].Err == nil || !strings.Contains(results[i].Err.Error(), want) {
+			t.Errorf("results[%d].Err = %v, want %q", i, results[i].Err, want)
+		}
+	}
+}
+
+func TestOpenAI_GenerateBatch_JobFailed(t *testing.T) {
+	server := fakeBatchServer(t, "test-key", "failed", func(customID string, body map[string]any) string { return "" })
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o-mini", BaseURL: server.URL, Client: &http.Client{}, BatchPollInterval: time.Millisecond}
+	_, err := core.BatchGenerate(context.Background(), lm, []core.BatchRequest{{Messages: []core.Message{{Role: "user", Content: "hi"}}}})
+	if err == nil || !strings.Contains(err.Error(), "batch batch-1 failed: invalid_file: bad input") {
+		t.Errorf("expected batch failure, got %v", err)
+	}
+}
+
+func TestOpenAI_GenerateBatch_KeyPool(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	core.Configure(core.WithAPIKeys("openai", []string{"key-1", "key-2"}, core.KeyRoundRobin))
+	t.Setenv("OPENAI_API_KEY", "")
+
+	// Round robin would switch keys between requests; the job must stay on the first one
+	server := fakeBatchServer(t, "key-1", "completed", func(customID string, body map[string]any) string {
+		return completionLine(customID, "ok")
+	})
+	defer server.Close()
+
+	lm := newOpenAI("gpt-4o-mini")
+	lm.BaseURL = server.URL
+	lm.BatchPollInterval = time.Millisecond
+
+	results, err := core.BatchGenerate(context.Background(), lm, []core.BatchRequest{{ID: "a", Messages: []core.Message{{Role: "user", Content: "hi"}}}})
+	if err != nil {
+		t.Fatalf("BatchGenerate() error = %v", err)
+	}
+	if results[0].Err != nil || results[0].Result.Content != "ok" {
+		t.Errorf("results[0] = %+v, want ok", results[0])
+	}
+}
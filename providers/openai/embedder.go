@@ -0,0 +1,102 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/internal/retry"
+)
+
+func init() {
+	core.RegisterEmbedder("openai", func(model string) core.Embedder {
+		return newOpenAIEmbedder(model)
+	})
+}
+
+// openAIEmbedder implements core.Embedder using the OpenAI embeddings API
+type openAIEmbedder struct {
+	APIKey  string
+	Keys    *core.KeyPool // Rotates requests across several keys when set (see core.WithAPIKeys)
+	Model   string
+	BaseURL string
+	Client  *http.Client
+}
+
+// newOpenAIEmbedder creates a new OpenAI embedder
+func newOpenAIEmbedder(model string) *openAIEmbedder {
+	return &openAIEmbedder{
+		APIKey:  os.Getenv("OPENAI_API_KEY"),
+		Model:   model,
+		BaseURL: core.BaseURLFor("openai", defaultBaseURL),
+		Client:  core.HTTPClientFor("openai"),
+		Keys:    core.KeyPoolFor("openai"),
+	}
+}
+
+// Embed returns one embedding per text, in input order
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	bodyBytes, err := json.Marshal(map[string]any{
+		"model": e.Model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (*http.Response, error) {
+		resp, _, err := e.Keys.Do(e.APIKey, func(key string) (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, "POST", e.BaseURL+"/embeddings", bytes.NewReader(bodyBytes))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+key)
+			core.SetRequestHeaders(ctx, req)
+			return e.Client.Do(req)
+		})
+		return resp, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, core.NewAPIError("openai", resp.StatusCode, string(body), resp.Header)
+	}
+
+	var apiResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, d := range apiResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	for i, emb := range embeddings {
+		if emb == nil {
+			return nil, fmt.Errorf("missing embedding for input %d", i)
+		}
+	}
+	return embeddings, nil
+}
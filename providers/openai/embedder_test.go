@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/assagman/dsgo/core"
+)
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "text-embedding-3-small" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		// Return data out of order to check it is mapped by index
+		_, _ = w.Write([]byte(`{"data": [
+			{"index": 1, "embedding": [0, 1]},
+			{"index": 0, "embedding": [1, 0]}
+		]}`))
+	}))
+	defer server.Close()
+
+	e := newOpenAIEmbedder("text-embedding-3-small")
+	e.APIKey = "test-key"
+	e.BaseURL = server.URL
+
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("unexpected vectors %v", vectors)
+	}
+}
+
+func TestOpenAIEmbedder_KeyPool(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	core.Configure(core.WithAPIKeys("openai", []string{"key-1", "key-2"}, core.KeyRoundRobin))
+	t.Setenv("OPENAI_API_KEY", "")
+
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data": [{"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	e := newOpenAIEmbedder("text-embedding-3-small")
+	e.BaseURL = server.URL
+	for range 2 {
+		if _, err := e.Embed(context.Background(), []string{"a"}); err != nil {
+			t.Fatalf("Embed() error: %v", err)
+		}
+	}
+	if want := []string{"Bearer key-1", "Bearer key-2"}; !slices.Equal(keys, want) {
+		t.Errorf("Authorization headers = %v, want %v", keys, want)
+	}
+}
+
+func TestOpenAIEmbedder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "api error", status: http.StatusBadRequest, body: `{"error": "bad input"}`},
+		{name: "missing embedding", status: http.StatusOK, body: `{"data": [{"index": 0, "embedding": [1]}]}`},
+		{name: "index out of range", status: http.StatusOK, body: `{"data": [{"index": 5, "embedding": [1]}]}`},
+		{name: "invalid json", status: http.StatusOK, body: `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			e := newOpenAIEmbedder("text-embedding-3-small")
+			e.BaseURL = server.URL
+
+			if _, err := e.Embed(context.Background(), []string{"a", "b"}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -32,10 +32,13 @@ const (
 // openAI implements the LM interface for OpenAI models
 type openAI struct {
 	APIKey  string
+	Keys    *core.KeyPool // Rotates requests across several keys when set (see core.WithAPIKeys)
 	Model   string
 	BaseURL string
 	Client  *http.Client
 	Cache   core.Cache
+
+	BatchPollInterval time.Duration // How often GenerateBatch polls the job (default 30s)
 }
 
 // newOpenAI creates a new OpenAI LM
@@ -44,8 +47,9 @@ func newOpenAI(model string) *openAI {
 	return &openAI{
 		APIKey:  apiKey,
 		Model:   model,
-		BaseURL: defaultBaseURL,
-		Client:  &http.Client{},
+		BaseURL: core.BaseURLFor("openai", defaultBaseURL),
+		Client:  core.HTTPClientFor("openai"),
+		Keys:    core.KeyPoolFor("openai"),
 	}
 }
 
@@ -64,13 +68,73 @@ func (o *openAI) SupportsTools() bool {
 	return true
 }
 
+// Capabilities reports what the model supports
+// Legacy models (gpt-3.5, gpt-4, gpt-4-turbo) predate structured outputs and only get JSON mode.
+func (o *openAI) Capabilities() core.Capabilities {
+	window, _ := core.ContextWindowFor(o.Model)
+	return core.Capabilities{
+		SupportsTools:      true,
+		SupportsJSON:       true,
+		SupportsJSONSchema: supportsJSONSchema(o.Model),
+		SupportsVision:     core.IsVisionModel(o.Model),
+		SupportsStreaming:  true,
+		MaxContextTokens:   window,
+	}
+}
+
+// supportsJSONSchema reports whether a model accepts json_schema response formats
+func supportsJSONSchema(model string) bool {
+	name := strings.ToLower(model)
+	return !strings.HasPrefix(name, "gpt-3.5") && name != "gpt-4" && !strings.HasPrefix(name, "gpt-4-")
+}
+
+// HealthCheck retrieves the model from the models endpoint, verifying the API key,
+// connectivity and that the model exists, without generating any tokens
+func (o *openAI) HealthCheck(ctx context.Context) error {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
+
+	resp, _, err := o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.BaseURL+"/models/"+url.PathEscape(o.Model), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		core.SetRequestHeaders(ctx, req)
+		return o.Client.Do(req)
+	})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: openai does not serve %q", core.ErrModelNotFound, o.Model)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return core.NewAPIError("openai", resp.StatusCode, string(body), resp.Header)
+}
+
 // SetCache sets the cache instance for this LM
 func (o *openAI) SetCache(cache core.Cache) {
 	o.Cache = cache
 }
 
-// Generate generates a response from OpenAI
+// Generate generates a response from OpenAI, retrying empty completions
+// (see core.RetryEmptyResponses)
 func (o *openAI) Generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	return core.RetryEmptyResponses(ctx, "openai", func() (*core.GenerateResult, error) {
+		return o.generate(ctx, messages, options)
+	})
+}
+
+// generate makes one Generate request
+func (o *openAI) generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
 	startTime := time.Now()
 
 	// Calculate prompt length for logging
@@ -82,6 +146,10 @@ func (o *openAI) Generate(ctx context.Context, messages []core.Message, options
 	// Log API request start
 	logging.LogAPIRequest(ctx, o.Model, promptLength)
 
+	if err := o.checkImageSupport(messages); err != nil {
+		return nil, err
+	}
+
 	// Check cache if available
 	if o.Cache != nil {
 		cacheKey := core.GenerateCacheKey(o.Model, messages, options)
@@ -91,20 +159,31 @@ func (o *openAI) Generate(ctx context.Context, messages []core.Message, options
 	}
 
 	reqBody := o.buildRequest(messages, options)
+	if metadata := core.RequestMetadata(ctx); metadata != nil {
+		reqBody["metadata"] = metadata
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := retry.WithExponentialBackoff(ctx, func() (*http.Response, error) {
-		req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+o.APIKey)
-		return o.Client.Do(req)
+	// One key for all retries, so a request that succeeded before a dropped response is not billed twice
+	idempotencyKey := core.IdempotencyKeyFor(options)
+	var apiKey string
+	resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (resp *http.Response, err error) {
+		resp, apiKey, err = o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+key)
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+			core.SetRequestHeaders(ctx, req)
+			return o.Client.Do(req)
+		})
+		return resp, err
 	})
 	if err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
@@ -114,8 +193,9 @@ func (o *openAI) Generate(ctx context.Context, messages []core.Message, options
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		err := fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		err := core.NewAPIError("openai", resp.StatusCode, string(body), resp.Header)
 		logging.LogAPIError(ctx, o.Model, err)
+		o.captureRaw(ctx, reqBody, resp, body, err)
 		return nil, err
 	}
 
@@ -126,42 +206,34 @@ func (o *openAI) Generate(ctx context.Context, messages []core.Message, options
 		return nil, fmt.Errorf("failed to read response body: %w", readErr)
 	}
 
-	// Save raw request/response exchange if debug enabled
-	if debugEnv := os.Getenv("DSGO_SAVE_RAW_RESPONSES"); debugEnv == "1" || debugEnv == "true" {
-		if err := saveRawExchange(o.Model, reqBody, resp.StatusCode, resp.Header, bodyBytes, nil); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save raw exchange: %v\n", err)
-		}
-	}
-
 	var apiResp openAIResponse
 	if err := json.Unmarshal(bodyBytes, &apiResp); err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
-		// Save failed response for debugging
-		if err := saveRawExchange(o.Model+"_DECODE_FAILED", reqBody, resp.StatusCode, resp.Header, bodyBytes, err); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save failed exchange: %v\n", err)
-		}
+		o.captureRaw(ctx, reqBody, resp, bodyBytes, err)
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	result, err := o.parseResponse(&apiResp)
 	if err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
-		// Save raw response on parse error
-		if err := saveRawExchange(o.Model+"_PARSE_FAILED", reqBody, resp.StatusCode, resp.Header, bodyBytes, err); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save failed exchange: %v\n", err)
-		}
+		o.captureRaw(ctx, reqBody, resp, bodyBytes, err)
 		return nil, err
 	}
 
+	o.captureRaw(ctx, reqBody, resp, bodyBytes, nil)
+
 	// Extract metadata from response headers
 	result.Metadata = o.extractMetadata(resp.Header)
+	if o.Keys != nil {
+		result.Metadata["api_key"] = core.MaskAPIKey(apiKey)
+	}
 
 	// Log API response
 	duration := time.Since(startTime)
 	logging.LogAPIResponse(ctx, o.Model, resp.StatusCode, duration, result.Usage)
 
-	// Store in cache if available
-	if o.Cache != nil {
+	// Store in cache if available; empty completions are retried instead
+	if o.Cache != nil && !core.IsEmptyResult(result) {
 		cacheKey := core.GenerateCacheKey(o.Model, messages, options)
 		o.Cache.Set(cacheKey, result)
 	}
@@ -209,6 +281,13 @@ func (o *openAI) buildRequest(messages []core.Message, options *core.GenerateOpt
 	if options.PresencePenalty != 0 {
 		req["presence_penalty"] = options.PresencePenalty
 	}
+	if len(options.LogitBias) > 0 {
+		req["logit_bias"] = options.LogitBias
+	}
+	// OpenAI has no thinking token budget, only an effort level
+	if options.ReasoningEffort != "" {
+		req["reasoning_effort"] = options.ReasoningEffort
+	}
 
 	// Add tools if supported
 	if len(options.Tools) > 0 {
@@ -266,6 +345,9 @@ func (o *openAI) convertMessages(messages []core.Message) []map[string]any {
 				})
 			}
 			m["tool_calls"] = toolCalls
+		} else if len(msg.Images) > 0 {
+			// Multimodal message: text part followed by image parts
+			m["content"] = convertContentParts(msg)
 		} else {
 			// Regular message
 			m["content"] = msg.Content
@@ -276,37 +358,36 @@ func (o *openAI) convertMessages(messages []core.Message) []map[string]any {
 	return converted
 }
 
-func (o *openAI) convertTool(tool *core.Tool) map[string]any {
-	properties := make(map[string]any)
-	required := []string{}
-
-	for _, param := range tool.Parameters {
-		prop := map[string]any{
-			"type":        param.Type,
-			"description": param.Description,
-		}
-		if len(param.Enum) > 0 {
-			prop["enum"] = param.Enum
-		}
-		properties[param.Name] = prop
-
-		if param.Required {
-			required = append(required, param.Name)
-		}
+// convertContentParts builds the multimodal content array for a message with images
+func convertContentParts(msg core.Message) []map[string]any {
+	parts := make([]map[string]any, 0, len(msg.Images)+1)
+	if msg.Content != "" {
+		parts = append(parts, map[string]any{
+			"type": "text",
+			"text": msg.Content,
+		})
 	}
-
-	return map[string]any{
-		"type": "function",
-		"function": map[string]any{
-			"name":        tool.Name,
-			"description": tool.Description,
-			"parameters": map[string]any{
-				"type":       "object",
-				"properties": properties,
-				"required":   required,
+	for _, img := range msg.Images {
+		parts = append(parts, map[string]any{
+			"type": "image_url",
+			"image_url": map[string]any{
+				"url": img.DataURL(),
 			},
-		},
+		})
+	}
+	return parts
+}
+
+// checkImageSupport rejects image inputs for models without vision support
+func (o *openAI) checkImageSupport(messages []core.Message) error {
+	if core.HasImages(messages) && !core.IsVisionModel(o.Model) {
+		return fmt.Errorf("%w: %s (use a vision model such as gpt-4o, or register it with core.RegisterVisionModel)", core.ErrImageInputUnsupported, o.Model)
 	}
+	return nil
+}
+
+func (o *openAI) convertTool(tool *core.Tool) map[string]any {
+	return tool.FunctionSchema()
 }
 
 func (o *openAI) parseResponse(resp *openAIResponse) (*core.GenerateResult, error) {
@@ -315,8 +396,13 @@ func (o *openAI) parseResponse(resp *openAIResponse) (*core.GenerateResult, erro
 	}
 
 	choice := resp.Choices[0]
+	// Refusals and moderation blocks come back as ordinary (often empty) completions
+	if err := core.CheckContentFilter("openai", choice.FinishReason, choice.Message.Refusal); err != nil {
+		return nil, err
+	}
 	result := &core.GenerateResult{
 		Content:      choice.Message.Content,
+		Reasoning:    choice.Message.ReasoningContent,
 		FinishReason: choice.FinishReason,
 		Usage: core.Usage{
 			PromptTokens:     resp.Usage.PromptTokens,
@@ -324,6 +410,7 @@ func (o *openAI) parseResponse(resp *openAIResponse) (*core.GenerateResult, erro
 			TotalTokens:      resp.Usage.TotalTokens,
 		},
 	}
+	core.FillCost(o.Model, &result.Usage)
 
 	// Parse tool calls if present
 	if len(choice.Message.ToolCalls) > 0 {
@@ -397,8 +484,18 @@ func (o *openAI) Stream(ctx context.Context, messages []core.Message, options *c
 		defer close(chunkChan)
 		defer close(errChan)
 
+		if err := o.checkImageSupport(messages); err != nil {
+			errChan <- err
+			return
+		}
+
 		reqBody := o.buildRequest(messages, options)
 		reqBody["stream"] = true
+		if metadata := core.RequestMetadata(ctx); metadata != nil {
+			reqBody["metadata"] = metadata
+		}
+		// Ask for the terminal usage chunk, which is otherwise omitted when streaming
+		reqBody["stream_options"] = map[string]any{"include_usage": true}
 
 		bodyBytes, err := json.Marshal(reqBody)
 		if err != nil {
@@ -406,16 +503,23 @@ func (o *openAI) Stream(ctx context.Context, messages []core.Message, options *c
 			return
 		}
 
-		resp, err := retry.WithExponentialBackoff(ctx, func() (*http.Response, error) {
-			req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
-			if err != nil {
-				return nil, err
-			}
+		// Same key on every retry, as in Generate
+		idempotencyKey := core.IdempotencyKeyFor(options)
+		resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (*http.Response, error) {
+			resp, _, err := o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+				req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
+				if err != nil {
+					return nil, err
+				}
 
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("Authorization", "Bearer "+o.APIKey)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+key)
+				req.Header.Set("Idempotency-Key", idempotencyKey)
+				core.SetRequestHeaders(ctx, req)
 
-			return o.Client.Do(req)
+				return o.Client.Do(req)
+			})
+			return resp, err
 		})
 		if err != nil {
 			errChan <- fmt.Errorf("request failed: %w", err)
@@ -425,67 +529,130 @@ func (o *openAI) Stream(ctx context.Context, messages []core.Message, options *c
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			errChan <- fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			err := core.NewAPIError("openai", resp.StatusCode, string(body), resp.Header)
+			o.captureRaw(ctx, reqBody, resp, body, err)
+			errChan <- err
 			return
 		}
 
-		// Read SSE stream
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
+		// Tee the SSE body so the assembled stream is captured once it ends
+		var raw bytes.Buffer
+		body := io.Reader(resp.Body)
+		capture := core.RawCaptureDir() != ""
+		if capture {
+			body = io.TeeReader(resp.Body, &raw)
+		}
+		err = o.readStream(ctx, body, chunkChan)
+		if capture {
+			o.captureRaw(ctx, reqBody, resp, raw.Bytes(), err)
+		}
+		if err != nil {
+			errChan <- err
+		}
+	}()
 
-			// Skip empty lines
-			if line == "" {
-				continue
-			}
+	return chunkChan, errChan
+}
 
-			// Parse SSE format: "data: {json}"
-			if !strings.HasPrefix(line, "data: ") {
-				continue
-			}
+// readStream decodes the SSE body into chunkChan until [DONE] or EOF
+func (o *openAI) readStream(ctx context.Context, body io.Reader, chunkChan chan<- core.Chunk) error {
+	toolCalls := core.NewToolCallAccumulator()
+	var refusal strings.Builder
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
 
-			data := strings.TrimPrefix(line, "data: ")
+		// Parse SSE format: "data: {json}"
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
 
-			// Check for stream end
-			if data == "[DONE]" {
-				break
-			}
+		data := strings.TrimPrefix(line, "data: ")
 
-			// Parse JSON chunk
-			var streamResp openAIStreamResponse
-			if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
-				errChan <- fmt.Errorf("failed to parse stream chunk: %w", err)
-				return
-			}
+		// Check for stream end
+		if data == "[DONE]" {
+			break
+		}
+
+		// Parse JSON chunk
+		var streamResp openAIStreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		// The terminal usage chunk has no choices, so handle usage independently
+		if len(streamResp.Choices) == 0 && streamResp.Usage == nil {
+			continue
+		}
 
-			// Extract chunk data
-			if len(streamResp.Choices) > 0 {
-				choice := streamResp.Choices[0]
-				chunk := core.Chunk{
-					Content:      choice.Delta.Content,
-					FinishReason: choice.FinishReason,
+		var chunk core.Chunk
+		if len(streamResp.Choices) > 0 {
+			choice := streamResp.Choices[0]
+			chunk.Content = choice.Delta.Content
+			chunk.Reasoning = choice.Delta.ReasoningContent
+			chunk.FinishReason = choice.FinishReason
+			refusal.WriteString(choice.Delta.Refusal)
+			if choice.FinishReason != "" {
+				if err := core.CheckContentFilter("openai", choice.FinishReason, refusal.String()); err != nil {
+					return err
 				}
+			}
 
-				// Add usage if present (typically in last chunk)
-				if streamResp.Usage != nil {
-					chunk.Usage = core.Usage{
-						PromptTokens:     streamResp.Usage.PromptTokens,
-						CompletionTokens: streamResp.Usage.CompletionTokens,
-						TotalTokens:      streamResp.Usage.TotalTokens,
-					}
+			for _, tc := range choice.Delta.ToolCalls {
+				toolCalls.Add(tc.Index, tc.ID, tc.Function.Name, tc.Function.Arguments)
+			}
+			// Arguments arrive in fragments; only emit the calls once the model has finished them
+			if choice.FinishReason != "" && toolCalls.Pending() {
+				var err error
+				if chunk.ToolCalls, err = toolCalls.Flush(); err != nil {
+					return err
 				}
+			}
+		}
 
-				chunkChan <- chunk
+		// Add usage if present (typically in last chunk)
+		if streamResp.Usage != nil {
+			chunk.Usage = core.Usage{
+				PromptTokens:     streamResp.Usage.PromptTokens,
+				CompletionTokens: streamResp.Usage.CompletionTokens,
+				TotalTokens:      streamResp.Usage.TotalTokens,
 			}
+			core.FillCost(o.Model, &chunk.Usage)
 		}
 
-		if err := scanner.Err(); err != nil {
-			errChan <- fmt.Errorf("stream reading error: %w", err)
-			return
+		// Stop reading as soon as the caller cancels; the deferred Close in Stream aborts the request
+		select {
+		case chunkChan <- chunk:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-	}()
+	}
 
-	return chunkChan, errChan
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("stream reading error: %w", err)
+	}
+
+	// The stream ended without a finish reason; release any calls still buffered
+	if toolCalls.Pending() {
+		calls, err := toolCalls.Flush()
+		if err != nil {
+			return err
+		}
+		select {
+		case chunkChan <- core.Chunk{ToolCalls: calls, FinishReason: "tool_calls"}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
 }
 
 // OpenAI API response structures
@@ -507,9 +674,11 @@ type openAIResponse struct {
 }
 
 type openAIMessage struct {
-	Role      string           `json:"role"`
-	Content   string           `json:"content"`
-	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+	Role             string           `json:"role"`
+	Content          string           `json:"content"`
+	Refusal          string           `json:"refusal,omitempty"`           // Set instead of Content when the model refuses
+	ReasoningContent string           `json:"reasoning_content,omitempty"` // Reasoning models on OpenAI-compatible APIs (e.g. DeepSeek)
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
 }
 
 type openAIToolCall struct {
@@ -521,6 +690,16 @@ type openAIToolCall struct {
 	} `json:"function"`
 }
 
+// openAIToolCallDelta is one streamed fragment of a tool call, identified by Index
+type openAIToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
 type openAIStreamResponse struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
@@ -529,8 +708,11 @@ type openAIStreamResponse struct {
 	Choices []struct {
 		Index int `json:"index"`
 		Delta struct {
-			Content string `json:"content"`
-			Role    string `json:"role,omitempty"`
+			Content          string                `json:"content"`
+			ReasoningContent string                `json:"reasoning_content,omitempty"` // Streamed counterpart of openAIMessage.ReasoningContent
+			Refusal          string                `json:"refusal,omitempty"`
+			Role             string                `json:"role,omitempty"`
+			ToolCalls        []openAIToolCallDelta `json:"tool_calls,omitempty"`
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -541,67 +723,19 @@ type openAIStreamResponse struct {
 	} `json:"usage,omitempty"`
 }
 
-// saveRawExchange saves complete request/response exchange to a file for debugging
-func saveRawExchange(model string, request map[string]any, statusCode int, headers http.Header, responseBody []byte, saveErr error) error {
-	// Determine output directory - prefer DSGO_ARTIFACT_DIR, fallback to test_matrix_logs
-	baseDir := os.Getenv("DSGO_ARTIFACT_DIR")
-	if baseDir == "" {
-		baseDir = "test_matrix_logs"
-	}
-	rawDir := filepath.Join(baseDir, "raw")
-	if err := os.MkdirAll(rawDir, 0755); err != nil {
-		return err
-	}
-
-	// Parse response body as JSON if possible
-	var responseJSON any
-	var responseText string
-	if err := json.Unmarshal(responseBody, &responseJSON); err != nil {
-		responseText = string(responseBody)
-	}
-
-	// Build complete exchange record
-	exchange := map[string]any{
-		"timestamp": time.Now().Format(time.RFC3339Nano),
-		"provider":  "openai",
-		"model":     model,
-		"example":   os.Getenv("DSGO_EXAMPLE"),
-		"request":   request,
-		"http": map[string]any{
-			"status":  statusCode,
-			"headers": headers,
-		},
-	}
-
-	if responseJSON != nil {
-		exchange["response"] = responseJSON
-	} else {
-		exchange["response_text"] = responseText
-	}
-
+// captureRaw saves the exchange when raw response capture is enabled (see core.WithRawResponseCapture)
+func (o *openAI) captureRaw(ctx context.Context, request map[string]any, resp *http.Response, body []byte, err error) {
+	_, saveErr := core.CaptureRawExchange(core.RawExchange{
+		Provider:   "openai",
+		Model:      o.Model,
+		RequestID:  logging.GetRequestID(ctx),
+		Request:    request,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Err:        err,
+	})
 	if saveErr != nil {
-		exchange["error"] = saveErr.Error()
-	}
-
-	// Create filename with timestamp and model
-	timestamp := time.Now().Format("20060102_150405.000000")
-	safeModel := strings.ReplaceAll(model, "/", "_")
-	safeModel = strings.ReplaceAll(safeModel, ":", "_")
-	filename := filepath.Join(rawDir, fmt.Sprintf("%s_openai_%s.json", timestamp, safeModel))
-
-	// Marshal with 2-space indentation
-	exchangeJSON, err := json.MarshalIndent(exchange, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	// Write to file
-	if err := os.WriteFile(filename, exchangeJSON, 0644); err != nil {
-		return err
-	}
-
-	if os.Getenv("DSGO_ARTIFACT_DIR") != "" {
-		fmt.Fprintf(os.Stderr, "📝 Raw exchange saved to: %s\n", filename)
+		fmt.Fprintf(os.Stderr, "Warning: failed to save raw exchange: %v\n", saveErr)
 	}
-	return nil
 }
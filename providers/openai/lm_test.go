@@ -3,12 +3,17 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
 )
 
 func TestNewOpenAI(t *testing.T) {
@@ -32,6 +37,30 @@ func TestNewOpenAI(t *testing.T) {
 	}
 }
 
+func TestNewOpenAI_BaseURL(t *testing.T) {
+	defer core.ResetConfig()
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "hi"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	core.Configure(core.WithProvider("openai"), core.WithBaseURL(server.URL+"/v1/"))
+	lm := newOpenAI("gpt-4o")
+	lm.APIKey = "test-key"
+	if lm.BaseURL != server.URL+"/v1" {
+		t.Errorf("BaseURL = %q, want the configured gateway", lm.BaseURL)
+	}
+	if _, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "Hello"}}, core.DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if path != "/v1/chat/completions" {
+		t.Errorf("request path = %q, want /v1/chat/completions on the gateway", path)
+	}
+}
+
 func TestOpenAI_Name(t *testing.T) {
 	lm := &openAI{Model: "gpt-4-turbo"}
 	if lm.Name() != "gpt-4-turbo" {
@@ -53,6 +82,254 @@ func TestOpenAI_SupportsTools(t *testing.T) {
 	}
 }
 
+func TestOpenAI_Capabilities(t *testing.T) {
+	tests := []struct {
+		model      string
+		wantSchema bool
+		wantVision bool
+		wantWindow int
+	}{
+		{"gpt-4o-mini", true, true, 128000},
+		{"gpt-4.1", true, true, 1047576},
+		{"gpt-4-turbo", false, true, 128000},
+		{"gpt-4", false, false, 8192},
+		{"gpt-3.5-turbo", false, false, 16385},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			caps := (&openAI{Model: tt.model}).Capabilities()
+			if !caps.SupportsTools || !caps.SupportsJSON || !caps.SupportsStreaming {
+				t.Errorf("expected tools, JSON and streaming support, got %+v", caps)
+			}
+			if caps.SupportsJSONSchema != tt.wantSchema {
+				t.Errorf("SupportsJSONSchema = %v, want %v", caps.SupportsJSONSchema, tt.wantSchema)
+			}
+			if caps.SupportsVision != tt.wantVision {
+				t.Errorf("SupportsVision = %v, want %v", caps.SupportsVision, tt.wantVision)
+			}
+			if caps.MaxContextTokens != tt.wantWindow {
+				t.Errorf("MaxContextTokens = %d, want %d", caps.MaxContextTokens, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestOpenAI_Generate_RawResponseCapture(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	dir := t.TempDir()
+	core.Configure(core.WithRawResponseCapture(dir))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "bad request"}}`))
+	}))
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL, Client: &http.Client{}}
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	if _, err := lm.Generate(ctx, []core.Message{{Role: "user", Content: "Hello"}}, core.DefaultGenerateOptions()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "req-1", "*_openai_gpt-4o.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one captured exchange, got %v (%v)", files, err)
+	}
+	data, _ := os.ReadFile(files[0])
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if record["error"] == nil || record["request"] == nil || record["response"] == nil {
+		t.Errorf("expected request, response and error in capture, got %v", record)
+	}
+}
+
+func TestOpenAI_Stream_RawResponseCapture(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	dir := t.TempDir()
+	core.Configure(core.WithRawResponseCapture(dir))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL, Client: &http.Client{}}
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	chunkChan, errChan := lm.Stream(ctx, []core.Message{{Role: "user", Content: "Hello"}}, core.DefaultGenerateOptions())
+	for range chunkChan {
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "req-1", "*_openai_gpt-4o.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one captured exchange, got %v (%v)", files, err)
+	}
+	data, _ := os.ReadFile(files[0])
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	body, _ := record["response_text"].(string)
+	if !strings.Contains(body, `"content":"Hel"`) || !strings.Contains(body, "data: [DONE]") {
+		t.Errorf("expected the whole SSE body in the capture, got %q", body)
+	}
+	if record["request"] == nil || record["error"] != nil {
+		t.Errorf("expected the request and no error in capture, got %v", record)
+	}
+}
+
+func TestOpenAI_Generate_ReasoningContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "reasoning_content": "2 + 2 is 4", "content": "4"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "deepseek-reasoner", BaseURL: server.URL, Client: &http.Client{}}
+	result, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "2 + 2?"}}, core.DefaultGenerateOptions())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Content != "4" || result.Reasoning != "2 + 2 is 4" {
+		t.Errorf("got content %q, reasoning %q", result.Content, result.Reasoning)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestOpenAI_CustomHTTPClient(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gateway") != "corp" {
+			t.Errorf("expected request to pass through the custom transport")
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	var calls int
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		req.Header.Set("X-Gateway", "corp")
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	core.Configure(core.WithProviderHTTPClient("openai", client))
+
+	lm := newOpenAI("gpt-4o")
+	lm.BaseURL = server.URL
+	if _, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call through the custom client, got %d", calls)
+	}
+}
+
+func TestOpenAI_Generate_DefaultTimeoutWithCustomClient(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// The custom client has no Timeout of its own
+	core.Configure(core.WithHTTPClient(&http.Client{}), core.WithTimeout(50*time.Millisecond))
+
+	lm := newOpenAI("gpt-4o")
+	lm.BaseURL = server.URL
+	start := time.Now()
+	_, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions())
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Generate took %v, expected the 50ms timeout to apply", elapsed)
+	}
+}
+
+func TestOpenAI_Generate_IdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			// The first attempt fails after the server may have done the work
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL, Client: &http.Client{}}
+	messages := []core.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := lm.Generate(context.Background(), messages, core.DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected the retry to reuse a generated key, got %q", keys)
+	}
+
+	options := core.DefaultGenerateOptions()
+	options.IdempotencyKey = "order-42"
+	if _, err := lm.Generate(context.Background(), messages, options); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if last := keys[len(keys)-1]; last != "order-42" {
+		t.Errorf("Idempotency-Key = %q, want the caller's key", last)
+	}
+	if keys[0] == "order-42" {
+		t.Error("generated keys must differ from the caller's key")
+	}
+}
+
+func TestOpenAI_Generate_ConfiguredBackoff(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	core.Configure(core.WithBackoff(core.BackoffConfig{InitialDelay: time.Millisecond, MaxRetries: 1}))
+
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL, Client: &http.Client{}}
+	start := time.Now()
+	if _, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "hi"}}, core.DefaultGenerateOptions()); err == nil {
+		t.Fatal("expected an error after retries")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 (one retry)", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Generate took %v, expected the 1ms initial delay", elapsed)
+	}
+}
+
 func TestOpenAI_Generate_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -218,6 +495,65 @@ func TestOpenAI_Generate_ErrorResponse(t *testing.T) {
 	}
 }
 
+func TestOpenAI_Generate_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "quota exhausted",
+			status: http.StatusTooManyRequests,
+			body:   `{"error": {"code": "insufficient_quota"}}`,
+			check: func(t *testing.T, err error) {
+				var rateErr *core.RateLimitError
+				if !errors.As(err, &rateErr) {
+					t.Fatalf("expected *core.RateLimitError, got %T: %v", err, err)
+				}
+				if rateErr.RetryAfter != 7*time.Second {
+					t.Errorf("RetryAfter = %v, want 7s", rateErr.RetryAfter)
+				}
+			},
+		},
+		{
+			name:   "invalid key",
+			status: http.StatusUnauthorized,
+			body:   `{"error": {"code": "invalid_api_key"}}`,
+			check: func(t *testing.T, err error) {
+				var authErr *core.AuthError
+				if !errors.As(err, &authErr) {
+					t.Fatalf("expected *core.AuthError, got %T: %v", err, err)
+				}
+				if authErr.Err.Provider != "openai" {
+					t.Errorf("Provider = %q, want openai", authErr.Err.Provider)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			lm := &openAI{
+				APIKey:  "test-key",
+				Model:   "gpt-4",
+				BaseURL: server.URL,
+				Client:  &http.Client{},
+			}
+
+			_, err := lm.Generate(context.Background(), []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+			tt.check(t, err)
+		})
+	}
+}
+
 func TestOpenAI_Generate_NoChoices(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := openAIResponse{
@@ -339,6 +675,53 @@ func TestOpenAI_BuildRequest(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "with schema from signature",
+			messages: []core.Message{{Role: "user", Content: "test"}},
+			options: &core.GenerateOptions{
+				ResponseFormat: "json",
+				ResponseSchema: core.NewSignature("Classify").
+					AddClassOutput("sentiment", []string{"positive", "negative"}, "Overall sentiment").
+					SignatureToJSONSchema(),
+			},
+			check: func(t *testing.T, req map[string]any) {
+				rf := req["response_format"].(map[string]any)
+				schema := rf["json_schema"].(map[string]any)["schema"].(map[string]any)
+				prop := schema["properties"].(map[string]any)["sentiment"].(map[string]any)
+				if prop["description"] != "Overall sentiment" {
+					t.Errorf("expected field description in schema, got %v", prop["description"])
+				}
+				if enum, _ := prop["enum"].([]string); len(enum) != 2 {
+					t.Errorf("expected class enum in schema, got %v", prop["enum"])
+				}
+			},
+		},
+		{
+			name:     "with logit bias",
+			messages: []core.Message{{Role: "user", Content: "test"}},
+			options: &core.GenerateOptions{
+				LogitBias: map[int]int{1234: 5},
+			},
+			check: func(t *testing.T, req map[string]any) {
+				bias, ok := req["logit_bias"].(map[int]int)
+				if !ok || bias[1234] != 5 {
+					t.Errorf("expected logit_bias, got %v", req["logit_bias"])
+				}
+			},
+		},
+		{
+			name:     "with reasoning effort",
+			messages: []core.Message{{Role: "user", Content: "test"}},
+			options: &core.GenerateOptions{
+				ReasoningEffort:      core.ReasoningEffortLow,
+				ThinkingBudgetTokens: 1024,
+			},
+			check: func(t *testing.T, req map[string]any) {
+				if req["reasoning_effort"] != "low" {
+					t.Errorf("expected reasoning_effort low, got %v", req["reasoning_effort"])
+				}
+			},
+		},
 		{
 			name:     "with stop sequences",
 			messages: []core.Message{{Role: "user", Content: "test"}},
@@ -463,6 +846,36 @@ func TestOpenAI_ConvertMessages(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "user message with images",
+			messages: []core.Message{
+				{
+					Role:    "user",
+					Content: "Describe this",
+					Images: []core.ImageContent{
+						core.NewImageFromURL("https://example.com/cat.png"),
+						core.NewImageFromBytes([]byte("fake"), "image/png"),
+					},
+				},
+			},
+			check: func(t *testing.T, converted []map[string]any) {
+				parts, ok := converted[0]["content"].([]map[string]any)
+				if !ok || len(parts) != 3 {
+					t.Fatalf("expected 3 content parts, got %v", converted[0]["content"])
+				}
+				if parts[0]["type"] != "text" || parts[0]["text"] != "Describe this" {
+					t.Errorf("expected text part first, got %v", parts[0])
+				}
+				urlPart := parts[1]["image_url"].(map[string]any)
+				if urlPart["url"] != "https://example.com/cat.png" {
+					t.Errorf("expected image URL, got %v", urlPart["url"])
+				}
+				dataPart := parts[2]["image_url"].(map[string]any)
+				if dataPart["url"] != "data:image/png;base64,ZmFrZQ==" {
+					t.Errorf("expected data URL, got %v", dataPart["url"])
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1367,3 +1780,436 @@ func TestOpenAI_Generate_WithMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenAI_Generate_ImageOnNonVisionModel(t *testing.T) {
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-3.5-turbo",
+		BaseURL: "http://127.0.0.1:0",
+		Client:  &http.Client{},
+	}
+
+	messages := []core.Message{{
+		Role:    "user",
+		Content: "Describe this",
+		Images:  []core.ImageContent{core.NewImageFromURL("https://example.com/cat.png")},
+	}}
+
+	_, err := lm.Generate(context.Background(), messages, core.DefaultGenerateOptions())
+	if !errors.Is(err, core.ErrImageInputUnsupported) {
+		t.Fatalf("expected ErrImageInputUnsupported, got %v", err)
+	}
+
+	_, errChan := lm.Stream(context.Background(), messages, core.DefaultGenerateOptions())
+	if err := <-errChan; !errors.Is(err, core.ErrImageInputUnsupported) {
+		t.Fatalf("expected ErrImageInputUnsupported from stream, got %v", err)
+	}
+}
+
+func TestOpenAI_ParseResponse_ComputesCost(t *testing.T) {
+	var resp openAIResponse
+	body := `{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	lm := &openAI{Model: "gpt-4o"}
+	result, err := lm.parseResponse(&resp)
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if diff := result.Usage.Cost - 0.0075; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Cost = %f, want 0.0075", result.Usage.Cost)
+	}
+}
+
+func TestOpenAI_ParseResponse_ContentFiltered(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+	}{
+		{
+			name:       "refusal field",
+			body:       `{"choices":[{"message":{"content":null,"refusal":"I can't help with that."},"finish_reason":"stop"}]}`,
+			wantReason: "I can't help with that.",
+		},
+		{
+			name:       "content_filter finish reason",
+			body:       `{"choices":[{"message":{"content":""},"finish_reason":"content_filter"}]}`,
+			wantReason: "finish_reason=content_filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp openAIResponse
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			lm := &openAI{Model: "gpt-4o"}
+			_, err := lm.parseResponse(&resp)
+			var filterErr *core.ContentFilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("expected *core.ContentFilterError, got %v", err)
+			}
+			if filterErr.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", filterErr.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestOpenAI_Stream_ContentFiltered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Sure\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"content_filter\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+	for range chunkChan {
+	}
+	if err := <-errChan; !errors.Is(err, core.ErrContentFiltered) {
+		t.Fatalf("expected ErrContentFiltered, got %v", err)
+	}
+}
+
+func TestOpenAI_Stream_TerminalUsageChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		streamOptions, _ := req["stream_options"].(map[string]any)
+		if streamOptions["include_usage"] != true {
+			t.Errorf("expected stream_options.include_usage to be requested, got %v", req["stream_options"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		// Usage arrives in a final chunk with an empty choices array
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+
+	var content string
+	var usage core.Usage
+	for chunk := range chunkChan {
+		content += chunk.Content
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if content != "Hi" {
+		t.Errorf("expected content 'Hi', got %q", content)
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 3 || usage.TotalTokens != 15 {
+		t.Errorf("expected streamed usage 12/3/15, got %d/%d/%d", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+}
+
+func TestOpenAI_Stream_ReasoningDeltas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"Add two \"},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"and two.\"},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"4\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "2+2?"}}, core.DefaultGenerateOptions())
+
+	var content, reasoning string
+	for chunk := range chunkChan {
+		content += chunk.Content
+		reasoning += chunk.Reasoning
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if reasoning != "Add two and two." {
+		t.Errorf("expected reasoning %q, got %q", "Add two and two.", reasoning)
+	}
+	if content != "4" {
+		t.Errorf("expected content '4', got %q", content)
+	}
+}
+
+func TestOpenAI_Stream_ToolCallArgumentFragments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		// The arguments JSON is split mid-key and mid-value across chunks
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"search\",\"arguments\":\"\"}}]},\"finish_reason\":\"\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"qu\"}}]},\"finish_reason\":\"\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"ery\\\": \\\"go chan\"}}]},\"finish_reason\":\"\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"nels\\\"}\"}}]},\"finish_reason\":\"\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	var received map[string]any
+	tool := core.NewTool("search", "Search the web", func(ctx context.Context, args map[string]any) (any, error) {
+		received = args
+		return "ok", nil
+	}).AddParameter("query", "string", "Search query", true)
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+
+	var calls []core.ToolCall
+	for chunk := range chunkChan {
+		if len(chunk.ToolCalls) > 0 && chunk.FinishReason != "tool_calls" {
+			t.Errorf("tool calls emitted before the stream finished them: %+v", chunk.ToolCalls)
+		}
+		calls = append(calls, chunk.ToolCalls...)
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "search" {
+		t.Fatalf("expected one assembled search call, got %+v", calls)
+	}
+	if _, err := tool.Execute(context.Background(), calls[0].Arguments); err != nil {
+		t.Fatalf("tool execution failed: %v", err)
+	}
+	if received["query"] != "go channels" {
+		t.Errorf("tool received %v, want query \"go channels\"", received)
+	}
+}
+
+func TestOpenAI_Stream_CancelAbortsRequest(t *testing.T) {
+	requestAborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+
+		// Stream until the client goes away, like a long generation
+		for {
+			_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"tok \"}}]}\n\n"))
+			flusher.Flush()
+			select {
+			case <-r.Context().Done():
+				close(requestAborted)
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunkChan, errChan := lm.Stream(ctx, []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+
+	if _, ok := <-chunkChan; !ok {
+		t.Fatal("expected at least one chunk before cancelling")
+	}
+	// Stop reading and cancel; the reader goroutine must not stay blocked on send
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+
+	select {
+	case <-requestAborted:
+	case <-time.After(time.Second):
+		t.Fatal("HTTP request was not aborted after cancel")
+	}
+
+	for range chunkChan {
+	}
+}
+
+func TestOpenAI_Stream_CancelledContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be sent with a cancelled context")
+	}))
+	defer server.Close()
+
+	lm := &openAI{
+		APIKey:  "test-key",
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chunkChan, errChan := lm.Stream(ctx, []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+	for range chunkChan {
+		t.Error("expected no chunks")
+	}
+	if err := <-errChan; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOpenAI_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer good-key":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/models/gpt-4o":
+			_, _ = w.Write([]byte(`{"id": "gpt-4o", "object": "model"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name, key, model string
+		check            func(error) bool
+	}{
+		{"healthy", "good-key", "gpt-4o", func(err error) bool { return err == nil }},
+		{"invalid key", "bad-key", "gpt-4o", func(err error) bool {
+			var authErr *core.AuthError
+			return errors.As(err, &authErr)
+		}},
+		{"unknown model", "good-key", "gpt-9", func(err error) bool { return errors.Is(err, core.ErrModelNotFound) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := &openAI{APIKey: tt.key, Model: tt.model, BaseURL: server.URL, Client: &http.Client{}}
+			if err := lm.HealthCheck(context.Background()); !tt.check(err) {
+				t.Errorf("HealthCheck() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenAI_MaxResponseBytes(t *testing.T) {
+	defer core.ResetConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] == true {
+			for i := 0; i < 100; i++ {
+				_, _ = w.Write([]byte(`data: {"choices": [{"delta": {"content": "endless "}}]}` + "\n\n"))
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "` + strings.Repeat("a", 10_000) + `"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	core.Configure(core.WithProvider("openai"), core.WithBaseURL(server.URL), core.WithMaxResponseBytes(1024))
+	lm := newOpenAI("gpt-4o")
+	lm.APIKey = "test-key"
+	messages := []core.Message{{Role: "user", Content: "Hello"}}
+
+	if _, err := lm.Generate(context.Background(), messages, core.DefaultGenerateOptions()); !errors.Is(err, core.ErrResponseTooLarge) {
+		t.Errorf("Generate() error = %v, want ErrResponseTooLarge", err)
+	}
+
+	chunks, errs := lm.Stream(context.Background(), messages, core.DefaultGenerateOptions())
+	for range chunks {
+	}
+	if err := <-errs; !errors.Is(err, core.ErrResponseTooLarge) {
+		t.Errorf("Stream() error = %v, want ErrResponseTooLarge", err)
+	}
+}
+
+func TestOpenAI_RequestMetadata(t *testing.T) {
+	defer core.ResetConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		want := map[string]any{"team": "search", "trace_id": "abc"}
+		if metadata, _ := req["metadata"].(map[string]any); len(metadata) != len(want) || metadata["team"] != want["team"] || metadata["trace_id"] != want["trace_id"] {
+			t.Errorf("metadata = %v, want %v", req["metadata"], want)
+		}
+		if got := r.Header.Get("X-Request-Source"); got != "batch-job" {
+			t.Errorf("X-Request-Source = %q, want the configured header", got)
+		}
+		if req["stream"] == true {
+			_, _ = w.Write([]byte("data: {\"choices\": [{\"delta\": {\"content\": \"ok\"}, \"finish_reason\": \"stop\"}]}\n\ndata: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "ok"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	core.Configure(
+		core.WithRequestMetadata(map[string]string{"team": "search", "trace_id": "global"}),
+		core.WithRequestHeaders(map[string]string{"X-Request-Source": "batch-job"}),
+	)
+	lm := &openAI{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL, Client: &http.Client{}}
+	ctx := core.WithCallRequestMetadata(context.Background(), map[string]string{"trace_id": "abc"})
+	messages := []core.Message{{Role: "user", Content: "Hello"}}
+
+	if _, err := lm.Generate(ctx, messages, core.DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	chunks, errs := lm.Stream(ctx, messages, core.DefaultGenerateOptions())
+	for range chunks {
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+}
@@ -3,9 +3,11 @@ package openrouter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/assagman/dsgo/core"
 )
@@ -49,9 +51,14 @@ func TestGenerate_EmptyChoices(t *testing.T) {
 	}
 }
 
-// TestGenerate_NullContent tests response with null content
+// TestGenerate_NullContent tests that null content is retried and then reported as an empty response
 func TestGenerate_NullContent(t *testing.T) {
+	defer core.ResetConfig()
+	core.Configure(core.WithBackoff(core.BackoffConfig{InitialDelay: time.Millisecond, MaxRetries: 2}))
+
+	calls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
 		// Return response with null content
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{
@@ -72,12 +79,12 @@ func TestGenerate_NullContent(t *testing.T) {
 	messages := []core.Message{{Role: "user", Content: "test"}}
 	result, err := lm.Generate(context.Background(), messages, nil)
 
-	// Should succeed with empty string content
-	if err != nil {
-		t.Fatalf("Unexpected error: %v", err)
+	var emptyErr *core.EmptyResponseError
+	if !errors.As(err, &emptyErr) || !errors.Is(err, core.ErrEmptyResponse) {
+		t.Fatalf("Generate() = %v, %v, want an EmptyResponseError", result, err)
 	}
-	if result.Content != "" {
-		t.Errorf("Expected empty content for null, got %q", result.Content)
+	if calls != 3 || emptyErr.Attempts != 3 || emptyErr.FinishReason != "stop" {
+		t.Errorf("calls = %d, error = %+v, want 3 attempts (MaxRetries 2)", calls, emptyErr)
 	}
 }
 
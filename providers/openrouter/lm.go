@@ -9,7 +9,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -32,6 +31,7 @@ const (
 // openRouter implements the LM interface for OpenRouter models
 type openRouter struct {
 	APIKey   string
+	Keys     *core.KeyPool // Rotates requests across several keys when set (see core.WithAPIKeys)
 	Model    string
 	BaseURL  string
 	Client   *http.Client
@@ -46,8 +46,9 @@ func newOpenRouter(model string) *openRouter {
 	return &openRouter{
 		APIKey:   apiKey,
 		Model:    model,
-		BaseURL:  defaultBaseURL,
-		Client:   &http.Client{},
+		BaseURL:  core.BaseURLFor("openrouter", defaultBaseURL),
+		Client:   core.HTTPClientFor("openrouter"),
+		Keys:     core.KeyPoolFor("openrouter"),
 		SiteName: os.Getenv("OPENROUTER_SITE_NAME"),
 		SiteURL:  os.Getenv("OPENROUTER_SITE_URL"),
 	}
@@ -68,13 +69,92 @@ func (o *openRouter) SupportsTools() bool {
 	return true
 }
 
+// Capabilities reports what the model supports
+// JSON schema is reported as supported since Generate retries without the schema
+// when an upstream provider rejects it.
+func (o *openRouter) Capabilities() core.Capabilities {
+	window, _ := core.ContextWindowFor(o.Model)
+	return core.Capabilities{
+		SupportsTools:      true,
+		SupportsJSON:       true,
+		SupportsJSONSchema: true,
+		SupportsVision:     core.IsVisionModel(o.Model),
+		SupportsStreaming:  true,
+		MaxContextTokens:   window,
+	}
+}
+
+// HealthCheck verifies the API key with the key endpoint and that the model is listed
+// by the models endpoint, without generating any tokens. Variant suffixes such as
+// ":online" are ignored when the variant itself is not listed.
+func (o *openRouter) HealthCheck(ctx context.Context) error {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
+
+	if err := o.get(ctx, "/key", &struct{}{}); err != nil {
+		return err
+	}
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := o.get(ctx, "/models", &models); err != nil {
+		return err
+	}
+	base, _, _ := strings.Cut(o.Model, ":")
+	for _, model := range models.Data {
+		if model.ID == o.Model || model.ID == base {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: openrouter does not list %q", core.ErrModelNotFound, o.Model)
+}
+
+// get sends an authenticated GET request and decodes the JSON response into out
+func (o *openRouter) get(ctx context.Context, path string, out any) error {
+	resp, _, err := o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.BaseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		core.SetRequestHeaders(ctx, req)
+		return o.Client.Do(req)
+	})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return core.NewAPIError("openrouter", resp.StatusCode, string(body), resp.Header)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
 // SetCache sets the cache instance for this LM
 func (o *openRouter) SetCache(cache core.Cache) {
 	o.Cache = cache
 }
 
-// Generate generates a response from OpenRouter
+// Generate generates a response from OpenRouter, retrying empty completions
+// (see core.RetryEmptyResponses)
 func (o *openRouter) Generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	return core.RetryEmptyResponses(ctx, "openrouter", func() (*core.GenerateResult, error) {
+		return o.generate(ctx, messages, options)
+	})
+}
+
+// generate makes one Generate request
+func (o *openRouter) generate(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (*core.GenerateResult, error) {
+	ctx, cancel := core.WithDefaultTimeout(ctx)
+	defer cancel()
 	startTime := time.Now()
 
 	// Calculate prompt length for logging
@@ -86,6 +166,10 @@ func (o *openRouter) Generate(ctx context.Context, messages []core.Message, opti
 	// Log API request start
 	logging.LogAPIRequest(ctx, o.Model, promptLength)
 
+	if err := o.checkImageSupport(messages); err != nil {
+		return nil, err
+	}
+
 	// Check cache if available
 	if o.Cache != nil {
 		cacheKey := core.GenerateCacheKey(o.Model, messages, options)
@@ -101,20 +185,28 @@ func (o *openRouter) Generate(ctx context.Context, messages []core.Message, opti
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := retry.WithExponentialBackoff(ctx, func() (*http.Response, error) {
-		req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+o.APIKey)
-		if o.SiteName != "" {
-			req.Header.Set("X-Title", o.SiteName)
-		}
-		if o.SiteURL != "" {
-			req.Header.Set("HTTP-Referer", o.SiteURL)
-		}
-		return o.Client.Do(req)
+	// One key for all retries, so a request that succeeded before a dropped response is not billed twice
+	idempotencyKey := core.IdempotencyKeyFor(options)
+	var apiKey string
+	resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (resp *http.Response, err error) {
+		resp, apiKey, err = o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+key)
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+			if o.SiteName != "" {
+				req.Header.Set("X-Title", o.SiteName)
+			}
+			if o.SiteURL != "" {
+				req.Header.Set("HTTP-Referer", o.SiteURL)
+			}
+			core.SetRequestHeaders(ctx, req)
+			return o.Client.Do(req)
+		})
+		return resp, err
 	})
 	if err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
@@ -160,8 +252,9 @@ func (o *openRouter) Generate(ctx context.Context, messages []core.Message, opti
 		fmt.Fprintf(os.Stderr, "\nResponse Body:\n%s\n", string(body))
 		fmt.Fprintf(os.Stderr, "=======================\n\n")
 
-		err := fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		err := core.NewAPIError("openrouter", resp.StatusCode, string(body), resp.Header)
 		logging.LogAPIError(ctx, o.Model, err)
+		o.captureRaw(ctx, reqBody, resp, body, err)
 		return nil, err
 	}
 
@@ -172,42 +265,34 @@ func (o *openRouter) Generate(ctx context.Context, messages []core.Message, opti
 		return nil, fmt.Errorf("failed to read response body: %w", readErr)
 	}
 
-	// Save raw request/response exchange if debug enabled
-	if debugEnv := os.Getenv("DSGO_SAVE_RAW_RESPONSES"); debugEnv == "1" || debugEnv == "true" {
-		if err := saveRawExchange(o.Model, reqBody, resp.StatusCode, resp.Header, bodyBytes, nil); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save raw exchange: %v\n", err)
-		}
-	}
-
 	var apiResp openRouterResponse
 	if err := json.Unmarshal(bodyBytes, &apiResp); err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
-		// Save failed response for debugging
-		if err := saveRawExchange(o.Model+"_DECODE_FAILED", reqBody, resp.StatusCode, resp.Header, bodyBytes, err); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save failed exchange: %v\n", err)
-		}
+		o.captureRaw(ctx, reqBody, resp, bodyBytes, err)
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	result, err := o.parseResponse(&apiResp)
 	if err != nil {
 		logging.LogAPIError(ctx, o.Model, err)
-		// Save raw response on parse error
-		if err := saveRawExchange(o.Model+"_PARSE_FAILED", reqBody, resp.StatusCode, resp.Header, bodyBytes, err); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save failed exchange: %v\n", err)
-		}
+		o.captureRaw(ctx, reqBody, resp, bodyBytes, err)
 		return nil, err
 	}
 
+	o.captureRaw(ctx, reqBody, resp, bodyBytes, nil)
+
 	// Extract metadata from response headers
 	result.Metadata = o.extractMetadata(resp.Header)
+	if o.Keys != nil {
+		result.Metadata["api_key"] = core.MaskAPIKey(apiKey)
+	}
 
 	// Log API response
 	duration := time.Since(startTime)
 	logging.LogAPIResponse(ctx, o.Model, resp.StatusCode, duration, result.Usage)
 
-	// Store in cache if available
-	if o.Cache != nil {
+	// Store in cache if available; empty completions are retried instead
+	if o.Cache != nil && !core.IsEmptyResult(result) {
 		cacheKey := core.GenerateCacheKey(o.Model, messages, options)
 		o.Cache.Set(cacheKey, result)
 	}
@@ -260,6 +345,16 @@ func (o *openRouter) buildRequest(messages []core.Message, options *core.Generat
 	if options.PresencePenalty != 0 {
 		req["presence_penalty"] = options.PresencePenalty
 	}
+	if len(options.LogitBias) > 0 {
+		req["logit_bias"] = options.LogitBias
+	}
+	// OpenRouter maps its unified reasoning parameter to each model's native control
+	// (e.g. Gemini thinkingConfig) and accepts either a budget or an effort, not both
+	if options.ThinkingBudgetTokens > 0 {
+		req["reasoning"] = map[string]any{"max_tokens": options.ThinkingBudgetTokens}
+	} else if options.ReasoningEffort != "" {
+		req["reasoning"] = map[string]any{"effort": options.ReasoningEffort}
+	}
 
 	// Add tools if supported
 	if len(options.Tools) > 0 {
@@ -317,6 +412,9 @@ func (o *openRouter) convertMessages(messages []core.Message) []map[string]any {
 				})
 			}
 			m["tool_calls"] = toolCalls
+		} else if len(msg.Images) > 0 {
+			// Multimodal message: text part followed by image parts
+			m["content"] = convertContentParts(msg)
 		} else {
 			// Regular message
 			m["content"] = msg.Content
@@ -327,37 +425,36 @@ func (o *openRouter) convertMessages(messages []core.Message) []map[string]any {
 	return converted
 }
 
-func (o *openRouter) convertTool(tool *core.Tool) map[string]any {
-	properties := make(map[string]any)
-	required := []string{}
-
-	for _, param := range tool.Parameters {
-		prop := map[string]any{
-			"type":        param.Type,
-			"description": param.Description,
-		}
-		if len(param.Enum) > 0 {
-			prop["enum"] = param.Enum
-		}
-		properties[param.Name] = prop
-
-		if param.Required {
-			required = append(required, param.Name)
-		}
+// convertContentParts builds the multimodal content array for a message with images
+func convertContentParts(msg core.Message) []map[string]any {
+	parts := make([]map[string]any, 0, len(msg.Images)+1)
+	if msg.Content != "" {
+		parts = append(parts, map[string]any{
+			"type": "text",
+			"text": msg.Content,
+		})
 	}
-
-	return map[string]any{
-		"type": "function",
-		"function": map[string]any{
-			"name":        tool.Name,
-			"description": tool.Description,
-			"parameters": map[string]any{
-				"type":       "object",
-				"properties": properties,
-				"required":   required,
+	for _, img := range msg.Images {
+		parts = append(parts, map[string]any{
+			"type": "image_url",
+			"image_url": map[string]any{
+				"url": img.DataURL(),
 			},
-		},
+		})
 	}
+	return parts
+}
+
+// checkImageSupport rejects image inputs for models without vision support
+func (o *openRouter) checkImageSupport(messages []core.Message) error {
+	if core.HasImages(messages) && !core.IsVisionModel(o.Model) {
+		return fmt.Errorf("%w: %s (use a vision model such as gpt-4o, or register it with core.RegisterVisionModel)", core.ErrImageInputUnsupported, o.Model)
+	}
+	return nil
+}
+
+func (o *openRouter) convertTool(tool *core.Tool) map[string]any {
+	return tool.FunctionSchema()
 }
 
 func (o *openRouter) parseResponse(resp *openRouterResponse) (*core.GenerateResult, error) {
@@ -375,15 +472,22 @@ func (o *openRouter) parseResponse(resp *openRouterResponse) (*core.GenerateResu
 	}
 
 	choice := resp.Choices[0]
+	// Refusals and moderation blocks come back as ordinary (often empty) completions
+	if err := core.CheckContentFilter("openrouter", choice.FinishReason, choice.Message.Refusal); err != nil {
+		return nil, err
+	}
 	result := &core.GenerateResult{
 		Content:      choice.Message.Content,
+		Reasoning:    choice.Message.reasoning(),
 		FinishReason: choice.FinishReason,
 		Usage: core.Usage{
 			PromptTokens:     resp.Usage.PromptTokens,
 			CompletionTokens: resp.Usage.CompletionTokens,
 			TotalTokens:      resp.Usage.TotalTokens,
+			Cost:             resp.Usage.Cost,
 		},
 	}
+	core.FillCost(o.Model, &result.Usage)
 
 	// Parse tool calls if present
 	if len(choice.Message.ToolCalls) > 0 {
@@ -445,8 +549,15 @@ func (o *openRouter) Stream(ctx context.Context, messages []core.Message, option
 		defer close(chunkChan)
 		defer close(errChan)
 
+		if err := o.checkImageSupport(messages); err != nil {
+			errChan <- err
+			return
+		}
+
 		reqBody := o.buildRequest(messages, options)
 		reqBody["stream"] = true
+		// Ask for the terminal usage chunk, which is otherwise omitted when streaming
+		reqBody["stream_options"] = map[string]any{"include_usage": true}
 
 		bodyBytes, err := json.Marshal(reqBody)
 		if err != nil {
@@ -454,22 +565,29 @@ func (o *openRouter) Stream(ctx context.Context, messages []core.Message, option
 			return
 		}
 
-		resp, err := retry.WithExponentialBackoff(ctx, func() (*http.Response, error) {
-			req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
-			if err != nil {
-				return nil, err
-			}
+		// Same key on every retry, as in Generate
+		idempotencyKey := core.IdempotencyKeyFor(options)
+		resp, err := retry.WithBackoff(ctx, retry.Policy(core.RetryBackoff()), func() (*http.Response, error) {
+			resp, _, err := o.Keys.Do(o.APIKey, func(key string) (*http.Response, error) {
+				req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(bodyBytes))
+				if err != nil {
+					return nil, err
+				}
 
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("Authorization", "Bearer "+o.APIKey)
-			if o.SiteName != "" {
-				req.Header.Set("X-Title", o.SiteName)
-			}
-			if o.SiteURL != "" {
-				req.Header.Set("HTTP-Referer", o.SiteURL)
-			}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+key)
+				req.Header.Set("Idempotency-Key", idempotencyKey)
+				if o.SiteName != "" {
+					req.Header.Set("X-Title", o.SiteName)
+				}
+				if o.SiteURL != "" {
+					req.Header.Set("HTTP-Referer", o.SiteURL)
+				}
+				core.SetRequestHeaders(ctx, req)
 
-			return o.Client.Do(req)
+				return o.Client.Do(req)
+			})
+			return resp, err
 		})
 		if err != nil {
 			errChan <- fmt.Errorf("request failed: %w", err)
@@ -492,67 +610,131 @@ func (o *openRouter) Stream(ctx context.Context, messages []core.Message, option
 			fmt.Fprintf(os.Stderr, "\nResponse Body:\n%s\n", string(body))
 			fmt.Fprintf(os.Stderr, "==================================\n\n")
 
-			errChan <- fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			err := core.NewAPIError("openrouter", resp.StatusCode, string(body), resp.Header)
+			o.captureRaw(ctx, reqBody, resp, body, err)
+			errChan <- err
 			return
 		}
 
-		// Read SSE stream
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
+		// Tee the SSE body so the assembled stream is captured once it ends
+		var raw bytes.Buffer
+		body := io.Reader(resp.Body)
+		capture := core.RawCaptureDir() != ""
+		if capture {
+			body = io.TeeReader(resp.Body, &raw)
+		}
+		err = o.readStream(ctx, body, chunkChan)
+		if capture {
+			o.captureRaw(ctx, reqBody, resp, raw.Bytes(), err)
+		}
+		if err != nil {
+			errChan <- err
+		}
+	}()
 
-			// Skip empty lines
-			if line == "" {
-				continue
-			}
+	return chunkChan, errChan
+}
 
-			// Parse SSE format: "data: {json}"
-			if !strings.HasPrefix(line, "data: ") {
-				continue
-			}
+// readStream decodes the SSE body into chunkChan until [DONE] or EOF
+func (o *openRouter) readStream(ctx context.Context, body io.Reader, chunkChan chan<- core.Chunk) error {
+	toolCalls := core.NewToolCallAccumulator()
+	var refusal strings.Builder
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
 
-			data := strings.TrimPrefix(line, "data: ")
+		// Parse SSE format: "data: {json}"
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
 
-			// Check for stream end
-			if data == "[DONE]" {
-				break
-			}
+		data := strings.TrimPrefix(line, "data: ")
 
-			// Parse JSON chunk
-			var streamResp openRouterStreamResponse
-			if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
-				errChan <- fmt.Errorf("failed to parse stream chunk: %w", err)
-				return
-			}
+		// Check for stream end
+		if data == "[DONE]" {
+			break
+		}
 
-			// Extract chunk data
-			if len(streamResp.Choices) > 0 {
-				choice := streamResp.Choices[0]
-				chunk := core.Chunk{
-					Content:      choice.Delta.Content,
-					FinishReason: choice.FinishReason,
+		// Parse JSON chunk
+		var streamResp openRouterStreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		// The terminal usage chunk has no choices, so handle usage independently
+		if len(streamResp.Choices) == 0 && streamResp.Usage == nil {
+			continue
+		}
+
+		var chunk core.Chunk
+		if len(streamResp.Choices) > 0 {
+			choice := streamResp.Choices[0]
+			chunk.Content = choice.Delta.Content
+			chunk.Reasoning = choice.Delta.reasoning()
+			chunk.FinishReason = choice.FinishReason
+			refusal.WriteString(choice.Delta.Refusal)
+			if choice.FinishReason != "" {
+				if err := core.CheckContentFilter("openrouter", choice.FinishReason, refusal.String()); err != nil {
+					return err
 				}
+			}
 
-				// Add usage if present (typically in last chunk)
-				if streamResp.Usage != nil {
-					chunk.Usage = core.Usage{
-						PromptTokens:     streamResp.Usage.PromptTokens,
-						CompletionTokens: streamResp.Usage.CompletionTokens,
-						TotalTokens:      streamResp.Usage.TotalTokens,
-					}
+			for _, tc := range choice.Delta.ToolCalls {
+				toolCalls.Add(tc.Index, tc.ID, tc.Function.Name, tc.Function.Arguments)
+			}
+			// Arguments arrive in fragments; only emit the calls once the model has finished them
+			if choice.FinishReason != "" && toolCalls.Pending() {
+				var err error
+				if chunk.ToolCalls, err = toolCalls.Flush(); err != nil {
+					return err
 				}
+			}
+		}
 
-				chunkChan <- chunk
+		// Add usage if present (typically in last chunk)
+		if streamResp.Usage != nil {
+			chunk.Usage = core.Usage{
+				PromptTokens:     streamResp.Usage.PromptTokens,
+				CompletionTokens: streamResp.Usage.CompletionTokens,
+				TotalTokens:      streamResp.Usage.TotalTokens,
+				Cost:             streamResp.Usage.Cost,
 			}
+			core.FillCost(o.Model, &chunk.Usage)
 		}
 
-		if err := scanner.Err(); err != nil {
-			errChan <- fmt.Errorf("stream reading error: %w", err)
-			return
+		// Stop reading as soon as the caller cancels; the deferred Close in Stream aborts the request
+		select {
+		case chunkChan <- chunk:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-	}()
+	}
 
-	return chunkChan, errChan
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("stream reading error: %w", err)
+	}
+
+	// The stream ended without a finish reason; release any calls still buffered
+	if toolCalls.Pending() {
+		calls, err := toolCalls.Flush()
+		if err != nil {
+			return err
+		}
+		select {
+		case chunkChan <- core.Chunk{ToolCalls: calls, FinishReason: "tool_calls"}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
 }
 
 // OpenRouter API response structures
@@ -567,16 +749,29 @@ type openRouterResponse struct {
 		FinishReason string            `json:"finish_reason"`
 	} `json:"choices"`
 	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
+		PromptTokens     int     `json:"prompt_tokens"`
+		CompletionTokens int     `json:"completion_tokens"`
+		TotalTokens      int     `json:"total_tokens"`
+		Cost             float64 `json:"cost"` // Reported when usage accounting is enabled
 	} `json:"usage"`
 }
 
 type openRouterMessage struct {
-	Role      string               `json:"role"`
-	Content   string               `json:"content"`
-	ToolCalls []openRouterToolCall `json:"tool_calls,omitempty"`
+	Role             string               `json:"role"`
+	Content          string               `json:"content"`
+	Refusal          string               `json:"refusal,omitempty"` // Set instead of Content when the model refuses
+	Reasoning        string               `json:"reasoning,omitempty"`
+	ReasoningContent string               `json:"reasoning_content,omitempty"`
+	ToolCalls        []openRouterToolCall `json:"tool_calls,omitempty"`
+}
+
+// reasoning returns the model's thinking; OpenRouter normalizes it to "reasoning",
+// but some upstream providers pass through "reasoning_content"
+func (m openRouterMessage) reasoning() string {
+	if m.Reasoning != "" {
+		return m.Reasoning
+	}
+	return m.ReasoningContent
 }
 
 type openRouterToolCall struct {
@@ -588,23 +783,49 @@ type openRouterToolCall struct {
 	} `json:"function"`
 }
 
+// openRouterToolCallDelta is one streamed fragment of a tool call, identified by Index
+type openRouterToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openRouterDelta is the incremental message of a streamed choice
+type openRouterDelta struct {
+	Content          string                    `json:"content"`
+	Reasoning        string                    `json:"reasoning,omitempty"`
+	ReasoningContent string                    `json:"reasoning_content,omitempty"`
+	Refusal          string                    `json:"refusal,omitempty"`
+	Role             string                    `json:"role,omitempty"`
+	ToolCalls        []openRouterToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// reasoning returns the streamed thinking, under either field name (see openRouterMessage.reasoning)
+func (d openRouterDelta) reasoning() string {
+	if d.Reasoning != "" {
+		return d.Reasoning
+	}
+	return d.ReasoningContent
+}
+
 type openRouterStreamResponse struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
 	Choices []struct {
-		Index int `json:"index"`
-		Delta struct {
-			Content string `json:"content"`
-			Role    string `json:"role,omitempty"`
-		} `json:"delta"`
-		FinishReason string `json:"finish_reason"`
+		Index        int             `json:"index"`
+		Delta        openRouterDelta `json:"delta"`
+		FinishReason string          `json:"finish_reason"`
 	} `json:"choices"`
 	Usage *struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
+		PromptTokens     int     `json:"prompt_tokens"`
+		CompletionTokens int     `json:"completion_tokens"`
+		TotalTokens      int     `json:"total_tokens"`
+		Cost             float64 `json:"cost"`
 	} `json:"usage,omitempty"`
 }
 
@@ -700,67 +921,19 @@ func debugEnabled() bool {
 	return d == "1" || strings.ToLower(d) == "true"
 }
 
-// saveRawExchange saves complete request/response exchange to a file for debugging
-func saveRawExchange(model string, request map[string]any, statusCode int, headers http.Header, responseBody []byte, saveErr error) error {
-	// Determine output directory - prefer DSGO_ARTIFACT_DIR, fallback to test_matrix_logs
-	baseDir := os.Getenv("DSGO_ARTIFACT_DIR")
-	if baseDir == "" {
-		baseDir = "test_matrix_logs"
-	}
-	rawDir := filepath.Join(baseDir, "raw")
-	if err := os.MkdirAll(rawDir, 0755); err != nil {
-		return err
-	}
-
-	// Parse response body as JSON if possible
-	var responseJSON any
-	var responseText string
-	if err := json.Unmarshal(responseBody, &responseJSON); err != nil {
-		responseText = string(responseBody)
-	}
-
-	// Build complete exchange record
-	exchange := map[string]any{
-		"timestamp": time.Now().Format(time.RFC3339Nano),
-		"provider":  "openrouter",
-		"model":     model,
-		"example":   os.Getenv("DSGO_EXAMPLE"),
-		"request":   request,
-		"http": map[string]any{
-			"status":  statusCode,
-			"headers": headers,
-		},
-	}
-
-	if responseJSON != nil {
-		exchange["response"] = responseJSON
-	} else {
-		exchange["response_text"] = responseText
-	}
-
+// captureRaw saves the exchange when raw response capture is enabled (see core.WithRawResponseCapture)
+func (o *openRouter) captureRaw(ctx context.Context, request map[string]any, resp *http.Response, body []byte, err error) {
+	_, saveErr := core.CaptureRawExchange(core.RawExchange{
+		Provider:   "openrouter",
+		Model:      o.Model,
+		RequestID:  logging.GetRequestID(ctx),
+		Request:    request,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Err:        err,
+	})
 	if saveErr != nil {
-		exchange["error"] = saveErr.Error()
+		fmt.Fprintf(os.Stderr, "Warning: failed to save raw exchange: %v\n", saveErr)
 	}
-
-	// Create filename with timestamp and model
-	timestamp := time.Now().Format("20060102_150405.000000")
-	safeModel := strings.ReplaceAll(model, "/", "_")
-	safeModel = strings.ReplaceAll(safeModel, ":", "_")
-	filename := filepath.Join(rawDir, fmt.Sprintf("%s_openrouter_%s.json", timestamp, safeModel))
-
-	// Marshal with 2-space indentation
-	exchangeJSON, err := json.MarshalIndent(exchange, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	// Write to file
-	if err := os.WriteFile(filename, exchangeJSON, 0644); err != nil {
-		return err
-	}
-
-	if os.Getenv("DSGO_ARTIFACT_DIR") != "" {
-		fmt.Fprintf(os.Stderr, "📝 Raw exchange saved to: %s\n", filename)
-	}
-	return nil
 }
@@ -3,12 +3,17 @@ package openrouter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/logging"
 )
 
 func TestNewOpenRouter(t *testing.T) {
@@ -209,9 +214,10 @@ func TestOpenRouter_Generate_Success(t *testing.T) {
 				},
 			},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{
 				PromptTokens:     10,
 				CompletionTokens: 5,
@@ -249,6 +255,49 @@ func TestOpenRouter_Generate_Success(t *testing.T) {
 	}
 }
 
+func TestOpenRouter_Generate_RotatesAPIKeys(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		mu.Lock()
+		calls[auth]++
+		mu.Unlock()
+		if auth == "Bearer sk-limited-1111" {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Key limit exceeded"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	lm := &openRouter{
+		Keys:    core.NewKeyPool([]string{"sk-limited-1111", "sk-spare-2222"}, core.KeyRoundRobin),
+		Model:   "gpt-4",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+	messages := []core.Message{{Role: "user", Content: "Hello"}}
+
+	for i := 0; i < 3; i++ {
+		result, err := lm.Generate(context.Background(), messages, core.DefaultGenerateOptions())
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result.Metadata["api_key"] != "...2222" {
+			t.Errorf("api_key metadata = %v, want ...2222", result.Metadata["api_key"])
+		}
+	}
+	if calls["Bearer sk-limited-1111"] != 1 {
+		t.Errorf("rate-limited key used %d times, want 1 (parked after its 429)", calls["Bearer sk-limited-1111"])
+	}
+	if calls["Bearer sk-spare-2222"] != 3 {
+		t.Errorf("spare key used %d times, want 3", calls["Bearer sk-spare-2222"])
+	}
+}
+
 func TestOpenRouter_Generate_WithHeaders(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("X-Title") != "test-site" {
@@ -265,9 +314,10 @@ func TestOpenRouter_Generate_WithHeaders(t *testing.T) {
 				FinishReason string            `json:"finish_reason"`
 			}{{Message: openRouterMessage{Content: "ok"}, FinishReason: "stop"}},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -288,6 +338,32 @@ func TestOpenRouter_Generate_WithHeaders(t *testing.T) {
 	}
 }
 
+func TestOpenRouter_Generate_RequestHeaders(t *testing.T) {
+	defer core.ResetConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Title"); got != "per-call-app" {
+			t.Errorf("X-Title = %q, want the per-call header over SiteName", got)
+		}
+		if got := r.Header.Get("X-Trace-Id"); got != "trace-123" {
+			t.Errorf("X-Trace-Id = %q, want the configured header", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want the API key", got)
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "ok"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	core.Configure(core.WithRequestHeaders(map[string]string{"X-Trace-Id": "trace-123", "authorization": "Bearer stolen"}))
+	lm := &openRouter{APIKey: "test-key", BaseURL: server.URL, Client: &http.Client{}, SiteName: "test-site"}
+
+	ctx := core.WithCallRequestHeaders(context.Background(), map[string]string{"X-Title": "per-call-app"})
+	if _, err := lm.Generate(ctx, []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+}
+
 func TestOpenRouter_Generate_WithTools(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req map[string]interface{}
@@ -324,9 +400,10 @@ func TestOpenRouter_Generate_WithTools(t *testing.T) {
 				},
 			},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -392,9 +469,10 @@ func TestOpenRouter_Generate_ToolCallsWithMalformedJSON(t *testing.T) {
 				},
 			},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -595,6 +673,33 @@ func TestOpenRouter_BuildRequest(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "with reasoning effort",
+			messages: []core.Message{{Role: "user", Content: "test"}},
+			options: &core.GenerateOptions{
+				ReasoningEffort: core.ReasoningEffortHigh,
+			},
+			check: func(t *testing.T, req map[string]interface{}) {
+				reasoning, ok := req["reasoning"].(map[string]any)
+				if !ok || reasoning["effort"] != "high" {
+					t.Errorf("expected reasoning effort high, got %v", req["reasoning"])
+				}
+			},
+		},
+		{
+			name:     "thinking budget takes precedence over effort",
+			messages: []core.Message{{Role: "user", Content: "test"}},
+			options: &core.GenerateOptions{
+				ReasoningEffort:      core.ReasoningEffortHigh,
+				ThinkingBudgetTokens: 2048,
+			},
+			check: func(t *testing.T, req map[string]interface{}) {
+				reasoning, ok := req["reasoning"].(map[string]any)
+				if !ok || reasoning["max_tokens"] != 2048 || reasoning["effort"] != nil {
+					t.Errorf("expected only a 2048 token reasoning budget, got %v", req["reasoning"])
+				}
+			},
+		},
 		{
 			name:     "with penalties",
 			messages: []core.Message{{Role: "user", Content: "test"}},
@@ -682,6 +787,36 @@ func TestOpenRouter_ConvertMessages(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "user message with images",
+			messages: []core.Message{
+				{
+					Role:    "user",
+					Content: "Describe this",
+					Images: []core.ImageContent{
+						core.NewImageFromURL("https://example.com/cat.png"),
+						core.NewImageFromBytes([]byte("fake"), "image/png"),
+					},
+				},
+			},
+			check: func(t *testing.T, converted []map[string]interface{}) {
+				parts, ok := converted[0]["content"].([]map[string]any)
+				if !ok || len(parts) != 3 {
+					t.Fatalf("expected 3 content parts, got %v", converted[0]["content"])
+				}
+				if parts[0]["type"] != "text" || parts[0]["text"] != "Describe this" {
+					t.Errorf("expected text part first, got %v", parts[0])
+				}
+				urlPart := parts[1]["image_url"].(map[string]any)
+				if urlPart["url"] != "https://example.com/cat.png" {
+					t.Errorf("expected image URL, got %v", urlPart["url"])
+				}
+				dataPart := parts[2]["image_url"].(map[string]any)
+				if dataPart["url"] != "data:image/png;base64,ZmFrZQ==" {
+					t.Errorf("expected data URL, got %v", dataPart["url"])
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -763,9 +898,10 @@ func TestOpenRouter_ParseResponse_InvalidToolArgs(t *testing.T) {
 			},
 		},
 		Usage: struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
+			PromptTokens     int     `json:"prompt_tokens"`
+			CompletionTokens int     `json:"completion_tokens"`
+			TotalTokens      int     `json:"total_tokens"`
+			Cost             float64 `json:"cost"`
 		}{},
 	}
 
@@ -795,9 +931,10 @@ func TestOpenRouter_Generate_WithToolChoice(t *testing.T) {
 				FinishReason string            `json:"finish_reason"`
 			}{{Message: openRouterMessage{Content: "ok"}, FinishReason: "stop"}},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -837,9 +974,10 @@ func TestOpenRouter_Generate_ToolChoiceNone(t *testing.T) {
 				FinishReason string            `json:"finish_reason"`
 			}{{Message: openRouterMessage{Content: "ok"}, FinishReason: "stop"}},
 			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
+				PromptTokens     int     `json:"prompt_tokens"`
+				CompletionTokens int     `json:"completion_tokens"`
+				TotalTokens      int     `json:"total_tokens"`
+				Cost             float64 `json:"cost"`
 			}{},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -952,3 +1090,243 @@ func TestOpenRouter_Stream_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestOpenRouter_Generate_ImageOnNonVisionModel(t *testing.T) {
+	lm := &openRouter{
+		APIKey:  "test-key",
+		Model:   "meta-llama/llama-3.3-70b-instruct",
+		BaseURL: "http://127.0.0.1:0",
+		Client:  &http.Client{},
+	}
+
+	messages := []core.Message{{
+		Role:    "user",
+		Content: "Describe this",
+		Images:  []core.ImageContent{core.NewImageFromURL("https://example.com/cat.png")},
+	}}
+
+	_, err := lm.Generate(context.Background(), messages, core.DefaultGenerateOptions())
+	if !errors.Is(err, core.ErrImageInputUnsupported) {
+		t.Fatalf("expected ErrImageInputUnsupported, got %v", err)
+	}
+
+	_, errChan := lm.Stream(context.Background(), messages, core.DefaultGenerateOptions())
+	if err := <-errChan; !errors.Is(err, core.ErrImageInputUnsupported) {
+		t.Fatalf("expected ErrImageInputUnsupported from stream, got %v", err)
+	}
+}
+
+func TestOpenRouter_ParseResponse_Cost(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		body     string
+		wantCost float64
+	}{
+		{
+			name:     "reported cost is kept",
+			model:    "openai/gpt-4o",
+			body:     `{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500,"cost":0.42}}`,
+			wantCost: 0.42,
+		},
+		{
+			name:     "missing cost is computed from pricing",
+			model:    "openai/gpt-4o",
+			body:     `{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`,
+			wantCost: 0.0075,
+		},
+		{
+			name:     "unknown model stays zero",
+			model:    "acme/unpriced-model",
+			body:     `{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`,
+			wantCost: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp openRouterResponse
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			lm := &openRouter{Model: tt.model}
+			result, err := lm.parseResponse(&resp)
+			if err != nil {
+				t.Fatalf("parseResponse() error = %v", err)
+			}
+			if diff := result.Usage.Cost - tt.wantCost; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Cost = %f, want %f", result.Usage.Cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestOpenRouter_Stream_TerminalUsageChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		streamOptions, _ := req["stream_options"].(map[string]any)
+		if streamOptions["include_usage"] != true {
+			t.Errorf("expected stream_options.include_usage to be requested, got %v", req["stream_options"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		// Usage arrives in a final chunk with an empty choices array
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openRouter{
+		APIKey:  "test-key",
+		Model:   "test-model",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "test"}}, core.DefaultGenerateOptions())
+
+	var content string
+	var usage core.Usage
+	for chunk := range chunkChan {
+		content += chunk.Content
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if content != "Hi" {
+		t.Errorf("expected content 'Hi', got %q", content)
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 3 || usage.TotalTokens != 15 {
+		t.Errorf("expected streamed usage 12/3/15, got %d/%d/%d", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+}
+
+func TestOpenRouter_Stream_ReasoningDeltas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		// Upstream providers name the field either way
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning\":\"Add two \"},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"and two.\"},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"4\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openRouter{
+		APIKey:  "test-key",
+		Model:   "test-model",
+		BaseURL: server.URL,
+		Client:  &http.Client{},
+	}
+
+	chunkChan, errChan := lm.Stream(context.Background(), []core.Message{{Role: "user", Content: "2+2?"}}, core.DefaultGenerateOptions())
+
+	var content, reasoning string
+	for chunk := range chunkChan {
+		content += chunk.Content
+		reasoning += chunk.Reasoning
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if reasoning != "Add two and two." {
+		t.Errorf("expected reasoning %q, got %q", "Add two and two.", reasoning)
+	}
+	if content != "4" {
+		t.Errorf("expected content '4', got %q", content)
+	}
+}
+
+func TestOpenRouter_Stream_RawResponseCapture(t *testing.T) {
+	core.ResetConfig()
+	defer core.ResetConfig()
+	dir := t.TempDir()
+	core.Configure(core.WithRawResponseCapture(dir))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	lm := &openRouter{APIKey: "test-key", Model: "test-model", BaseURL: server.URL, Client: &http.Client{}}
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	chunkChan, errChan := lm.Stream(ctx, []core.Message{{Role: "user", Content: "Hello"}}, core.DefaultGenerateOptions())
+	for range chunkChan {
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "req-1", "*_openrouter_test-model.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one captured exchange, got %v (%v)", files, err)
+	}
+	data, _ := os.ReadFile(files[0])
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	body, _ := record["response_text"].(string)
+	if !strings.Contains(body, `"content":"Hel"`) || !strings.Contains(body, "data: [DONE]") {
+		t.Errorf("expected the whole SSE body in the capture, got %q", body)
+	}
+	if record["request"] == nil || record["error"] != nil {
+		t.Errorf("expected the request and no error in capture, got %v", record)
+	}
+}
+
+func TestOpenRouter_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/key":
+			if r.Header.Get("Authorization") != "Bearer good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error": {"message": "No auth credentials found"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"label": "test"}}`))
+		case "/models":
+			_, _ = w.Write([]byte(`{"data": [{"id": "z-ai/glm-4.6"}, {"id": "openai/gpt-4o"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name, key, model string
+		check            func(error) bool
+	}{
+		{"healthy", "good-key", "z-ai/glm-4.6", func(err error) bool { return err == nil }},
+		{"variant", "good-key", "openai/gpt-4o:online", func(err error) bool { return err == nil }},
+		{"invalid key", "bad-key", "z-ai/glm-4.6", func(err error) bool {
+			var authErr *core.AuthError
+			return errors.As(err, &authErr)
+		}},
+		{"unknown model", "good-key", "z-ai/glm-9", func(err error) bool { return errors.Is(err, core.ErrModelNotFound) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := &openRouter{APIKey: tt.key, Model: tt.model, BaseURL: server.URL, Client: &http.Client{}}
+			if err := lm.HealthCheck(context.Background()); !tt.check(err) {
+				t.Errorf("HealthCheck() error = %v", err)
+			}
+		})
+	}
+}
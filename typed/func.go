@@ -2,6 +2,7 @@ package typed
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 
@@ -208,6 +209,139 @@ func (f *Func[I, O]) RunWithPrediction(ctx context.Context, input I) (O, *core.P
 	return output, pred, nil
 }
 
+// StreamResult holds the channels of a typed streaming run
+type StreamResult[O any] struct {
+	Chunks <-chan core.Chunk // Incremental content as it arrives
+	Output <-chan O          // Final typed output (sent after the stream completes)
+	Errors <-chan error      // Streaming, parsing or conversion errors
+}
+
+// Stream executes the typed module with streaming output, mirroring Predict.Stream
+// Chunks are forwarded as they arrive; the final prediction is converted to O
+// the same way as Run. Drain Chunks before waiting on Output or Errors.
+// Only modules created with NewPredict support streaming.
+func (f *Func[I, O]) Stream(ctx context.Context, input I) (*StreamResult[O], error) {
+	predict, ok := f.module.(*module.Predict)
+	if !ok {
+		return nil, fmt.Errorf("streaming is not supported for %T", f.module)
+	}
+
+	// Convert input struct to map
+	inputMap, err := StructToMap(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input to map: %w", err)
+	}
+
+	result, err := predict.Stream(ctx, inputMap)
+	if err != nil {
+		return nil, fmt.Errorf("module execution failed: %w", err)
+	}
+
+	outputChan := make(chan O, 1)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(outputChan)
+		defer close(errorChan)
+
+		pred, ok := <-result.Prediction
+		if !ok {
+			if err := <-result.Errors; err != nil {
+				errorChan <- fmt.Errorf("module execution failed: %w", err)
+			}
+			return
+		}
+
+		// Convert output map to struct
+		var output O
+		if err := MapToStruct(pred.Outputs, &output); err != nil {
+			errorChan <- fmt.Errorf("failed to convert output to struct: %w", err)
+			return
+		}
+		outputChan <- output
+	}()
+
+	return &StreamResult[O]{
+		Chunks: result.Chunks,
+		Output: outputChan,
+		Errors: errorChan,
+	}, nil
+}
+
+// ReActStreamResult holds the channels of a typed streaming ReAct run
+type ReActStreamResult[O any] struct {
+	Events <-chan module.ReActEvent // Thoughts, tool calls and observations as they happen
+	Output <-chan O                 // Final typed output (sent after the loop completes)
+	Errors <-chan error             // Fatal, parsing or conversion errors
+}
+
+// StreamReAct executes the typed ReAct module, mirroring ReAct.Stream
+// Progress events are forwarded as they happen (decode tool arguments with
+// ToolArgs); the final prediction is converted to O the same way as Run.
+// Drain Events before waiting on Output or Errors.
+// Only modules created with NewReAct support it.
+func (f *Func[I, O]) StreamReAct(ctx context.Context, input I) (*ReActStreamResult[O], error) {
+	react, ok := f.module.(*module.ReAct)
+	if !ok {
+		return nil, fmt.Errorf("ReAct streaming is not supported for %T", f.module)
+	}
+
+	// Convert input struct to map
+	inputMap, err := StructToMap(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input to map: %w", err)
+	}
+
+	result, err := react.Stream(ctx, inputMap)
+	if err != nil {
+		return nil, fmt.Errorf("module execution failed: %w", err)
+	}
+
+	outputChan := make(chan O, 1)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(outputChan)
+		defer close(errorChan)
+
+		pred, ok := <-result.Prediction
+		if !ok {
+			if err := <-result.Errors; err != nil {
+				errorChan <- fmt.Errorf("module execution failed: %w", err)
+			}
+			return
+		}
+
+		// Convert output map to struct
+		var output O
+		if err := MapToStruct(pred.Outputs, &output); err != nil {
+			errorChan <- fmt.Errorf("failed to convert output to struct: %w", err)
+			return
+		}
+		outputChan <- output
+	}()
+
+	return &ReActStreamResult[O]{
+		Events: result.Events,
+		Output: outputChan,
+		Errors: errorChan,
+	}, nil
+}
+
+// ToolArgs decodes the arguments of a ToolCallStarted event into T using their JSON
+// form, so T is usually a struct with json tags matching the tool's parameters
+func ToolArgs[T any](event module.ReActEvent) (T, error) {
+	var args T
+	data, err := json.Marshal(event.ToolArgs)
+	if err != nil {
+		return args, fmt.Errorf("failed to encode %s arguments: %w", event.ToolName, err)
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return args, fmt.Errorf("failed to decode %s arguments: %w", event.ToolName, err)
+	}
+	return args, nil
+}
+
 // WithOptions sets custom generation options
 // Works with all module types (Predict, ChainOfThought, ReAct, etc.)
 func (f *Func[I, O]) WithOptions(options *core.GenerateOptions) *Func[I, O] {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/assagman/dsgo/core"
+	"github.com/assagman/dsgo/module"
 )
 
 // Mock LM for testing
@@ -1022,3 +1024,180 @@ func TestRun_ErrorConditions(t *testing.T) {
 		t.Error("Run should return error when generation fails")
 	}
 }
+
+// streamingLM streams fixed chunks, optionally followed by an error
+type streamingLM struct {
+	mockLM
+	chunks []string
+	err    error
+}
+
+func (m *streamingLM) Stream(ctx context.Context, messages []core.Message, options *core.GenerateOptions) (<-chan core.Chunk, <-chan error) {
+	chunks := make(chan core.Chunk)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+		for i, c := range m.chunks {
+			chunk := core.Chunk{Content: c}
+			if i == len(m.chunks)-1 {
+				chunk.Usage = core.Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}
+			}
+			chunks <- chunk
+		}
+		if m.err != nil {
+			errs <- m.err
+		}
+	}()
+	return chunks, errs
+}
+
+func TestFunc_Stream(t *testing.T) {
+	type Input struct {
+		Question string `dsgo:"input,desc=Question"`
+	}
+	type Output struct {
+		Answer     string `dsgo:"output,desc=Answer"`
+		Confidence int    `dsgo:"output,desc=Confidence"`
+	}
+
+	tests := []struct {
+		name    string
+		chunks  []string
+		err     error
+		want    Output
+		wantErr string
+	}{
+		{
+			name:   "typed output after chunks",
+			chunks: []string{"[[ ## Answer ## ]]\nPar", "is\n\n[[ ## Confidence ## ]]\n9", "5"},
+			want:   Output{Answer: "Paris", Confidence: 95},
+		},
+		{
+			name:    "stream error",
+			chunks:  []string{"partial"},
+			err:     fmt.Errorf("connection reset"),
+			wantErr: "connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := NewPredict[Input, Output](&streamingLM{chunks: tt.chunks, err: tt.err})
+			if err != nil {
+				t.Fatalf("NewPredict() error = %v", err)
+			}
+
+			result, err := fn.Stream(context.Background(), Input{Question: "Capital of France?"})
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+
+			chunkCount := 0
+			for range result.Chunks {
+				chunkCount++
+			}
+			if chunkCount == 0 {
+				t.Error("expected streamed chunks")
+			}
+
+			output, gotOutput := <-result.Output
+			streamErr := <-result.Errors
+
+			if tt.wantErr != "" {
+				if gotOutput || streamErr == nil || !strings.Contains(streamErr.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q and no output, got output=%v err=%v", tt.wantErr, gotOutput, streamErr)
+				}
+				return
+			}
+			if streamErr != nil {
+				t.Fatalf("unexpected stream error: %v", streamErr)
+			}
+			if output != tt.want {
+				t.Errorf("output = %+v, want %+v", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunc_Stream_UnsupportedModule(t *testing.T) {
+	type Input struct {
+		Question string `dsgo:"input,desc=Question"`
+	}
+	type Output struct {
+		Answer string `dsgo:"output,desc=Answer"`
+	}
+
+	fn, err := NewCoT[Input, Output](&mockLM{})
+	if err != nil {
+		t.Fatalf("NewCoT() error = %v", err)
+	}
+	if _, err := fn.Stream(context.Background(), Input{Question: "q"}); err == nil {
+		t.Error("expected error for module without streaming support")
+	}
+}
+
+func TestFunc_StreamReAct(t *testing.T) {
+	type Input struct {
+		Topic string `dsgo:"input,desc=Research topic"`
+	}
+	type Output struct {
+		Summary string `dsgo:"output,desc=Summary"`
+	}
+	type SearchArgs struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+
+	lm := core.NewMockLM().WithToolSupport(true).RespondSequence(
+		core.MockResponse{
+			Content:   "Searching first",
+			ToolCalls: []core.ToolCall{{ID: "1", Name: "search", Arguments: map[string]any{"query": "go generics", "limit": 3.0}}},
+		},
+		core.MockResponse{Content: `{"Summary": "Go has generics"}`},
+	)
+	search := core.NewTool("search", "Search the web", func(ctx context.Context, args map[string]any) (any, error) {
+		return "generics landed in Go 1.18", nil
+	}).AddParameter("query", "string", "Query", true).AddParameter("limit", "integer", "Max results", false)
+
+	fn, err := NewReAct[Input, Output](lm, []core.Tool{*search})
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+	result, err := fn.StreamReAct(context.Background(), Input{Topic: "Go generics"})
+	if err != nil {
+		t.Fatalf("StreamReAct() error = %v", err)
+	}
+
+	var observation string
+	var args SearchArgs
+	for event := range result.Events {
+		switch event.Type {
+		case module.ReActEventToolCallStarted:
+			if args, err = ToolArgs[SearchArgs](event); err != nil {
+				t.Errorf("ToolArgs() error = %v", err)
+			}
+		case module.ReActEventToolResult:
+			observation = event.Output
+		}
+	}
+	if err := <-result.Errors; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	output := <-result.Output
+
+	if args != (SearchArgs{Query: "go generics", Limit: 3}) {
+		t.Errorf("tool args = %+v", args)
+	}
+	if !strings.Contains(observation, "Go 1.18") {
+		t.Errorf("observation = %q", observation)
+	}
+	if output.Summary != "Go has generics" {
+		t.Errorf("output = %+v", output)
+	}
+
+	predict, _ := NewPredict[Input, Output](lm)
+	if _, err := predict.StreamReAct(context.Background(), Input{Topic: "x"}); err == nil {
+		t.Error("expected error for a module other than ReAct")
+	}
+}